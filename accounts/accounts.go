@@ -39,6 +39,7 @@ const (
 	MimetypeDataWithValidator = "data/validator"
 	MimetypeTypedData         = "data/typed"
 	MimetypeClique            = "application/x-clique-header"
+	MimetypeEquality          = "application/x-equality-header"
 	MimetypeTextPlain         = "text/plain"
 )
 
@@ -0,0 +1,366 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package hsm implements an accounts backend signing with a secp256k1 key kept
+// in a hardware security module, accessed through its PKCS#11 library.
+//
+// The key never leaves the HSM: hashes are signed on the token with CKM_ECDSA,
+// and the raw signature returned is normalized to the low-S form and completed
+// with the recovery id expected by Ethereum.
+package hsm
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/SecretBlockChain/go-secret"
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/math"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/event"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/miekg/pkcs11"
+)
+
+// Scheme is the URI prefix for HSM wallets.
+const Scheme = "pkcs11"
+
+var (
+	// secp256k1OID is the DER encoded object identifier of the secp256k1 curve,
+	// as found in the CKA_EC_PARAMS of the keys.
+	secp256k1OID = []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}
+
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// errKeyNotFound is returned if the token holds no key pair with the label.
+var errKeyNotFound = errors.New("key not found on the token")
+
+// Config contains the settings to reach the key in the HSM.
+type Config struct {
+	Module string // Path of the PKCS#11 library of the HSM
+	Slot   uint   // Slot of the token holding the key
+	PIN    string // PIN of the user of the token
+	Label  string // Label of the key pair (CKA_LABEL)
+}
+
+// Backend is an accounts.Backend exposing the key of the HSM as a wallet.
+type Backend struct {
+	wallets []accounts.Wallet
+}
+
+// NewBackend opens a session on the token and looks up the key to sign with.
+func NewBackend(config Config) (*Backend, error) {
+	wallet, err := newWallet(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{wallets: []accounts.Wallet{wallet}}, nil
+}
+
+// Wallets implements accounts.Backend, returning the wallet of the HSM key.
+func (b *Backend) Wallets() []accounts.Wallet {
+	return b.wallets
+}
+
+// Subscribe implements accounts.Backend. The wallet is never added nor removed
+// once the backend created, so no events are ever sent.
+func (b *Backend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// Wallet is an accounts.Wallet signing with a key pair of the HSM.
+type Wallet struct {
+	config  Config
+	url     accounts.URL
+	account accounts.Account
+	pubkey  []byte // Uncompressed public key of the key pair
+
+	ctx     *pkcs11.Ctx          // PKCS#11 library, nil once the wallet is closed
+	session pkcs11.SessionHandle // Session logged into the token
+	key     pkcs11.ObjectHandle  // Private key signing the hashes
+	lock    sync.Mutex           // Lock serializing the operations of the session
+}
+
+// newWallet loads the PKCS#11 library and logs into the token.
+func newWallet(config Config) (*Wallet, error) {
+	ctx := pkcs11.New(config.Module)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %s", config.Module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize PKCS#11 module: %v", err)
+	}
+	w := &Wallet{
+		config: config,
+		url:    accounts.URL{Scheme: Scheme, Path: fmt.Sprintf("%d/%s", config.Slot, config.Label)},
+		ctx:    ctx,
+	}
+	if err := w.open(); err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	w.account = accounts.Account{Address: common.BytesToAddress(crypto.Keccak256(w.pubkey[1:])[12:]), URL: w.url}
+	log.Info("Opened HSM wallet", "url", w.url, "address", w.account.Address)
+	return w, nil
+}
+
+// open logs into the token and looks the key pair up.
+func (w *Wallet) open() error {
+	session, err := w.ctx.OpenSession(w.config.Slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return fmt.Errorf("failed to open session on slot %d: %v", w.config.Slot, err)
+	}
+	if err := w.ctx.Login(session, pkcs11.CKU_USER, w.config.PIN); err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		w.ctx.CloseSession(session)
+		return fmt.Errorf("failed to log into slot %d: %v", w.config.Slot, err)
+	}
+	w.session = session
+
+	key, err := w.findObject(pkcs11.CKO_PRIVATE_KEY)
+	if err == nil {
+		var pub pkcs11.ObjectHandle
+		if pub, err = w.findObject(pkcs11.CKO_PUBLIC_KEY); err == nil {
+			w.pubkey, err = w.publicKey(pub)
+		}
+	}
+	if err != nil {
+		w.ctx.Logout(session)
+		w.ctx.CloseSession(session)
+		return fmt.Errorf("key %q: %v", w.config.Label, err)
+	}
+	w.key = key
+	return nil
+}
+
+// findObject returns the object of the class labelled as the key pair.
+func (w *Wallet) findObject(class uint) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, w.config.Label),
+	}
+	if err := w.ctx.FindObjectsInit(w.session, template); err != nil {
+		return 0, err
+	}
+	objects, _, err := w.ctx.FindObjects(w.session, 2)
+	w.ctx.FindObjectsFinal(w.session)
+	switch {
+	case err != nil:
+		return 0, err
+	case len(objects) == 0:
+		return 0, errKeyNotFound
+	case len(objects) > 1:
+		return 0, errors.New("label shared by several keys")
+	}
+	return objects[0], nil
+}
+
+// publicKey reads the public key of the key pair, checking it is on secp256k1.
+func (w *Wallet) publicKey(object pkcs11.ObjectHandle) ([]byte, error) {
+	attrs, err := w.ctx.GetAttributeValue(w.session, object, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(attrs[0].Value, secp256k1OID) {
+		return nil, errors.New("key isn't on the secp256k1 curve")
+	}
+	return decodePoint(attrs[1].Value)
+}
+
+// decodePoint decodes the CKA_EC_POINT of a public key into an uncompressed key.
+// The point is meant to be wrapped into a DER octet string, but some modules
+// return it raw.
+func decodePoint(data []byte) ([]byte, error) {
+	var point []byte
+	if rest, err := asn1.Unmarshal(data, &point); err == nil && len(rest) == 0 {
+		data = point
+	}
+	pub, err := crypto.UnmarshalPubkey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	return crypto.FromECDSAPub(pub), nil
+}
+
+// URL implements accounts.Wallet, returning the slot and label of the key.
+func (w *Wallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet, returning whether the session is open.
+func (w *Wallet) Status() (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.ctx == nil {
+		return "Closed", accounts.ErrWalletClosed
+	}
+	return "Online", nil
+}
+
+// Open implements accounts.Wallet. The session with the token is opened once
+// the backend created, the PIN coming from the configuration.
+func (w *Wallet) Open(passphrase string) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.ctx == nil {
+		return accounts.ErrWalletClosed
+	}
+	return accounts.ErrWalletAlreadyOpen
+}
+
+// Close implements accounts.Wallet, logging out of the token and unloading the
+// PKCS#11 library.
+func (w *Wallet) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.ctx == nil {
+		return nil
+	}
+	w.ctx.Logout(w.session)
+	w.ctx.CloseSession(w.session)
+	err := w.ctx.Finalize()
+	w.ctx.Destroy()
+	w.ctx = nil
+	return err
+}
+
+// Accounts implements accounts.Wallet, returning the account of the HSM key.
+func (w *Wallet) Accounts() []accounts.Account {
+	return []accounts.Account{w.account}
+}
+
+// Contains implements accounts.Wallet, returning whether the account is the one
+// of the HSM key.
+func (w *Wallet) Contains(account accounts.Account) bool {
+	return account.Address == w.account.Address && (account.URL == (accounts.URL{}) || account.URL == w.url)
+}
+
+// Derive implements accounts.Wallet, but is a noop for HSM wallets since the
+// key is selected by its label.
+func (w *Wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for HSM wallets.
+func (w *Wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+	log.Error("Operation SelfDerive not supported on HSM wallets")
+}
+
+// signHash signs the hash with the HSM key, returning a signature in the
+// [R || S || V] format where V is 0 or 1.
+func (w *Wallet) signHash(account accounts.Account, hash []byte) ([]byte, error) {
+	if !w.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.ctx == nil {
+		return nil, accounts.ErrWalletClosed
+	}
+	if err := w.ctx.SignInit(w.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, w.key); err != nil {
+		return nil, fmt.Errorf("failed to sign with HSM: %v", err)
+	}
+	raw, err := w.ctx.Sign(w.session, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with HSM: %v", err)
+	}
+	return recoverableSignature(raw, hash, w.pubkey)
+}
+
+// recoverableSignature converts the [R || S] signature made by the HSM into the
+// one used by Ethereum: S is normalized to the lower half of the curve order,
+// and the recovery id is found by recovering the public key of the signer.
+func recoverableSignature(raw, hash, pubkey []byte) ([]byte, error) {
+	if len(raw) != 64 {
+		return nil, fmt.Errorf("invalid HSM signature length %d", len(raw))
+	}
+	s := new(big.Int).SetBytes(raw[32:])
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s.Sub(secp256k1N, s)
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, raw[:32])
+	math.ReadBits(s, sig[32:64])
+
+	for v := byte(0); v < 2; v++ {
+		sig[crypto.RecoveryIDOffset] = v
+		if pub, err := crypto.Ecrecover(hash, sig); err == nil && bytes.Equal(pub, pubkey) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("HSM signature doesn't match the key")
+}
+
+// SignData implements accounts.Wallet, signing keccak256(data) with the key.
+func (w *Wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase implements accounts.Wallet. The PIN of the token is
+// configured at startup, so no passphrase is accepted.
+func (w *Wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// SignText implements accounts.Wallet, signing the hash of the text prefixed
+// as an Ethereum message.
+func (w *Wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet, but is not supported.
+func (w *Wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// SignTx implements accounts.Wallet, signing the transaction with EIP155 if a
+// chain ID is given, homestead otherwise.
+func (w *Wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	var signer types.Signer = types.HomesteadSigner{}
+	if chainID != nil {
+		signer = types.NewEIP155Signer(chainID)
+	}
+	sig, err := w.signHash(account, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet, but is not supported.
+func (w *Wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, accounts.ErrNotSupported
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hsm
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common/math"
+	"github.com/SecretBlockChain/go-secret/crypto"
+)
+
+// Tests that the raw signatures of the HSM are converted into the recoverable
+// low-S signatures Ethereum expects, whatever the S the HSM picked.
+func TestRecoverableSignature(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pubkey := crypto.FromECDSAPub(&key.PublicKey)
+
+	for i := 0; i < 16; i++ {
+		hash := crypto.Keccak256([]byte{byte(i)})
+		want, err := crypto.Sign(hash, key)
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		// HSMs return [R || S], S being in either half of the curve order
+		high := make([]byte, 64)
+		copy(high, want[:32])
+		math.ReadBits(new(big.Int).Sub(secp256k1N, new(big.Int).SetBytes(want[32:64])), high[32:])
+
+		for _, raw := range [][]byte{want[:64], high} {
+			sig, err := recoverableSignature(raw, hash, pubkey)
+			if err != nil {
+				t.Fatalf("hash %d: failed to convert signature: %v", i, err)
+			}
+			if !bytes.Equal(sig, want) {
+				t.Fatalf("hash %d: signature mismatch: have %x, want %x", i, sig, want)
+			}
+		}
+		other, _ := crypto.GenerateKey()
+		if _, err := recoverableSignature(want[:64], hash, crypto.FromECDSAPub(&other.PublicKey)); err == nil {
+			t.Fatalf("hash %d: signature accepted for another key", i)
+		}
+	}
+	if _, err := recoverableSignature(make([]byte, 72), make([]byte, 32), pubkey); err == nil {
+		t.Fatalf("DER signature accepted")
+	}
+}
+
+// Tests that the EC points are decoded both wrapped into octet strings and raw.
+func TestDecodePoint(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pubkey := crypto.FromECDSAPub(&key.PublicKey)
+
+	wrapped, _ := asn1.Marshal(pubkey)
+	for _, data := range [][]byte{wrapped, pubkey} {
+		point, err := decodePoint(data)
+		if err != nil {
+			t.Fatalf("failed to decode point %x: %v", data, err)
+		}
+		if !bytes.Equal(point, pubkey) {
+			t.Fatalf("point mismatch: have %x, want %x", point, pubkey)
+		}
+	}
+	if _, err := decodePoint(pubkey[1:]); err == nil {
+		t.Fatalf("truncated point accepted")
+	}
+}
@@ -69,6 +69,15 @@ type driver interface {
 	SignTx(path accounts.DerivationPath, tx *types.Transaction, chainID *big.Int) (common.Address, *types.Transaction, error)
 }
 
+// hashSigner is implemented by the drivers of devices able to sign the hash of
+// arbitrary data, such as the seal of consensus headers. The stock Ethereum apps
+// of the Ledger and Trezor wallets only sign transactions.
+type hashSigner interface {
+	// SignHash sends the hash to the USB device and waits for the user to confirm
+	// or deny the signature.
+	SignHash(path accounts.DerivationPath, hash []byte) (common.Address, []byte, error)
+}
+
 // wallet represents the common functionality shared by all USB hardware
 // wallets to prevent reimplementing the same complex maintenance mechanisms
 // for different vendors.
@@ -516,10 +525,48 @@ func (w *wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.Chai
 	w.deriveChain = chain
 }
 
-// signHash implements accounts.Wallet, however signing arbitrary data is not
-// supported for hardware wallets, so this method will always return an error.
+// signHash implements accounts.Wallet, however signing arbitrary data is only
+// supported by the devices whose driver is a hashSigner, this method returns an
+// error for the others.
 func (w *wallet) signHash(account accounts.Account, hash []byte) ([]byte, error) {
-	return nil, accounts.ErrNotSupported
+	signer, ok := w.driver.(hashSigner)
+	if !ok {
+		return nil, accounts.ErrNotSupported
+	}
+	w.stateLock.RLock() // Comms have own mutex, this is for the state fields
+	defer w.stateLock.RUnlock()
+
+	// If the wallet is closed, abort
+	if w.device == nil {
+		return nil, accounts.ErrWalletClosed
+	}
+	// Make sure the requested account is contained within
+	path, ok := w.paths[account.Address]
+	if !ok {
+		return nil, accounts.ErrUnknownAccount
+	}
+	<-w.commsLock
+	defer func() { w.commsLock <- struct{}{} }()
+
+	// Ensure the device isn't screwed with while user confirmation is pending
+	w.hub.commsLock.Lock()
+	w.hub.commsPend++
+	w.hub.commsLock.Unlock()
+
+	defer func() {
+		w.hub.commsLock.Lock()
+		w.hub.commsPend--
+		w.hub.commsLock.Unlock()
+	}()
+	// Sign the hash and verify the signer to avoid hardware fault surprises
+	sender, signature, err := signer.SignHash(path, hash)
+	if err != nil {
+		return nil, err
+	}
+	if sender != account.Address {
+		return nil, fmt.Errorf("signer mismatch: expected %s, got %s", account.Address.Hex(), sender.Hex())
+	}
+	return signature, nil
 }
 
 // SignData signs keccak256(data). The mimetype parameter describes the type of data being signed
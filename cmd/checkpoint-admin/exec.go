@@ -48,6 +48,7 @@ var commandDeploy = cli.Command{
 		signerFlag,
 		signersFlag,
 		thresholdFlag,
+		equalityFlag,
 	},
 	Action: utils.MigrateFlags(deploy),
 }
@@ -62,6 +63,7 @@ var commandSign = cli.Command{
 		indexFlag,
 		hashFlag,
 		oracleFlag,
+		equalityFlag,
 	},
 	Action: utils.MigrateFlags(sign),
 }
@@ -109,8 +111,9 @@ func deploy(ctx *cli.Context) error {
 
 	// Deploy the checkpoint oracle
 	fmt.Println("Sending deploy request to Clef...")
-	oracle, tx, _, err := contract.DeployCheckpointOracle(transactor, client, addrs, big.NewInt(int64(params.CheckpointFrequency)),
-		big.NewInt(int64(params.CheckpointProcessConfirmations)), big.NewInt(int64(needed)))
+	frequency, confirmations := checkpointSections(ctx)
+	oracle, tx, _, err := contract.DeployCheckpointOracle(transactor, client, addrs, new(big.Int).SetUint64(frequency),
+		new(big.Int).SetUint64(confirmations), big.NewInt(int64(needed)))
 	if err != nil {
 		utils.Fatalf("Failed to deploy checkpoint oracle %v", err)
 	}
@@ -119,6 +122,15 @@ func deploy(ctx *cli.Context) error {
 	return nil
 }
 
+// checkpointSections returns the section size of the checkpoints and the number
+// of confirmations before they are generated.
+func checkpointSections(ctx *cli.Context) (uint64, uint64) {
+	if ctx.Bool(equalityFlag.Name) {
+		return params.EqualityCheckpointFrequency, params.EqualityCheckpointProcessConfirmations
+	}
+	return params.CheckpointFrequency, params.CheckpointProcessConfirmations
+}
+
 // sign creates the signature for specific checkpoint
 // with local key. Only contract admins have the permission to
 // sign checkpoint.
@@ -165,7 +177,8 @@ func sign(ctx *cli.Context) error {
 			return err
 		}
 		num := head.Number.Uint64()
-		if num < ((cindex+1)*params.CheckpointFrequency + params.CheckpointProcessConfirmations) {
+		frequency, confirmations := checkpointSections(ctx)
+		if num < ((cindex+1)*frequency + confirmations) {
 			utils.Fatalf("Invalid future checkpoint")
 		}
 		_, oracle = newContract(node)
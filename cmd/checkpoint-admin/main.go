@@ -91,6 +91,10 @@ var (
 		Name:  "signatures",
 		Usage: "Comma separated checkpoint signatures to submit",
 	}
+	equalityFlag = cli.BoolFlag{
+		Name:  "equality",
+		Usage: "Use the checkpoint sections of chains sealed by equality",
+	}
 )
 
 func main() {
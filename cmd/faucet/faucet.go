@@ -45,6 +45,8 @@ import (
 	"github.com/SecretBlockChain/go-secret/accounts/keystore"
 	"github.com/SecretBlockChain/go-secret/cmd/utils"
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/eth"
@@ -59,6 +61,7 @@ import (
 	"github.com/SecretBlockChain/go-secret/p2p/enode"
 	"github.com/SecretBlockChain/go-secret/p2p/nat"
 	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rlp"
 	"github.com/gorilla/websocket"
 )
 
@@ -75,6 +78,9 @@ var (
 	minutesFlag = flag.Int("faucet.minutes", 1440, "Number of minutes to wait between funding rounds")
 	tiersFlag   = flag.Int("faucet.tiers", 3, "Number of funding tiers to enable (x3 time, x2.5 funds)")
 
+	candidateFlag = flag.Bool("faucet.candidate", false, "Enables candidate deposit grants of equality networks")
+	registerFlag  = flag.Bool("faucet.register", false, "Submits signed candidate registrations once the deposit is funded")
+
 	accJSONFlag = flag.String("account.json", "", "Key json file to fund user requests with")
 	accPassFlag = flag.String("account.pass", "", "Decryption password to access faucet funds")
 
@@ -125,6 +131,26 @@ func main() {
 			periods[i] = strings.TrimSuffix(periods[i], "s")
 		}
 	}
+	// Load and parse the genesis block requested by the user
+	blob, err := ioutil.ReadFile(*genesisFlag)
+	if err != nil {
+		log.Crit("Failed to read genesis block contents", "genesis", *genesisFlag, "err", err)
+	}
+	genesis := new(core.Genesis)
+	if err = json.Unmarshal(blob, genesis); err != nil {
+		log.Crit("Failed to parse genesis block json", "err", err)
+	}
+	// Candidate deposits are granted on top of the base tier to cover the fees
+	var candidate string
+	if *candidateFlag {
+		if genesis.Config.Equality == nil {
+			log.Crit("Candidate deposits require an equality genesis")
+		}
+		amount := new(big.Int).Add(genesis.Config.Equality.MinCandidateBalance, new(big.Int).Mul(big.NewInt(int64(*payoutFlag)), ether))
+		candidate = fmt.Sprintf("%s Ethers", new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetInt(ether)).Text('f', -1))
+	} else if *registerFlag {
+		log.Crit("Candidate registrations require candidate deposits")
+	}
 	// Load up and render the faucet website
 	tmpl, err := Asset("faucet.html")
 	if err != nil {
@@ -137,19 +163,12 @@ func main() {
 		"Periods":   periods,
 		"Recaptcha": *captchaToken,
 		"NoAuth":    *noauthFlag,
+		"Candidate": candidate,
+		"Register":  *registerFlag,
 	})
 	if err != nil {
 		log.Crit("Failed to render the faucet template", "err", err)
 	}
-	// Load and parse the genesis block requested by the user
-	blob, err := ioutil.ReadFile(*genesisFlag)
-	if err != nil {
-		log.Crit("Failed to read genesis block contents", "genesis", *genesisFlag, "err", err)
-	}
-	genesis := new(core.Genesis)
-	if err = json.Unmarshal(blob, genesis); err != nil {
-		log.Crit("Failed to parse genesis block json", "err", err)
-	}
 	// Convert the bootnodes to internal enode representations
 	var enodes []*discv5.Node
 	for _, boot := range strings.Split(*bootFlag, ",") {
@@ -194,6 +213,8 @@ type request struct {
 	Account common.Address     `json:"account"` // Ethereum address being funded
 	Time    time.Time          `json:"time"`    // Timestamp when the request was accepted
 	Tx      *types.Transaction `json:"tx"`      // Transaction funding the account
+
+	Registration *types.Transaction `json:"registration,omitempty"` // Candidate registration to submit once funded
 }
 
 // faucet represents a crypto faucet backed by an Ethereum light client.
@@ -379,9 +400,11 @@ func (f *faucet) apiHandler(w http.ResponseWriter, r *http.Request) {
 	for {
 		// Fetch the next funding request and validate against github
 		var msg struct {
-			URL     string `json:"url"`
-			Tier    uint   `json:"tier"`
-			Captcha string `json:"captcha"`
+			URL          string        `json:"url"`
+			Tier         uint          `json:"tier"`
+			Captcha      string        `json:"captcha"`
+			Candidate    bool          `json:"candidate"`
+			Registration hexutil.Bytes `json:"registration"`
 		}
 		if err = conn.ReadJSON(&msg); err != nil {
 			return
@@ -394,6 +417,22 @@ func (f *faucet) apiHandler(w http.ResponseWriter, r *http.Request) {
 			}
 			continue
 		}
+		if msg.Candidate && !*candidateFlag {
+			//lint:ignore ST1005 This error is to be displayed in the browser
+			if err = sendError(conn, errors.New("Candidate deposits not enabled")); err != nil {
+				log.Warn("Failed to send candidate error to client", "err", err)
+				return
+			}
+			continue
+		}
+		if len(msg.Registration) > 0 && (!msg.Candidate || !*registerFlag) {
+			//lint:ignore ST1005 This error is to be displayed in the browser
+			if err = sendError(conn, errors.New("Candidate registrations not enabled")); err != nil {
+				log.Warn("Failed to send registration error to client", "err", err)
+				return
+			}
+			continue
+		}
 		if msg.Tier >= uint(*tiersFlag) {
 			//lint:ignore ST1005 This error is to be displayed in the browser
 			if err = sendError(conn, errors.New("Invalid funding tier requested")); err != nil {
@@ -480,6 +519,18 @@ func (f *faucet) apiHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		log.Info("Faucet request valid", "url", msg.URL, "tier", msg.Tier, "user", username, "address", address)
 
+		// Make sure the registration is signed by the funded account and paid by the grant
+		var registration *types.Transaction
+		if len(msg.Registration) > 0 {
+			if registration, err = f.checkRegistration(msg.Registration, address); err != nil {
+				if err = sendError(conn, err); err != nil {
+					log.Warn("Failed to send registration error to client", "err", err)
+					return
+				}
+				continue
+			}
+		}
+
 		// Ensure the user didn't request funds too recently
 		f.lock.Lock()
 		var (
@@ -491,6 +542,9 @@ func (f *faucet) apiHandler(w http.ResponseWriter, r *http.Request) {
 			amount := new(big.Int).Mul(big.NewInt(int64(*payoutFlag)), ether)
 			amount = new(big.Int).Mul(amount, new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(msg.Tier)), nil))
 			amount = new(big.Int).Div(amount, new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(msg.Tier)), nil))
+			if msg.Candidate {
+				amount = new(big.Int).Add(f.config.Equality.MinCandidateBalance, new(big.Int).Mul(big.NewInt(int64(*payoutFlag)), ether))
+			}
 
 			tx := types.NewTransaction(f.nonce+uint64(len(f.reqs)), address, amount, 21000, f.price, nil)
 			signed, err := f.keystore.SignTx(f.account, tx, f.config.ChainID)
@@ -512,10 +566,11 @@ func (f *faucet) apiHandler(w http.ResponseWriter, r *http.Request) {
 				continue
 			}
 			f.reqs = append(f.reqs, &request{
-				Avatar:  avatar,
-				Account: address,
-				Time:    time.Now(),
-				Tx:      signed,
+				Avatar:       avatar,
+				Account:      address,
+				Time:         time.Now(),
+				Tx:           signed,
+				Registration: registration,
 			})
 			timeout := time.Duration(*minutesFlag*int(math.Pow(3, float64(msg.Tier)))) * time.Minute
 			grace := timeout / 288 // 24h timeout => 5m grace
@@ -577,14 +632,54 @@ func (f *faucet) refresh(head *types.Header) error {
 	f.lock.Lock()
 	f.head, f.balance = head, balance
 	f.price, f.nonce = price, nonce
+	var registrations []*types.Transaction
 	for len(f.reqs) > 0 && f.reqs[0].Tx.Nonce() < f.nonce {
+		if f.reqs[0].Registration != nil {
+			registrations = append(registrations, f.reqs[0].Registration)
+		}
 		f.reqs = f.reqs[1:]
 	}
 	f.lock.Unlock()
 
+	// Deposits of the ejected requests were funded, submit the registrations
+	for _, tx := range registrations {
+		if err := f.client.SendTransaction(ctx, tx); err != nil {
+			log.Warn("Failed to submit candidate registration", "hash", tx.Hash(), "err", err)
+			continue
+		}
+		log.Info("Submitted candidate registration", "hash", tx.Hash())
+	}
 	return nil
 }
 
+// checkRegistration decodes a signed candidate registration and ensures it was
+// signed by the funded account and its fees are covered by the base tier grant
+// topping up the candidate deposit.
+func (f *faucet) checkRegistration(blob []byte, address common.Address) (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, tx); err != nil {
+		return nil, err
+	}
+	if tx.ChainId().Cmp(f.config.ChainID) != 0 {
+		//lint:ignore ST1005 This error is to be displayed in the browser
+		return nil, errors.New("Registration signed for another chain")
+	}
+	ctx, err := equality.NewTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	event, ok := ctx.(*equality.EventBecomeCandidate)
+	if !ok || event.Candidate != address {
+		//lint:ignore ST1005 This error is to be displayed in the browser
+		return nil, errors.New("Registration doesn't make the funded account a candidate")
+	}
+	if tx.Cost().Cmp(new(big.Int).Mul(big.NewInt(int64(*payoutFlag)), ether)) > 0 {
+		//lint:ignore ST1005 This error is to be displayed in the browser
+		return nil, errors.New("Registration costs more than the granted fees")
+	}
+	return tx, nil
+}
+
 // loop keeps waiting for interesting events and pushes them out to connected
 // websockets.
 func (f *faucet) loop() {
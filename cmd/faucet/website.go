@@ -1,6 +1,6 @@
 // Code generated by go-bindata. DO NOT EDIT.
 // sources:
-// faucet.html (11.27kB)
+// faucet.html (12.38kB)
 
 package main
 
@@ -69,7 +69,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _faucetHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x5a\x6d\x93\xdb\x36\x92\xfe\x3c\xfe\x15\x1d\x9e\xbd\x92\xce\x43\x52\x33\x63\x7b\x7d\x12\xa9\x94\xd7\x9b\xdd\xf3\xd5\x5d\x92\x4a\x9c\xba\xdb\xca\xa6\xae\x40\xb2\x25\xc2\x03\x02\x0c\x00\x4a\xa3\x4c\xe9\xbf\x5f\x35\x40\x52\xd4\xcb\x4c\xec\xb5\xaf\x6a\xfd\x61\x4c\x02\x8d\x46\xa3\xfb\x69\xf4\x0b\x95\x7c\xf5\xe7\xef\xde\xbe\xff\xdb\xf7\xdf\x40\x69\x2b\xb1\x78\x92\xd0\x7f\x20\x98\x5c\xa5\x01\xca\x60\xf1\xe4\x22\x29\x91\x15\x8b\x27\x17\x17\x49\x85\x96\x41\x5e\x32\x6d\xd0\xa6\x41\x63\x97\xe1\xeb\x60\x3f\x51\x5a\x5b\x87\xf8\x6b\xc3\xd7\x69\xf0\x3f\xe1\x4f\x6f\xc2\xb7\xaa\xaa\x99\xe5\x99\xc0\x00\x72\x25\x2d\x4a\x9b\x06\xef\xbe\x49\xb1\x58\xe1\x60\x9d\x64\x15\xa6\xc1\x9a\xe3\xa6\x56\xda\x0e\x48\x37\xbc\xb0\x65\x5a\xe0\x9a\xe7\x18\xba\x97\x4b\xe0\x92\x5b\xce\x44\x68\x72\x26\x30\xbd\x0a\x16\x4f\x88\x8f\xe5\x56\xe0\xe2\xfe\x3e\xfa\x16\xed\x46\xe9\xdb\xdd\x6e\x06\x6f\x1a\x5b\xa2\xb4\x3c\x67\x16\x0b\xf8\x0b\x6b\x72\xb4\x49\xec\x29\xdd\x22\xc1\xe5\x2d\x94\x1a\x97\x69\x40\xa2\x9b\x59\x1c\xe7\x85\xfc\x60\xa2\x5c\xa8\xa6\x58\x0a\xa6\x31\xca\x55\x15\xb3\x0f\xec\x2e\x16\x3c\x33\xb1\xdd\x70\x6b\x51\x87\x99\x52\xd6\x58\xcd\xea\xf8\x26\xba\x89\xfe\x18\xe7\xc6\xc4\xfd\x58\x54\x71\x19\xe5\xc6\x04\xa0\x51\xa4\x81\xb1\x5b\x81\xa6\x44\xb4\x01\xc4\x8b\x7f\x6c\xdf\xa5\x92\x36\x64\x1b\x34\xaa\xc2\xf8\x45\xf4\xc7\x68\xea\xb6\x1c\x0e\x3f\xbe\x2b\x6d\x6b\x72\xcd\x6b\x0b\x46\xe7\x1f\xbd\xef\x87\x5f\x1b\xd4\xdb\xf8\x26\xba\x8a\xae\xda\x17\xb7\xcf\x07\x13\x2c\x92\xd8\x33\x5c\x7c\x16\xef\x50\x2a\xbb\x8d\xaf\xa3\x17\xd1\x55\x5c\xb3\xfc\x96\xad\xb0\xe8\x76\xa2\xa9\xa8\x1b\xfc\x62\xfb\x3e\x64\xc3\x0f\xc7\x26\xfc\x12\x9b\x55\xaa\x42\x69\xa3\x0f\x26\xbe\x8e\xae\x5e\x47\xd3\x6e\xe0\x94\xbf\xdb\x80\x8c\x46\x5b\x5d\x44\x6b\xd4\x84\x5c\x11\xe6\x28\x2d\x6a\xb8\xa7\xd1\x8b\x8a\xcb\xb0\x44\xbe\x2a\xed\x0c\xae\xa6\xd3\x67\xf3\x73\xa3\xeb\xd2\x0f\x17\xdc\xd4\x82\x6d\x67\xb0\x14\x78\xe7\x87\x98\xe0\x2b\x19\x72\x8b\x95\x99\x81\xe7\xec\x26\x76\x6e\xcf\x5a\xab\x95\x46\x63\xda\xcd\x6a\x65\xb8\xe5\x4a\xce\x08\x51\xcc\xf2\x35\x9e\xa3\x35\x35\x93\x27\x0b\x58\x66\x94\x68\x2c\x1e\x09\x92\x09\x95\xdf\xfa\x31\xe7\xcd\xc3\x43\xe4\x4a\x28\x3d\x83\x4d\xc9\xdb\x65\xe0\x36\x82\x5a\x63\xcb\x1e\x6a\x56\x14\x5c\xae\x66\xf0\xaa\x6e\xcf\x03\x15\xd3\x2b\x2e\x67\x30\xdd\x2f\x49\xe2\x4e\x8d\x49\xec\x2f\xae\x27\x17\x49\xa6\x8a\xad\xb3\x61\xc1\xd7\x90\x0b\x66\x4c\x1a\x1c\xa9\xd8\x5d\x48\x07\x04\x74\x0f\x31\x2e\xbb\xa9\x83\x39\xad\x36\x01\xb8\x8d\xd2\xc0\x0b\x11\x66\xca\x5a\x55\xcd\xe0\x8a\xc4\x6b\x97\x1c\xf1\x13\xa1\x58\x85\x57\xd7\xdd\xe4\x45\x52\x5e\x75\x4c\x2c\xde\xd9\xd0\xd9\xa7\xb7\x4c\xb0\x48\x78\xb7\x76\xc9\x60\xc9\xc2\x8c\xd9\x32\x00\xa6\x39\x0b\x4b\x5e\x14\x28\xd3\xc0\xea\x06\x09\x47\x7c\x01\xc3\xeb\xef\x81\xdb\xaf\xbc\xea\xe4\x8a\x0b\xbe\x6e\x8f\x35\x78\x3c\x3a\xe1\xc3\x87\x78\x0d\xed\x83\x5a\x2e\x0d\xda\x70\x70\xa6\x01\x31\x97\x75\x63\xc3\x95\x56\x4d\xdd\xcf\x5f\x24\x6e\x14\x78\x91\x06\x8d\x16\x41\x7b\xfd\xbb\x47\xbb\xad\x5b\x55\x04\xfd\xc1\x95\xae\x42\xb2\x84\x56\x22\x80\x5a\xb0\x1c\x4b\x25\x0a\xd4\x69\xf0\xa3\xca\x39\x13\x20\xfd\x99\xe1\xa7\x1f\xfe\x13\x5a\x93\x71\xb9\x82\xad\x6a\x34\x7c\x63\x4b\xd4\xd8\x54\xc0\x8a\x82\xe0\x1a\x45\xd1\x40\x10\x87\xdd\x53\x51\xc3\xcc\xca\x3d\xd5\x45\x92\x35\xd6\xaa\x9e\x30\xb3\x12\x32\x2b\xc3\x02\x97\xac\x11\x16\x0a\xad\xea\x42\x6d\x64\x68\xd5\x6a\x45\x91\xce\x1f\xc2\x2f\x0a\xa0\x60\x96\xb5\x53\x69\xd0\xd1\x76\x36\x64\xa6\x56\x75\x53\xb7\x56\xf4\x83\x78\x57\x33\x59\x60\x41\x36\x17\x06\x83\xc5\x5f\xf9\x1a\xa1\x42\x7f\x96\x8b\x63\x48\xe4\x4c\xa3\x0d\x87\x4c\x4f\x80\x91\xc4\x5e\x18\x7f\x24\x68\xff\x25\x8d\xe8\x38\xf5\x47\xa8\x50\x36\x70\xf0\x16\x6a\xba\x57\x82\xc5\xfd\xbd\x66\x72\x85\xf0\x94\x17\x77\x97\xf0\x94\x55\xaa\x91\x16\x66\x29\x44\x6f\xdc\xa3\xd9\xed\x0e\xb8\x03\x24\x82\x2f\x12\xf6\x18\xbc\x41\xc9\x5c\xf0\xfc\x36\x0d\x2c\x47\x9d\xde\xdf\x13\xf3\xdd\x6e\x0e\xf7\xf7\x7c\x09\x4f\xa3\x1f\x30\x67\xb5\xcd\x4b\xb6\xdb\xad\x74\xf7\x1c\xe1\x1d\xe6\x8d\xc5\xf1\xe4\xfe\x1e\x85\xc1\xdd\xce\x34\x59\xc5\xed\xb8\x5b\x4e\xe3\xb2\xd8\xed\x48\xe6\x56\xce\xdd\x0e\x62\x62\x2a\x0b\xbc\x83\xa7\xd1\xf7\xa8\xb9\x2a\x0c\x78\xfa\x24\x66\x8b\x24\x16\x7c\xd1\xae\x3b\x54\x52\xdc\x88\x3d\x5e\x62\x02\x4c\x8f\x73\xe7\x36\x4e\xd4\xa1\xa4\x67\xbc\x60\x15\xf6\xd2\xb7\x78\x30\xdc\xe2\x2d\x6e\xd3\xe0\xfe\x7e\xb8\xb6\x9d\xcd\x99\x10\x19\x23\xbd\xf8\xa3\xf5\x8b\x7e\x43\xc2\xe9\x9a\x1b\x97\x52\x2d\x3a\x09\xf6\x62\x7f\xa4\x5b\x1f\x5d\x5c\x56\xd5\x33\xb8\xb9\x1e\xdc\x5a\xe7\x3c\xfe\xd5\x91\xc7\xdf\x9c\x25\xae\x99\x44\x01\xee\x6f\x68\x2a\x26\xba\xe7\xd6\x5b\x06\xce\x77\xbc\x28\xa4\x3b\xba\x17\xad\xbf\xeb\xa7\x73\x50\x6b\xd4\x4b\xa1\x36\x33\x60\x8d\x55\x73\xa8\xd8\x5d\x1f\xef\x6e\xa6\xd3\xa1\xdc\x94\x0a\xb2\x4c\xa0\xbb\x5d\x34\xfe\xda\xa0\xb1\xa6\xbf\x4b\xfc\x94\xfb\x4b\x57\x4a\x81\xd2\x60\x71\xa4\x0d\xda\x91\x54\xeb\xa8\x06\xa6\xef\x95\x79\x56\xf6\xa5\x52\x7d\x08\x19\x8a\xd1\xb2\x1e\x44\xbb\x60\x91\x58\xbd\xa7\xbb\x48\x6c\xf1\x49\x21\x40\x53\x8a\xf7\x50\x04\xf0\x37\x1a\x9d\xbd\x46\xd4\x3e\xbf\x20\xc8\x82\x7b\x4d\x62\x5b\x7c\xc6\xce\x04\xc2\x8c\x19\xfc\x98\xed\x5d\xa4\xdf\x6f\xef\x5e\x3f\x77\xff\x12\x99\xb6\x19\x32\xfb\x31\x02\x2c\x1b\x59\x0c\xce\xef\xee\xce\xcf\x15\xa0\x91\x7c\x8d\xda\x70\xbb\xfd\x58\x09\xb0\xd8\x8b\xe0\xdf\x0f\x45\x48\x62\xab\x1f\xc7\xda\xf0\xe5\x0b\x39\xf7\xef\xa5\x24\x37\x8b\x7f\x57\x1b\x28\x14\x1a\xb0\x25\x37\x40\xc1\xf5\xeb\x24\x2e\x6f\x7a\x92\x7a\xf1\x9e\x26\x9c\x52\x61\xe9\x52\x0b\xe0\x06\x74\x23\x5d\xe4\x55\x12\x6c\x89\x87\xe9\x48\x1b\xa4\x23\x78\xaf\x28\xa5\x5b\xa3\xb4\x50\x31\xc1\x73\xae\x1a\x03\x2c\xb7\x4a\x1b\x58\x6a\x55\x01\xde\x95\xac\x31\x96\x18\xd1\xf5\xc1\xd6\x8c\x0b\xe7\x4b\xce\xa4\xa0\x34\xb0\x3c\x6f\xaa\x86\x52\x52\xb9\x02\x94\xaa\x59\x95\xad\x2c\x56\x81\x0f\x4c\x42\xc9\x55\x2f\x8f\xa9\x59\x05\xcc\x5a\x96\xdf\x9a\x4b\xe8\x6e\x05\x60\x1a\xc1\x72\x2c\x68\x55\xae\xaa\x4a\x49\xb8\xd1\x05\xd4\x4c\xdb\x2d\x98\xc3\xdc\x82\xe5\xb9\x8b\x72\x11\xbc\x91\x5b\x25\x11\x4a\xb6\x76\x12\xc2\x7b\x5f\x4e\x90\x5c\x7f\x61\x39\x66\x4a\xf5\xd4\x50\xb1\x6d\xb7\x5d\x2b\xfd\x86\xdb\x92\x7b\xf5\xd4\xa8\x2b\x5a\x5a\x80\xe0\x15\xb7\x26\x4a\xe2\x7a\x7f\xa3\xee\x63\xb3\x08\x4b\xa5\xf9\x6f\x94\xd8\x88\xe1\xf5\x69\x8f\x2e\x97\xee\x6e\x74\x56\x17\xb8\xb4\x33\x78\xe1\xef\xc6\x63\x1c\xb7\x15\xd0\x39\x10\x77\x3c\x5d\x65\x49\x01\x67\x06\x37\x3e\x9d\xf5\x89\x44\x61\x07\x12\x14\x47\x50\xf3\x9b\xbe\x7e\x5d\xdf\xf5\x72\xf4\x39\xf1\xb4\x67\x42\x08\x38\x54\xca\x9a\xf7\x6a\xbc\x84\x8a\xdd\x22\x30\x48\xd8\x51\x85\xdc\x0a\xed\xea\x2b\xee\xfa\x03\xb1\xdd\x20\xda\xaf\xc9\x75\xd3\x1f\x3c\x43\x2e\x57\xcf\xae\xa7\x1e\x91\xf4\x40\xec\x9f\x5d\x4f\xb9\xb4\xea\xd9\xf5\x74\x7a\x37\xfd\xc8\x7f\xcf\xae\xa7\x4a\x3e\xbb\x9e\xda\x12\x9f\x5d\x4f\x9f\x5d\xdf\x0c\xb1\xec\x47\xba\xcc\x92\xa8\xd0\xd0\x6e\x1d\xc4\x03\xb0\x4c\xaf\xd0\xa6\xc1\xff\xb2\x4c\x35\x76\x96\x09\x26\x6f\x83\x85\x13\x97\xb2\x0d\x87\x82\xf3\xf9\x29\xd4\xcc\x10\x24\x48\x62\x87\x92\xb6\x17\x62\x60\x6c\x1a\xad\x55\x23\x29\x2a\x02\x9d\xd9\x79\xa8\x1c\x11\xca\x48\x31\x93\x28\xc9\x74\xbc\x78\xab\xea\x6d\xe8\x98\xb8\xe5\x27\x6a\x34\x4d\x5d\x2b\x6d\xa3\xa1\x3a\x19\xd5\x41\x02\x4d\xfc\x7a\xfa\xf2\xf5\xab\x47\xc5\x37\x94\x65\xbb\x33\xf4\x12\xb2\x4c\xad\x11\x7c\x4e\x9f\xa9\x3b\x60\xb2\x80\x25\xd7\x08\x6c\xc3\xb6\x5f\x25\x71\xe1\x2a\xb0\xcf\x47\xed\xb2\xf5\xae\x7f\x2a\xd8\x76\x2e\x7f\x09\x75\x93\x09\x6e\x4a\x60\x20\x71\x03\x89\xb1\x5a\xc9\xd5\xc2\x8d\xe6\x54\x92\xba\x57\xa8\x95\xb1\x8f\x99\x1f\xab\x0c\x8b\xe2\x0c\x00\xbe\x94\xfd\x37\x9b\x4d\xd4\x69\xd2\x19\xbf\x44\x51\xc7\x74\xfd\x35\x92\xdb\x6d\xec\xdd\x48\xc9\xf8\x6b\x5e\xa4\xd7\xaf\xaf\x5f\xbd\xba\x7e\xf1\x6f\xaf\x5f\xbe\xbc\x7e\xfd\xe2\xe5\x43\xc8\xa0\x43\x7d\x26\x30\x7c\x1a\xfd\xad\xa2\xaa\xb5\xcf\xa1\x3d\x5e\xba\xdc\x8d\x22\x74\x41\x35\x88\x0e\xfe\x61\x0c\x35\x92\x12\x91\x90\x89\xb3\x39\xc4\x27\xa0\xc8\xc1\xe8\x11\xc9\x3e\x13\x5a\x1d\x7c\x08\x29\xaa\xb1\x74\xc2\xae\x98\xe7\x4a\xf6\x70\xba\x04\xc3\xab\x5a\x6c\x21\xdf\x5b\xfd\x3c\xae\x1e\x34\xca\xef\xc2\xea\xd0\x6c\x1e\x64\x2e\xfa\x57\xaa\x40\x8a\xfa\xa6\x31\x39\xd6\xae\xcb\x4b\x91\xf4\x4f\xdb\xdf\x98\xb4\x5c\x62\x17\x71\x23\xf8\x4e\x8a\x2d\x34\x06\x61\xa9\x34\x14\x98\x35\xab\x95\x4b\x13\x34\xd4\x9a\xaf\x99\xc5\x2e\xcc\x9a\x16\x15\x3d\x28\x06\x95\x0d\xa5\x3c\x62\x90\x81\xfc\x4d\x35\x90\x33\x09\x56\xb3\xfc\xd6\x7b\x4a\xa3\x35\x79\x4a\x8d\xfe\x34\x7d\xa0\xcf\x50\xa8\x8d\x23\xf1\xe7\x5e\x72\x14\x2e\xea\x1b\x44\x28\xd5\x06\xaa\x26\x77\x0e\x49\x51\xdd\x1d\x62\xc3\xb8\x85\x46\x5a\x2e\xbc\x3e\x6d\xa3\x25\xe5\x08\x78\x10\xa5\x4f\x6a\xbf\x04\xab\xc5\xfb\x12\xcf\xa4\x44\x7d\xd5\x06\x1a\xdf\x7a\x72\xa8\xb5\xb2\x98\x93\x41\x81\xad\x18\x97\x86\x2c\xe2\xf2\x00\xac\x3e\xa2\xaa\xeb\x9f\xda\x87\x7d\x87\xd2\x4d\xc7\x31\xfc\x55\xa8\x8c\x09\x58\x13\xd2\x33\x41\xe9\x9c\x82\x52\xd1\xd1\x07\xda\x32\x96\xd9\xc6\x80\x5a\xba\x51\x2f\x39\xad\x5f\x33\x4d\x16\xc4\xaa\xb6\x90\xb6\xfd\x35\x1a\x33\xa8\xd7\x6d\xd7\x90\x5e\xa9\x72\x3f\x98\xef\xb5\x9e\xc2\xcf\xbf\xcc\x9f\xb4\xa2\xfc\x19\x97\x0e\x12\x84\x6f\x7f\x64\x5b\x32\x0b\xb9\x46\x66\xd1\x40\x2e\x94\x69\xb4\x97\xb0\xd0\xaa\x06\x92\xb2\xe3\xd4\x71\xa6\x89\xda\xed\xd6\x31\x19\x97\xcc\x94\x93\xb6\x3d\xa8\xd1\x59\xa9\x9f\xeb\xc6\x2f\x08\x75\x63\x62\xc0\xd3\xe9\x1c\x78\xd2\xf1\x8d\x04\xca\x95\x2d\xe7\xc0\x9f\x3f\xef\x89\x2f\xf8\x12\xc6\x1d\xc5\xcf\xfc\x97\xc8\xde\x45\xb4\x0b\xa4\x29\x0c\x77\x73\x1b\xb6\x7c\x4c\x2d\x78\x8e\x63\x7e\x09\x57\x93\x79\x37\x9b\x69\x64\xb7\xdd\x5b\x6b\x47\xff\x9f\xfb\xbb\x9b\x1f\x6a\xc6\x29\xff\x40\x37\xbe\xf6\x37\xc0\x60\xc5\x8d\x85\x46\x0b\x68\x7d\xd8\x9b\xa0\x37\x88\xa3\x1b\x6a\xe5\x04\x97\xed\x43\x8b\xa9\xee\x08\x9e\x4d\x64\x50\x16\xe3\xff\xf8\xf1\xbb\x6f\x23\x63\x35\x97\x2b\xbe\xdc\x8e\xef\x1b\x2d\x66\xf0\x74\x1c\xfc\x4b\xa3\x45\x30\xf9\x79\xfa\x4b\xb4\x66\xa2\xc1\x4b\x67\xef\x99\xfb\x7b\xb2\xcb\x25\xb4\x8f\x33\x38\xdc\x70\x37\x99\xcc\xcf\xf7\x49\x06\x6d\x1d\x8d\x06\xed\x98\x08\x7b\xe0\x1f\xeb\x88\x41\x85\xb6\x54\xce\x75\x35\xe6\x4a\x4a\xcc\x2d\x34\xb5\x92\xad\x4a\x40\x28\x63\xf6\x40\xec\x28\xd2\x53\x50\xb4\xf4\xa9\x0b\xd6\xff\x8d\xd9\x8f\x2a\xbf\x45\x3b\x1e\x8f\x37\x5c\x16\x6a\x13\x09\xe5\xaf\xda\x88\x9c\x54\xe5\x4a\x40\x9a\xa6\xd0\x46\xd1\x60\x02\x5f\x43\xb0\x31\x14\x4f\x03\x98\xd1\x23\x3d\x4d\xe0\x39\x1c\x2f\x2f\x29\xde\x3f\x87\x20\x66\x35\x0f\x26\xde\x1d\x3a\xc5\x2b\x59\xa1\x31\x6c\x85\x43\x01\x5d\x65\xd4\x83\x8c\xce\x51\x99\x15\xa4\xe0\x0c\x54\x33\x6d\xd0\x93\x44\x54\x8d\x77\x68\x23\xcc\x3a\xb2\x34\x05\xd9\x08\xb1\x07\xa9\x77\x8a\x79\x07\xbf\x03\xf2\xc8\xc7\x9a\xaf\xd2\x14\xa8\x34\x25\x15\x17\xfb\x95\x64\x7c\x5f\x44\x4f\x22\x8a\x0b\xfb\x15\x93\xf9\x10\xcd\x07\xdc\xb0\xf8\x3d\x76\x58\x1c\xf3\xc3\xe2\x01\x86\xae\x67\xf1\x18\x3f\xdf\xe3\x18\xb0\x73\x03\x0f\x70\x93\x4d\x95\xa1\x7e\x8c\x9d\xef\x59\xb4\xec\x9c\xaa\xdf\x49\x3b\x58\x7b\x09\x57\xaf\x26\x0f\x70\x47\xad\xd5\x83\xcc\xa5\xb2\xdb\xf1\xbd\x60\x5b\xca\x99\x60\x64\x55\xfd\xd6\xb5\x18\x46\x97\x2e\xe2\xce\xa0\xe7\x70\xe9\x9a\xc7\x33\x18\xb9\x37\x9a\xe7\x15\xba\x55\x2f\xa7\xd3\xe9\x25\x74\x5f\x5d\xfe\xc4\xc8\x09\x75\x83\xbb\x07\xe4\x31\x4d\x9e\x53\xdc\xff\x1c\x89\x5a\x1e\xbd\x4c\xed\xfb\x67\x48\xd5\xc7\x86\x03\xb1\xe0\x0f\x7f\x80\x93\xd9\x43\x18\xc7\x31\xfc\x17\xa3\x32\x5c\x08\xd7\x3d\x70\x4d\x83\x9e\xbe\xe2\xc6\xb8\x62\xdc\x40\xa1\x24\xb6\x6b\x3e\xed\xda\x3f\x91\xb1\x25\x83\x05\x4c\x8f\x05\xa4\xeb\x70\x10\x16\xce\x44\x8b\x01\xdf\xc3\x40\x70\xb1\x1b\xee\x77\xb0\x92\x57\x08\x5f\xa5\x10\x04\xc3\xc5\x27\x14\x44\xd0\x33\xbb\x30\x68\xdf\x7b\x5b\x8c\xdb\xe8\x78\x2e\x76\x4d\x2e\xe1\x66\x3a\x9d\x4e\x4e\x84\xd8\xed\xd5\xfb\xa6\xa6\xb4\x09\x98\xdc\xba\x2b\xb1\xd7\xad\x4b\x1c\x29\x05\xa2\x2b\x4d\x40\xae\x84\xf0\x39\x4b\xbb\x94\x14\xdc\x36\x4f\x52\x08\xaf\xe6\x67\xa2\xe8\x40\x93\x83\xa3\x1d\x9b\xe7\x8c\xee\x8f\x4d\x74\xa8\xb3\x23\xe2\xf0\xea\xc0\x28\x07\xf6\x3a\x6f\x98\x8b\x5e\x6e\xbe\xd7\xe8\x91\xb9\xf6\xf6\x3a\xd6\xd9\x40\x7e\xcf\xe7\xf9\xd5\x47\x1e\xa3\x9f\xae\x1b\x53\x8e\x8f\x04\x9d\xcc\x4f\x6d\xf3\xce\xa2\xa6\x2c\x59\x51\xc8\x22\x5b\x50\x29\xa0\xf1\xc4\x24\x2e\x55\xd7\x18\x6a\x94\x05\xea\x2e\xa5\xf0\x99\x3d\x25\x80\x07\x26\xf3\x55\xe5\x10\x4e\x9f\xe8\x30\x2e\x25\x53\x12\x01\x00\x8e\x9c\xc0\x01\xf5\x00\xa9\x44\x8c\x82\xd5\x06\x0b\x48\xc1\x7f\x04\x1f\x4f\xa2\x46\xf2\xbb\xf1\x24\x6c\xdf\x8f\x79\x74\xf3\xf3\xbe\x4c\xec\xc4\x7e\x9e\x42\x90\x58\x0d\xbc\x48\x47\x01\x3c\x3f\xe7\x82\x14\x75\x47\x8b\xbd\x04\xc3\xa5\x00\x89\x2d\x16\xae\x0f\xea\xeb\xb5\xbf\x07\x19\xcb\x6f\x57\xae\x10\x9a\x51\xaa\x35\x3e\x61\xcb\xd6\xcc\x32\xed\xb8\x4e\xe6\xb0\x27\x6f\x0b\xc5\x9c\x8c\x33\x07\x5f\x91\xba\x76\x2b\xf4\x9f\x28\xdc\x5b\xa6\x74\x81\x3a\xd4\xac\xe0\x8d\x99\xc1\x8b\xfa\x6e\xfe\xf7\xee\x13\x8e\x6b\x0a\x3f\x2a\x6a\xad\x71\x71\x22\x51\xdb\x65\x7c\x0e\x41\x12\x13\xc1\xef\xb1\xe9\x0f\x3b\xfc\xf8\x0e\x67\x5a\xdf\xd0\x7f\x1a\x6f\xc7\x2b\x5e\x14\x02\x49\xe0\x3d\x7b\x72\x46\xb2\xff\xd0\xa5\x0e\xb7\x84\xb6\xe7\xbd\x5f\xb3\x03\x14\x06\x1f\x59\xd0\xb7\xcf\x47\x04\x80\x90\x8e\xcc\x9d\xce\xdb\x62\xdb\x0d\xeb\x91\xd3\x45\xfb\x53\x8a\xa2\xd1\x2e\xd7\x1a\x87\x2d\xc0\x2e\x61\x64\x28\xf7\x2b\xcc\x68\x12\x95\x4d\xc5\x24\xff\x0d\xc7\x14\x97\x26\x5e\x57\xae\x1f\x1f\x9c\x5e\xc9\x27\xc2\xec\x1b\xe5\xa3\x2e\xc6\x8d\x5a\x25\x8e\x3a\xeb\xbe\xd8\xd7\xf6\x33\x98\xce\x47\x9f\xa8\xa1\xf3\xbb\x84\x19\xd3\x30\x7c\x09\xbb\xe0\x0b\x5a\xd1\xee\xdd\x5c\xc6\xf4\xc8\x77\x32\x5c\x7e\x2e\xd5\x26\x1d\xdd\x4c\x7b\x21\xbd\xa1\x9d\x9d\x47\x2d\xd6\x4e\x8c\x41\x52\x76\xae\xb9\x80\x9b\xe9\x97\x90\xd6\x77\x43\x8e\x4e\x60\x35\xaf\xb1\x00\x96\x5b\xbe\xc6\xff\x87\x83\x7c\x01\x25\x7f\xb2\x88\x84\xc3\x4e\x79\x0e\xa6\x07\xf2\xd2\x6c\xaf\xdb\x7f\x25\x7f\x83\xd8\x69\xf8\x39\x04\x67\x0f\xf2\x20\x12\x8f\x08\x8f\x5c\xfb\x61\xbf\x77\x1f\x98\x82\xe3\x98\x42\xd9\x6e\xff\x71\x74\x12\x95\xb6\x12\xe3\x20\xb1\xee\x47\x32\x24\x73\xcf\xc1\x31\xf0\xc3\x87\x29\xdd\xee\xb0\x90\xa1\xfa\x1d\x8f\xea\x2c\x18\x24\x27\x7d\x2d\xd6\x65\x22\xb0\xdb\xff\x96\x28\x8e\xe1\x47\xcb\xb4\x05\x06\x3f\xbd\x83\xa6\x2e\x98\xf5\x9f\x72\x28\x3e\xfa\x4f\x25\xdd\x8f\x8d\x32\xa6\x0d\x2c\x95\xde\x30\x5d\xb4\xfd\x19\x5b\xe2\xd6\x7d\xca\xe9\x52\x3f\x83\xf6\x1d\xdd\x62\x6b\x26\xc6\x27\x75\xdf\xd3\xf1\x28\x1a\x9a\x7c\x34\x89\x90\xe5\xe5\x29\xa1\x8b\x58\xfd\xbe\x29\x7c\xeb\x4a\x80\xf1\xd3\xb1\x2d\xb9\x99\x44\xcc\x5a\x3d\x1e\x1d\x80\x61\x34\x21\xbb\x5e\x0d\x4a\xb2\x7e\x79\x72\xe0\x56\x8f\xf1\xd8\x27\xd3\x7d\x22\xd0\x91\xe7\xc6\x8c\x3d\xae\x46\x97\x03\xde\x87\xb0\x1a\x3d\x1b\xf5\x86\xda\xbb\xf7\xfe\x1c\xe9\x59\x49\x0e\x58\x8f\xc8\xcb\x46\x27\xdb\xb3\xa2\x78\x4b\xfe\x33\x0e\xce\x78\xfa\x31\x3a\x26\xbd\xb2\xfd\x7d\xfd\xa8\x96\xfd\xcf\x32\x1e\x50\x31\x2f\x46\x93\xc8\x34\x99\xef\x4d\x8c\x5f\xf6\x05\x58\x47\xe6\xc0\x7b\x1c\x0a\x4e\x12\x0a\xda\xe2\x30\xa9\x08\x8f\x92\x90\x47\xa2\x46\xbb\xa5\x3f\xd5\xee\x92\x14\x3e\x9d\xf4\xad\xad\x6f\x0c\x25\x57\xbe\xf5\xbf\xc1\xcc\xb8\x4e\x02\xb4\x78\x77\xdd\x1c\xdf\xb5\x79\xf3\xfd\xbb\x41\xe7\xa6\xf7\x88\xb1\xe3\xde\xff\x0e\xf0\x5c\x9f\xe4\xec\x0f\x0f\x37\x9b\x4d\xb4\x52\x6a\x25\xfc\x4f\x0e\xfb\x46\x4a\xcc\x6a\x1e\x7d\x30\x01\x30\xb3\x95\x39\x14\xb8\x44\xbd\x18\xb0\x6f\xbb\x2b\x49\xec\x7f\x12\x97\xc4\xfe\x57\xbf\xff\x17\x00\x00\xff\xff\x31\x9f\x54\x5e\x06\x2c\x00\x00")
+var _faucetHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xcc\x3b\xed\x92\xdb\x38\x72\xbf\xc7\x4f\xd1\xcb\xd8\x27\x29\x1e\x92\x9a\x19\xdb\xe7\x48\xa4\xb6\x7c\xbe\xbd\x8b\x53\xc9\xde\xd6\xae\x2f\xc9\xd5\xde\x55\x0a\x22\x5b\x22\x3c\x20\xc0\x05\x40\x69\xb4\x53\x7a\xf7\x54\x03\x24\x45\x4a\x9a\x59\xef\x8e\x53\x15\xff\xd0\x90\x40\xa3\xbb\xd1\x5f\xe8\x6e\xd0\xc9\x57\x7f\xfc\xcb\xfb\x8f\x7f\xfb\xee\x1b\x28\x6c\x29\x16\xcf\x12\xfa\x03\x82\xc9\x75\x1a\xa0\x0c\x16\xcf\x2e\x92\x02\x59\xbe\x78\x76\x71\x91\x94\x68\x19\x64\x05\xd3\x06\x6d\x1a\xd4\x76\x15\xbe\x0d\x0e\x13\x85\xb5\x55\x88\x3f\xd5\x7c\x93\x06\xff\x1d\xfe\xf5\x5d\xf8\x5e\x95\x15\xb3\x7c\x29\x30\x80\x4c\x49\x8b\xd2\xa6\xc1\x87\x6f\x52\xcc\xd7\xd8\x5b\x27\x59\x89\x69\xb0\xe1\xb8\xad\x94\xb6\x3d\xd0\x2d\xcf\x6d\x91\xe6\xb8\xe1\x19\x86\xee\xe5\x12\xb8\xe4\x96\x33\x11\x9a\x8c\x09\x4c\xaf\x82\xc5\x33\xc2\x63\xb9\x15\xb8\xb8\xbf\x8f\xbe\x45\xbb\x55\xfa\x76\xbf\x9f\xc1\xbb\xda\x16\x28\x2d\xcf\x98\xc5\x1c\xfe\xc4\xea\x0c\x6d\x12\x7b\x48\xb7\x48\x70\x79\x0b\x85\xc6\x55\x1a\x10\xeb\x66\x16\xc7\x59\x2e\x3f\x99\x28\x13\xaa\xce\x57\x82\x69\x8c\x32\x55\xc6\xec\x13\xbb\x8b\x05\x5f\x9a\xd8\x6e\xb9\xb5\xa8\xc3\xa5\x52\xd6\x58\xcd\xaa\xf8\x26\xba\x89\x7e\x1f\x67\xc6\xc4\xdd\x58\x54\x72\x19\x65\xc6\x04\xa0\x51\xa4\x81\xb1\x3b\x81\xa6\x40\xb4\x01\xc4\x8b\xdf\x46\x77\xa5\xa4\x0d\xd9\x16\x8d\x2a\x31\x7e\x15\xfd\x3e\x9a\x3a\x92\xfd\xe1\xc7\xa9\x12\x59\x93\x69\x5e\x59\x30\x3a\xfb\x6c\xba\x9f\x7e\xaa\x51\xef\xe2\x9b\xe8\x2a\xba\x6a\x5e\x1c\x9d\x4f\x26\x58\x24\xb1\x47\xb8\x78\x12\xee\x50\x2a\xbb\x8b\xaf\xa3\x57\xd1\x55\x5c\xb1\xec\x96\xad\x31\x6f\x29\xd1\x54\xd4\x0e\x7e\x31\xba\x0f\xe9\xf0\xd3\xb1\x0a\xbf\x04\xb1\x52\x95\x28\x6d\xf4\xc9\xc4\xd7\xd1\xd5\xdb\x68\xda\x0e\x9c\xe2\x77\x04\x48\x69\x44\xea\x22\xda\xa0\x26\xcb\x15\x61\x86\xd2\xa2\x86\x7b\x1a\xbd\x28\xb9\x0c\x0b\xe4\xeb\xc2\xce\xe0\x6a\x3a\x7d\x31\x3f\x37\xba\x29\xfc\x70\xce\x4d\x25\xd8\x6e\x06\x2b\x81\x77\x7e\x88\x09\xbe\x96\x21\xb7\x58\x9a\x19\x78\xcc\x6e\x62\xef\x68\x56\x5a\xad\x35\x1a\xd3\x10\xab\x94\xe1\x96\x2b\x39\x23\x8b\x62\x96\x6f\xf0\x1c\xac\xa9\x98\x3c\x59\xc0\x96\x46\x89\xda\xe2\x11\x23\x4b\xa1\xb2\x5b\x3f\xe6\xbc\xb9\xbf\x89\x4c\x09\xa5\x67\xb0\x2d\x78\xb3\x0c\x1c\x21\xa8\x34\x36\xe8\xa1\x62\x79\xce\xe5\x7a\x06\x6f\xaa\x66\x3f\x50\x32\xbd\xe6\x72\x06\xd3\xc3\x92\x24\x6e\xc5\x98\xc4\x3e\x70\x3d\xbb\x48\x96\x2a\xdf\x39\x1d\xe6\x7c\x03\x99\x60\xc6\xa4\xc1\x91\x88\x5d\x40\x1a\x00\x50\x1c\x62\x5c\xb6\x53\x83\x39\xad\xb6\x01\x38\x42\x69\xe0\x99\x08\x97\xca\x5a\x55\xce\xe0\x8a\xd8\x6b\x96\x1c\xe1\x13\xa1\x58\x87\x57\xd7\xed\xe4\x45\x52\x5c\xb5\x48\x2c\xde\xd9\xd0\xe9\xa7\xd3\x4c\xb0\x48\x78\xbb\x76\xc5\x60\xc5\xc2\x25\xb3\x45\x00\x4c\x73\x16\x16\x3c\xcf\x51\xa6\x81\xd5\x35\x92\x1d\xf1\x05\xf4\xc3\xdf\x03\xd1\xaf\xb8\x6a\xf9\x8a\x73\xbe\x59\x3c\x3b\x7e\x3c\xda\xe1\xc3\x9b\x78\x0b\xcd\x83\x5a\xad\x0c\xda\xb0\xb7\xa7\x1e\x30\x97\x55\x6d\xc3\xb5\x56\x75\xd5\xcd\x5f\x24\x6e\x14\x78\x9e\x06\xb5\x16\x41\x13\xfe\xdd\xa3\xdd\x55\x8d\x28\x82\x6e\xe3\x4a\x97\x21\x69\x42\x2b\x11\x40\x25\x58\x86\x85\x12\x39\xea\x34\xf8\x41\x65\x9c\x09\x90\x7e\xcf\xf0\xd7\xef\xff\x1d\x1a\x95\x71\xb9\x86\x9d\xaa\x35\x7c\x63\x0b\xd4\x58\x97\xc0\xf2\x9c\xcc\x35\x8a\xa2\x20\x3e\x70\xe2\x8c\xf7\x94\xd7\x70\x69\xe5\x81\xdf\x8b\x64\x59\x5b\xab\x3a\xc0\xa5\x95\xb0\xb4\x32\xcc\x71\xc5\x6a\x61\x21\xd7\xaa\xca\xd5\x56\x86\x56\xad\xd7\x02\xdb\x5d\xf8\x45\x01\xe4\xcc\xb2\x66\x2a\x0d\x5a\xd8\x56\x89\xcc\x54\xaa\xaa\xab\x46\x8d\x7e\x10\xef\x2a\x26\x73\xcc\x49\xe9\xc2\x60\xb0\xf8\x33\xdf\x20\x94\xe8\x37\x73\x71\x6c\x13\x19\xd3\x68\xc3\x3e\xd2\x13\xcb\x48\x62\xcf\x8c\xdf\x12\x34\xff\x92\x5a\xb4\x98\xba\x2d\x94\x28\x6b\x18\xbc\x85\x9a\x02\x4b\xb0\xb8\xbf\xd7\x4c\xae\x11\x9e\xf3\xfc\xee\x12\x9e\xb3\x52\xd5\xd2\xc2\x2c\x85\xe8\x9d\x7b\x34\xfb\xfd\x00\x3b\x40\x22\xf8\x22\x61\x8f\xd9\x37\x28\x99\x09\x9e\xdd\xa6\x81\xe5\xa8\xd3\xfb\x7b\x42\xbe\xdf\xcf\x21\x63\x32\xe7\x39\xb3\x98\x3a\x11\xcc\xe1\xfe\x9e\xaf\xe0\x79\xf4\x3d\x66\xac\xb2\x59\xc1\xf6\xfb\xb5\x6e\x9f\x23\xbc\xc3\xac\xb6\x38\x9e\xdc\xdf\xa3\x30\xb8\xdf\x9b\x7a\x59\x72\x3b\x6e\xf1\xd1\xb8\xcc\xf7\x7b\xda\x44\xc3\xf8\x7e\x0f\x31\x21\x95\x39\xde\xc1\xf3\xe8\x3b\xd4\x5c\xe5\x06\x3c\x7c\x12\xb3\x45\x12\x0b\xbe\x68\xd6\x39\xe2\xd1\xfb\x96\xa7\x73\x1b\x05\xad\x68\x93\x06\x2b\xa6\x99\x55\xba\x33\xdf\x9c\x6f\x78\x4e\x11\xc4\x21\x7c\xaa\x84\xa6\x7d\xd1\x90\x7e\x7f\xa3\x64\xa6\x7d\x91\xf4\x77\x76\x40\x0f\x39\xba\x78\x7e\x56\x4e\xd3\x53\x21\x0d\x4d\x2b\xae\xc5\xc1\xcb\x62\x72\xb3\xf6\xd5\x47\x1b\x2f\xd2\xef\x71\xcd\x8d\x45\xdd\xac\x1e\x84\x06\xed\xe6\x34\xa3\x03\xa5\x8d\x11\xc3\xb1\x5f\x0c\x16\xc3\xf0\x6c\x55\x35\x83\xb7\x14\x9a\x8f\xa2\x08\x5f\x4b\xcc\x7b\x1b\xef\x53\x01\xab\x99\x34\x2c\xf3\xcf\x0a\xbc\xfc\x48\x2b\x08\xb6\x38\x08\x89\x1b\x58\xd5\xe4\xb4\x30\x56\x15\x01\x33\x31\xf1\xb1\x66\x60\x43\x3d\x2d\x9d\x89\x96\xeb\xb0\xd3\x5c\x13\x36\x0c\xb7\x78\x8b\xbb\x34\xb8\xbf\xef\xaf\x6d\x66\x33\x26\xc4\x92\x91\x71\x78\xb6\xba\x45\x3f\x23\x85\xb3\x0d\x37\x2e\xf5\x5e\xb4\x22\x3f\xe8\xe9\x33\xc3\xff\x39\x09\xde\x5c\xf7\x4e\xb7\x73\x27\xc3\x9b\xa3\x93\xe1\xe6\x2c\x70\xc5\x24\x0a\x70\xbf\xa1\x29\x99\x68\x9f\x9b\xa0\xda\x3b\x2d\x8e\x17\x85\x74\x96\x77\xac\x75\x39\xc1\x74\x0e\x6a\x83\x7a\x25\xd4\x76\x06\xac\xb6\x6a\x0e\x25\xbb\xeb\xf2\xa2\x9b\xe9\xb4\xcf\xf7\xc5\x45\x62\xd9\x52\x60\x63\x6a\x3f\xd5\x68\xac\xe9\xcc\xc8\x4f\xb9\x5f\xb2\xa6\x1c\xa5\xc1\xfc\x48\x1a\x44\x91\x44\xeb\xa0\x7a\xb6\xde\x09\xf3\x2c\xef\x2b\xa5\xba\x54\xa3\xcf\x46\x83\xba\x97\x15\x05\x8b\xc4\xea\x03\xdc\x45\x62\xf3\x5f\x95\x2a\x68\x63\x1e\xce\x14\xfc\xc1\x47\x7b\xaf\x10\xb5\xcf\x43\xc9\x47\xc1\xbd\x26\xb1\xcd\x9f\x40\x99\x8c\x70\xc9\x0c\x7e\x0e\x79\x97\x11\x1e\xc8\xbb\xd7\xa7\xd2\x2f\x90\x69\xbb\x44\x66\x3f\x87\x01\xf2\xda\xde\xfe\xdd\x11\xfb\x54\x06\x6a\xc9\x37\xa8\x0d\xb7\xbb\xcf\xe5\x00\xf3\x03\x0b\xfe\x7d\xc8\x42\x12\x5b\xfd\xb8\xad\xf5\x5f\xbe\x90\x73\xff\x52\xea\x7a\xb3\xf8\x57\xb5\x85\x5c\xa1\x01\x5b\x70\x03\x94\x84\x7d\x9d\xc4\xc5\x4d\x07\x52\x2d\x3e\xd2\x84\x13\x2a\xac\x5c\x0a\x4a\x91\x52\xd7\xd2\x65\x68\x14\x52\x0b\x1c\xa6\xad\x4d\x32\x17\xc1\x47\x45\xa9\xff\x06\xa5\x85\x92\x09\x9e\x71\x55\x1b\x60\x99\x55\xda\xc0\x4a\xab\x12\xf0\xae\x60\xb5\xb1\x84\x88\xc2\x07\xdb\x30\x2e\x9c\x2f\x39\x95\x82\xd2\xc0\xb2\xac\x2e\x6b\x2a\x5d\xe4\x1a\x50\xaa\x7a\x5d\x34\xbc\x58\x05\x3e\x7f\x11\x4a\xae\x3b\x7e\x4c\xc5\x4a\x60\xd6\xb2\xec\xd6\x5c\x42\x1b\x15\x80\x69\x04\xcb\x31\xa7\x55\x99\x2a\x4b\x25\xe1\x46\xe7\x50\x31\x6d\x77\x60\x86\x39\x28\xcb\x32\xc2\x6b\x22\x78\x27\x77\x4a\x22\x14\x6c\xe3\x38\x84\x8f\xbe\xec\x24\xbe\xfe\xc4\x32\x5c\x2a\xd5\x41\x43\xc9\x76\x2d\xb9\x86\xfb\x2d\xb7\x05\xf7\xe2\xa9\x50\x97\xb4\x34\x07\xc1\x4b\x6e\x4d\x94\xc4\xd5\x21\xa2\x1e\x52\x38\x11\x16\x4a\xf3\x9f\x29\x01\x16\xfd\xf0\x69\x8f\x82\x4b\x1b\x1b\x9d\xd6\x05\xae\xec\x0c\x5e\xf9\xd8\x78\x6c\xc7\x4d\xa5\x7c\xce\x88\x5b\x9c\xae\x03\x41\x07\xce\x0c\x6e\x7c\xd9\xe3\xf3\xcd\xdc\xf6\x38\xc8\x8f\x4c\xcd\x13\x7d\x4b\x67\x31\x1c\xd7\x4e\xd3\x0e\x09\x59\xc0\x50\x28\x1b\xde\x89\xf1\x12\x4a\x76\x8b\xc0\x20\x61\x47\x9d\x94\x86\x69\x57\x87\x73\xd7\x47\x8a\xed\x16\xd1\x7e\x4d\xae\x9b\x7e\xef\x11\x72\xb9\x7e\x71\x3d\xf5\x16\x49\x0f\x84\xfe\xc5\xf5\x94\x4b\xab\x5e\x5c\x4f\xa7\x77\xd3\xcf\xfc\xf7\xe2\x7a\xaa\xe4\x8b\xeb\xa9\x2d\xf0\xc5\xf5\xf4\xc5\xf5\x4d\xdf\x96\xfd\x48\x5b\x81\x10\x14\x1a\xa2\xd6\x9a\x78\x00\x96\xe9\x35\x35\xd2\xfe\x87\x2d\x55\x6d\x67\x4b\xc1\xe4\x6d\xb0\x70\xec\x52\x7a\xe5\xac\xe0\x7c\x1d\x03\x15\x33\x64\x12\xc4\xb1\xb3\x92\xa6\x67\x66\x60\x6c\x6a\xad\x55\x2d\xe9\x54\x04\xda\xb3\xf3\x50\x39\x22\x2b\x23\xc1\x4c\xa2\x64\xa9\xe3\xc5\x7b\x55\xed\x42\x87\xc4\x2d\x3f\x11\xa3\xa9\x2b\x6a\xc6\x45\x7d\x71\x32\xaa\x97\x05\x9a\xf8\xed\xf4\xf5\xdb\x37\x8f\xb2\x6f\xa8\x1a\x73\x7b\xe8\x38\x64\x4b\xb5\x41\xf0\x09\xde\x52\xdd\x01\x93\x39\xac\xb8\x46\x60\x5b\xb6\xfb\x2a\x89\x73\x57\xa9\x3f\xdd\x6a\x57\x8d\x77\xfd\xbf\x32\xdb\xd6\xe5\x2f\xa1\xaa\x97\x82\x9b\x02\x18\x48\xdc\x42\x62\xac\x56\x72\xbd\x70\xa3\x59\x12\x37\xaf\x50\x29\x63\x1f\x53\x3f\x96\x4b\xcc\xf3\x33\x06\xf0\xa5\xf4\xbf\xdd\x6e\xa3\x56\x92\x4e\xf9\x05\x8a\x2a\xa6\xf0\x57\x4b\x6e\x77\xb1\x77\x23\x25\xe3\xaf\x79\x9e\x5e\xbf\xbd\x7e\xf3\xe6\xfa\xd5\xbf\xbc\x7d\xfd\xfa\xfa\xed\xab\xd7\x0f\x59\x06\x6d\xea\x89\x86\xe1\xd3\xe8\x6f\x15\x75\x37\xba\x1c\xda\xdb\x4b\x9b\xbb\xd1\x09\x9d\x53\xa9\xaa\x83\xdf\x6c\x43\xb5\xa4\x44\x24\x64\xc2\x3e\xd1\x8a\x9c\x19\x3d\xc2\xd9\x13\x4d\xab\x35\x1f\xb2\x14\x55\x5b\x60\x87\xa6\x0f\x57\xb2\x33\xa7\x4b\x30\xbc\xac\xc4\x0e\xb2\x83\xd6\xcf\xdb\xd5\x83\x4a\xf9\x45\xb3\x1a\xaa\xcd\x1b\x99\x3b\xfd\x4b\x95\x23\x9d\xfa\xa6\x36\x19\x56\xee\x36\x80\x4e\xd2\x3f\xec\x7e\x66\xd2\x72\x89\xed\x89\x1b\xc1\x5f\xa4\xd8\x41\x6d\x10\x56\x4a\x43\x8e\xcb\x7a\xbd\x26\x6a\x4a\x43\xa5\xf9\x86\x59\x6c\x8f\x59\xd3\x58\x45\x67\x14\xbd\xca\x86\x52\x9e\xae\xf6\x3c\xa9\xdc\x93\x6a\xf1\x9f\x4c\xd0\x8b\xd2\x87\x92\xcf\x0c\x4e\x60\x06\x47\x35\xf1\x5a\x33\x69\x21\xa3\xf2\xc2\xed\x9e\xbc\xed\xa4\x4c\xa6\xed\xdb\xe2\xa8\x7a\x5c\x21\x9a\xe8\xb8\xd6\x85\xef\x0e\x0a\x30\xbe\xf8\x4c\x32\x95\xe3\x02\x7f\xaa\x99\xe0\x76\x37\xbb\x9a\xb9\xc4\x67\xd6\x51\x49\x62\x07\x30\xa8\x46\x3b\x55\x19\xa4\x1a\xe5\xc8\x81\x68\xa2\xc9\xb8\xb6\x5c\x88\xb6\x70\x75\xb5\xab\x27\xbd\xc4\x82\x89\x15\x30\x03\x46\x29\x49\x7f\xcf\x16\xb4\x51\x23\x5d\xca\x3a\x8e\x04\x5d\x2d\xfe\xa6\x6a\x12\x05\xf1\x95\xdd\x7a\xc1\xd4\x5a\x53\x18\xaa\xd0\x9b\x4a\x97\x45\x2d\x51\xa8\xad\x03\xf1\x8c\xae\x38\x0a\x97\x52\x19\x44\x28\xd4\x16\xca\x3a\x73\xd1\x8e\x52\x26\x67\x21\x5b\xc6\x2d\xd4\xd2\x72\xe1\x19\xb6\xb5\x96\x94\x80\xe1\x20\x05\x3a\x29\xac\x13\x2c\x17\x1f\x0b\x3c\x93\x6f\x76\x25\x31\x68\x7c\xef\xc1\xa1\xd2\xca\xa2\x17\x28\x5b\x33\x2e\x0d\x89\xd0\x25\x59\x58\x7e\x46\xc9\xdc\x3d\x35\x0f\x87\x6b\x02\x37\x1d\xc7\xf0\x67\xa1\x96\x4c\xc0\x86\xc2\xc8\x52\xa0\xa1\x9d\x51\xeb\x61\x20\x2d\x63\x99\xad\x0d\xa8\x55\x4f\x6f\xb4\x7e\xc3\x34\xb9\x07\x96\x95\x85\xb4\x69\x72\xd3\x98\x41\xbd\x41\xdd\xbd\x5a\x8e\x7a\x30\x7f\x30\xcf\x14\x7c\x07\xad\x9d\xe9\xf4\x91\xc2\x8f\xff\x98\x3f\x6b\x98\xfc\x23\xae\x9c\x27\x92\xce\xbd\x30\x6c\xc1\x2c\x64\x1a\x9d\x77\x64\x42\x99\x5a\x7b\xde\xa9\x39\x08\xc4\x7f\x8b\xa9\xc5\x4c\x13\x95\xe3\xa3\x45\x32\x2e\x98\x29\x26\x4d\xf7\x5e\xa3\xd3\x5f\x37\xd7\x8e\x5f\x90\xb3\x8f\x09\x01\xa7\xf6\x16\x4f\x5a\xbc\x91\x40\xb9\xb6\xc5\x1c\xf8\xcb\x97\x1d\xf0\x05\x5f\xc1\xb8\x85\xf8\x91\xff\x23\xb2\x77\x11\x51\x81\x34\x85\x3e\x35\x47\xb0\xc1\x63\x2a\xc1\x33\x1c\xf3\x4b\xb8\x9a\xcc\xdb\xd9\xa5\x46\x76\xdb\xbe\xed\x9f\xf5\xfe\xb8\xdf\xfd\x7c\x28\x19\xa7\x96\x81\x6c\xbc\x43\x19\x60\x40\x7e\x0d\xb5\x16\xd0\xf9\x23\x29\xa7\x53\x95\x83\xeb\x4b\xe5\xc4\x62\x9b\x87\xc6\xda\xda\x2d\x78\x6d\xf5\x82\x49\x0a\xc7\x91\xe4\xa0\xe6\xdf\xfd\x0e\x9e\x8f\x83\x7f\xea\xc3\x07\x93\x1f\xa7\xff\x88\x36\x4c\xd4\x08\x5f\x3f\x3a\x3b\x83\x86\x34\x39\x3c\x6d\x37\xf7\x82\xf1\x1b\x89\x0c\xca\x7c\xfc\x6f\x3f\xfc\xe5\xdb\xc8\x58\x0a\x80\x7c\xb5\x1b\xdf\xd7\x5a\xcc\x1c\x52\xea\xdb\x1f\x70\x5d\x3a\x5b\x9c\xb9\xdf\xcb\x83\x19\xce\x0e\x8f\x97\x83\x4d\xcd\x06\x6f\x27\x92\xb9\x84\xe6\x71\x06\x43\x21\xed\x27\x93\xf9\xf9\x96\x5a\xaf\xfb\xa9\xd1\xa0\x1d\x4f\xe6\xcd\x9a\x73\x7a\x65\x50\xa2\x2d\x94\x0b\x44\x9a\xe2\xa8\xc4\xcc\x42\x5d\x29\xd9\xa8\x11\x84\x32\xe6\xe0\x3c\x2d\x44\x7a\x6a\xc8\x0d\x7c\xea\xf2\xba\xff\xc2\xe5\x0f\x2a\xbb\x45\x3b\x1e\x8f\xb7\x5c\xe6\x6a\x1b\x09\xe5\x4f\x65\xba\x3a\xb3\x2a\x53\x02\xd2\x34\x85\x26\xe1\x0a\x26\xf0\x35\x04\x5b\x43\xa9\x57\x00\x33\x7a\xa4\xa7\x09\xbc\x84\xe3\xe5\x85\x32\x16\x5e\x42\x10\xb3\x8a\x07\x93\xf9\xb3\x1e\xf1\x48\xc9\x12\x8d\x61\x6b\xec\x33\xe8\xce\x92\xce\x31\x68\x1f\xa5\x59\x43\x0a\x4e\xa5\x15\xd3\x06\x3d\x48\x44\x8d\x9b\xd6\x43\xc8\xcf\x1c\x58\x9a\x82\xac\x85\xe8\xd6\x37\x8e\x3c\x6f\x5d\x66\x00\x1e\xf9\xb4\xe4\xab\x34\x85\xce\x96\x0e\x2b\xc9\x5c\x1c\x40\x30\x89\x28\x85\x38\xac\x98\xcc\xfb\x1e\x38\xc0\x86\xf9\x2f\xa1\xc3\xfc\x18\x1f\xe6\x0f\x20\x74\xed\xad\xc7\xf0\x39\x80\x3e\x3a\x37\xf0\x00\x36\x59\x97\x4b\xd4\x8f\xa1\xf3\xed\xad\x06\x9d\x13\xf5\x07\x69\x7b\x6b\x2f\xe1\xea\xcd\xe4\x01\xec\xa8\xb5\x7a\x10\x39\x5d\x8d\x8f\xef\x05\xdb\x51\x7a\x0d\x23\xab\xaa\xf7\xae\x1b\x35\xba\x74\xc9\xd9\x0c\x3a\x0c\x97\xae\x4f\x3e\x83\x91\x7b\xa3\x79\x5e\xa2\x5b\xf5\x7a\x3a\x9d\x5e\x42\x7b\x91\xfb\x07\x46\x6e\xab\x6b\xdc\x3f\xc0\x8f\xa9\xb3\x0c\x8d\x79\x12\x47\x0d\x8e\x8e\xa7\xe6\xfd\x09\x5c\x75\xe7\xd9\x80\x2d\x8a\x87\x27\xb3\x43\x33\x8e\x63\xf8\x0f\x46\x1d\x1b\x6a\x3d\x6b\xdc\xb8\xfe\x52\x07\x5f\x72\x63\x5c\xdf\xc6\x40\xae\x24\x3e\xbb\xf8\x0d\x47\xd5\x09\x8f\x0d\x18\x2c\x60\x7a\xcc\xe0\x8f\xd3\xc1\x51\x76\xe6\x84\xeb\xe1\x1d\x1e\x5e\x17\xfb\x3e\xbd\xc1\x4a\x5e\x22\x7c\x95\x42\x10\xf4\x17\x9f\x40\x10\x40\x87\xec\xc2\xa0\xfd\xe8\x75\x31\x6e\x4e\xf4\x73\xe7\xed\xe4\x92\x3a\xea\xd3\xc9\x09\x13\xfb\x83\x78\xdf\x55\x94\x04\x02\x93\x3b\x17\x12\x3b\xd9\xba\xc4\x95\x12\x3a\x0a\x69\x82\x6e\x0b\x84\xcf\xc0\x9a\xa5\x2e\x81\xf1\x7d\xb6\x14\xc2\xab\xf9\x99\x93\xbf\x27\xc9\xde\xd6\x8e\xd5\x73\x46\xf6\xc7\x2a\x1a\xca\xec\x08\x38\xbc\x1a\x28\x65\xa0\xaf\xf3\x8a\xb9\xe8\xf8\xe6\x07\x89\x1e\xa9\xeb\xa0\xaf\x63\x99\xf5\xf8\xf7\x78\x5e\x5e\x7d\xe6\x36\xba\xe9\xaa\x36\xc5\xf8\x88\xd1\xc9\xfc\x54\x37\x1f\x2c\x6a\x66\xd1\x5d\x99\x38\x5d\x50\xd5\xa8\xf1\x44\x25\xae\x96\xd0\x18\x6a\x94\x39\xea\x36\x0d\xf2\x45\x20\xa5\xb3\x03\x95\xf9\x06\x44\xdf\x9c\x7e\xa5\xc3\x10\x24\xb9\x1b\x00\x40\x0a\xa7\x86\xda\x47\xed\x80\x51\xb0\xca\x60\x0e\x29\xf8\xef\x6a\xc6\x93\xa8\x96\xfc\x6e\x3c\x09\x9b\xf7\x63\x1c\xed\xfc\xbc\xeb\x28\xb4\x6c\xbf\x4c\x21\x48\xac\xa6\x96\xfc\x28\x80\x97\xe7\x5c\x90\x4e\xdd\xd1\x22\x98\x9f\x5b\x0a\x90\xd8\x7c\xe1\x5a\xe6\xbe\xb4\xff\x7b\x40\x57\x73\x6b\x57\x33\xcf\x28\x3d\x1c\x9f\xa0\x65\x1b\x66\x99\x76\x58\x27\x73\x38\x80\x37\x3d\x05\x57\x70\xce\xc1\x37\x2f\x5c\x67\x1e\xba\xdb\x2c\xf7\xb6\x54\x3a\x47\x1d\x6a\x96\xf3\xda\xcc\xe0\x55\x75\x37\xff\x7b\x7b\xdb\xe7\xee\x0f\x1e\x65\xb5\xd2\xb8\x38\xe1\xa8\x69\x48\xbf\x84\x20\x89\x09\xe0\x97\xd0\x74\x9b\xed\x7f\xcf\x03\x67\x6e\x49\xa0\xfb\xda\xa6\x19\x2f\x79\x9e\x0b\x24\x86\x0f\xe8\xc9\x19\x49\xff\x7d\x97\x1a\x92\x84\xa6\x2a\x3d\xac\xd9\x03\x0a\x83\x8f\x2c\xe8\x6e\x5a\x46\x64\x00\x21\x6d\x99\x3b\x99\x37\x7d\x19\x37\xac\x47\x4e\x16\xcd\xd7\x59\x79\xed\xf3\xd1\x71\xd8\x18\xd8\x25\x8c\x7c\x95\x6d\x46\x93\xa8\xa8\x4b\x26\xf9\xcf\x38\xa6\x73\x69\xe2\x65\x45\x34\x7a\x1b\xd9\x3f\x7b\x88\x99\xc3\x9d\xca\xa8\x3d\xe3\x46\x8d\x10\x47\xad\x76\x5f\x1d\xda\x40\x74\xcb\x38\xfa\x95\x12\x3a\x4f\x25\x5c\x32\x0d\xfd\x97\xb0\x3d\x7c\xfd\xb7\x0b\x1d\xe0\x92\xe9\x91\x6f\x7a\xb9\x8c\x5e\xaa\x6d\x3a\xba\x99\x76\x4c\x7a\x45\x3b\x3d\x8f\x1a\x5b\x3b\x51\x06\x71\xd9\xba\xe6\x02\x6e\xa6\x5f\x82\x5b\xdf\x38\x3b\xda\x81\xd5\xbc\xc2\x1c\xa8\x23\xb2\xc1\xff\x83\x8d\x7c\x01\x21\xff\x6a\x16\xc9\x0e\x5b\xe1\x39\x33\x1d\xf0\x4b\xb3\x9d\x6c\xff\x99\xfc\x0d\x62\x27\xe1\x97\x10\x9c\xdd\xc8\x83\x96\x78\x04\x78\xe4\xda\x0f\xfb\xbd\xbb\x8b\x0c\x8e\xcf\x14\x5f\x5f\x36\xf7\xe8\x93\x88\xbe\x21\x1e\x07\x89\x75\xdf\xdd\x11\xcf\x1d\x06\x87\xc0\x0f\x0f\x53\xba\xfd\xb0\x90\xa1\x9e\x03\x1e\xd5\x59\xd0\x4b\x4e\xba\x5a\xac\xcd\x44\x60\x7f\xf8\x3c\x31\x8e\xe1\x07\xcb\xb4\x05\x06\x7f\xfd\x00\x75\x45\x85\xa7\xbb\xf5\xa3\xf3\xd1\xdf\xaa\x35\x1a\x80\x25\xa3\x0b\x45\xa5\xb7\x4c\xe7\x4d\xb7\xc9\x16\xb8\x73\xb7\x7e\x6d\xea\x67\xd0\x7e\xa0\x28\xb6\x61\x62\x7c\x52\xf7\x3d\x1f\x8f\xa2\xbe\xca\x47\x93\x08\x59\x56\x9c\x02\xba\x13\xab\xa3\x9b\xc2\xb7\xae\x04\x18\x3f\x1f\xd3\xed\xe9\x24\x62\xd6\xea\xf1\x68\x60\x0c\xa3\x09\xe9\xf5\xaa\x57\x92\x75\xcb\x93\x81\x5b\x3d\x86\xe3\x90\x4c\x4f\xe6\x47\xe0\x99\x31\x63\x6f\x57\xa3\xcb\x1e\xee\xa1\x59\x8d\x5e\x8c\x3a\x45\x1d\xdc\xfb\xb0\x8f\xf4\x2c\x27\x03\xd4\x23\xf2\xb2\xd1\x09\x79\x96\xe7\xef\xc9\x7f\xc6\xc1\x19\x4f\x3f\xb6\x8e\x49\x27\x6c\x1f\xaf\x1f\x95\xb2\xff\x5e\xe9\x01\x11\xf3\x7c\x34\x89\x4c\xbd\xf4\xdd\x8c\xf1\xeb\xae\x00\x6b\xc1\x9c\xf1\x1e\x1f\x05\x27\x09\x05\x91\x18\x26\x15\xe1\x51\x12\xf2\xc8\xa9\x31\x99\xf7\x76\xb5\xbf\x84\x2b\x9f\x4c\x37\xb6\xfb\x8d\xb1\xac\xbd\x25\xda\xe2\xd2\xb8\x4e\x02\x34\xf6\xde\x7c\x89\x44\x46\xfc\xee\xbb\x0f\xbd\x6e\x53\xe7\x11\x63\x87\xbd\xfb\xb4\xf8\x5c\x9f\xe4\xec\xb7\xcc\x74\xe7\xb3\x56\x6a\x2d\xfc\x57\xcc\x5d\x23\x85\x3a\x0d\xf4\xb5\x32\x30\xb3\x93\x19\xe4\xb8\x42\xbd\xe8\xa1\x6f\xba\x2b\x49\xec\xbf\xb2\x4d\x62\xff\x1f\x09\xfe\x77\x00\x0e\x37\xfb\xc6\x59\x30\x00\x00")
 
 func faucetHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -85,7 +85,7 @@ func faucetHtml() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "faucet.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdb, 0xa2, 0x98, 0x44, 0x4b, 0x50, 0xf8, 0xa1, 0xac, 0x4a, 0x76, 0x2e, 0xcc, 0x3d, 0xcb, 0x81, 0x9e, 0x2a, 0xaa, 0x87, 0xf5, 0x9d, 0x53, 0x4, 0x8a, 0xdd, 0x5a, 0xfe, 0xd3, 0xc3, 0xf, 0x11}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x31, 0x85, 0x4c, 0x6b, 0x12, 0xa2, 0xc1, 0xeb, 0x28, 0xc5, 0x10, 0xd5, 0xca, 0x7f, 0x1, 0xa5, 0x90, 0x21, 0x47, 0xd1, 0xa5, 0xba, 0xa1, 0x59, 0x9a, 0xa8, 0x61, 0xea, 0x70, 0xda, 0x3d, 0x2f}}
 	return a, nil
 }
 
@@ -31,6 +31,21 @@ import (
 	"github.com/SecretBlockChain/go-secret/params"
 )
 
+// errEqualityUnsupported is returned when converting an equality genesis into the
+// chain spec format of a client not implementing the engine.
+var errEqualityUnsupported = errors.New("equality consensus engine unsupported, use the secret chain spec")
+
+// newSecretChainSpec wraps a genesis block into the chain spec format loaded by
+// secret through --chainspec. The equality section is part of the chain config,
+// so it is carried as is for the other tooling to consume.
+func newSecretChainSpec(network string, genesis *core.Genesis, bootnodes []string) *core.ChainSpec {
+	return &core.ChainSpec{
+		Name:      network,
+		Bootnodes: bootnodes,
+		Genesis:   genesis,
+	}
+}
+
 // alethGenesisSpec represents the genesis specification format used by the
 // C++ Ethereum implementation.
 type alethGenesisSpec struct {
@@ -97,6 +112,9 @@ type alethGenesisSpecLinearPricing struct {
 // chain specification format.
 func newAlethGenesisSpec(network string, genesis *core.Genesis) (*alethGenesisSpec, error) {
 	// Only ethash is currently supported between go-ethereum and aleth
+	if genesis.Config.Equality != nil {
+		return nil, errEqualityUnsupported
+	}
 	if genesis.Config.Ethash == nil {
 		return nil, errors.New("unsupported consensus engine")
 	}
@@ -365,6 +383,9 @@ type parityChainSpecVersionedPricing struct {
 // chain specification format.
 func newParityChainSpec(network string, genesis *core.Genesis, bootnodes []string) (*parityChainSpec, error) {
 	// Only ethash is currently supported between go-ethereum and Parity
+	if genesis.Config.Equality != nil {
+		return nil, errEqualityUnsupported
+	}
 	if genesis.Config.Ethash == nil {
 		return nil, errors.New("unsupported consensus engine")
 	}
@@ -608,6 +629,9 @@ type pyEthereumGenesisSpec struct {
 // chain specification format.
 func newPyEthereumGenesisSpec(network string, genesis *core.Genesis) (*pyEthereumGenesisSpec, error) {
 	// Only ethash is currently supported between go-ethereum and pyethereum
+	if genesis.Config.Equality != nil {
+		return nil, errEqualityUnsupported
+	}
 	if genesis.Config.Ethash == nil {
 		return nil, errors.New("unsupported consensus engine")
 	}
@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -93,3 +95,39 @@ func TestParitySturebyConverter(t *testing.T) {
 		t.Fatalf("chainspec mismatch")
 	}
 }
+
+// Tests that equality genesis blocks are exported in the secret chain spec format
+// only, the third-party formats being unable to represent the engine.
+func TestSecretChainSpec(t *testing.T) {
+	genesis := core.DefaultTestnetGenesisBlock()
+	if _, err := newAlethGenesisSpec("testnet", genesis); err != errEqualityUnsupported {
+		t.Fatalf("aleth conversion error mismatch: have %v, want %v", err, errEqualityUnsupported)
+	}
+	if _, err := newParityChainSpec("testnet", genesis, nil); err != errEqualityUnsupported {
+		t.Fatalf("parity conversion error mismatch: have %v, want %v", err, errEqualityUnsupported)
+	}
+	dir, err := ioutil.TempDir("", "puppeth-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	bootnodes := []string{"enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"}
+	saveGenesis(dir, "testnet", "secret", newSecretChainSpec("testnet", genesis, bootnodes))
+
+	spec, err := core.LoadChainSpec(filepath.Join(dir, "testnet-secret.json"))
+	if err != nil {
+		t.Fatalf("failed to load chain spec: %v", err)
+	}
+	if spec.Name != "testnet" || !reflect.DeepEqual(spec.Bootnodes, bootnodes) {
+		t.Errorf("chain spec metadata mismatch: have %s %v", spec.Name, spec.Bootnodes)
+	}
+	have, _ := json.Marshal(spec.Genesis.Config.Equality)
+	want, _ := json.Marshal(genesis.Config.Equality)
+	if !bytes.Equal(have, want) {
+		t.Errorf("equality config mismatch: have %s, want %s", have, want)
+	}
+	if have, want := spec.Genesis.ToBlock(nil).Hash(), genesis.ToBlock(nil).Hash(); have != want {
+		t.Errorf("genesis hash mismatch: have %x, want %x", have, want)
+	}
+}
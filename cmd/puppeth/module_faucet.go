@@ -46,6 +46,7 @@ ENTRYPOINT [ \
 	"--faucet.name", "{{.FaucetName}}", "--faucet.amount", "{{.FaucetAmount}}", "--faucet.minutes", "{{.FaucetMinutes}}", "--faucet.tiers", "{{.FaucetTiers}}",             \
 	"--account.json", "/account.json", "--account.pass", "/account.pass"                                                                                                    \
 	{{if .CaptchaToken}}, "--captcha.token", "{{.CaptchaToken}}", "--captcha.secret", "{{.CaptchaSecret}}"{{end}}{{if .NoAuth}}, "--noauth"{{end}}                          \
+	{{if .Candidate}}, "--faucet.candidate"{{end}}{{if .Register}}, "--faucet.register"{{end}}                                                                              \
 ]`
 
 // faucetComposefile is the docker-compose.yml file required to deploy and maintain
@@ -71,7 +72,9 @@ services:
       - FAUCET_TIERS={{.FaucetTiers}}
       - CAPTCHA_TOKEN={{.CaptchaToken}}
       - CAPTCHA_SECRET={{.CaptchaSecret}}
-      - NO_AUTH={{.NoAuth}}{{if .VHost}}
+      - NO_AUTH={{.NoAuth}}
+      - FAUCET_CANDIDATE={{.Candidate}}
+      - FAUCET_REGISTER={{.Register}}{{if .VHost}}
       - VIRTUAL_HOST={{.VHost}}
       - VIRTUAL_PORT=8080{{end}}
     logging:
@@ -103,6 +106,8 @@ func deployFaucet(client *sshClient, network string, bootnodes []string, config
 		"FaucetMinutes": config.minutes,
 		"FaucetTiers":   config.tiers,
 		"NoAuth":        config.noauth,
+		"Candidate":     config.candidate,
+		"Register":      config.register,
 	})
 	files[filepath.Join(workdir, "Dockerfile")] = dockerfile.Bytes()
 
@@ -120,6 +125,8 @@ func deployFaucet(client *sshClient, network string, bootnodes []string, config
 		"FaucetMinutes": config.minutes,
 		"FaucetTiers":   config.tiers,
 		"NoAuth":        config.noauth,
+		"Candidate":     config.candidate,
+		"Register":      config.register,
 	})
 	files[filepath.Join(workdir, "docker-compose.yaml")] = composefile.Bytes()
 
@@ -150,6 +157,8 @@ type faucetInfos struct {
 	minutes       int
 	tiers         int
 	noauth        bool
+	candidate     bool
+	register      bool
 	captchaToken  string
 	captchaSecret string
 }
@@ -170,6 +179,12 @@ func (info *faucetInfos) Report() map[string]string {
 	if info.noauth {
 		report["Debug mode (no auth)"] = "enabled"
 	}
+	if info.candidate {
+		report["Candidate deposits"] = "enabled"
+		if info.register {
+			report["Candidate deposits"] = "enabled, submitting registrations"
+		}
+	}
 	if info.node.keyJSON != "" {
 		var key struct {
 			Address string `json:"address"`
@@ -243,5 +258,7 @@ func checkFaucet(client *sshClient, network string) (*faucetInfos, error) {
 		captchaToken:  infos.envvars["CAPTCHA_TOKEN"],
 		captchaSecret: infos.envvars["CAPTCHA_SECRET"],
 		noauth:        infos.envvars["NO_AUTH"] == "true",
+		candidate:     infos.envvars["FAUCET_CANDIDATE"] == "true",
+		register:      infos.envvars["FAUCET_REGISTER"] == "true",
 	}, nil
 }
@@ -0,0 +1,280 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// validatorNodeDockerfile is the Dockerfile required to build and run a sealing
+// node of an equality network. The equality engine is missing from the upstream
+// images, so the node is built from source.
+var validatorNodeDockerfile = `
+FROM golang:1.15-alpine as builder
+
+RUN apk add --no-cache make gcc musl-dev linux-headers git
+RUN git clone --depth 1 https://github.com/SecretBlockChain/go-secret /go-secret && cd /go-secret && make secret
+
+FROM alpine:latest
+
+RUN apk add --no-cache ca-certificates
+COPY --from=builder /go-secret/build/bin/secret /usr/local/bin/
+
+ADD genesis.json /genesis.json
+ADD config.toml /config.toml
+ADD signer.json /signer.json
+ADD signer.pass /signer.pass
+
+RUN \
+  echo 'secret --cache 512 init /genesis.json' > secret.sh && \
+	echo 'mkdir -p /root/.secret/keystore/ && cp /signer.json /root/.secret/keystore/' >> secret.sh && \
+	echo 'exec secret --config /config.toml --cache 512 --nat extip:{{.IP}} --unlock {{.Signer}} --password /signer.pass --mine' >> secret.sh
+
+ENTRYPOINT ["/bin/sh", "secret.sh"]
+`
+
+// validatorNodeConfig is the TOML configuration of a sealing node, in the format
+// of the dumpconfig command.
+var validatorNodeConfig = `[Eth]
+NetworkId = {{.NetworkID}}
+EqualityNoPruning = {{.Archive}}
+
+[Eth.Miner]
+Etherbase = "{{.Signer}}"
+GasFloor = {{.GasTarget}}
+GasCeil = {{.GasLimit}}
+GasPrice = {{.GasPrice}}
+
+[Node.P2P]
+MaxPeers = {{.Peers}}
+ListenAddr = ":{{.Port}}"
+BootstrapNodes = [{{range $i, $enode := .Bootnodes}}{{if $i}}, {{end}}"{{$enode}}"{{end}}]
+
+[Ethstats]
+URL = "{{.Ethstats}}"
+`
+
+// validatorNodeComposefile is the docker-compose.yml file required to deploy and
+// maintain a sealing node of an equality network.
+var validatorNodeComposefile = `
+version: '2'
+services:
+  validator:
+    build: .
+    image: {{.Network}}/validator
+    container_name: {{.Network}}_validator_1
+    ports:
+      - "{{.Port}}:{{.Port}}"
+      - "{{.Port}}:{{.Port}}/udp"
+    volumes:
+      - {{.Datadir}}:/root/.secret
+    environment:
+      - PORT={{.Port}}/tcp
+      - TOTAL_PEERS={{.TotalPeers}}
+      - STATS_NAME={{.Ethstats}}
+      - GAS_TARGET={{.GasTarget}}
+      - GAS_LIMIT={{.GasLimit}}
+      - GAS_PRICE={{.GasPrice}}
+      - GC_MODE={{.GCMode}}
+    logging:
+      driver: "json-file"
+      options:
+        max-size: "1m"
+        max-file: "10"
+    restart: always
+`
+
+// deployValidatorNode deploys a new equality sealing node container to a remote
+// machine via SSH, docker and docker-compose. If an instance with the specified
+// network name already exists there, it will be overwritten!
+func deployValidatorNode(client *sshClient, network string, bootnodes []string, config *validatorNodeInfos, nocache bool) ([]byte, error) {
+	signer, err := config.signer()
+	if err != nil {
+		return nil, err
+	}
+	// Generate the content to upload to the server
+	workdir := fmt.Sprintf("%d", rand.Int63())
+	files := make(map[string][]byte)
+
+	dockerfile := new(bytes.Buffer)
+	template.Must(template.New("").Parse(validatorNodeDockerfile)).Execute(dockerfile, map[string]interface{}{
+		"IP":     client.address,
+		"Signer": signer.Hex(),
+	})
+	files[filepath.Join(workdir, "Dockerfile")] = dockerfile.Bytes()
+
+	tomlfile := new(bytes.Buffer)
+	template.Must(template.New("").Parse(validatorNodeConfig)).Execute(tomlfile, map[string]interface{}{
+		"NetworkID": config.node.network,
+		"Archive":   config.archive,
+		"Signer":    signer.Hex(),
+		"GasTarget": uint64(1000000 * config.node.gasTarget),
+		"GasLimit":  uint64(1000000 * config.node.gasLimit),
+		"GasPrice":  uint64(1000000000 * config.node.gasPrice),
+		"Peers":     config.node.peersTotal,
+		"Port":      config.node.port,
+		"Bootnodes": bootnodes,
+		"Ethstats":  config.node.ethstats,
+	})
+	files[filepath.Join(workdir, "config.toml")] = tomlfile.Bytes()
+
+	gcmode := "pruned"
+	if config.archive {
+		gcmode = "archive"
+	}
+	composefile := new(bytes.Buffer)
+	template.Must(template.New("").Parse(validatorNodeComposefile)).Execute(composefile, map[string]interface{}{
+		"Datadir":    config.node.datadir,
+		"Network":    network,
+		"Port":       config.node.port,
+		"TotalPeers": config.node.peersTotal,
+		"Ethstats":   config.node.ethstats[:strings.Index(config.node.ethstats, ":")],
+		"GasTarget":  config.node.gasTarget,
+		"GasLimit":   config.node.gasLimit,
+		"GasPrice":   config.node.gasPrice,
+		"GCMode":     gcmode,
+	})
+	files[filepath.Join(workdir, "docker-compose.yaml")] = composefile.Bytes()
+
+	files[filepath.Join(workdir, "genesis.json")] = config.node.genesis
+	files[filepath.Join(workdir, "signer.json")] = []byte(config.node.keyJSON)
+	files[filepath.Join(workdir, "signer.pass")] = []byte(config.node.keyPass)
+
+	// Upload the deployment files to the remote server (and clean up afterwards)
+	if out, err := client.Upload(files); err != nil {
+		return out, err
+	}
+	defer client.Run("rm -rf " + workdir)
+
+	// Build and deploy the validator node service
+	if nocache {
+		return nil, client.Stream(fmt.Sprintf("cd %s && docker-compose -p %s build --pull --no-cache && docker-compose -p %s up -d --force-recreate --timeout 60", workdir, network, network))
+	}
+	return nil, client.Stream(fmt.Sprintf("cd %s && docker-compose -p %s up -d --build --force-recreate --timeout 60", workdir, network))
+}
+
+// validatorNodeInfos is returned from a validator node status check to allow
+// reporting various configuration parameters.
+type validatorNodeInfos struct {
+	node    *nodeInfos
+	archive bool
+}
+
+// signer returns the address of the signer key of the validator node.
+func (info *validatorNodeInfos) signer() (common.Address, error) {
+	var key struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal([]byte(info.node.keyJSON), &key); err != nil {
+		return common.Address{}, err
+	}
+	return common.HexToAddress(key.Address), nil
+}
+
+// Report converts the typed struct into a plain string->string map, containing
+// most - but not all - fields for reporting to the user.
+func (info *validatorNodeInfos) Report() map[string]string {
+	report := map[string]string{
+		"Data directory":               info.node.datadir,
+		"Listener port":                strconv.Itoa(info.node.port),
+		"Peer count (all total)":       strconv.Itoa(info.node.peersTotal),
+		"Ethstats username":            info.node.ethstats,
+		"Gas price (minimum accepted)": fmt.Sprintf("%0.3f GWei", info.node.gasPrice),
+		"Gas floor (baseline target)":  fmt.Sprintf("%0.3f MGas", info.node.gasTarget),
+		"Gas ceil  (target maximum)":   fmt.Sprintf("%0.3f MGas", info.node.gasLimit),
+		"Snapshot retention":           "pruned",
+	}
+	if info.archive {
+		report["Snapshot retention"] = "archive"
+	}
+	if signer, err := info.signer(); err == nil {
+		report["Signer account"] = signer.Hex()
+	} else {
+		log.Error("Failed to retrieve signer address", "err", err)
+	}
+	return report
+}
+
+// checkValidatorNode does a health-check against a validator node server to
+// verify whether it's running, and if yes, whether it's responsive.
+func checkValidatorNode(client *sshClient, network string) (*validatorNodeInfos, error) {
+	// Inspect a possible validator node container on the host
+	infos, err := inspectContainer(client, fmt.Sprintf("%s_validator_1", network))
+	if err != nil {
+		return nil, err
+	}
+	if !infos.running {
+		return nil, ErrServiceOffline
+	}
+	// Resolve a few types from the environmental variables
+	totalPeers, _ := strconv.Atoi(infos.envvars["TOTAL_PEERS"])
+	gasTarget, _ := strconv.ParseFloat(infos.envvars["GAS_TARGET"], 64)
+	gasLimit, _ := strconv.ParseFloat(infos.envvars["GAS_LIMIT"], 64)
+	gasPrice, _ := strconv.ParseFloat(infos.envvars["GAS_PRICE"], 64)
+
+	// Container available, retrieve its node ID and its genesis json
+	var out []byte
+	if out, err = client.Run(fmt.Sprintf("docker exec %s_validator_1 secret --exec admin.nodeInfo.enode attach", network)); err != nil {
+		return nil, ErrServiceUnreachable
+	}
+	enode := bytes.Trim(bytes.TrimSpace(out), "\"")
+
+	if out, err = client.Run(fmt.Sprintf("docker exec %s_validator_1 cat /genesis.json", network)); err != nil {
+		return nil, ErrServiceUnreachable
+	}
+	genesis := bytes.TrimSpace(out)
+
+	keyJSON, keyPass := "", ""
+	if out, err = client.Run(fmt.Sprintf("docker exec %s_validator_1 cat /signer.json", network)); err == nil {
+		keyJSON = string(bytes.TrimSpace(out))
+	}
+	if out, err = client.Run(fmt.Sprintf("docker exec %s_validator_1 cat /signer.pass", network)); err == nil {
+		keyPass = string(bytes.TrimSpace(out))
+	}
+	// Run a sanity check to see if the devp2p is reachable
+	port := infos.portmap[infos.envvars["PORT"]]
+	if err = checkPort(client.server, port); err != nil {
+		log.Warn("Validator node devp2p port seems unreachable", "server", client.server, "port", port, "err", err)
+	}
+	// Assemble and return the useful infos
+	return &validatorNodeInfos{
+		node: &nodeInfos{
+			genesis:    genesis,
+			datadir:    infos.volumes["/root/.secret"],
+			port:       port,
+			enode:      string(enode),
+			peersTotal: totalPeers,
+			ethstats:   infos.envvars["STATS_NAME"],
+			keyJSON:    keyJSON,
+			keyPass:    keyPass,
+			gasTarget:  gasTarget,
+			gasLimit:   gasLimit,
+			gasPrice:   gasPrice,
+		},
+		archive: infos.envvars["GC_MODE"] == "archive",
+	}, nil
+}
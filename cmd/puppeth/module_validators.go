@@ -0,0 +1,366 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// validatorsContent is the validator monitoring page, polling the eq namespace
+// of a node through the RPC proxy of the container.
+var validatorsContent = `
+<!DOCTYPE html>
+<html lang="en">
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=UTF-8">
+		<meta charset="utf-8">
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+
+		<title>{{.NetworkTitle}}: Validators</title>
+
+		<link href="https://cdnjs.cloudflare.com/ajax/libs/twitter-bootstrap/3.3.7/css/bootstrap.min.css" rel="stylesheet">
+	</head>
+
+	<body>
+		<div class="container">
+			<h1>{{.NetworkTitle}} validators</h1>
+			<p id="summary" class="lead">Loading...</p>
+			<table class="table table-striped">
+				<thead>
+					<tr>
+						<th>Address</th>
+						<th>Genesis</th>
+						<th>Current epoch</th>
+						<th>Minted in epoch</th>
+						<th>Recent blocks</th>
+						<th>Missed slots</th>
+						<th>Deposit</th>
+					</tr>
+				</thead>
+				<tbody id="validators"></tbody>
+			</table>
+			<p class="text-muted">Recent blocks and missed slots cover the last {{.Window}} blocks of the current epoch.</p>
+		</div>
+
+		<script>
+			var window_ = {{.Window}};
+
+			// rpc sends a batch of [method, params] calls to the node behind the proxy.
+			function rpc(calls) {
+				var batch = calls.map(function(call, id) {
+					return {jsonrpc: "2.0", id: id, method: call[0], params: call[1]};
+				});
+				return fetch("/rpc", {method: "POST", headers: {"Content-Type": "application/json"}, body: JSON.stringify(batch)})
+					.then(function(res) { return res.json(); })
+					.then(function(results) {
+						results.sort(function(a, b) { return a.id - b.id; });
+						return results.map(function(result) {
+							if (result.error) {
+								throw new Error(result.error.message);
+							}
+							return result.result;
+						});
+					});
+			}
+
+			function ethers(wei) {
+				return (Number(BigInt(wei) / BigInt(1e12)) / 1e6) + " ethers";
+			}
+
+			function refresh() {
+				rpc([["eth_blockNumber", []], ["eq_dumpSnapshot", ["latest"]]]).then(function(results) {
+					var head = parseInt(results[0], 16), snap = results[1], config = snap.config;
+					var validators = snap.validators.map(function(v) { return v.address.toLowerCase(); });
+
+					// Blocks after the epoch block are all scheduled among the current validators
+					var from = Math.max(snap.epochBlock, head - window_), calls = [];
+					for (var number = from; number <= head; number++) {
+						calls.push(["eth_getBlockByNumber", ["0x" + number.toString(16), false]]);
+					}
+					return rpc(calls).then(function(blocks) {
+						var minted = {}, missed = {};
+						for (var i = 1; i < blocks.length; i++) {
+							var parent = parseInt(blocks[i-1].timestamp, 16), time = parseInt(blocks[i].timestamp, 16);
+							var miner = blocks[i].miner.toLowerCase();
+							minted[miner] = (minted[miner] || 0) + 1;
+
+							// Every slot skipped between the two blocks was missed by the validator in turn
+							var slot = config.genesisTimestamp + (Math.floor((parent - config.genesisTimestamp) / config.period) + 1) * config.period;
+							for (; slot < time && validators.length > 0; slot += config.period) {
+								var turn = validators[Math.floor((slot - config.genesisTimestamp) / config.period) % validators.length];
+								missed[turn] = (missed[turn] || 0) + 1;
+							}
+						}
+						render(head, snap, validators, minted, missed);
+					});
+				}).catch(function(err) {
+					document.getElementById("summary").textContent = "Failed to query the node: " + err.message;
+				});
+			}
+
+			function render(head, snap, validators, minted, missed) {
+				var genesis = (snap.config.validators || []).map(function(v) { return v.toLowerCase(); });
+				var counts = {}, deposits = {};
+				snap.validators.forEach(function(v) { counts[v.address.toLowerCase()] = v.countMinted || 0; });
+				snap.candidates.forEach(function(c) { deposits[c.address.toLowerCase()] = c.staked; });
+
+				var rows = genesis.slice();
+				validators.forEach(function(v) { if (rows.indexOf(v) < 0) { rows.push(v); } });
+
+				var body = document.getElementById("validators");
+				body.innerHTML = "";
+				rows.forEach(function(address) {
+					var current = validators.indexOf(address) >= 0;
+					var cells = [
+						address,
+						genesis.indexOf(address) >= 0 ? "yes" : "no",
+						current ? "yes" : "no",
+						current ? counts[address] : "-",
+						minted[address] || 0,
+						current ? (missed[address] || 0) : "-",
+						address in deposits ? ethers(deposits[address]) : "not a candidate"
+					];
+					var row = document.createElement("tr");
+					if (current && missed[address] > 0) {
+						row.className = "warning";
+					}
+					cells.forEach(function(cell) {
+						var td = document.createElement("td");
+						td.textContent = cell;
+						row.appendChild(td);
+					});
+					body.appendChild(row);
+				});
+				document.getElementById("summary").textContent = "Block #" + head + ", epoch " + snap.epoch + " started at block #" + snap.epochBlock +
+					", " + validators.length + " validators, " + snap.candidates.length + " candidates";
+			}
+
+			refresh();
+			setInterval(refresh, 10000);
+		</script>
+	</body>
+</html>
+`
+
+// validatorsServer is the node.js server of the validator monitor, serving the
+// page and proxying the read-only RPC methods it needs to the node.
+var validatorsServer = `
+var fs = require("fs");
+var http = require("http");
+var https = require("https");
+var url = require("url");
+
+var allowed = ["eth_blockNumber", "eth_getBlockByNumber", "eq_dumpSnapshot", "eq_getValidators", "eq_getCandidates", "eq_getCandidateHistory"];
+var index = fs.readFileSync("/validators/index.html");
+var target = url.parse(process.env.RPC_URL);
+
+http.createServer(function(req, res) {
+	if (req.method !== "POST" || req.url !== "/rpc") {
+		res.writeHead(200, {"Content-Type": "text/html"});
+		return res.end(index);
+	}
+	var body = "";
+	req.on("data", function(chunk) {
+		body += chunk;
+		if (body.length > 1024 * 1024) {
+			req.destroy();
+		}
+	});
+	req.on("end", function() {
+		var calls;
+		try {
+			calls = JSON.parse(body);
+		} catch (err) {
+			res.writeHead(400);
+			return res.end();
+		}
+		var batch = Array.isArray(calls) ? calls : [calls];
+		for (var i = 0; i < batch.length; i++) {
+			if (allowed.indexOf(batch[i].method) < 0) {
+				res.writeHead(403);
+				return res.end();
+			}
+		}
+		var proxy = (target.protocol === "https:" ? https : http).request({
+			hostname: target.hostname,
+			port:     target.port,
+			path:     target.path,
+			method:   "POST",
+			headers:  {"Content-Type": "application/json"}
+		}, function(upstream) {
+			res.writeHead(upstream.statusCode, {"Content-Type": "application/json"});
+			upstream.pipe(res);
+		});
+		proxy.on("error", function() {
+			res.writeHead(502);
+			res.end();
+		});
+		proxy.end(body);
+	});
+}).listen(80, function() {
+	console.log("Server running on 80...");
+});
+`
+
+// validatorsDockerfile is the Dockerfile required to build a validator monitor
+// container.
+var validatorsDockerfile = `
+FROM mhart/alpine-node:latest
+
+ADD index.html /validators/index.html
+ADD server.js /server.js
+
+EXPOSE 80
+
+CMD ["node", "/server.js"]
+`
+
+// validatorsComposefile is the docker-compose.yml file required to deploy and
+// maintain a validator monitor.
+var validatorsComposefile = `
+version: '2'
+services:
+  validators:
+    build: .
+    image: {{.Network}}/validators
+    container_name: {{.Network}}_validators_1{{if not .VHost}}
+    ports:
+      - "{{.Port}}:80"{{end}}
+    environment:
+      - RPC_URL={{.RPC}}
+      - BLOCK_WINDOW={{.Window}}{{if .VHost}}
+      - VIRTUAL_HOST={{.VHost}}{{end}}
+    logging:
+      driver: "json-file"
+      options:
+        max-size: "1m"
+        max-file: "10"
+    restart: always
+`
+
+// deployValidators deploys a new validator monitor container to a remote machine
+// via SSH, docker and docker-compose. If an instance with the specified network
+// name already exists there, it will be overwritten!
+func deployValidators(client *sshClient, network string, config *validatorsInfos, nocache bool) ([]byte, error) {
+	// Generate the content to upload to the server
+	workdir := fmt.Sprintf("%d", rand.Int63())
+	files := make(map[string][]byte)
+
+	dockerfile := new(bytes.Buffer)
+	template.Must(template.New("").Parse(validatorsDockerfile)).Execute(dockerfile, nil)
+	files[filepath.Join(workdir, "Dockerfile")] = dockerfile.Bytes()
+
+	composefile := new(bytes.Buffer)
+	template.Must(template.New("").Parse(validatorsComposefile)).Execute(composefile, map[string]interface{}{
+		"Network": network,
+		"Port":    config.port,
+		"VHost":   config.host,
+		"RPC":     config.rpc,
+		"Window":  config.window,
+	})
+	files[filepath.Join(workdir, "docker-compose.yaml")] = composefile.Bytes()
+
+	indexfile := new(bytes.Buffer)
+	template.Must(template.New("").Parse(validatorsContent)).Execute(indexfile, map[string]interface{}{
+		"NetworkTitle": strings.Title(network),
+		"Window":       config.window,
+	})
+	files[filepath.Join(workdir, "index.html")] = indexfile.Bytes()
+	files[filepath.Join(workdir, "server.js")] = []byte(validatorsServer)
+
+	// Upload the deployment files to the remote server (and clean up afterwards)
+	if out, err := client.Upload(files); err != nil {
+		return out, err
+	}
+	defer client.Run("rm -rf " + workdir)
+
+	// Build and deploy the validator monitor service
+	if nocache {
+		return nil, client.Stream(fmt.Sprintf("cd %s && docker-compose -p %s build --pull --no-cache && docker-compose -p %s up -d --force-recreate --timeout 60", workdir, network, network))
+	}
+	return nil, client.Stream(fmt.Sprintf("cd %s && docker-compose -p %s up -d --build --force-recreate --timeout 60", workdir, network))
+}
+
+// validatorsInfos is returned from a validator monitor status check to allow
+// reporting various configuration parameters.
+type validatorsInfos struct {
+	host   string
+	port   int
+	rpc    string
+	window int
+}
+
+// Report converts the typed struct into a plain string->string map, containing
+// most - but not all - fields for reporting to the user.
+func (info *validatorsInfos) Report() map[string]string {
+	return map[string]string{
+		"Website address":       info.host,
+		"Website listener port": strconv.Itoa(info.port),
+		"Node RPC endpoint":     info.rpc,
+		"Monitored blocks":      strconv.Itoa(info.window),
+	}
+}
+
+// checkValidators does a health-check against a validator monitor container to
+// verify if it's running, and if yes, gathering a collection of useful infos
+// about it.
+func checkValidators(client *sshClient, network string) (*validatorsInfos, error) {
+	// Inspect a possible validator monitor container on the host
+	infos, err := inspectContainer(client, fmt.Sprintf("%s_validators_1", network))
+	if err != nil {
+		return nil, err
+	}
+	if !infos.running {
+		return nil, ErrServiceOffline
+	}
+	// Resolve the port from the host, or the reverse proxy
+	port := infos.portmap["80/tcp"]
+	if port == 0 {
+		if proxy, _ := checkNginx(client, network); proxy != nil {
+			port = proxy.port
+		}
+	}
+	if port == 0 {
+		return nil, ErrNotExposed
+	}
+	// Resolve the host from the reverse-proxy and the config values
+	host := infos.envvars["VIRTUAL_HOST"]
+	if host == "" {
+		host = client.server
+	}
+	window, _ := strconv.Atoi(infos.envvars["BLOCK_WINDOW"])
+
+	// Run a sanity check to see if the port is reachable
+	if err = checkPort(host, port); err != nil {
+		log.Warn("Validator monitor seems unreachable", "server", host, "port", port, "err", err)
+	}
+	// Container available, assemble and return the useful infos
+	return &validatorsInfos{
+		host:   host,
+		port:   port,
+		rpc:    infos.envvars["RPC_URL"],
+		window: window,
+	}, nil
+}
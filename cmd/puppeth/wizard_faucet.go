@@ -77,6 +77,23 @@ func (w *wizard) deployFaucet() {
 		log.Error("At least one funding tier must be set")
 		return
 	}
+	// Equality networks may onboard validators by granting the candidate deposits
+	if w.conf.Genesis.Config.Equality != nil {
+		fmt.Println()
+		fmt.Printf("Grant candidate deposits for validator onboarding (y/n)? (default = %v)\n", infos.candidate)
+		infos.candidate = w.readDefaultYesNo(infos.candidate)
+
+		if infos.candidate {
+			fmt.Println()
+			fmt.Printf("Submit signed candidate registrations on behalf of the requesters (y/n)? (default = %v)\n", infos.register)
+			infos.register = w.readDefaultYesNo(infos.register)
+		}
+	} else {
+		infos.candidate = false
+	}
+	if !infos.candidate {
+		infos.register = false
+	}
 	// Accessing the reCaptcha service requires API authorizations, request it
 	if infos.captchaToken != "" {
 		fmt.Println()
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"math/rand"
 	"net/http"
@@ -31,7 +32,6 @@ import (
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/core"
-	"github.com/SecretBlockChain/go-secret/crypto"
 	"github.com/SecretBlockChain/go-secret/log"
 	"github.com/SecretBlockChain/go-secret/params"
 )
@@ -108,9 +108,9 @@ func (w *wizard) makeGenesis() {
 		}
 
 	case choice == "" || choice == "3":
-		// In the case of alien, configure the consensus parameters
+		// In the case of equality, configure the consensus parameters
 		genesis.Difficulty = big.NewInt(1)
-		genesis.Config.Equality = params.TestnetEqualityConfig()
+		genesis.Config.Equality = new(params.EqualityConfig)
 		fmt.Println()
 		fmt.Println("How many seconds should blocks take? (default = 5)")
 		genesis.Config.Equality.Period = uint64(w.readDefaultInt(5))
@@ -128,10 +128,33 @@ func (w *wizard) makeGenesis() {
 		genesis.Config.Equality.MaxValidatorsCount = uint64(w.readDefaultInt(21))
 
 		fmt.Println()
-		fmt.Println("What is the minimize balance of become candidate ? (default = 100)")
-		genesis.Config.Equality.MinCandidateBalance = new(big.Int).Mul(big.NewInt(int64(w.readDefaultInt(0))),
+		fmt.Println("What is the minimize balance of become candidate, in ethers? (default = 100)")
+		genesis.Config.Equality.MinCandidateBalance = new(big.Int).Mul(big.NewInt(int64(w.readDefaultInt(100))),
 			big.NewInt(1e+18))
 
+		// Rewards are paid up to the block of each rule, the last one applying forever
+		fmt.Println()
+		fmt.Println("What is the reward of minting a block, in ethers? (default = 2)")
+		reward := w.readDefaultInt(2)
+		for {
+			fmt.Println()
+			fmt.Printf("Up to which block is the reward of %d ethers paid? (default = forever)\n", reward)
+			until := uint64(w.readDefaultInt(0))
+			if until == 0 {
+				until = math.MaxUint64
+			}
+			genesis.Config.Equality.Rewards = append(genesis.Config.Equality.Rewards, params.EqualityReward{
+				Number: until,
+				Reward: new(big.Int).Mul(big.NewInt(int64(reward)), big.NewInt(1e+18)),
+			})
+			if until == math.MaxUint64 {
+				break
+			}
+			fmt.Println()
+			fmt.Printf("What is the reward after block %d, in ethers? (default = 0)\n", until)
+			reward = w.readDefaultInt(0)
+		}
+
 		fmt.Println()
 		fmt.Println("How many minutes delay to create first block ? (default = 1)")
 		genesis.Config.Equality.GenesisTimestamp = uint64(time.Now().Unix()) + uint64(w.readDefaultInt(1)*60)
@@ -152,8 +175,10 @@ func (w *wizard) makeGenesis() {
 				break
 			}
 		}
-
-		genesis.ExtraData = make([]byte, 32+crypto.SignatureLength)
+		if err := genesis.Config.Equality.Validate(); err != nil {
+			log.Warn("Suspicious equality configuration", "err", err)
+		}
+		genesis.ExtraData, _ = core.EqualityExtraData(nil)
 
 	default:
 		log.Crit("Invalid consensus engine choice", "choice", choice)
@@ -289,6 +314,14 @@ func (w *wizard) manageGenesis() {
 		fmt.Printf("Which block should YOLOv1 come into effect? (default = %v)\n", w.conf.Genesis.Config.YoloV1Block)
 		w.conf.Genesis.Config.YoloV1Block = w.readDefaultBigInt(w.conf.Genesis.Config.YoloV1Block)
 
+		fmt.Println()
+		fmt.Printf("Which block should Berlin come into effect? (default = %v)\n", w.conf.Genesis.Config.BerlinBlock)
+		w.conf.Genesis.Config.BerlinBlock = w.readDefaultBigInt(w.conf.Genesis.Config.BerlinBlock)
+
+		fmt.Println()
+		fmt.Printf("Which block should London come into effect? (default = %v)\n", w.conf.Genesis.Config.LondonBlock)
+		w.conf.Genesis.Config.LondonBlock = w.readDefaultBigInt(w.conf.Genesis.Config.LondonBlock)
+
 		out, _ := json.MarshalIndent(w.conf.Genesis.Config, "", "  ")
 		fmt.Printf("Chain configuration updated:\n\n%s\n", out)
 
@@ -298,7 +331,7 @@ func (w *wizard) manageGenesis() {
 		// Save whatever genesis configuration we currently have
 		fmt.Println()
 		fmt.Printf("Which folder to save the genesis specs into? (default = current)\n")
-		fmt.Printf("  Will create %s.json, %s-aleth.json, %s-harmony.json, %s-parity.json\n", w.network, w.network, w.network, w.network)
+		fmt.Printf("  Will create %s.json, %s-secret.json, %s-aleth.json, %s-harmony.json, %s-parity.json\n", w.network, w.network, w.network, w.network, w.network)
 
 		folder := w.readDefaultString(".")
 		if err := os.MkdirAll(folder, 0755); err != nil {
@@ -315,19 +348,27 @@ func (w *wizard) manageGenesis() {
 		}
 		log.Info("Saved native genesis chain spec", "path", gethJson)
 
+		// Export the chain spec loaded by secret and the tooling around it
+		saveGenesis(folder, w.network, "secret", newSecretChainSpec(w.network, w.conf.Genesis, w.conf.bootnodes))
+
 		// Export the genesis spec used by Aleth (formerly C++ Ethereum)
-		if spec, err := newAlethGenesisSpec(w.network, w.conf.Genesis); err != nil {
+		if spec, err := newAlethGenesisSpec(w.network, w.conf.Genesis); err == errEqualityUnsupported {
+			log.Warn("Skipped Aleth chain spec", "err", err)
+		} else if err != nil {
 			log.Error("Failed to create Aleth chain spec", "err", err)
 		} else {
 			saveGenesis(folder, w.network, "aleth", spec)
 		}
 		// Export the genesis spec used by Parity
-		if spec, err := newParityChainSpec(w.network, w.conf.Genesis, []string{}); err != nil {
+		if spec, err := newParityChainSpec(w.network, w.conf.Genesis, []string{}); err == errEqualityUnsupported {
+			log.Warn("Skipped Parity chain spec", "err", err)
+		} else if err != nil {
 			log.Error("Failed to create Parity chain spec", "err", err)
 		} else {
 			saveGenesis(folder, w.network, "parity", spec)
 		}
-		// Export the genesis spec used by Harmony (formerly EthereumJ)
+		// Export the genesis spec used by Harmony (formerly EthereumJ), which
+		// embeds the equality section of the config untranslated
 		saveGenesis(folder, w.network, "harmony", w.conf.Genesis)
 
 	case "3":
@@ -133,6 +133,15 @@ func (w *wizard) gatherStats(server string, pubkey []byte, client *sshClient) *s
 		stat.services["sealnode"] = infos.Report()
 		genesis = string(infos.genesis)
 	}
+	logger.Debug("Checking for validator node availability")
+	if infos, err := checkValidatorNode(client, w.network); err != nil {
+		if err != ErrServiceUnknown {
+			stat.services["validator"] = map[string]string{"offline": err.Error()}
+		}
+	} else {
+		stat.services["validator"] = infos.Report()
+		genesis = string(infos.node.genesis)
+	}
 	logger.Debug("Checking for explorer availability")
 	if infos, err := checkExplorer(client, w.network); err != nil {
 		if err != ErrServiceUnknown {
@@ -165,6 +174,14 @@ func (w *wizard) gatherStats(server string, pubkey []byte, client *sshClient) *s
 	} else {
 		stat.services["dashboard"] = infos.Report()
 	}
+	logger.Debug("Checking for validator monitor availability")
+	if infos, err := checkValidators(client, w.network); err != nil {
+		if err != ErrServiceUnknown {
+			stat.services["validators"] = map[string]string{"offline": err.Error()}
+		}
+	} else {
+		stat.services["validators"] = infos.Report()
+	}
 	// Feed and newly discovered information into the wizard
 	w.lock.Lock()
 	defer w.lock.Unlock()
@@ -0,0 +1,199 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/accounts/keystore"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/pborman/uuid"
+)
+
+// deployValidatorNode creates a new equality sealing node configuration based
+// on some user input.
+func (w *wizard) deployValidatorNode() {
+	// Do some sanity check before the user wastes time on input
+	if w.conf.Genesis == nil || w.conf.Genesis.Config.Equality == nil {
+		log.Error("Validator nodes require an equality genesis")
+		return
+	}
+	if w.conf.ethstats == "" {
+		log.Error("No ethstats server configured")
+		return
+	}
+	// Select the server to interact with
+	server := w.selectServer()
+	if server == "" {
+		return
+	}
+	client := w.servers[server]
+
+	// Retrieve any active validator node configurations from the server
+	infos, err := checkValidatorNode(client, w.network)
+	if err != nil {
+		infos = &validatorNodeInfos{
+			node: &nodeInfos{port: 30303, peersTotal: 50, gasTarget: 7.5, gasLimit: 10, gasPrice: 1},
+		}
+	}
+	existed := err == nil
+
+	infos.node.genesis, _ = json.MarshalIndent(w.conf.Genesis, "", "  ")
+	infos.node.network = w.conf.Genesis.Config.ChainID.Int64()
+
+	// Figure out where the user wants to store the persistent data
+	fmt.Println()
+	if infos.node.datadir == "" {
+		fmt.Printf("Where should data be stored on the remote machine?\n")
+		infos.node.datadir = w.readString()
+	} else {
+		fmt.Printf("Where should data be stored on the remote machine? (default = %s)\n", infos.node.datadir)
+		infos.node.datadir = w.readDefaultString(infos.node.datadir)
+	}
+	fmt.Println()
+	fmt.Printf("Keep the equality snapshot of every block (archive) (y/n)? (default = %v)\n", infos.archive)
+	infos.archive = w.readDefaultYesNo(infos.archive)
+
+	// Figure out which port to listen on
+	fmt.Println()
+	fmt.Printf("Which TCP/UDP port to listen on? (default = %d)\n", infos.node.port)
+	infos.node.port = w.readDefaultInt(infos.node.port)
+
+	// Figure out how many peers to allow
+	fmt.Println()
+	fmt.Printf("How many peers to allow connecting? (default = %d)\n", infos.node.peersTotal)
+	infos.node.peersTotal = w.readDefaultInt(infos.node.peersTotal)
+
+	// Set a proper name to report on the stats page
+	fmt.Println()
+	if infos.node.ethstats == "" {
+		fmt.Printf("What should the node be called on the stats page?\n")
+		infos.node.ethstats = w.readString() + ":" + w.conf.ethstats
+	} else {
+		fmt.Printf("What should the node be called on the stats page? (default = %s)\n", infos.node.ethstats)
+		infos.node.ethstats = w.readDefaultString(infos.node.ethstats) + ":" + w.conf.ethstats
+	}
+	// If a previous signer was already set, offer to reuse it
+	if infos.node.keyJSON != "" {
+		if key, err := keystore.DecryptKey([]byte(infos.node.keyJSON), infos.node.keyPass); err != nil {
+			infos.node.keyJSON, infos.node.keyPass = "", ""
+		} else {
+			fmt.Println()
+			fmt.Printf("Reuse previous (%s) signing account (y/n)? (default = yes)\n", key.Address.Hex())
+			if !w.readDefaultYesNo(true) {
+				infos.node.keyJSON, infos.node.keyPass = "", ""
+			}
+		}
+	}
+	// Validators need a keyfile and unlock password, import or generate one
+	if infos.node.keyJSON == "" {
+		fmt.Println()
+		fmt.Println("Generate a new signing account (y/n)? (default = yes)")
+		generate := w.readDefaultYesNo(true)
+
+		fmt.Println()
+		if generate {
+			fmt.Println("What should be the unlock password for the account? (won't be echoed)")
+		} else {
+			fmt.Println("Please paste the signer's key JSON:")
+			infos.node.keyJSON = w.readJSON()
+
+			fmt.Println()
+			fmt.Println("What's the unlock password for the account? (won't be echoed)")
+		}
+		infos.node.keyPass = w.readPassword()
+
+		if generate {
+			if infos.node.keyJSON, err = generateSigner(infos.node.keyPass); err != nil {
+				log.Error("Failed to generate signing account", "err", err)
+				return
+			}
+		}
+		if _, err := keystore.DecryptKey([]byte(infos.node.keyJSON), infos.node.keyPass); err != nil {
+			log.Error("Failed to decrypt key with given password")
+			return
+		}
+	}
+	// Only the genesis validators seal right away, anyone else has to register first
+	if signer, err := infos.signer(); err == nil {
+		validator := false
+		for _, address := range w.conf.Genesis.Config.Equality.Validators {
+			if address == signer {
+				validator = true
+			}
+		}
+		if !validator {
+			log.Warn("Signer is not a genesis validator, register it as a candidate to seal", "signer", signer,
+				"deposit", w.conf.Genesis.Config.Equality.MinCandidateBalance)
+		}
+	}
+	// Establish the gas dynamics to be enforced by the signer
+	fmt.Println()
+	fmt.Printf("What gas limit should empty blocks target (MGas)? (default = %0.3f)\n", infos.node.gasTarget)
+	infos.node.gasTarget = w.readDefaultFloat(infos.node.gasTarget)
+
+	fmt.Println()
+	fmt.Printf("What gas limit should full blocks target (MGas)? (default = %0.3f)\n", infos.node.gasLimit)
+	infos.node.gasLimit = w.readDefaultFloat(infos.node.gasLimit)
+
+	fmt.Println()
+	fmt.Printf("What gas price should the signer require (GWei)? (default = %0.3f)\n", infos.node.gasPrice)
+	infos.node.gasPrice = w.readDefaultFloat(infos.node.gasPrice)
+
+	// Try to deploy the validator node on the host
+	nocache := false
+	if existed {
+		fmt.Println()
+		fmt.Printf("Should the node be built from scratch (y/n)? (default = no)\n")
+		nocache = w.readDefaultYesNo(false)
+	}
+	if out, err := deployValidatorNode(client, w.network, w.conf.bootnodes, infos, nocache); err != nil {
+		log.Error("Failed to deploy validator node container", "err", err)
+		if len(out) > 0 {
+			fmt.Printf("%s\n", out)
+		}
+		return
+	}
+	// All ok, run a network scan to pick any changes up
+	log.Info("Waiting for node to finish booting")
+	time.Sleep(3 * time.Second)
+
+	w.networkStats()
+}
+
+// generateSigner creates a new signing account, returning its key JSON encrypted
+// with the given password.
+func generateSigner(password string) (string, error) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	key := &keystore.Key{
+		Id:         uuid.NewRandom(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+	keyJSON, err := keystore.EncryptKey(key, password, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return "", err
+	}
+	log.Info("Generated signing account", "address", key.Address)
+	return string(keyJSON), nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// deployValidators queries the user for various input on deploying a validator
+// monitor of an equality network, after which it pushes the container.
+func (w *wizard) deployValidators() {
+	// Do some sanity check before the user wastes time on input
+	if w.conf.Genesis == nil || w.conf.Genesis.Config.Equality == nil {
+		log.Error("Validator monitoring requires an equality genesis")
+		return
+	}
+	// Select the server to interact with
+	server := w.selectServer()
+	if server == "" {
+		return
+	}
+	client := w.servers[server]
+
+	// Retrieve any active validator monitor configurations from the server
+	infos, err := checkValidators(client, w.network)
+	if err != nil {
+		infos = &validatorsInfos{
+			port:   80,
+			host:   client.server,
+			window: 1000,
+		}
+	}
+	existed := err == nil
+
+	// Figure out which port to listen on
+	fmt.Println()
+	fmt.Printf("Which port should the validator monitor listen on? (default = %d)\n", infos.port)
+	infos.port = w.readDefaultInt(infos.port)
+
+	// Figure which virtual-host to deploy the validator monitor on
+	if infos.host, err = w.ensureVirtualHost(client, infos.port, infos.host); err != nil {
+		log.Error("Failed to decide on validator monitor host", "err", err)
+		return
+	}
+	// Figure out which node to query, it must expose the eth and eq namespaces
+	fmt.Println()
+	if infos.rpc == "" {
+		fmt.Printf("Which HTTP RPC endpoint should be queried? (must expose eth and eq)\n")
+		for infos.rpc == "" {
+			if endpoint := w.readURL(); endpoint != nil {
+				infos.rpc = endpoint.String()
+			}
+		}
+	} else {
+		fmt.Printf("Which HTTP RPC endpoint should be queried? (default = %s)\n", infos.rpc)
+		infos.rpc = w.readDefaultString(infos.rpc)
+	}
+	fmt.Println()
+	fmt.Printf("How many recent blocks should be checked for missed slots? (default = %d)\n", infos.window)
+	infos.window = w.readDefaultInt(infos.window)
+
+	// Try to deploy the validator monitor on the host
+	nocache := false
+	if existed {
+		fmt.Println()
+		fmt.Printf("Should the validator monitor be built from scratch (y/n)? (default = no)\n")
+		nocache = w.readDefaultYesNo(false)
+	}
+	if out, err := deployValidators(client, w.network, infos, nocache); err != nil {
+		log.Error("Failed to deploy validator monitor container", "err", err)
+		if len(out) > 0 {
+			fmt.Printf("%s\n", out)
+		}
+		return
+	}
+	// All ok, run a network scan to pick any changes up
+	w.networkStats()
+}
@@ -19,10 +19,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -34,9 +36,12 @@ import (
 	"github.com/SecretBlockChain/go-secret/core/state"
 	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/eth/downloader"
+	"github.com/SecretBlockChain/go-secret/ethdb"
 	"github.com/SecretBlockChain/go-secret/event"
+	"github.com/SecretBlockChain/go-secret/light"
 	"github.com/SecretBlockChain/go-secret/log"
 	"github.com/SecretBlockChain/go-secret/metrics"
+	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/SecretBlockChain/go-secret/trie"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -80,6 +85,9 @@ The dumpgenesis command dumps the genesis block configuration in JSON format to
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
+			utils.EqualityGCModeFlag,
+			utils.EqualityHeaderOnlyFlag,
+			utils.EqualitySnapshotsFlag,
 			utils.SnapshotFlag,
 			utils.CacheDatabaseFlag,
 			utils.CacheGCFlag,
@@ -101,7 +109,10 @@ The import command imports blocks from an RLP-encoded form. The form can be one
 with several RLP-encoded blocks, or several files can be used.
 
 If only one file is used, import error will result in failure. If several files are used,
-processing will proceed even if an individual RLP-file import failure occurs.`,
+processing will proceed even if an individual RLP-file import failure occurs.
+
+The --equality.snapshots flag imports the epoch snapshots exported along with the chain
+before the blocks, rebuilding the ones in between afterwards.`,
 	}
 	exportCommand = cli.Command{
 		Action:    utils.MigrateFlags(exportChain),
@@ -112,6 +123,7 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 			utils.DataDirFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
+			utils.EqualitySnapshotsFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -119,7 +131,10 @@ Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
-be gzipped.`,
+be gzipped.
+
+The --equality.snapshots flag names a second file receiving the snapshots
+of the epoch blocks exported, needed to verify the chain once imported.`,
 	}
 	importPreimagesCommand = cli.Command{
 		Action:    utils.MigrateFlags(importPreimages),
@@ -160,6 +175,7 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 			utils.SyncModeFlag,
 			utils.FakePoWFlag,
 			utils.LocalnetFlag,
+			utils.ChainSpecFlag,
 			utils.TxLookupLimitFlag,
 			utils.LegacyTestnetFlag,
 		},
@@ -198,6 +214,34 @@ Remove blockchain and state databases`,
 The arguments are interpreted as block numbers or hashes.
 Use "ethereum dump 0" to dump the genesis block.`,
 	}
+	checkpointCommand = cli.Command{
+		Action:    utils.MigrateFlags(checkpoint),
+		Name:      "checkpoint",
+		Usage:     "Compute the light client trusted checkpoint of the local chain",
+		ArgsUsage: "[<sectionIndex>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+			utils.LocalnetFlag,
+			utils.ChainSpecFlag,
+			utils.LegacyTestnetFlag,
+			utils.SyncModeFlag,
+			checkpointVerifyFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Prints the checkpoint of the given section, or of the latest one, assembled from
+the CHT and BloomTrie roots generated while serving light clients (--light.serve).
+The output is meant to be registered as the trusted checkpoint of the network.
+
+With --verify, the published checkpoint in the given JSON file, as printed by this
+command, is checked against the one assembled from the local chain instead.`,
+	}
+	checkpointVerifyFlag = cli.StringFlag{
+		Name:  "verify",
+		Usage: "JSON file of a published checkpoint to verify against the local chain",
+	}
 	inspectCommand = cli.Command{
 		Action:    utils.MigrateFlags(inspect),
 		Name:      "inspect",
@@ -208,6 +252,7 @@ Use "ethereum dump 0" to dump the genesis block.`,
 			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.LocalnetFlag,
+			utils.ChainSpecFlag,
 			utils.LegacyTestnetFlag,
 			utils.SyncModeFlag,
 		},
@@ -298,6 +343,11 @@ func importChain(ctx *cli.Context) error {
 
 	var importErr error
 
+	if ctx.IsSet(utils.EqualitySnapshotsFlag.Name) {
+		if err := utils.ImportSnapshots(chain, ctx.String(utils.EqualitySnapshotsFlag.Name)); err != nil {
+			utils.Fatalf("Snapshot import error: %v", err)
+		}
+	}
 	if len(ctx.Args()) == 1 {
 		if err := utils.ImportChain(chain, ctx.Args().First()); err != nil {
 			importErr = err
@@ -311,6 +361,12 @@ func importChain(ctx *cli.Context) error {
 			}
 		}
 	}
+	if ctx.IsSet(utils.EqualitySnapshotsFlag.Name) {
+		if err := utils.EnsureSnapshots(chain); err != nil {
+			importErr = err
+			log.Error("Snapshot rebuild error", "err", err)
+		}
+	}
 	chain.Stop()
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
@@ -373,26 +429,36 @@ func exportChain(ctx *cli.Context) error {
 	chain, _ := utils.MakeChain(ctx, stack, true)
 	start := time.Now()
 
-	var err error
+	var (
+		err         error
+		first, last uint64
+	)
 	fp := ctx.Args().First()
 	if len(ctx.Args()) < 3 {
+		first, last = 0, chain.CurrentBlock().NumberU64()
 		err = utils.ExportChain(chain, fp)
 	} else {
 		// This can be improved to allow for numbers larger than 9223372036854775807
-		first, ferr := strconv.ParseInt(ctx.Args().Get(1), 10, 64)
-		last, lerr := strconv.ParseInt(ctx.Args().Get(2), 10, 64)
+		firstNum, ferr := strconv.ParseInt(ctx.Args().Get(1), 10, 64)
+		lastNum, lerr := strconv.ParseInt(ctx.Args().Get(2), 10, 64)
 		if ferr != nil || lerr != nil {
 			utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
 		}
-		if first < 0 || last < 0 {
+		if firstNum < 0 || lastNum < 0 {
 			utils.Fatalf("Export error: block number must be greater than 0\n")
 		}
-		err = utils.ExportAppendChain(chain, fp, uint64(first), uint64(last))
+		first, last = uint64(firstNum), uint64(lastNum)
+		err = utils.ExportAppendChain(chain, fp, first, last)
 	}
 
 	if err != nil {
 		utils.Fatalf("Export error: %v\n", err)
 	}
+	if ctx.IsSet(utils.EqualitySnapshotsFlag.Name) {
+		if err := utils.ExportSnapshots(chain, ctx.String(utils.EqualitySnapshotsFlag.Name), first, last); err != nil {
+			utils.Fatalf("Snapshot export error: %v\n", err)
+		}
+	}
 	fmt.Printf("Export done in %v\n", time.Since(start))
 	return nil
 }
@@ -600,6 +666,76 @@ func inspect(ctx *cli.Context) error {
 	return rawdb.InspectDatabase(chainDb)
 }
 
+func checkpoint(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		utils.Fatalf("This command requires at most one argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	// The section size depends on the chain the sections were indexed for
+	config := light.ServerIndexerConfig(rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0)))
+
+	if file := ctx.String(checkpointVerifyFlag.Name); file != "" {
+		return verifyCheckpoint(db, config, file)
+	}
+	var cp params.TrustedCheckpoint
+	if len(ctx.Args()) == 1 {
+		section, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+		if err != nil {
+			utils.Fatalf("Invalid section index: %v", err)
+		}
+		cp = light.ReadTrustedCheckpoint(db, config, section)
+	} else {
+		cp = light.ReadLatestTrustedCheckpoint(db, config)
+	}
+	if cp.Empty() {
+		utils.Fatalf("No checkpoint available, sections are only indexed while serving light clients")
+	}
+	out, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	fmt.Println("Checkpoint hash:", cp.Hash().Hex())
+	return nil
+}
+
+// verifyCheckpoint checks the published checkpoint in the file against the one of
+// the same section assembled from the local chain.
+func verifyCheckpoint(db ethdb.Database, config *light.IndexerConfig, file string) error {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		utils.Fatalf("Failed to read checkpoint file: %v", err)
+	}
+	var published params.TrustedCheckpoint
+	if err := json.Unmarshal(blob, &published); err != nil {
+		utils.Fatalf("Invalid checkpoint file: %v", err)
+	}
+	local := light.ReadTrustedCheckpoint(db, config, published.SectionIndex)
+	if local.Empty() {
+		utils.Fatalf("Section %d not indexed locally, sections are only indexed while serving light clients", published.SectionIndex)
+	}
+	var mismatches []string
+	if published.SectionHead != local.SectionHead {
+		mismatches = append(mismatches, fmt.Sprintf("section head %s, local %s", published.SectionHead.Hex(), local.SectionHead.Hex()))
+	}
+	if published.CHTRoot != local.CHTRoot {
+		mismatches = append(mismatches, fmt.Sprintf("CHT root %s, local %s", published.CHTRoot.Hex(), local.CHTRoot.Hex()))
+	}
+	if published.BloomRoot != local.BloomRoot {
+		mismatches = append(mismatches, fmt.Sprintf("bloom root %s, local %s", published.BloomRoot.Hex(), local.BloomRoot.Hex()))
+	}
+	if len(mismatches) > 0 {
+		utils.Fatalf("Checkpoint of section %d doesn't match the local chain:\n  %s", published.SectionIndex, strings.Join(mismatches, "\n  "))
+	}
+	fmt.Println("Checkpoint verified, section", published.SectionIndex, "hash", local.Hash().Hex())
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
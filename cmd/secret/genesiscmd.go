@@ -0,0 +1,404 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"path/filepath"
+
+	"github.com/SecretBlockChain/go-secret/cmd/utils"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rlp"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	genesisCommand = cli.Command{
+		Name:     "genesis",
+		Usage:    "Create and verify genesis specs of equality networks",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The genesis commands are a non-interactive alternative to the genesis wizard of
+puppeth, e.g. to create private networks from continuous integration jobs.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "build",
+				Usage:     "Build a genesis spec from a declarative YAML file",
+				ArgsUsage: "<spec.yaml>",
+				Action:    utils.MigrateFlags(buildGenesis),
+				Flags: []cli.Flag{
+					genesisOutputFlag,
+				},
+				Description: `
+Builds the genesis.json of an equality network from a YAML spec like:
+
+    name: testnet          # Chain ID derived from the name unless given
+    chainId: 1337          # Optional
+    timestamp: 1609459200  # Defaults to 0
+    gasLimit: 4700000
+    equality:
+      period: 5
+      epoch: 17280
+      pool: "0x..."
+      maxValidators: 21
+      minCandidateBalance: "100"   # Ethers
+      rewards:                     # Ethers, up to the block (0 = forever)
+        - {reward: "2", until: 1000000}
+        - {reward: "1"}
+      genesisTimestamp: 1609459260 # Defaults to the timestamp
+      validators:
+        - address: "0x..."
+          balance: "1000"          # Ethers, defaults to 2^256/128 wei
+    allocFile: holders.csv         # CSV or JSON holder list, relative to the spec
+    alloc:
+      "0x...": "500"               # Ethers
+    prefundPrecompiles: true       # Fund 0x00 .. 0xff with 1 wei
+
+The same spec always produces the same genesis block.`,
+			},
+			{
+				Name:      "verify",
+				Usage:     "Check a genesis spec against a known network or genesis hash",
+				ArgsUsage: "<genesis.json>",
+				Action:    utils.MigrateFlags(verifyGenesis),
+				Flags: []cli.Flag{
+					genesisHashFlag,
+				},
+				Description: `
+Recomputes the hash of the genesis block of the spec and compares it with the
+given hash or, if none is given, with the ones of the main and test networks.
+The equality section, not part of the block, is checked too: it has to pass the
+checks of the node, survive the encoding it is recorded in the headers with and,
+for the built-in networks, match their chain config.`,
+			},
+		},
+	}
+	genesisOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write the genesis spec to",
+		Value: "genesis.json",
+	}
+	genesisHashFlag = cli.StringFlag{
+		Name:  "hash",
+		Usage: "Genesis hash the spec is expected to produce",
+	}
+)
+
+// genesisSpec is the declarative YAML description of an equality network.
+type genesisSpec struct {
+	Name               string            `yaml:"name"`
+	ChainID            uint64            `yaml:"chainId"`
+	Timestamp          uint64            `yaml:"timestamp"`
+	GasLimit           uint64            `yaml:"gasLimit"`
+	Equality           equalitySpec      `yaml:"equality"`
+	AllocFile          string            `yaml:"allocFile"`
+	Alloc              map[string]string `yaml:"alloc"`
+	PrefundPrecompiles bool              `yaml:"prefundPrecompiles"`
+}
+
+// equalitySpec is the consensus section of a genesis spec.
+type equalitySpec struct {
+	Period              uint64          `yaml:"period"`
+	Epoch               uint64          `yaml:"epoch"`
+	Pool                string          `yaml:"pool"`
+	MaxValidators       uint64          `yaml:"maxValidators"`
+	MinCandidateBalance string          `yaml:"minCandidateBalance"`
+	Rewards             []rewardSpec    `yaml:"rewards"`
+	GenesisTimestamp    uint64          `yaml:"genesisTimestamp"`
+	Validators          []validatorSpec `yaml:"validators"`
+}
+
+// rewardSpec is a block reward paid up to the given block, forever if zero.
+type rewardSpec struct {
+	Reward string `yaml:"reward"`
+	Until  uint64 `yaml:"until"`
+}
+
+// validatorSpec is a genesis validator along with its funds.
+type validatorSpec struct {
+	Address string `yaml:"address"`
+	Balance string `yaml:"balance"`
+}
+
+// buildGenesis builds a genesis spec from a declarative YAML file.
+func buildGenesis(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires a YAML spec argument.")
+	}
+	blob, err := ioutil.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read genesis spec: %v", err)
+	}
+	spec := new(genesisSpec)
+	if err := yaml.UnmarshalStrict(blob, spec); err != nil {
+		utils.Fatalf("Invalid genesis spec: %v", err)
+	}
+	if spec.AllocFile != "" && !filepath.IsAbs(spec.AllocFile) {
+		spec.AllocFile = filepath.Join(filepath.Dir(ctx.Args().First()), spec.AllocFile)
+	}
+	genesis, err := spec.genesis()
+	if err != nil {
+		utils.Fatalf("Invalid genesis spec: %v", err)
+	}
+	out, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(ctx.String(genesisOutputFlag.Name), out, 0644); err != nil {
+		utils.Fatalf("Failed to write genesis spec: %v", err)
+	}
+	log.Info("Built genesis spec", "file", ctx.String(genesisOutputFlag.Name), "chainid", genesis.Config.ChainID, "hash", genesis.ToBlock(nil).Hash())
+	return nil
+}
+
+// genesis converts the spec into an equality genesis, configured like the ones
+// of the puppeth wizard.
+func (spec *genesisSpec) genesis() (*core.Genesis, error) {
+	genesis := &core.Genesis{
+		Timestamp:  spec.Timestamp,
+		GasLimit:   spec.GasLimit,
+		Difficulty: big.NewInt(1),
+		Alloc:      make(core.GenesisAlloc),
+		Config: &params.ChainConfig{
+			ChainID:             new(big.Int).SetUint64(spec.ChainID),
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+		},
+	}
+	genesis.ExtraData, _ = core.EqualityExtraData(nil)
+	if genesis.GasLimit == 0 {
+		genesis.GasLimit = 4700000
+	}
+	if spec.ChainID == 0 {
+		if spec.Name == "" {
+			return nil, errors.New("either the name or the chain ID is required")
+		}
+		// Keep the derived chain IDs within 31 bits, some tools choke on larger ones
+		id := uint64(binary.BigEndian.Uint32(crypto.Keccak256([]byte(spec.Name))) >> 1)
+		if id == 0 {
+			id = 1
+		}
+		genesis.Config.ChainID.SetUint64(id)
+	}
+	// Configure the consensus engine
+	config := &params.EqualityConfig{
+		Period:             spec.Equality.Period,
+		Epoch:              spec.Equality.Epoch,
+		MaxValidatorsCount: spec.Equality.MaxValidators,
+		GenesisTimestamp:   spec.Equality.GenesisTimestamp,
+	}
+	if config.Period == 0 {
+		config.Period = 5
+	}
+	if config.Epoch == 0 {
+		config.Epoch = 17280
+	}
+	if config.MaxValidatorsCount == 0 {
+		config.MaxValidatorsCount = 21
+	}
+	if config.GenesisTimestamp == 0 {
+		config.GenesisTimestamp = spec.Timestamp
+	}
+	if spec.Equality.Pool != "" {
+		if !common.IsHexAddress(spec.Equality.Pool) {
+			return nil, fmt.Errorf("invalid pool address %q", spec.Equality.Pool)
+		}
+		config.Pool = common.HexToAddress(spec.Equality.Pool)
+	}
+	var err error
+	if config.MinCandidateBalance, err = parseEthers(spec.Equality.MinCandidateBalance, "100"); err != nil {
+		return nil, fmt.Errorf("invalid min candidate balance: %v", err)
+	}
+	for i, rule := range spec.Equality.Rewards {
+		reward, err := parseEthers(rule.Reward, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid reward #%d: %v", i, err)
+		}
+		until := rule.Until
+		if until == 0 {
+			until = math.MaxUint64
+		}
+		config.Rewards = append(config.Rewards, params.EqualityReward{Number: until, Reward: reward})
+	}
+	if len(config.Rewards) == 0 {
+		config.Rewards = params.EqualityRewards{{Number: math.MaxUint64, Reward: new(big.Int).Mul(big.NewInt(2), big.NewInt(params.Ether))}}
+	}
+	if len(spec.Equality.Validators) == 0 {
+		return nil, errors.New("at least one validator is required")
+	}
+	for i, validator := range spec.Equality.Validators {
+		if !common.IsHexAddress(validator.Address) {
+			return nil, fmt.Errorf("invalid validator #%d address %q", i, validator.Address)
+		}
+		address := common.HexToAddress(validator.Address)
+		config.Validators = append(config.Validators, address)
+
+		// Validators are funded like in puppeth unless told otherwise
+		balance := new(big.Int).Lsh(big.NewInt(1), 256-7) // 2^256 / 128 (allow many pre-funds without balance overflows)
+		if validator.Balance != "" {
+			if balance, err = parseEthers(validator.Balance, ""); err != nil {
+				return nil, fmt.Errorf("invalid validator #%d balance: %v", i, err)
+			}
+		}
+		genesis.Alloc[address] = core.GenesisAccount{Balance: balance}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	genesis.Config.Equality = config
+
+	// Fund the holders listed, the accounts requested and the precompiles if needed
+	if spec.AllocFile != "" {
+		holders, err := core.LoadGenesisAlloc(spec.AllocFile)
+		if err != nil {
+			return nil, err
+		}
+		for address, account := range holders {
+			genesis.Alloc[address] = account
+		}
+	}
+	for account, amount := range spec.Alloc {
+		if !common.IsHexAddress(account) {
+			return nil, fmt.Errorf("invalid alloc address %q", account)
+		}
+		balance, err := parseEthers(amount, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid alloc of %s: %v", account, err)
+		}
+		genesis.Alloc[common.HexToAddress(account)] = core.GenesisAccount{Balance: balance}
+	}
+	if spec.PrefundPrecompiles {
+		// Add a batch of precompile balances to avoid them getting deleted
+		for i := int64(0); i < 256; i++ {
+			address := common.BigToAddress(big.NewInt(i))
+			if _, ok := genesis.Alloc[address]; !ok {
+				genesis.Alloc[address] = core.GenesisAccount{Balance: big.NewInt(1)}
+			}
+		}
+	}
+	return genesis, nil
+}
+
+// parseEthers parses a decimal amount of ethers into wei, using the fallback if
+// the amount is empty.
+func parseEthers(amount string, fallback string) (*big.Int, error) {
+	if amount == "" {
+		amount = fallback
+	}
+	ethers, ok := new(big.Float).SetPrec(256).SetString(amount)
+	if !ok || ethers.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	wei, _ := ethers.Mul(ethers, new(big.Float).SetPrec(256).SetInt64(params.Ether)).Int(nil)
+	return wei, nil
+}
+
+// verifyGenesis checks a genesis spec against a known network or genesis hash.
+func verifyGenesis(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires a genesis.json argument.")
+	}
+	blob, err := ioutil.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read genesis spec: %v", err)
+	}
+	genesis := new(core.Genesis)
+	if err := json.Unmarshal(blob, genesis); err != nil {
+		utils.Fatalf("Invalid genesis spec: %v", err)
+	}
+	var want common.Hash
+	if ctx.IsSet(genesisHashFlag.Name) {
+		hex := ctx.String(genesisHashFlag.Name)
+		if len(common.FromHex(hex)) != common.HashLength {
+			utils.Fatalf("Invalid genesis hash %q", hex)
+		}
+		want = common.HexToHash(hex)
+	}
+	hash, err := checkGenesis(genesis, want)
+	if err != nil {
+		utils.Fatalf("Genesis %x rejected: %v", hash, err)
+	}
+	log.Info("Genesis spec verified", "hash", hash)
+	return nil
+}
+
+// checkGenesis verifies the genesis spec produces the wanted genesis block, the
+// one of a built-in network if no hash is wanted, returning the hash of the
+// block of the spec.
+func checkGenesis(genesis *core.Genesis, want common.Hash) (common.Hash, error) {
+	if genesis.Config == nil {
+		return common.Hash{}, errors.New("chain config missing")
+	}
+	hash := genesis.ToBlock(nil).Hash()
+
+	// The chain config isn't hashed, compare the built-in ones on their own
+	var builtin *params.ChainConfig
+	switch {
+	case want != (common.Hash{}) && hash != want:
+		return hash, fmt.Errorf("genesis hash mismatch: want %x", want)
+	case hash == params.MainnetGenesisHash:
+		builtin = params.MainnetChainConfig
+	case hash == params.TestnetGenesisHash:
+		builtin = params.TestnetChainConfig
+	case want == (common.Hash{}):
+		return hash, errors.New("genesis of no known network, pass the expected hash")
+	}
+	config := genesis.Config.Equality
+	if config == nil {
+		if builtin != nil && builtin.Equality != nil {
+			return hash, errors.New("equality section missing")
+		}
+		return hash, nil
+	}
+	if builtin == nil {
+		if err := config.Validate(); err != nil {
+			return hash, err
+		}
+	} else if builtin.Equality == nil || !config.Equal(*builtin.Equality) {
+		return hash, errors.New("equality section differs from the one of the network")
+	}
+	// The config is recorded in the first equality header, check it round trips
+	blob, err := rlp.EncodeToBytes(config)
+	if err != nil {
+		return hash, fmt.Errorf("failed to encode equality section: %v", err)
+	}
+	decoded := new(params.EqualityConfig)
+	if err := rlp.DecodeBytes(blob, decoded); err != nil {
+		return hash, fmt.Errorf("failed to decode equality section: %v", err)
+	}
+	if !decoded.Equal(*config) {
+		return hash, errors.New("equality section changes once encoded in the headers")
+	}
+	return hash, nil
+}
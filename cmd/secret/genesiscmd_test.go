@@ -0,0 +1,126 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core"
+	"gopkg.in/yaml.v2"
+)
+
+const testGenesisSpec = `
+name: testnet
+timestamp: 1609459200
+equality:
+  pool: "0x1111111111111111111111111111111111111111"
+  minCandidateBalance: "0.5"
+  validators:
+    - address: "0xcc7c8317b21e1cea6139700c3c46c21af998d14c"
+alloc:
+  "0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c": "500"
+prefundPrecompiles: true
+`
+
+func TestBuildGenesis(t *testing.T) {
+	build := func(blob string) *genesisSpec {
+		spec := new(genesisSpec)
+		if err := yaml.UnmarshalStrict([]byte(blob), spec); err != nil {
+			t.Fatalf("failed to parse spec: %v", err)
+		}
+		return spec
+	}
+	spec := build(testGenesisSpec)
+	genesis, err := spec.genesis()
+	if err != nil {
+		t.Fatalf("failed to build genesis: %v", err)
+	}
+	// The same spec always yields the same genesis block
+	again, _ := build(testGenesisSpec).genesis()
+	if genesis.ToBlock(nil).Hash() != again.ToBlock(nil).Hash() {
+		t.Errorf("genesis not deterministic")
+	}
+	if genesis.Config.ChainID.Sign() <= 0 || genesis.Config.ChainID.BitLen() > 31 {
+		t.Errorf("derived chain ID out of range: %v", genesis.Config.ChainID)
+	}
+	if have, want := genesis.Config.Equality.MinCandidateBalance, big.NewInt(5e17); have.Cmp(want) != 0 {
+		t.Errorf("min candidate balance mismatch: have %v, want %v", have, want)
+	}
+	if have, want := genesis.Alloc[common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")].Balance, new(big.Int).Mul(big.NewInt(500), big.NewInt(1e18)); have.Cmp(want) != 0 {
+		t.Errorf("alloc mismatch: have %v, want %v", have, want)
+	}
+	if have, want := len(genesis.Alloc), 258; have != want {
+		t.Errorf("alloc count mismatch: have %d, want %d", have, want)
+	}
+	// Explicit chain IDs win, invalid specs are rejected
+	spec.ChainID = 1337
+	if genesis, _ = spec.genesis(); genesis.Config.ChainID.Uint64() != 1337 {
+		t.Errorf("chain ID mismatch: have %v, want 1337", genesis.Config.ChainID)
+	}
+	spec.Equality.Validators = nil
+	if _, err := spec.genesis(); err == nil {
+		t.Errorf("genesis without validators accepted")
+	}
+}
+
+func TestCheckGenesis(t *testing.T) {
+	// The built-in networks verify through their JSON specs
+	for _, genesis := range []*core.Genesis{core.DefaultGenesisBlock(), core.DefaultTestnetGenesisBlock()} {
+		blob, err := json.Marshal(genesis)
+		if err != nil {
+			t.Fatalf("failed to encode genesis: %v", err)
+		}
+		spec := new(core.Genesis)
+		if err := json.Unmarshal(blob, spec); err != nil {
+			t.Fatalf("failed to decode genesis: %v", err)
+		}
+		if _, err := checkGenesis(spec, common.Hash{}); err != nil {
+			t.Errorf("built-in genesis rejected: %v", err)
+		}
+	}
+	// The equality section of a built-in network is checked, not being hashed
+	genesis := core.DefaultGenesisBlock()
+	config := *genesis.Config
+	equality := *config.Equality
+	equality.Period++
+	config.Equality, genesis.Config = &equality, &config
+	if _, err := checkGenesis(genesis, common.Hash{}); err == nil {
+		t.Error("modified mainnet equality section accepted")
+	}
+	// Custom networks need the expected hash
+	spec := new(genesisSpec)
+	if err := yaml.UnmarshalStrict([]byte(testGenesisSpec), spec); err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+	custom, err := spec.genesis()
+	if err != nil {
+		t.Fatalf("failed to build genesis: %v", err)
+	}
+	if _, err := checkGenesis(custom, common.Hash{}); err == nil {
+		t.Error("unknown genesis accepted without hash")
+	}
+	hash := custom.ToBlock(nil).Hash()
+	if have, err := checkGenesis(custom, hash); err != nil || have != hash {
+		t.Errorf("custom genesis rejected: %v", err)
+	}
+	if _, err := checkGenesis(custom, common.Hash{0x01}); err == nil {
+		t.Error("genesis hash mismatch accepted")
+	}
+}
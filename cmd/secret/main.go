@@ -69,6 +69,10 @@ var (
 		utils.ExternalSignerFlag,
 		utils.NoUSBFlag,
 		utils.SmartCardDaemonPathFlag,
+		utils.PKCS11ModuleFlag,
+		utils.PKCS11SlotFlag,
+		utils.PKCS11PINFileFlag,
+		utils.PKCS11LabelFlag,
 		utils.EthashCacheDirFlag,
 		utils.EthashCachesInMemoryFlag,
 		utils.EthashCachesOnDiskFlag,
@@ -91,6 +95,8 @@ var (
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
+		utils.EqualityGCModeFlag,
+		utils.EqualityHeaderOnlyFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
 		utils.LightServeFlag,
@@ -100,11 +106,19 @@ var (
 		utils.LightMaxPeersFlag,
 		utils.LegacyLightPeersFlag,
 		utils.LightNoPruneFlag,
+		utils.LightOracleFlag,
+		utils.LightOracleSignersFlag,
+		utils.LightOracleThresholdFlag,
 		utils.LightKDFFlag,
 		utils.UltraLightServersFlag,
 		utils.UltraLightFractionFlag,
 		utils.UltraLightOnlyAnnounceFlag,
 		utils.WhitelistFlag,
+		utils.OverrideEqualityBlockFlag,
+		utils.OverrideEqualityPeriodFlag,
+		utils.OverrideEqualityEpochFlag,
+		utils.OverrideEqualityPoolFlag,
+		utils.OverrideEqualityResumeFlag,
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
 		utils.CacheTrieFlag,
@@ -116,6 +130,7 @@ var (
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
+		utils.MaxClockDriftFlag,
 		utils.MiningEnabledFlag,
 		utils.MinerThreadsFlag,
 		utils.LegacyMinerThreadsFlag,
@@ -128,6 +143,22 @@ var (
 		utils.MinerEtherbaseFlag,
 		utils.LegacyMinerEtherbaseFlag,
 		utils.MinerExtraDataFlag,
+		utils.MinerVanityFlag,
+		utils.MinerSealKeyFlag,
+		utils.MinerSignerURLFlag,
+		utils.MinerSignerCertFlag,
+		utils.MinerSignerKeyFlag,
+		utils.MinerSignerCAFlag,
+		utils.MinerSignerWalletFlag,
+		utils.MinerSignerPathFlag,
+		utils.MinerSignerUnlockFlag,
+		utils.MinerSignGuardFlag,
+		utils.MinerCoSignersFlag,
+		utils.MinerCoSignerThresholdFlag,
+		utils.MinerPolicyHeightFlag,
+		utils.MinerPolicyDriftFlag,
+		utils.MinerPolicyValidatorsFlag,
+		utils.MinerConsensusTxQuotaFlag,
 		utils.LegacyMinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerfiyFlag,
@@ -140,8 +171,10 @@ var (
 		utils.DNSDiscoveryFlag,
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
+		utils.DeveloperEqualityFlag,
 		utils.LegacyTestnetFlag,
 		utils.LocalnetFlag,
+		utils.ChainSpecFlag,
 		utils.VMEnableDebugFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
@@ -227,9 +260,16 @@ func init() {
 		dumpCommand,
 		dumpGenesisCommand,
 		inspectCommand,
+		checkpointCommand,
+		// See snapshotcmd.go:
+		snapshotCommand,
+		// See genesiscmd.go:
+		genesisCommand,
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
+		// See validatorcmd.go:
+		validatorCommand,
 		// See consolecmd.go:
 		consoleCommand,
 		attachCommand,
@@ -288,20 +328,23 @@ func prepare(ctx *cli.Context) {
 	case ctx.GlobalIsSet(utils.DeveloperFlag.Name):
 		log.Info("Starting Secret in ephemeral dev mode...")
 
+	case ctx.GlobalIsSet(utils.ChainSpecFlag.Name):
+		log.Info("Starting Secret on custom network...", "spec", ctx.GlobalString(utils.ChainSpecFlag.Name))
+
 	case !ctx.GlobalIsSet(utils.NetworkIdFlag.Name):
 		log.Info("Starting Secret on Secret mainnet...")
 	}
 	// If we're a full node on mainnet without --cache specified, bump default cache allowance
-	if ctx.GlobalString(utils.SyncModeFlag.Name) != "light" && !ctx.GlobalIsSet(utils.CacheFlag.Name) && !ctx.GlobalIsSet(utils.NetworkIdFlag.Name) {
+	if !utils.IsLightClient(ctx) && !ctx.GlobalIsSet(utils.CacheFlag.Name) && !ctx.GlobalIsSet(utils.NetworkIdFlag.Name) {
 		// Make sure we're not on any supported preconfigured testnet either
-		if !ctx.GlobalIsSet(utils.LegacyTestnetFlag.Name) && !ctx.GlobalIsSet(utils.LocalnetFlag.Name) && !ctx.GlobalIsSet(utils.DeveloperFlag.Name) {
+		if !ctx.GlobalIsSet(utils.LegacyTestnetFlag.Name) && !ctx.GlobalIsSet(utils.LocalnetFlag.Name) && !ctx.GlobalIsSet(utils.DeveloperFlag.Name) && !ctx.GlobalIsSet(utils.ChainSpecFlag.Name) {
 			// Nope, we're really on mainnet. Bump that cache up!
 			log.Info("Bumping default cache on mainnet", "provided", ctx.GlobalInt(utils.CacheFlag.Name), "updated", 4096)
 			ctx.GlobalSet(utils.CacheFlag.Name, strconv.Itoa(4096))
 		}
 	}
 	// If we're running a light client on any network, drop the cache to some meaningfully low amount
-	if ctx.GlobalString(utils.SyncModeFlag.Name) == "light" && !ctx.GlobalIsSet(utils.CacheFlag.Name) {
+	if utils.IsLightClient(ctx) && !ctx.GlobalIsSet(utils.CacheFlag.Name) {
 		log.Info("Dropping default light client cache", "provided", ctx.GlobalInt(utils.CacheFlag.Name), "updated", 128)
 		ctx.GlobalSet(utils.CacheFlag.Name, strconv.Itoa(128))
 	}
@@ -432,7 +475,7 @@ func startNode(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
 	// Start auxiliary services if enabled
 	if ctx.GlobalBool(utils.MiningEnabledFlag.Name) || ctx.GlobalBool(utils.DeveloperFlag.Name) {
 		// Mining only makes sense if a full Ethereum node is running
-		if ctx.GlobalString(utils.SyncModeFlag.Name) == "light" {
+		if utils.IsLightClient(ctx) {
 			utils.Fatalf("Light clients do not support mining")
 		}
 		ethBackend, ok := backend.(*eth.EthAPIBackend)
@@ -0,0 +1,219 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/cmd/utils"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/ethdb"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	snapshotCommand = cli.Command{
+		Name:     "snapshot",
+		Usage:    "Inspect and repair the equality consensus snapshots",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The snapshot commands read the consensus snapshots of the equality engine straight
+from the database, e.g. to debug a consensus split on a stopped node, or repair the
+damaged ones.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "inspect",
+				Usage:     "Print the content of the consensus snapshot of a block",
+				ArgsUsage: "[<blockNum>|<blockHash>]",
+				Action:    utils.MigrateFlags(inspectSnapshot),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.CacheFlag,
+					utils.LocalnetFlag,
+					utils.ChainSpecFlag,
+					utils.LegacyTestnetFlag,
+					utils.SyncModeFlag,
+					snapshotRootsFlag,
+					snapshotVerifyFlag,
+				},
+				Description: `
+Prints the validators, the candidates, the mint counts of every epoch and the
+chain config of the snapshot committed in the header of the given block, or of
+the head block if none is given.
+
+With --roots, the snapshot of the given trie roots is printed instead, e.g. the
+ones committed by the block of a peer the local node disagrees with. With --verify,
+all the trie nodes of the snapshot are checked to exist in the database.`,
+			},
+			{
+				Name:      "repair",
+				Usage:     "Regenerate the damaged consensus snapshots of the recent blocks",
+				ArgsUsage: "[<blockNum>|<blockHash>]",
+				Action:    utils.MigrateFlags(repairSnapshots),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.CacheFlag,
+					utils.LocalnetFlag,
+					utils.ChainSpecFlag,
+					utils.LegacyTestnetFlag,
+					utils.SyncModeFlag,
+					utils.EqualityGCModeFlag,
+					snapshotDepthFlag,
+				},
+				Description: `
+Checks the trie nodes of the snapshot of the given block, or of the head block if
+none is given, and of the epoch blocks among its --depth ancestors. The snapshots
+with missing or corrupt nodes are regenerated by replaying the headers on top of
+the last intact epoch snapshot, sparing a full resync.`,
+			},
+		},
+	}
+	snapshotRootsFlag = cli.StringFlag{
+		Name:  "roots",
+		Usage: "Comma separated epoch, candidate, mint count and config trie roots of the snapshot",
+	}
+	snapshotVerifyFlag = cli.BoolFlag{
+		Name:  "verify",
+		Usage: "Verify that all the trie nodes of the snapshot exist in the database",
+	}
+	snapshotDepthFlag = cli.Uint64Flag{
+		Name:  "depth",
+		Usage: "Number of recent blocks whose epoch snapshots are checked",
+		Value: 4096,
+	}
+)
+
+// inspectSnapshot prints the content of the consensus snapshot of a block, or
+// of the given trie roots.
+func inspectSnapshot(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		utils.Fatalf("This command requires at most one argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	var root equality.Root
+	if roots := ctx.String(snapshotRootsFlag.Name); roots != "" {
+		hashes := strings.Split(roots, ",")
+		if len(hashes) != 4 {
+			utils.Fatalf("Invalid snapshot roots, expected 4 hashes: %s", roots)
+		}
+		root = equality.Root{
+			EpochHash:     common.HexToHash(hashes[0]),
+			CandidateHash: common.HexToHash(hashes[1]),
+			MintCntHash:   common.HexToHash(hashes[2]),
+			ConfigHash:    common.HexToHash(hashes[3]),
+		}
+	} else {
+		header := snapshotHeader(db, ctx.Args().First())
+		headerExtra, err := equality.DecodeHeaderExtra(header)
+		if err != nil {
+			utils.Fatalf("Block #%d carries no equality snapshot: %v", header.Number, err)
+		}
+		root = headerExtra.Root
+		fmt.Printf("Snapshot of block #%d [%x…], epoch %d opened at block #%d\n", header.Number, header.Hash().Bytes()[:4], headerExtra.Epoch, headerExtra.EpochBlock)
+	}
+	if ctx.Bool(snapshotVerifyFlag.Name) {
+		nodes, err := equality.VerifySnapshot(db, root)
+		if err != nil {
+			utils.Fatalf("Snapshot incomplete after %d trie nodes: %v", nodes, err)
+		}
+		fmt.Printf("Verified %d trie nodes\n", nodes)
+	}
+	inspection, err := equality.InspectSnapshot(db, root)
+	if err != nil {
+		utils.Fatalf("Failed to inspect the snapshot: %v", err)
+	}
+	out, err := json.MarshalIndent(inspection, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// repairSnapshots regenerates the damaged consensus snapshots of the recent blocks.
+func repairSnapshots(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		utils.Fatalf("This command requires at most one argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+	defer db.Close()
+
+	engine, ok := chain.Engine().(interface {
+		RepairSnapshots(chain consensus.ChainHeaderReader, header *types.Header, depth uint64) (int, error)
+	})
+	if !ok {
+		utils.Fatalf("Consensus engine has no snapshots")
+	}
+	header := snapshotHeader(db, ctx.Args().First())
+
+	start := time.Now()
+	damaged, err := engine.RepairSnapshots(chain, header, ctx.Uint64(snapshotDepthFlag.Name))
+	chain.Stop()
+	if cerr := chain.Engine().Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		utils.Fatalf("Failed to repair the snapshots: %v", err)
+	}
+	if damaged == 0 {
+		fmt.Printf("No damaged snapshot found up to block #%d\n", header.Number)
+	} else {
+		fmt.Printf("Repaired %d damaged snapshots up to block #%d in %v\n", damaged, header.Number, time.Since(start))
+	}
+	return nil
+}
+
+// snapshotHeader retrieves the canonical header of the given block number, the
+// header of the given block hash, or the head header if no block is given.
+func snapshotHeader(db ethdb.Database, arg string) *types.Header {
+	var hash common.Hash
+	switch {
+	case arg == "":
+		arg, hash = "head", rawdb.ReadHeadHeaderHash(db)
+	case hashish(arg):
+		hash = common.HexToHash(arg)
+	default:
+		number, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			utils.Fatalf("Invalid block number: %v", err)
+		}
+		hash = rawdb.ReadCanonicalHash(db, number)
+	}
+	number := rawdb.ReadHeaderNumber(db, hash)
+	if number == nil {
+		utils.Fatalf("Block %s not found", arg)
+	}
+	return rawdb.ReadHeader(db, hash, *number)
+}
@@ -39,16 +39,28 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.KeyStoreDirFlag,
 			utils.NoUSBFlag,
 			utils.SmartCardDaemonPathFlag,
+			utils.PKCS11ModuleFlag,
+			utils.PKCS11SlotFlag,
+			utils.PKCS11PINFileFlag,
+			utils.PKCS11LabelFlag,
 			utils.NetworkIdFlag,
 			utils.LocalnetFlag,
+			utils.ChainSpecFlag,
 			utils.SyncModeFlag,
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
+			utils.EqualityGCModeFlag,
+			utils.EqualityHeaderOnlyFlag,
 			utils.TxLookupLimitFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
 			utils.WhitelistFlag,
+			utils.OverrideEqualityBlockFlag,
+			utils.OverrideEqualityPeriodFlag,
+			utils.OverrideEqualityEpochFlag,
+			utils.OverrideEqualityPoolFlag,
+			utils.OverrideEqualityResumeFlag,
 		},
 	},
 	{
@@ -62,6 +74,9 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.UltraLightFractionFlag,
 			utils.UltraLightOnlyAnnounceFlag,
 			utils.LightNoPruneFlag,
+			utils.LightOracleFlag,
+			utils.LightOracleSignersFlag,
+			utils.LightOracleThresholdFlag,
 		},
 	},
 	{
@@ -69,6 +84,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Flags: []cli.Flag{
 			utils.DeveloperFlag,
 			utils.DeveloperPeriodFlag,
+			utils.DeveloperEqualityFlag,
 		},
 	},
 	{
@@ -158,6 +174,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.ListenPortFlag,
 			utils.MaxPeersFlag,
 			utils.MaxPendingPeersFlag,
+			utils.MaxClockDriftFlag,
 			utils.NATFlag,
 			utils.NoDiscoverFlag,
 			utils.DiscoveryV5Flag,
@@ -177,6 +194,22 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerGasLimitFlag,
 			utils.MinerEtherbaseFlag,
 			utils.MinerExtraDataFlag,
+			utils.MinerVanityFlag,
+			utils.MinerSealKeyFlag,
+			utils.MinerSignerURLFlag,
+			utils.MinerSignerCertFlag,
+			utils.MinerSignerKeyFlag,
+			utils.MinerSignerCAFlag,
+			utils.MinerSignerWalletFlag,
+			utils.MinerSignerPathFlag,
+			utils.MinerSignerUnlockFlag,
+			utils.MinerSignGuardFlag,
+			utils.MinerCoSignersFlag,
+			utils.MinerCoSignerThresholdFlag,
+			utils.MinerPolicyHeightFlag,
+			utils.MinerPolicyDriftFlag,
+			utils.MinerPolicyValidatorsFlag,
+			utils.MinerConsensusTxQuotaFlag,
 			utils.MinerRecommitIntervalFlag,
 			utils.MinerNoVerfiyFlag,
 		},
@@ -0,0 +1,469 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/SecretBlockChain/go-secret"
+	"github.com/SecretBlockChain/go-secret/accounts/keystore"
+	"github.com/SecretBlockChain/go-secret/cmd/utils"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/ethclient"
+	"github.com/SecretBlockChain/go-secret/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	validatorFlags = []cli.Flag{
+		utils.DataDirFlag,
+		utils.KeyStoreDirFlag,
+		utils.PasswordFileFlag,
+		utils.LightKDFFlag,
+		utils.LocalnetFlag,
+		validatorEndpointFlag,
+	}
+	validatorEndpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "RPC endpoint of the node submitting the transaction (default = IPC endpoint of the datadir)",
+	}
+
+	validatorCommand = cli.Command{
+		Name:     "validator",
+		Usage:    "Manage the equality candidacy of accounts",
+		Category: "ACCOUNT COMMANDS",
+		Description: `
+The validator commands sign the custom transactions of the equality engine with
+an account of the keystore and submit them through a running node, by default the
+one of the datadir.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "register",
+				Usage:     "Register an account as validator candidate",
+				ArgsUsage: "<address>",
+				Action:    utils.MigrateFlags(validatorRegister),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator register <address>
+
+Registers the account as validator candidate, locking the minimum candidate
+balance of the network as security deposit until the candidacy is canceled.`,
+			},
+			{
+				Name:      "cancel",
+				Usage:     "Cancel the candidacy of an account",
+				ArgsUsage: "<address>",
+				Action:    utils.MigrateFlags(validatorCancel),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator cancel <address>
+
+Cancels the candidacy of the account, refunding its security deposit.`,
+			},
+			{
+				Name:      "rotate",
+				Usage:     "Bind a new signing key to a candidate",
+				ArgsUsage: "<address> <key>",
+				Action:    utils.MigrateFlags(validatorRotate),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator rotate <address> <key>
+
+Binds the signing key to the candidate account, the key sealing the slots of the
+candidate from the next epoch on while the account keeps its deposit and seat.
+Binding the address of the account itself restores its own key. Nodes sealing
+with the key are started with --miner.etherbase <address> --miner.sealkey <key>.`,
+			},
+			{
+				Name:      "payout",
+				Usage:     "Credit the rewards of a candidate to another address",
+				ArgsUsage: "<address> <recipient>",
+				Action:    utils.MigrateFlags(validatorPayout),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator payout <address> <recipient>
+
+Credits the rewards of the blocks minted by the candidate account to the
+recipient, e.g. a cold wallet, from the next block on. Setting the address of
+the account itself credits the rewards to the coinbase again.`,
+			},
+			{
+				Name:      "propose",
+				Usage:     "Propose a new equality config to the validators",
+				ArgsUsage: "<address> <config.json>",
+				Action:    utils.MigrateFlags(validatorPropose),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator propose <address> <config.json>
+
+Submits the equality config of the JSON file to the vote of the validators, the
+genesis timestamp and validators being kept. The hash of the transaction printed
+identifies the proposal, activated in the first block of an epoch once approved
+by the quorum of the validators, two thirds by default, within the proposal
+window of the network. Proposals still pending past the window expire in the
+first block of the next epoch. Proposals lock the deposit the network requires,
+if any, refunded once approved and burned otherwise.`,
+			},
+			{
+				Name:      "halt",
+				Usage:     "Propose to halt the chain after a height to the validators",
+				ArgsUsage: "<address> <height>",
+				Action:    utils.MigrateFlags(validatorHalt),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator halt <address> <height>
+
+Submits an emergency halt of the chain to the vote of the validators. The hash
+of the transaction printed identifies the proposal, set as soon as approved by a
+supermajority of the validators, two thirds at least. Validators then refuse to
+seal and nodes to import any block after the height, until resumed by an
+override agreed on off-chain with --override.equality.resume.`,
+			},
+			{
+				Name:      "fork",
+				Usage:     "Propose to schedule a protocol fork to the validators",
+				ArgsUsage: "<address> <fork> <block>",
+				Action:    utils.MigrateFlags(validatorFork),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator fork <address> <fork> <block>
+
+Submits the activation of the protocol fork (berlin or london) at the block to
+the vote of the validators. The hash of the transaction printed identifies the
+proposal, written into the chain config of every node in the first block of an
+epoch once approved by the quorum of the validators, if the block is still
+ahead by then.`,
+			},
+			{
+				Name:      "signal",
+				Usage:     "Poll the validators on a text without binding effect",
+				ArgsUsage: "<address> <text file>",
+				Action:    utils.MigrateFlags(validatorSignal),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator signal <address> <text file>
+
+Submits the keccak256 hash of the text file to the vote of the validators, to
+gauge their sentiment before a binding proposal. The text itself is published
+off-chain. The hash of the transaction printed identifies the proposal, closed
+in the first block of an epoch once approved by the quorum of the validators,
+nothing being executed, or expired past the proposal window. Signals lock the
+deposit the network requires like the other proposals.`,
+			},
+			{
+				Name:      "spend",
+				Usage:     "Propose to transfer an amount from the pool to the validators",
+				ArgsUsage: "<address> <recipient> <amount>",
+				Action:    utils.MigrateFlags(validatorSpend),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator spend <address> <recipient> <amount>
+
+Submits the transfer of the amount, in wei, from the pool collecting the share
+of the block rewards to the recipient to the vote of the validators. The hash of
+the transaction printed identifies the proposal, transferred in the first block
+of an epoch once approved by the quorum of the validators, as soon as the pool
+affords it within the proposal window.`,
+			},
+			{
+				Name:      "vote",
+				Usage:     "Vote on a governance proposal as validator",
+				ArgsUsage: "<address> <proposal> <yes|no>",
+				Action:    utils.MigrateFlags(validatorVote),
+				Flags:     validatorFlags,
+				Description: `
+    secret validator vote <address> <proposal> <yes|no>
+
+Approves or rejects the config, halt, fork, signal or spend proposal on behalf of the validator account, a
+later vote replacing the previous one. Only the validators of the current epoch
+may vote.`,
+			},
+		},
+	}
+)
+
+// candidateStatus is the candidate information of an account and the config of
+// the equality engine at the head of the node.
+type candidateStatus struct {
+	client  *ethclient.Client
+	account common.Address
+	info    struct {
+		IsCandidate bool `json:"isCandidate"`
+	}
+	config params.EqualityConfig
+}
+
+func validatorRegister(ctx *cli.Context) error {
+	return submitCandidateTransaction(ctx, new(equality.EventBecomeCandidate), func(status *candidateStatus) {
+		if status.info.IsCandidate {
+			utils.Fatalf("Account %s is already a candidate", status.account.Hex())
+		}
+		balance, err := status.client.BalanceAt(context.Background(), status.account, nil)
+		if err != nil {
+			utils.Fatalf("Failed to retrieve the account balance: %v", err)
+		}
+		// The deposit is silently skipped if the balance is short when sealed
+		if balance.Cmp(status.config.MinCandidateBalance) <= 0 {
+			utils.Fatalf("Balance of %s too low for the %v wei candidate deposit and the fees", status.account.Hex(), status.config.MinCandidateBalance)
+		}
+	})
+}
+
+func validatorCancel(ctx *cli.Context) error {
+	return submitCandidateTransaction(ctx, new(equality.EventCancelCandidate), func(status *candidateStatus) {
+		if !status.info.IsCandidate {
+			utils.Fatalf("Account %s is not a candidate", status.account.Hex())
+		}
+	})
+}
+
+func validatorRotate(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 || !common.IsHexAddress(ctx.Args().Get(1)) {
+		utils.Fatalf("This command requires an address and a key argument.")
+	}
+	event := &equality.EventBindSigner{Key: common.HexToAddress(ctx.Args().Get(1))}
+	return submitCandidateTransaction(ctx, event, func(status *candidateStatus) {
+		if !status.info.IsCandidate {
+			utils.Fatalf("Account %s is not a candidate", status.account.Hex())
+		}
+	})
+}
+
+func validatorPayout(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 || !common.IsHexAddress(ctx.Args().Get(1)) {
+		utils.Fatalf("This command requires an address and a recipient argument.")
+	}
+	event := &equality.EventSetPayout{Recipient: common.HexToAddress(ctx.Args().Get(1))}
+	return submitCandidateTransaction(ctx, event, func(status *candidateStatus) {
+		if !status.info.IsCandidate {
+			utils.Fatalf("Account %s is not a candidate", status.account.Hex())
+		}
+	})
+}
+
+func validatorPropose(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		utils.Fatalf("This command requires an address and a config file argument.")
+	}
+	data, err := ioutil.ReadFile(ctx.Args().Get(1))
+	if err != nil {
+		utils.Fatalf("Failed to read config file: %v", err)
+	}
+	event := new(equality.EventPropose)
+	if err := json.Unmarshal(data, &event.Config); err != nil {
+		utils.Fatalf("Invalid config file: %v", err)
+	}
+	return submitCandidateTransaction(ctx, event, checkProposal)
+}
+
+func validatorHalt(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		utils.Fatalf("This command requires an address and a height argument.")
+	}
+	height, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	if err != nil || height == 0 {
+		utils.Fatalf("Invalid height %s", ctx.Args().Get(1))
+	}
+	return submitCandidateTransaction(ctx, &equality.EventHalt{Height: height}, checkProposal)
+}
+
+func validatorFork(ctx *cli.Context) error {
+	if len(ctx.Args()) != 3 {
+		utils.Fatalf("This command requires an address, a fork and a block argument.")
+	}
+	block, err := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if err != nil || block == 0 {
+		utils.Fatalf("Invalid block %s", ctx.Args().Get(2))
+	}
+	event := &equality.EventFork{Fork: ctx.Args().Get(1), Block: block}
+	if !schedulableFork(event.Fork) {
+		utils.Fatalf("Fork %s can't be scheduled, only %s", event.Fork, strings.Join(params.SchedulableForks, ", "))
+	}
+	return submitCandidateTransaction(ctx, event, checkProposal)
+}
+
+func validatorSignal(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		utils.Fatalf("This command requires an address and a text file argument.")
+	}
+	text, err := ioutil.ReadFile(ctx.Args().Get(1))
+	if err != nil {
+		utils.Fatalf("Failed to read text file: %v", err)
+	}
+	event := &equality.EventSignal{Text: crypto.Keccak256Hash(text)}
+	fmt.Printf("Text hash: %s\n", event.Text.Hex())
+	return submitCandidateTransaction(ctx, event, checkProposal)
+}
+
+func validatorSpend(ctx *cli.Context) error {
+	if len(ctx.Args()) != 3 {
+		utils.Fatalf("This command requires an address, a recipient and an amount argument.")
+	}
+	if !common.IsHexAddress(ctx.Args().Get(1)) {
+		utils.Fatalf("Invalid recipient %s", ctx.Args().Get(1))
+	}
+	amount, ok := new(big.Int).SetString(ctx.Args().Get(2), 10)
+	if !ok || amount.Sign() <= 0 {
+		utils.Fatalf("Invalid amount %s", ctx.Args().Get(2))
+	}
+	event := &equality.EventSpend{Recipient: common.HexToAddress(ctx.Args().Get(1)), Amount: amount}
+	return submitCandidateTransaction(ctx, event, func(status *candidateStatus) {
+		checkProposal(status)
+		if status.config.Pool == (common.Address{}) {
+			utils.Fatalf("The network has no pool to spend from")
+		}
+	})
+}
+
+func validatorVote(ctx *cli.Context) error {
+	if len(ctx.Args()) != 3 {
+		utils.Fatalf("This command requires an address, a proposal and a vote argument.")
+	}
+	id, err := hexutil.Decode(ctx.Args().Get(1))
+	if err != nil || len(id) != common.HashLength {
+		utils.Fatalf("Invalid proposal %s", ctx.Args().Get(1))
+	}
+	event := &equality.EventVote{ID: common.BytesToHash(id)}
+	switch ctx.Args().Get(2) {
+	case "yes":
+		event.Approve = true
+	case "no":
+	default:
+		utils.Fatalf("Votes are either yes or no")
+	}
+	return submitCandidateTransaction(ctx, event, func(status *candidateStatus) {
+		if status.config.ProposalWindow == 0 {
+			utils.Fatalf("Governance is disabled on this network")
+		}
+	})
+}
+
+// checkProposal ensures the network runs governance and the account affords the
+// proposal deposit.
+func checkProposal(status *candidateStatus) {
+	if status.config.ProposalWindow == 0 {
+		utils.Fatalf("Governance is disabled on this network")
+	}
+	deposit := status.config.RequiredDeposit()
+	if deposit.Sign() == 0 {
+		return
+	}
+	balance, err := status.client.BalanceAt(context.Background(), status.account, nil)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve the account balance: %v", err)
+	}
+	// The proposal is silently skipped if the balance is short when sealed
+	if balance.Cmp(deposit) <= 0 {
+		utils.Fatalf("Balance of %s too low for the %v wei proposal deposit and the fees", status.account.Hex(), deposit)
+	}
+}
+
+// schedulableFork returns whether the validators may schedule the fork.
+func schedulableFork(name string) bool {
+	for _, fork := range params.SchedulableForks {
+		if fork == name {
+			return true
+		}
+	}
+	return false
+}
+
+// submitCandidateTransaction signs the custom transaction of the event with the
+// account given as argument and submits it through the node, once the status of
+// the account passes the check.
+func submitCandidateTransaction(ctx *cli.Context, event equality.Transaction, check func(*candidateStatus)) error {
+	if len(ctx.Args()) == 0 {
+		utils.Fatalf("This command requires an address argument.")
+	}
+	// Open the keystore without the node, which is likely running
+	cfg := defaultNodeConfig()
+	utils.SetNodeConfig(ctx, &cfg)
+
+	scryptN, scryptP, keydir, err := cfg.AccountConfig()
+	if err != nil {
+		utils.Fatalf("Failed to read configuration: %v", err)
+	}
+	ks := keystore.NewKeyStore(keydir, scryptN, scryptP)
+	account, _ := unlockAccount(ks, ctx.Args().First(), 0, utils.MakePasswordList(ctx))
+
+	// Attach to the node and check the candidacy of the account
+	endpoint := ctx.String(validatorEndpointFlag.Name)
+	if endpoint == "" {
+		endpoint = cfg.IPCEndpoint()
+	}
+	rpcClient, err := dialRPC(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to node: %v", err)
+	}
+	defer rpcClient.Close()
+
+	status := &candidateStatus{client: ethclient.NewClient(rpcClient), account: account.Address}
+	if err := rpcClient.Call(&status.info, "eq_getAddress", account.Address, "latest"); err != nil {
+		utils.Fatalf("Failed to retrieve the candidacy of %s: %v", account.Address.Hex(), err)
+	}
+	var snapshot struct {
+		Config params.EqualityConfig `json:"config"`
+	}
+	if err := rpcClient.Call(&snapshot, "eq_dumpSnapshot", "latest"); err != nil {
+		utils.Fatalf("Failed to retrieve the equality config: %v", err)
+	}
+	status.config = snapshot.Config
+	check(status)
+
+	// Assemble the transaction to the account itself, carrying the event only
+	var (
+		client = status.client
+		data   = equality.EncodeTransaction(event)
+		bg     = context.Background()
+	)
+	chainID, err := client.ChainID(bg)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve the chain ID: %v", err)
+	}
+	nonce, err := client.PendingNonceAt(bg, account.Address)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve the account nonce: %v", err)
+	}
+	price, err := client.SuggestGasPrice(bg)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve the gas price: %v", err)
+	}
+	gas, err := client.EstimateGas(bg, ethereum.CallMsg{From: account.Address, To: &account.Address, GasPrice: price, Data: data})
+	if err != nil {
+		utils.Fatalf("Failed to estimate the gas: %v", err)
+	}
+	tx := types.NewTransaction(nonce, account.Address, new(big.Int), gas, price, data)
+	signed, err := ks.SignTx(account, tx, chainID)
+	if err != nil {
+		utils.Fatalf("Failed to sign the transaction: %v", err)
+	}
+	if err := client.SendTransaction(bg, signed); err != nil {
+		utils.Fatalf("Failed to submit the transaction: %v", err)
+	}
+	fmt.Printf("Submitted transaction %s (%s)\n", signed.Hash().Hex(), data)
+	return nil
+}
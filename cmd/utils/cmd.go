@@ -19,6 +19,7 @@ package utils
 
 import (
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -28,6 +29,8 @@ import (
 	"syscall"
 
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/types"
@@ -237,6 +240,119 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 	return nil
 }
 
+// snapshotEngine is implemented by the consensus engines committing snapshots
+// in the block headers, which have to be carried along with the blocks.
+type snapshotEngine interface {
+	ExportSnapshot(header *types.Header) ([]byte, error)
+	ImportSnapshot(header *types.Header, data []byte) error
+	EnsureSnapshot(chain consensus.ChainHeaderReader, header *types.Header) error
+}
+
+// snapshotRecord is an epoch block header along with its serialized snapshot.
+type snapshotRecord struct {
+	Header *types.Header
+	Data   []byte
+}
+
+// ExportSnapshots exports the consensus snapshots of the epoch blocks within the
+// given range into the specified file, appending to any data already present.
+func ExportSnapshots(blockchain *core.BlockChain, fn string, first uint64, last uint64) error {
+	engine, ok := blockchain.Engine().(snapshotEngine)
+	if !ok {
+		return errors.New("consensus engine has no snapshots")
+	}
+	log.Info("Exporting snapshots", "file", fn)
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	// Iterate over the epoch blocks and export their snapshots
+	exported := 0
+	for nr := first; nr <= last; nr++ {
+		header := blockchain.GetHeaderByNumber(nr)
+		if header == nil {
+			return fmt.Errorf("export failed on #%d: not found", nr)
+		}
+		headerExtra, err := equality.DecodeHeaderExtra(header)
+		if err != nil || headerExtra.EpochBlock != nr {
+			continue
+		}
+		data, err := engine.ExportSnapshot(header)
+		if err != nil {
+			log.Warn("Skipping unavailable snapshot", "number", nr, "hash", header.Hash(), "err", err)
+			continue
+		}
+		if err := rlp.Encode(writer, &snapshotRecord{Header: header, Data: data}); err != nil {
+			return err
+		}
+		exported++
+	}
+	log.Info("Exported snapshots", "file", fn, "count", exported)
+	return nil
+}
+
+// ImportSnapshots imports the consensus snapshots of the epoch blocks from the
+// specified file. The snapshots are verified against the roots committed in the
+// headers, the ones in between being rebuilt from them as the blocks get
+// imported, or by EnsureSnapshots afterwards.
+func ImportSnapshots(chain *core.BlockChain, fn string) error {
+	engine, ok := chain.Engine().(snapshotEngine)
+	if !ok {
+		return errors.New("consensus engine has no snapshots")
+	}
+	log.Info("Importing snapshots", "file", fn)
+
+	// Open the file handle and potentially unwrap the gzip stream
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	stream := rlp.NewStream(reader, 0)
+
+	imported := 0
+	for {
+		var record snapshotRecord
+		if err := stream.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("at snapshot %d: %v", imported, err)
+		}
+		if err := engine.ImportSnapshot(record.Header, record.Data); err != nil {
+			return fmt.Errorf("invalid snapshot of block %d: %v", record.Header.Number, err)
+		}
+		imported++
+	}
+	log.Info("Imported snapshots", "file", fn, "count", imported)
+	return nil
+}
+
+// EnsureSnapshots rebuilds the consensus snapshot of the chain head, replaying
+// the headers since the last snapshot available.
+func EnsureSnapshots(chain *core.BlockChain) error {
+	engine, ok := chain.Engine().(snapshotEngine)
+	if !ok {
+		return nil
+	}
+	return engine.EnsureSnapshot(chain, chain.CurrentHeader())
+}
+
 // ImportPreimages imports a batch of exported hash preimages into the database.
 func ImportPreimages(db ethdb.Database, fn string) error {
 	log.Info("Importing preimages", "file", fn)
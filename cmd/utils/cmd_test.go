@@ -0,0 +1,197 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/consensus/ethash"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/ethdb"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// newSnapshotChain creates an equality chain of the given length sealed by a
+// single validator, its blocks spaced by the period in the past.
+func newSnapshotChain(t *testing.T, db ethdb.Database, length int) (*core.Genesis, []*types.Block) {
+	key, _ := crypto.GenerateKey()
+	config := *params.TestChainConfig
+	config.Equality = &params.EqualityConfig{
+		Period:              1,
+		Epoch:               2,
+		MaxValidatorsCount:  21,
+		MinCandidateBalance: big.NewInt(1),
+		Validators:          []common.Address{crypto.PubkeyToAddress(key.PublicKey)},
+	}
+	genesis := &core.Genesis{Config: &config, Timestamp: uint64(time.Now().Unix()) - 1000, GasLimit: params.GenesisGasLimit}
+	genesis.MustCommit(db)
+
+	engine := equality.New(config.Equality, db)
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	blocks := make([]*types.Block, 0, length)
+	for i := 0; i < length; i++ {
+		parent := chain.CurrentBlock()
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), common.Big1),
+			GasLimit:   parent.GasLimit(),
+			Coinbase:   config.Equality.Validators[0],
+		}
+		if err := engine.Prepare(chain, header); err != nil {
+			t.Fatalf("failed to prepare block %d: %v", header.Number, err)
+		}
+		header.Time = parent.Time() + config.Equality.Period
+
+		statedb, err := state.New(parent.Root(), chain.StateCache(), nil)
+		if err != nil {
+			t.Fatalf("failed to open state: %v", err)
+		}
+		block, err := engine.FinalizeAndAssemble(chain, header, statedb, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to assemble block %d: %v", header.Number, err)
+		}
+		header = block.Header()
+		signature, err := crypto.Sign(equality.SealHash(header).Bytes(), key)
+		if err != nil {
+			t.Fatalf("failed to seal block %d: %v", header.Number, err)
+		}
+		copy(header.Extra[len(header.Extra)-len(signature):], signature)
+		block = block.WithSeal(header)
+
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", header.Number, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return genesis, blocks
+}
+
+func TestExportImportSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "snapshots.gz")
+
+	// Export the snapshots of the epoch blocks of a chain
+	db := rawdb.NewMemoryDatabase()
+	genesis, blocks := newSnapshotChain(t, db, 6)
+	exporter := equality.New(genesis.Config.Equality, db)
+	chain, err := core.NewBlockChain(db, nil, genesis.Config, exporter, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if err := ExportSnapshots(chain, fn, 1, uint64(len(blocks))); err != nil {
+		t.Fatalf("failed to export snapshots: %v", err)
+	}
+	chain.Stop()
+
+	var epochs []*types.Header
+	for _, block := range blocks {
+		if _, err := exporter.ExportSnapshot(block.Header()); err == nil {
+			epochs = append(epochs, block.Header())
+		}
+	}
+	if len(epochs) != 3 {
+		t.Fatalf("epoch snapshot count mismatch: have %d, want 3", len(epochs))
+	}
+
+	// Import them into a fresh database, the roots committed in the headers
+	// being checked along
+	fresh := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(fresh)
+	importer := equality.New(genesis.Config.Equality, fresh)
+	chain, err = core.NewBlockChain(fresh, nil, genesis.Config, importer, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	for _, header := range epochs {
+		if importer.SnapshotAvailable(header) {
+			t.Fatalf("snapshot of block %d available before the import", header.Number)
+		}
+	}
+	if err := ImportSnapshots(chain, fn); err != nil {
+		t.Fatalf("failed to import snapshots: %v", err)
+	}
+	for _, header := range epochs {
+		if !importer.SnapshotAvailable(header) {
+			t.Errorf("snapshot of block %d not imported", header.Number)
+		}
+		have, err := importer.ExportSnapshot(header)
+		if err != nil {
+			t.Errorf("failed to read the snapshot of block %d: %v", header.Number, err)
+			continue
+		}
+		want, _ := exporter.ExportSnapshot(header)
+		if !bytes.Equal(have, want) {
+			t.Errorf("snapshot of block %d mismatch", header.Number)
+		}
+	}
+	if head := blocks[len(blocks)-1].Header(); importer.SnapshotAvailable(head) {
+		t.Error("snapshot of a block past the last epoch block imported")
+	}
+
+	// The blocks are verified against the imported snapshots
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import blocks: %v", err)
+	}
+	if err := EnsureSnapshots(chain); err != nil {
+		t.Errorf("failed to ensure the head snapshot: %v", err)
+	}
+}
+
+func TestSnapshotsWithoutEngineSupport(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// Engines without snapshots have none to move around or rebuild
+	if err := ExportSnapshots(chain, filepath.Join(os.TempDir(), "snapshots"), 0, genesis.NumberU64()); err == nil {
+		t.Error("snapshots exported from an engine without any")
+	}
+	if err := ImportSnapshots(chain, filepath.Join(os.TempDir(), "snapshots")); err == nil {
+		t.Error("snapshots imported into an engine without any")
+	}
+	if err := EnsureSnapshots(chain); err != nil {
+		t.Errorf("failed to skip the head snapshot: %v", err)
+	}
+}
@@ -127,6 +127,22 @@ var (
 		Usage: "Path to the smartcard daemon (pcscd) socket file",
 		Value: pcsclite.PCSCDSockName,
 	}
+	PKCS11ModuleFlag = cli.StringFlag{
+		Name:  "pkcs11.module",
+		Usage: "Path of the PKCS#11 library of the HSM holding a key to sign with",
+	}
+	PKCS11SlotFlag = cli.UintFlag{
+		Name:  "pkcs11.slot",
+		Usage: "Slot of the HSM token holding the key",
+	}
+	PKCS11PINFileFlag = cli.StringFlag{
+		Name:  "pkcs11.pinfile",
+		Usage: "File containing the PIN logging into the HSM token",
+	}
+	PKCS11LabelFlag = cli.StringFlag{
+		Name:  "pkcs11.label",
+		Usage: "Label of the key pair in the HSM token",
+	}
 	NetworkIdFlag = cli.Uint64Flag{
 		Name:  "networkid",
 		Usage: "Explicitly set network id (integer)(For testnets: use --ropsten, --rinkeby, --goerli instead)",
@@ -136,6 +152,30 @@ var (
 		Name:  "localnet",
 		Usage: "Local network: pre-configured proof-of-equality test network",
 	}
+	ChainSpecFlag = cli.StringFlag{
+		Name:  "chainspec",
+		Usage: "Chain spec file (JSON or TOML) defining the genesis and bootnodes of a custom network",
+	}
+	OverrideEqualityBlockFlag = cli.Uint64Flag{
+		Name:  "override.equality.block",
+		Usage: "Manually specify the block recording the overridden equality parameters",
+	}
+	OverrideEqualityPeriodFlag = cli.Uint64Flag{
+		Name:  "override.equality.period",
+		Usage: "Manually specify the equality block period from the override block on",
+	}
+	OverrideEqualityEpochFlag = cli.Uint64Flag{
+		Name:  "override.equality.epoch",
+		Usage: "Manually specify the equality epoch length from the override block on",
+	}
+	OverrideEqualityPoolFlag = cli.StringFlag{
+		Name:  "override.equality.pool",
+		Usage: "Manually specify the equality pool address from the override block on",
+	}
+	OverrideEqualityResumeFlag = cli.BoolFlag{
+		Name:  "override.equality.resume",
+		Usage: "Manually resume the chain halted by the validators from the override block on",
+	}
 	DeveloperFlag = cli.BoolFlag{
 		Name:  "dev",
 		Usage: "Ephemeral proof-of-authority network with a pre-funded developer account, mining enabled",
@@ -144,6 +184,10 @@ var (
 		Name:  "dev.period",
 		Usage: "Block period to use in developer mode (0 = mine only if transaction pending)",
 	}
+	DeveloperEqualityFlag = cli.BoolFlag{
+		Name:  "dev.equality",
+		Usage: "Seal the developer chain with the equality engine, the developer account being its single validator",
+	}
 	IdentityFlag = cli.StringFlag{
 		Name:  "identity",
 		Usage: "Custom node name",
@@ -176,7 +220,7 @@ var (
 	defaultSyncMode = eth.DefaultConfig.SyncMode
 	SyncModeFlag    = TextMarshalerFlag{
 		Name:  "syncmode",
-		Usage: `Blockchain sync mode ("fast", "full", or "light")`,
+		Usage: `Blockchain sync mode ("fast", "full", "light" or "warp")`,
 		Value: &defaultSyncMode,
 	}
 	GCModeFlag = cli.StringFlag{
@@ -184,6 +228,19 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	EqualityHeaderOnlyFlag = cli.BoolFlag{
+		Name:  "equality.headeronly",
+		Usage: "Verify the equality seals against the epoch validators only, trusting the snapshot roots (non-minting nodes)",
+	}
+	EqualityGCModeFlag = cli.StringFlag{
+		Name:  "equality.gcmode",
+		Usage: `Equality snapshot garbage collection mode ("pruned" keeping the epoch blocks and recent ones, "archive")`,
+		Value: "pruned",
+	}
+	EqualitySnapshotsFlag = cli.StringFlag{
+		Name:  "equality.snapshots",
+		Usage: "File carrying the equality epoch snapshots along with the exported or imported chain",
+	}
 	SnapshotFlag = cli.BoolFlag{
 		Name:  "snapshot",
 		Usage: `Enables snapshot-database mode -- experimental work in progress feature`,
@@ -240,6 +297,19 @@ var (
 		Name:  "light.nopruning",
 		Usage: "Disable ancient light chain data pruning",
 	}
+	LightOracleFlag = cli.StringFlag{
+		Name:  "light.oracle",
+		Usage: "Address of the checkpoint oracle contract (overrides the network default)",
+	}
+	LightOracleSignersFlag = cli.StringFlag{
+		Name:  "light.oracle.signers",
+		Usage: "Comma separated list of the addresses trusted to sign oracle checkpoints",
+	}
+	LightOracleThresholdFlag = cli.Uint64Flag{
+		Name:  "light.oracle.threshold",
+		Usage: "Number of signatures required to accept an oracle checkpoint",
+		Value: 1,
+	}
 	// Ethash settings
 	EthashCacheDirFlag = DirectoryFlag{
 		Name:  "ethash.cachedir",
@@ -410,6 +480,73 @@ var (
 		Name:  "miner.extradata",
 		Usage: "Block extra data set by the miner (default = client version)",
 	}
+	MinerVanityFlag = cli.StringFlag{
+		Name:  "miner.vanity",
+		Usage: "Validator tag placed in the vanity of sealed blocks (equality only, up to 32 bytes of UTF-8)",
+	}
+	MinerSealKeyFlag = cli.StringFlag{
+		Name:  "miner.sealkey",
+		Usage: "Signing key bound to the etherbase sealing the blocks (equality only, default = etherbase)",
+	}
+	MinerSignerURLFlag = cli.StringFlag{
+		Name:  "miner.signer.url",
+		Usage: "URL of a web3signer compatible remote signer sealing the blocks (equality only)",
+	}
+	MinerSignerCertFlag = cli.StringFlag{
+		Name:  "miner.signer.cert",
+		Usage: "TLS client certificate authenticating to the remote signer",
+	}
+	MinerSignerKeyFlag = cli.StringFlag{
+		Name:  "miner.signer.key",
+		Usage: "TLS client key authenticating to the remote signer",
+	}
+	MinerSignerCAFlag = cli.StringFlag{
+		Name:  "miner.signer.ca",
+		Usage: "TLS certificate authority of the remote signer (default = system roots)",
+	}
+	MinerSignerWalletFlag = cli.StringFlag{
+		Name:  "miner.signer.wallet",
+		Usage: "URL of a hardware wallet sealing the blocks, e.g. ledger://0001:0004:00 (equality only)",
+	}
+	MinerSignerPathFlag = cli.StringFlag{
+		Name:  "miner.signer.path",
+		Usage: "Derivation path of the sealing key in the hardware wallet",
+		Value: accounts.DefaultBaseDerivationPath.String(),
+	}
+	MinerSignerUnlockFlag = cli.StringFlag{
+		Name:  "miner.signer.unlock",
+		Usage: "Password file unlocking the sealing key for each seal only, sealing being stopped on chain divergence or double-sign risk (equality only)",
+	}
+	MinerSignGuardFlag = cli.StringFlag{
+		Name:  "miner.signguard",
+		Usage: "File recording the last slot sealed, refusing double signs across restarts (equality only, relative to the datadir)",
+		Value: eth.DefaultConfig.Miner.SignGuard,
+	}
+	MinerCoSignersFlag = cli.StringFlag{
+		Name:  "miner.cosigners",
+		Usage: "Comma separated co-signers whose approval the seals wait for, as address@url of their remote signer; advisory, the local key still seals alone (equality only)",
+	}
+	MinerCoSignerThresholdFlag = cli.IntFlag{
+		Name:  "miner.cosigners.threshold",
+		Usage: "Number of co-signer approvals needed to seal a block (default = all co-signers)",
+	}
+	MinerPolicyHeightFlag = cli.BoolFlag{
+		Name:  "miner.policy.height",
+		Usage: "Refuse to seal blocks below the highest block sealed (equality only)",
+	}
+	MinerPolicyDriftFlag = cli.DurationFlag{
+		Name:  "miner.policy.drift",
+		Usage: "Refuse to seal blocks whose timestamp drifts from the local clock by more than this (equality only, 0 = off)",
+	}
+	MinerPolicyValidatorsFlag = cli.StringFlag{
+		Name:  "miner.policy.validators",
+		Usage: "Comma separated validators expected, refusing to seal in epochs with others (equality only)",
+	}
+	MinerConsensusTxQuotaFlag = cli.IntFlag{
+		Name:  "miner.consensusquota",
+		Usage: "Transactions registering or cancelling candidates included ahead of the others in each block (equality only, 0 = off)",
+		Value: eth.DefaultConfig.Miner.ConsensusTxQuota,
+	}
 	MinerRecommitIntervalFlag = cli.DurationFlag{
 		Name:  "miner.recommit",
 		Usage: "Time interval to recreate the block being mined",
@@ -562,6 +699,10 @@ var (
 		Usage: "Maximum number of pending connection attempts (defaults used if set to 0)",
 		Value: node.DefaultConfig.P2P.MaxPendingPeers,
 	}
+	MaxClockDriftFlag = cli.DurationFlag{
+		Name:  "maxclockdrift",
+		Usage: "Maximum clock difference tolerated from network peers (defaults used if set to 0)",
+	}
 	ListenPortFlag = cli.IntFlag{
 		Name:  "port",
 		Usage: "Network listening port",
@@ -779,6 +920,8 @@ func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
 		}
 	case ctx.GlobalBool(LegacyTestnetFlag.Name) || ctx.GlobalBool(LocalnetFlag.Name):
 		urls = params.TestnetBootnodes
+	case ctx.GlobalIsSet(ChainSpecFlag.Name):
+		urls = MakeChainSpec(ctx).Bootnodes
 	case cfg.BootstrapNodes != nil:
 		return // already set, don't apply defaults.
 	}
@@ -809,6 +952,8 @@ func setBootstrapNodesV5(ctx *cli.Context, cfg *p2p.Config) {
 		}
 	case ctx.GlobalBool(LocalnetFlag.Name):
 		urls = params.TestnetBootnodes
+	case ctx.GlobalIsSet(ChainSpecFlag.Name):
+		urls = MakeChainSpec(ctx).Bootnodes
 	case cfg.BootstrapNodesV5 != nil:
 		return // already set, don't apply defaults.
 	}
@@ -1007,6 +1152,67 @@ func setLes(ctx *cli.Context, cfg *eth.Config) {
 	if ctx.GlobalIsSet(LightNoPruneFlag.Name) {
 		cfg.LightNoPrune = ctx.GlobalBool(LightNoPruneFlag.Name)
 	}
+	if ctx.GlobalIsSet(LightOracleFlag.Name) {
+		cfg.CheckpointOracle = makeCheckpointOracle(ctx)
+	}
+}
+
+// makeEqualityOverride creates the equality parameter override from the command
+// line flags.
+func makeEqualityOverride(ctx *cli.Context) *params.EqualityOverride {
+	override := &params.EqualityOverride{Block: ctx.GlobalUint64(OverrideEqualityBlockFlag.Name)}
+	if override.Block == 0 {
+		Fatalf("Equality parameters cannot be overridden at the genesis block")
+	}
+	if ctx.GlobalIsSet(OverrideEqualityPeriodFlag.Name) {
+		period := ctx.GlobalUint64(OverrideEqualityPeriodFlag.Name)
+		if period == 0 {
+			Fatalf("Overridden equality period must be positive")
+		}
+		override.Period = &period
+	}
+	if ctx.GlobalIsSet(OverrideEqualityEpochFlag.Name) {
+		epoch := ctx.GlobalUint64(OverrideEqualityEpochFlag.Name)
+		if epoch == 0 {
+			Fatalf("Overridden equality epoch must be positive")
+		}
+		override.Epoch = &epoch
+	}
+	if ctx.GlobalIsSet(OverrideEqualityPoolFlag.Name) {
+		address := ctx.GlobalString(OverrideEqualityPoolFlag.Name)
+		if !common.IsHexAddress(address) || common.HexToAddress(address) == (common.Address{}) {
+			Fatalf("Invalid overridden equality pool address %q", address)
+		}
+		pool := common.HexToAddress(address)
+		override.Pool = &pool
+	}
+	override.Resume = ctx.GlobalBool(OverrideEqualityResumeFlag.Name)
+	if override.Period == nil && override.Epoch == nil && override.Pool == nil && !override.Resume {
+		Fatalf("No equality parameter to override at block %d", override.Block)
+	}
+	return override
+}
+
+// makeCheckpointOracle creates the checkpoint oracle config from the command line flags.
+func makeCheckpointOracle(ctx *cli.Context) *params.CheckpointOracleConfig {
+	address := ctx.GlobalString(LightOracleFlag.Name)
+	if !common.IsHexAddress(address) {
+		Fatalf("Invalid checkpoint oracle address %q", address)
+	}
+	config := &params.CheckpointOracleConfig{
+		Address:   common.HexToAddress(address),
+		Threshold: ctx.GlobalUint64(LightOracleThresholdFlag.Name),
+	}
+	for _, signer := range SplitAndTrim(ctx.GlobalString(LightOracleSignersFlag.Name)) {
+		if !common.IsHexAddress(signer) {
+			Fatalf("Invalid checkpoint oracle signer %q", signer)
+		}
+		config.Signers = append(config.Signers, common.HexToAddress(signer))
+	}
+	if config.Threshold == 0 || uint64(len(config.Signers)) < config.Threshold {
+		Fatalf("Checkpoint oracle needs at least %d signers, %d given", config.Threshold, len(config.Signers))
+	}
+	return config
 }
 
 // makeDatabaseHandles raises out the number of allowed file handles per process
@@ -1100,7 +1306,7 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	setBootstrapNodes(ctx, cfg)
 	setBootstrapNodesV5(ctx, cfg)
 
-	lightClient := ctx.GlobalString(SyncModeFlag.Name) == "light"
+	lightClient := IsLightClient(ctx)
 	lightServer := (ctx.GlobalInt(LegacyLightServFlag.Name) != 0 || ctx.GlobalInt(LightServeFlag.Name) != 0)
 
 	lightPeers := ctx.GlobalInt(LegacyLightPeersFlag.Name)
@@ -1137,6 +1343,9 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.GlobalIsSet(MaxPendingPeersFlag.Name) {
 		cfg.MaxPendingPeers = ctx.GlobalInt(MaxPendingPeersFlag.Name)
 	}
+	if ctx.GlobalIsSet(MaxClockDriftFlag.Name) {
+		cfg.MaxClockDrift = ctx.GlobalDuration(MaxClockDriftFlag.Name)
+	}
 	if ctx.GlobalIsSet(NoDiscoverFlag.Name) || lightClient {
 		cfg.NoDiscovery = true
 	}
@@ -1178,6 +1387,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setNodeUserIdent(ctx, cfg)
 	setDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
+	setPKCS11(ctx, cfg)
 
 	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
@@ -1217,6 +1427,27 @@ func setSmartCard(ctx *cli.Context, cfg *node.Config) {
 	cfg.SmartCardDaemonPath = path
 }
 
+// setPKCS11 configures the HSM holding a key to sign with, the PIN being read
+// from the first line of its file.
+func setPKCS11(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(PKCS11ModuleFlag.Name) {
+		cfg.PKCS11Module = ctx.GlobalString(PKCS11ModuleFlag.Name)
+	}
+	if ctx.GlobalIsSet(PKCS11SlotFlag.Name) {
+		cfg.PKCS11Slot = ctx.GlobalUint(PKCS11SlotFlag.Name)
+	}
+	if ctx.GlobalIsSet(PKCS11LabelFlag.Name) {
+		cfg.PKCS11Label = ctx.GlobalString(PKCS11LabelFlag.Name)
+	}
+	if path := ctx.GlobalString(PKCS11PINFileFlag.Name); path != "" {
+		text, err := ioutil.ReadFile(path)
+		if err != nil {
+			Fatalf("Failed to read HSM PIN file: %v", err)
+		}
+		cfg.PKCS11PIN = strings.TrimRight(strings.SplitN(string(text), "\n", 2)[0], "\r")
+	}
+}
+
 func setDataDir(ctx *cli.Context, cfg *node.Config) {
 	switch {
 	case ctx.GlobalIsSet(DataDirFlag.Name):
@@ -1343,6 +1574,16 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerExtraDataFlag.Name) {
 		cfg.ExtraData = []byte(ctx.GlobalString(MinerExtraDataFlag.Name))
 	}
+	if ctx.GlobalIsSet(MinerVanityFlag.Name) {
+		vanity := ctx.GlobalString(MinerVanityFlag.Name)
+		if err := equality.ValidateVanity(vanity); err != nil {
+			Fatalf("Invalid --%s: %v", MinerVanityFlag.Name, err)
+		}
+		if ctx.GlobalIsSet(MinerExtraDataFlag.Name) {
+			log.Warn("Both --miner.vanity and --miner.extradata set, using --miner.vanity")
+		}
+		cfg.ExtraData = []byte(vanity)
+	}
 	if ctx.GlobalIsSet(LegacyMinerGasTargetFlag.Name) {
 		cfg.GasFloor = ctx.GlobalUint64(LegacyMinerGasTargetFlag.Name)
 		log.Warn("The flag --targetgaslimit is deprecated and will be removed in the future, please use --miner.gastarget")
@@ -1363,6 +1604,54 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerRecommitIntervalFlag.Name) {
 		cfg.Recommit = ctx.GlobalDuration(MinerRecommitIntervalFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerSealKeyFlag.Name) {
+		key := ctx.GlobalString(MinerSealKeyFlag.Name)
+		if !common.IsHexAddress(key) {
+			Fatalf("Invalid miner seal key: %s", key)
+		}
+		cfg.SealKey = common.HexToAddress(key)
+	}
+	if ctx.GlobalIsSet(MinerSignerURLFlag.Name) {
+		cfg.SignerURL = ctx.GlobalString(MinerSignerURLFlag.Name)
+		cfg.SignerCert = ctx.GlobalString(MinerSignerCertFlag.Name)
+		cfg.SignerKey = ctx.GlobalString(MinerSignerKeyFlag.Name)
+		cfg.SignerCA = ctx.GlobalString(MinerSignerCAFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerSignerWalletFlag.Name) {
+		cfg.SignerWallet = ctx.GlobalString(MinerSignerWalletFlag.Name)
+		cfg.SignerPath = ctx.GlobalString(MinerSignerPathFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerSignerUnlockFlag.Name) {
+		cfg.SignerUnlock = ctx.GlobalString(MinerSignerUnlockFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerSignGuardFlag.Name) {
+		cfg.SignGuard = ctx.GlobalString(MinerSignGuardFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerCoSignersFlag.Name) {
+		cfg.CoSigners = SplitAndTrim(ctx.GlobalString(MinerCoSignersFlag.Name))
+		cfg.CoSignerThreshold = len(cfg.CoSigners)
+	}
+	if ctx.GlobalIsSet(MinerCoSignerThresholdFlag.Name) {
+		cfg.CoSignerThreshold = ctx.GlobalInt(MinerCoSignerThresholdFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerPolicyHeightFlag.Name) {
+		cfg.PolicyHeight = ctx.GlobalBool(MinerPolicyHeightFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerPolicyDriftFlag.Name) {
+		cfg.PolicyDrift = ctx.GlobalDuration(MinerPolicyDriftFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerPolicyValidatorsFlag.Name) {
+		cfg.PolicyValidators = nil
+		for _, validator := range SplitAndTrim(ctx.GlobalString(MinerPolicyValidatorsFlag.Name)) {
+			if !common.IsHexAddress(validator) {
+				Fatalf("Invalid validator in the seal policy: %s", validator)
+			}
+			cfg.PolicyValidators = append(cfg.PolicyValidators, common.HexToAddress(validator))
+		}
+	}
+	if ctx.GlobalIsSet(MinerConsensusTxQuotaFlag.Name) {
+		cfg.ConsensusTxQuota = ctx.GlobalInt(MinerConsensusTxQuotaFlag.Name)
+	}
 	if ctx.GlobalIsSet(MinerNoVerfiyFlag.Name) {
 		cfg.Noverify = ctx.GlobalBool(MinerNoVerfiyFlag.Name)
 	}
@@ -1432,6 +1721,13 @@ func CheckExclusive(ctx *cli.Context, args ...interface{}) {
 	}
 }
 
+// IsLightClient returns whether the sync mode requested on the command line is
+// served by a light client.
+func IsLightClient(ctx *cli.Context) bool {
+	mode := ctx.GlobalString(SyncModeFlag.Name)
+	return mode == "light" || mode == "warp"
+}
+
 // SetShhConfig applies shh-related command line flags to the config.
 func SetShhConfig(ctx *cli.Context, stack *node.Node) {
 	if ctx.GlobalIsSet(WhisperEnabledFlag.Name) ||
@@ -1445,10 +1741,15 @@ func SetShhConfig(ctx *cli.Context, stack *node.Node) {
 // SetEthConfig applies eth-related command line flags to the config.
 func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	// Avoid conflicting network flags
-	CheckExclusive(ctx, DeveloperFlag, LegacyTestnetFlag, LocalnetFlag)
+	CheckExclusive(ctx, DeveloperFlag, LegacyTestnetFlag, LocalnetFlag, ChainSpecFlag)
 	CheckExclusive(ctx, LegacyLightServFlag, LightServeFlag, SyncModeFlag, "light")
+	CheckExclusive(ctx, LegacyLightServFlag, LightServeFlag, SyncModeFlag, "warp")
 	CheckExclusive(ctx, DeveloperFlag, ExternalSignerFlag) // Can't use both ephemeral unlocked and external signer
+	if ctx.GlobalBool(DeveloperEqualityFlag.Name) && !ctx.GlobalBool(DeveloperFlag.Name) {
+		Fatalf("Flag --%s is only available in developer mode (--%s)", DeveloperEqualityFlag.Name, DeveloperFlag.Name)
+	}
 	CheckExclusive(ctx, GCModeFlag, "archive", TxLookupLimitFlag)
+	CheckExclusive(ctx, MiningEnabledFlag, EqualityHeaderOnlyFlag) // Header-only nodes lack the snapshots to mint blocks
 	// todo(rjl493456442) make it available for les server
 	// Ancient tx indices pruning is not available for les server now
 	// since light client relies on the server for transaction status query.
@@ -1458,7 +1759,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		ks = keystores[0].(*keystore.KeyStore)
 	}
 	setEtherbase(ctx, ks, cfg)
-	setGPO(ctx, &cfg.GPO, ctx.GlobalString(SyncModeFlag.Name) == "light")
+	setGPO(ctx, &cfg.GPO, IsLightClient(ctx))
 	setTxPool(ctx, &cfg.TxPool)
 	setEthash(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
@@ -1485,6 +1786,15 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(GCModeFlag.Name) {
 		cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
 	}
+	if gcmode := ctx.GlobalString(EqualityGCModeFlag.Name); gcmode != "pruned" && gcmode != "archive" {
+		Fatalf("--%s must be either 'pruned' or 'archive'", EqualityGCModeFlag.Name)
+	}
+	if ctx.GlobalIsSet(EqualityGCModeFlag.Name) {
+		cfg.EqualityNoPruning = ctx.GlobalString(EqualityGCModeFlag.Name) == "archive"
+	}
+	if ctx.GlobalIsSet(EqualityHeaderOnlyFlag.Name) {
+		cfg.EqualityHeaderOnly = ctx.GlobalBool(EqualityHeaderOnlyFlag.Name)
+	}
 	if ctx.GlobalIsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = ctx.GlobalBool(CacheNoPrefetchFlag.Name)
 	}
@@ -1545,6 +1855,13 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		}
 	}
 
+	if ctx.GlobalIsSet(OverrideEqualityBlockFlag.Name) {
+		cfg.OverrideEquality = makeEqualityOverride(ctx)
+	} else if ctx.GlobalIsSet(OverrideEqualityPeriodFlag.Name) || ctx.GlobalIsSet(OverrideEqualityEpochFlag.Name) ||
+		ctx.GlobalIsSet(OverrideEqualityPoolFlag.Name) {
+		Fatalf("Flag --%s is required to override equality parameters", OverrideEqualityBlockFlag.Name)
+	}
+
 	// Override any default configs for hard coded networks.
 	switch {
 	case ctx.GlobalBool(LegacyTestnetFlag.Name) || ctx.GlobalBool(LocalnetFlag.Name):
@@ -1553,6 +1870,18 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		}
 		cfg.Genesis = core.DefaultTestnetGenesisBlock()
 		SetDNSDiscoveryDefaults(cfg, params.TestnetGenesisHash)
+	case ctx.GlobalIsSet(ChainSpecFlag.Name):
+		spec := MakeChainSpec(ctx)
+		if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
+			cfg.NetworkId = spec.Network()
+		}
+		cfg.Genesis = spec.Genesis
+		if cfg.Checkpoint == nil {
+			cfg.Checkpoint = spec.Checkpoint
+		}
+		if cfg.CheckpointOracle == nil {
+			cfg.CheckpointOracle = spec.CheckpointOracle
+		}
 	case ctx.GlobalBool(DeveloperFlag.Name):
 		if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
 			cfg.NetworkId = 1337
@@ -1586,7 +1915,11 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		log.Info("Using developer account", "address", developer.Address)
 
 		// Create a new developer genesis block or reuse existing one
-		cfg.Genesis = core.DeveloperGenesisBlock(uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name)), developer.Address)
+		if ctx.GlobalBool(DeveloperEqualityFlag.Name) {
+			cfg.Genesis = core.DeveloperEqualityGenesisBlock(uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name)), developer.Address)
+		} else {
+			cfg.Genesis = core.DeveloperGenesisBlock(uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name)), developer.Address)
+		}
 		if ctx.GlobalIsSet(DataDirFlag.Name) {
 			// Check if we have an already initialized chain and fall back to
 			// that if so. Otherwise we need to generate a new genesis spec.
@@ -1614,7 +1947,7 @@ func SetDNSDiscoveryDefaults(cfg *eth.Config, genesis common.Hash) {
 	}
 
 	protocol := "all"
-	if cfg.SyncMode == downloader.LightSync {
+	if cfg.SyncMode.IsLight() {
 		protocol = "les"
 	}
 	if url := params.KnownDNSNetwork(genesis, protocol); url != "" {
@@ -1624,7 +1957,7 @@ func SetDNSDiscoveryDefaults(cfg *eth.Config, genesis common.Hash) {
 
 // RegisterEthService adds an Ethereum client to the stack.
 func RegisterEthService(stack *node.Node, cfg *eth.Config) ethapi.Backend {
-	if cfg.SyncMode == downloader.LightSync {
+	if cfg.SyncMode.IsLight() {
 		backend, err := les.New(stack, cfg)
 		if err != nil {
 			Fatalf("Failed to register the Ethereum service: %v", err)
@@ -1714,7 +2047,7 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
 		err     error
 		chainDb ethdb.Database
 	)
-	if ctx.GlobalString(SyncModeFlag.Name) == "light" {
+	if IsLightClient(ctx) {
 		name := "lightchaindata"
 		chainDb, err = stack.OpenDatabase(name, cache, handles, "")
 	} else {
@@ -1732,12 +2065,23 @@ func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	switch {
 	case ctx.GlobalBool(LegacyTestnetFlag.Name) || ctx.GlobalBool(LocalnetFlag.Name):
 		genesis = core.DefaultTestnetGenesisBlock()
+	case ctx.GlobalIsSet(ChainSpecFlag.Name):
+		genesis = MakeChainSpec(ctx).Genesis
 	case ctx.GlobalBool(DeveloperFlag.Name):
 		Fatalf("Developer chains are ephemeral")
 	}
 	return genesis
 }
 
+// MakeChainSpec loads the custom network preset set on the command line.
+func MakeChainSpec(ctx *cli.Context) *core.ChainSpec {
+	spec, err := core.LoadChainSpec(ctx.GlobalString(ChainSpecFlag.Name))
+	if err != nil {
+		Fatalf("Failed to load chain spec: %v", err)
+	}
+	return spec
+}
+
 // MakeChain creates a chain manager from set command line flags.
 func MakeChain(ctx *cli.Context, stack *node.Node, readOnly bool) (chain *core.BlockChain, chainDb ethdb.Database) {
 	var err error
@@ -1749,7 +2093,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readOnly bool) (chain *core.B
 	var engine consensus.Engine
 	if config.Clique != nil {
 		engine = clique.New(config.Clique, chainDb)
-	} else if config.Equality != nil {
+	} else if config.IsEquality(common.Big0) {
 		engine = equality.New(config.Equality, chainDb)
 	} else {
 		engine = ethash.NewFaker()
@@ -1766,9 +2110,27 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readOnly bool) (chain *core.B
 			}, nil, false)
 		}
 	}
+	if config.Equality != nil && !config.IsEquality(common.Big0) {
+		engine = equality.NewTransition(engine, equality.NewFromBlock(config.Equality, chainDb, config.EqualityBlock.Uint64()))
+	}
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
+	if gcmode := ctx.GlobalString(EqualityGCModeFlag.Name); gcmode != "pruned" && gcmode != "archive" {
+		Fatalf("--%s must be either 'pruned' or 'archive'", EqualityGCModeFlag.Name)
+	}
+	type pruned interface {
+		SetSnapshotPruning(enabled bool)
+	}
+	if engine, ok := engine.(pruned); ok {
+		engine.SetSnapshotPruning(ctx.GlobalString(EqualityGCModeFlag.Name) == "pruned")
+	}
+	type headerOnly interface {
+		SetHeaderOnly()
+	}
+	if engine, ok := engine.(headerOnly); ok && ctx.GlobalBool(EqualityHeaderOnlyFlag.Name) {
+		engine.SetHeaderOnly()
+	}
 	cache := &core.CacheConfig{
 		TrieCleanLimit:      eth.DefaultConfig.TrieCleanCache,
 		TrieCleanNoPrefetch: ctx.GlobalBool(CacheNoPrefetchFlag.Name),
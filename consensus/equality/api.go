@@ -1,12 +1,18 @@
 package equality
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/math"
 	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/SecretBlockChain/go-secret/rpc"
 )
 
@@ -29,10 +35,115 @@ type rpcCandidateInfo struct {
 	BlockNumber *math.HexOrDecimal256 `json:"blockNumber"`
 }
 
+type rpcCandidateEvent struct {
+	Event       string                `json:"event"`
+	BlockNumber *math.HexOrDecimal256 `json:"blockNumber"`
+	Epoch       uint64                `json:"epoch"`
+	Staked      *math.HexOrDecimal256 `json:"staked"`
+}
+
+type rpcCandidateHistory struct {
+	Address      common.Address      `json:"address"`
+	Events       []rpcCandidateEvent `json:"events"`
+	EpochsServed int                 `json:"epochsServed"`
+}
+
+type rpcKickedCandidate struct {
+	Address     common.Address        `json:"address"`
+	BlockNumber *math.HexOrDecimal256 `json:"blockNumber"`
+	MintCount   uint64                `json:"mintCount"`
+	Threshold   uint64                `json:"threshold"`
+}
+
+type rpcBlockRewards struct {
+	BlockNumber    *math.HexOrDecimal256                    `json:"blockNumber"`
+	BlockHash      common.Hash                              `json:"blockHash"`
+	Coinbase       common.Address                           `json:"coinbase"`
+	Recipient      common.Address                           `json:"recipient"`
+	CoinbaseReward *math.HexOrDecimal256                    `json:"coinbaseReward"`
+	Pool           common.Address                           `json:"pool"`
+	PoolReward     *math.HexOrDecimal256                    `json:"poolReward"`
+	Delegators     map[common.Address]*math.HexOrDecimal256 `json:"delegators"`
+	Total          *math.HexOrDecimal256                    `json:"total"`
+	Transfers      []RewardTransfer                         `json:"transfers"` // Credits made, base fee included
+}
+
+type rpcSnapshotProof struct {
+	Address        common.Address        `json:"address"`
+	BlockNumber    *math.HexOrDecimal256 `json:"blockNumber"`
+	Epoch          uint64                `json:"epoch"`
+	EpochHash      common.Hash           `json:"epochHash"`
+	CandidateHash  common.Hash           `json:"candidateHash"`
+	IsValidator    bool                  `json:"isValidator"`
+	ValidatorProof []string              `json:"validatorProof"`
+	IsCandidate    bool                  `json:"isCandidate"`
+	CandidateProof []string              `json:"candidateProof"`
+}
+
+type rpcVanityTag struct {
+	Tag        string                `json:"tag"`
+	Count      int                   `json:"count"`
+	FirstBlock *math.HexOrDecimal256 `json:"firstBlock"`
+	LastBlock  *math.HexOrDecimal256 `json:"lastBlock"`
+}
+
+type rpcSnapshotDump struct {
+	BlockNumber   *math.HexOrDecimal256 `json:"blockNumber"`
+	BlockHash     common.Hash           `json:"blockHash"`
+	Epoch         uint64                `json:"epoch"`
+	EpochBlock    uint64                `json:"epochBlock"`
+	EpochHash     common.Hash           `json:"epochHash"`
+	CandidateHash common.Hash           `json:"candidateHash"`
+	MintCntHash   common.Hash           `json:"mintCntHash"`
+	ConfigHash    common.Hash           `json:"configHash"`
+	Validators    []rpcValidator        `json:"validators"`
+	Candidates    []rpcCandidate        `json:"candidates"`
+	Config        params.EqualityConfig `json:"config"`
+}
+
 type rpcCandidatesCount struct {
 	CandidatesCount int `json:"candidatesCount"`
 }
 
+type rpcEpochPerformance struct {
+	Epoch    uint64 `json:"epoch"`
+	Produced uint64 `json:"produced"`
+	Expected uint64 `json:"expected"`
+}
+
+type rpcValidatorPerformance struct {
+	Address      common.Address        `json:"address"`
+	FromEpoch    uint64                `json:"fromEpoch"`
+	ToEpoch      uint64                `json:"toEpoch"`
+	Produced     uint64                `json:"produced"`
+	Expected     uint64                `json:"expected"`
+	AverageDelay *uint64               `json:"averageDelay"` // Milliseconds, nil if no block was propagated
+	Epochs       []rpcEpochPerformance `json:"epochs"`
+	KickOuts     []rpcKickedCandidate  `json:"kickOuts"`
+}
+
+type rpcEpochStats struct {
+	Epoch      uint64                `json:"epoch"`
+	FirstBlock uint64                `json:"firstBlock"`
+	LastBlock  uint64                `json:"lastBlock"`
+	Blocks     uint64                `json:"blocks"`
+	Proposers  uint64                `json:"proposers"`
+	Interval   uint64                `json:"interval"` // Average milliseconds between blocks
+	Rewards    *math.HexOrDecimal256 `json:"rewards"`
+	Registered uint64                `json:"registered"`
+	Canceled   uint64                `json:"canceled"`
+	KickedOut  uint64                `json:"kickedOut"`
+	Expired    uint64                `json:"expired"`
+}
+
+type rpcValidatorStats struct {
+	Address     common.Address `json:"address"`
+	IsValidator bool           `json:"isValidator"`
+	Minted      uint64         `json:"minted"`
+	Missed      uint64         `json:"missed"`
+	LastMissed  uint64         `json:"lastMissed,omitempty"`
+}
+
 // API is a user facing RPC API to allow controlling the signer and voting
 // mechanisms of the proof-of-equality scheme.
 type API struct {
@@ -40,14 +151,17 @@ type API struct {
 	equality *Equality
 }
 
-// load a snapshot at specified block
-func (api *API) loadSnapshot(number *rpc.BlockNumber) (*Snapshot, HeaderExtra, error) {
-	var header *types.Header
+// header retrieves the header at specified block, the latest one if not specified.
+func (api *API) header(number *rpc.BlockNumber) *types.Header {
 	if number == nil || *number == rpc.LatestBlockNumber {
-		header = api.chain.CurrentHeader()
-	} else {
-		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+		return api.chain.CurrentHeader()
 	}
+	return api.chain.GetHeaderByNumber(uint64(number.Int64()))
+}
+
+// load a snapshot at specified block
+func (api *API) loadSnapshot(number *rpc.BlockNumber) (*Snapshot, HeaderExtra, error) {
+	header := api.header(number)
 	if header == nil {
 		return nil, HeaderExtra{}, errUnknownBlock
 	}
@@ -57,7 +171,7 @@ func (api *API) loadSnapshot(number *rpc.BlockNumber) (*Snapshot, HeaderExtra, e
 		return nil, HeaderExtra{}, err
 	}
 
-	snap, err := loadSnapshot(api.equality.db, headerExtra.Root)
+	snap, err := api.equality.openSnapshot(headerExtra.Root)
 	return snap, headerExtra, err
 }
 
@@ -101,6 +215,11 @@ func (api *API) GetCandidates(number *rpc.BlockNumber) ([]rpcCandidate, error) {
 		return nil, err
 	}
 
+	return candidatesOf(snap)
+}
+
+// candidatesOf converts the candidates of a snapshot to their RPC representation.
+func candidatesOf(snap *Snapshot) ([]rpcCandidate, error) {
 	candidates, err := snap.GetCandidates()
 	if err != nil {
 		return nil, err
@@ -118,6 +237,235 @@ func (api *API) GetCandidates(number *rpc.BlockNumber) ([]rpcCandidate, error) {
 	return result, nil
 }
 
+// GetSnapshotProof retrieves the merkle proofs of the validator set and of the
+// candidate information of the address at specified block. Both proofs can be
+// verified against the snapshot roots carried in the header of the block.
+func (api *API) GetSnapshotProof(address common.Address, number *rpc.BlockNumber) (rpcSnapshotProof, error) {
+	header := api.header(number)
+	if header == nil {
+		return rpcSnapshotProof{}, errUnknownBlock
+	}
+	blockNumber := rpc.BlockNumber(header.Number.Int64())
+	snap, headerExtra, err := api.loadSnapshot(&blockNumber)
+	if err != nil {
+		return rpcSnapshotProof{}, err
+	}
+
+	validatorProof, err := snap.ProveValidators()
+	if err != nil {
+		return rpcSnapshotProof{}, err
+	}
+	candidateProof, err := snap.ProveCandidate(address)
+	if err != nil {
+		return rpcSnapshotProof{}, err
+	}
+
+	validators, err := snap.GetValidators()
+	if err != nil {
+		return rpcSnapshotProof{}, err
+	}
+	candidate, err := snap.GetCandidate(address)
+	if err != nil {
+		return rpcSnapshotProof{}, err
+	}
+	return rpcSnapshotProof{
+		Address:        address,
+		BlockNumber:    (*math.HexOrDecimal256)(header.Number),
+		Epoch:          headerExtra.Epoch,
+		EpochHash:      headerExtra.Root.EpochHash,
+		CandidateHash:  headerExtra.Root.CandidateHash,
+		IsValidator:    addressesExist(validators, address),
+		ValidatorProof: common.ToHexArray(validatorProof),
+		IsCandidate:    candidate != nil,
+		CandidateProof: common.ToHexArray(candidateProof),
+	}, nil
+}
+
+// DumpSnapshot retrieves the whole consensus snapshot at specified block: the
+// validators with their mint counts for the epoch, all the candidates with their
+// deposits and registration heights, and the active chain config.
+func (api *API) DumpSnapshot(number *rpc.BlockNumber) (rpcSnapshotDump, error) {
+	header := api.header(number)
+	if header == nil {
+		return rpcSnapshotDump{}, errUnknownBlock
+	}
+	blockNumber := rpc.BlockNumber(header.Number.Int64())
+	snap, headerExtra, err := api.loadSnapshot(&blockNumber)
+	if err != nil {
+		return rpcSnapshotDump{}, err
+	}
+
+	validators, err := validatorsOf(snap, headerExtra.Epoch)
+	if err != nil {
+		return rpcSnapshotDump{}, err
+	}
+	candidates, err := candidatesOf(snap)
+	if err != nil {
+		return rpcSnapshotDump{}, err
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return bytes.Compare(candidates[i].Address[:], candidates[j].Address[:]) < 0
+	})
+	config, err := api.equality.chainConfigByHash(headerExtra.Root.ConfigHash)
+	if err != nil {
+		return rpcSnapshotDump{}, err
+	}
+
+	return rpcSnapshotDump{
+		BlockNumber:   (*math.HexOrDecimal256)(header.Number),
+		BlockHash:     header.Hash(),
+		Epoch:         headerExtra.Epoch,
+		EpochBlock:    headerExtra.EpochBlock,
+		EpochHash:     headerExtra.Root.EpochHash,
+		CandidateHash: headerExtra.Root.CandidateHash,
+		MintCntHash:   headerExtra.Root.MintCntHash,
+		ConfigHash:    headerExtra.Root.ConfigHash,
+		Validators:    validators,
+		Candidates:    candidates,
+		Config:        config,
+	}, nil
+}
+
+// GetVanityTags summarizes the vanity tags of the blocks sealed by each validator
+// in the specified range of blocks.
+func (api *API) GetVanityTags(from, to rpc.BlockNumber) (map[common.Address][]rpcVanityTag, error) {
+	last := api.header(&to)
+	if last == nil {
+		return nil, errUnknownBlock
+	}
+	if from < 0 || uint64(from) > last.Number.Uint64() {
+		return nil, errUnknownBlock
+	}
+	if last.Number.Uint64()-uint64(from) >= maxVanityRange {
+		return nil, fmt.Errorf("block range exceeds %d blocks", maxVanityRange)
+	}
+
+	result := make(map[common.Address][]rpcVanityTag)
+	for number := uint64(from); number <= last.Number.Uint64(); number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil || number == 0 {
+			continue
+		}
+		tag, ok := Vanity(header)
+		if !ok {
+			continue
+		}
+		validator, err := api.equality.Author(header)
+		if err != nil {
+			continue
+		}
+
+		tags := result[validator]
+		if n := len(tags); n > 0 && tags[n-1].Tag == tag {
+			tags[n-1].Count++
+			tags[n-1].LastBlock = math.NewHexOrDecimal256(int64(number))
+			continue
+		}
+		result[validator] = append(tags, rpcVanityTag{
+			Tag:        tag,
+			Count:      1,
+			FirstBlock: math.NewHexOrDecimal256(int64(number)),
+			LastBlock:  math.NewHexOrDecimal256(int64(number)),
+		})
+	}
+	return result, nil
+}
+
+// GetCandidateHistory retrieves the lifecycle of a candidate on the canonical chain:
+// registrations, cancellations, kick-outs and the epochs it served as validator.
+func (api *API) GetCandidateHistory(address common.Address) (rpcCandidateHistory, error) {
+	result := rpcCandidateHistory{Address: address, Events: make([]rpcCandidateEvent, 0)}
+	for _, event := range rawdb.ReadCandidateEvents(api.equality.db, address) {
+		if !canonicalCandidateEvent(api.chain, address, event) {
+			continue
+		}
+		if event.Kind == candidateEventElected {
+			result.EpochsServed++
+		}
+		staked := math.HexOrDecimal256(*event.Staked)
+		result.Events = append(result.Events, rpcCandidateEvent{
+			Event:       candidateEventNames[event.Kind],
+			BlockNumber: math.NewHexOrDecimal256(int64(event.Number)),
+			Epoch:       event.Epoch,
+			Staked:      &staked,
+		})
+	}
+	return result, nil
+}
+
+// GetBlockRewards retrieves the amounts credited by the consensus engine for
+// minting the specified block, recomputed from the chain config in effect, and
+// the credits made as internal transactions.
+// Delegators are not rewarded yet, the field is reserved for them.
+// Unlike the RewardPaid logs, which are missing from the blocks without any
+// transaction, it covers every block minted by the engine.
+func (api *API) GetBlockRewards(number *rpc.BlockNumber) (rpcBlockRewards, error) {
+	header := api.header(number)
+	if header == nil {
+		return rpcBlockRewards{}, errUnknownBlock
+	}
+	if header.Number.Uint64() < api.equality.start {
+		return rpcBlockRewards{}, errors.New("block is not minted by the equality engine")
+	}
+	parent := api.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return rpcBlockRewards{}, errUnknownBlock
+	}
+	config, err := api.equality.chainConfig(parent)
+	if err != nil {
+		return rpcBlockRewards{}, err
+	}
+
+	recipient := header.Coinbase
+	if headerExtra, err := DecodeHeaderExtra(header); err == nil {
+		recipient = headerExtra.rewardRecipient(header)
+	}
+	coinbase, pool := blockRewards(config, header.Number.Uint64())
+	if coinbase == nil {
+		coinbase, pool = big.NewInt(0), big.NewInt(0)
+	}
+	return rpcBlockRewards{
+		BlockNumber:    (*math.HexOrDecimal256)(header.Number),
+		BlockHash:      header.Hash(),
+		Coinbase:       header.Coinbase,
+		Recipient:      recipient,
+		CoinbaseReward: (*math.HexOrDecimal256)(coinbase),
+		Pool:           config.Pool,
+		PoolReward:     (*math.HexOrDecimal256)(pool),
+		Delegators:     make(map[common.Address]*math.HexOrDecimal256),
+		Total:          (*math.HexOrDecimal256)(new(big.Int).Add(coinbase, pool)),
+		Transfers:      append(make([]RewardTransfer, 0), api.equality.rewardTransfers(config, header, recipient)...),
+	}, nil
+}
+
+// GetKickedCandidates retrieves the validators kicked out of the candidates when
+// entering the epoch, the latest one if not specified, along with the blocks they
+// minted in the previous epoch and the threshold they missed.
+func (api *API) GetKickedCandidates(epoch *uint64) ([]rpcKickedCandidate, error) {
+	if epoch == nil {
+		headerExtra, err := DecodeHeaderExtra(api.chain.CurrentHeader())
+		if err != nil {
+			return nil, err
+		}
+		epoch = &headerExtra.Epoch
+	}
+
+	result := make([]rpcKickedCandidate, 0)
+	for _, event := range rawdb.ReadKickOutEvents(api.equality.db, *epoch) {
+		kickOut := rawdb.CandidateEvent{Kind: candidateEventKickOut, Number: event.Number}
+		if !canonicalCandidateEvent(api.chain, event.Address, kickOut) {
+			continue
+		}
+		result = append(result, rpcKickedCandidate{
+			Address:     event.Address,
+			BlockNumber: math.NewHexOrDecimal256(int64(event.Number)),
+			MintCount:   event.MintCount,
+			Threshold:   event.Threshold,
+		})
+	}
+	return result, nil
+}
+
 // GetCandidatesCount retrieves number of the candidates at specified block
 func (api *API) GetCandidatesCount(number *rpc.BlockNumber) (rpcCandidatesCount, error) {
 	snap, _, err := api.loadSnapshot(number)
@@ -139,14 +487,19 @@ func (api *API) GetValidators(number *rpc.BlockNumber) ([]rpcValidator, error) {
 	if err != nil {
 		return nil, err
 	}
+	return validatorsOf(snap, headerExtra.Epoch)
+}
 
+// validatorsOf converts the validators of a snapshot to their RPC representation,
+// along with the count of blocks they minted in the epoch.
+func validatorsOf(snap *Snapshot, epoch uint64) ([]rpcValidator, error) {
 	validators, err := snap.GetValidators()
 	if err != nil {
 		return nil, err
 	}
 
 	mapper := make(map[common.Address]*big.Int)
-	addresses, err := snap.CountMinted(headerExtra.Epoch)
+	addresses, err := snap.CountMinted(epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -162,3 +515,154 @@ func (api *API) GetValidators(number *rpc.BlockNumber) ([]rpcValidator, error) {
 	}
 	return result, nil
 }
+
+// GetValidatorStats retrieves the count of slots filled and missed by every
+// validator since the node started, the current validators included.
+func (api *API) GetValidatorStats() ([]rpcValidatorStats, error) {
+	stats := api.equality.slots.snapshot()
+
+	current := make(map[common.Address]bool)
+	if snap, _, err := api.loadSnapshot(nil); err == nil {
+		validators, err := snap.GetValidators()
+		if err != nil {
+			return nil, err
+		}
+		for _, validator := range validators {
+			current[validator] = true
+			if _, ok := stats[validator]; !ok {
+				stats[validator] = validatorStats{}
+			}
+		}
+	}
+	result := make([]rpcValidatorStats, 0, len(stats))
+	for address, validator := range stats {
+		result = append(result, rpcValidatorStats{
+			Address:     address,
+			IsValidator: current[address],
+			Minted:      validator.Minted,
+			Missed:      validator.Missed,
+			LastMissed:  validator.LastMissed,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].Address[:], result[j].Address[:]) < 0
+	})
+	return result, nil
+}
+
+// GetValidatorPerformance retrieves the blocks a validator produced out of the
+// slots it was scheduled in within a range of epochs, the current one if not
+// specified, along with the average propagation delay of its blocks and its
+// kick-outs. Only the blocks fully verified by the node are indexed.
+func (api *API) GetValidatorPerformance(address common.Address, fromEpoch, toEpoch *uint64) (rpcValidatorPerformance, error) {
+	if toEpoch == nil {
+		headerExtra, err := DecodeHeaderExtra(api.chain.CurrentHeader())
+		if err != nil {
+			return rpcValidatorPerformance{}, err
+		}
+		toEpoch = &headerExtra.Epoch
+	}
+	if fromEpoch == nil {
+		fromEpoch = toEpoch
+	}
+	if *fromEpoch > *toEpoch {
+		return rpcValidatorPerformance{}, fmt.Errorf("invalid epoch range %d - %d", *fromEpoch, *toEpoch)
+	}
+	result := rpcValidatorPerformance{
+		Address:   address,
+		FromEpoch: *fromEpoch,
+		ToEpoch:   *toEpoch,
+		Epochs:    make([]rpcEpochPerformance, 0),
+		KickOuts:  make([]rpcKickedCandidate, 0),
+	}
+	// Aggregate the slots of the canonical blocks by epoch
+	var (
+		canonical = make(map[uint64]common.Hash)
+		delays    uint64
+		samples   uint64
+	)
+	for _, record := range rawdb.ReadSlotRecords(api.equality.db, address, *fromEpoch, *toEpoch) {
+		hash, ok := canonical[record.Number]
+		if !ok {
+			if header := api.chain.GetHeaderByNumber(record.Number); header != nil {
+				hash = header.Hash()
+			}
+			canonical[record.Number] = hash
+		}
+		if hash != record.Hash {
+			continue
+		}
+		if n := len(result.Epochs); n == 0 || result.Epochs[n-1].Epoch != record.Epoch {
+			result.Epochs = append(result.Epochs, rpcEpochPerformance{Epoch: record.Epoch})
+		}
+		epoch := &result.Epochs[len(result.Epochs)-1]
+		epoch.Expected++
+		result.Expected++
+		if record.Minted {
+			epoch.Produced++
+			result.Produced++
+			if record.Delay > 0 {
+				delays += record.Delay
+				samples++
+			}
+		}
+	}
+	if samples > 0 {
+		average := delays / samples
+		result.AverageDelay = &average
+	}
+	// Look up the kick-outs of the validator within the epochs
+	for _, event := range rawdb.ReadCandidateEvents(api.equality.db, address) {
+		if event.Kind != candidateEventKickOut || event.Epoch < *fromEpoch || event.Epoch > *toEpoch {
+			continue
+		}
+		if !canonicalCandidateEvent(api.chain, address, event) {
+			continue
+		}
+		for _, kickOut := range rawdb.ReadKickOutEvents(api.equality.db, event.Epoch) {
+			if kickOut.Address == address && kickOut.Number == event.Number {
+				result.KickOuts = append(result.KickOuts, rpcKickedCandidate{
+					Address:     address,
+					BlockNumber: math.NewHexOrDecimal256(int64(event.Number)),
+					MintCount:   kickOut.MintCount,
+					Threshold:   kickOut.Threshold,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// GetEpochStats retrieves the statistics of a finished epoch of the canonical
+// chain, the previous one if not specified. They are aggregated when the epoch
+// rolls over, the epochs which predate the index are aggregated on demand.
+func (api *API) GetEpochStats(epoch *uint64) (rpcEpochStats, error) {
+	if epoch == nil {
+		headerExtra, err := DecodeHeaderExtra(api.chain.CurrentHeader())
+		if err != nil {
+			return rpcEpochStats{}, err
+		}
+		if headerExtra.Epoch == 0 {
+			return rpcEpochStats{}, errEpochNotFinished
+		}
+		previous := headerExtra.Epoch - 1
+		epoch = &previous
+	}
+	stats, err := api.equality.canonicalEpochStats(api.chain, *epoch)
+	if err != nil {
+		return rpcEpochStats{}, err
+	}
+	return rpcEpochStats{
+		Epoch:      stats.Epoch,
+		FirstBlock: stats.FirstBlock,
+		LastBlock:  stats.LastBlock,
+		Blocks:     stats.LastBlock - stats.FirstBlock + 1,
+		Proposers:  stats.Proposers,
+		Interval:   stats.Interval,
+		Rewards:    (*math.HexOrDecimal256)(stats.Rewards),
+		Registered: stats.Registered,
+		Canceled:   stats.Canceled,
+		KickedOut:  stats.KickedOut,
+		Expired:    stats.Expired,
+	}, nil
+}
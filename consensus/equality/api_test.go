@@ -0,0 +1,153 @@
+package equality
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testChainReader is a canonical chain of headers kept in memory.
+type testChainReader struct {
+	config  *params.ChainConfig
+	headers []*types.Header
+}
+
+func (chain *testChainReader) Config() *params.ChainConfig {
+	if chain.config == nil {
+		return params.TestChainConfig
+	}
+	return chain.config
+}
+
+func (chain *testChainReader) CurrentHeader() *types.Header {
+	return chain.headers[len(chain.headers)-1]
+}
+
+func (chain *testChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header := chain.GetHeaderByNumber(number)
+	if header == nil || header.Hash() != hash {
+		return nil
+	}
+	return header
+}
+
+func (chain *testChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(chain.headers)) {
+		return nil
+	}
+	return chain.headers[number]
+}
+
+func (chain *testChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, header := range chain.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+
+// newTestHeader creates an unsigned header carrying the HeaderExtra.
+func newTestHeader(t *testing.T, number uint64, headerExtra HeaderExtra) *types.Header {
+	data, err := headerExtra.Encode()
+	assert.Nil(t, err)
+
+	extra := append(bytes.Repeat([]byte{0x00}, extraVanity), data...)
+	extra = append(extra, bytes.Repeat([]byte{0x00}, extraSeal)...)
+	return &types.Header{Number: new(big.Int).SetUint64(number), Extra: extra}
+}
+
+func TestDumpSnapshot(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	config := params.EqualityConfig{
+		Period:              3,
+		Epoch:               100,
+		MaxValidatorsCount:  21,
+		MinCandidateBalance: big.NewInt(1000),
+	}
+	equality := New(&config, db)
+
+	address1 := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	address2 := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+
+	snap, err := newSnapshot(db)
+	assert.Nil(t, err)
+	assert.Nil(t, snap.SetChainConfig(config))
+	assert.Nil(t, snap.SetValidators([]common.Address{address1}))
+	_, err = snap.BecomeCandidate(address1, 1, big.NewInt(0))
+	assert.Nil(t, err)
+	_, err = snap.BecomeCandidate(address2, 1, big.NewInt(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, snap.MintBlock(1, 1, address1))
+	root, err := snap.Root()
+	assert.Nil(t, err)
+	assert.Nil(t, snap.Commit(root))
+
+	chain := &testChainReader{headers: []*types.Header{
+		{Number: big.NewInt(0)},
+		newTestHeader(t, 1, HeaderExtra{Root: root, Epoch: 1, EpochBlock: 1}),
+	}}
+	api := &API{chain: chain, equality: equality}
+
+	dump, err := api.DumpSnapshot(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, chain.headers[1].Hash(), dump.BlockHash)
+	assert.Equal(t, uint64(1), dump.Epoch)
+	assert.Equal(t, root.CandidateHash, dump.CandidateHash)
+	assert.Equal(t, 1, len(dump.Validators))
+	assert.Equal(t, address1, dump.Validators[0].Address)
+	assert.Equal(t, big.NewInt(1), dump.Validators[0].CountMinted)
+	assert.Equal(t, 2, len(dump.Candidates))
+	assert.Equal(t, address2, dump.Candidates[0].Address)
+	assert.True(t, config.Equal(dump.Config))
+
+	number := rpc.BlockNumber(2)
+	_, err = api.DumpSnapshot(&number)
+	assert.Equal(t, errUnknownBlock, err)
+}
+
+func TestGetBlockRewards(t *testing.T) {
+	pool := common.HexToAddress("0x53d77827bE168aB2a911B5A14D0f16D1C5657196")
+	coinbase := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	config := params.EqualityConfig{
+		MinCandidateBalance: big.NewInt(1000),
+		Pool:                pool,
+		Rewards: params.EqualityRewards{
+			{Number: 1, Reward: big.NewInt(1000)},
+			{Number: 2, Reward: big.NewInt(500)},
+		},
+	}
+	equality := New(&config, rawdb.NewMemoryDatabase())
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	chain := &testChainReader{headers: []*types.Header{
+		genesis,
+		{Number: big.NewInt(1), ParentHash: genesis.Hash(), Coinbase: coinbase},
+	}}
+	api := &API{chain: chain, equality: equality}
+
+	rewards, err := api.GetBlockRewards(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, coinbase, rewards.Coinbase)
+	assert.Equal(t, pool, rewards.Pool)
+	assert.Equal(t, big.NewInt(100), (*big.Int)(rewards.CoinbaseReward))
+	assert.Equal(t, big.NewInt(900), (*big.Int)(rewards.PoolReward))
+	assert.Equal(t, big.NewInt(1000), (*big.Int)(rewards.Total))
+	assert.Equal(t, 0, len(rewards.Delegators))
+	assert.Equal(t, []RewardTransfer{
+		{Type: RewardTypeBlock, To: coinbase, Value: (*hexutil.Big)(big.NewInt(100))},
+		{Type: RewardTypePool, To: pool, Value: (*hexutil.Big)(big.NewInt(900))},
+	}, rewards.Transfers)
+
+	number := rpc.BlockNumber(0)
+	_, err = api.GetBlockRewards(&number)
+	assert.NotNil(t, err)
+}
@@ -11,10 +11,12 @@ import (
 	"github.com/SecretBlockChain/go-secret/accounts"
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/misc"
 	"github.com/SecretBlockChain/go-secret/core/state"
 	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/crypto"
 	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/metrics"
 	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/SecretBlockChain/go-secret/rlp"
 	"github.com/SecretBlockChain/go-secret/trie"
@@ -22,6 +24,11 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
+var (
+	sealTimeHistogram  = metrics.NewRegisteredHistogram("equality/seal/time", nil, metrics.NewExpDecaySample(1028, 0.015))
+	sealDelayHistogram = metrics.NewRegisteredHistogram("equality/seal/delay", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
 // ecrecover extracts the Ethereum account address from a signed header.
 func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
 	// If the signature's already cached, return that
@@ -96,6 +103,26 @@ func (e *Equality) verifyHeader(chain consensus.ChainHeaderReader, header *types
 	}
 	log.Trace("[equality] VerifyHeader", "number", header.Number.Int64())
 
+	if err := verifyStandaloneFields(header); err != nil {
+		return err
+	}
+
+	// Light clients lack the snapshots, rely on the epoch headers instead
+	if e.lightMode() {
+		return e.verifyLightFields(chain, header, parents)
+	}
+
+	// All basic checks passed, verify cascading fields
+	err := e.verifyCascadingFields(chain, header, parents)
+	if err != nil {
+		log.Warn("[equality] Failed to verify cascading fields", "number", header.Number.Int64(), "reason", err)
+	}
+	return err
+}
+
+// verifyStandaloneFields checks the header fields which don't depend on other
+// headers.
+func verifyStandaloneFields(header *types.Header) error {
 	// Don't waste time checking blocks from the future
 	if header.Time > uint64(time.Now().Unix()) {
 		return consensus.ErrFutureBlock
@@ -118,13 +145,7 @@ func (e *Equality) verifyHeader(chain consensus.ChainHeaderReader, header *types
 	if header.UncleHash != uncleHash {
 		return errInvalidUncleHash
 	}
-
-	// All basic checks passed, verify cascading fields
-	err := e.verifyCascadingFields(chain, header, parents)
-	if err != nil {
-		log.Warn("[equality] Failed to verify cascading fields", "number", header.Number.Int64(), "reason", err)
-	}
-	return err
+	return nil
 }
 
 // verifyCascadingFields verifies all the header fields that are not standalone,
@@ -151,6 +172,9 @@ func (e *Equality) verifyCascadingFields(chain consensus.ChainHeaderReader, head
 	if parent.Time > header.Time {
 		return ErrInvalidTimestamp
 	}
+	if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
 
 	// Load snapshot of parent block
 	var snap *Snapshot
@@ -161,8 +185,8 @@ func (e *Equality) verifyCascadingFields(chain consensus.ChainHeaderReader, head
 	}
 
 	parentHeaderExtra := headerExtra
-	if parent.Number.Int64() == 0 {
-		snap, err = newSnapshot(e.db)
+	if parent.Number.Uint64() < e.start {
+		snap, err = e.createSnapshot()
 		if err != nil {
 			return err
 		}
@@ -171,16 +195,25 @@ func (e *Equality) verifyCascadingFields(chain consensus.ChainHeaderReader, head
 		if err != nil {
 			return err
 		}
+		// Blocks imported without their snapshot, e.g. by fast sync, are replayed
+		if len(parents) == 0 && !e.snapshotAvailable(parentHeaderExtra.Root) {
+			if err = e.EnsureSnapshot(chain, parent); err != nil {
+				return err
+			}
+		}
 
 		config, err = e.chainConfigByHash(parentHeaderExtra.Root.ConfigHash)
 		if err != nil {
 			return err
 		}
 
-		snap, err = loadSnapshot(e.db, parentHeaderExtra.Root)
+		snap, err = e.openSnapshot(parentHeaderExtra.Root)
 		if err != nil {
 			return err
 		}
+		if err = e.checkHalt(snap, number); err != nil {
+			return err
+		}
 	}
 
 	// Ensure that the epoch timestamp and parent block are continuous
@@ -191,7 +224,7 @@ func (e *Equality) verifyCascadingFields(chain consensus.ChainHeaderReader, head
 	}
 
 	// Retrieve the snapshot needed to verify this header and cache it
-	err = snap.apply(config, header, headerExtra)
+	err = snap.apply(config, header, headerExtra, number == e.start)
 	if err != nil {
 		return err
 	}
@@ -216,6 +249,14 @@ func (e *Equality) verifyCascadingFields(chain consensus.ChainHeaderReader, head
 	if err = snap.Commit(root); err != nil {
 		return errors.New("failed to write snapshot")
 	}
+	if headerExtra.EpochBlock == number {
+		if err = snap.Persist(); err != nil {
+			return errors.New("failed to write snapshot")
+		}
+	}
+	e.writeCandidateHistory(chain, config, header, headerExtra, parentHeaderExtra.Root)
+	e.writeProposalHistory(config, header, headerExtra, parentHeaderExtra.Root)
+	e.writeSlotHistory(parent, header, headerExtra)
 	return nil
 }
 
@@ -235,7 +276,10 @@ func (e *Equality) VerifySeal(chain consensus.ChainHeaderReader, header *types.H
 
 	config := *e.config
 	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
-	if header.Number.Int64() > 1 {
+	if e.lightMode() {
+		return e.verifyLightSeal(chain, header, parent, nil)
+	}
+	if header.Number.Uint64() > e.start {
 		var err error
 		config, err = e.chainConfig(parent)
 		if err != nil {
@@ -286,7 +330,7 @@ func (e *Equality) Prepare(chain consensus.ChainHeaderReader, header *types.Head
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
-	if number == 1 {
+	if number == e.start {
 		config = *e.config
 		now := time.Now().Unix()
 		header.Time = parent.Time + config.Period
@@ -307,6 +351,15 @@ func (e *Equality) Prepare(chain consensus.ChainHeaderReader, header *types.Head
 			return err
 		}
 
+		// Refuse to mint past the halt approved by the validators
+		snap, err := e.openSnapshot(parentHeaderExtra.Root)
+		if err != nil {
+			return err
+		}
+		if err := e.checkHalt(snap, number); err != nil {
+			return err
+		}
+
 		now := time.Now().Unix()
 		header.Time = parent.Time + config.Period
 		if int64(header.Time) < now {
@@ -356,16 +409,31 @@ func (e *Equality) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 		return
 	}
 
+	var parentRoot Root
 	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
-	if number <= 1 {
-		snap, err = newSnapshot(e.db)
+	if number <= e.start {
+		snap, err = e.createSnapshot()
 	} else {
 		parentHeaderExtra, err := DecodeHeaderExtra(parent)
 		if err != nil {
 			state.Reset(common.Hash{})
 			return
 		}
-		snap, err = loadSnapshot(e.db, parentHeaderExtra.Root)
+		parentRoot = parentHeaderExtra.Root
+
+		// Header-only nodes fall back to the replay of the blocks the header doesn't settle
+		if e.headerOnly {
+			if e.finalizeHeaderOnly(chain, header, state, headerExtra, parentRoot, txs) {
+				header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+				header.UncleHash = types.CalcUncleHash(nil)
+				return
+			}
+			if err := e.EnsureSnapshot(chain, parent); err != nil {
+				state.Reset(common.Hash{})
+				return
+			}
+		}
+		snap, err = e.openSnapshot(parentRoot)
 	}
 	if err != nil {
 		state.Reset(common.Hash{})
@@ -379,20 +447,36 @@ func (e *Equality) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 		return
 	}
 
-	// Accumulate any block rewards and commit the final state root
-	e.accumulateRewards(config, state, header)
-
 	// Replay custom transactions and check HeaderExtra of block header
 	temp := HeaderExtra{
 		Root:       headerExtra.Root,
 		Epoch:      headerExtra.Epoch,
 		EpochBlock: headerExtra.EpochBlock,
 	}
-	e.processTransactions(config, state, header, snap, &temp, txs)
-	if err = e.tryElect(config, header, snap, &temp); err != nil || !temp.Equal(headerExtra) {
+
+	// Accumulate any block rewards and commit the final state root
+	recipient, err := rewardRecipient(chain.Config(), snap, header, &temp)
+	if err != nil {
+		state.Reset(common.Hash{})
+		return
+	}
+	transfers := e.accumulateRewards(config, state, header, recipient)
+	e.processTransactions(config, chain.Config(), state, header, snap, &temp, txs)
+	if chain.Config().IsRewardLog(header.Number) {
+		logRewardPaid(state, header, config, txs, transfers)
+	}
+	if err = e.expireCandidates(config, state, header, snap, &temp); err != nil {
+		state.Reset(common.Hash{})
+		return
+	}
+	if err = e.tryElect(config, state, header, snap, &temp); err != nil || !temp.Equal(headerExtra) {
 		state.Reset(common.Hash{})
 		return
 	}
+	if e.headerOnly {
+		// Verification doesn't record the history in header-only mode
+		e.writeCandidateHistory(chain, config, header, headerExtra, parentRoot)
+	}
 
 	// Accumulate any block and uncle rewards and commit the final state root
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
@@ -419,14 +503,14 @@ func (e *Equality) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 		EpochBlock: oldHeaderExtra.EpochBlock,
 	}
 	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
-	if header.Number.Int64() > 1 {
+	if header.Number.Uint64() > e.start {
 		parentHeaderExtra, err := DecodeHeaderExtra(parent)
 		if err != nil {
 			return nil, err
 		}
 		headerExtra.Root = parentHeaderExtra.Root
 	}
-	snap, err := loadSnapshot(e.db, headerExtra.Root)
+	snap, err := e.openSnapshot(headerExtra.Root)
 	if err != nil {
 		return nil, err
 	}
@@ -438,7 +522,11 @@ func (e *Equality) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 	}
 
 	// Accumulate any block rewards and commit the final state root
-	e.accumulateRewards(config, state, header)
+	recipient, err := rewardRecipient(chain.Config(), snap, header, &headerExtra)
+	if err != nil {
+		return nil, err
+	}
+	transfers := e.accumulateRewards(config, state, header, recipient)
 
 	// Save validator of block to snapshot
 	if err = snap.MintBlock(headerExtra.Epoch, header.Number.Uint64(), header.Coinbase); err != nil {
@@ -446,10 +534,19 @@ func (e *Equality) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 	}
 
 	// Parse and process custom transactions
-	e.processTransactions(config, state, header, snap, &headerExtra, txs)
+	e.processTransactions(config, chain.Config(), state, header, snap, &headerExtra, txs)
+	if chain.Config().IsRewardLog(header.Number) {
+		logRewardPaid(state, header, config, txs, transfers)
+	}
+
+	// Expire dormant candidates in first block for epoch
+	if err = e.expireCandidates(config, state, header, snap, &headerExtra); err != nil {
+		log.Warn("[equality] Failed to expire candidates", "reason", err)
+		return nil, err
+	}
 
 	// Elect validators in first block for epoch
-	if err = e.tryElect(config, header, snap, &headerExtra); err != nil {
+	if err = e.tryElect(config, state, header, snap, &headerExtra); err != nil {
 		log.Warn("[equality] Failed to try elect", "reason", err)
 		return nil, err
 	}
@@ -462,6 +559,11 @@ func (e *Equality) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 	if err = snap.Commit(headerExtra.Root); err != nil {
 		return nil, err
 	}
+	if headerExtra.EpochBlock == header.Number.Uint64() {
+		if err = snap.Persist(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Write HeaderExtra of current block into header.Extra
 	data, err := headerExtra.Encode()
@@ -474,6 +576,7 @@ func (e *Equality) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	header.UncleHash = types.CalcUncleHash(nil)
+	attachLogs(state, receipts)
 	return types.NewBlock(header, txs, nil, receipts, new(trie.Trie)), nil
 }
 
@@ -484,6 +587,7 @@ func (e *Equality) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 // than one result may also be returned depending on the consensus algorithm.
 func (e *Equality) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
 	log.Trace("[equality] Seal", "number", block.Number().Int64())
+	start := time.Now()
 
 	// Sealing the genesis block is not supported
 	header := block.Header()
@@ -508,25 +612,54 @@ func (e *Equality) Seal(chain consensus.ChainHeaderReader, block *types.Block, r
 		return err
 	}
 
+	// Don't hold the signer fields for the entire sealing procedure
+	e.lock.RLock()
+	signer, signFn, guard, policies := e.signer, e.signFn, e.guard, e.policies
+	e.lock.RUnlock()
+
 	// Bail out if we're unauthorized to sign a block
-	if !e.inTurn(config, parent, header.Time, header.Coinbase) {
+	if !e.inTurn(config, parent, header.Time, signer) {
 		return errUnauthorized
 	}
+	if err := e.checkSealPolicies(policies, config, parent, header, signer); err != nil {
+		log.Warn("[equality] Refusing to seal block", "number", number, "err", err)
+		return err
+	}
 
-	// Don't hold the signer fields for the entire sealing procedure
-	e.lock.RLock()
-	signer, signFn := e.signer, e.signFn
-	e.lock.RUnlock()
+	// Record the slot before signing, refusing to sign twice across restarts
+	if guard != nil {
+		headerExtra, err := DecodeHeaderExtra(header)
+		if err != nil {
+			return err
+		}
+		slot := (header.Time - config.GenesisTimestamp) / config.Period
+		if err := guard.Allow(headerExtra.Epoch, slot, number, SealHash(header)); err != nil {
+			return err
+		}
+	}
 
 	// Sign all the things!
-	sigHash, err := signFn(accounts.Account{Address: signer}, accounts.MimetypeClique, EqualityRLP(header))
+	sigHash, err := signFn(accounts.Account{Address: signer}, accounts.MimetypeEquality, EqualityRLP(header))
 	if err != nil {
 		return err
 	}
 	copy(header.Extra[len(header.Extra)-extraSeal:], sigHash)
+	sealTimeHistogram.Update(time.Since(start).Milliseconds())
+
+	// Hardware wallets wait for the confirmation of the user, don't propagate
+	// a block whose slot passed in the meantime
+	if deadline := time.Unix(int64(header.Time+config.Period), 0); time.Now().After(deadline) {
+		log.Warn("[equality] Signer too slow to seal in the slot", "number", number, "elapsed", common.PrettyDuration(time.Since(start)))
+		return errSealTooLate
+	}
 
 	// Wait until sealing is terminated or delay timeout.
 	delay := time.Unix(int64(header.Time), 0).Sub(time.Now())
+	if delay > 0 {
+		sealDelayHistogram.Update(delay.Milliseconds())
+	} else {
+		sealDelayHistogram.Update(0)
+	}
 	log.Info("[equality] Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay))
 	go func() {
 		select {
@@ -537,6 +670,10 @@ func (e *Equality) Seal(chain consensus.ChainHeaderReader, block *types.Block, r
 
 		select {
 		case results <- block.WithSeal(header):
+			e.lock.Lock()
+			e.lastSealed = number
+			e.lock.Unlock()
+			e.events.blockSealed.Send(BlockSealedEvent{Signer: signer, Number: number, Hash: header.Hash()})
 		default:
 			log.Warn("[equality] Sealing result is not read by miner", "sealhash", SealHash(header))
 		}
@@ -577,7 +714,7 @@ func EqualityRLP(header *types.Header) []byte {
 }
 
 func encodeSigHeader(w io.Writer, header *types.Header) {
-	err := rlp.Encode(w, []interface{}{
+	enc := []interface{}{
 		header.ParentHash,
 		header.UncleHash,
 		header.Coinbase,
@@ -593,8 +730,11 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 		header.Extra[:len(header.Extra)-crypto.SignatureLength], // Yes, this will panic if extra is too short
 		header.MixDigest,
 		header.Nonce,
-	})
-	if err != nil {
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	if err := rlp.Encode(w, enc); err != nil {
 		panic("can't encode: " + err.Error())
 	}
 }
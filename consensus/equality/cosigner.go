@@ -0,0 +1,97 @@
+package equality
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// CoSigner is a host approving the seal hashes of a validator with its own key
+// through a remote signer.
+type CoSigner struct {
+	Address common.Address
+	signer  *RemoteSigner
+}
+
+// ParseCoSigner parses a co-signer given as address@url, the client certificate
+// of the config authenticating to its remote signer.
+func ParseCoSigner(spec string, config RemoteSignerConfig) (*CoSigner, error) {
+	parts := strings.SplitN(spec, "@", 2)
+	if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+		return nil, fmt.Errorf("invalid co-signer %q, want address@url", spec)
+	}
+	config.URL = parts[1]
+	signer, err := NewRemoteSigner(config)
+	if err != nil {
+		return nil, err
+	}
+	return &CoSigner{Address: common.HexToAddress(parts[0]), signer: signer}, nil
+}
+
+// ApprovalGate holds back the seals of the local node until t of the n
+// co-signers of the validator approved the seal hash, so that a compromised or
+// misbehaving block-producing node can be stopped by the co-signers refusing to
+// approve, e.g. on slashing protection grounds.
+//
+// The gate is advisory only: it is not threshold signing. The seal is the
+// signature of the validator key held by the block-producing host alone, the
+// approvals are discarded and the header carries a single signature. Anyone
+// holding the validator key can seal blocks without any co-signer.
+type ApprovalGate struct {
+	threshold int
+	cosigners []*CoSigner
+	signFn    SignerFn
+}
+
+// NewApprovalGate creates a gate collecting threshold approvals of the co-signers
+// before sealing with the validator signer.
+func NewApprovalGate(threshold int, cosigners []*CoSigner, signFn SignerFn) (*ApprovalGate, error) {
+	if threshold <= 0 || threshold > len(cosigners) {
+		return nil, fmt.Errorf("invalid threshold %d of %d co-signers", threshold, len(cosigners))
+	}
+	seen := make(map[common.Address]bool)
+	for _, cosigner := range cosigners {
+		if seen[cosigner.Address] {
+			return nil, fmt.Errorf("duplicate co-signer %s", cosigner.Address.Hex())
+		}
+		seen[cosigner.Address] = true
+	}
+	return &ApprovalGate{threshold: threshold, cosigners: cosigners, signFn: signFn}, nil
+}
+
+// SignData collects the approvals of the co-signers in parallel and seals the
+// data with the validator signer once enough of them approved, matching the
+// SignerFn of the engine.
+func (s *ApprovalGate) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	type approval struct {
+		cosigner common.Address
+		err      error
+	}
+	approvals := make(chan approval, len(s.cosigners))
+	for _, cosigner := range s.cosigners {
+		go func(cosigner *CoSigner) {
+			// Co-signers sign with their own key, verified by the remote signer
+			_, err := cosigner.signer.SignData(accounts.Account{Address: cosigner.Address}, mimeType, data)
+			approvals <- approval{cosigner: cosigner.Address, err: err}
+		}(cosigner)
+	}
+	var approved, denied int
+	for range s.cosigners {
+		result := <-approvals
+		if result.err != nil {
+			log.Warn("[equality] Co-signer refused to approve the seal", "cosigner", result.cosigner, "err", result.err)
+			if denied++; len(s.cosigners)-denied < s.threshold {
+				return nil, errors.New("not enough co-signer approvals")
+			}
+			continue
+		}
+		if approved++; approved == s.threshold {
+			break
+		}
+	}
+	return s.signFn(account, mimeType, data)
+}
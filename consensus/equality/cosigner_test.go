@@ -0,0 +1,73 @@
+package equality
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCoSigner starts a remote signer approving with a fresh key, or refusing
+// to approve anything.
+func newTestCoSigner(t *testing.T, approve bool) (*CoSigner, func()) {
+	key, _ := crypto.GenerateKey()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !approve {
+			http.Error(w, "slashing protection", http.StatusPreconditionFailed)
+			return
+		}
+		var req struct {
+			Data string `json:"data"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		data, _ := hexutil.Decode(req.Data)
+		signature, _ := crypto.Sign(crypto.Keccak256(data), key)
+		w.Write([]byte(hexutil.Encode(signature)))
+	}))
+	cosigner, err := ParseCoSigner(crypto.PubkeyToAddress(key.PublicKey).Hex()+"@"+server.URL, RemoteSignerConfig{})
+	assert.Nil(t, err)
+	return cosigner, server.Close
+}
+
+func TestApprovalGate(t *testing.T) {
+	var (
+		cosigners []*CoSigner
+		sealed    int
+	)
+	for _, approve := range []bool{true, false, true} {
+		cosigner, closeFn := newTestCoSigner(t, approve)
+		defer closeFn()
+		cosigners = append(cosigners, cosigner)
+	}
+	signFn := func(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+		sealed++
+		return crypto.Sign(crypto.Keccak256(data), testUserKey)
+	}
+	account := accounts.Account{Address: testUserAddress}
+
+	// Two approvals out of three are enough to seal
+	signer, err := NewApprovalGate(2, cosigners, signFn)
+	assert.Nil(t, err)
+	_, err = signer.SignData(account, accounts.MimetypeEquality, []byte("header"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sealed)
+
+	// Three approvals can't be collected, the validator key is never used
+	signer, err = NewApprovalGate(3, cosigners, signFn)
+	assert.Nil(t, err)
+	_, err = signer.SignData(account, accounts.MimetypeEquality, []byte("header"))
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, sealed)
+
+	_, err = NewApprovalGate(4, cosigners, signFn)
+	assert.NotNil(t, err)
+	_, err = NewApprovalGate(1, []*CoSigner{cosigners[0], cosigners[0]}, signFn)
+	assert.NotNil(t, err)
+	_, err = ParseCoSigner("https://localhost", RemoteSignerConfig{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,96 @@
+package equality
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// errDeviceUnsupported is returned when the hardware wallet sealing the blocks
+// can't sign the seal hashes, its firmware only signing transactions.
+var errDeviceUnsupported = errors.New("hardware wallet can't sign equality seals")
+
+// DeviceSigner seals blocks with a key held by a hardware wallet. The session
+// with the device is kept open across blocks, and opened again when the device
+// was unplugged or locked in between.
+type DeviceSigner struct {
+	manager *accounts.Manager
+	url     string
+	path    accounts.DerivationPath
+
+	wallet  accounts.Wallet // Wallet of the current session, nil if closed
+	account accounts.Account
+	lock    sync.Mutex // Serializes the sessions with the device
+}
+
+// NewDeviceSigner opens a session with the hardware wallet and pins the account
+// derived at the given path.
+func NewDeviceSigner(manager *accounts.Manager, url string, path accounts.DerivationPath) (*DeviceSigner, error) {
+	signer := &DeviceSigner{manager: manager, url: url, path: path}
+	if err := signer.open(); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// Address returns the address of the key sealing the blocks.
+func (s *DeviceSigner) Address() common.Address {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.account.Address
+}
+
+// open looks up the wallet, which is a new one once the device was plugged back,
+// and opens a session deriving the sealing account.
+func (s *DeviceSigner) open() error {
+	wallet, err := s.manager.Wallet(s.url)
+	if err != nil {
+		return err
+	}
+	if err := wallet.Open(""); err != nil && err != accounts.ErrWalletAlreadyOpen {
+		return err
+	}
+	account, err := wallet.Derive(s.path, true)
+	if err != nil {
+		return err
+	}
+	if s.wallet != nil && account.Address != s.account.Address {
+		return fmt.Errorf("device account changed from %s to %s", s.account.Address.Hex(), account.Address.Hex())
+	}
+	s.wallet, s.account = wallet, account
+	return nil
+}
+
+// SignData signs the data with the device, matching the SignerFn of the engine.
+func (s *DeviceSigner) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if account.Address != s.account.Address {
+		return nil, accounts.ErrUnknownAccount
+	}
+	var (
+		signature []byte
+		err       = accounts.ErrWalletClosed
+	)
+	if s.wallet != nil {
+		signature, err = s.wallet.SignData(s.account, mimeType, data)
+	}
+	if err == accounts.ErrWalletClosed || err == accounts.ErrUnknownWallet || err == accounts.ErrUnknownAccount {
+		// The session ended since the last block, open a new one
+		log.Info("[equality] Reopening hardware wallet session", "url", s.url, "err", err)
+		if err = s.open(); err != nil {
+			return nil, err
+		}
+		signature, err = s.wallet.SignData(s.account, mimeType, data)
+	}
+	if err == accounts.ErrNotSupported {
+		return nil, errDeviceUnsupported
+	}
+	return signature, err
+}
@@ -0,0 +1,91 @@
+package equality
+
+import (
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/event"
+	"github.com/stretchr/testify/assert"
+)
+
+// testDevice is a hardware wallet holding the test key, whose session can be
+// closed as if the device was unplugged.
+type testDevice struct {
+	accounts.Wallet
+	open        bool
+	unsupported bool
+}
+
+func (d *testDevice) URL() accounts.URL {
+	return accounts.URL{Scheme: "ledger", Path: "test"}
+}
+
+func (d *testDevice) Open(passphrase string) error {
+	if d.open {
+		return accounts.ErrWalletAlreadyOpen
+	}
+	d.open = true
+	return nil
+}
+
+func (d *testDevice) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	if !d.open {
+		return accounts.Account{}, accounts.ErrWalletClosed
+	}
+	return accounts.Account{Address: testUserAddress, URL: d.URL()}, nil
+}
+
+func (d *testDevice) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	if d.unsupported {
+		return nil, accounts.ErrNotSupported
+	}
+	if !d.open {
+		return nil, accounts.ErrWalletClosed
+	}
+	return crypto.Sign(crypto.Keccak256(data), testUserKey)
+}
+
+type testDeviceBackend struct {
+	device *testDevice
+	feed   event.Feed
+}
+
+func (b *testDeviceBackend) Wallets() []accounts.Wallet {
+	return []accounts.Wallet{b.device}
+}
+
+func (b *testDeviceBackend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return b.feed.Subscribe(sink)
+}
+
+func TestDeviceSigner(t *testing.T) {
+	device := new(testDevice)
+	manager := accounts.NewManager(&accounts.Config{}, &testDeviceBackend{device: device})
+	defer manager.Close()
+
+	signer, err := NewDeviceSigner(manager, "ledger://test", accounts.DefaultBaseDerivationPath)
+	assert.Nil(t, err)
+	assert.Equal(t, testUserAddress, signer.Address())
+
+	account := accounts.Account{Address: testUserAddress}
+	_, err = signer.SignData(account, accounts.MimetypeEquality, []byte("header"))
+	assert.Nil(t, err)
+
+	// The session is opened again once the device is plugged back
+	device.open = false
+	_, err = signer.SignData(account, accounts.MimetypeEquality, []byte("header"))
+	assert.Nil(t, err)
+	assert.True(t, device.open)
+
+	_, err = signer.SignData(accounts.Account{Address: common.HexToAddress("0x01")}, accounts.MimetypeEquality, []byte("header"))
+	assert.Equal(t, accounts.ErrUnknownAccount, err)
+
+	device.unsupported = true
+	_, err = signer.SignData(account, accounts.MimetypeEquality, []byte("header"))
+	assert.Equal(t, errDeviceUnsupported, err)
+
+	_, err = NewDeviceSigner(manager, "trezor://test", accounts.DefaultBaseDerivationPath)
+	assert.Equal(t, accounts.ErrUnknownWallet, err)
+}
@@ -0,0 +1,140 @@
+package equality
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// errEpochNotFinished is returned when asking for the statistics of an epoch
+// which isn't over yet.
+var errEpochNotFinished = errors.New("epoch not finished")
+
+// writeEpochStats aggregates the statistics of the epoch closed by the parent of
+// an epoch block, unless they were already stored for the same chain.
+func (e *Equality) writeEpochStats(chain consensus.ChainHeaderReader, config params.EqualityConfig, header *types.Header, epoch uint64) {
+	number := header.Number.Uint64()
+	if number <= e.start || epoch == 0 {
+		return
+	}
+	if stored := rawdb.ReadEpochStats(e.db, epoch-1); stored != nil && stored.LastHash == header.ParentHash {
+		return
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return
+	}
+	stats, err := e.epochStats(chain, config, parent)
+	if err != nil {
+		log.Debug("[equality] Failed to aggregate epoch stats", "number", number, "err", err)
+		return
+	}
+	rawdb.WriteEpochStats(e.db, stats)
+}
+
+// epochStats aggregates the blocks of the epoch ending with the given header.
+func (e *Equality) epochStats(chain consensus.ChainHeaderReader, config params.EqualityConfig, last *types.Header) (*rawdb.EpochStats, error) {
+	lastExtra, err := DecodeHeaderExtra(last)
+	if err != nil {
+		return nil, err
+	}
+	stats := &rawdb.EpochStats{
+		Epoch:      lastExtra.Epoch,
+		FirstBlock: lastExtra.EpochBlock,
+		LastBlock:  last.Number.Uint64(),
+		LastHash:   last.Hash(),
+		Rewards:    new(big.Int),
+	}
+	proposers := make(map[common.Address]struct{})
+	for current := last; ; {
+		number := current.Number.Uint64()
+		headerExtra, err := DecodeHeaderExtra(current)
+		if err != nil {
+			return nil, err
+		}
+		proposers[current.Coinbase] = struct{}{}
+		if base, pool := blockRewards(config, number); base != nil {
+			stats.Rewards.Add(stats.Rewards, base)
+			stats.Rewards.Add(stats.Rewards, pool)
+		}
+		stats.Registered += uint64(len(headerExtra.CurrentBlockCandidates))
+		stats.Canceled += uint64(len(headerExtra.CurrentBlockCancelCandidates))
+		stats.KickedOut += uint64(len(headerExtra.CurrentBlockKickOutCandidates))
+		stats.Expired += uint64(len(headerExtra.CurrentBlockExpiredCandidates))
+
+		if number <= stats.FirstBlock {
+			if stats.LastBlock > number {
+				stats.Interval = (last.Time - current.Time) * 1000 / (stats.LastBlock - number)
+			}
+			break
+		}
+		if current = chain.GetHeader(current.ParentHash, number-1); current == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+	}
+	stats.Proposers = uint64(len(proposers))
+	return stats, nil
+}
+
+// canonicalEpochStats retrieves the statistics of a finished epoch of the canonical
+// chain, aggregating them again if the ones stored were reorganised away or
+// predate the index.
+func (e *Equality) canonicalEpochStats(chain consensus.ChainHeaderReader, epoch uint64) (*rawdb.EpochStats, error) {
+	if stats := rawdb.ReadEpochStats(e.db, epoch); stats != nil {
+		if header := chain.GetHeaderByNumber(stats.LastBlock); header != nil && header.Hash() == stats.LastHash {
+			return stats, nil
+		}
+	}
+	// Look up the first block of the next epoch, the epochs only ever increase
+	head := chain.CurrentHeader()
+	headExtra, err := DecodeHeaderExtra(head)
+	if err != nil {
+		return nil, err
+	}
+	if epoch >= headExtra.Epoch {
+		return nil, errEpochNotFinished
+	}
+	var searchErr error
+	start := head.Number.Uint64() - uint64(sort.Search(int(head.Number.Uint64()-e.start+1), func(i int) bool {
+		// Search backwards from the head for the first block of an earlier epoch
+		header := chain.GetHeaderByNumber(head.Number.Uint64() - uint64(i))
+		if header == nil {
+			searchErr = consensus.ErrUnknownAncestor
+			return true
+		}
+		headerExtra, err := DecodeHeaderExtra(header)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return headerExtra.Epoch <= epoch
+	}))
+	if searchErr != nil {
+		return nil, searchErr
+	}
+	last := chain.GetHeaderByNumber(start)
+	if last == nil || last.Number.Uint64() < e.start {
+		return nil, errUnknownBlock
+	}
+	var config params.EqualityConfig
+	if e.lightMode() {
+		config = e.lightConfig(last.Number.Uint64())
+	} else if config, err = e.chainConfig(last); err != nil {
+		return nil, err
+	}
+	stats, err := e.epochStats(chain, config, last)
+	if err != nil {
+		return nil, err
+	}
+	if stats.Epoch != epoch {
+		return nil, errUnknownBlock
+	}
+	return stats, nil
+}
@@ -0,0 +1,43 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpochStats(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	first, err := DecodeHeaderExtra(chain.headers[1])
+	assert.Nil(t, err)
+
+	// The epoch was aggregated when the next one started
+	stats := rawdb.ReadEpochStats(sealer.db, first.Epoch)
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, uint64(1), stats.FirstBlock)
+		assert.Equal(t, uint64(2), stats.LastBlock)
+		assert.Equal(t, chain.headers[2].Hash(), stats.LastHash)
+		assert.Equal(t, uint64(1), stats.Proposers)
+	}
+	api := &API{chain: chain, equality: sealer}
+	result, err := api.GetEpochStats(&first.Epoch)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), result.Blocks)
+
+	// Epochs reorganised away are aggregated again on demand
+	rawdb.WriteEpochStats(sealer.db, &rawdb.EpochStats{Epoch: first.Epoch, LastBlock: 2, Proposers: 5})
+	again, err := api.GetEpochStats(&first.Epoch)
+	assert.Nil(t, err)
+	assert.Equal(t, result, again)
+
+	// The current epoch isn't finished yet
+	head, err := DecodeHeaderExtra(chain.headers[len(chain.headers)-1])
+	assert.Nil(t, err)
+	_, err = api.GetEpochStats(&head.Epoch)
+	assert.Equal(t, errEpochNotFinished, err)
+
+	assert.Equal(t, 0, (*big.Int)(result.Rewards).Sign())
+}
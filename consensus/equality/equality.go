@@ -40,6 +40,18 @@ var (
 	// errUnauthorized is returned if a header is signed by a non-authorized entity.
 	errUnauthorized = errors.New("unauthorized")
 
+	// errSealTooLate is returned if the signer took so long to seal a block that
+	// its slot is over.
+	errSealTooLate = errors.New("seal too late for the slot")
+
+	// errNotCandidate is returned if an account which isn't a candidate binds a
+	// signing key.
+	errNotCandidate = errors.New("not a candidate")
+
+	// errInvalidSignerKey is returned if a signing key can't be bound to a
+	// candidate, being in use by another one.
+	errInvalidSignerKey = errors.New("invalid signer key")
+
 	// errUnknownBlock is returned when the list of signers is requested for a block
 	// that is not part of the local blockchain.
 	errUnknownBlock = errors.New("unknown block")
@@ -79,26 +91,61 @@ type Equality struct {
 	signer     common.Address         // Ethereum address of the signing key
 	signFn     SignerFn               // Signer function to authorize hashes with
 	lock       sync.RWMutex           // Protects the signer fields
+	lastSealed uint64                 // Number of the last block sealed, protected by the lock
+	guard      *SignGuard             // Refuses the seals conflicting with the previous ones, protected by the lock
+	policies   []SealPolicy           // Vet the headers before sealing, protected by the lock
+	start      uint64                 // Number of the first block minted by the engine
+
+	lightValidators *lru.ARCCache   // Validators allowed after recent blocks, only set in light mode
+	fetchSnapshot   SnapshotFetcher // Retrieves the snapshots of the epoch blocks from the peers
+	pruner          *snapshotPruner // Retains the recent snapshots in memory, nil in archive mode
+	headerOnly      bool            // Whether the balance changes are taken from the headers, see SetHeaderOnly
+	slots           *slotTracker    // Slots filled and missed by the validators since the start of the node
+	events          *eventFeeds     // Feeds of the consensus events, see PublishChainEvent
+	forksSynced     bool            // Whether the forks of a canonical snapshot were scheduled, protected by the lock
 }
 
 // New creates a Equality proof-of-equality consensus engine with the initial
 // signers set to the ones provided by the user.
 func New(config *params.EqualityConfig, db ethdb.Database) *Equality {
+	return NewFromBlock(config, db, 1)
+}
+
+// NewFromBlock creates a Equality proof-of-equality consensus engine taking over
+// the chain at the given block, the blocks before it being minted by another
+// engine. The genesis validators are elected in the first equality block.
+func NewFromBlock(config *params.EqualityConfig, db ethdb.Database, start uint64) *Equality {
+	if start == 0 {
+		start = 1
+	}
+	if err := config.Validate(); err != nil {
+		log.Warn("[equality] Suspicious engine configuration", "err", err)
+	}
 	signatures, _ := lru.NewARC(inMemorySignatures)
-	return &Equality{db: db, signatures: signatures, config: config}
+	return &Equality{db: db, signatures: signatures, config: config, start: start, slots: newSlotTracker(), events: new(eventFeeds)}
 }
 
 // Close terminates any background threads maintained by the consensus engine.
 func (e *Equality) Close() error {
+	e.events.scope.Close()
+	if e.pruner != nil {
+		return e.pruner.close()
+	}
 	return nil
 }
 
 // APIs returns the RPC APIs this consensus engine provides.
 func (e *Equality) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	api := &API{chain: chain, equality: e}
 	return []rpc.API{{
 		Namespace: "eq",
 		Version:   "1.0",
-		Service:   &API{chain: chain, equality: e},
+		Service:   api,
+		Public:    true,
+	}, {
+		Namespace: "gov",
+		Version:   "1.0",
+		Service:   &GovernanceAPI{api: api},
 		Public:    true,
 	}}
 }
@@ -139,35 +186,38 @@ func (e *Equality) inTurn(config params.EqualityConfig,
 	lastBlockHeader *types.Header, nexBlockTime uint64, signer common.Address) bool {
 
 	validators := config.Validators
-	if lastBlockHeader != nil && lastBlockHeader.Number.Int64() > 0 {
+	if lastBlockHeader != nil && lastBlockHeader.Number.Uint64() >= e.start {
 		headerExtra, err := DecodeHeaderExtra(lastBlockHeader)
 		if err != nil {
 			return false
 		}
 
-		snap, err := loadSnapshot(e.db, headerExtra.Root)
+		snap, err := e.openSnapshot(headerExtra.Root)
 		if err != nil {
 			return false
 		}
 
-		validators, err = snap.GetValidators()
+		validators, err = snap.GetSigners()
 		if err != nil {
 			return false
 		}
 	}
 
-	count := len(validators)
-	if count == 0 {
+	return isInTurn(config, validators, nexBlockTime, signer)
+}
+
+// isInTurn returns if the signer is the validator scheduled at the block time.
+func isInTurn(config params.EqualityConfig, validators []common.Address, blockTime uint64, signer common.Address) bool {
+	if len(validators) == 0 {
 		return false
 	}
-
-	idx := (nexBlockTime - config.GenesisTimestamp) / config.Period % uint64(len(validators))
+	idx := (blockTime - config.GenesisTimestamp) / config.Period % uint64(len(validators))
 	return validators[idx] == signer
 }
 
 // Gets the chain config for the specified block number.
 func (e *Equality) chainConfig(header *types.Header) (params.EqualityConfig, error) {
-	if header == nil || header.Number.Int64() == 0 {
+	if header == nil || header.Number.Uint64() < e.start {
 		return *e.config, nil
 	}
 
@@ -204,8 +254,14 @@ func validatorsToString(validators []common.Address) string {
 	return "[" + strings.Join(slice, ",") + "]"
 }
 
+// kickOutThreshold returns the minimum number of blocks a validator has to mint
+// in an epoch to remain a candidate.
+func kickOutThreshold(config params.EqualityConfig) uint64 {
+	return config.Epoch / config.MaxValidatorsCount / 2
+}
+
 // Elect validators in first block for epoch.
-func (e *Equality) tryElect(config params.EqualityConfig, header *types.Header,
+func (e *Equality) tryElect(config params.EqualityConfig, state *state.StateDB, header *types.Header,
 	snap *Snapshot, headerExtra *HeaderExtra) error {
 
 	// Is come to next epoch?
@@ -214,11 +270,16 @@ func (e *Equality) tryElect(config params.EqualityConfig, header *types.Header,
 		return nil
 	}
 
+	// Activate the approved proposals while the validators of the ending epoch are known
+	if err := e.executeProposals(config, state, header, snap, headerExtra); err != nil {
+		return err
+	}
+
 	// Find not active validators
 	needKickOutValidators := make(SortableAddresses, 0)
-	if number <= 1 {
+	if number <= e.start {
 		for _, validator := range config.Validators {
-			if _, err := snap.BecomeCandidate(validator, 1, big.NewInt(0)); err != nil {
+			if _, err := snap.BecomeCandidate(validator, number, big.NewInt(0)); err != nil {
 				return err
 			}
 			headerExtra.CurrentBlockCandidates = append(headerExtra.CurrentBlockCandidates, validator)
@@ -226,7 +287,7 @@ func (e *Equality) tryElect(config params.EqualityConfig, header *types.Header,
 
 		headerExtra.CurrentBlockCandidates = addressesDistinct(headerExtra.CurrentBlockCandidates)
 	} else {
-		minMint := big.NewInt(int64(config.Epoch / config.MaxValidatorsCount / 2))
+		minMint := new(big.Int).SetUint64(kickOutThreshold(config))
 		validators, err := snap.CountMinted(headerExtra.Epoch - 1)
 		if err != nil {
 			return err
@@ -273,13 +334,108 @@ func (e *Equality) tryElect(config params.EqualityConfig, header *types.Header,
 	headerExtra.CurrentEpochValidators = append(headerExtra.CurrentEpochValidators, candidates...)
 	log.Debug("[equality] Come to next epoch",
 		"number", number, "epoch", headerExtra.Epoch, "validators", validatorsToString(headerExtra.CurrentEpochValidators))
-	return snap.SetValidators(headerExtra.CurrentEpochValidators)
+	if err := snap.SetValidators(headerExtra.CurrentEpochValidators); err != nil {
+		return err
+	}
+	var deposits map[common.Address]Candidate
+	if _, ok := e.config.SystemContract("staking"); ok {
+		if deposits, err = snap.GetCandidates(); err != nil {
+			return err
+		}
+	}
+	e.updateSystemContracts(headerExtra.effectiveConfig(config), state, headerExtra.Epoch, headerExtra.CurrentEpochValidators, deposits)
+
+	// The keys bound to the validators seal their slots from this epoch on
+	for _, validator := range headerExtra.CurrentEpochValidators {
+		key, err := snap.GetSignerKey(validator)
+		if err != nil {
+			return err
+		}
+		if key != validator {
+			headerExtra.CurrentEpochSignerKeys = append(headerExtra.CurrentEpochSignerKeys, SignerKey{Validator: validator, Key: key})
+		}
+	}
+	if err := snap.SetEpochSignerKeys(headerExtra.CurrentEpochSignerKeys); err != nil {
+		return err
+	}
+	if config.CandidateExpiry > 0 {
+		return snap.SetLastElected(headerExtra.CurrentEpochValidators, number)
+	}
+	return nil
 }
 
-// Credits the coinbase of the given block with the mining reward.
-func (e *Equality) accumulateRewards(config params.EqualityConfig, state *state.StateDB, header *types.Header) {
-	var blockReward *big.Int
+// bindSigner binds the signing key to the candidate if the key is free, neither
+// bound to nor being another candidate.
+func bindSigner(snap *Snapshot, candidate, key common.Address) error {
+	if existing, err := snap.GetCandidate(candidate); err != nil {
+		return err
+	} else if existing == nil {
+		return errNotCandidate
+	}
+	if key == (common.Address{}) {
+		return errInvalidSignerKey
+	}
+	if key == candidate {
+		return snap.BindSignerKey(candidate, key)
+	}
+	if owner, err := snap.GetSignerOf(key); err != nil {
+		return err
+	} else if owner != key {
+		return errInvalidSignerKey
+	}
+	if other, err := snap.GetCandidate(key); err == nil && other != nil {
+		return errInvalidSignerKey
+	}
+	return snap.BindSignerKey(candidate, key)
+}
+
+// Cancel and refund the candidates dormant for too long in first block for epoch.
+func (e *Equality) expireCandidates(config params.EqualityConfig, state *state.StateDB, header *types.Header,
+	snap *Snapshot, headerExtra *HeaderExtra) error {
+
 	number := header.Number.Uint64()
+	if config.CandidateExpiry == 0 || number <= e.start || number != headerExtra.EpochBlock {
+		return nil
+	}
+
+	dormant, err := snap.DormantCandidates(number, config.CandidateExpiry*config.Epoch)
+	if err != nil {
+		return err
+	}
+	if len(dormant) == 0 {
+		return nil
+	}
+
+	// Ensure candidate count greater than or equal to safeSize, like kick outs
+	safeSize := int(config.MaxValidatorsCount*2/3 + 1)
+	candidateCount, _ := snap.EnoughCandidates(safeSize + len(dormant))
+	for i, candidate := range dormant {
+		if candidateCount <= safeSize {
+			log.Info("[equality] No more candidate can be expired",
+				"epoch", headerExtra.Epoch, "candidateCount", candidateCount, "dormantCount", len(dormant)-i)
+			break
+		}
+
+		exist, security, err := snap.ExpireCandidate(candidate)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			continue
+		}
+		state.AddBalance(candidate, security)
+
+		candidateCount--
+		headerExtra.CurrentBlockExpiredCandidates = append(headerExtra.CurrentBlockExpiredCandidates, candidate)
+		log.Info("[equality] Expire dormant candidate", "epoch", headerExtra.Epoch, "candidate", candidate, "refund", security)
+	}
+	return nil
+}
+
+// blockRewards returns the amounts credited to the coinbase and to the pool for
+// minting the given block, nil if the block isn't rewarded.
+func blockRewards(config params.EqualityConfig, number uint64) (coinbase *big.Int, pool *big.Int) {
+	var blockReward *big.Int
 	for _, reward := range config.Rewards {
 		blockReward = reward.Reward
 		if reward.Number >= number {
@@ -288,33 +444,121 @@ func (e *Equality) accumulateRewards(config params.EqualityConfig, state *state.
 	}
 
 	if blockReward == nil || blockReward.Cmp(big.NewInt(0)) <= 0 {
-		return
+		return nil, nil
 	}
 
 	base := big.NewInt(0).Div(blockReward, big.NewInt(10))
-	state.AddBalance(header.Coinbase, base)
-	state.AddBalance(config.Pool, big.NewInt(0).Sub(blockReward, base))
+	return base, big.NewInt(0).Sub(blockReward, base)
+}
+
+// rewardTransfers returns the credits of the rewards of the given block as the
+// internal transactions reported to the tracers, the base fee of the block to
+// the pool first, then the shares of the block reward of the recipient, the
+// payout of its coinbase, and of the pool.
+func (e *Equality) rewardTransfers(config params.EqualityConfig, header *types.Header, recipient common.Address) []RewardTransfer {
+	var transfers []RewardTransfer
+
+	// The transactions paid the base fee to no one, the pool gets it unless burned
+	if header.BaseFee != nil && !config.BurnBaseFee {
+		fees := new(big.Int).Mul(header.BaseFee, new(big.Int).SetUint64(header.GasUsed))
+		transfers = append(transfers, newRewardTransfer(RewardTypeBaseFee, config.Pool, fees))
+	}
+	if base, pool := blockRewards(config, header.Number.Uint64()); base != nil {
+		transfers = append(transfers,
+			newRewardTransfer(RewardTypeBlock, recipient, base),
+			newRewardTransfer(RewardTypePool, config.Pool, pool),
+		)
+	}
+	return transfers
+}
+
+// Credits the recipient of the given block, the payout of its coinbase, with the mining reward,
+// and the pool with its share and the base fee of the block, returning the credits made.
+func (e *Equality) accumulateRewards(config params.EqualityConfig, state *state.StateDB, header *types.Header, recipient common.Address) []RewardTransfer {
+	transfers := e.rewardTransfers(config, header, recipient)
+	for _, transfer := range transfers {
+		state.AddBalance(transfer.To, transfer.Value.ToInt())
+	}
+	log.Debug("[equality] Accumulate rewards", "coinbase", header.Coinbase, "recipient", recipient, "pool", config.Pool, "credits", len(transfers))
+	return transfers
+}
+
+// rewardRecipient returns the payout of the coinbase of the block, recorded in
+// the header extra unless it is the coinbase itself. The coinbase is credited
+// before the payout fork.
+func rewardRecipient(config *params.ChainConfig, snap *Snapshot, header *types.Header, headerExtra *HeaderExtra) (common.Address, error) {
+	if !config.IsPayout(header.Number) {
+		return header.Coinbase, nil
+	}
+	recipient, err := snap.GetPayout(header.Coinbase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if recipient != header.Coinbase {
+		headerExtra.RewardRecipient = recipient
+	}
+	return recipient, nil
+}
+
+// registerCandidate registers the candidate with its deposit, recording it in
+// the header. It returns false if the address is a candidate already.
+func registerCandidate(snap *Snapshot, headerExtra *HeaderExtra, candidate common.Address, number uint64, deposit *big.Int) bool {
+	alreadyIsCandidate, err := snap.BecomeCandidate(candidate, number, deposit)
+	if err != nil || alreadyIsCandidate {
+		return false
+	}
+	headerExtra.CurrentBlockCandidates = append(headerExtra.CurrentBlockCandidates, candidate)
+	if addressesExist(headerExtra.CurrentBlockCancelCandidates, candidate) {
+		headerExtra.CurrentBlockCancelCandidates = addressesRemove(headerExtra.CurrentBlockCancelCandidates, candidate)
+	}
+	return true
+}
 
-	log.Debug("[equality] Accumulate rewards",
-		"coinbase", header.Coinbase, "amount", base,
-		"pool", config.Pool, "amount", big.NewInt(0).Sub(blockReward, base))
+// cancelCandidate removes the candidate, recording it in the header, and returns
+// the deposit to refund. It returns false if the address is not a candidate.
+func cancelCandidate(snap *Snapshot, headerExtra *HeaderExtra, candidate common.Address) (*big.Int, bool) {
+	exist, security, err := snap.CancelCandidate(candidate)
+	if err != nil || !exist {
+		return nil, false
+	}
+	headerExtra.CurrentBlockCancelCandidates = append(headerExtra.CurrentBlockCancelCandidates, candidate)
+	if addressesExist(headerExtra.CurrentBlockCandidates, candidate) {
+		headerExtra.CurrentBlockCandidates = addressesRemove(headerExtra.CurrentBlockCandidates, candidate)
+	}
+	return security, true
 }
 
-// Process custom transactions, write into header.Extra.
-func (e *Equality) processTransactions(config params.EqualityConfig, state *state.StateDB, header *types.Header,
+// Process custom transactions, write into header.Extra. From the staking fork on,
+// candidates register and cancel through the staking contract, its logs taking
+// the place of the custom transactions.
+func (e *Equality) processTransactions(config params.EqualityConfig, chainConfig *params.ChainConfig, state *state.StateDB, header *types.Header,
 	snap *Snapshot, headerExtra *HeaderExtra, txs []*types.Transaction) {
 
 	number := header.Number.Uint64()
-	if number <= 1 {
+	staking := chainConfig.IsStaking(header.Number)
+	if number <= e.start {
 		if err := snap.SetChainConfig(config); err != nil {
 			panic(err)
 		}
 		headerExtra.ChainConfig = []params.EqualityConfig{config}
+	} else if override := e.config.OverrideAt(number); override != nil {
+		overridden := override.Apply(config)
+		if err := snap.SetChainConfig(overridden); err != nil {
+			panic(err)
+		}
+		headerExtra.ChainConfig = append(headerExtra.ChainConfig, overridden)
+		log.Info("[equality] Chain config overridden", "number", number, "period", overridden.Period, "epoch", overridden.Epoch, "pool", overridden.Pool)
 	}
 
 	count := 0
-	for _, tx := range txs {
-		ctx, err := NewTransaction(tx)
+	for i, tx := range txs {
+		// Governance logs go to the receipt of their transaction
+		state.Prepare(tx.Hash(), state.BlockHash(), i)
+		if staking {
+			count += processStakingLogs(state, header, snap, headerExtra, tx)
+		}
+
+		ctx, err := e.decodeTransaction(chainConfig, header.Number, tx)
 		if err != nil {
 			continue
 		}
@@ -324,27 +568,119 @@ func (e *Equality) processTransactions(config params.EqualityConfig, state *stat
 			switch ctx.(type) {
 			case *EventBecomeCandidate:
 				event := ctx.(*EventBecomeCandidate)
-				if state.GetBalance(event.Candidate).Cmp(config.MinCandidateBalance) == -1 {
+				if staking || state.GetBalance(event.Candidate).Cmp(config.MinCandidateBalance) == -1 {
 					break
 				}
-				if alreadyIsCandidate, err := snap.BecomeCandidate(event.Candidate, number, config.MinCandidateBalance); err == nil {
-					if !alreadyIsCandidate {
-						state.SubBalance(event.Candidate, config.MinCandidateBalance)
-						headerExtra.CurrentBlockCandidates = append(headerExtra.CurrentBlockCandidates, event.Candidate)
-						if addressesExist(headerExtra.CurrentBlockCancelCandidates, event.Candidate) {
-							headerExtra.CurrentBlockCancelCandidates = addressesRemove(headerExtra.CurrentBlockCancelCandidates, event.Candidate)
-						}
-					}
+				if registerCandidate(snap, headerExtra, event.Candidate, number, config.MinCandidateBalance) {
+					state.SubBalance(event.Candidate, config.MinCandidateBalance)
 				}
 				count++
 			case *EventCancelCandidate:
 				event := ctx.(*EventCancelCandidate)
-				if exist, security, err := snap.CancelCandidate(event.Delegator); err == nil && exist {
+				if staking {
+					break
+				}
+				if security, ok := cancelCandidate(snap, headerExtra, event.Delegator); ok {
 					state.AddBalance(event.Delegator, security)
-					headerExtra.CurrentBlockCancelCandidates = append(headerExtra.CurrentBlockCancelCandidates, event.Delegator)
-					if addressesExist(headerExtra.CurrentBlockCandidates, event.Delegator) {
-						headerExtra.CurrentBlockCandidates = addressesRemove(headerExtra.CurrentBlockCandidates, event.Delegator)
+				}
+				count++
+			case *EventBindSigner:
+				event := ctx.(*EventBindSigner)
+				if !chainConfig.IsSignerKey(header.Number) {
+					break
+				}
+				if err := bindSigner(snap, event.Candidate, event.Key); err == nil {
+					headerExtra.CurrentBlockSignerKeys = append(headerExtra.CurrentBlockSignerKeys, SignerKey{Validator: event.Candidate, Key: event.Key})
+				} else {
+					log.Debug("[equality] Signer key not bound", "candidate", event.Candidate, "key", event.Key, "reason", err)
+				}
+				count++
+			case *EventSetPayout:
+				event := ctx.(*EventSetPayout)
+				if event.Recipient == (common.Address{}) || !chainConfig.IsPayout(header.Number) {
+					break
+				}
+				if exist, err := snap.SetPayout(event.Candidate, event.Recipient); err == nil && exist {
+					headerExtra.CurrentBlockPayouts = append(headerExtra.CurrentBlockPayouts, Payout{Candidate: event.Candidate, Recipient: event.Recipient})
+				}
+				count++
+			case *EventPropose:
+				event := ctx.(*EventPropose)
+				if config.ProposalWindow == 0 || number <= e.start {
+					break
+				}
+				if proposal, err := propose(config, state, header, snap, event); err == nil {
+					headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, *proposal)
+					logProposalSubmitted(state, header, proposal)
+				} else {
+					log.Debug("[equality] Proposal rejected", "proposer", event.Proposer, "proposal", event.ID, "reason", err)
+				}
+				count++
+			case *EventVote:
+				event := ctx.(*EventVote)
+				if config.ProposalWindow == 0 || number <= e.start {
+					break
+				}
+				_, approved := tallyApproved(config, snap, event.ID)
+				if err := vote(header, snap, event); err == nil {
+					headerExtra.CurrentBlockVotes = append(headerExtra.CurrentBlockVotes, Vote{ID: event.ID, Validator: event.Validator, Approve: event.Approve})
+					logProposalVoted(state, header, headerExtra.CurrentBlockVotes[len(headerExtra.CurrentBlockVotes)-1])
+					if tally, ok := tallyApproved(config, snap, event.ID); ok && !approved {
+						logQuorumReached(state, header, event.ID, tally)
+					}
+					if err := e.executeHalt(config, state, header, snap, headerExtra, event.ID); err != nil {
+						panic(err)
 					}
+				} else {
+					log.Debug("[equality] Vote rejected", "validator", event.Validator, "proposal", event.ID, "reason", err)
+				}
+				count++
+			case *EventHalt:
+				event := ctx.(*EventHalt)
+				if config.ProposalWindow == 0 || number <= e.start {
+					break
+				}
+				if proposal, err := proposeHalt(config, state, header, snap, event); err == nil {
+					headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, *proposal)
+					logProposalSubmitted(state, header, proposal)
+				} else {
+					log.Debug("[equality] Halt proposal rejected", "proposer", event.Proposer, "proposal", event.ID, "reason", err)
+				}
+				count++
+			case *EventFork:
+				event := ctx.(*EventFork)
+				if config.ProposalWindow == 0 || number <= e.start {
+					break
+				}
+				if proposal, err := proposeFork(config, state, header, snap, event); err == nil {
+					headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, *proposal)
+					logProposalSubmitted(state, header, proposal)
+				} else {
+					log.Debug("[equality] Fork proposal rejected", "proposer", event.Proposer, "proposal", event.ID, "reason", err)
+				}
+				count++
+			case *EventSignal:
+				event := ctx.(*EventSignal)
+				if config.ProposalWindow == 0 || number <= e.start {
+					break
+				}
+				if proposal, err := proposeSignal(config, state, header, snap, event); err == nil {
+					headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, *proposal)
+					logProposalSubmitted(state, header, proposal)
+				} else {
+					log.Debug("[equality] Signal proposal rejected", "proposer", event.Proposer, "proposal", event.ID, "reason", err)
+				}
+				count++
+			case *EventSpend:
+				event := ctx.(*EventSpend)
+				if config.ProposalWindow == 0 || number <= e.start {
+					break
+				}
+				if proposal, err := proposeSpend(config, state, header, snap, event); err == nil {
+					headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, *proposal)
+					logProposalSubmitted(state, header, proposal)
+				} else {
+					log.Debug("[equality] Spending proposal rejected", "proposer", event.Proposer, "proposal", event.ID, "reason", err)
 				}
 				count++
 			}
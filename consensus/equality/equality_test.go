@@ -1,15 +1,20 @@
 package equality
 
 import (
+	"math"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/SecretBlockChain/go-secret/accounts"
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/crypto"
 	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
 )
 
 var (
@@ -36,3 +41,186 @@ func TestNewEquality(t *testing.T) {
 		return crypto.Sign(crypto.Keccak256(data), testUserKey)
 	})
 }
+
+// mintTestBlock mints a block on top of the chain signed by the test user,
+// finalizing it over the given state.
+func mintTestBlock(t *testing.T, sealer *Equality, chain *testChainReader, statedb *state.StateDB, txs []*types.Transaction) *types.Header {
+	parent := chain.headers[len(chain.headers)-1]
+	header := &types.Header{
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		ParentHash: parent.Hash(),
+		Coinbase:   testUserAddress,
+	}
+	assert.Nil(t, sealer.Prepare(chain, header))
+	block, err := sealer.FinalizeAndAssemble(chain, header, statedb, txs, nil, nil)
+	assert.Nil(t, err)
+
+	header = block.Header()
+	signature, err := crypto.Sign(SealHash(header).Bytes(), testUserKey)
+	assert.Nil(t, err)
+	copy(header.Extra[len(header.Extra)-extraSeal:], signature)
+	chain.headers = append(chain.headers, header)
+	return header
+}
+
+func TestRotateSignerKey(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	newKey, _ := crypto.GenerateKey()
+	newSigner := crypto.PubkeyToAddress(newKey.PublicKey)
+
+	rotate := types.NewTransaction(0, testUserAddress, new(big.Int), 0, new(big.Int), EncodeTransaction(&EventBindSigner{Key: newSigner}))
+	rotate, err := types.SignTx(rotate, types.NewEIP155Signer(big.NewInt(1)), testUserKey)
+	assert.Nil(t, err)
+
+	mint := func(txs []*types.Transaction) *HeaderExtra {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		headerExtra, err := DecodeHeaderExtra(mintTestBlock(t, sealer, chain, statedb, txs))
+		assert.Nil(t, err)
+		return &headerExtra
+	}
+
+	// Keys aren't bound before the fork
+	headerExtra := mint([]*types.Transaction{rotate})
+	assert.Empty(t, headerExtra.CurrentBlockSignerKeys)
+	headerExtra = mint(nil)
+	assert.Empty(t, headerExtra.CurrentEpochSignerKeys)
+
+	// The key is bound at once but seals from the next epoch only
+	chainConfig := *params.TestChainConfig
+	chainConfig.SignerKeyBlock = new(big.Int).Add(chain.headers[len(chain.headers)-1].Number, common.Big1)
+	chain.config = &chainConfig
+	headerExtra = mint([]*types.Transaction{rotate})
+	assert.Equal(t, []SignerKey{{Validator: testUserAddress, Key: newSigner}}, headerExtra.CurrentBlockSignerKeys)
+	assert.Empty(t, headerExtra.CurrentEpochSignerKeys)
+	blockTime := chain.headers[len(chain.headers)-1].Time + config.Period
+	assert.True(t, sealer.inTurn(config, chain.headers[len(chain.headers)-1], blockTime, testUserAddress))
+
+	headerExtra = mint(nil)
+	head := chain.headers[len(chain.headers)-1]
+	assert.Equal(t, head.Number.Uint64(), headerExtra.EpochBlock)
+	assert.Equal(t, []SignerKey{{Validator: testUserAddress, Key: newSigner}}, headerExtra.CurrentEpochSignerKeys)
+	assert.Equal(t, []common.Address{testUserAddress}, headerExtra.CurrentEpochValidators)
+	assert.True(t, sealer.inTurn(config, head, head.Time+config.Period, newSigner))
+	assert.False(t, sealer.inTurn(config, head, head.Time+config.Period, testUserAddress))
+
+	// The bindings are replayed from the headers
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	assert.Nil(t, engine.EnsureSnapshot(chain, head))
+	assert.True(t, engine.snapshotAvailable(headerExtra.Root))
+}
+
+func TestRewardPayout(t *testing.T) {
+	config := testSnapshotConfig()
+	config.Rewards = params.EqualityRewards{{Number: math.MaxUint64, Reward: big.NewInt(10)}}
+	sealer, chain := makeSnapshotChain(t, &config)
+	recipient := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+
+	payout := types.NewTransaction(0, testUserAddress, new(big.Int), 0, new(big.Int), EncodeTransaction(&EventSetPayout{Recipient: recipient}))
+	payout, err := types.SignTx(payout, types.NewEIP155Signer(big.NewInt(1)), testUserKey)
+	assert.Nil(t, err)
+
+	// Payouts aren't set before the fork
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header := mintTestBlock(t, sealer, chain, statedb, []*types.Transaction{payout})
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Empty(t, headerExtra.CurrentBlockPayouts)
+	assert.Equal(t, big.NewInt(1), statedb.GetBalance(testUserAddress))
+
+	// The rewards of the block setting the payout still go to the coinbase
+	chainConfig := *params.TestChainConfig
+	chainConfig.PayoutBlock = new(big.Int).Add(header.Number, common.Big1)
+	chain.config = &chainConfig
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, []*types.Transaction{payout})
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, []Payout{{Candidate: testUserAddress, Recipient: recipient}}, headerExtra.CurrentBlockPayouts)
+	assert.Equal(t, common.Address{}, headerExtra.RewardRecipient)
+	assert.Equal(t, big.NewInt(1), statedb.GetBalance(testUserAddress))
+
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, nil)
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, recipient, headerExtra.RewardRecipient)
+	assert.Equal(t, big.NewInt(1), statedb.GetBalance(recipient))
+	assert.Equal(t, new(big.Int), statedb.GetBalance(testUserAddress))
+
+	// Verifying nodes credit the same recipient
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	sealer.Finalize(chain, types.CopyHeader(header), statedb, nil, nil)
+	assert.Equal(t, big.NewInt(1), statedb.GetBalance(recipient))
+
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	assert.Nil(t, engine.EnsureSnapshot(chain, header))
+	assert.True(t, engine.snapshotAvailable(headerExtra.Root))
+}
+
+func TestBaseFeeToPool(t *testing.T) {
+	config := testSnapshotConfig()
+	config.Pool = common.HexToAddress("0x47746e8acb5dafe9c00b7195d0c2d830fcc04910")
+	header := &types.Header{Number: big.NewInt(10), GasUsed: 21000, BaseFee: big.NewInt(7)}
+
+	// The base fee of the block goes to the pool unless burned
+	for _, burn := range []bool{false, true} {
+		config.BurnBaseFee = burn
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		transfers := New(&config, rawdb.NewMemoryDatabase()).accumulateRewards(config, statedb, header, testUserAddress)
+
+		want := uint64(21000 * 7)
+		if burn {
+			want = 0
+			assert.Empty(t, transfers)
+		} else {
+			assert.Equal(t, []RewardTransfer{{Type: RewardTypeBaseFee, To: config.Pool, Value: (*hexutil.Big)(new(big.Int).SetUint64(want))}}, transfers)
+		}
+		assert.Equal(t, want, statedb.GetBalance(config.Pool).Uint64())
+		assert.Equal(t, uint64(0), statedb.GetBalance(testUserAddress).Uint64())
+	}
+
+	// The parameters in effect at the block decide, not the ones the engine started with
+	engine := config
+	engine.BurnBaseFee = true
+	config.BurnBaseFee = false
+	transfers := New(&engine, rawdb.NewMemoryDatabase()).rewardTransfers(config, header, testUserAddress)
+	assert.Equal(t, []RewardTransfer{{Type: RewardTypeBaseFee, To: config.Pool, Value: (*hexutil.Big)(big.NewInt(21000 * 7))}}, transfers)
+
+	// Headers past the London fork must carry the base fee
+	config.BurnBaseFee = false
+	sealer, chain := makeSnapshotChain(t, &config)
+	london := *params.TestChainConfig
+	london.LondonBlock = new(big.Int).Add(chain.headers[len(chain.headers)-1].Number, common.Big1)
+	chain.config = &london
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, nil)
+	assert.NotNil(t, sealer.VerifyHeader(chain, header, true))
+}
+
+func TestOverridePool(t *testing.T) {
+	config := testSnapshotConfig()
+	config.Pool = common.HexToAddress("0x47746e8acb5dafe9c00b7195d0c2d830fcc04910")
+	config.Rewards = params.EqualityRewards{{Number: math.MaxUint64, Reward: big.NewInt(10)}}
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	pool := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	next := chain.headers[len(chain.headers)-1].Number.Uint64() + 1
+	config.Overrides = []params.EqualityOverride{{Block: next, Pool: &pool}}
+
+	// The override block records the new pool, still rewarding the old one
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	headerExtra, err := DecodeHeaderExtra(mintTestBlock(t, sealer, chain, statedb, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(headerExtra.ChainConfig))
+	assert.Equal(t, pool, headerExtra.ChainConfig[0].Pool)
+	assert.Equal(t, big.NewInt(9), statedb.GetBalance(config.Pool))
+	assert.Equal(t, new(big.Int), statedb.GetBalance(pool))
+
+	// Its descendants reward the new pool
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	mintTestBlock(t, sealer, chain, statedb, nil)
+	assert.Equal(t, new(big.Int), statedb.GetBalance(config.Pool))
+	assert.Equal(t, big.NewInt(9), statedb.GetBalance(pool))
+}
@@ -0,0 +1,94 @@
+package equality
+
+import (
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/event"
+)
+
+// EpochStartedEvent is posted when the first block of an epoch is included in
+// the canonical chain.
+type EpochStartedEvent struct {
+	Epoch  uint64
+	Number uint64
+	Hash   common.Hash
+}
+
+// ValidatorElectedEvent is posted for each validator elected by the first block
+// of an epoch included in the canonical chain.
+type ValidatorElectedEvent struct {
+	Validator common.Address
+	Epoch     uint64
+	Number    uint64
+	Hash      common.Hash
+}
+
+// CandidateKickedEvent is posted for each candidate kicked out by a block
+// included in the canonical chain.
+type CandidateKickedEvent struct {
+	Candidate common.Address
+	Epoch     uint64
+	Number    uint64
+	Hash      common.Hash
+}
+
+// BlockSealedEvent is posted when the local signer sealed a block, once the
+// block is handed over to the miner.
+type BlockSealedEvent struct {
+	Signer common.Address
+	Number uint64
+	Hash   common.Hash
+}
+
+// eventFeeds are the feeds of the consensus events posted by the engine.
+type eventFeeds struct {
+	epochStarted     event.Feed
+	validatorElected event.Feed
+	candidateKicked  event.Feed
+	blockSealed      event.Feed
+	scope            event.SubscriptionScope
+}
+
+// SubscribeEpochStarted registers a subscription of EpochStartedEvent.
+func (e *Equality) SubscribeEpochStarted(ch chan<- EpochStartedEvent) event.Subscription {
+	return e.events.scope.Track(e.events.epochStarted.Subscribe(ch))
+}
+
+// SubscribeValidatorElected registers a subscription of ValidatorElectedEvent.
+func (e *Equality) SubscribeValidatorElected(ch chan<- ValidatorElectedEvent) event.Subscription {
+	return e.events.scope.Track(e.events.validatorElected.Subscribe(ch))
+}
+
+// SubscribeCandidateKicked registers a subscription of CandidateKickedEvent.
+func (e *Equality) SubscribeCandidateKicked(ch chan<- CandidateKickedEvent) event.Subscription {
+	return e.events.scope.Track(e.events.candidateKicked.Subscribe(ch))
+}
+
+// SubscribeBlockSealed registers a subscription of BlockSealedEvent.
+func (e *Equality) SubscribeBlockSealed(ch chan<- BlockSealedEvent) event.Subscription {
+	return e.events.scope.Track(e.events.blockSealed.Subscribe(ch))
+}
+
+// PublishChainEvent posts the consensus events carried by a block included in
+// the canonical chain. The node calls it for every canonical block, the engine
+// not knowing which of the blocks it verifies end up canonical.
+func (e *Equality) PublishChainEvent(header *types.Header) {
+	number := header.Number.Uint64()
+	if number < e.start {
+		return
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return
+	}
+	hash := header.Hash()
+	if number == headerExtra.EpochBlock {
+		e.events.epochStarted.Send(EpochStartedEvent{Epoch: headerExtra.Epoch, Number: number, Hash: hash})
+		for _, validator := range headerExtra.CurrentEpochValidators {
+			e.events.validatorElected.Send(ValidatorElectedEvent{Validator: validator, Epoch: headerExtra.Epoch, Number: number, Hash: hash})
+		}
+	}
+	for _, candidate := range headerExtra.CurrentBlockKickOutCandidates {
+		e.events.candidateKicked.Send(CandidateKickedEvent{Candidate: candidate, Epoch: headerExtra.Epoch, Number: number, Hash: hash})
+	}
+}
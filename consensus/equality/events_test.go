@@ -0,0 +1,39 @@
+package equality
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishChainEvent(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	started := make(chan EpochStartedEvent, 1)
+	elected := make(chan ValidatorElectedEvent, 1)
+	startedSub := sealer.SubscribeEpochStarted(started)
+	electedSub := sealer.SubscribeValidatorElected(elected)
+
+	// Blocks within an epoch carry no event
+	sealer.PublishChainEvent(chain.headers[2])
+	assert.Empty(t, started)
+	assert.Empty(t, elected)
+
+	epoch := chain.headers[3]
+	headerExtra, err := DecodeHeaderExtra(epoch)
+	assert.Nil(t, err)
+	sealer.PublishChainEvent(epoch)
+
+	ev := <-started
+	assert.Equal(t, headerExtra.Epoch, ev.Epoch)
+	assert.Equal(t, epoch.Hash(), ev.Hash)
+	assert.Equal(t, testUserAddress, (<-elected).Validator)
+
+	// Closing the engine ends the subscriptions
+	assert.Nil(t, sealer.Close())
+	_, ok := <-startedSub.Err()
+	assert.False(t, ok)
+	_, ok = <-electedSub.Err()
+	assert.False(t, ok)
+}
@@ -0,0 +1,267 @@
+package equality
+
+import (
+	"math"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rlp"
+	"github.com/SecretBlockChain/go-secret/rpc"
+)
+
+// Statuses of the governance proposals reported over RPC.
+const (
+	proposalPending  = "pending"  // Open to the votes of the validators
+	proposalExecuted = "executed" // Approved and executed
+	proposalExpired  = "expired"  // Dropped unapproved past its deadline
+)
+
+type rpcProposal struct {
+	ID        common.Hash            `json:"id"`
+	Kind      string                 `json:"kind"` // config, halt, fork, signal or spend
+	Proposer  common.Address         `json:"proposer"`
+	Config    *params.EqualityConfig `json:"config,omitempty"`
+	Halt      uint64                 `json:"halt,omitempty"`
+	Fork      string                 `json:"fork,omitempty"`
+	ForkBlock uint64                 `json:"forkBlock,omitempty"`
+	Signal    *common.Hash           `json:"signal,omitempty"`
+	Recipient *common.Address        `json:"recipient,omitempty"`
+	Amount    *hexutil.Big           `json:"amount,omitempty"`
+	Deposit   *hexutil.Big           `json:"deposit"`
+	Number    uint64                 `json:"number"`
+	Deadline  uint64                 `json:"deadline"`
+	Status    string                 `json:"status"`
+	Closed    uint64                 `json:"closed,omitempty"` // Block the proposal was executed or expired in
+	Tally     ProposalTally          `json:"tally"`
+}
+
+type rpcVote struct {
+	Validator common.Address `json:"validator"`
+	Voted     bool           `json:"voted"`
+	Approve   bool           `json:"approve"`
+}
+
+// newRPCProposal converts a proposal of the snapshot to its RPC representation.
+func newRPCProposal(id common.Hash, proposal *ProposalRecord, tally ProposalTally) rpcProposal {
+	result := rpcProposal{
+		ID:        id,
+		Kind:      "config",
+		Proposer:  proposal.Proposer,
+		Halt:      proposal.Halt,
+		Fork:      proposal.Fork,
+		ForkBlock: proposal.ForkBlock,
+		Deposit:   (*hexutil.Big)(proposal.Deposit),
+		Number:    proposal.Number,
+		Deadline:  proposal.Deadline,
+		Status:    proposalPending,
+		Closed:    proposal.Executed,
+		Tally:     tally,
+	}
+	switch {
+	case proposal.Halt != 0:
+		result.Kind = "halt"
+	case proposal.Fork != "":
+		result.Kind = "fork"
+	case proposal.spending():
+		recipient := proposal.Recipient
+		result.Kind, result.Recipient, result.Amount = "spend", &recipient, (*hexutil.Big)(proposal.Amount)
+	case proposal.Signal != (common.Hash{}):
+		signal := proposal.Signal
+		result.Kind, result.Signal = "signal", &signal
+	default:
+		config := proposal.Config
+		result.Config = &config
+	}
+	if result.Deposit == nil {
+		result.Deposit = new(hexutil.Big)
+	}
+	if proposal.Executed != 0 {
+		result.Status = proposalExecuted
+	}
+	return result
+}
+
+// GovernanceAPI is a user facing RPC API to follow the proposals submitted to
+// the validators of the proof-of-equality scheme and their votes.
+type GovernanceAPI struct {
+	api *API
+}
+
+// proposalSnapshot loads the snapshot at specified block along with the config
+// and the validators the proposals are tallied with.
+func (api *GovernanceAPI) proposalSnapshot(number *rpc.BlockNumber) (*Snapshot, params.EqualityConfig, []common.Address, error) {
+	header := api.api.header(number)
+	if header == nil {
+		return nil, params.EqualityConfig{}, nil, errUnknownBlock
+	}
+	snap, _, err := api.api.loadSnapshot(number)
+	if err != nil {
+		return nil, params.EqualityConfig{}, nil, err
+	}
+	config, err := api.api.equality.chainConfig(header)
+	if err != nil {
+		return nil, params.EqualityConfig{}, nil, err
+	}
+	validators, err := snap.GetValidators()
+	if err != nil {
+		return nil, params.EqualityConfig{}, nil, err
+	}
+	return snap, config, validators, nil
+}
+
+// GetProposals retrieves the proposals pending at specified block, in the order
+// they were submitted, along with their tally so far.
+func (api *GovernanceAPI) GetProposals(number *rpc.BlockNumber) ([]rpcProposal, error) {
+	snap, config, validators, err := api.proposalSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := snap.GetPendingProposals()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]rpcProposal, 0, len(ids))
+	for _, id := range ids {
+		proposal, err := snap.GetProposal(id)
+		if err != nil {
+			return nil, err
+		}
+		if proposal == nil {
+			continue
+		}
+		tally, err := snap.TallyProposal(config, id, validators)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, newRPCProposal(id, proposal, tally))
+	}
+	return result, nil
+}
+
+// GetProposal retrieves a proposal at specified block. The proposals pruned from
+// the snapshot are looked up in the history of the closed proposals.
+func (api *GovernanceAPI) GetProposal(id common.Hash, number *rpc.BlockNumber) (rpcProposal, error) {
+	snap, config, validators, err := api.proposalSnapshot(number)
+	if err != nil {
+		return rpcProposal{}, err
+	}
+	proposal, err := snap.GetProposal(id)
+	if err != nil {
+		return rpcProposal{}, err
+	}
+	if proposal != nil && proposal.Executed == 0 {
+		tally, err := snap.TallyProposal(config, id, validators)
+		if err != nil {
+			return rpcProposal{}, err
+		}
+		return newRPCProposal(id, proposal, tally), nil
+	}
+	// The votes on closed proposals are pruned, report the tally of the history
+	header := api.api.header(number)
+	var found *rpcProposal
+	for _, outcome := range api.history(0, header.Number.Uint64()) {
+		if outcome.ID == id {
+			outcome := outcome
+			found = &outcome
+		}
+	}
+	switch {
+	case found != nil:
+		return *found, nil
+	case proposal != nil:
+		return newRPCProposal(id, proposal, ProposalTally{}), nil
+	}
+	return rpcProposal{}, errUnknownProposal
+}
+
+// GetTally retrieves the votes counted so far at specified block on a pending
+// proposal, along with the approvals required to execute it.
+func (api *GovernanceAPI) GetTally(id common.Hash, number *rpc.BlockNumber) (ProposalTally, error) {
+	snap, config, validators, err := api.proposalSnapshot(number)
+	if err != nil {
+		return ProposalTally{}, err
+	}
+	proposal, err := snap.GetProposal(id)
+	if err != nil {
+		return ProposalTally{}, err
+	}
+	if proposal == nil || proposal.Executed != 0 {
+		return ProposalTally{}, errUnknownProposal
+	}
+	return snap.TallyProposal(config, id, validators)
+}
+
+// GetVotes retrieves the votes of the validators at specified block on a pending
+// proposal, the validators which didn't vote included. The votes are pruned once
+// the proposal is closed.
+func (api *GovernanceAPI) GetVotes(id common.Hash, number *rpc.BlockNumber) ([]rpcVote, error) {
+	snap, _, validators, err := api.proposalSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := snap.GetProposal(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil || proposal.Executed != 0 {
+		return nil, errUnknownProposal
+	}
+	votes := make([]rpcVote, 0, len(validators))
+	for _, validator := range validators {
+		voted, approve, err := snap.GetVote(id, validator)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, rpcVote{Validator: validator, Voted: voted, Approve: approve})
+	}
+	return votes, nil
+}
+
+// GetHistory retrieves the outcomes of the proposals executed or expired on the
+// canonical chain within the specified range of blocks, the whole chain if not
+// specified, along with their final tally. Only the blocks fully verified by the
+// node are indexed.
+func (api *GovernanceAPI) GetHistory(from, to *rpc.BlockNumber) ([]rpcProposal, error) {
+	first, last := uint64(0), uint64(math.MaxUint64)
+	if from != nil && *from != rpc.LatestBlockNumber {
+		first = uint64(from.Int64())
+	}
+	if to != nil && *to != rpc.LatestBlockNumber {
+		last = uint64(to.Int64())
+	}
+	result := api.history(first, last)
+	if result == nil {
+		result = make([]rpcProposal, 0)
+	}
+	return result, nil
+}
+
+// history retrieves the outcomes of the proposals closed on the canonical chain
+// within the range of blocks, ordered by block number.
+func (api *GovernanceAPI) history(from, to uint64) []rpcProposal {
+	var result []rpcProposal
+	for _, outcome := range rawdb.ReadProposalOutcomes(api.api.equality.db, from, to) {
+		proposal := new(ProposalRecord)
+		if err := rlp.DecodeBytes(outcome.Record, proposal); err != nil {
+			continue
+		}
+		if !canonicalProposalOutcome(api.api.chain, outcome, proposal) {
+			continue
+		}
+		entry := newRPCProposal(outcome.ID, proposal, ProposalTally{
+			Approvals:  int(outcome.Approvals),
+			Rejections: int(outcome.Rejections),
+			Validators: int(outcome.Validators),
+			Quorum:     int(outcome.Quorum),
+		})
+		entry.Closed = outcome.Number
+		entry.Status = proposalExecuted
+		if !outcome.Executed {
+			entry.Status = proposalExpired
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package equality
+
+import (
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGovernanceAPI(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 1
+	sealer, chain := makeSnapshotChain(t, &config)
+	api := &GovernanceAPI{api: &API{chain: chain, equality: sealer}}
+
+	mint := func(txs []*types.Transaction) {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		recordTestHistory(t, sealer, chain, mintTestBlock(t, sealer, chain, statedb, txs))
+	}
+	proposed := config
+	proposed.MaxValidatorsCount = 7
+	approved := signTestEvent(t, 0, &EventPropose{Config: proposed})
+	expired := signTestEvent(t, 1, &EventHalt{Height: 100})
+	mint([]*types.Transaction{
+		approved,
+		expired,
+		signTestEvent(t, 2, &EventVote{ID: approved.Hash(), Approve: true}),
+		signTestEvent(t, 3, &EventVote{ID: expired.Hash(), Approve: false}),
+	})
+
+	// Pending proposals are reported with their tally and votes so far
+	proposals, err := api.GetProposals(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(proposals))
+	assert.Equal(t, approved.Hash(), proposals[0].ID)
+	assert.Equal(t, "config", proposals[0].Kind)
+	assert.Equal(t, uint64(7), proposals[0].Config.MaxValidatorsCount)
+	assert.Equal(t, ProposalTally{Approvals: 1, Validators: 1, Quorum: 1}, proposals[0].Tally)
+	assert.Equal(t, "halt", proposals[1].Kind)
+	assert.Equal(t, proposalPending, proposals[1].Status)
+
+	tally, err := api.GetTally(expired.Hash(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ProposalTally{Rejections: 1, Validators: 1, Quorum: 1}, tally)
+	votes, err := api.GetVotes(expired.Hash(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []rpcVote{{Validator: testUserAddress, Voted: true}}, votes)
+
+	// Closed proposals are reported from the history with their final tally
+	for i := 0; i < 3; i++ {
+		mint(nil)
+	}
+	proposals, err = api.GetProposals(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, proposals)
+	_, err = api.GetVotes(approved.Hash(), nil)
+	assert.Equal(t, errUnknownProposal, err)
+
+	history, err := api.GetHistory(nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, approved.Hash(), history[0].ID)
+	assert.Equal(t, proposalExecuted, history[0].Status)
+	assert.Equal(t, uint64(7), history[0].Closed)
+	assert.Equal(t, 1, history[0].Tally.Approvals)
+	assert.Equal(t, expired.Hash(), history[1].ID)
+	assert.Equal(t, proposalExpired, history[1].Status)
+	assert.Equal(t, uint64(9), history[1].Closed)
+	assert.Equal(t, 1, history[1].Tally.Rejections)
+
+	proposal, err := api.GetProposal(expired.Hash(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, proposalExpired, proposal.Status)
+
+	// Before the expiry, the proposal is still pending
+	number := rpc.BlockNumber(8)
+	proposal, err = api.GetProposal(expired.Hash(), &number)
+	assert.Nil(t, err)
+	assert.Equal(t, proposalPending, proposal.Status)
+
+	_, err = api.GetProposal(common.Hash{0x01}, nil)
+	assert.Equal(t, errUnknownProposal, err)
+}
@@ -0,0 +1,948 @@
+package equality
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rlp"
+	"github.com/SecretBlockChain/go-secret/trie"
+)
+
+// The governance state is kept in the config trie along with the chain config:
+//   proposals:                       {[]proposalID}, pending in submission order
+//   executed-proposals:              {[]proposalID}, executed in execution order
+//   proposal-{proposalID}:           {ProposalRecord}
+//   vote-{proposalID}{validator}:    {1 if approving, 0 if rejecting}
+//   halt:                            {height after which no block is minted}
+//   fork-{name}:                     {activation block of the fork}
+
+var (
+	// errUnknownProposal is returned when voting on a proposal which doesn't exist
+	// or isn't pending anymore.
+	errUnknownProposal = errors.New("unknown proposal")
+
+	// errChainHalted is returned when minting or importing a block past the halt
+	// height approved by the validators.
+	errChainHalted = errors.New("chain halted by the validators")
+
+	// errInvalidFork is returned when proposing a fork which can't be scheduled.
+	errInvalidFork = errors.New("invalid fork")
+
+	// errInsufficientDeposit is returned when the proposer can't afford the
+	// deposit locked by a proposal.
+	errInsufficientDeposit = errors.New("insufficient balance for the proposal deposit")
+
+	// errNoPool is returned when proposing to spend from the pool of a network
+	// without pool.
+	errNoPool = errors.New("no pool to spend from")
+
+	// errSlotScheduleChange is returned if a config proposal changes the period
+	// or the epoch length, which light clients and warp sync can't follow.
+	errSlotScheduleChange = errors.New("proposals can't change the period or the epoch length")
+)
+
+// ProposalRecord is a config, halt, fork, signal or spending proposal as stored
+// in the snapshot.
+type ProposalRecord struct {
+	Proposer  common.Address        `json:"proposer"`
+	Config    params.EqualityConfig `json:"config"`
+	Halt      uint64                `json:"halt,omitempty"`      // Height after which no block is minted, 0 for other proposals
+	Fork      string                `json:"fork,omitempty"`      // Protocol fork scheduled, empty for other proposals
+	ForkBlock uint64                `json:"forkBlock,omitempty"` // Activation block of the fork
+	Signal    common.Hash           `json:"signal,omitempty"`    // Hash of the text of a signal proposal, empty for other proposals
+	Recipient common.Address        `json:"recipient,omitempty"` // Recipient of a spending of the pool
+	Amount    *big.Int              `json:"amount,omitempty"`    // Amount spent from the pool, nil for other proposals
+	Deposit   *big.Int              `json:"deposit"`             // Locked until approved by the quorum, burned otherwise
+	Number    uint64                `json:"number"`              // Block the proposal was submitted in
+	Deadline  uint64                `json:"deadline"`            // Last block the validators may vote in
+	Executed  uint64                `json:"executed"`            // Block the proposal was executed in, 0 while pending
+}
+
+// spending returns whether the proposal transfers an amount from the pool.
+func (proposal *ProposalRecord) spending() bool {
+	return proposal.Amount != nil && proposal.Amount.Sign() > 0
+}
+
+// proposalKey returns the config trie key of a proposal.
+func proposalKey(id common.Hash) []byte {
+	return append([]byte("proposal-"), id.Bytes()...)
+}
+
+// voteKey returns the config trie key of the vote of a validator on a proposal.
+func voteKey(id common.Hash, validator common.Address) []byte {
+	return append(append([]byte("vote-"), id.Bytes()...), validator.Bytes()...)
+}
+
+// GetPendingProposals returns the proposals not activated yet, in the order
+// they were submitted.
+func (snap *Snapshot) GetPendingProposals() ([]common.Hash, error) {
+	return snap.getProposalList([]byte("proposals"))
+}
+
+// setPendingProposals writes the proposals not activated yet to snapshot.
+func (snap *Snapshot) setPendingProposals(ids []common.Hash) error {
+	return snap.setProposalList([]byte("proposals"), ids)
+}
+
+// GetExecutedProposals returns the executed proposals whose records are still
+// kept, in the order they were executed.
+func (snap *Snapshot) GetExecutedProposals() ([]common.Hash, error) {
+	return snap.getProposalList([]byte("executed-proposals"))
+}
+
+// setExecutedProposals writes the executed proposals still kept to snapshot.
+func (snap *Snapshot) setExecutedProposals(ids []common.Hash) error {
+	return snap.setProposalList([]byte("executed-proposals"), ids)
+}
+
+// getProposalList returns the proposal IDs stored under the given key.
+func (snap *Snapshot) getProposalList(key []byte) ([]common.Hash, error) {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return nil, err
+	}
+	data, err := configTrie.TryGet(key)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	var ids []common.Hash
+	if err := rlp.DecodeBytes(data, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// setProposalList writes the proposal IDs under the given key, deleting the key
+// of an empty list.
+func (snap *Snapshot) setProposalList(key []byte, ids []common.Hash) error {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return configTrie.TryDelete(key)
+	}
+	data, err := rlp.EncodeToBytes(ids)
+	if err != nil {
+		return err
+	}
+	return configTrie.TryUpdate(key, data)
+}
+
+// GetProposal returns the proposal with the given ID, nil if there is none.
+func (snap *Snapshot) GetProposal(id common.Hash) (*ProposalRecord, error) {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return nil, err
+	}
+	data, err := configTrie.TryGet(proposalKey(id))
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	proposal := new(ProposalRecord)
+	if err := rlp.DecodeBytes(data, proposal); err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+// setProposal writes the proposal to snapshot.
+func (snap *Snapshot) setProposal(id common.Hash, proposal *ProposalRecord) error {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return err
+	}
+	data, err := rlp.EncodeToBytes(proposal)
+	if err != nil {
+		return err
+	}
+	return configTrie.TryUpdate(proposalKey(id), data)
+}
+
+// Propose records a config proposal submitted in the given block along with its
+// deposit, pending until the validators approve it.
+func (snap *Snapshot) Propose(id common.Hash, proposer common.Address, config params.EqualityConfig, number, deadline uint64, deposit *big.Int) error {
+	config.Overrides = nil
+	return snap.addProposal(id, &ProposalRecord{Proposer: proposer, Config: config, Number: number, Deadline: deadline, Deposit: deposit})
+}
+
+// ProposeHalt records a proposal to halt the chain after the given height,
+// submitted in the given block and pending until the validators approve it.
+func (snap *Snapshot) ProposeHalt(id common.Hash, proposer common.Address, height, number, deadline uint64, deposit *big.Int) error {
+	return snap.addProposal(id, &ProposalRecord{Proposer: proposer, Halt: height, Number: number, Deadline: deadline, Deposit: deposit})
+}
+
+// ProposeFork records a proposal to schedule the protocol fork at the given
+// block, submitted in the given block and pending until the validators approve
+// it.
+func (snap *Snapshot) ProposeFork(id common.Hash, proposer common.Address, fork string, block, number, deadline uint64, deposit *big.Int) error {
+	return snap.addProposal(id, &ProposalRecord{Proposer: proposer, Fork: fork, ForkBlock: block, Number: number, Deadline: deadline, Deposit: deposit})
+}
+
+// ProposeSignal records a non-binding proposal on the text of the given hash,
+// submitted in the given block and pending until the validators approve it.
+func (snap *Snapshot) ProposeSignal(id common.Hash, proposer common.Address, text common.Hash, number, deadline uint64, deposit *big.Int) error {
+	return snap.addProposal(id, &ProposalRecord{Proposer: proposer, Signal: text, Number: number, Deadline: deadline, Deposit: deposit})
+}
+
+// ProposeSpend records a proposal to transfer the amount from the pool to the
+// recipient, submitted in the given block and pending until the validators
+// approve it.
+func (snap *Snapshot) ProposeSpend(id common.Hash, proposer, recipient common.Address, amount *big.Int, number, deadline uint64, deposit *big.Int) error {
+	return snap.addProposal(id, &ProposalRecord{Proposer: proposer, Recipient: recipient, Amount: amount, Number: number, Deadline: deadline, Deposit: deposit})
+}
+
+// addProposal writes the proposal to snapshot and appends it to the pending ones.
+func (snap *Snapshot) addProposal(id common.Hash, proposal *ProposalRecord) error {
+	if err := snap.setProposal(id, proposal); err != nil {
+		return err
+	}
+	ids, err := snap.GetPendingProposals()
+	if err != nil {
+		return err
+	}
+	return snap.setPendingProposals(append(ids, id))
+}
+
+// Vote records the vote of a validator on a pending proposal, replacing its
+// previous vote.
+func (snap *Snapshot) Vote(id common.Hash, validator common.Address, approve bool) error {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return err
+	}
+	vote := []byte{0}
+	if approve {
+		vote[0] = 1
+	}
+	return configTrie.TryUpdate(voteKey(id, validator), vote)
+}
+
+// GetVote returns the vote of a validator on a proposal, voted being false if
+// the validator didn't vote.
+func (snap *Snapshot) GetVote(id common.Hash, validator common.Address) (voted bool, approve bool, err error) {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return false, false, err
+	}
+	vote, err := configTrie.TryGet(voteKey(id, validator))
+	if err != nil || len(vote) == 0 {
+		return false, false, err
+	}
+	return true, vote[0] == 1, nil
+}
+
+// ExecuteProposal records the activation of a proposal in the given block,
+// moving it from the pending to the executed ones. Its votes are dropped, its
+// record is kept until PruneProposals passes its deadline. Halt proposals set
+// the halt height, no earlier than the block, fork proposals the activation
+// block of the fork.
+func (snap *Snapshot) ExecuteProposal(id common.Hash, number uint64) error {
+	proposal, err := snap.GetProposal(id)
+	if err != nil {
+		return err
+	}
+	if proposal == nil {
+		return errUnknownProposal
+	}
+	proposal.Executed = number
+	if err := snap.setProposal(id, proposal); err != nil {
+		return err
+	}
+	if proposal.Halt != 0 {
+		height := proposal.Halt
+		if height < number {
+			height = number
+		}
+		if err := snap.setHaltHeight(height); err != nil {
+			return err
+		}
+	}
+	if proposal.Fork != "" {
+		if err := snap.setForkBlock(proposal.Fork, proposal.ForkBlock); err != nil {
+			return err
+		}
+	}
+	if err := snap.deleteVotes(id); err != nil {
+		return err
+	}
+	ids, err := snap.GetPendingProposals()
+	if err != nil {
+		return err
+	}
+	pending := make([]common.Hash, 0, len(ids))
+	for _, pendingID := range ids {
+		if pendingID != id {
+			pending = append(pending, pendingID)
+		}
+	}
+	if err := snap.setPendingProposals(pending); err != nil {
+		return err
+	}
+	executed, err := snap.GetExecutedProposals()
+	if err != nil {
+		return err
+	}
+	return snap.setExecutedProposals(append(executed, id))
+}
+
+// RefundProposal clears the deposit of an approved proposal left pending, its
+// proposer getting it back before the proposal is executed or expires.
+func (snap *Snapshot) RefundProposal(id common.Hash) error {
+	proposal, err := snap.GetProposal(id)
+	if err != nil {
+		return err
+	}
+	if proposal == nil {
+		return errUnknownProposal
+	}
+	proposal.Deposit = new(big.Int)
+	return snap.setProposal(id, proposal)
+}
+
+// ExpiredProposals returns the pending proposals whose voting period ended
+// before the given block.
+func (snap *Snapshot) ExpiredProposals(number uint64) ([]common.Hash, error) {
+	ids, err := snap.GetPendingProposals()
+	if err != nil {
+		return nil, err
+	}
+	var expired []common.Hash
+	for _, id := range ids {
+		proposal, err := snap.GetProposal(id)
+		if err != nil {
+			return nil, err
+		}
+		if proposal == nil || number > proposal.Deadline {
+			expired = append(expired, id)
+		}
+	}
+	return expired, nil
+}
+
+// PruneProposals drops the proposals whose voting period ended before the given
+// block, so that the governance state doesn't grow with every proposal ever
+// submitted. The pending ones expire, their records and votes deleted, the
+// executed ones lose their records. It returns the expired proposals.
+func (snap *Snapshot) PruneProposals(number uint64) ([]common.Hash, error) {
+	expired, err := snap.ExpiredProposals(number)
+	if err != nil {
+		return nil, err
+	}
+	if len(expired) > 0 {
+		ids, err := snap.GetPendingProposals()
+		if err != nil {
+			return nil, err
+		}
+		drop := make(map[common.Hash]bool, len(expired))
+		for _, id := range expired {
+			if err := snap.deleteProposal(id); err != nil {
+				return nil, err
+			}
+			drop[id] = true
+		}
+		pending := make([]common.Hash, 0, len(ids))
+		for _, id := range ids {
+			if !drop[id] {
+				pending = append(pending, id)
+			}
+		}
+		if err := snap.setPendingProposals(pending); err != nil {
+			return nil, err
+		}
+	}
+	ids, err := snap.GetExecutedProposals()
+	if err != nil {
+		return nil, err
+	}
+	kept := make([]common.Hash, 0, len(ids))
+	for _, id := range ids {
+		proposal, err := snap.GetProposal(id)
+		if err != nil {
+			return nil, err
+		}
+		if proposal != nil && number <= proposal.Deadline {
+			kept = append(kept, id)
+			continue
+		}
+		if err := snap.deleteProposal(id); err != nil {
+			return nil, err
+		}
+	}
+	if len(kept) != len(ids) {
+		if err := snap.setExecutedProposals(kept); err != nil {
+			return nil, err
+		}
+	}
+	return expired, nil
+}
+
+// deleteProposal deletes the record of a proposal and the votes on it.
+func (snap *Snapshot) deleteProposal(id common.Hash) error {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return err
+	}
+	if err := configTrie.TryDelete(proposalKey(id)); err != nil {
+		return err
+	}
+	return snap.deleteVotes(id)
+}
+
+// deleteVotes deletes the votes of the validators on a proposal.
+func (snap *Snapshot) deleteVotes(id common.Hash) error {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return err
+	}
+	prefix := append([]byte("vote-"), id.Bytes()...)
+	iter := trie.NewIterator(configTrie.PrefixIterator(prefix))
+
+	// Collect the keys first, the trie can't be modified while iterated
+	var keys [][]byte
+	for iter.Next() {
+		keys = append(keys, common.CopyBytes(iter.Key[len(configPrefix):]))
+	}
+	if iter.Err != nil {
+		return iter.Err
+	}
+	for _, key := range keys {
+		if err := configTrie.TryDelete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHaltHeight returns the height after which no block is minted, 0 if the
+// validators never halted the chain.
+func (snap *Snapshot) GetHaltHeight() (uint64, error) {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return 0, err
+	}
+	value, err := configTrie.TryGet([]byte("halt"))
+	if err != nil || len(value) != 8 {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// setHaltHeight writes the height after which no block is minted to snapshot.
+func (snap *Snapshot) setHaltHeight(height uint64) error {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return err
+	}
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, height)
+	return configTrie.TryUpdate([]byte("halt"), value)
+}
+
+// forkKey returns the config trie key of the activation block of a fork.
+func forkKey(name string) []byte {
+	return append([]byte("fork-"), name...)
+}
+
+// GetForkBlock returns the activation block of the fork scheduled by the
+// validators, 0 if they didn't schedule it.
+func (snap *Snapshot) GetForkBlock(name string) (uint64, error) {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return 0, err
+	}
+	value, err := configTrie.TryGet(forkKey(name))
+	if err != nil || len(value) != 8 {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// setForkBlock writes the activation block of the fork to snapshot.
+func (snap *Snapshot) setForkBlock(name string, block uint64) error {
+	configTrie, err := snap.ensureTrie(configPrefix)
+	if err != nil {
+		return err
+	}
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, block)
+	return configTrie.TryUpdate(forkKey(name), value)
+}
+
+// proposalDeadline returns the last block the validators may vote in on a
+// proposal submitted in the given block.
+func proposalDeadline(config params.EqualityConfig, number uint64) uint64 {
+	return number + config.ProposalWindow*config.Epoch
+}
+
+// proposedConfig returns the config proposed by the transaction, the fields the
+// chain can't change once launched being kept. The period and the epoch length
+// are left to the overrides: light clients and warp sync schedule the slots and
+// the epochs from the genesis config and its overrides only, without the configs
+// activated by the snapshots.
+func proposedConfig(config params.EqualityConfig, proposed params.EqualityConfig) (params.EqualityConfig, error) {
+	if proposed.Period != config.Period || proposed.Epoch != config.Epoch {
+		return params.EqualityConfig{}, errSlotScheduleChange
+	}
+	proposed.GenesisTimestamp = config.GenesisTimestamp
+	proposed.Validators = config.Validators
+	proposed.Overrides = nil
+	if err := proposed.Validate(); err != nil {
+		return params.EqualityConfig{}, err
+	}
+	return proposed, nil
+}
+
+// propose records the config proposal of the transaction if valid, locking the
+// deposit of the proposer.
+func propose(config params.EqualityConfig, state *state.StateDB, header *types.Header, snap *Snapshot, event *EventPropose) (*Proposal, error) {
+	proposed, err := proposedConfig(config, event.Config)
+	if err != nil {
+		return nil, err
+	}
+	deposit := config.RequiredDeposit()
+	if state.GetBalance(event.Proposer).Cmp(deposit) < 0 {
+		return nil, errInsufficientDeposit
+	}
+	number := header.Number.Uint64()
+	if err := snap.Propose(event.ID, event.Proposer, proposed, number, proposalDeadline(config, number), deposit); err != nil {
+		return nil, err
+	}
+	state.SubBalance(event.Proposer, deposit)
+	return &Proposal{ID: event.ID, Proposer: event.Proposer, Config: proposed}, nil
+}
+
+// proposeHalt records the halt proposal of the transaction, locking the deposit
+// of the proposer.
+func proposeHalt(config params.EqualityConfig, state *state.StateDB, header *types.Header, snap *Snapshot, event *EventHalt) (*Proposal, error) {
+	deposit := config.RequiredDeposit()
+	if state.GetBalance(event.Proposer).Cmp(deposit) < 0 {
+		return nil, errInsufficientDeposit
+	}
+	number := header.Number.Uint64()
+	if err := snap.ProposeHalt(event.ID, event.Proposer, event.Height, number, proposalDeadline(config, number), deposit); err != nil {
+		return nil, err
+	}
+	state.SubBalance(event.Proposer, deposit)
+	return &Proposal{ID: event.ID, Proposer: event.Proposer, Halt: event.Height}, nil
+}
+
+// proposeFork records the fork proposal of the transaction if the fork can be
+// scheduled and isn't yet, locking the deposit of the proposer. The fork must
+// activate more than an epoch ahead, leaving the nodes importing the approving
+// block, possibly through a reorg, time to schedule it before it's reached.
+func proposeFork(config params.EqualityConfig, state *state.StateDB, header *types.Header, snap *Snapshot, event *EventFork) (*Proposal, error) {
+	if !schedulableFork(event.Fork) {
+		return nil, errInvalidFork
+	}
+	number := header.Number.Uint64()
+	if event.Block <= number+config.Epoch {
+		return nil, errInvalidFork
+	}
+	if scheduled, err := snap.GetForkBlock(event.Fork); err != nil || scheduled != 0 {
+		if err == nil {
+			err = errInvalidFork
+		}
+		return nil, err
+	}
+	deposit := config.RequiredDeposit()
+	if state.GetBalance(event.Proposer).Cmp(deposit) < 0 {
+		return nil, errInsufficientDeposit
+	}
+	if err := snap.ProposeFork(event.ID, event.Proposer, event.Fork, event.Block, number, proposalDeadline(config, number), deposit); err != nil {
+		return nil, err
+	}
+	state.SubBalance(event.Proposer, deposit)
+	return &Proposal{ID: event.ID, Proposer: event.Proposer, Fork: event.Fork, ForkBlock: event.Block}, nil
+}
+
+// proposeSignal records the signal proposal of the transaction, locking the
+// deposit of the proposer.
+func proposeSignal(config params.EqualityConfig, state *state.StateDB, header *types.Header, snap *Snapshot, event *EventSignal) (*Proposal, error) {
+	deposit := config.RequiredDeposit()
+	if state.GetBalance(event.Proposer).Cmp(deposit) < 0 {
+		return nil, errInsufficientDeposit
+	}
+	number := header.Number.Uint64()
+	if err := snap.ProposeSignal(event.ID, event.Proposer, event.Text, number, proposalDeadline(config, number), deposit); err != nil {
+		return nil, err
+	}
+	state.SubBalance(event.Proposer, deposit)
+	return &Proposal{ID: event.ID, Proposer: event.Proposer, Signal: event.Text}, nil
+}
+
+// proposeSpend records the spending proposal of the transaction if the network
+// has a pool, locking the deposit of the proposer.
+func proposeSpend(config params.EqualityConfig, state *state.StateDB, header *types.Header, snap *Snapshot, event *EventSpend) (*Proposal, error) {
+	if config.Pool == (common.Address{}) {
+		return nil, errNoPool
+	}
+	deposit := config.RequiredDeposit()
+	if state.GetBalance(event.Proposer).Cmp(deposit) < 0 {
+		return nil, errInsufficientDeposit
+	}
+	number := header.Number.Uint64()
+	if err := snap.ProposeSpend(event.ID, event.Proposer, event.Recipient, event.Amount, number, proposalDeadline(config, number), deposit); err != nil {
+		return nil, err
+	}
+	state.SubBalance(event.Proposer, deposit)
+	return &Proposal{ID: event.ID, Proposer: event.Proposer, Recipient: event.Recipient, Amount: event.Amount}, nil
+}
+
+// schedulableFork returns whether the validators may schedule the fork.
+func schedulableFork(name string) bool {
+	for _, fork := range params.SchedulableForks {
+		if fork == name {
+			return true
+		}
+	}
+	return false
+}
+
+// vote records the vote of the transaction if cast by a validator of the epoch
+// on a proposal still open.
+func vote(header *types.Header, snap *Snapshot, event *EventVote) error {
+	proposal, err := snap.GetProposal(event.ID)
+	if err != nil {
+		return err
+	}
+	if proposal == nil || proposal.Executed != 0 || header.Number.Uint64() > proposal.Deadline {
+		return errUnknownProposal
+	}
+	validators, err := snap.GetValidators()
+	if err != nil {
+		return err
+	}
+	if !addressesExist(validators, event.Validator) {
+		return errUnauthorized
+	}
+	return snap.Vote(event.ID, event.Validator, event.Approve)
+}
+
+// ProposalTally is the count of the votes of the validators of the epoch on a
+// proposal.
+type ProposalTally struct {
+	Approvals  int `json:"approvals"`
+	Rejections int `json:"rejections"`
+	Validators int `json:"validators"`
+	Quorum     int `json:"quorum"` // Approvals activating the proposal
+}
+
+// Approved returns whether the quorum of the validators approved the proposal.
+func (tally ProposalTally) Approved() bool {
+	return tally.Validators > 0 && tally.Approvals >= tally.Quorum
+}
+
+// proposalQuorum returns the number of validators out of the given count whose
+// approval activates a proposal, the configured percentage of them rounded up,
+// two thirds by default.
+func proposalQuorum(config params.EqualityConfig, validators int) int {
+	if config.ProposalQuorum == 0 {
+		return (2*validators + 2) / 3
+	}
+	return (int(config.ProposalQuorum)*validators + 99) / 100
+}
+
+// haltQuorum returns the number of validators out of the given count whose
+// approval halts the chain, a supermajority of two thirds at least.
+func haltQuorum(config params.EqualityConfig, validators int) int {
+	quorum := proposalQuorum(config, validators)
+	if supermajority := (2*validators + 2) / 3; quorum < supermajority {
+		return supermajority
+	}
+	return quorum
+}
+
+// TallyProposal counts the votes of the validators on the proposal. The votes
+// of former validators are ignored.
+func (snap *Snapshot) TallyProposal(config params.EqualityConfig, id common.Hash, validators []common.Address) (ProposalTally, error) {
+	proposal, err := snap.GetProposal(id)
+	if err != nil {
+		return ProposalTally{}, err
+	}
+	return tallyVotes(config, proposal, validators, func(validator common.Address) (bool, bool, error) {
+		return snap.GetVote(id, validator)
+	})
+}
+
+// tallyVotes counts the votes of the validators on a proposal, looked up with
+// the given function.
+func tallyVotes(config params.EqualityConfig, proposal *ProposalRecord, validators []common.Address,
+	vote func(validator common.Address) (voted bool, approve bool, err error)) (ProposalTally, error) {
+
+	tally := ProposalTally{Validators: len(validators), Quorum: proposalQuorum(config, len(validators))}
+	if proposal != nil && proposal.Halt != 0 {
+		tally.Quorum = haltQuorum(config, len(validators))
+	}
+	for _, validator := range validators {
+		voted, approve, err := vote(validator)
+		if err != nil {
+			return ProposalTally{}, err
+		}
+		switch {
+		case voted && approve:
+			tally.Approvals++
+		case voted:
+			tally.Rejections++
+		}
+	}
+	return tally, nil
+}
+
+// tallyApproved tallies the votes of the validators on the proposal, returning
+// whether the quorum approves it.
+func tallyApproved(config params.EqualityConfig, snap *Snapshot, id common.Hash) (ProposalTally, bool) {
+	validators, err := snap.GetValidators()
+	if err != nil {
+		return ProposalTally{}, false
+	}
+	tally, err := snap.TallyProposal(config, id, validators)
+	if err != nil {
+		return ProposalTally{}, false
+	}
+	return tally, tally.Approved()
+}
+
+// refundDeposit gives the deposit locked by an approved proposal back to its
+// proposer. The deposits of the proposals never approved stay burned.
+func refundDeposit(state *state.StateDB, proposal *ProposalRecord) {
+	if proposal.Deposit != nil && proposal.Deposit.Sign() > 0 {
+		state.AddBalance(proposal.Proposer, proposal.Deposit)
+	}
+}
+
+// releaseDeposit refunds the deposit of a proposal the quorum approved but which
+// can't be executed, a spending the pool can't afford yet or a fork scheduled
+// already. The record is left without deposit, so that it's neither refunded
+// twice nor burned when the proposal expires.
+func releaseDeposit(state *state.StateDB, snap *Snapshot, headerExtra *HeaderExtra, id common.Hash, proposal *ProposalRecord) error {
+	if proposal.Deposit == nil || proposal.Deposit.Sign() == 0 {
+		return nil
+	}
+	if err := snap.RefundProposal(id); err != nil {
+		return err
+	}
+	refundDeposit(state, proposal)
+	headerExtra.RefundedProposals = append(headerExtra.RefundedProposals, id)
+	return nil
+}
+
+// Activate the configs approved by the quorum of the validators of the ending
+// epoch in first block for epoch. The votes are tallied against the snapshot of
+// the block, the votes of its own transactions included, and the proposals are
+// executed in the order they were submitted, so that every node activates the
+// same configs at the same block, the last one approved prevailing. Approved
+// proposals which can't be executed get their deposit back all the same. The
+// proposals whose deadline passed are pruned afterwards.
+func (e *Equality) executeProposals(config params.EqualityConfig, state *state.StateDB, header *types.Header,
+	snap *Snapshot, headerExtra *HeaderExtra) error {
+
+	number := header.Number.Uint64()
+	if config.ProposalWindow == 0 || number <= e.start || number != headerExtra.EpochBlock {
+		return nil
+	}
+	ids, err := snap.GetPendingProposals()
+	if err != nil {
+		return err
+	}
+	validators, err := snap.GetValidators()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		proposal, err := snap.GetProposal(id)
+		if err != nil {
+			return err
+		}
+		if proposal == nil || proposal.Halt != 0 || number > proposal.Deadline {
+			continue
+		}
+		// Forks only activate over an epoch ahead, the first one approved being kept
+		stale := false
+		if proposal.Fork != "" {
+			scheduled, err := snap.GetForkBlock(proposal.Fork)
+			if err != nil {
+				return err
+			}
+			stale = scheduled != 0 || proposal.ForkBlock <= number+config.Epoch
+		}
+		tally, err := snap.TallyProposal(config, id, validators)
+		if err != nil {
+			return err
+		}
+		if !tally.Approved() {
+			continue
+		}
+		if stale {
+			if err := releaseDeposit(state, snap, headerExtra, id, proposal); err != nil {
+				return err
+			}
+			log.Debug("[equality] Fork proposal approved too late", "number", number, "proposal", id,
+				"fork", proposal.Fork, "block", proposal.ForkBlock)
+			continue
+		}
+		// Spendings wait for the pool to afford them until their deadline
+		if proposal.spending() && state.GetBalance(config.Pool).Cmp(proposal.Amount) < 0 {
+			if err := releaseDeposit(state, snap, headerExtra, id, proposal); err != nil {
+				return err
+			}
+			log.Debug("[equality] Spending proposal exceeds the pool", "number", number, "proposal", id,
+				"amount", proposal.Amount, "pool", state.GetBalance(config.Pool))
+			continue
+		}
+		if err := snap.ExecuteProposal(id, number); err != nil {
+			return err
+		}
+		refundDeposit(state, proposal)
+		headerExtra.ExecutedProposals = append(headerExtra.ExecutedProposals, id)
+		logProposalExecuted(state, header, id)
+		if proposal.spending() {
+			state.SubBalance(config.Pool, proposal.Amount)
+			state.AddBalance(proposal.Recipient, proposal.Amount)
+			log.Info("[equality] Spending proposal executed", "number", number, "proposal", id, "pool", config.Pool,
+				"recipient", proposal.Recipient, "amount", proposal.Amount,
+				"approvals", tally.Approvals, "quorum", tally.Quorum, "validators", tally.Validators)
+			continue
+		}
+		if proposal.Signal != (common.Hash{}) {
+			log.Info("[equality] Signal proposal approved", "number", number, "proposal", id, "text", proposal.Signal,
+				"approvals", tally.Approvals, "quorum", tally.Quorum, "validators", tally.Validators)
+			continue
+		}
+		if proposal.Fork != "" {
+			log.Info("[equality] Fork proposal approved", "number", number, "proposal", id, "fork", proposal.Fork, "block", proposal.ForkBlock,
+				"approvals", tally.Approvals, "quorum", tally.Quorum, "validators", tally.Validators)
+			continue
+		}
+		if err := snap.SetChainConfig(proposal.Config); err != nil {
+			return err
+		}
+		headerExtra.ChainConfig = append(headerExtra.ChainConfig, proposal.Config)
+		log.Info("[equality] Chain config proposal activated", "number", number, "proposal", id,
+			"approvals", tally.Approvals, "quorum", tally.Quorum, "validators", tally.Validators)
+	}
+	// Proposals past their deadline can't be approved anymore, drop them
+	ids, err = snap.ExpiredProposals(number)
+	if err != nil {
+		return err
+	}
+	burned := make(map[common.Hash]*big.Int, len(ids))
+	for _, id := range ids {
+		if proposal, err := snap.GetProposal(id); err == nil && proposal != nil {
+			burned[id] = proposal.Deposit
+		}
+	}
+	expired, err := snap.PruneProposals(number)
+	if err != nil {
+		return err
+	}
+	for _, id := range expired {
+		if deposit := burned[id]; deposit != nil && deposit.Sign() > 0 {
+			log.Info("[equality] Proposal expired, deposit burned", "number", number, "proposal", id, "deposit", deposit)
+		} else {
+			log.Info("[equality] Proposal expired", "number", number, "proposal", id)
+		}
+	}
+	return nil
+}
+
+// Halt the chain as soon as a supermajority of the validators approves the halt
+// proposal voted on, so that a critical bug doesn't wait for the end of the epoch.
+func (e *Equality) executeHalt(config params.EqualityConfig, state *state.StateDB, header *types.Header,
+	snap *Snapshot, headerExtra *HeaderExtra, id common.Hash) error {
+
+	proposal, err := snap.GetProposal(id)
+	if err != nil || proposal == nil || proposal.Halt == 0 || proposal.Executed != 0 {
+		return err
+	}
+	validators, err := snap.GetValidators()
+	if err != nil {
+		return err
+	}
+	tally, err := snap.TallyProposal(config, id, validators)
+	if err != nil || !tally.Approved() {
+		return err
+	}
+	number := header.Number.Uint64()
+	if err := snap.ExecuteProposal(id, number); err != nil {
+		return err
+	}
+	refundDeposit(state, proposal)
+	headerExtra.ExecutedProposals = append(headerExtra.ExecutedProposals, id)
+	logProposalExecuted(state, header, id)
+	log.Warn("[equality] Chain halt approved", "number", number, "proposal", id, "height", proposal.Halt,
+		"approvals", tally.Approvals, "quorum", tally.Quorum, "validators", tally.Validators)
+	return nil
+}
+
+// checkHalt returns errChainHalted if the block of the given number comes after
+// the halt height of the snapshot of its parent, unless an override resumed the
+// chain.
+func (e *Equality) checkHalt(snap *Snapshot, number uint64) error {
+	halt, err := snap.GetHaltHeight()
+	if err != nil {
+		return err
+	}
+	if halt == 0 || number <= halt || e.config.Resumed(halt, number) {
+		return nil
+	}
+	return errChainHalted
+}
+
+// ScheduleForks writes the forks the validators scheduled up to the canonical
+// block into the chain config of the node, both in memory for the EVM and in the
+// database for restarts. The approval being part of consensus, every node
+// schedules the same forks, reading them from the snapshot of the block once it
+// is canonical so that the ones approved on side chains are left out. Only the
+// blocks executing proposals are looked at after the first one.
+func (e *Equality) ScheduleForks(chain consensus.ChainHeaderReader, header *types.Header) {
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return
+	}
+	e.lock.RLock()
+	synced := e.forksSynced
+	e.lock.RUnlock()
+	if synced && len(headerExtra.ExecutedProposals) == 0 {
+		return
+	}
+	snap, err := e.openSnapshot(headerExtra.Root)
+	if err != nil {
+		log.Debug("[equality] Snapshot unavailable to schedule forks", "number", header.Number, "err", err)
+		return
+	}
+	config, scheduled := chain.Config(), false
+	for _, name := range params.SchedulableForks {
+		block, err := snap.GetForkBlock(name)
+		if err != nil {
+			return
+		}
+		if block == 0 {
+			continue
+		}
+		fork := params.EqualityFork{Name: name, Block: block}
+		added, err := config.ScheduleEqualityFork(fork)
+		if err != nil {
+			log.Error("[equality] Failed to schedule fork approved by the validators", "fork", fork.Name, "block", fork.Block, "err", err)
+			continue
+		}
+		if added {
+			scheduled = true
+			log.Info("[equality] Fork scheduled by the validators", "fork", fork.Name, "block", fork.Block)
+		}
+	}
+	if genesis := chain.GetHeaderByNumber(0); scheduled && genesis != nil {
+		rawdb.WriteChainConfig(e.db, genesis.Hash(), config)
+	}
+	e.lock.Lock()
+	e.forksSynced = true
+	e.lock.Unlock()
+}
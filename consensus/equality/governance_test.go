@@ -0,0 +1,597 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+// signTestEvent signs a custom transaction of the test user.
+func signTestEvent(t *testing.T, nonce uint64, event Transaction) *types.Transaction {
+	tx := types.NewTransaction(nonce, testUserAddress, new(big.Int), 0, new(big.Int), EncodeTransaction(event))
+	tx, err := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1)), testUserKey)
+	assert.Nil(t, err)
+	return tx
+}
+
+func TestConfigProposal(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 2
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	proposed := config
+	proposed.MaxValidatorsCount = 7
+	propose := signTestEvent(t, 0, &EventPropose{Config: proposed})
+	id := propose.Hash()
+	approve := signTestEvent(t, 1, &EventVote{ID: id, Approve: true})
+
+	// Votes of other accounts than the validators are ignored
+	outsider, _ := crypto.GenerateKey()
+	reject, err := types.SignTx(types.NewTransaction(0, testUserAddress, new(big.Int), 0, new(big.Int), EncodeTransaction(&EventVote{ID: id})),
+		types.NewEIP155Signer(big.NewInt(1)), outsider)
+	assert.Nil(t, err)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	txs := []*types.Transaction{propose, approve, reject}
+	header := mintTestBlock(t, sealer, chain, statedb, txs)
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(headerExtra.CurrentBlockProposals))
+	assert.Equal(t, id, headerExtra.CurrentBlockProposals[0].ID)
+	assert.Equal(t, testUserAddress, headerExtra.CurrentBlockProposals[0].Proposer)
+	assert.True(t, proposed.Equal(headerExtra.CurrentBlockProposals[0].Config))
+	assert.Equal(t, []Vote{{ID: id, Validator: testUserAddress, Approve: true}}, headerExtra.CurrentBlockVotes)
+	assert.Empty(t, headerExtra.ExecutedProposals)
+
+	// Verifying nodes replay the same governance actions
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(testUserAddress, big.NewInt(1))
+	sealer.Finalize(chain, types.CopyHeader(header), statedb, txs, nil)
+	assert.Equal(t, big.NewInt(1), statedb.GetBalance(testUserAddress))
+
+	// The config is activated in the first block of the next epoch
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, nil)
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, header.Number.Uint64(), headerExtra.EpochBlock)
+	assert.Equal(t, []common.Hash{id}, headerExtra.ExecutedProposals)
+	assert.Equal(t, 1, len(headerExtra.ChainConfig))
+
+	active, err := sealer.chainConfig(header)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), active.MaxValidatorsCount)
+
+	snap, err := sealer.openSnapshot(headerExtra.Root)
+	assert.Nil(t, err)
+	record, err := snap.GetProposal(id)
+	assert.Nil(t, err)
+	assert.Equal(t, header.Number.Uint64(), record.Executed)
+	pending, err := snap.GetPendingProposals()
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	assert.Nil(t, engine.EnsureSnapshot(chain, header))
+	assert.True(t, engine.snapshotAvailable(headerExtra.Root))
+}
+
+func TestConfigProposalRejected(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 1
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	// Proposals breaking the config or changing the slot schedule, which light
+	// clients and warp sync follow from the genesis config, are refused
+	broken := config
+	broken.MaxValidatorsCount = 0
+	period, epoch := config, config
+	period.Period = 2
+	epoch.Epoch = 4
+	txs := []*types.Transaction{
+		signTestEvent(t, 0, &EventPropose{Config: broken}),
+		signTestEvent(t, 1, &EventPropose{Config: period}),
+		signTestEvent(t, 2, &EventPropose{Config: epoch}),
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header := mintTestBlock(t, sealer, chain, statedb, txs)
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Empty(t, headerExtra.CurrentBlockProposals)
+
+	// Proposals the validators reject stay pending without being activated
+	proposed := config
+	proposed.MaxValidatorsCount = 7
+	propose := signTestEvent(t, 3, &EventPropose{Config: proposed})
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	mintTestBlock(t, sealer, chain, statedb, []*types.Transaction{propose, signTestEvent(t, 4, &EventVote{ID: propose.Hash()})})
+
+	for i := 0; i < 2; i++ {
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		header = mintTestBlock(t, sealer, chain, statedb, nil)
+	}
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, header.Number.Uint64(), headerExtra.EpochBlock)
+	assert.Empty(t, headerExtra.ExecutedProposals)
+	active, err := sealer.chainConfig(header)
+	assert.Nil(t, err)
+	assert.Equal(t, config.MaxValidatorsCount, active.MaxValidatorsCount)
+}
+
+func TestProposalQuorum(t *testing.T) {
+	config := testSnapshotConfig()
+	for validators, quorum := range map[int]int{1: 1, 3: 2, 4: 3, 21: 14} {
+		assert.Equal(t, quorum, proposalQuorum(config, validators), "validators %d", validators)
+	}
+	config.ProposalQuorum = 51
+	assert.Equal(t, 11, proposalQuorum(config, 21))
+	config.ProposalQuorum = 100
+	assert.Equal(t, 21, proposalQuorum(config, 21))
+
+	// Only the votes of the given validators are tallied
+	config.ProposalQuorum = 0
+	snap, err := newSnapshot(rawdb.NewMemoryDatabase())
+	assert.Nil(t, err)
+	id := common.Hash{0x01}
+	validators := []common.Address{{0x01}, {0x02}, {0x03}}
+	assert.Nil(t, snap.Vote(id, validators[0], true))
+	assert.Nil(t, snap.Vote(id, validators[1], false))
+	assert.Nil(t, snap.Vote(id, common.Address{0x04}, true))
+
+	tally, err := snap.TallyProposal(config, id, validators)
+	assert.Nil(t, err)
+	assert.Equal(t, ProposalTally{Approvals: 1, Rejections: 1, Validators: 3, Quorum: 2}, tally)
+	assert.False(t, tally.Approved())
+
+	// A later vote replaces the previous one
+	assert.Nil(t, snap.Vote(id, validators[1], true))
+	tally, err = snap.TallyProposal(config, id, validators)
+	assert.Nil(t, err)
+	assert.True(t, tally.Approved())
+
+	tally, err = snap.TallyProposal(config, id, nil)
+	assert.Nil(t, err)
+	assert.False(t, tally.Approved())
+}
+
+func TestHaltProposal(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 1
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	// The halt is set as soon as the validators approve it, before the epoch ends
+	halt := signTestEvent(t, 0, &EventHalt{Height: 7})
+	id := halt.Hash()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	txs := []*types.Transaction{halt, signTestEvent(t, 1, &EventVote{ID: id, Approve: true})}
+	header := mintTestBlock(t, sealer, chain, statedb, txs)
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.NotEqual(t, header.Number.Uint64(), headerExtra.EpochBlock)
+	assert.Equal(t, []Proposal{{ID: id, Proposer: testUserAddress, Halt: 7}}, headerExtra.CurrentBlockProposals)
+	assert.Equal(t, []common.Hash{id}, headerExtra.ExecutedProposals)
+	assert.Empty(t, headerExtra.ChainConfig)
+
+	// Verifying nodes replay the same halt
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	sealer.Finalize(chain, types.CopyHeader(header), statedb, txs, nil)
+
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	assert.Nil(t, engine.EnsureSnapshot(chain, header))
+	snap, err := engine.openSnapshot(headerExtra.Root)
+	assert.Nil(t, err)
+	height, err := snap.GetHaltHeight()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), height)
+
+	// Blocks up to the halt height are minted, none after it
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, nil)
+	assert.Equal(t, uint64(7), header.Number.Uint64())
+
+	next := &types.Header{Number: big.NewInt(8), ParentHash: header.Hash(), Coinbase: testUserAddress}
+	assert.Equal(t, errChainHalted, sealer.Prepare(chain, next))
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	snap, err = sealer.openSnapshot(headerExtra.Root)
+	assert.Nil(t, err)
+	assert.Equal(t, errChainHalted, sealer.checkHalt(snap, 8))
+
+	// An override agreed on off-chain resumes the chain
+	config.Overrides = []params.EqualityOverride{{Block: 8, Resume: true}}
+	assert.Nil(t, sealer.checkHalt(snap, 8))
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, nil)
+	assert.Equal(t, uint64(8), header.Number.Uint64())
+}
+
+func TestHaltQuorum(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalQuorum = 51
+	assert.Equal(t, 11, proposalQuorum(config, 21))
+	assert.Equal(t, 14, haltQuorum(config, 21))
+	config.ProposalQuorum = 90
+	assert.Equal(t, 19, haltQuorum(config, 21))
+
+	snap, err := newSnapshot(rawdb.NewMemoryDatabase())
+	assert.Nil(t, err)
+	id := common.Hash{0x01}
+	validators := []common.Address{{0x01}, {0x02}, {0x03}, {0x04}}
+	assert.Nil(t, snap.ProposeHalt(id, validators[0], 100, 10, 20, new(big.Int)))
+	config.ProposalQuorum = 51
+	tally, err := snap.TallyProposal(config, id, validators)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, tally.Quorum)
+}
+
+func TestForkProposal(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 2
+	sealer, chain := makeSnapshotChain(t, &config)
+	chainConfig := *params.TestChainConfig
+	chainConfig.Equality = &config
+	chain.config = &chainConfig
+
+	// Unknown forks and past blocks are refused
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	propose := signTestEvent(t, 2, &EventFork{Fork: "staking", Block: 100})
+	txs := []*types.Transaction{
+		signTestEvent(t, 0, &EventFork{Fork: "frontier", Block: 100}),
+		signTestEvent(t, 1, &EventFork{Fork: "staking", Block: 6}),
+		propose,
+		signTestEvent(t, 3, &EventVote{ID: propose.Hash(), Approve: true}),
+	}
+	header := mintTestBlock(t, sealer, chain, statedb, txs)
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, []Proposal{{ID: propose.Hash(), Proposer: testUserAddress, Fork: "staking", ForkBlock: 100}}, headerExtra.CurrentBlockProposals)
+	assert.Nil(t, chainConfig.StakingBlock)
+
+	// The fork is scheduled in the first block of the next epoch
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, nil)
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Hash{propose.Hash()}, headerExtra.ExecutedProposals)
+	assert.Empty(t, headerExtra.ChainConfig)
+
+	// But applied once the block is canonical only
+	assert.Nil(t, chainConfig.StakingBlock)
+	assert.Nil(t, rawdb.ReadChainConfig(sealer.db, chain.headers[0].Hash()))
+	sealer.ScheduleForks(chain, header)
+	assert.Equal(t, big.NewInt(100), chainConfig.StakingBlock)
+	assert.Equal(t, []params.EqualityFork{{Name: "staking", Block: 100}}, config.Forks)
+	stored := rawdb.ReadChainConfig(sealer.db, chain.headers[0].Hash())
+	assert.NotNil(t, stored)
+	assert.Equal(t, big.NewInt(100), stored.StakingBlock)
+	assert.Equal(t, config.Forks, stored.Equality.Forks)
+
+	// Verifying nodes replay the same fork, which can't be scheduled again
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	assert.Nil(t, engine.EnsureSnapshot(chain, header))
+	snap, err := engine.openSnapshot(headerExtra.Root)
+	assert.Nil(t, err)
+	block, err := snap.GetForkBlock("staking")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), block)
+
+	// Starting nodes pick the forks from the first canonical snapshot
+	restarted := *params.TestChainConfig
+	restarted.Equality = &params.EqualityConfig{}
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	next := mintTestBlock(t, sealer, chain, statedb, nil)
+	New(&config, sealer.db).ScheduleForks(&testChainReader{config: &restarted, headers: chain.headers}, next)
+	assert.Equal(t, big.NewInt(100), restarted.StakingBlock)
+	assert.Equal(t, config.Forks, restarted.Equality.Forks)
+
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, []*types.Transaction{signTestEvent(t, 4, &EventFork{Fork: "staking", Block: 200})})
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Empty(t, headerExtra.CurrentBlockProposals)
+}
+
+func TestSignalProposal(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 2
+	config.ProposalDeposit = big.NewInt(10)
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	// Signals are voted on like the other proposals
+	text := crypto.Keccak256Hash([]byte("raise the gas limit"))
+	signal := signTestEvent(t, 0, &EventSignal{Text: text})
+	txs := []*types.Transaction{
+		signal,
+		signTestEvent(t, 1, &EventSignal{}),
+		signTestEvent(t, 2, &EventVote{ID: signal.Hash(), Approve: true}),
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(testUserAddress, big.NewInt(10))
+	header := mintTestBlock(t, sealer, chain, statedb, txs)
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, []Proposal{{ID: signal.Hash(), Proposer: testUserAddress, Signal: text}}, headerExtra.CurrentBlockProposals)
+	assert.Equal(t, 0, statedb.GetBalance(testUserAddress).Sign())
+
+	// Once approved, the signal is closed and refunded, nothing being executed
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, nil)
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Hash{signal.Hash()}, headerExtra.ExecutedProposals)
+	assert.Empty(t, headerExtra.ChainConfig)
+	assert.Equal(t, big.NewInt(10), statedb.GetBalance(testUserAddress))
+	assert.Nil(t, sealer.verifyCascadingFields(chain, header, nil))
+
+	snap, err := sealer.openSnapshot(headerExtra.Root)
+	assert.Nil(t, err)
+	record, err := snap.GetProposal(signal.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, text, record.Signal)
+	assert.Equal(t, header.Number.Uint64(), record.Executed)
+	current, err := snap.GetChainConfig()
+	assert.Nil(t, err)
+	assert.True(t, current.Equal(config))
+}
+
+func TestSpendProposal(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 2
+	config.Pool = common.HexToAddress("0x53d77827bE168aB2a911B5A14D0f16D1C5657196")
+	sealer, chain := makeSnapshotChain(t, &config)
+	recipient := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+
+	spend := signTestEvent(t, 0, &EventSpend{Recipient: recipient, Amount: big.NewInt(100)})
+	txs := []*types.Transaction{
+		spend,
+		signTestEvent(t, 1, &EventVote{ID: spend.Hash(), Approve: true}),
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header := mintTestBlock(t, sealer, chain, statedb, txs)
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, []Proposal{{ID: spend.Hash(), Proposer: testUserAddress, Recipient: recipient, Amount: big.NewInt(100)}}, headerExtra.CurrentBlockProposals)
+
+	// The approved spending waits for the pool to afford it
+	mint := func(pool int64) (*state.StateDB, *HeaderExtra) {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.AddBalance(config.Pool, big.NewInt(pool))
+		headerExtra, err := DecodeHeaderExtra(mintTestBlock(t, sealer, chain, statedb, nil))
+		assert.Nil(t, err)
+		return statedb, &headerExtra
+	}
+	statedb, headerExtra2 := mint(50)
+	assert.Empty(t, headerExtra2.ExecutedProposals)
+	assert.Equal(t, big.NewInt(50), statedb.GetBalance(config.Pool))
+	assert.Equal(t, 0, statedb.GetBalance(recipient).Sign())
+
+	mint(0)
+	statedb, headerExtra2 = mint(150)
+	assert.Equal(t, []common.Hash{spend.Hash()}, headerExtra2.ExecutedProposals)
+	assert.Equal(t, big.NewInt(50), statedb.GetBalance(config.Pool))
+	assert.Equal(t, big.NewInt(100), statedb.GetBalance(recipient))
+	assert.Nil(t, sealer.verifyCascadingFields(chain, chain.headers[len(chain.headers)-1], nil))
+}
+
+func TestProposalDeposit(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 1
+	config.ProposalDeposit = big.NewInt(10)
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	// The deposit is locked, proposers unable to afford it are refused
+	proposed := config
+	proposed.MaxValidatorsCount = 7
+	approved := signTestEvent(t, 0, &EventPropose{Config: proposed})
+	rejected := signTestEvent(t, 1, &EventHalt{Height: 100})
+	txs := []*types.Transaction{
+		approved,
+		rejected,
+		signTestEvent(t, 2, &EventFork{Fork: "staking", Block: 100}),
+		signTestEvent(t, 3, &EventVote{ID: approved.Hash(), Approve: true}),
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(testUserAddress, big.NewInt(25))
+	header := mintTestBlock(t, sealer, chain, statedb, txs)
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(headerExtra.CurrentBlockProposals))
+	assert.Equal(t, big.NewInt(5), statedb.GetBalance(testUserAddress))
+
+	snap, err := sealer.openSnapshot(headerExtra.Root)
+	assert.Nil(t, err)
+	record, err := snap.GetProposal(rejected.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(10), record.Deposit)
+
+	// The approved proposal is refunded in the first block of the next epoch,
+	// the deposit of the other one is burned
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, nil)
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Hash{approved.Hash()}, headerExtra.ExecutedProposals)
+	assert.Equal(t, big.NewInt(10), statedb.GetBalance(testUserAddress))
+
+	for i := 0; i < 2; i++ {
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		header = mintTestBlock(t, sealer, chain, statedb, nil)
+	}
+	headerExtra, err = DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, header.Number.Uint64(), headerExtra.EpochBlock)
+	assert.Empty(t, headerExtra.ExecutedProposals)
+	assert.Equal(t, 0, statedb.GetBalance(testUserAddress).Sign())
+}
+
+func TestApprovedDepositRefund(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 2
+	config.ProposalDeposit = big.NewInt(10)
+	config.Pool = common.HexToAddress("0x53d77827bE168aB2a911B5A14D0f16D1C5657196")
+	sealer, chain := makeSnapshotChain(t, &config)
+	recipient := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+
+	// A spending the pool can't afford and a fork scheduled by an earlier
+	// proposal are approved by the quorum
+	spend := signTestEvent(t, 0, &EventSpend{Recipient: recipient, Amount: big.NewInt(100)})
+	fork := signTestEvent(t, 1, &EventFork{Fork: "staking", Block: 100})
+	stale := signTestEvent(t, 2, &EventFork{Fork: "staking", Block: 200})
+	txs := []*types.Transaction{
+		spend,
+		fork,
+		stale,
+		signTestEvent(t, 3, &EventVote{ID: spend.Hash(), Approve: true}),
+		signTestEvent(t, 4, &EventVote{ID: fork.Hash(), Approve: true}),
+		signTestEvent(t, 5, &EventVote{ID: stale.Hash(), Approve: true}),
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(testUserAddress, big.NewInt(30))
+	header := mintTestBlock(t, sealer, chain, statedb, txs)
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(headerExtra.CurrentBlockProposals))
+	assert.Equal(t, 0, statedb.GetBalance(testUserAddress).Sign())
+
+	mint := func(pool int64) (*state.StateDB, *HeaderExtra, *Snapshot) {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.AddBalance(config.Pool, big.NewInt(pool))
+		header := mintTestBlock(t, sealer, chain, statedb, nil)
+		assert.Nil(t, sealer.verifyCascadingFields(chain, header, nil))
+		headerExtra, err := DecodeHeaderExtra(header)
+		assert.Nil(t, err)
+		snap, err := sealer.openSnapshot(headerExtra.Root)
+		assert.Nil(t, err)
+		return statedb, &headerExtra, snap
+	}
+	// All the deposits are refunded once approved, only the first fork being
+	// executed
+	statedb, headerExtra2, snap := mint(50)
+	assert.Equal(t, []common.Hash{fork.Hash()}, headerExtra2.ExecutedProposals)
+	assert.Equal(t, big.NewInt(30), statedb.GetBalance(testUserAddress))
+	for _, id := range []common.Hash{spend.Hash(), stale.Hash()} {
+		record, err := snap.GetProposal(id)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), record.Executed)
+		assert.Equal(t, 0, record.Deposit.Sign())
+	}
+
+	// Neither is refunded twice, the spending being executed once affordable
+	mint(0)
+	statedb, headerExtra2, _ = mint(150)
+	assert.Equal(t, []common.Hash{spend.Hash()}, headerExtra2.ExecutedProposals)
+	assert.Equal(t, 0, statedb.GetBalance(testUserAddress).Sign())
+	assert.Equal(t, big.NewInt(100), statedb.GetBalance(recipient))
+}
+
+func TestProposalExpiry(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 1
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	proposed := config
+	proposed.MaxValidatorsCount = 7
+	approved := signTestEvent(t, 0, &EventPropose{Config: proposed})
+	expired := signTestEvent(t, 1, &EventHalt{Height: 100})
+	txs := []*types.Transaction{
+		approved,
+		expired,
+		signTestEvent(t, 2, &EventVote{ID: approved.Hash(), Approve: true}),
+		signTestEvent(t, 3, &EventVote{ID: expired.Hash(), Approve: false}),
+	}
+	mint := func(txs []*types.Transaction) (*HeaderExtra, *Snapshot) {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		header := mintTestBlock(t, sealer, chain, statedb, txs)
+		assert.Nil(t, sealer.verifyCascadingFields(chain, header, nil))
+		headerExtra, err := DecodeHeaderExtra(header)
+		assert.Nil(t, err)
+		snap, err := sealer.openSnapshot(headerExtra.Root)
+		assert.Nil(t, err)
+		return &headerExtra, snap
+	}
+	mint(txs)
+
+	// The votes on the executed proposal are dropped, its record is kept until
+	// its deadline
+	headerExtra, snap := mint(nil)
+	assert.Equal(t, []common.Hash{approved.Hash()}, headerExtra.ExecutedProposals)
+	voted, _, err := snap.GetVote(approved.Hash(), testUserAddress)
+	assert.Nil(t, err)
+	assert.False(t, voted)
+	record, err := snap.GetProposal(approved.Hash())
+	assert.Nil(t, err)
+	assert.NotNil(t, record)
+	executed, err := snap.GetExecutedProposals()
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Hash{approved.Hash()}, executed)
+
+	// Past the deadline, the pending proposal expires and both are pruned
+	mint(nil)
+	headerExtra, snap = mint(nil)
+	assert.Equal(t, chain.headers[len(chain.headers)-1].Number.Uint64(), headerExtra.EpochBlock)
+	for _, id := range []common.Hash{approved.Hash(), expired.Hash()} {
+		record, err := snap.GetProposal(id)
+		assert.Nil(t, err)
+		assert.Nil(t, record)
+		voted, _, err := snap.GetVote(id, testUserAddress)
+		assert.Nil(t, err)
+		assert.False(t, voted)
+	}
+	pending, err := snap.GetPendingProposals()
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+	executed, err = snap.GetExecutedProposals()
+	assert.Nil(t, err)
+	assert.Empty(t, executed)
+}
+
+func TestGovernanceLogs(t *testing.T) {
+	config := testSnapshotConfig()
+	config.ProposalWindow = 2
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	// Submissions, votes and quorums are logged on the transactions carrying them
+	proposed := config
+	proposed.MaxValidatorsCount = 7
+	propose := signTestEvent(t, 0, &EventPropose{Config: proposed})
+	vote := signTestEvent(t, 1, &EventVote{ID: propose.Hash(), Approve: true})
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header := mintTestBlock(t, sealer, chain, statedb, []*types.Transaction{propose, vote})
+
+	logs := statedb.GetLogs(propose.Hash())
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, GovernanceAddress, logs[0].Address)
+	assert.Equal(t, []common.Hash{ProposalSubmittedTopic, propose.Hash(), common.BytesToHash(testUserAddress.Bytes())}, logs[0].Topics)
+	assert.Equal(t, common.LeftPadBytes([]byte{ProposalKindConfig}, 32), logs[0].Data)
+	assert.Equal(t, header.Number.Uint64(), logs[0].BlockNumber)
+
+	logs = statedb.GetLogs(vote.Hash())
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, []common.Hash{ProposalVotedTopic, propose.Hash(), common.BytesToHash(testUserAddress.Bytes())}, logs[0].Topics)
+	assert.Equal(t, common.LeftPadBytes([]byte{1}, 32), logs[0].Data)
+	assert.Equal(t, []common.Hash{QuorumReachedTopic, propose.Hash()}, logs[1].Topics)
+	assert.Equal(t, append(common.LeftPadBytes([]byte{1}, 32), common.LeftPadBytes([]byte{1}, 32)...), logs[1].Data)
+
+	// Executions at the epoch block are logged on its last transaction
+	signal := signTestEvent(t, 2, &EventSignal{Text: crypto.Keccak256Hash([]byte("raise the gas limit"))})
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = mintTestBlock(t, sealer, chain, statedb, []*types.Transaction{signal})
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Hash{propose.Hash()}, headerExtra.ExecutedProposals)
+
+	logs = statedb.GetLogs(signal.Hash())
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, common.LeftPadBytes([]byte{ProposalKindSignal}, 32), logs[0].Data)
+	assert.Equal(t, []common.Hash{ProposalExecutedTopic, propose.Hash()}, logs[1].Topics)
+	assert.Empty(t, logs[1].Data)
+	assert.Nil(t, sealer.verifyCascadingFields(chain, header, nil))
+}
@@ -0,0 +1,107 @@
+package equality
+
+import (
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// GovernanceAddress is the reserved address the logs of the governance actions
+// are emitted from, the one of the governance system contract if deployed at
+// genesis, no key standing behind it.
+var GovernanceAddress = params.SystemContractAddresses["governance"]
+
+// Topics of the governance logs, the hashes of their event signatures so that
+// the logs decode with the matching ABI. The proposal and the account are the
+// indexed topics, the other arguments the data.
+var (
+	ProposalSubmittedTopic = crypto.Keccak256Hash([]byte("ProposalSubmitted(bytes32,address,uint8)"))
+	ProposalVotedTopic     = crypto.Keccak256Hash([]byte("ProposalVoted(bytes32,address,bool)"))
+	QuorumReachedTopic     = crypto.Keccak256Hash([]byte("QuorumReached(bytes32,uint256,uint256)"))
+	ProposalExecutedTopic  = crypto.Keccak256Hash([]byte("ProposalExecuted(bytes32)"))
+)
+
+// Kinds of the proposals carried by the ProposalSubmitted logs.
+const (
+	ProposalKindConfig uint8 = iota // Change of the chain config
+	ProposalKindHalt                // Halt of the chain
+	ProposalKindFork                // Activation of a protocol fork
+	ProposalKindSignal              // Non-binding signal
+	ProposalKindSpend               // Spending of the pool
+)
+
+// kind returns the kind of the proposal.
+func (proposal Proposal) kind() uint8 {
+	switch {
+	case proposal.Amount != nil:
+		return ProposalKindSpend
+	case proposal.Signal != (common.Hash{}):
+		return ProposalKindSignal
+	case proposal.Fork != "":
+		return ProposalKindFork
+	case proposal.Halt != 0:
+		return ProposalKindHalt
+	}
+	return ProposalKindConfig
+}
+
+// addGovernanceLog attaches a governance log to the receipt of the transaction
+// the state is prepared for. The proposals executed in the first block of an
+// epoch are logged on its last transaction, not at all if it has none.
+func addGovernanceLog(state *state.StateDB, header *types.Header, topics []common.Hash, words ...*big.Int) {
+	data := make([]byte, 0, len(words)*32)
+	for _, word := range words {
+		data = append(data, common.LeftPadBytes(word.Bytes(), 32)...)
+	}
+	state.AddLog(&types.Log{
+		Address:     GovernanceAddress,
+		Topics:      topics,
+		Data:        data,
+		BlockNumber: header.Number.Uint64(),
+	})
+}
+
+// logProposalSubmitted logs the submission of a proposal.
+func logProposalSubmitted(state *state.StateDB, header *types.Header, proposal *Proposal) {
+	addGovernanceLog(state, header, []common.Hash{ProposalSubmittedTopic, proposal.ID, common.BytesToHash(proposal.Proposer.Bytes())},
+		new(big.Int).SetUint64(uint64(proposal.kind())))
+}
+
+// logProposalVoted logs the vote of a validator on a proposal.
+func logProposalVoted(state *state.StateDB, header *types.Header, vote Vote) {
+	approve := new(big.Int)
+	if vote.Approve {
+		approve.SetUint64(1)
+	}
+	addGovernanceLog(state, header, []common.Hash{ProposalVotedTopic, vote.ID, common.BytesToHash(vote.Validator.Bytes())}, approve)
+}
+
+// logQuorumReached logs the vote which brought the approvals of a proposal to
+// the quorum.
+func logQuorumReached(state *state.StateDB, header *types.Header, id common.Hash, tally ProposalTally) {
+	addGovernanceLog(state, header, []common.Hash{QuorumReachedTopic, id},
+		big.NewInt(int64(tally.Approvals)), big.NewInt(int64(tally.Quorum)))
+}
+
+// logProposalExecuted logs the execution of an approved proposal.
+func logProposalExecuted(state *state.StateDB, header *types.Header, id common.Hash) {
+	addGovernanceLog(state, header, []common.Hash{ProposalExecutedTopic, id})
+}
+
+// attachLogs refreshes the logs of the receipts with the ones emitted by the
+// engine while finalizing the block, numbering them in the block again.
+func attachLogs(state *state.StateDB, receipts []*types.Receipt) {
+	var index uint
+	for _, receipt := range receipts {
+		receipt.Logs = state.GetLogs(receipt.TxHash)
+		for _, log := range receipt.Logs {
+			log.Index = index
+			index++
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	}
+}
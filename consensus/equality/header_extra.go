@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"fmt"
 	"io"
+	"math/big"
 	"strings"
 
 	"github.com/SecretBlockChain/go-secret/common"
@@ -50,6 +51,317 @@ type HeaderExtra struct {
 	CurrentBlockCancelCandidates  []common.Address
 	CurrentEpochValidators        []common.Address
 	ChainConfig                   []params.EqualityConfig
+	CurrentBlockExpiredCandidates []common.Address
+	CurrentBlockSignerKeys        []SignerKey    // Signing keys bound to candidates by the transactions of the block
+	CurrentEpochSignerKeys        []SignerKey    // Signing keys of the validators of the epoch, only in the first block
+	CurrentBlockPayouts           []Payout       // Reward recipients set by the transactions of the block
+	RewardRecipient               common.Address // Payout credited with the rewards of the block, zero for the coinbase
+	CurrentBlockProposals         []Proposal     // Config proposals submitted by the transactions of the block
+	CurrentBlockVotes             []Vote         // Votes cast on the proposals by the transactions of the block
+	ExecutedProposals             []common.Hash  // Approved proposals activated by the block, halts as soon as approved, configs in the first block of an epoch
+	RefundedProposals             []common.Hash  // Approved proposals left pending whose deposit the first block of an epoch refunds
+}
+
+// SignerKey is a signing key bound to the identity of a candidate, sealing the
+// blocks of its slots instead of the candidate's own key.
+type SignerKey struct {
+	Validator common.Address
+	Key       common.Address
+}
+
+// Payout is the address credited with the rewards of a candidate instead of
+// the coinbase of its blocks.
+type Payout struct {
+	Candidate common.Address
+	Recipient common.Address
+}
+
+// Proposal is a change of the chain config, a halt of the chain, a protocol fork,
+// a non-binding signal or a spending of the pool submitted to the vote of the
+// validators, identified by the hash of the transaction submitting it.
+type Proposal struct {
+	ID        common.Hash
+	Proposer  common.Address
+	Config    params.EqualityConfig
+	Halt      uint64         // Height after which no block is minted, 0 for other proposals
+	Fork      string         // Protocol fork scheduled, empty for other proposals
+	ForkBlock uint64         // Activation block of the fork
+	Signal    common.Hash    // Hash of the text of a signal proposal, empty for other proposals
+	Recipient common.Address // Recipient of a spending of the pool
+	Amount    *big.Int       // Amount spent from the pool, nil for other proposals
+}
+
+// Vote is the approval or rejection of a proposal by a validator.
+type Vote struct {
+	ID        common.Hash
+	Validator common.Address
+	Approve   bool
+}
+
+// headerExtraRLP is the encoding of HeaderExtra. The trailing list holds the
+// expired candidates followed by the signing keys and the payouts, all omitted
+// if empty so that older headers keep decoding and encoding to the same bytes.
+type headerExtraRLP struct {
+	Root                          Root
+	Epoch                         uint64
+	EpochBlock                    uint64
+	CurrentBlockCandidates        []common.Address
+	CurrentBlockKickOutCandidates []common.Address
+	CurrentBlockCancelCandidates  []common.Address
+	CurrentEpochValidators        []common.Address
+	ChainConfig                   []params.EqualityConfig
+	Tail                          []rlp.RawValue `rlp:"tail"`
+}
+
+// Kinds of the entries of the trailing list of the header extra following the
+// expired candidates.
+const (
+	tailBlockSignerKey   uint8 = iota // Signing key bound by the block
+	tailEpochSignerKey                // Signing key of a validator of the epoch
+	tailBlockPayout                   // Reward recipient set by the block
+	tailRewardRecipient               // Reward recipient of the block
+	tailProposal                      // Config proposal submitted by the block
+	tailVote                          // Vote on a proposal cast by the block
+	tailExecutedProposal              // Proposal activated by the block
+	tailHaltProposal                  // Halt proposal submitted by the block
+	tailForkProposal                  // Fork proposal submitted by the block
+	tailSignalProposal                // Signal proposal submitted by the block
+	tailSpendProposal                 // Pool spending proposal submitted by the block
+	tailRefundedProposal              // Approved proposal left pending whose deposit the block refunds
+)
+
+// tailEntryRLP is the encoding of a signing key, a payout or a governance action
+// in the trailing list of the header extra.
+type tailEntryRLP struct {
+	Kind    uint8
+	Account common.Address // Validator, candidate or proposer
+	Address common.Address // Signing key or recipient
+	Extra   []rlp.RawValue `rlp:"tail"` // Proposal ID followed by the config, the halt height, the fork or the vote
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (headerExtra HeaderExtra) EncodeRLP(w io.Writer) error {
+	enc := headerExtraRLP{
+		Root:                          headerExtra.Root,
+		Epoch:                         headerExtra.Epoch,
+		EpochBlock:                    headerExtra.EpochBlock,
+		CurrentBlockCandidates:        headerExtra.CurrentBlockCandidates,
+		CurrentBlockKickOutCandidates: headerExtra.CurrentBlockKickOutCandidates,
+		CurrentBlockCancelCandidates:  headerExtra.CurrentBlockCancelCandidates,
+		CurrentEpochValidators:        headerExtra.CurrentEpochValidators,
+		ChainConfig:                   headerExtra.ChainConfig,
+	}
+	for _, candidate := range headerExtra.CurrentBlockExpiredCandidates {
+		raw, err := rlp.EncodeToBytes(candidate)
+		if err != nil {
+			return err
+		}
+		enc.Tail = append(enc.Tail, raw)
+	}
+	var entries []tailEntryRLP
+	for _, key := range headerExtra.CurrentBlockSignerKeys {
+		entries = append(entries, tailEntryRLP{Kind: tailBlockSignerKey, Account: key.Validator, Address: key.Key})
+	}
+	for _, key := range headerExtra.CurrentEpochSignerKeys {
+		entries = append(entries, tailEntryRLP{Kind: tailEpochSignerKey, Account: key.Validator, Address: key.Key})
+	}
+	for _, payout := range headerExtra.CurrentBlockPayouts {
+		entries = append(entries, tailEntryRLP{Kind: tailBlockPayout, Account: payout.Candidate, Address: payout.Recipient})
+	}
+	if headerExtra.RewardRecipient != (common.Address{}) {
+		entries = append(entries, tailEntryRLP{Kind: tailRewardRecipient, Address: headerExtra.RewardRecipient})
+	}
+	for _, proposal := range headerExtra.CurrentBlockProposals {
+		if proposal.Amount != nil {
+			extra, err := encodeTailExtra(proposal.ID, proposal.Recipient, proposal.Amount)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, tailEntryRLP{Kind: tailSpendProposal, Account: proposal.Proposer, Extra: extra})
+			continue
+		}
+		if proposal.Signal != (common.Hash{}) {
+			extra, err := encodeTailExtra(proposal.ID, proposal.Signal)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, tailEntryRLP{Kind: tailSignalProposal, Account: proposal.Proposer, Extra: extra})
+			continue
+		}
+		if proposal.Fork != "" {
+			extra, err := encodeTailExtra(proposal.ID, proposal.Fork, proposal.ForkBlock)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, tailEntryRLP{Kind: tailForkProposal, Account: proposal.Proposer, Extra: extra})
+			continue
+		}
+		if proposal.Halt != 0 {
+			extra, err := encodeTailExtra(proposal.ID, proposal.Halt)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, tailEntryRLP{Kind: tailHaltProposal, Account: proposal.Proposer, Extra: extra})
+			continue
+		}
+		extra, err := encodeTailExtra(proposal.ID, proposal.Config)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, tailEntryRLP{Kind: tailProposal, Account: proposal.Proposer, Extra: extra})
+	}
+	for _, vote := range headerExtra.CurrentBlockVotes {
+		extra, err := encodeTailExtra(vote.ID, vote.Approve)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, tailEntryRLP{Kind: tailVote, Account: vote.Validator, Extra: extra})
+	}
+	for _, id := range headerExtra.ExecutedProposals {
+		extra, err := encodeTailExtra(id)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, tailEntryRLP{Kind: tailExecutedProposal, Extra: extra})
+	}
+	for _, id := range headerExtra.RefundedProposals {
+		extra, err := encodeTailExtra(id)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, tailEntryRLP{Kind: tailRefundedProposal, Extra: extra})
+	}
+	for _, entry := range entries {
+		raw, err := rlp.EncodeToBytes(entry)
+		if err != nil {
+			return err
+		}
+		enc.Tail = append(enc.Tail, raw)
+	}
+	return rlp.Encode(w, &enc)
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (headerExtra *HeaderExtra) DecodeRLP(s *rlp.Stream) error {
+	var dec headerExtraRLP
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	*headerExtra = HeaderExtra{
+		Root:                          dec.Root,
+		Epoch:                         dec.Epoch,
+		EpochBlock:                    dec.EpochBlock,
+		CurrentBlockCandidates:        dec.CurrentBlockCandidates,
+		CurrentBlockKickOutCandidates: dec.CurrentBlockKickOutCandidates,
+		CurrentBlockCancelCandidates:  dec.CurrentBlockCancelCandidates,
+		CurrentEpochValidators:        dec.CurrentEpochValidators,
+		ChainConfig:                   dec.ChainConfig,
+	}
+	for _, raw := range dec.Tail {
+		kind, _, _, err := rlp.Split(raw)
+		if err != nil {
+			return err
+		}
+		if kind != rlp.List {
+			var candidate common.Address
+			if err := rlp.DecodeBytes(raw, &candidate); err != nil {
+				return err
+			}
+			headerExtra.CurrentBlockExpiredCandidates = append(headerExtra.CurrentBlockExpiredCandidates, candidate)
+			continue
+		}
+		var entry tailEntryRLP
+		if err := rlp.DecodeBytes(raw, &entry); err != nil {
+			return err
+		}
+		switch entry.Kind {
+		case tailBlockSignerKey:
+			headerExtra.CurrentBlockSignerKeys = append(headerExtra.CurrentBlockSignerKeys, SignerKey{Validator: entry.Account, Key: entry.Address})
+		case tailEpochSignerKey:
+			headerExtra.CurrentEpochSignerKeys = append(headerExtra.CurrentEpochSignerKeys, SignerKey{Validator: entry.Account, Key: entry.Address})
+		case tailBlockPayout:
+			headerExtra.CurrentBlockPayouts = append(headerExtra.CurrentBlockPayouts, Payout{Candidate: entry.Account, Recipient: entry.Address})
+		case tailRewardRecipient:
+			headerExtra.RewardRecipient = entry.Address
+		case tailProposal:
+			proposal := Proposal{Proposer: entry.Account}
+			if err := decodeTailExtra(entry.Extra, &proposal.ID, &proposal.Config); err != nil {
+				return err
+			}
+			headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, proposal)
+		case tailHaltProposal:
+			proposal := Proposal{Proposer: entry.Account}
+			if err := decodeTailExtra(entry.Extra, &proposal.ID, &proposal.Halt); err != nil {
+				return err
+			}
+			headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, proposal)
+		case tailForkProposal:
+			proposal := Proposal{Proposer: entry.Account}
+			if err := decodeTailExtra(entry.Extra, &proposal.ID, &proposal.Fork, &proposal.ForkBlock); err != nil {
+				return err
+			}
+			headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, proposal)
+		case tailSpendProposal:
+			proposal := Proposal{Proposer: entry.Account}
+			if err := decodeTailExtra(entry.Extra, &proposal.ID, &proposal.Recipient, &proposal.Amount); err != nil {
+				return err
+			}
+			headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, proposal)
+		case tailSignalProposal:
+			proposal := Proposal{Proposer: entry.Account}
+			if err := decodeTailExtra(entry.Extra, &proposal.ID, &proposal.Signal); err != nil {
+				return err
+			}
+			headerExtra.CurrentBlockProposals = append(headerExtra.CurrentBlockProposals, proposal)
+		case tailVote:
+			vote := Vote{Validator: entry.Account}
+			if err := decodeTailExtra(entry.Extra, &vote.ID, &vote.Approve); err != nil {
+				return err
+			}
+			headerExtra.CurrentBlockVotes = append(headerExtra.CurrentBlockVotes, vote)
+		case tailExecutedProposal:
+			var id common.Hash
+			if err := decodeTailExtra(entry.Extra, &id); err != nil {
+				return err
+			}
+			headerExtra.ExecutedProposals = append(headerExtra.ExecutedProposals, id)
+		case tailRefundedProposal:
+			var id common.Hash
+			if err := decodeTailExtra(entry.Extra, &id); err != nil {
+				return err
+			}
+			headerExtra.RefundedProposals = append(headerExtra.RefundedProposals, id)
+		default:
+			return fmt.Errorf("unknown header extra entry %d", entry.Kind)
+		}
+	}
+	return nil
+}
+
+// encodeTailExtra encodes the values carried by an entry of the trailing list.
+func encodeTailExtra(values ...interface{}) ([]rlp.RawValue, error) {
+	extra := make([]rlp.RawValue, 0, len(values))
+	for _, value := range values {
+		raw, err := rlp.EncodeToBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		extra = append(extra, raw)
+	}
+	return extra, nil
+}
+
+// decodeTailExtra decodes the values carried by an entry of the trailing list.
+func decodeTailExtra(extra []rlp.RawValue, values ...interface{}) error {
+	if len(extra) != len(values) {
+		return fmt.Errorf("invalid header extra entry, %d values instead of %d", len(extra), len(values))
+	}
+	for i, value := range values {
+		if err := rlp.DecodeBytes(extra[i], value); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // NewHeaderExtra new HeaderExtra from rlp bytes.
@@ -103,6 +415,9 @@ func (headerExtra HeaderExtra) Equal(other HeaderExtra) bool {
 	if headerExtra.Epoch != other.Epoch {
 		return false
 	}
+	if headerExtra.RewardRecipient != other.RewardRecipient {
+		return false
+	}
 	if headerExtra.EpochBlock != other.EpochBlock {
 		return false
 	}
@@ -143,6 +458,71 @@ func (headerExtra HeaderExtra) Equal(other HeaderExtra) bool {
 		}
 	}
 
+	if len(headerExtra.CurrentBlockExpiredCandidates) != len(other.CurrentBlockExpiredCandidates) {
+		return false
+	}
+	for idx, candidate := range headerExtra.CurrentBlockExpiredCandidates {
+		if candidate != other.CurrentBlockExpiredCandidates[idx] {
+			return false
+		}
+	}
+
+	if !signerKeysEqual(headerExtra.CurrentBlockSignerKeys, other.CurrentBlockSignerKeys) {
+		return false
+	}
+	if !signerKeysEqual(headerExtra.CurrentEpochSignerKeys, other.CurrentEpochSignerKeys) {
+		return false
+	}
+
+	if len(headerExtra.CurrentBlockPayouts) != len(other.CurrentBlockPayouts) {
+		return false
+	}
+	for idx, payout := range headerExtra.CurrentBlockPayouts {
+		if payout != other.CurrentBlockPayouts[idx] {
+			return false
+		}
+	}
+
+	if len(headerExtra.CurrentBlockProposals) != len(other.CurrentBlockProposals) {
+		return false
+	}
+	for idx, proposal := range headerExtra.CurrentBlockProposals {
+		otherProposal := other.CurrentBlockProposals[idx]
+		if proposal.ID != otherProposal.ID || proposal.Proposer != otherProposal.Proposer || proposal.Halt != otherProposal.Halt ||
+			proposal.Fork != otherProposal.Fork || proposal.ForkBlock != otherProposal.ForkBlock || proposal.Signal != otherProposal.Signal ||
+			proposal.Recipient != otherProposal.Recipient || (proposal.Amount == nil) != (otherProposal.Amount == nil) ||
+			(proposal.Amount != nil && proposal.Amount.Cmp(otherProposal.Amount) != 0) || !proposal.Config.Equal(otherProposal.Config) {
+			return false
+		}
+	}
+
+	if len(headerExtra.CurrentBlockVotes) != len(other.CurrentBlockVotes) {
+		return false
+	}
+	for idx, vote := range headerExtra.CurrentBlockVotes {
+		if vote != other.CurrentBlockVotes[idx] {
+			return false
+		}
+	}
+
+	if len(headerExtra.ExecutedProposals) != len(other.ExecutedProposals) {
+		return false
+	}
+	for idx, id := range headerExtra.ExecutedProposals {
+		if id != other.ExecutedProposals[idx] {
+			return false
+		}
+	}
+
+	if len(headerExtra.RefundedProposals) != len(other.RefundedProposals) {
+		return false
+	}
+	for idx, id := range headerExtra.RefundedProposals {
+		if id != other.RefundedProposals[idx] {
+			return false
+		}
+	}
+
 	if len(headerExtra.CurrentEpochValidators) != len(other.CurrentEpochValidators) {
 		return false
 	}
@@ -154,6 +534,36 @@ func (headerExtra HeaderExtra) Equal(other HeaderExtra) bool {
 	return true
 }
 
+// rewardRecipient returns the address credited with the rewards of the block.
+func (headerExtra HeaderExtra) rewardRecipient(header *types.Header) common.Address {
+	if headerExtra.RewardRecipient != (common.Address{}) {
+		return headerExtra.RewardRecipient
+	}
+	return header.Coinbase
+}
+
+// effectiveConfig returns the chain config in force after the block, the last
+// one it records if it changes the given config of its parent.
+func (headerExtra HeaderExtra) effectiveConfig(config params.EqualityConfig) params.EqualityConfig {
+	if n := len(headerExtra.ChainConfig); n > 0 {
+		return headerExtra.ChainConfig[n-1]
+	}
+	return config
+}
+
+// signerKeysEqual compares two lists of signing keys for equality.
+func signerKeysEqual(keys, other []SignerKey) bool {
+	if len(keys) != len(other) {
+		return false
+	}
+	for idx, key := range keys {
+		if key != other[idx] {
+			return false
+		}
+	}
+	return true
+}
+
 func DecodeHeaderExtra(header *types.Header) (HeaderExtra, error) {
 	headerExtra := header.Extra
 	if len(headerExtra) < extraVanity {
@@ -175,6 +585,26 @@ func addressesExist(slice []common.Address, addr common.Address) bool {
 	return false
 }
 
+// Returns whether a hash exists in the hash list.
+func hashesExist(slice []common.Hash, hash common.Hash) bool {
+	for _, item := range slice {
+		if item == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns whether a proposal of the given ID exists in the proposal list.
+func proposalsExist(slice []Proposal, id common.Hash) bool {
+	for _, proposal := range slice {
+		if proposal.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // Ensure each element of an common.Address slice are not the same.
 func addressesDistinct(slice []common.Address) []common.Address {
 	if len(slice) <= 1 {
@@ -1,11 +1,14 @@
 package equality
 
 import (
+	"math/big"
 	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rlp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -34,6 +37,52 @@ func TestEncodeHeaderExtra(t *testing.T) {
 	assert.Equal(t, newHeaderExtra.CurrentBlockCandidates, headerExtra.CurrentBlockCandidates)
 }
 
+func TestEncodeHeaderExtraTail(t *testing.T) {
+	address1 := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	address2 := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	headerExtra := HeaderExtra{
+		Epoch:                         2,
+		CurrentBlockExpiredCandidates: []common.Address{address1},
+		CurrentBlockSignerKeys:        []SignerKey{{Validator: address1, Key: address2}},
+		CurrentEpochSignerKeys:        []SignerKey{{Validator: address2, Key: address1}},
+		CurrentBlockPayouts:           []Payout{{Candidate: address2, Recipient: address1}},
+		RewardRecipient:               address2,
+		RefundedProposals:             []common.Hash{common.HexToHash("0x01")},
+	}
+	data, err := headerExtra.Encode()
+	assert.Nil(t, err)
+	decoded, err := NewHeaderExtra(data)
+	assert.Nil(t, err)
+	assert.True(t, headerExtra.Equal(decoded))
+	assert.Equal(t, headerExtra.CurrentBlockExpiredCandidates, decoded.CurrentBlockExpiredCandidates)
+	assert.Equal(t, headerExtra.CurrentBlockSignerKeys, decoded.CurrentBlockSignerKeys)
+	assert.Equal(t, headerExtra.CurrentEpochSignerKeys, decoded.CurrentEpochSignerKeys)
+	assert.Equal(t, headerExtra.CurrentBlockPayouts, decoded.CurrentBlockPayouts)
+	assert.Equal(t, headerExtra.RewardRecipient, decoded.RewardRecipient)
+	assert.Equal(t, headerExtra.RefundedProposals, decoded.RefundedProposals)
+
+	// Without signing keys the encoding is the one of the trailing expired candidates
+	type tailHeaderExtra struct {
+		Root                          Root
+		Epoch                         uint64
+		EpochBlock                    uint64
+		CurrentBlockCandidates        []common.Address
+		CurrentBlockKickOutCandidates []common.Address
+		CurrentBlockCancelCandidates  []common.Address
+		CurrentEpochValidators        []common.Address
+		ChainConfig                   []params.EqualityConfig
+		CurrentBlockExpiredCandidates []common.Address `rlp:"tail"`
+	}
+	headerExtra.CurrentBlockSignerKeys, headerExtra.CurrentEpochSignerKeys = nil, nil
+	headerExtra.CurrentBlockPayouts, headerExtra.RewardRecipient = nil, common.Address{}
+	headerExtra.RefundedProposals = nil
+	enc, err := rlp.EncodeToBytes(headerExtra)
+	assert.Nil(t, err)
+	tail, err := rlp.EncodeToBytes(tailHeaderExtra{Epoch: 2, CurrentBlockExpiredCandidates: []common.Address{address1}})
+	assert.Nil(t, err)
+	assert.Equal(t, tail, enc)
+}
+
 func TestHeaderExtraEqual(t *testing.T) {
 	var headerExtra HeaderExtra
 	var otherHeaderExtra HeaderExtra
@@ -55,3 +104,56 @@ func TestHeaderExtraEqual(t *testing.T) {
 	otherHeaderExtra.CurrentEpochValidators = append(otherHeaderExtra.CurrentEpochValidators, headerExtra.CurrentEpochValidators[0])
 	assert.True(t, headerExtra.Equal(otherHeaderExtra))
 }
+
+func TestDecodeLegacyHeaderExtra(t *testing.T) {
+	type legacyConfig struct {
+		Period              uint64
+		Epoch               uint64
+		MaxValidatorsCount  uint64
+		MinCandidateBalance *big.Int
+		GenesisTimestamp    uint64
+		Validators          []common.Address
+		Pool                common.Address
+		Rewards             params.EqualityRewards
+	}
+	type legacyHeaderExtra struct {
+		Root                          Root
+		Epoch                         uint64
+		EpochBlock                    uint64
+		CurrentBlockCandidates        []common.Address
+		CurrentBlockKickOutCandidates []common.Address
+		CurrentBlockCancelCandidates  []common.Address
+		CurrentEpochValidators        []common.Address
+		ChainConfig                   []legacyConfig
+	}
+	address := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	legacy := legacyHeaderExtra{
+		Epoch:                  1,
+		EpochBlock:             1,
+		CurrentEpochValidators: []common.Address{address},
+		ChainConfig:            []legacyConfig{{Epoch: 100, MinCandidateBalance: big.NewInt(1000)}},
+	}
+	data, err := rlp.EncodeToBytes(legacy)
+	assert.Nil(t, err)
+
+	// Headers without the new fields must decode and re-encode identically
+	var headerExtra HeaderExtra
+	assert.Nil(t, rlp.DecodeBytes(data, &headerExtra))
+	assert.Equal(t, uint64(100), headerExtra.ChainConfig[0].Epoch)
+	assert.Equal(t, uint64(0), headerExtra.ChainConfig[0].CandidateExpiry)
+	assert.Equal(t, 0, len(headerExtra.CurrentBlockExpiredCandidates))
+	encoded, err := rlp.EncodeToBytes(headerExtra)
+	assert.Nil(t, err)
+	assert.Equal(t, data, encoded)
+
+	headerExtra.ChainConfig[0].CandidateExpiry = 4
+	headerExtra.ChainConfig[0].ProposalDeposit, _ = new(big.Int).SetString("100000000000000000000", 10)
+	headerExtra.CurrentBlockExpiredCandidates = []common.Address{address}
+	data, err = headerExtra.Encode()
+	assert.Nil(t, err)
+	decoded, err := NewHeaderExtra(data)
+	assert.Nil(t, err)
+	assert.True(t, headerExtra.Equal(decoded))
+	assert.Equal(t, uint64(4), decoded.ChainConfig[0].CandidateExpiry)
+	assert.Equal(t, headerExtra.ChainConfig[0].ProposalDeposit, decoded.ChainConfig[0].ProposalDeposit)
+}
@@ -0,0 +1,138 @@
+package equality
+
+import (
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// SetHeaderOnly switches the engine to the relaxed verification of full nodes
+// not minting blocks. The seals are checked against the validators committed in
+// the epoch headers like light clients do, and the balance changes of the
+// candidates are taken from the header extras instead of replaying the blocks
+// against the candidate and mint count tries, which are not built at all. The
+// roots committed in the headers are thus trusted rather than verified.
+func (e *Equality) SetHeaderOnly() {
+	e.SetLightMode()
+	e.headerOnly = true
+}
+
+// finalizeHeaderOnly is the header-only counterpart of Finalize, applying the
+// balance changes recorded in the header extra. It returns false, leaving the
+// state untouched, if the header doesn't settle them and the block has to be
+// replayed against the snapshot of its parent.
+func (e *Equality) finalizeHeaderOnly(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB,
+	headerExtra HeaderExtra, parentRoot Root, txs []*types.Transaction) bool {
+
+	number := header.Number.Uint64()
+	config := e.lightConfig(number)
+
+	// Governance actions move deposits and emit logs the header doesn't settle
+	if len(headerExtra.CurrentBlockProposals) > 0 || len(headerExtra.CurrentBlockVotes) > 0 || len(headerExtra.ExecutedProposals) > 0 ||
+		len(headerExtra.RefundedProposals) > 0 {
+		return false
+	}
+	// The deposits of the candidates written to the staking contract aren't
+	// recorded in the headers either
+	if _, ok := e.config.SystemContract("staking"); ok && number == headerExtra.EpochBlock {
+		return false
+	}
+	// Nor are the deposits escrowed by the staking contract, which the candidates
+	// registered twice in the block get back
+	if chain.Config().IsStaking(header.Number) {
+		for _, tx := range txs {
+			if len(stakingLogs(state, tx)) > 0 {
+				return false
+			}
+		}
+	}
+
+	// Candidates registering and canceling in the same block only show in one of
+	// the lists, depending on the order of the transactions
+	registered := make(map[common.Address]bool)
+	canceled := make(map[common.Address]bool)
+	for _, tx := range txs {
+		ctx, err := NewTransaction(tx)
+		if err != nil {
+			continue
+		}
+		switch event := ctx.(type) {
+		case *EventBecomeCandidate:
+			registered[event.Candidate] = true
+		case *EventCancelCandidate:
+			canceled[event.Delegator] = true
+		}
+	}
+	for candidate := range registered {
+		if canceled[candidate] {
+			return false
+		}
+	}
+
+	// Look up the deposits refunded before touching the state
+	var (
+		refunded []common.Address
+		refunds  []*big.Int
+	)
+	for _, candidate := range append(append([]common.Address{}, headerExtra.CurrentBlockCancelCandidates...), headerExtra.CurrentBlockExpiredCandidates...) {
+		security, ok := e.headerStake(chain, config, candidate, number)
+		if !ok {
+			return false
+		}
+		refunded, refunds = append(refunded, candidate), append(refunds, security)
+	}
+
+	recipient := header.Coinbase
+	if chain.Config().IsPayout(header.Number) {
+		recipient = headerExtra.rewardRecipient(header)
+	}
+	transfers := e.accumulateRewards(config, state, header, recipient)
+	if chain.Config().IsRewardLog(header.Number) {
+		logRewardPaid(state, header, config, txs, transfers)
+	}
+	if number == headerExtra.EpochBlock {
+		e.updateSystemContracts(headerExtra.effectiveConfig(config), state, headerExtra.Epoch, headerExtra.CurrentEpochValidators, nil)
+	}
+	for _, candidate := range headerExtra.CurrentBlockCandidates {
+		state.SubBalance(candidate, config.MinCandidateBalance)
+	}
+	for i, candidate := range refunded {
+		state.AddBalance(candidate, refunds[i])
+	}
+	e.writeCandidateHistory(chain, config, header, headerExtra, parentRoot)
+	return true
+}
+
+// headerStake returns the security deposit of a candidate leaving in the given
+// block, derived from the candidate history instead of the snapshot. Candidates
+// registering after the first equality block all stake the minimum balance,
+// while the genesis validators stake nothing until they register again. The
+// boolean is false if the history doesn't tell, e.g. after a fast sync.
+func (e *Equality) headerStake(chain consensus.ChainHeaderReader, config params.EqualityConfig,
+	candidate common.Address, number uint64) (*big.Int, bool) {
+
+	if !addressesExist(e.config.Validators, candidate) {
+		return config.MinCandidateBalance, true
+	}
+	genesis := false
+	for _, event := range rawdb.ReadCandidateEvents(e.db, candidate) {
+		if event.Kind != candidateEventRegister || event.Number >= number || !canonicalCandidateEvent(chain, candidate, event) {
+			continue
+		}
+		if event.Number > e.start {
+			return config.MinCandidateBalance, true
+		}
+		genesis = true
+	}
+	if !genesis {
+		log.Debug("[equality] Deposit of genesis validator unknown", "candidate", candidate, "number", number)
+		return nil, false
+	}
+	return big.NewInt(0), true
+}
@@ -0,0 +1,105 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderStake(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	validator := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	candidate := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	config := params.EqualityConfig{MinCandidateBalance: big.NewInt(1000), Validators: []common.Address{validator}}
+	engine := New(&config, db)
+
+	chain := &testChainReader{headers: []*types.Header{
+		{Number: big.NewInt(0)},
+		newTestHeader(t, 1, HeaderExtra{Epoch: 1, EpochBlock: 1, CurrentBlockCandidates: []common.Address{validator}}),
+		newTestHeader(t, 2, HeaderExtra{Epoch: 1, EpochBlock: 1, CurrentBlockCancelCandidates: []common.Address{validator}}),
+		newTestHeader(t, 3, HeaderExtra{Epoch: 1, EpochBlock: 1, CurrentBlockCandidates: []common.Address{validator}}),
+	}}
+
+	// Candidates other than the genesis validators always staked the minimum
+	security, ok := engine.headerStake(chain, config, candidate, 10)
+	assert.True(t, ok)
+	assert.Equal(t, config.MinCandidateBalance, security)
+
+	// Genesis validators are only settled by the history
+	_, ok = engine.headerStake(chain, config, validator, 2)
+	assert.False(t, ok)
+
+	rawdb.WriteCandidateEvent(db, validator, rawdb.CandidateEvent{Kind: candidateEventRegister, Number: 1, Epoch: 1})
+	security, ok = engine.headerStake(chain, config, validator, 2)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), security.Int64())
+
+	// Registering again stakes the minimum, unless the block was reorganised away
+	rawdb.WriteCandidateEvent(db, validator, rawdb.CandidateEvent{Kind: candidateEventRegister, Number: 2, Epoch: 1, Staked: config.MinCandidateBalance})
+	security, _ = engine.headerStake(chain, config, validator, 4)
+	assert.Equal(t, int64(0), security.Int64())
+
+	rawdb.WriteCandidateEvent(db, validator, rawdb.CandidateEvent{Kind: candidateEventRegister, Number: 3, Epoch: 1, Staked: config.MinCandidateBalance})
+	security, _ = engine.headerStake(chain, config, validator, 4)
+	assert.Equal(t, config.MinCandidateBalance, security)
+}
+
+func TestFinalizeHeaderOnly(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	candidate := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	canceled := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	config := params.EqualityConfig{MinCandidateBalance: big.NewInt(1000)}
+	engine := New(&config, db)
+	engine.SetHeaderOnly()
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	assert.Nil(t, err)
+	statedb.AddBalance(candidate, big.NewInt(5000))
+
+	headerExtra := HeaderExtra{
+		Epoch:                        1,
+		EpochBlock:                   1,
+		CurrentBlockCandidates:       []common.Address{candidate},
+		CurrentBlockCancelCandidates: []common.Address{canceled},
+	}
+	header := newTestHeader(t, 2, headerExtra)
+	chain := &testChainReader{headers: []*types.Header{{Number: big.NewInt(0)}, newTestHeader(t, 1, HeaderExtra{Epoch: 1, EpochBlock: 1})}}
+
+	// The deposits are taken from the header extra
+	assert.True(t, engine.finalizeHeaderOnly(chain, header, statedb, headerExtra, Root{}, nil))
+	assert.Equal(t, big.NewInt(4000), statedb.GetBalance(candidate))
+	assert.Equal(t, config.MinCandidateBalance, statedb.GetBalance(canceled))
+	assert.Equal(t, 1, len(rawdb.ReadCandidateEvents(db, candidate)))
+
+	// Registering and canceling in the same block is left to the replay
+	register := types.NewTransaction(0, candidate, new(big.Int), 0, new(big.Int), EncodeTransaction(new(EventBecomeCandidate)))
+	cancel := types.NewTransaction(1, candidate, new(big.Int), 0, new(big.Int), EncodeTransaction(new(EventCancelCandidate)))
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	register, _ = types.SignTx(register, signer, testUserKey)
+	cancel, _ = types.SignTx(cancel, signer, testUserKey)
+	assert.False(t, engine.finalizeHeaderOnly(chain, header, statedb, headerExtra, Root{}, []*types.Transaction{register, cancel}))
+	assert.Equal(t, big.NewInt(4000), statedb.GetBalance(candidate))
+
+	// So are the epoch blocks writing the deposits into the staking contract
+	config.SystemContracts = []params.EqualitySystemContract{{Name: "staking"}}
+	epochExtra := HeaderExtra{Epoch: 2, EpochBlock: 2}
+	assert.False(t, engine.finalizeHeaderOnly(chain, newTestHeader(t, 2, epochExtra), statedb, epochExtra, Root{}, nil))
+	assert.Equal(t, big.NewInt(4000), statedb.GetBalance(candidate))
+
+	// And the blocks calling the staking contract
+	chainConfig := *params.TestChainConfig
+	chainConfig.StakingBlock = big.NewInt(0)
+	chain.config = &chainConfig
+	call := types.NewTransaction(2, params.StakingContractAddress, big.NewInt(1000), 100000, new(big.Int), nil)
+	statedb.Prepare(call.Hash(), common.Hash{}, 0)
+	statedb.AddLog(&types.Log{Address: params.StakingContractAddress, Topics: []common.Hash{vm.CandidateRegisteredTopic, common.BytesToHash(candidate.Bytes())}})
+	assert.False(t, engine.finalizeHeaderOnly(chain, header, statedb, headerExtra, Root{}, []*types.Transaction{call}))
+	assert.Equal(t, big.NewInt(4000), statedb.GetBalance(candidate))
+}
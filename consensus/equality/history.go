@@ -0,0 +1,293 @@
+package equality
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rlp"
+)
+
+// slotDelayLimit is the largest delay between a slot and the import of its block
+// indexed as a propagation delay, the blocks imported later being synced.
+const slotDelayLimit = time.Minute
+
+// Kinds of candidate lifecycle events kept in the history index.
+const (
+	candidateEventRegister uint8 = iota // Address became a candidate
+	candidateEventCancel                // Candidate canceled its candidacy
+	candidateEventKickOut               // Candidate was kicked out for not minting enough blocks
+	candidateEventElected               // Candidate was elected as validator of an epoch
+	candidateEventExpired               // Candidate was canceled after being dormant for too long
+)
+
+// candidateEventNames maps the kind of candidate event to a readable name.
+var candidateEventNames = map[uint8]string{
+	candidateEventRegister: "register",
+	candidateEventCancel:   "cancel",
+	candidateEventKickOut:  "kickout",
+	candidateEventElected:  "elected",
+	candidateEventExpired:  "expired",
+}
+
+// writeCandidateHistory records the candidate lifecycle events carried by the
+// header into the history index. The parent root is used to look up the security
+// deposit of canceled or kicked out candidates, the history itself in header-only
+// mode where the snapshot of the parent isn't built.
+func (e *Equality) writeCandidateHistory(chain consensus.ChainHeaderReader, config params.EqualityConfig, header *types.Header,
+	headerExtra HeaderExtra, parentRoot Root) {
+
+	number := header.Number.Uint64()
+	batch := e.db.NewBatch()
+	write := func(address common.Address, kind uint8, staked *big.Int) {
+		rawdb.WriteCandidateEvent(batch, address, rawdb.CandidateEvent{
+			Kind:   kind,
+			Number: number,
+			Epoch:  headerExtra.Epoch,
+			Staked: staked,
+		})
+	}
+
+	security := big.NewInt(0)
+	if number > e.start {
+		security = config.MinCandidateBalance
+	}
+	for _, candidate := range headerExtra.CurrentBlockCandidates {
+		write(candidate, candidateEventRegister, security)
+	}
+
+	var parent *Snapshot
+	staked := func(address common.Address) *big.Int {
+		if parent == nil {
+			parent, _ = e.openSnapshot(parentRoot)
+		}
+		candidate, err := parent.GetCandidate(address)
+		if err != nil && e.headerOnly {
+			if security, ok := e.headerStake(chain, config, address, number); ok {
+				return security
+			}
+		}
+		if err != nil || candidate == nil {
+			return big.NewInt(0)
+		}
+		return candidate.Staked
+	}
+	for _, candidate := range headerExtra.CurrentBlockCancelCandidates {
+		write(candidate, candidateEventCancel, staked(candidate))
+	}
+	for _, candidate := range headerExtra.CurrentBlockKickOutCandidates {
+		write(candidate, candidateEventKickOut, staked(candidate))
+	}
+	for _, candidate := range headerExtra.CurrentBlockExpiredCandidates {
+		write(candidate, candidateEventExpired, staked(candidate))
+	}
+	if len(headerExtra.CurrentBlockKickOutCandidates) > 0 {
+		// The parent snapshot still holds the validators of the previous epoch
+		minted := make(map[common.Address]uint64)
+		if counts, err := parent.CountMinted(headerExtra.Epoch - 1); err == nil {
+			for _, count := range counts {
+				minted[count.Address] = count.Weight.Uint64()
+			}
+		}
+		for _, candidate := range headerExtra.CurrentBlockKickOutCandidates {
+			rawdb.WriteKickOutEvent(batch, headerExtra.Epoch, rawdb.KickOutEvent{
+				Address:   candidate,
+				Number:    number,
+				MintCount: minted[candidate],
+				Threshold: kickOutThreshold(config),
+			})
+		}
+	}
+
+	if number == headerExtra.EpochBlock {
+		for _, validator := range headerExtra.CurrentEpochValidators {
+			write(validator, candidateEventElected, nil)
+		}
+		e.writeEpochStats(chain, config, header, headerExtra.Epoch)
+	}
+
+	if err := batch.Write(); err != nil {
+		log.Warn("[equality] Failed to write candidate history", "number", number, "reason", err)
+	}
+}
+
+// writeSlotHistory records into the slot index the slot filled by the header and
+// the ones left empty since its parent. The time elapsed since the slot is kept
+// as the propagation delay of the block, unless the block is too old to have been
+// propagated rather than synced.
+func (e *Equality) writeSlotHistory(parent, header *types.Header, headerExtra HeaderExtra) {
+	var (
+		config     = *e.config
+		validators []common.Address // The slots before the first block of the engine aren't scheduled
+		err        error
+	)
+	if parent.Number.Uint64() >= e.start {
+		if validators, config, err = e.scheduledValidators(parent); err != nil {
+			return
+		}
+	}
+	if config.Period == 0 || header.Time < config.GenesisTimestamp {
+		return
+	}
+	signer, err := ecrecover(header, e.signatures)
+	if err != nil {
+		return
+	}
+	var (
+		number = header.Number.Uint64()
+		hash   = header.Hash()
+		slot   = (header.Time - config.GenesisTimestamp) / config.Period
+		delay  uint64
+	)
+	if elapsed := time.Since(time.Unix(int64(header.Time), 0)); elapsed > 0 && elapsed < slotDelayLimit {
+		delay = uint64(elapsed / time.Millisecond)
+	}
+	batch := e.db.NewBatch()
+	rawdb.WriteSlotRecord(batch, signer, rawdb.SlotRecord{Epoch: headerExtra.Epoch, Slot: slot, Number: number, Hash: hash, Minted: true, Delay: delay})
+
+	missed := missedSlots(config, validators, parent.Time, header.Time)
+	for i, validator := range missed {
+		rawdb.WriteSlotRecord(batch, validator, rawdb.SlotRecord{Epoch: headerExtra.Epoch, Slot: slot - uint64(len(missed)-i), Number: number, Hash: hash})
+	}
+	if err := batch.Write(); err != nil {
+		log.Warn("[equality] Failed to write slot history", "number", number, "reason", err)
+	}
+}
+
+// writeProposalHistory records into the proposal index the proposals executed or
+// expired by the header. Their votes being pruned along, the tally is counted
+// over the snapshot of the parent and the votes of the block itself.
+func (e *Equality) writeProposalHistory(config params.EqualityConfig, header *types.Header, headerExtra HeaderExtra, parentRoot Root) {
+	number := header.Number.Uint64()
+	epochBlock := config.ProposalWindow > 0 && number == headerExtra.EpochBlock
+	if number <= e.start || (len(headerExtra.ExecutedProposals) == 0 && !epochBlock) {
+		return
+	}
+	parent, err := e.openSnapshot(parentRoot)
+	if err != nil {
+		return
+	}
+	var expired []common.Hash
+	if epochBlock {
+		if expired, err = parent.ExpiredProposals(number); err != nil {
+			return
+		}
+	}
+	if len(headerExtra.ExecutedProposals) == 0 && len(expired) == 0 {
+		return
+	}
+	snap, err := e.openSnapshot(headerExtra.Root)
+	if err != nil {
+		return
+	}
+	validators, err := parent.GetValidators()
+	if err != nil {
+		return
+	}
+	votes := make(map[common.Hash]map[common.Address]bool)
+	for _, vote := range headerExtra.CurrentBlockVotes {
+		if votes[vote.ID] == nil {
+			votes[vote.ID] = make(map[common.Address]bool)
+		}
+		votes[vote.ID][vote.Validator] = vote.Approve
+	}
+
+	batch := e.db.NewBatch()
+	write := func(id common.Hash, proposal *ProposalRecord, executed bool) {
+		if proposal == nil {
+			return
+		}
+		tally, err := tallyVotes(config, proposal, validators, func(validator common.Address) (bool, bool, error) {
+			if approve, ok := votes[id][validator]; ok {
+				return true, approve, nil
+			}
+			return parent.GetVote(id, validator)
+		})
+		if err != nil {
+			return
+		}
+		record, err := rlp.EncodeToBytes(proposal)
+		if err != nil {
+			return
+		}
+		rawdb.WriteProposalOutcome(batch, rawdb.ProposalOutcome{
+			ID:         id,
+			Number:     number,
+			Executed:   executed,
+			Approvals:  uint64(tally.Approvals),
+			Rejections: uint64(tally.Rejections),
+			Validators: uint64(tally.Validators),
+			Quorum:     uint64(tally.Quorum),
+			Record:     record,
+		})
+	}
+	for _, id := range headerExtra.ExecutedProposals {
+		proposal, _ := snap.GetProposal(id)
+		write(id, proposal, true)
+	}
+	for _, id := range expired {
+		proposal, _ := parent.GetProposal(id)
+		write(id, proposal, false)
+	}
+	if err := batch.Write(); err != nil {
+		log.Warn("[equality] Failed to write proposal history", "number", number, "reason", err)
+	}
+}
+
+// canonicalProposalOutcome reports whether the indexed outcome is the one of the
+// canonical chain: the proposal was submitted by the canonical block at its
+// height and, if executed, by the canonical block the outcome was indexed at.
+func canonicalProposalOutcome(chain consensus.ChainHeaderReader, outcome rawdb.ProposalOutcome, proposal *ProposalRecord) bool {
+	submitted := chain.GetHeaderByNumber(proposal.Number)
+	if submitted == nil {
+		return false
+	}
+	headerExtra, err := DecodeHeaderExtra(submitted)
+	if err != nil || !proposalsExist(headerExtra.CurrentBlockProposals, outcome.ID) {
+		return false
+	}
+	closed := chain.GetHeaderByNumber(outcome.Number)
+	if closed == nil {
+		return false
+	}
+	if headerExtra, err = DecodeHeaderExtra(closed); err != nil {
+		return false
+	}
+	if outcome.Executed {
+		return hashesExist(headerExtra.ExecutedProposals, outcome.ID)
+	}
+	return headerExtra.EpochBlock == outcome.Number
+}
+
+// canonicalCandidateEvent reports whether the indexed event is carried by the
+// canonical block at its height. Events of blocks that were reorganised away
+// remain in the index and must be filtered out.
+func canonicalCandidateEvent(chain consensus.ChainHeaderReader, address common.Address, event rawdb.CandidateEvent) bool {
+	header := chain.GetHeaderByNumber(event.Number)
+	if header == nil {
+		return false
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return false
+	}
+
+	switch event.Kind {
+	case candidateEventRegister:
+		return addressesExist(headerExtra.CurrentBlockCandidates, address)
+	case candidateEventCancel:
+		return addressesExist(headerExtra.CurrentBlockCancelCandidates, address)
+	case candidateEventKickOut:
+		return addressesExist(headerExtra.CurrentBlockKickOutCandidates, address)
+	case candidateEventExpired:
+		return addressesExist(headerExtra.CurrentBlockExpiredCandidates, address)
+	case candidateEventElected:
+		return headerExtra.EpochBlock == event.Number && addressesExist(headerExtra.CurrentEpochValidators, address)
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCandidateHistory(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	equality := New(&params.EqualityConfig{MinCandidateBalance: big.NewInt(1000)}, db)
+	config := *equality.config
+
+	address1 := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	address2 := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+
+	snap, err := newSnapshot(db)
+	assert.Nil(t, err)
+	_, err = snap.BecomeCandidate(address2, 2, config.MinCandidateBalance)
+	assert.Nil(t, err)
+	parentRoot, err := snap.Root()
+	assert.Nil(t, err)
+	assert.Nil(t, snap.Commit(parentRoot))
+
+	header := &types.Header{Number: big.NewInt(10)}
+	headerExtra := HeaderExtra{
+		Epoch:                        2,
+		EpochBlock:                   10,
+		CurrentBlockCandidates:       []common.Address{address1},
+		CurrentBlockCancelCandidates: []common.Address{address2},
+		CurrentEpochValidators:       []common.Address{address1},
+	}
+	equality.writeCandidateHistory(&testChainReader{}, config, header, headerExtra, parentRoot)
+
+	events := rawdb.ReadCandidateEvents(db, address1)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, candidateEventRegister, events[0].Kind)
+	assert.Equal(t, uint64(10), events[0].Number)
+	assert.Equal(t, uint64(2), events[0].Epoch)
+	assert.Equal(t, config.MinCandidateBalance, events[0].Staked)
+	assert.Equal(t, candidateEventElected, events[1].Kind)
+
+	events = rawdb.ReadCandidateEvents(db, address2)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, candidateEventCancel, events[0].Kind)
+	assert.Equal(t, config.MinCandidateBalance, events[0].Staked)
+}
+
+func TestAssembleSkipsCandidateHistory(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	events := rawdb.ReadCandidateEvents(sealer.db, testUserAddress)
+	assert.NotEmpty(t, events)
+
+	// Minted blocks may never be imported, only importing them records the history
+	for i := 0; i < int(config.Epoch); i++ {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		mintTestBlock(t, sealer, chain, statedb, nil)
+	}
+	assert.Equal(t, events, rawdb.ReadCandidateEvents(sealer.db, testUserAddress))
+}
+
+func TestWriteKickOutHistory(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	equality := New(&params.EqualityConfig{Epoch: 100, MaxValidatorsCount: 5, MinCandidateBalance: big.NewInt(1000)}, db)
+	config := *equality.config
+
+	address1 := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	address2 := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+
+	snap, err := newSnapshot(db)
+	assert.Nil(t, err)
+	assert.Nil(t, snap.SetValidators([]common.Address{address1, address2}))
+	for _, address := range []common.Address{address1, address2} {
+		_, err = snap.BecomeCandidate(address, 2, config.MinCandidateBalance)
+		assert.Nil(t, err)
+	}
+	for number := uint64(100); number < 103; number++ {
+		assert.Nil(t, snap.MintBlock(1, number, address2))
+	}
+	assert.Nil(t, snap.MintBlock(1, 103, address1))
+	parentRoot, err := snap.Root()
+	assert.Nil(t, err)
+	assert.Nil(t, snap.Commit(parentRoot))
+
+	header := &types.Header{Number: big.NewInt(200)}
+	headerExtra := HeaderExtra{
+		Epoch:                         2,
+		EpochBlock:                    200,
+		CurrentBlockKickOutCandidates: []common.Address{address1},
+	}
+	equality.writeCandidateHistory(&testChainReader{}, config, header, headerExtra, parentRoot)
+
+	events := rawdb.ReadKickOutEvents(db, 2)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, address1, events[0].Address)
+	assert.Equal(t, uint64(200), events[0].Number)
+	assert.Equal(t, uint64(1), events[0].MintCount)
+	assert.Equal(t, uint64(10), events[0].Threshold)
+	assert.Equal(t, 0, len(rawdb.ReadKickOutEvents(db, 1)))
+}
+
+func TestValidatorPerformance(t *testing.T) {
+	config := testSnapshotConfig()
+	_, chain := makeSnapshotChain(t, &config)
+	head := chain.headers[len(chain.headers)-1]
+	headExtra, err := DecodeHeaderExtra(head)
+	assert.Nil(t, err)
+
+	// Verifying the blocks indexes the slots of the validators
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	assert.Nil(t, engine.EnsureSnapshot(chain, head))
+
+	api := &API{chain: chain, equality: engine}
+	first := uint64(1)
+	performance, err := api.GetValidatorPerformance(testUserAddress, &first, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, headExtra.Epoch, performance.ToEpoch)
+	assert.Equal(t, uint64(len(chain.headers)-1), performance.Produced)
+	assert.True(t, performance.Expected >= performance.Produced)
+	assert.Equal(t, int(headExtra.Epoch), len(performance.Epochs))
+
+	// Slots of blocks reorganised away are ignored
+	rawdb.WriteSlotRecord(engine.db, testUserAddress, rawdb.SlotRecord{Epoch: 1, Slot: 1 << 40, Number: 1, Hash: common.Hash{0x01}})
+	again, err := api.GetValidatorPerformance(testUserAddress, &first, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, performance.Expected, again.Expected)
+
+	// Inverted ranges are rejected
+	_, err = api.GetValidatorPerformance(testUserAddress, &headExtra.Epoch, &first)
+	assert.NotNil(t, err)
+}
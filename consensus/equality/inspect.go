@@ -0,0 +1,133 @@
+package equality
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/ethdb"
+	"github.com/SecretBlockChain/go-secret/ethdb/memorydb"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rlp"
+	"github.com/SecretBlockChain/go-secret/trie"
+)
+
+// SnapshotInspection is the content of a snapshot read straight from the database,
+// every epoch of the mint count trie included.
+type SnapshotInspection struct {
+	Root        Root                                 `json:"root"`
+	Validators  []common.Address                     `json:"validators"`
+	LastElected map[common.Address]uint64            `json:"lastElected"`
+	Candidates  map[common.Address]Candidate         `json:"candidates"`
+	MintCounts  map[uint64]map[common.Address]uint64 `json:"mintCounts"`
+	Config      *params.EqualityConfig               `json:"config"`
+}
+
+// InspectSnapshot loads the snapshot with the given roots from the database of
+// a stopped node, without the engine nor the chain, and decodes all its tries.
+func InspectSnapshot(db ethdb.Database, root Root) (*SnapshotInspection, error) {
+	snap, err := loadSnapshot(db, root)
+	if err != nil {
+		return nil, err
+	}
+	inspection := &SnapshotInspection{
+		Root:        root,
+		LastElected: make(map[common.Address]uint64),
+		MintCounts:  make(map[uint64]map[common.Address]uint64),
+	}
+	// The epoch trie holds the validators and the last elections of the candidates
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return nil, err
+	}
+	it := trie.NewIterator(epochTrie.NodeIterator(nil))
+	for it.Next() {
+		key := bytes.TrimPrefix(it.Key, epochPrefix)
+		switch {
+		case bytes.Equal(key, []byte("validator")):
+			if err := rlp.DecodeBytes(it.Value, &inspection.Validators); err != nil {
+				return nil, err
+			}
+		case bytes.HasPrefix(key, []byte("elected-")):
+			inspection.LastElected[common.BytesToAddress(key)] = binary.BigEndian.Uint64(it.Value)
+		}
+	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
+	if inspection.Candidates, err = snap.GetCandidates(); err != nil {
+		return nil, err
+	}
+	// The mint count trie records the validator of every block, keyed by epoch
+	mintCntTrie, err := snap.ensureTrie(mintCntPrefix)
+	if err != nil {
+		return nil, err
+	}
+	it = trie.NewIterator(mintCntTrie.NodeIterator(nil))
+	for it.Next() {
+		key := bytes.TrimPrefix(it.Key, mintCntPrefix)
+		if len(key) != 16 {
+			continue
+		}
+		epoch := binary.BigEndian.Uint64(key[:8])
+		if inspection.MintCounts[epoch] == nil {
+			inspection.MintCounts[epoch] = make(map[common.Address]uint64)
+		}
+		inspection.MintCounts[epoch][common.BytesToAddress(it.Value)]++
+	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
+	if root.ConfigHash != (common.Hash{}) {
+		config, err := snap.GetChainConfig()
+		if err != nil {
+			return nil, err
+		}
+		inspection.Config = &config
+	}
+	return inspection, nil
+}
+
+// VerifySnapshot walks the tries of the snapshot with the given roots, returning
+// the number of trie nodes found in the database, or the first missing or
+// corrupt one.
+func VerifySnapshot(db ethdb.Database, root Root) (int, error) {
+	hashes := root.hashes()
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+	// Schedule the nodes like a sync against an empty database, so that every
+	// node is checked before being decoded: the tries panic on corrupt ones
+	scratch := memorydb.New()
+	bloom := trie.NewSyncBloom(1, scratch)
+	defer bloom.Close()
+
+	sched := trie.NewSync(hashes[0], scratch, nil, bloom)
+	for _, hash := range hashes[1:] {
+		sched.AddSubTrie(hash, nil, common.Hash{}, nil)
+	}
+	nodes := 0
+	for sched.Pending() > 0 {
+		missing, _, _ := sched.Missing(0)
+		for _, hash := range missing {
+			blob, err := db.Get(hash.Bytes())
+			if err != nil {
+				return nodes, &trie.MissingNodeError{NodeHash: hash}
+			}
+			if crypto.Keccak256Hash(blob) != hash {
+				return nodes, fmt.Errorf("corrupt trie node %x", hash)
+			}
+			if err := sched.Process(trie.SyncResult{Hash: hash, Data: blob}); err != nil {
+				return nodes, err
+			}
+			nodes++
+		}
+		// The nodes are only flushed to be released from the scheduler
+		if err := sched.Commit(scratch.NewBatch()); err != nil {
+			return nodes, err
+		}
+	}
+	return nodes, nil
+}
@@ -0,0 +1,52 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectSnapshot(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	snap, err := newSnapshot(db)
+	assert.Nil(t, err)
+
+	validator := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	candidate := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	config := params.EqualityConfig{Period: 3, Epoch: 100, MinCandidateBalance: big.NewInt(1000)}
+
+	assert.Nil(t, snap.SetChainConfig(config))
+	assert.Nil(t, snap.SetValidators([]common.Address{validator}))
+	assert.Nil(t, snap.SetLastElected([]common.Address{validator}, 1))
+	_, err = snap.BecomeCandidate(candidate, 2, big.NewInt(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, snap.MintBlock(1, 1, validator))
+	assert.Nil(t, snap.MintBlock(1, 2, validator))
+	assert.Nil(t, snap.MintBlock(2, 3, validator))
+
+	root, err := snap.Root()
+	assert.Nil(t, err)
+	assert.Nil(t, snap.Commit(root))
+
+	inspection, err := InspectSnapshot(db, root)
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Address{validator}, inspection.Validators)
+	assert.Equal(t, uint64(1), inspection.LastElected[validator])
+	assert.Equal(t, uint64(2), inspection.Candidates[candidate].BlockNumber)
+	assert.Equal(t, uint64(2), inspection.MintCounts[1][validator])
+	assert.Equal(t, uint64(1), inspection.MintCounts[2][validator])
+	assert.Equal(t, config, *inspection.Config)
+
+	nodes, err := VerifySnapshot(db, root)
+	assert.Nil(t, err)
+	assert.True(t, nodes >= len(root.hashes()))
+
+	// Dropping a root node is reported as missing
+	assert.Nil(t, db.Delete(root.CandidateHash[:]))
+	_, err = VerifySnapshot(db, root)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,273 @@
+// +build integration
+
+package equality_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/accounts/keystore"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/math"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/eth"
+	"github.com/SecretBlockChain/go-secret/eth/downloader"
+	"github.com/SecretBlockChain/go-secret/ethclient"
+	"github.com/SecretBlockChain/go-secret/miner"
+	"github.com/SecretBlockChain/go-secret/node"
+	"github.com/SecretBlockChain/go-secret/p2p"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// Run with: go test -tags integration -run TestIntegration ./consensus/equality
+
+var (
+	integrationPool  = common.HexToAddress("0x53d77827bE168aB2a911B5A14D0f16D1C5657196")
+	integrationEther = big.NewInt(params.Ether)
+)
+
+// makeIntegrationGenesis creates an equality genesis with the sealers as genesis
+// validators, 4 blocks long epochs and the faucets funded.
+func makeIntegrationGenesis(sealers, faucets []*ecdsa.PrivateKey) *core.Genesis {
+	config := *params.TestnetChainConfig
+	config.ChainID = big.NewInt(18)
+	config.Equality = &params.EqualityConfig{
+		Period:              1,
+		Epoch:               4,
+		MaxValidatorsCount:  3,
+		MinCandidateBalance: new(big.Int).Set(integrationEther),
+		GenesisTimestamp:    uint64(time.Now().Unix()),
+		Pool:                integrationPool,
+		Rewards:             params.EqualityRewards{{Number: 1000000, Reward: new(big.Int).Set(integrationEther)}},
+	}
+	for _, sealer := range sealers {
+		config.Equality.Validators = append(config.Equality.Validators, crypto.PubkeyToAddress(sealer.PublicKey))
+	}
+
+	alloc := core.GenesisAlloc{}
+	for _, faucet := range faucets {
+		alloc[crypto.PubkeyToAddress(faucet.PublicKey)] = core.GenesisAccount{
+			Balance: new(big.Int).Mul(big.NewInt(100), integrationEther),
+		}
+	}
+	return &core.Genesis{
+		Config:     &config,
+		Timestamp:  config.Equality.GenesisTimestamp,
+		ExtraData:  make([]byte, 32+crypto.SignatureLength),
+		GasLimit:   25000000,
+		Difficulty: big.NewInt(1),
+		Alloc:      alloc,
+	}
+}
+
+// makeIntegrationSealer boots a full node on the genesis, sealing with the key.
+func makeIntegrationSealer(t *testing.T, genesis *core.Genesis, key *ecdsa.PrivateKey) (*node.Node, *eth.Ethereum) {
+	datadir, err := ioutil.TempDir("", "equality-integration-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack, err := node.New(&node.Config{
+		Name:    "secret",
+		Version: params.Version,
+		DataDir: datadir,
+		P2P: p2p.Config{
+			ListenAddr:  "127.0.0.1:0",
+			NoDiscovery: true,
+			MaxPeers:    25,
+		},
+		NoUSB: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend, err := eth.New(stack, &eth.Config{
+		Genesis:         genesis,
+		NetworkId:       genesis.Config.ChainID.Uint64(),
+		SyncMode:        downloader.FullSync,
+		DatabaseCache:   64,
+		DatabaseHandles: 64,
+		TxPool:          core.DefaultTxPoolConfig,
+		GPO:             eth.DefaultConfig.GPO,
+		Miner: miner.Config{
+			GasFloor: genesis.GasLimit * 9 / 10,
+			GasCeil:  genesis.GasLimit * 11 / 10,
+			GasPrice: big.NewInt(1),
+			Recommit: time.Second,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	store := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, err := store.ImportECDSA(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Unlock(account, ""); err != nil {
+		t.Fatal(err)
+	}
+	backend.SetEtherbase(account.Address)
+	return stack, backend
+}
+
+// waitFor polls the condition until it holds or the timeout expires.
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+type integrationAddress struct {
+	Address common.Address `json:"address"`
+}
+
+// containsAddress queries an eq_ method listing addresses and checks membership.
+func containsAddress(t *testing.T, client *rpc.Client, method string, address common.Address) bool {
+	var result []integrationAddress
+	if err := client.Call(&result, method); err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	for _, item := range result {
+		if item.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+// currentEpoch returns the epoch of the head block of the client.
+func currentEpoch(t *testing.T, client *ethclient.Client) uint64 {
+	header, err := client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerExtra, err := equality.DecodeHeaderExtra(header)
+	if err != nil {
+		return 0
+	}
+	return headerExtra.Epoch
+}
+
+func TestIntegration(t *testing.T) {
+	sealers := make([]*ecdsa.PrivateKey, 2)
+	for i := range sealers {
+		sealers[i], _ = crypto.GenerateKey()
+	}
+	candidateKey, _ := crypto.GenerateKey()
+	candidate := crypto.PubkeyToAddress(candidateKey.PublicKey)
+	genesis := makeIntegrationGenesis(sealers, []*ecdsa.PrivateKey{candidateKey})
+
+	// Boot the sealers, interconnect them and start sealing
+	var backends []*eth.Ethereum
+	var stacks []*node.Node
+	for _, sealer := range sealers {
+		stack, backend := makeIntegrationSealer(t, genesis, sealer)
+		defer os.RemoveAll(stack.DataDir())
+		defer stack.Close()
+
+		for _, other := range stacks {
+			stack.Server().AddPeer(other.Server().Self())
+		}
+		stacks, backends = append(stacks, stack), append(backends, backend)
+	}
+	waitFor(t, 10*time.Second, "peers to connect", func() bool { return stacks[0].Server().PeerCount() > 0 })
+	for _, backend := range backends {
+		if err := backend.StartMining(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rpcClient, err := stacks[0].Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcClient.Close()
+	client := ethclient.NewClient(rpcClient)
+	ctx := context.Background()
+
+	// Both sealers must take turns through the first epoch
+	waitFor(t, 30*time.Second, "the second epoch", func() bool { return currentEpoch(t, client) >= 2 })
+	for _, sealer := range sealers {
+		assert.True(t, containsAddress(t, rpcClient, "eq_getValidators", crypto.PubkeyToAddress(sealer.PublicKey)))
+	}
+
+	// Rewards are split between the coinbase and the pool
+	var rewards struct {
+		CoinbaseReward *math.HexOrDecimal256 `json:"coinbaseReward"`
+		PoolReward     *math.HexOrDecimal256 `json:"poolReward"`
+	}
+	assert.Nil(t, rpcClient.Call(&rewards, "eq_getBlockRewards"))
+	assert.Equal(t, new(big.Int).Div(integrationEther, big.NewInt(10)), (*big.Int)(rewards.CoinbaseReward))
+	assert.Equal(t, new(big.Int).Sub(integrationEther, (*big.Int)(rewards.CoinbaseReward)), (*big.Int)(rewards.PoolReward))
+	poolBalance, err := client.BalanceAt(ctx, integrationPool, nil)
+	assert.Nil(t, err)
+	assert.True(t, poolBalance.Sign() > 0)
+
+	// Register a third candidate through the RPC and wait for it to be elected
+	send := func(nonce uint64, data []byte) {
+		tx := types.NewTransaction(nonce, candidate, new(big.Int), 100000, big.NewInt(params.GWei), data)
+		signed, err := types.SignTx(tx, types.NewEIP155Signer(genesis.Config.ChainID), candidateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.SendTransaction(ctx, signed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send(0, equality.EncodeTransaction(new(equality.EventBecomeCandidate)))
+	waitFor(t, 30*time.Second, "candidate registration", func() bool {
+		return containsAddress(t, rpcClient, "eq_getCandidates", candidate)
+	})
+	waitFor(t, 30*time.Second, "candidate election", func() bool {
+		return containsAddress(t, rpcClient, "eq_getValidators", candidate)
+	})
+
+	// Cancel the candidacy and wait for it to rotate out of the validators
+	send(1, equality.EncodeTransaction(new(equality.EventCancelCandidate)))
+	waitFor(t, 30*time.Second, "candidate cancellation", func() bool {
+		return !containsAddress(t, rpcClient, "eq_getCandidates", candidate)
+	})
+	waitFor(t, 30*time.Second, "validator rotation", func() bool {
+		return !containsAddress(t, rpcClient, "eq_getValidators", candidate)
+	})
+
+	// The candidate history reflects the whole lifecycle
+	var history struct {
+		Events []struct {
+			Event string `json:"event"`
+		} `json:"events"`
+		EpochsServed int `json:"epochsServed"`
+	}
+	assert.Nil(t, rpcClient.Call(&history, "eq_getCandidateHistory", candidate))
+	var events []string
+	for _, event := range history.Events {
+		events = append(events, event.Event)
+	}
+	assert.Contains(t, events, "register")
+	assert.Contains(t, events, "elected")
+	assert.Contains(t, events, "cancel")
+	assert.True(t, history.EpochsServed > 0)
+
+	// The deposit was refunded on cancellation, minus the gas spent
+	balance, err := client.BalanceAt(ctx, candidate, nil)
+	assert.Nil(t, err)
+	assert.True(t, balance.Cmp(new(big.Int).Mul(big.NewInt(99), integrationEther)) > 0)
+}
@@ -0,0 +1,159 @@
+package equality
+
+import (
+	"errors"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/misc"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// errUnknownEpoch is returned if the header of the epoch a block belongs to is
+// not available, which happens for the blocks following a light client checkpoint.
+var errUnknownEpoch = errors.New("unknown epoch header")
+
+// SetLightMode switches the engine to verifying headers without the snapshot
+// tries, which light clients don't have. The seals are checked against the
+// validators committed in the header of the epoch instead.
+func (e *Equality) SetLightMode() {
+	e.lightValidators, _ = lru.NewARC(inMemorySignatures)
+}
+
+// lightMode returns if the engine verifies headers only.
+func (e *Equality) lightMode() bool {
+	return e.lightValidators != nil
+}
+
+// lightConfig returns the config governing the given block, built from the
+// genesis config and the overrides recorded before the block. The configs
+// activated by proposals are left out, which is fine for the period and the
+// epoch length as proposals can't change them.
+func (e *Equality) lightConfig(number uint64) params.EqualityConfig {
+	config := *e.config
+	for _, override := range e.config.Overrides {
+		if override.Block < number {
+			config = override.Apply(config)
+		}
+	}
+	return config
+}
+
+// verifyLightFields is the light counterpart of verifyCascadingFields, checking
+// the continuity of the epochs and the seal of the header.
+func (e *Equality) verifyLightFields(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	parent := lightParent(chain, header, parents)
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time > header.Time {
+		return ErrInvalidTimestamp
+	}
+	if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
+
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return err
+	}
+	if number > e.start {
+		parentHeaderExtra, err := DecodeHeaderExtra(parent)
+		if err != nil {
+			return err
+		}
+		if headerExtra.Epoch != parentHeaderExtra.Epoch || headerExtra.EpochBlock != parentHeaderExtra.EpochBlock {
+			if headerExtra.Epoch != parentHeaderExtra.Epoch+1 || headerExtra.EpochBlock != number {
+				return ErrInvalidTimestamp
+			}
+		}
+	}
+	return e.verifyLightSeal(chain, header, parent, parents)
+}
+
+// verifyLightSeal checks that the header is signed by the validator in turn
+// among the ones committed in the header of the epoch of the parent.
+func (e *Equality) verifyLightSeal(chain consensus.ChainHeaderReader, header, parent *types.Header, parents []*types.Header) error {
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	validators, err := e.epochValidators(chain, parent, parents)
+	if err == errUnknownEpoch {
+		// The checkpoint the light client started from vouches for the header
+		log.Debug("[equality] Epoch header unknown, seal not verified", "number", header.Number)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	signer, err := ecrecover(header, e.signatures)
+	if err != nil {
+		return err
+	}
+	if !isInTurn(e.lightConfig(header.Number.Uint64()), validators, header.Time, signer) {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// epochValidators returns the validators allowed to mint the children of the
+// header, walking back the headers to the last epoch header. The validators
+// sealing with a bound signing key are replaced by their key.
+func (e *Equality) epochValidators(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) ([]common.Address, error) {
+	var (
+		validators []common.Address
+		walked     []common.Hash
+	)
+	for {
+		if header.Number.Uint64() < e.start {
+			validators = e.config.Validators
+			break
+		}
+		if cached, ok := e.lightValidators.Get(header.Hash()); ok {
+			validators = cached.([]common.Address)
+			break
+		}
+		headerExtra, err := DecodeHeaderExtra(header)
+		if err != nil {
+			return nil, err
+		}
+		if header.Number.Uint64() == headerExtra.EpochBlock {
+			validators = signingKeys(headerExtra.CurrentEpochValidators, headerExtra.CurrentEpochSignerKeys)
+			e.lightValidators.Add(header.Hash(), validators)
+			break
+		}
+		walked = append(walked, header.Hash())
+		if header = lightParent(chain, header, parents); header == nil {
+			return nil, errUnknownEpoch
+		}
+	}
+	for _, hash := range walked {
+		e.lightValidators.Add(hash, validators)
+	}
+	return validators, nil
+}
+
+// lightParent returns the parent of the header, looking it up in the batch of
+// headers being verified first.
+func lightParent(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) *types.Header {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	if len(parents) > 0 {
+		first := parents[0].Number.Uint64()
+		if number > first && number-1-first < uint64(len(parents)) {
+			if parent := parents[number-1-first]; parent.Hash() == header.ParentHash {
+				return parent
+			}
+		}
+	}
+	return chain.GetHeader(header.ParentHash, number-1)
+}
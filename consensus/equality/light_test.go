@@ -0,0 +1,71 @@
+package equality
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLightVerification(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 2)
+	validators := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		validators[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	config := params.EqualityConfig{
+		Period:              1,
+		Epoch:               100,
+		MaxValidatorsCount:  21,
+		MinCandidateBalance: big.NewInt(1),
+		Validators:          validators,
+	}
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	engine.SetLightMode()
+
+	// Block 1 opens the first epoch, the validators take turns every second
+	seal := func(header *types.Header, key *ecdsa.PrivateKey) {
+		header.UncleHash = uncleHash
+		signature, err := crypto.Sign(SealHash(header).Bytes(), key)
+		assert.Nil(t, err)
+		copy(header.Extra[len(header.Extra)-extraSeal:], signature)
+	}
+	headers := []*types.Header{{Number: big.NewInt(0), UncleHash: uncleHash}}
+	for number := uint64(1); number <= 4; number++ {
+		headerExtra := HeaderExtra{Epoch: 1, EpochBlock: 1}
+		if number == 1 {
+			headerExtra.CurrentEpochValidators = validators
+		}
+		header := newTestHeader(t, number, headerExtra)
+		header.ParentHash = headers[number-1].Hash()
+		header.Time = number
+		seal(header, keys[number%2])
+		headers = append(headers, header)
+	}
+	chain := &testChainReader{headers: headers[:1]}
+	_, results := engine.VerifyHeaders(chain, headers[1:], make([]bool, 4))
+	for range headers[1:] {
+		assert.Nil(t, <-results)
+	}
+
+	// Headers signed out of turn are rejected
+	forged := newTestHeader(t, 3, HeaderExtra{Epoch: 1, EpochBlock: 1})
+	forged.ParentHash = headers[2].Hash()
+	forged.Time = 3
+	seal(forged, keys[0])
+	chain = &testChainReader{headers: headers[:3]}
+	assert.Equal(t, errUnauthorized, engine.VerifyHeader(chain, forged, true))
+
+	// Without the epoch header, e.g. after a checkpoint, only the continuity is checked
+	engine = New(&config, rawdb.NewMemoryDatabase())
+	engine.SetLightMode()
+	chain = &testChainReader{headers: []*types.Header{headers[0], nil, headers[2]}}
+	assert.Nil(t, engine.VerifyHeader(chain, forged, true))
+}
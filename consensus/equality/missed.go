@@ -0,0 +1,175 @@
+package equality
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/metrics"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+var (
+	missedSlotsMeter      = metrics.NewRegisteredMeter("equality/slots/missed", nil)
+	localMissedSlotsMeter = metrics.NewRegisteredMeter("equality/slots/missed/local", nil)
+	mintedSlotsMeter      = metrics.NewRegisteredMeter("equality/slots/minted", nil)
+
+	// slotOffsetHistogram tracks the milliseconds between the scheduled time of a
+	// slot and the import of its block, drifting clocks and slow validators show
+	// up as a growing offset long before slots are missed.
+	slotOffsetHistogram = metrics.NewRegisteredHistogram("equality/slots/offset", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// validatorStats counts the slots a validator filled or left empty since the
+// node started tracking them.
+type validatorStats struct {
+	Minted     uint64
+	Missed     uint64
+	LastMissed uint64 // Number of the block following the last slot missed
+}
+
+// slotTracker accumulates the statistics of the validators as blocks are imported.
+type slotTracker struct {
+	stats map[common.Address]*validatorStats
+	lock  sync.Mutex
+}
+
+// newSlotTracker creates an empty tracker of the validator slots.
+func newSlotTracker() *slotTracker {
+	return &slotTracker{stats: make(map[common.Address]*validatorStats)}
+}
+
+// validator returns the statistics of a validator, the lock being held.
+func (t *slotTracker) validator(address common.Address) *validatorStats {
+	stats, ok := t.stats[address]
+	if !ok {
+		stats = new(validatorStats)
+		t.stats[address] = stats
+	}
+	return stats
+}
+
+// snapshot returns a copy of the statistics of all the validators tracked.
+func (t *slotTracker) snapshot() map[common.Address]validatorStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	stats := make(map[common.Address]validatorStats, len(t.stats))
+	for address, validator := range t.stats {
+		stats[address] = *validator
+	}
+	return stats
+}
+
+// missedSlots returns the validators scheduled in the slots between two blocks,
+// which they left empty. A gap longer than an epoch is an outage of the network
+// rather than of its validators, only the last epoch of slots is counted.
+func missedSlots(config params.EqualityConfig, validators []common.Address, parentTime, blockTime uint64) []common.Address {
+	if len(validators) == 0 || config.Period == 0 || parentTime < config.GenesisTimestamp || blockTime <= parentTime {
+		return nil
+	}
+	first, last := (parentTime-config.GenesisTimestamp)/config.Period+1, (blockTime-config.GenesisTimestamp)/config.Period
+	if config.Epoch > 0 && last > first+config.Epoch {
+		first = last - config.Epoch
+	}
+	var missed []common.Address
+	for slot := first; slot < last; slot++ {
+		missed = append(missed, validators[slot%uint64(len(validators))])
+	}
+	return missed
+}
+
+// slotOffset returns the time elapsed between the start of the slot a block was
+// scheduled in and the given time, negative if the block arrived early.
+func slotOffset(config params.EqualityConfig, blockTime uint64, now time.Time) time.Duration {
+	scheduled := blockTime
+	if config.Period > 0 && blockTime >= config.GenesisTimestamp {
+		scheduled -= (blockTime - config.GenesisTimestamp) % config.Period
+	}
+	return now.Sub(time.Unix(int64(scheduled), 0))
+}
+
+// TrackSlots compares the schedule of the validators against an imported block,
+// counting the slots left empty since its parent and the one filled by the block.
+// It returns the validators which missed their slot.
+func (e *Equality) TrackSlots(chain consensus.ChainHeaderReader, header *types.Header) []common.Address {
+	number := header.Number.Uint64()
+	if number <= e.start {
+		return nil
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return nil
+	}
+	var (
+		config     params.EqualityConfig
+		validators []common.Address
+		err        error
+	)
+	if e.lightMode() {
+		config = e.lightConfig(number)
+		validators, err = e.epochValidators(chain, parent, nil)
+	} else {
+		validators, config, err = e.scheduledValidators(parent)
+	}
+	if err != nil {
+		log.Debug("[equality] Validator schedule unavailable", "number", number, "err", err)
+		return nil
+	}
+	signer, err := ecrecover(header, e.signatures)
+	if err != nil {
+		return nil
+	}
+	missed := missedSlots(config, validators, parent.Time, header.Time)
+	if offset := slotOffset(config, header.Time, time.Now()); offset > -slotDelayLimit && offset < slotDelayLimit {
+		slotOffsetHistogram.Update(offset.Milliseconds())
+	}
+
+	e.lock.RLock()
+	local := e.signer
+	e.lock.RUnlock()
+
+	e.slots.lock.Lock()
+	defer e.slots.lock.Unlock()
+
+	e.slots.validator(signer).Minted++
+	mintedSlotsMeter.Mark(1)
+	for _, validator := range missed {
+		stats := e.slots.validator(validator)
+		stats.Missed++
+		stats.LastMissed = number
+
+		missedSlotsMeter.Mark(1)
+		metrics.GetOrRegisterCounter("equality/slots/missed/"+validator.Hex(), nil).Inc(1)
+		if validator == local {
+			localMissedSlotsMeter.Mark(1)
+			log.Warn("[equality] Missed own slot", "number", number, "parent", parent.Time, "block", header.Time)
+		} else {
+			log.Info("[equality] Validator missed its slot", "validator", validator, "number", number)
+		}
+	}
+	return missed
+}
+
+// scheduledValidators returns the validators taking turns in the children of the
+// header, along with the config they mint with. The validators sealing with a
+// bound signing key are replaced by their key.
+func (e *Equality) scheduledValidators(header *types.Header) ([]common.Address, params.EqualityConfig, error) {
+	config, err := e.chainConfig(header)
+	if err != nil {
+		return nil, config, err
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return nil, config, err
+	}
+	snap, err := e.openSnapshot(headerExtra.Root)
+	if err != nil {
+		return nil, config, err
+	}
+	validators, err := snap.GetSigners()
+	return validators, config, err
+}
@@ -0,0 +1,69 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissedSlots(t *testing.T) {
+	first := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	second := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	validators := []common.Address{first, second}
+	config := params.EqualityConfig{Period: 5, GenesisTimestamp: 100}
+
+	// Consecutive slots miss nothing, the ones in between are missed in turn
+	assert.Empty(t, missedSlots(config, validators, 100, 105))
+	assert.Equal(t, []common.Address{second, first}, missedSlots(config, validators, 100, 115))
+	assert.Equal(t, []common.Address{second}, missedSlots(config, validators, 100, 114))
+
+	// Blocks out of the schedule are ignored
+	assert.Empty(t, missedSlots(config, validators, 90, 115))
+	assert.Empty(t, missedSlots(config, nil, 100, 115))
+}
+
+func TestSlotOffset(t *testing.T) {
+	config := params.EqualityConfig{Period: 5, GenesisTimestamp: 100}
+
+	assert.Equal(t, 1500*time.Millisecond, slotOffset(config, 105, time.Unix(106, 5e8)))
+	assert.Equal(t, -time.Second, slotOffset(config, 105, time.Unix(104, 0)))
+
+	// Blocks off the slot boundaries are measured from the start of their slot
+	assert.Equal(t, 3*time.Second, slotOffset(config, 107, time.Unix(108, 0)))
+}
+
+func TestTrackSlots(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	parent := chain.headers[len(chain.headers)-1]
+
+	// A block three slots after its parent means two slots were missed
+	header := types.CopyHeader(parent)
+	header.Number = new(big.Int).Add(parent.Number, common.Big1)
+	header.ParentHash = parent.Hash()
+	header.Time = parent.Time + 3*config.Period
+	signature, err := crypto.Sign(SealHash(header).Bytes(), testUserKey)
+	assert.Nil(t, err)
+	copy(header.Extra[len(header.Extra)-extraSeal:], signature)
+
+	missed := sealer.TrackSlots(chain, header)
+	assert.Equal(t, []common.Address{testUserAddress, testUserAddress}, missed)
+
+	stats := sealer.slots.snapshot()[testUserAddress]
+	assert.Equal(t, uint64(1), stats.Minted)
+	assert.Equal(t, uint64(2), stats.Missed)
+	assert.Equal(t, header.Number.Uint64(), stats.LastMissed)
+
+	api := &API{chain: chain, equality: sealer}
+	result, err := api.GetValidatorStats()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.True(t, result[0].IsValidator)
+	assert.Equal(t, uint64(2), result[0].Missed)
+}
@@ -0,0 +1,249 @@
+package equality
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+)
+
+// errEpochNotStarted is returned when looking up the status of an address in an
+// epoch which didn't start before the block.
+var errEpochNotStarted = errors.New("epoch not started")
+
+// consensusReader reads the epoch of a block, the validators sealing it and the
+// candidates of its parent for the consensus precompile and the staking
+// contract, loading them on first use, and the snapshots of the epochs before
+// for the candidate status precompile.
+type consensusReader struct {
+	engine    *Equality
+	header    *types.Header
+	getHeader func(common.Hash, uint64) *types.Header
+
+	loaded     bool
+	err        error
+	epoch      uint64
+	start      uint64
+	validators map[common.Address]bool
+	snap       *Snapshot
+	minBalance *big.Int
+
+	ancestors []*types.Header // Ancestors of the block walked back to the canonical chain
+}
+
+// ConsensusReader returns the reader of the consensus state at the header for
+// the consensus precompile, nil if the header precedes the engine.
+func (e *Equality) ConsensusReader(header *types.Header, getHeader func(common.Hash, uint64) *types.Header) vm.ConsensusReader {
+	if header.Number.Uint64() < e.start {
+		return nil
+	}
+	return &consensusReader{engine: e, header: header, getHeader: getHeader}
+}
+
+// Epoch returns the epoch of the block and the number of its first block.
+func (r *consensusReader) Epoch() (uint64, uint64, error) {
+	if err := r.load(); err != nil {
+		return 0, 0, err
+	}
+	return r.epoch, r.start, nil
+}
+
+// IsValidator returns whether the address is a validator of the parent snapshot,
+// the validators sealing the block.
+func (r *consensusReader) IsValidator(address common.Address) (bool, error) {
+	if err := r.load(); err != nil {
+		return false, err
+	}
+	return r.validators[address], nil
+}
+
+// Candidate returns the deposit of the candidate in the parent snapshot, nil if
+// the address is not a candidate.
+func (r *consensusReader) Candidate(address common.Address) (*big.Int, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	if r.snap == nil {
+		return nil, nil
+	}
+	return candidateDeposit(r.snap, address)
+}
+
+// candidateDeposit returns the deposit of the candidate in the snapshot, nil if
+// the address is not a candidate.
+func candidateDeposit(snap *Snapshot, address common.Address) (*big.Int, error) {
+	candidate, err := snap.GetCandidate(address)
+	if err != nil || candidate == nil {
+		return nil, err
+	}
+	if candidate.Staked == nil {
+		return new(big.Int), nil
+	}
+	return candidate.Staked, nil
+}
+
+// MinCandidateBalance returns the deposit of the candidates in the config the
+// block is minted with.
+func (r *consensusReader) MinCandidateBalance() (*big.Int, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r.minBalance, nil
+}
+
+// EpochStatus returns the first block of the epoch, the deposit of the candidate
+// and whether the address is a validator in the snapshot of that block, the one
+// electing the validators of the epoch.
+func (r *consensusReader) EpochStatus(address common.Address, epoch uint64) (uint64, *big.Int, bool, error) {
+	header, err := r.epochHeader(epoch)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	snap, err := r.engine.openSnapshot(headerExtra.Root)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	staked, err := candidateDeposit(snap, address)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	validators, err := snap.GetValidators()
+	if err != nil {
+		return 0, nil, false, err
+	}
+	for _, validator := range validators {
+		if validator == address {
+			return headerExtra.EpochBlock, staked, true, nil
+		}
+	}
+	return headerExtra.EpochBlock, staked, false, nil
+}
+
+// epochHeader returns the first block of the epoch among the ancestors of the
+// block, bisecting them on their epoch.
+func (r *consensusReader) epochHeader(epoch uint64) (*types.Header, error) {
+	number := r.header.Number.Uint64()
+	if number <= r.engine.start {
+		return nil, errEpochNotStarted
+	}
+	lo, hi := r.engine.start, number-1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		header, err := r.ancestor(mid)
+		if err != nil {
+			return nil, err
+		}
+		headerExtra, err := DecodeHeaderExtra(header)
+		if err != nil {
+			return nil, err
+		}
+		if headerExtra.Epoch < epoch {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	header, err := r.ancestor(lo)
+	if err != nil {
+		return nil, err
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	if headerExtra.Epoch != epoch || headerExtra.EpochBlock != lo {
+		return nil, errEpochNotStarted
+	}
+	return header, nil
+}
+
+// ancestor returns the ancestor of the block at the number. The ancestors are
+// walked back until the canonical chain, the older ones being looked up by
+// number, which spares the walk to the blocks extending the canonical chain.
+func (r *consensusReader) ancestor(number uint64) (*types.Header, error) {
+	parent := r.header.Number.Uint64() - 1
+	if r.ancestors == nil {
+		header := r.getHeader(r.header.ParentHash, parent)
+		for {
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			r.ancestors = append(r.ancestors, header)
+			current := header.Number.Uint64()
+			if current <= r.engine.start || rawdb.ReadCanonicalHash(r.engine.db, current) == header.Hash() {
+				break
+			}
+			header = r.getHeader(header.ParentHash, current-1)
+		}
+	}
+	if index := parent - number; index < uint64(len(r.ancestors)) {
+		return r.ancestors[index], nil
+	}
+	header := r.getHeader(rawdb.ReadCanonicalHash(r.engine.db, number), number)
+	if header == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	return header, nil
+}
+
+// load decodes the epoch from the header and the validators and candidates from
+// the snapshot of its parent, the genesis validators and no candidates for the
+// first block of the engine.
+func (r *consensusReader) load() error {
+	if r.loaded {
+		return r.err
+	}
+	r.loaded = true
+
+	headerExtra, err := DecodeHeaderExtra(r.header)
+	if err != nil {
+		r.err = err
+		return err
+	}
+	r.epoch, r.start = headerExtra.Epoch, headerExtra.EpochBlock
+
+	validators, config := r.engine.config.Validators, *r.engine.config
+	if number := r.header.Number.Uint64(); number > r.engine.start {
+		parent := r.getHeader(r.header.ParentHash, number-1)
+		if parent == nil {
+			r.err = consensus.ErrUnknownAncestor
+			return r.err
+		}
+		parentHeaderExtra, err := DecodeHeaderExtra(parent)
+		if err != nil {
+			r.err = err
+			return err
+		}
+		snap, err := r.engine.openSnapshot(parentHeaderExtra.Root)
+		if err != nil {
+			r.err = err
+			return err
+		}
+		if validators, err = snap.GetValidators(); err != nil {
+			r.err = err
+			return err
+		}
+		if config, err = r.engine.chainConfig(parent); err != nil {
+			r.err = err
+			return err
+		}
+		r.snap = snap
+	}
+	r.minBalance = new(big.Int)
+	if config.MinCandidateBalance != nil {
+		r.minBalance.Set(config.MinCandidateBalance)
+	}
+	r.validators = make(map[common.Address]bool, len(validators))
+	for _, validator := range validators {
+		r.validators[validator] = true
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsensusPrecompile(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	stranger := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+
+	chainConfig := *params.TestChainConfig
+	chainConfig.ConsensusPrecompileBlock = big.NewInt(3)
+	call := func(header *types.Header, address common.Address) ([]byte, error) {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		evm := vm.NewEVM(vm.Context{
+			CanTransfer: func(vm.StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(vm.StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: header.Number,
+			Time:        new(big.Int),
+			Difficulty:  new(big.Int),
+			Consensus:   sealer.ConsensusReader(header, chain.GetHeader),
+		}, statedb, &chainConfig, vm.Config{})
+		output, _, err := evm.Call(vm.AccountRef(stranger), params.ConsensusPrecompileAddress, common.LeftPadBytes(address.Bytes(), 32), 100000, new(big.Int))
+		return output, err
+	}
+	word := func(v uint64) []byte { return common.BigToHash(new(big.Int).SetUint64(v)).Bytes() }
+
+	// Nothing is deployed at the address before the fork
+	output, err := call(chain.headers[2], testUserAddress)
+	assert.Nil(t, err)
+	assert.Empty(t, output)
+
+	for _, header := range chain.headers[3:] {
+		headerExtra, err := DecodeHeaderExtra(header)
+		assert.Nil(t, err)
+		epoch := append(word(headerExtra.Epoch), word(headerExtra.EpochBlock)...)
+
+		output, err := call(header, testUserAddress)
+		assert.Nil(t, err)
+		assert.Equal(t, append(epoch, word(1)...), output)
+
+		output, err = call(header, stranger)
+		assert.Nil(t, err)
+		assert.Equal(t, append(epoch, word(0)...), output)
+	}
+
+	// Headers without consensus data fail the call rather than lie
+	output, err = call(&types.Header{Number: big.NewInt(3)}, testUserAddress)
+	assert.NotNil(t, err)
+	assert.Nil(t, NewFromBlock(&config, rawdb.NewMemoryDatabase(), 4).ConsensusReader(chain.headers[3], chain.GetHeader))
+}
+
+func TestCandidateStatusPrecompile(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	stranger := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+
+	chainConfig := *params.TestChainConfig
+	chainConfig.CandidateStatusBlock = big.NewInt(3)
+	call := func(header *types.Header, address common.Address, epoch uint64) ([]byte, error) {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		evm := vm.NewEVM(vm.Context{
+			CanTransfer: func(vm.StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(vm.StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: header.Number,
+			Time:        new(big.Int),
+			Difficulty:  new(big.Int),
+			Consensus:   sealer.ConsensusReader(header, chain.GetHeader),
+		}, statedb, &chainConfig, vm.Config{})
+		input := append(common.LeftPadBytes(address.Bytes(), 32), common.LeftPadBytes(new(big.Int).SetUint64(epoch).Bytes(), 32)...)
+		output, _, err := evm.Call(vm.AccountRef(stranger), params.CandidateStatusPrecompileAddress, input, 100000, new(big.Int))
+		return output, err
+	}
+	word := func(v uint64) []byte { return common.BigToHash(new(big.Int).SetUint64(v)).Bytes() }
+	status := func(start, candidate, validator, staked uint64) []byte {
+		return append(append(append(word(start), word(candidate)...), word(validator)...), word(staked)...)
+	}
+
+	// Nothing is deployed at the address before the fork
+	output, err := call(chain.headers[2], testUserAddress, 1)
+	assert.Nil(t, err)
+	assert.Empty(t, output)
+
+	// The epochs of the chain start at blocks 1, 3 and 5, the genesis validator
+	// standing as a candidate without any deposit
+	check := func() {
+		head := chain.headers[5]
+		for epoch, start := range map[uint64]uint64{1: 1, 2: 3} {
+			output, err := call(head, testUserAddress, epoch)
+			assert.Nil(t, err)
+			assert.Equal(t, status(start, 1, 1, 0), output)
+
+			output, err = call(head, stranger, epoch)
+			assert.Nil(t, err)
+			assert.Equal(t, status(start, 0, 0, 0), output)
+		}
+
+		// The epoch the block starts is not known yet, nor are the ones to come
+		_, err := call(head, testUserAddress, 3)
+		assert.NotNil(t, err)
+		_, err = call(head, testUserAddress, 0)
+		assert.NotNil(t, err)
+
+		next := &types.Header{Number: big.NewInt(6), ParentHash: head.Hash()}
+		output, err := call(next, testUserAddress, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, status(5, 1, 1, 0), output)
+	}
+	check()
+
+	// The ancestors on the canonical chain are looked up by number alike
+	for _, header := range chain.headers {
+		rawdb.WriteCanonicalHash(sealer.db, header.Hash(), header.Number.Uint64())
+	}
+	check()
+}
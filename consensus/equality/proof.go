@@ -0,0 +1,127 @@
+package equality
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/ethdb/memorydb"
+	"github.com/SecretBlockChain/go-secret/rlp"
+	"github.com/SecretBlockChain/go-secret/trie"
+)
+
+// errEmptyProof is returned if a proof doesn't contain any trie node.
+var errEmptyProof = errors.New("empty proof")
+
+// ValidatorsKey is the key of the validator set in the epoch trie, prefix
+// included, which light clients request the proofs of.
+var ValidatorsKey = append(append([]byte{}, epochPrefix...), "validator"...)
+
+// proofList collects the trie nodes of a merkle proof in order.
+type proofList [][]byte
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, value)
+	return nil
+}
+
+func (n *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// ProveValidators returns the merkle proof of the validator set of the current
+// epoch against the epoch trie root.
+func (snap *Snapshot) ProveValidators() ([][]byte, error) {
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var proof proofList
+	err = epochTrie.Prove([]byte("validator"), 0, &proof)
+	return proof, err
+}
+
+// ProveCandidate returns the merkle proof of the candidate information of the
+// address against the candidate trie root. If the address is not a candidate,
+// the proof proves its absence.
+func (snap *Snapshot) ProveCandidate(candidateAddr common.Address) ([][]byte, error) {
+	candidateTrie, err := snap.ensureTrie(candidatePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var proof proofList
+	err = candidateTrie.Prove(candidateAddr.Bytes(), 0, &proof)
+	return proof, err
+}
+
+// verifyProof checks the merkle proof of the prefixed key against the trie root
+// and returns the proven value, nil if the proof proves absence of the key.
+func verifyProof(root common.Hash, prefix, key []byte, proof [][]byte) ([]byte, error) {
+	if len(proof) == 0 {
+		return nil, errEmptyProof
+	}
+
+	proofDb := memorydb.New()
+	for _, node := range proof {
+		if err := proofDb.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+	return trie.VerifyProof(root, append(append([]byte{}, prefix...), key...), proofDb)
+}
+
+// VerifyValidatorsProof checks the merkle proof of a validator set against the
+// epoch trie root carried in the HeaderExtra of a block, and returns the proven
+// validators of the epoch.
+func VerifyValidatorsProof(root Root, proof [][]byte) ([]common.Address, error) {
+	value, err := verifyProof(root.EpochHash, epochPrefix, []byte("validator"), proof)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return DecodeValidators(value)
+}
+
+// EpochTrieRoot returns the root of the epoch trie committed in the header, the
+// validators of the epoch of the block are proven against.
+func EpochTrieRoot(header *types.Header) (common.Hash, error) {
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return headerExtra.Root.EpochHash, nil
+}
+
+// DecodeValidators decodes the validator set stored in the epoch trie.
+func DecodeValidators(value []byte) ([]common.Address, error) {
+	var validators []common.Address
+	if err := rlp.DecodeBytes(value, &validators); err != nil {
+		return nil, fmt.Errorf("failed to decode validators: %s", err)
+	}
+	return validators, nil
+}
+
+// VerifyCandidateProof checks the merkle proof of a candidate against the
+// candidate trie root carried in the HeaderExtra of a block. It returns the
+// proven candidate information, nil if the address is proven not a candidate.
+func VerifyCandidateProof(root Root, candidateAddr common.Address, proof [][]byte) (*Candidate, error) {
+	value, err := verifyProof(root.CandidateHash, candidatePrefix, candidateAddr.Bytes(), proof)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	var candidate Candidate
+	if err := rlp.DecodeBytes(value, &candidate); err != nil {
+		return nil, fmt.Errorf("failed to decode candidate: %s", err)
+	}
+	return &candidate, nil
+}
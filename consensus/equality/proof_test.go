@@ -0,0 +1,84 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotProof(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	snap, err := newSnapshot(db)
+	assert.Nil(t, err)
+
+	address1 := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	address2 := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	validators := []common.Address{address1, address2}
+	assert.Nil(t, snap.SetValidators(validators))
+	_, err = snap.BecomeCandidate(address1, 10, big.NewInt(1000))
+	assert.Nil(t, err)
+	_, err = snap.BecomeCandidate(address2, 11, big.NewInt(1000))
+	assert.Nil(t, err)
+
+	root, err := snap.Root()
+	assert.Nil(t, err)
+	assert.Nil(t, snap.Commit(root))
+
+	// Prove against a snapshot reloaded from disk, as the API does
+	snap, err = loadSnapshot(db, root)
+	assert.Nil(t, err)
+
+	proof, err := snap.ProveValidators()
+	assert.Nil(t, err)
+	proven, err := VerifyValidatorsProof(root, proof)
+	assert.Nil(t, err)
+	assert.Equal(t, validators, proven)
+
+	proof, err = snap.ProveCandidate(address2)
+	assert.Nil(t, err)
+	candidate, err := VerifyCandidateProof(root, address2, proof)
+	assert.Nil(t, err)
+	assert.NotNil(t, candidate)
+	assert.Equal(t, uint64(11), candidate.BlockNumber)
+	assert.Equal(t, big.NewInt(1000), candidate.Staked)
+
+	// Absence of a candidate must be provable too
+	other := common.HexToAddress("0x6c4ab069affd856bb915ee93cb59370574f5331e")
+	proof, err = snap.ProveCandidate(other)
+	assert.Nil(t, err)
+	candidate, err = VerifyCandidateProof(root, other, proof)
+	assert.Nil(t, err)
+	assert.Nil(t, candidate)
+
+	// A proof must not verify against another root
+	proof, err = snap.ProveValidators()
+	assert.Nil(t, err)
+	_, err = VerifyValidatorsProof(Root{EpochHash: root.CandidateHash}, proof)
+	assert.NotNil(t, err)
+}
+
+func TestEpochTrieProof(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	head := chain.headers[len(chain.headers)-1]
+
+	// Light servers prove the validators straight from the epoch trie in the database
+	root, err := EpochTrieRoot(head)
+	assert.Nil(t, err)
+	epochTrie, err := trie.New(root, trie.NewDatabase(sealer.db))
+	assert.Nil(t, err)
+	var proof proofList
+	assert.Nil(t, epochTrie.Prove(ValidatorsKey, 0, &proof))
+
+	proven, err := VerifyValidatorsProof(Root{EpochHash: root}, proof)
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Address{testUserAddress}, proven)
+
+	// The genesis header doesn't commit to any epoch trie
+	_, err = EpochTrieRoot(chain.headers[0])
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,118 @@
+package equality
+
+import (
+	"sync"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/ethdb"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/trie"
+)
+
+const (
+	// snapshotsInMemory is the number of recent snapshots kept in memory in
+	// pruned mode, the older ones being regenerated from the epoch snapshots.
+	snapshotsInMemory = 128
+
+	// snapshotMemoryLimit is the memory allowance of the snapshot tries kept in
+	// memory in pruned mode, above which the oldest nodes are flushed to disk.
+	snapshotMemoryLimit = 64 * 1024 * 1024
+)
+
+// snapshotPruner keeps the tries of the recent snapshots in a shared memory
+// database in pruned mode, reference counting their nodes like the state tries
+// of the blockchain. Only the snapshots of the epoch blocks are written to disk,
+// which is all EnsureSnapshot needs to regenerate any other.
+type snapshotPruner struct {
+	triedb *trie.Database
+	recent []Root // Snapshots referenced in memory, oldest first
+	lock   sync.Mutex
+}
+
+// newSnapshotPruner creates a snapshot pruner on top of the database.
+func newSnapshotPruner(db ethdb.Database) *snapshotPruner {
+	return &snapshotPruner{triedb: trie.NewDatabase(db)}
+}
+
+// SetSnapshotPruning switches the engine between the archive mode, writing the
+// snapshot of every block to disk, and the pruned mode retaining the snapshots
+// of the epoch blocks and the recent ones only. It must be called before the
+// engine verifies or mints any block.
+func (e *Equality) SetSnapshotPruning(enabled bool) {
+	if enabled {
+		e.pruner = newSnapshotPruner(e.db)
+	} else {
+		e.pruner = nil
+	}
+}
+
+// createSnapshot creates a new empty snapshot in the database of the gc mode.
+func (e *Equality) createSnapshot() (*Snapshot, error) {
+	if e.pruner == nil {
+		return newSnapshot(e.db)
+	}
+	return &Snapshot{db: e.pruner.triedb, pruner: e.pruner}, nil
+}
+
+// openSnapshot loads an existing snapshot from the database of the gc mode.
+func (e *Equality) openSnapshot(root Root) (*Snapshot, error) {
+	if e.pruner == nil {
+		return loadSnapshot(e.db, root)
+	}
+	return &Snapshot{root: root, db: e.pruner.triedb, pruner: e.pruner}, nil
+}
+
+// hashes returns the roots of the tries of the snapshot.
+func (root Root) hashes() []common.Hash {
+	var hashes []common.Hash
+	for _, hash := range []common.Hash{root.EpochHash, root.CandidateHash, root.MintCntHash, root.ConfigHash} {
+		if hash != (common.Hash{}) {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// retain references the tries of a committed snapshot in memory, releasing the
+// ones of the oldest snapshot once there are more than snapshotsInMemory.
+func (p *snapshotPruner) retain(root Root) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, hash := range root.hashes() {
+		p.triedb.Reference(hash, common.Hash{})
+	}
+	p.recent = append(p.recent, root)
+	if len(p.recent) > snapshotsInMemory {
+		for _, hash := range p.recent[0].hashes() {
+			p.triedb.Dereference(hash)
+		}
+		p.recent = p.recent[1:]
+	}
+	if size, _ := p.triedb.Size(); size > snapshotMemoryLimit {
+		if err := p.triedb.Cap(snapshotMemoryLimit - ethdb.IdealBatchSize); err != nil {
+			log.Error("[equality] Failed to flush snapshot tries", "err", err)
+		}
+	}
+}
+
+// persist writes the tries of the snapshot to disk.
+func (p *snapshotPruner) persist(root Root) error {
+	for _, hash := range root.hashes() {
+		if err := p.triedb.Commit(hash, false, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close writes the latest snapshot to disk, sparing its regeneration on restart.
+func (p *snapshotPruner) close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.recent) == 0 {
+		return nil
+	}
+	return p.persist(p.recent[len(p.recent)-1])
+}
@@ -0,0 +1,121 @@
+package equality
+
+import (
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// snapshotAvailable returns if all the tries of the snapshot are in the database.
+func (e *Equality) snapshotAvailable(root Root) bool {
+	snap, err := e.openSnapshot(root)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range [][]byte{epochPrefix, candidatePrefix, mintCntPrefix, configPrefix} {
+		if _, err := snap.ensureTrie(prefix); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// SnapshotTries returns the roots of the non-empty snapshot tries committed in
+// the header. The nodes of the tries live in the chain database next to the
+// state, so they are served by the node data requests of the eth protocol and
+// fast sync fetches them along with the state of the pivot block.
+func (e *Equality) SnapshotTries(header *types.Header) ([]common.Hash, error) {
+	if header.Number.Uint64() < e.start || header.Number.Uint64() == 0 {
+		return nil, nil
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	var tries []common.Hash
+	for _, root := range []common.Hash{headerExtra.Root.EpochHash, headerExtra.Root.CandidateHash, headerExtra.Root.MintCntHash, headerExtra.Root.ConfigHash} {
+		if root == (common.Hash{}) || root == types.EmptyRootHash {
+			continue
+		}
+		duplicate := false
+		for _, known := range tries {
+			duplicate = duplicate || known == root
+		}
+		if !duplicate {
+			tries = append(tries, root)
+		}
+	}
+	return tries, nil
+}
+
+// EnsureSnapshot makes sure the snapshot committed in the header is available,
+// regenerating the missing ones from the header extras of its ancestors. Fast
+// sync only downloads the state of the pivot block, so this is what gives the
+// node the snapshot it needs to verify and mint the next blocks.
+//
+// Every regenerated snapshot goes through the full header verification, so the
+// roots committed in the headers are checked along the way.
+func (e *Equality) EnsureSnapshot(chain consensus.ChainHeaderReader, header *types.Header) error {
+	type pendingHeader struct {
+		hash   common.Hash
+		number uint64
+	}
+	// Walk back to the last snapshot available, or the start of the engine. The
+	// snapshot of the first epoch block met is fetched from the peers if possible.
+	var (
+		pending []pendingHeader
+		fetch   = e.snapshotFetcher()
+	)
+	for current := header; current.Number.Uint64() >= e.start && current.Number.Uint64() > 0; {
+		headerExtra, err := DecodeHeaderExtra(current)
+		if err != nil {
+			return err
+		}
+		if e.snapshotAvailable(headerExtra.Root) {
+			break
+		}
+		number := current.Number.Uint64()
+		if fetch != nil && headerExtra.EpochBlock == number {
+			if err := fetch(current); err == nil {
+				log.Info("[equality] Fetched snapshot from peers", "number", number, "hash", current.Hash())
+				break
+			} else {
+				log.Debug("[equality] Failed to fetch snapshot from peers", "number", number, "err", err)
+			}
+			fetch = nil
+		}
+		pending = append(pending, pendingHeader{hash: current.Hash(), number: number})
+		if current = chain.GetHeader(current.ParentHash, number-1); current == nil {
+			return consensus.ErrUnknownAncestor
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Replay the headers in order, verifying and committing their snapshots
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	log.Info("[equality] Regenerating snapshots", "from", pending[len(pending)-1].number, "to", header.Number)
+	for i := len(pending) - 1; i >= 0; i-- {
+		current := chain.GetHeader(pending[i].hash, pending[i].number)
+		if current == nil {
+			return consensus.ErrUnknownAncestor
+		}
+		if err := e.verifyCascadingFields(chain, current, nil); err != nil {
+			log.Error("[equality] Failed to regenerate snapshot", "number", pending[i].number, "hash", pending[i].hash, "err", err)
+			return err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("[equality] Regenerating snapshots", "number", pending[i].number, "left", i, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("[equality] Regenerated snapshots", "count", len(pending), "head", header.Number, "hash", header.Hash(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,201 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeSnapshotChain mints a few blocks signed by the test user, committing their
+// snapshots to the database of the returned engine.
+func makeSnapshotChain(t *testing.T, config *params.EqualityConfig) (*Equality, *testChainReader) {
+	sealer := New(config, rawdb.NewMemoryDatabase())
+	chain := &testChainReader{
+		config:  params.TestChainConfig,
+		headers: []*types.Header{{Number: big.NewInt(0), UncleHash: uncleHash}},
+	}
+	for number := uint64(1); number <= 5; number++ {
+		header := &types.Header{
+			Number:     new(big.Int).SetUint64(number),
+			ParentHash: chain.headers[number-1].Hash(),
+			Coinbase:   testUserAddress,
+		}
+		assert.Nil(t, sealer.Prepare(chain, header))
+
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		block, err := sealer.FinalizeAndAssemble(chain, header, statedb, nil, nil, nil)
+		assert.Nil(t, err)
+
+		header = block.Header()
+		signature, err := crypto.Sign(SealHash(header).Bytes(), testUserKey)
+		assert.Nil(t, err)
+		copy(header.Extra[len(header.Extra)-extraSeal:], signature)
+		chain.headers = append(chain.headers, header)
+		recordTestHistory(t, sealer, chain, header)
+	}
+	return sealer, chain
+}
+
+// recordTestHistory records the history of a minted header the way importing
+// it does, minting alone doesn't.
+func recordTestHistory(t *testing.T, sealer *Equality, chain *testChainReader, header *types.Header) {
+	parentExtra, _ := DecodeHeaderExtra(chain.headers[len(chain.headers)-2])
+	headerExtra, err := DecodeHeaderExtra(header)
+	assert.Nil(t, err)
+	config, err := sealer.chainConfigByHash(parentExtra.Root.ConfigHash)
+	assert.Nil(t, err)
+	sealer.writeCandidateHistory(chain, config, header, headerExtra, parentExtra.Root)
+	sealer.writeProposalHistory(config, header, headerExtra, parentExtra.Root)
+}
+
+func testSnapshotConfig() params.EqualityConfig {
+	return params.EqualityConfig{
+		Period:              1,
+		Epoch:               2,
+		MaxValidatorsCount:  21,
+		MinCandidateBalance: big.NewInt(1),
+		Validators:          []common.Address{testUserAddress},
+	}
+}
+
+func TestEnsureSnapshot(t *testing.T) {
+	config := testSnapshotConfig()
+	_, chain := makeSnapshotChain(t, &config)
+	head := chain.headers[len(chain.headers)-1]
+	headExtra, err := DecodeHeaderExtra(head)
+	assert.Nil(t, err)
+
+	// A node which synced the headers only regenerates the snapshots at the head
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	assert.False(t, engine.snapshotAvailable(headExtra.Root))
+	assert.Nil(t, engine.EnsureSnapshot(chain, head))
+	assert.True(t, engine.snapshotAvailable(headExtra.Root))
+
+	snap, err := loadSnapshot(engine.db, headExtra.Root)
+	assert.Nil(t, err)
+	validators, err := snap.GetValidators()
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Address{testUserAddress}, validators)
+
+	// Snapshots not matching the roots committed in the headers are rejected
+	forged := types.CopyHeader(head)
+	headExtra.Root.MintCntHash = common.Hash{0x01}
+	data, err := headExtra.Encode()
+	assert.Nil(t, err)
+	forged.Extra = append(append(append([]byte{}, head.Extra[:extraVanity]...), data...), make([]byte, extraSeal)...)
+	assert.NotNil(t, New(&config, rawdb.NewMemoryDatabase()).EnsureSnapshot(chain, forged))
+}
+
+func TestSnapshotTries(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	head := chain.headers[len(chain.headers)-1]
+	headExtra, err := DecodeHeaderExtra(head)
+	assert.Nil(t, err)
+
+	tries, err := sealer.SnapshotTries(head)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tries)
+	for _, root := range tries {
+		assert.NotEqual(t, types.EmptyRootHash, root)
+	}
+
+	// Syncing the tries node by node makes the snapshot available without replay
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	sched := trie.NewSync(tries[0], engine.db, nil, trie.NewSyncBloom(1, engine.db))
+	for _, root := range tries[1:] {
+		sched.AddSubTrie(root, nil, common.Hash{}, nil)
+	}
+	for sched.Pending() > 0 {
+		nodes, _, _ := sched.Missing(0)
+		for _, hash := range nodes {
+			data, err := sealer.db.Get(hash.Bytes())
+			assert.Nil(t, err)
+			assert.Nil(t, sched.Process(trie.SyncResult{Hash: hash, Data: data}))
+		}
+		batch := engine.db.NewBatch()
+		assert.Nil(t, sched.Commit(batch))
+		assert.Nil(t, batch.Write())
+	}
+	assert.True(t, engine.snapshotAvailable(headExtra.Root))
+}
+
+func TestExportSnapshot(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	head := chain.headers[len(chain.headers)-1]
+	headExtra, err := DecodeHeaderExtra(head)
+	assert.Nil(t, err)
+	assert.Equal(t, head.Number.Uint64(), headExtra.EpochBlock)
+
+	// Only the snapshots of the epoch blocks are exchanged
+	_, err = sealer.ExportSnapshot(chain.headers[4])
+	assert.Equal(t, errNotEpochBlock, err)
+	data, err := sealer.ExportSnapshot(head)
+	assert.Nil(t, err)
+
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	assert.Nil(t, engine.ImportSnapshot(head, data))
+	assert.True(t, engine.snapshotAvailable(headExtra.Root))
+
+	// Snapshots not matching the header roots are rejected
+	parentExtra, err := DecodeHeaderExtra(chain.headers[3])
+	assert.Nil(t, err)
+	parentData, err := sealer.ExportSnapshot(chain.headers[3])
+	assert.Nil(t, err)
+	engine = New(&config, rawdb.NewMemoryDatabase())
+	assert.Equal(t, errSnapshotMismatch, engine.ImportSnapshot(head, parentData))
+	assert.False(t, engine.snapshotAvailable(parentExtra.Root))
+
+	// Regenerating the snapshots fetches the epoch block snapshot instead of replaying
+	var fetched []uint64
+	engine.SetSnapshotFetcher(func(header *types.Header) error {
+		fetched = append(fetched, header.Number.Uint64())
+		data, err := sealer.ExportSnapshot(header)
+		if err != nil {
+			return err
+		}
+		return engine.ImportSnapshot(header, data)
+	})
+	assert.Nil(t, engine.EnsureSnapshot(chain, head))
+	assert.Equal(t, []uint64{head.Number.Uint64()}, fetched)
+	assert.True(t, engine.snapshotAvailable(headExtra.Root))
+}
+
+func TestSnapshotPruning(t *testing.T) {
+	config := testSnapshotConfig()
+	_, chain := makeSnapshotChain(t, &config)
+	roots := make([]Root, len(chain.headers))
+	for number := 1; number < len(chain.headers); number++ {
+		headerExtra, err := DecodeHeaderExtra(chain.headers[number])
+		assert.Nil(t, err)
+		roots[number] = headerExtra.Root
+	}
+
+	// Pruned mode only writes the snapshots of the epoch blocks to disk
+	db := rawdb.NewMemoryDatabase()
+	engine := New(&config, db)
+	engine.SetSnapshotPruning(true)
+	assert.Nil(t, engine.EnsureSnapshot(chain, chain.headers[4]))
+	for number := 1; number <= 4; number++ {
+		assert.True(t, engine.snapshotAvailable(roots[number]))
+	}
+	disk := New(&config, db)
+	assert.True(t, disk.snapshotAvailable(roots[1]))
+	assert.True(t, disk.snapshotAvailable(roots[3]))
+	assert.False(t, disk.snapshotAvailable(roots[2]))
+	assert.False(t, disk.snapshotAvailable(roots[4]))
+
+	// The latest snapshot is written on shutdown, the others are regenerated
+	assert.Nil(t, engine.Close())
+	assert.True(t, disk.snapshotAvailable(roots[4]))
+	assert.False(t, disk.snapshotAvailable(roots[2]))
+}
@@ -0,0 +1,109 @@
+package equality
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/crypto"
+)
+
+// remoteSignerTimeout is the longest a remote signer may take to sign a block,
+// well below the block period so that the slot isn't missed waiting for it.
+const remoteSignerTimeout = 2 * time.Second
+
+// RemoteSigner seals blocks with a key held by a remote signer exposing the eth1
+// signing endpoint of web3signer, so that the key never lives on the validator.
+type RemoteSigner struct {
+	url    string
+	client *http.Client
+}
+
+// RemoteSignerConfig is the location of a remote signer along with the TLS client
+// certificate authenticating the validator, all optional but the URL.
+type RemoteSignerConfig struct {
+	URL  string // Base URL of the signer
+	Cert string // PEM client certificate file
+	Key  string // PEM client key file
+	CA   string // PEM certificate authority file verifying the signer, system roots if empty
+}
+
+// NewRemoteSigner creates a client of the remote signer.
+func NewRemoteSigner(config RemoteSignerConfig) (*RemoteSigner, error) {
+	if config.URL == "" {
+		return nil, errors.New("missing remote signer URL")
+	}
+	tlsConfig := new(tls.Config)
+	if config.Cert != "" || config.Key != "" {
+		cert, err := tls.LoadX509KeyPair(config.Cert, config.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if config.CA != "" {
+		blob, err := ioutil.ReadFile(config.CA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(blob) {
+			return nil, errors.New("invalid certificate authority")
+		}
+	}
+	return &RemoteSigner{
+		url: strings.TrimRight(config.URL, "/"),
+		client: &http.Client{
+			Timeout:   remoteSignerTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// SignData requests the signature of the keccak256 hash of the data, matching the
+// SignerFn of the engine. The signature is checked against the account, so that a
+// misconfigured signer can't make the validator seal invalid blocks.
+func (s *RemoteSigner) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	body, _ := json.Marshal(map[string]string{"data": hexutil.Encode(data)})
+	res, err := s.client.Post(fmt.Sprintf("%s/api/v1/eth1/sign/%s", s.url, account.Address.Hex()), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	reply, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer failed: %s: %s", res.Status, strings.TrimSpace(string(reply)))
+	}
+	signature, err := hexutil.Decode(strings.Trim(strings.TrimSpace(string(reply)), `"`))
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signature: %v", err)
+	}
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid remote signature length %d", len(signature))
+	}
+	// Signers return the Ethereum flavor of the recovery id
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(data), signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signature: %v", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != account.Address {
+		return nil, fmt.Errorf("remote signature by %s instead of %s", signer.Hex(), account.Address.Hex())
+	}
+	return signature, nil
+}
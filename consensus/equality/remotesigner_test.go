@@ -0,0 +1,80 @@
+package equality
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/eth1/sign/"+testUserAddress.Hex() {
+			http.Error(w, "unknown key", http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Data string `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, err := hexutil.Decode(req.Data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		signature, _ := crypto.Sign(crypto.Keccak256(data), testUserKey)
+		signature[crypto.RecoveryIDOffset] += 27
+		w.Write([]byte(hexutil.Encode(signature)))
+	}))
+	defer server.Close()
+
+	signer, err := NewRemoteSigner(RemoteSignerConfig{URL: server.URL + "/"})
+	assert.Nil(t, err)
+
+	// Signatures of the remote key are accepted by the engine
+	header := &types.Header{Extra: make([]byte, extraSeal)}
+	signature, err := signer.SignData(accounts.Account{Address: testUserAddress}, accounts.MimetypeClique, EqualityRLP(header))
+	assert.Nil(t, err)
+	copy(header.Extra, signature)
+
+	signatures, _ := lru.NewARC(inMemorySignatures)
+	sealer, err := ecrecover(header, signatures)
+	assert.Nil(t, err)
+	assert.Equal(t, testUserAddress, sealer)
+
+	// Keys unknown to the signer are rejected
+	_, err = signer.SignData(accounts.Account{Address: common.HexToAddress("0x01")}, accounts.MimetypeClique, EqualityRLP(header))
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "404"))
+
+	_, err = NewRemoteSigner(RemoteSignerConfig{})
+	assert.NotNil(t, err)
+}
+
+func TestRemoteSignerWrongKey(t *testing.T) {
+	otherKey, _ := crypto.GenerateKey()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature, _ := crypto.Sign(crypto.Keccak256(nil), otherKey)
+		w.Write([]byte(`"` + hexutil.Encode(signature) + `"`))
+	}))
+	defer server.Close()
+
+	signer, err := NewRemoteSigner(RemoteSignerConfig{URL: server.URL})
+	assert.Nil(t, err)
+
+	// Signatures by another key than the validator's are never used to seal
+	_, err = signer.SignData(accounts.Account{Address: testUserAddress}, accounts.MimetypeClique, nil)
+	assert.NotNil(t, err)
+}
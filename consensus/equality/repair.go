@@ -0,0 +1,80 @@
+package equality
+
+import (
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// RepairSnapshots checks the snapshot of the header and the ones of the epoch
+// blocks among its last depth ancestors for missing or corrupt trie nodes. The
+// damaged snapshots are regenerated by replaying the headers on top of the last
+// intact epoch snapshot preceding them, the tries being rewritten along the way.
+// It returns the number of damaged snapshots found.
+//
+// The snapshots of the other blocks are not checked, as the pruned mode doesn't
+// write them to disk.
+func (e *Equality) RepairSnapshots(chain consensus.ChainHeaderReader, header *types.Header, depth uint64) (int, error) {
+	var (
+		pending []*types.Header // Headers to replay, newest first
+		damaged int
+	)
+	for current := header; ; {
+		number := current.Number.Uint64()
+		if number < e.start || number == 0 {
+			break
+		}
+		headerExtra, err := DecodeHeaderExtra(current)
+		if err != nil {
+			return damaged, err
+		}
+		if current == header || headerExtra.EpochBlock == number {
+			nodes, err := VerifySnapshot(e.db, headerExtra.Root)
+			switch {
+			case err != nil:
+				log.Warn("[equality] Damaged snapshot", "number", number, "hash", current.Hash(), "nodes", nodes, "err", err)
+				damaged++
+			case headerExtra.EpochBlock == number && damaged > 0:
+				log.Info("[equality] Found intact epoch snapshot", "number", number, "hash", current.Hash(), "nodes", nodes)
+				return damaged, e.replaySnapshots(chain, pending)
+			}
+		}
+		if damaged == 0 && header.Number.Uint64()-number >= depth {
+			return 0, nil
+		}
+		pending = append(pending, current)
+		if current = chain.GetHeader(current.ParentHash, number-1); current == nil {
+			return damaged, consensus.ErrUnknownAncestor
+		}
+	}
+	if damaged == 0 {
+		return 0, nil
+	}
+	// No intact snapshot left, regenerate them all from the start of the engine
+	log.Info("[equality] No intact epoch snapshot, replaying from the start", "start", e.start)
+	return damaged, e.replaySnapshots(chain, pending)
+}
+
+// replaySnapshots regenerates the snapshots of the given headers, newest first,
+// on top of the snapshot of the parent of the oldest one.
+func (e *Equality) replaySnapshots(chain consensus.ChainHeaderReader, pending []*types.Header) error {
+	log.Info("[equality] Regenerating snapshots", "from", pending[len(pending)-1].Number, "to", pending[0].Number)
+	for i := len(pending) - 1; i >= 0; i-- {
+		if err := e.verifyCascadingFields(chain, pending[i], nil); err != nil {
+			log.Error("[equality] Failed to regenerate snapshot", "number", pending[i].Number, "hash", pending[i].Hash(), "err", err)
+			return err
+		}
+	}
+	if e.pruner != nil {
+		// Only the epoch snapshots are persisted as the replay goes, write the head too
+		headerExtra, err := DecodeHeaderExtra(pending[0])
+		if err != nil {
+			return err
+		}
+		if err := e.pruner.persist(headerExtra.Root); err != nil {
+			return err
+		}
+	}
+	log.Info("[equality] Regenerated snapshots", "count", len(pending), "head", pending[0].Number, "hash", pending[0].Hash())
+	return nil
+}
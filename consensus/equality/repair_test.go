@@ -0,0 +1,51 @@
+package equality
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairSnapshots(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	head := chain.headers[len(chain.headers)-1]
+	headExtra, err := DecodeHeaderExtra(head)
+	assert.Nil(t, err)
+
+	// Intact snapshots are left alone
+	damaged, err := sealer.RepairSnapshots(chain, head, 16)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, damaged)
+
+	// Missing and corrupt trie nodes are both regenerated
+	assert.Nil(t, sealer.db.Delete(headExtra.Root.MintCntHash.Bytes()))
+	_, err = VerifySnapshot(sealer.db, headExtra.Root)
+	assert.NotNil(t, err)
+
+	damaged, err = sealer.RepairSnapshots(chain, head, 16)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, damaged)
+	_, err = VerifySnapshot(sealer.db, headExtra.Root)
+	assert.Nil(t, err)
+
+	assert.Nil(t, sealer.db.Put(headExtra.Root.EpochHash.Bytes(), []byte{0xc0}))
+	_, err = VerifySnapshot(sealer.db, headExtra.Root)
+	assert.NotNil(t, err)
+
+	// The validators never changed, the epoch trie is shared by all the epoch
+	// snapshots which are replayed from the start
+	damaged, err = sealer.RepairSnapshots(chain, head, 16)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, damaged)
+	_, err = VerifySnapshot(sealer.db, headExtra.Root)
+	assert.Nil(t, err)
+
+	// Damage beyond the scanned depth is not looked for
+	parentExtra, err := DecodeHeaderExtra(chain.headers[3])
+	assert.Nil(t, err)
+	assert.Nil(t, sealer.db.Delete(parentExtra.Root.MintCntHash.Bytes()))
+	damaged, err = sealer.RepairSnapshots(chain, head, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, damaged)
+}
@@ -0,0 +1,45 @@
+package equality
+
+import (
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// RewardPaidTopic is the topic of the reward logs, the hash of the signature of
+// the event RewardPaid(address indexed coinbase, address indexed pool, uint256 amount).
+//
+// Logs only exist in receipts, so blocks without transactions carry no reward log
+// even though their reward is issued all the same. Indexers reconciling issuance
+// from the logs have to account for the empty blocks themselves, for instance by
+// querying eq_getBlockRewards for them.
+var RewardPaidTopic = crypto.Keccak256Hash([]byte("RewardPaid(address,address,uint256)"))
+
+// logRewardPaid logs the block reward issued for minting the block, the shares
+// of the payout of the coinbase and of the pool together, the base fee being no
+// issuance. Like the governance logs, it goes to the receipt of the last
+// transaction of the block, the blocks without any logging nothing.
+func logRewardPaid(state *state.StateDB, header *types.Header, config params.EqualityConfig, txs []*types.Transaction,
+	transfers []RewardTransfer) {
+
+	amount := new(big.Int)
+	for _, transfer := range transfers {
+		if transfer.Type == RewardTypeBlock || transfer.Type == RewardTypePool {
+			amount.Add(amount, transfer.Value.ToInt())
+		}
+	}
+	if len(txs) == 0 || amount.Sign() == 0 {
+		return
+	}
+	state.Prepare(txs[len(txs)-1].Hash(), state.BlockHash(), len(txs)-1)
+	state.AddLog(&types.Log{
+		Address:     params.RewardLogAddress,
+		Topics:      []common.Hash{RewardPaidTopic, common.BytesToHash(header.Coinbase.Bytes()), common.BytesToHash(config.Pool.Bytes())},
+		Data:        common.LeftPadBytes(amount.Bytes(), 32),
+		BlockNumber: header.Number.Uint64(),
+	})
+}
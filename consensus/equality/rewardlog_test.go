@@ -0,0 +1,52 @@
+package equality
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewardPaidLog(t *testing.T) {
+	config := testSnapshotConfig()
+	config.Pool = common.HexToAddress("0x47746e8acb5dafe9c00b7195d0c2d830fcc04910")
+	config.Rewards = params.EqualityRewards{{Number: math.MaxUint64, Reward: big.NewInt(10)}}
+	sealer, chain := makeSnapshotChain(t, &config)
+	chainConfig := *params.TestChainConfig
+	chainConfig.RewardLogBlock = big.NewInt(7)
+	chain.config = &chainConfig
+
+	tx := types.NewTransaction(0, testUserAddress, new(big.Int), 21000, new(big.Int), nil)
+	mint := func(txs []*types.Transaction) (*types.Header, *state.StateDB) {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		return mintTestBlock(t, sealer, chain, statedb, txs), statedb
+	}
+
+	// Nothing is logged before the fork
+	_, statedb := mint([]*types.Transaction{tx})
+	assert.Empty(t, statedb.Logs())
+
+	// The issuance is logged on the last transaction of the block
+	header, statedb := mint([]*types.Transaction{tx})
+	logs := statedb.GetLogs(tx.Hash())
+	assert.Len(t, logs, 1)
+	assert.Equal(t, params.RewardLogAddress, logs[0].Address)
+	assert.Equal(t, []common.Hash{RewardPaidTopic, common.BytesToHash(testUserAddress.Bytes()), common.BytesToHash(config.Pool.Bytes())}, logs[0].Topics)
+	assert.Equal(t, common.LeftPadBytes([]byte{10}, 32), logs[0].Data)
+
+	// Verifying nodes log it alike
+	verified, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	verified.Prepare(tx.Hash(), common.Hash{}, 0)
+	sealer.Finalize(chain, types.CopyHeader(header), verified, []*types.Transaction{tx}, nil)
+	assert.Equal(t, logs, verified.GetLogs(tx.Hash()))
+
+	// Blocks without transactions have no receipt to log on
+	_, statedb = mint(nil)
+	assert.Empty(t, statedb.Logs())
+}
@@ -0,0 +1,138 @@
+package equality
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// errSealVetoed is wrapped by the errors of the seal policies vetoing a header.
+var errSealVetoed = errors.New("seal vetoed by policy")
+
+// SealContext is the header about to be sealed along with the chain state it is
+// sealed against.
+type SealContext struct {
+	Header     *types.Header
+	Parent     *types.Header
+	Signer     common.Address        // Key signing the seal
+	Validators []common.Address      // Validators of the epoch the header is sealed in
+	Config     params.EqualityConfig // Config the header is minted with
+}
+
+// SealPolicy vets the headers right before they are signed, letting operators
+// enforce policies of their own without forking the engine.
+type SealPolicy interface {
+	// CheckSeal returns an error to veto sealing the header.
+	CheckSeal(ctx *SealContext) error
+}
+
+// SealPolicyFunc is an adapter to use ordinary functions as seal policies.
+type SealPolicyFunc func(ctx *SealContext) error
+
+// CheckSeal implements SealPolicy.
+func (f SealPolicyFunc) CheckSeal(ctx *SealContext) error {
+	return f(ctx)
+}
+
+// AddSealPolicy appends a policy consulted before sealing each block, all the
+// policies having to allow the header in the order they were added.
+func (e *Equality) AddSealPolicy(policy SealPolicy) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.policies = append(e.policies, policy)
+}
+
+// checkSealPolicies runs the header through the seal policies, returning the
+// veto of the first policy refusing it.
+func (e *Equality) checkSealPolicies(policies []SealPolicy, config params.EqualityConfig, parent, header *types.Header, signer common.Address) error {
+	if len(policies) == 0 {
+		return nil
+	}
+	validators := config.Validators
+	if parent.Number.Uint64() >= e.start {
+		headerExtra, err := DecodeHeaderExtra(parent)
+		if err != nil {
+			return err
+		}
+		snap, err := e.openSnapshot(headerExtra.Root)
+		if err != nil {
+			return err
+		}
+		if validators, err = snap.GetValidators(); err != nil {
+			return err
+		}
+	}
+	ctx := &SealContext{Header: header, Parent: parent, Signer: signer, Validators: validators, Config: config}
+	for _, policy := range policies {
+		if err := policy.CheckSeal(ctx); err != nil {
+			return fmt.Errorf("%w: %v", errSealVetoed, err)
+		}
+	}
+	return nil
+}
+
+// HeightPolicy refuses to seal a block below the highest one sealed since the
+// start of the node, e.g. on a chain rewound by mistake.
+type HeightPolicy struct {
+	highest uint64
+	lock    sync.Mutex
+}
+
+// CheckSeal implements SealPolicy.
+func (p *HeightPolicy) CheckSeal(ctx *SealContext) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	number := ctx.Header.Number.Uint64()
+	if number < p.highest {
+		return fmt.Errorf("height %d below the highest sealed %d", number, p.highest)
+	}
+	p.highest = number
+	return nil
+}
+
+// ClockPolicy refuses to seal a block whose timestamp drifts from the local
+// clock by more than the allowance, beyond the period blocks are sealed ahead.
+type ClockPolicy struct {
+	MaxDrift time.Duration
+}
+
+// CheckSeal implements SealPolicy.
+func (p ClockPolicy) CheckSeal(ctx *SealContext) error {
+	ahead := time.Until(time.Unix(int64(ctx.Header.Time), 0))
+	if ahead > p.MaxDrift+time.Duration(ctx.Config.Period)*time.Second || -ahead > p.MaxDrift {
+		return fmt.Errorf("timestamp %d drifts %v from the local clock", ctx.Header.Time, common.PrettyDuration(ahead))
+	}
+	return nil
+}
+
+// ValidatorSetPolicy refuses to seal a block in an epoch whose validators are
+// not all known to the operator.
+type ValidatorSetPolicy struct {
+	Allowed map[common.Address]bool
+}
+
+// NewValidatorSetPolicy creates a policy allowing the given validators only.
+func NewValidatorSetPolicy(allowed []common.Address) ValidatorSetPolicy {
+	policy := ValidatorSetPolicy{Allowed: make(map[common.Address]bool)}
+	for _, validator := range allowed {
+		policy.Allowed[validator] = true
+	}
+	return policy
+}
+
+// CheckSeal implements SealPolicy.
+func (p ValidatorSetPolicy) CheckSeal(ctx *SealContext) error {
+	for _, validator := range ctx.Validators {
+		if !p.Allowed[validator] {
+			return fmt.Errorf("unexpected validator %s", validator.Hex())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package equality
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealPolicy(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	signed := 0
+	sealer.Authorize(testUserAddress, func(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+		signed++
+		return crypto.Sign(crypto.Keccak256(data), testUserKey)
+	})
+
+	parent := chain.headers[len(chain.headers)-1]
+	header := &types.Header{
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		ParentHash: parent.Hash(),
+		Coinbase:   testUserAddress,
+	}
+	assert.Nil(t, sealer.Prepare(chain, header))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	block, err := sealer.FinalizeAndAssemble(chain, header, statedb, nil, nil, nil)
+	assert.Nil(t, err)
+
+	// The policies see the header along with the validators of its epoch
+	var seen *SealContext
+	veto := true
+	sealer.AddSealPolicy(SealPolicyFunc(func(ctx *SealContext) error {
+		seen = ctx
+		if veto {
+			return errors.New("maintenance window")
+		}
+		return nil
+	}))
+	stop := make(chan struct{})
+	defer close(stop)
+
+	err = sealer.Seal(chain, block, make(chan *types.Block, 1), stop)
+	assert.True(t, errors.Is(err, errSealVetoed))
+	assert.Equal(t, 0, signed)
+	assert.Equal(t, block.Number(), seen.Header.Number)
+	assert.Equal(t, parent.Hash(), seen.Parent.Hash())
+	assert.Equal(t, []common.Address{testUserAddress}, seen.Validators)
+
+	veto = false
+	assert.Nil(t, sealer.Seal(chain, block, make(chan *types.Block, 1), stop))
+	assert.Equal(t, 1, signed)
+}
+
+func TestBuiltinSealPolicies(t *testing.T) {
+	header := func(number uint64, time uint64) *SealContext {
+		return &SealContext{Header: &types.Header{Number: new(big.Int).SetUint64(number), Time: time}}
+	}
+	height := new(HeightPolicy)
+	assert.Nil(t, height.CheckSeal(header(5, 0)))
+	assert.Nil(t, height.CheckSeal(header(5, 0)))
+	assert.NotNil(t, height.CheckSeal(header(4, 0)))
+
+	now := uint64(time.Now().Unix())
+	clock := ClockPolicy{MaxDrift: time.Minute}
+	assert.Nil(t, clock.CheckSeal(header(1, now)))
+	assert.NotNil(t, clock.CheckSeal(header(1, now+3600)))
+	assert.NotNil(t, clock.CheckSeal(header(1, now-3600)))
+
+	other := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	validators := NewValidatorSetPolicy([]common.Address{testUserAddress})
+	assert.Nil(t, validators.CheckSeal(&SealContext{Validators: []common.Address{testUserAddress}}))
+	assert.NotNil(t, validators.CheckSeal(&SealContext{Validators: []common.Address{testUserAddress, other}}))
+}
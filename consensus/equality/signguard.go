@@ -0,0 +1,116 @@
+package equality
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// errConflictingSeal is returned if sealing a block at or below the slot of the
+// last block sealed, which would be a double sign.
+var errConflictingSeal = errors.New("conflicting seal refused by the sign guard")
+
+// signRecord is the last block sealed by the node, as persisted by the guard.
+type signRecord struct {
+	Epoch    uint64      `json:"epoch"`
+	Slot     uint64      `json:"slot"`
+	Number   uint64      `json:"number"`
+	SealHash common.Hash `json:"sealHash"`
+}
+
+// SignGuard refuses to seal blocks conflicting with the ones sealed before. It
+// persists the highest (epoch, slot) sealed before the seal is signed, so that
+// the record survives crashes as well as the restore of the chain database from
+// a backup, the file being kept out of it if needed.
+type SignGuard struct {
+	path   string
+	record *signRecord // Last block sealed, nil if none
+	lock   sync.Mutex
+}
+
+// OpenSignGuard loads the record of the guard persisted at the given path.
+func OpenSignGuard(path string) (*SignGuard, error) {
+	guard := &SignGuard{path: path}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return guard, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	guard.record = new(signRecord)
+	if err := json.Unmarshal(data, guard.record); err != nil {
+		return nil, fmt.Errorf("corrupted sign guard %s: %v", path, err)
+	}
+	log.Info("[equality] Loaded sign guard", "path", path, "epoch", guard.record.Epoch, "slot", guard.record.Slot, "number", guard.record.Number)
+	return guard, nil
+}
+
+// Allow checks the block against the highest slot sealed and records it before
+// it is signed. Sealing the very block recorded again is allowed.
+func (g *SignGuard) Allow(epoch, slot, number uint64, sealHash common.Hash) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if last := g.record; last != nil {
+		if last.Epoch == epoch && last.Slot == slot && last.SealHash == sealHash {
+			return nil
+		}
+		if last.Epoch > epoch || (last.Epoch == epoch && last.Slot >= slot) {
+			log.Error("[equality] Refusing to seal conflicting block", "number", number, "epoch", epoch, "slot", slot,
+				"sealedNumber", last.Number, "sealedEpoch", last.Epoch, "sealedSlot", last.Slot)
+			return errConflictingSeal
+		}
+	}
+	record := &signRecord{Epoch: epoch, Slot: slot, Number: number, SealHash: sealHash}
+	if err := g.write(record); err != nil {
+		return fmt.Errorf("failed to persist sign guard: %v", err)
+	}
+	g.record = record
+	return nil
+}
+
+// write atomically replaces the record persisted, syncing it to disk.
+func (g *SignGuard) write(record *signRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(g.path), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(g.path), filepath.Base(g.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), g.path)
+}
+
+// SetSignGuard sets the guard consulted before sealing each block.
+func (e *Equality) SetSignGuard(guard *SignGuard) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.guard = guard
+}
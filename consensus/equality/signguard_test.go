@@ -0,0 +1,38 @@
+package equality
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignGuard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signguard")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keystore", "signguard.json")
+
+	guard, err := OpenSignGuard(path)
+	assert.Nil(t, err)
+	assert.Nil(t, guard.Allow(1, 10, 5, common.Hash{0x01}))
+	assert.Nil(t, guard.Allow(1, 10, 5, common.Hash{0x01}))
+	assert.Equal(t, errConflictingSeal, guard.Allow(1, 10, 5, common.Hash{0x02}))
+	assert.Nil(t, guard.Allow(1, 12, 6, common.Hash{0x03}))
+
+	// The record survives the restart of the node
+	guard, err = OpenSignGuard(path)
+	assert.Nil(t, err)
+	assert.Equal(t, errConflictingSeal, guard.Allow(1, 11, 6, common.Hash{0x04}))
+	assert.Equal(t, errConflictingSeal, guard.Allow(0, 20, 6, common.Hash{0x04}))
+	assert.Nil(t, guard.Allow(1, 12, 6, common.Hash{0x03}))
+	assert.Nil(t, guard.Allow(2, 13, 7, common.Hash{0x05}))
+
+	// Corrupted records are never taken as empty
+	assert.Nil(t, ioutil.WriteFile(path, []byte("{"), 0600))
+	_, err = OpenSignGuard(path)
+	assert.NotNil(t, err)
+}
@@ -5,14 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"math/rand"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/ethdb"
+	"github.com/SecretBlockChain/go-secret/metrics"
 	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/SecretBlockChain/go-secret/rlp"
 	"github.com/SecretBlockChain/go-secret/trie"
@@ -25,10 +28,48 @@ var (
 	configPrefix    = []byte("config")     // key: config:{params.EqualityConfig}
 )
 
+var (
+	snapshotRootTimer        = metrics.NewRegisteredTimer("equality/snapshot/root", nil)
+	snapshotRootBytesMeter   = metrics.NewRegisteredMeter("equality/snapshot/root/bytes", nil)
+	snapshotCommitTimer      = metrics.NewRegisteredTimer("equality/snapshot/commit", nil)
+	snapshotCommitBytesMeter = metrics.NewRegisteredMeter("equality/snapshot/commit/bytes", nil)
+)
+
 // Candidate basic information
 type Candidate struct {
-	Staked      *big.Int `json:"staked"`
-	BlockNumber uint64   `json:"blockNumber"`
+	Staked      *big.Int       `json:"staked"`
+	BlockNumber uint64         `json:"blockNumber"`
+	Payout      common.Address `json:"payout"` // Recipient of the rewards, zero for the candidate itself
+}
+
+// candidateRLP is the encoding of Candidate, the payout being omitted unless set
+// so that the candidates registered before keep their encoding.
+type candidateRLP struct {
+	Staked      *big.Int
+	BlockNumber uint64
+	Payout      []common.Address `rlp:"tail"`
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (candidate Candidate) EncodeRLP(w io.Writer) error {
+	enc := candidateRLP{Staked: candidate.Staked, BlockNumber: candidate.BlockNumber}
+	if candidate.Payout != (common.Address{}) {
+		enc.Payout = []common.Address{candidate.Payout}
+	}
+	return rlp.Encode(w, &enc)
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (candidate *Candidate) DecodeRLP(s *rlp.Stream) error {
+	var dec candidateRLP
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	*candidate = Candidate{Staked: dec.Staked, BlockNumber: dec.BlockNumber}
+	if len(dec.Payout) > 0 {
+		candidate.Payout = dec.Payout[0]
+	}
+	return nil
 }
 
 // SortableAddress sorted by votes.
@@ -67,6 +108,7 @@ type Snapshot struct {
 	mintCntTrie   *Trie
 	configTrie    *Trie
 	db            *trie.Database
+	pruner        *snapshotPruner // Retains the tries in memory in pruned mode, nil in archive mode
 }
 
 // newSnapshot creates a new empty snapshot
@@ -122,12 +164,13 @@ func (snap *Snapshot) ensureTrie(prefix []byte) (*Trie, error) {
 }
 
 // apply creates a new authorization snapshot by applying the given headers to
-// the original one.
-func (snap *Snapshot) apply(config params.EqualityConfig, header *types.Header, headerExtra HeaderExtra) error {
+// the original one. The candidates of the first equality block are the genesis
+// validators, registered without security deposit.
+func (snap *Snapshot) apply(config params.EqualityConfig, header *types.Header, headerExtra HeaderExtra, first bool) error {
 	number := header.Number.Uint64()
 	for _, candidate := range headerExtra.CurrentBlockCandidates {
 		security := big.NewInt(0)
-		if number > 1 {
+		if !first {
 			security = config.MinCandidateBalance
 		}
 		if _, err := snap.BecomeCandidate(candidate, number, security, true); err != nil {
@@ -135,6 +178,18 @@ func (snap *Snapshot) apply(config params.EqualityConfig, header *types.Header,
 		}
 	}
 
+	for _, key := range headerExtra.CurrentBlockSignerKeys {
+		if err := snap.BindSignerKey(key.Validator, key.Key); err != nil {
+			return err
+		}
+	}
+
+	for _, payout := range headerExtra.CurrentBlockPayouts {
+		if _, err := snap.SetPayout(payout.Candidate, payout.Recipient); err != nil {
+			return err
+		}
+	}
+
 	for _, candidate := range headerExtra.CurrentBlockKickOutCandidates {
 		if _, _, err := snap.CancelCandidate(candidate); err != nil {
 			return err
@@ -147,10 +202,78 @@ func (snap *Snapshot) apply(config params.EqualityConfig, header *types.Header,
 		}
 	}
 
+	for _, candidate := range headerExtra.CurrentBlockExpiredCandidates {
+		if _, _, err := snap.ExpireCandidate(candidate); err != nil {
+			return err
+		}
+	}
+
 	if header.Number.Uint64() == headerExtra.EpochBlock {
 		if err := snap.SetValidators(headerExtra.CurrentEpochValidators); err != nil {
 			return err
 		}
+		if err := snap.SetEpochSignerKeys(headerExtra.CurrentEpochSignerKeys); err != nil {
+			return err
+		}
+		if config.CandidateExpiry > 0 {
+			if err := snap.SetLastElected(headerExtra.CurrentEpochValidators, number); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, proposal := range headerExtra.CurrentBlockProposals {
+		if proposal.Amount != nil {
+			if err := snap.ProposeSpend(proposal.ID, proposal.Proposer, proposal.Recipient, proposal.Amount, number, proposalDeadline(config, number), config.RequiredDeposit()); err != nil {
+				return err
+			}
+			continue
+		}
+		if proposal.Signal != (common.Hash{}) {
+			if err := snap.ProposeSignal(proposal.ID, proposal.Proposer, proposal.Signal, number, proposalDeadline(config, number), config.RequiredDeposit()); err != nil {
+				return err
+			}
+			continue
+		}
+		if proposal.Fork != "" {
+			if err := snap.ProposeFork(proposal.ID, proposal.Proposer, proposal.Fork, proposal.ForkBlock, number, proposalDeadline(config, number), config.RequiredDeposit()); err != nil {
+				return err
+			}
+			continue
+		}
+		if proposal.Halt != 0 {
+			if err := snap.ProposeHalt(proposal.ID, proposal.Proposer, proposal.Halt, number, proposalDeadline(config, number), config.RequiredDeposit()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := snap.Propose(proposal.ID, proposal.Proposer, proposal.Config, number, proposalDeadline(config, number), config.RequiredDeposit()); err != nil {
+			return err
+		}
+	}
+
+	for _, vote := range headerExtra.CurrentBlockVotes {
+		if err := snap.Vote(vote.ID, vote.Validator, vote.Approve); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range headerExtra.ExecutedProposals {
+		if err := snap.ExecuteProposal(id, number); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range headerExtra.RefundedProposals {
+		if err := snap.RefundProposal(id); err != nil {
+			return err
+		}
+	}
+
+	if config.ProposalWindow > 0 && !first && header.Number.Uint64() == headerExtra.EpochBlock {
+		if _, err := snap.PruneProposals(number); err != nil {
+			return err
+		}
 	}
 
 	if len(headerExtra.ChainConfig) > 0 {
@@ -168,6 +291,14 @@ func (snap *Snapshot) apply(config params.EqualityConfig, header *types.Header,
 
 // Root returns root of snapshot trie.
 func (snap *Snapshot) Root() (root Root, err error) {
+	// Measure the time spent hashing and the nodes made dirty in the trie database
+	start, size := time.Now(), snap.dirtySize()
+	defer func() {
+		snapshotRootTimer.UpdateSince(start)
+		if grown := snap.dirtySize() - size; grown > 0 {
+			snapshotRootBytesMeter.Mark(int64(grown))
+		}
+	}()
 	root = snap.root
 	if snap.epochTrie != nil {
 		root.EpochHash, err = snap.epochTrie.Commit(nil)
@@ -199,8 +330,22 @@ func (snap *Snapshot) Root() (root Root, err error) {
 	return root, err
 }
 
-// Commit commit snapshot changes to database.
+// Commit commit snapshot changes to database. In pruned mode the tries are only
+// retained in memory, see Persist.
 func (snap *Snapshot) Commit(root Root) error {
+	// Measure the time spent and the nodes flushed out of the trie database
+	start, size := time.Now(), snap.dirtySize()
+	defer func() {
+		snapshotCommitTimer.UpdateSince(start)
+		if flushed := size - snap.dirtySize(); flushed > 0 {
+			snapshotCommitBytesMeter.Mark(int64(flushed))
+		}
+	}()
+	if snap.pruner != nil {
+		snap.pruner.retain(root)
+		snap.root = root
+		return nil
+	}
 	if snap.root.EpochHash != root.EpochHash {
 		if err := snap.db.Commit(root.EpochHash, false, nil); err != nil {
 			return err
@@ -225,6 +370,25 @@ func (snap *Snapshot) Commit(root Root) error {
 	return nil
 }
 
+// dirtySize returns the size of the trie nodes held in memory by the snapshot,
+// not yet flushed to disk.
+func (snap *Snapshot) dirtySize() common.StorageSize {
+	if !metrics.Enabled {
+		return 0
+	}
+	size, _ := snap.db.Size()
+	return size
+}
+
+// Persist writes the tries of the committed snapshot to disk in pruned mode,
+// which Commit does already in archive mode.
+func (snap *Snapshot) Persist() error {
+	if snap.pruner == nil {
+		return nil
+	}
+	return snap.pruner.persist(snap.root)
+}
+
 // GetChainConfig returns chain config from snapshot.
 func (snap *Snapshot) GetChainConfig() (params.EqualityConfig, error) {
 	configTrie, err := snap.ensureTrie(configPrefix)
@@ -249,6 +413,8 @@ func (snap *Snapshot) SetChainConfig(config params.EqualityConfig) error {
 	if len(config.Validators) == 0 {
 		config.Validators = nil
 	}
+	// Overrides are local settings, they must not change the root of the trie
+	config.Overrides = nil
 
 	configTrie, err := snap.ensureTrie(configPrefix)
 	if err != nil {
@@ -294,6 +460,234 @@ func (snap *Snapshot) SetValidators(validators []common.Address) error {
 	return epochTrie.TryUpdate(key, validatorsRLP)
 }
 
+// signerKeyKey returns the epoch trie key of the signing key bound to a candidate.
+func signerKeyKey(candidateAddr common.Address) []byte {
+	return append([]byte("signer-"), candidateAddr.Bytes()...)
+}
+
+// signerOfKey returns the epoch trie key of the candidate a signing key is bound to.
+func signerOfKey(key common.Address) []byte {
+	return append([]byte("signerOf-"), key.Bytes()...)
+}
+
+// GetSignerKey returns the signing key bound to the candidate, its own address
+// if it never bound another key.
+func (snap *Snapshot) GetSignerKey(candidateAddr common.Address) (common.Address, error) {
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	value, err := epochTrie.TryGet(signerKeyKey(candidateAddr))
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(value) != common.AddressLength {
+		return candidateAddr, nil
+	}
+	return common.BytesToAddress(value), nil
+}
+
+// GetSignerOf returns the candidate a signing key is bound to, the key itself if
+// it isn't bound to any candidate.
+func (snap *Snapshot) GetSignerOf(key common.Address) (common.Address, error) {
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	value, err := epochTrie.TryGet(signerOfKey(key))
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(value) != common.AddressLength {
+		return key, nil
+	}
+	return common.BytesToAddress(value), nil
+}
+
+// BindSignerKey binds a signing key to the candidate, releasing the key bound
+// before. Binding the candidate's own address restores its own key.
+func (snap *Snapshot) BindSignerKey(candidateAddr, key common.Address) error {
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return err
+	}
+
+	previous, err := snap.GetSignerKey(candidateAddr)
+	if err != nil {
+		return err
+	}
+	if previous != candidateAddr {
+		if err := epochTrie.TryDelete(signerOfKey(previous)); err != nil {
+			return err
+		}
+	}
+	if key == candidateAddr {
+		return epochTrie.TryDelete(signerKeyKey(candidateAddr))
+	}
+	if err := epochTrie.TryUpdate(signerKeyKey(candidateAddr), key.Bytes()); err != nil {
+		return err
+	}
+	return epochTrie.TryUpdate(signerOfKey(key), candidateAddr.Bytes())
+}
+
+// GetEpochSignerKeys returns the signing keys of the validators of the current
+// epoch which don't seal with their own key.
+func (snap *Snapshot) GetEpochSignerKeys() ([]SignerKey, error) {
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []SignerKey
+	keysRLP, err := epochTrie.TryGet([]byte("signers"))
+	if err != nil || len(keysRLP) == 0 {
+		return nil, err
+	}
+	if err := rlp.DecodeBytes(keysRLP, &keys); err != nil {
+		return nil, fmt.Errorf("failed to decode signer keys: %s", err)
+	}
+	return keys, nil
+}
+
+// SetEpochSignerKeys writes the signing keys of the validators of the current
+// epoch to snapshot. Nothing is stored without keys, leaving the root unchanged.
+func (snap *Snapshot) SetEpochSignerKeys(keys []SignerKey) error {
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return epochTrie.TryDelete([]byte("signers"))
+	}
+
+	keysRLP, err := rlp.EncodeToBytes(keys)
+	if err != nil {
+		return fmt.Errorf("failed to encode signer keys to rlp bytes: %s", err)
+	}
+	return epochTrie.TryUpdate([]byte("signers"), keysRLP)
+}
+
+// GetSigners returns the keys sealing the slots of the validators of the current
+// epoch, in the order of the validators.
+func (snap *Snapshot) GetSigners() ([]common.Address, error) {
+	validators, err := snap.GetValidators()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := snap.GetEpochSignerKeys()
+	if err != nil {
+		return nil, err
+	}
+	return signingKeys(validators, keys), nil
+}
+
+// signingKeys replaces the validators by the signing keys bound to them.
+func signingKeys(validators []common.Address, keys []SignerKey) []common.Address {
+	if len(keys) == 0 {
+		return validators
+	}
+	bound := make(map[common.Address]common.Address, len(keys))
+	for _, key := range keys {
+		bound[key.Validator] = key.Key
+	}
+	signers := make([]common.Address, len(validators))
+	for i, validator := range validators {
+		signers[i] = validator
+		if key, ok := bound[validator]; ok {
+			signers[i] = key
+		}
+	}
+	return signers
+}
+
+// lastElectedKey returns the epoch trie key of the last election of a candidate.
+func lastElectedKey(candidateAddr common.Address) []byte {
+	return append([]byte("elected-"), candidateAddr.Bytes()...)
+}
+
+// SetLastElected records the epoch block at which the validators were elected.
+func (snap *Snapshot) SetLastElected(validators []common.Address, number uint64) error {
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return err
+	}
+
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, number)
+	for _, validator := range validators {
+		if err := epochTrie.TryUpdate(lastElectedKey(validator), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLastElected returns the last epoch block at which the candidate was elected,
+// 0 if it never was.
+func (snap *Snapshot) GetLastElected(candidateAddr common.Address) (uint64, error) {
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := epochTrie.TryGet(lastElectedKey(candidateAddr))
+	if err != nil || len(value) != 8 {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// DormantCandidates returns the candidates which were neither registered nor
+// elected within the given number of blocks before number.
+func (snap *Snapshot) DormantCandidates(number, inactive uint64) ([]common.Address, error) {
+	candidateTrie, err := snap.ensureTrie(candidatePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	dormant := make([]common.Address, 0)
+	iterCandidate := trie.NewIterator(candidateTrie.NodeIterator(nil))
+	for iterCandidate.Next() {
+		var candidate Candidate
+		if err = rlp.DecodeBytes(iterCandidate.Value, &candidate); err != nil {
+			return nil, err
+		}
+		candidateAddr := common.BytesToAddress(iterCandidate.Key)
+		lastActive, err := snap.GetLastElected(candidateAddr)
+		if err != nil {
+			return nil, err
+		}
+		if candidate.BlockNumber > lastActive {
+			lastActive = candidate.BlockNumber
+		}
+		if number >= lastActive+inactive {
+			dormant = append(dormant, candidateAddr)
+		}
+	}
+	return dormant, nil
+}
+
+// ExpireCandidate removes a dormant candidate along with its election record.
+func (snap *Snapshot) ExpireCandidate(candidateAddr common.Address) (exist bool, security *big.Int, err error) {
+	exist, security, err = snap.CancelCandidate(candidateAddr)
+	if err != nil || !exist {
+		return exist, security, err
+	}
+
+	epochTrie, err := snap.ensureTrie(epochPrefix)
+	if err != nil {
+		return false, big.NewInt(0), err
+	}
+	if err = epochTrie.TryDelete(lastElectedKey(candidateAddr)); err != nil {
+		if _, ok := err.(*trie.MissingNodeError); !ok {
+			return false, big.NewInt(0), err
+		}
+	}
+	return true, security, nil
+}
+
 // CountMinted count the minted of each validator.
 func (snap *Snapshot) CountMinted(epoch uint64) (SortableAddresses, error) {
 	validators, err := snap.GetValidators()
@@ -472,6 +866,43 @@ func (snap *Snapshot) BecomeCandidate(
 	return false, candidateTrie.TryUpdate(key, value)
 }
 
+// SetPayout sets the address credited with the rewards of a candidate, setting
+// the candidate itself restoring the default. It returns false if the address
+// is not a candidate.
+func (snap *Snapshot) SetPayout(candidateAddr, recipient common.Address) (exist bool, err error) {
+	candidate, err := snap.GetCandidate(candidateAddr)
+	if err != nil || candidate == nil {
+		return false, err
+	}
+	candidate.Payout = recipient
+	if recipient == candidateAddr {
+		candidate.Payout = common.Address{}
+	}
+
+	candidateTrie, err := snap.ensureTrie(candidatePrefix)
+	if err != nil {
+		return false, err
+	}
+	value, err := rlp.EncodeToBytes(candidate)
+	if err != nil {
+		return false, err
+	}
+	return true, candidateTrie.TryUpdate(candidateAddr.Bytes(), value)
+}
+
+// GetPayout returns the address credited with the rewards of the blocks minted
+// by the validator, the validator itself unless it set a payout.
+func (snap *Snapshot) GetPayout(validator common.Address) (common.Address, error) {
+	candidate, err := snap.GetCandidate(validator)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if candidate == nil || candidate.Payout == (common.Address{}) {
+		return validator, nil
+	}
+	return candidate.Payout, nil
+}
+
 // CancelCandidate remove a candidate
 func (snap *Snapshot) CancelCandidate(candidateAddr common.Address) (exist bool, security *big.Int, err error) {
 	candidateTrie, err := snap.ensureTrie(candidatePrefix)
@@ -7,6 +7,7 @@ import (
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/stretchr/testify/assert"
 )
@@ -64,6 +65,87 @@ func TestSetValidators(t *testing.T) {
 	assert.Equal(t, validators[2], validator3)
 	assert.Equal(t, validators[3], validator4)
 }
+func TestBindSignerKey(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	snap, err := newSnapshot(db)
+	assert.Nil(t, err)
+
+	candidate := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	key1 := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	key2 := common.HexToAddress("0x10702d5b794d97fb720e02506ecfdb1186a804b1")
+	assert.Nil(t, snap.SetValidators([]common.Address{candidate}))
+	unbound, err := snap.Root()
+	assert.Nil(t, err)
+
+	key, err := snap.GetSignerKey(candidate)
+	assert.Nil(t, err)
+	assert.Equal(t, candidate, key)
+
+	// Rotating releases the key bound before
+	assert.Nil(t, snap.BindSignerKey(candidate, key1))
+	assert.Nil(t, snap.BindSignerKey(candidate, key2))
+	key, err = snap.GetSignerKey(candidate)
+	assert.Nil(t, err)
+	assert.Equal(t, key2, key)
+	owner, err := snap.GetSignerOf(key1)
+	assert.Nil(t, err)
+	assert.Equal(t, key1, owner)
+	owner, err = snap.GetSignerOf(key2)
+	assert.Nil(t, err)
+	assert.Equal(t, candidate, owner)
+
+	// Binding the own key of the candidate leaves the trie as it was
+	assert.Nil(t, snap.BindSignerKey(candidate, candidate))
+	assert.Nil(t, snap.SetEpochSignerKeys(nil))
+	root, err := snap.Root()
+	assert.Nil(t, err)
+	assert.Equal(t, unbound, root)
+
+	// The validators sealing with a bound key are replaced by it
+	assert.Nil(t, snap.SetValidators([]common.Address{key1, candidate}))
+	assert.Nil(t, snap.SetEpochSignerKeys([]SignerKey{{Validator: candidate, Key: key2}}))
+	signers, err := snap.GetSigners()
+	assert.Nil(t, err)
+	assert.Equal(t, []common.Address{key1, key2}, signers)
+}
+
+func TestSetPayout(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	snap, err := newSnapshot(db)
+	assert.Nil(t, err)
+
+	candidate := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	recipient := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	exist, err := snap.SetPayout(candidate, recipient)
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	_, err = snap.BecomeCandidate(candidate, 1, big.NewInt(10))
+	assert.Nil(t, err)
+	registered, err := snap.Root()
+	assert.Nil(t, err)
+
+	exist, err = snap.SetPayout(candidate, recipient)
+	assert.Nil(t, err)
+	assert.True(t, exist)
+	payout, err := snap.GetPayout(candidate)
+	assert.Nil(t, err)
+	assert.Equal(t, recipient, payout)
+	info, err := snap.GetCandidate(candidate)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(10), info.Staked)
+
+	// Setting the candidate itself restores the encoding of the candidate
+	_, err = snap.SetPayout(candidate, candidate)
+	assert.Nil(t, err)
+	payout, err = snap.GetPayout(candidate)
+	assert.Nil(t, err)
+	assert.Equal(t, candidate, payout)
+	root, err := snap.Root()
+	assert.Nil(t, err)
+	assert.Equal(t, registered, root)
+}
+
 func TestRandCandidates(t *testing.T) {
 	db := rawdb.NewMemoryDatabase()
 	snap, err := newSnapshot(db)
@@ -152,3 +234,49 @@ func TestCountMinted(t *testing.T) {
 	assert.Equal(t, result[2].Address, validator3)
 	assert.Equal(t, result[2].Weight, big.NewInt(4))
 }
+
+func TestExpireCandidates(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	snap, err := newSnapshot(db)
+	assert.Nil(t, err)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	assert.Nil(t, err)
+
+	config := params.EqualityConfig{
+		Epoch:               10,
+		MaxValidatorsCount:  1,
+		MinCandidateBalance: big.NewInt(1000),
+		CandidateExpiry:     2,
+	}
+	elected := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	dormant := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	recent := common.HexToAddress("0x6c4ab069affd856bb915ee93cb59370574f5331e")
+	_, err = snap.BecomeCandidate(elected, 1, big.NewInt(0))
+	assert.Nil(t, err)
+	_, err = snap.BecomeCandidate(dormant, 2, big.NewInt(1000))
+	assert.Nil(t, err)
+	_, err = snap.BecomeCandidate(recent, 15, big.NewInt(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, snap.SetLastElected([]common.Address{elected}, 21))
+
+	equality := New(&config, db)
+	header := &types.Header{Number: big.NewInt(31)}
+	headerExtra := HeaderExtra{Epoch: 3, EpochBlock: 31}
+	assert.Nil(t, equality.expireCandidates(config, statedb, header, snap, &headerExtra))
+
+	assert.Equal(t, []common.Address{dormant}, headerExtra.CurrentBlockExpiredCandidates)
+	assert.Equal(t, big.NewInt(1000), statedb.GetBalance(dormant))
+	candidate, err := snap.GetCandidate(dormant)
+	assert.Nil(t, err)
+	assert.Nil(t, candidate)
+	candidate, err = snap.GetCandidate(elected)
+	assert.Nil(t, err)
+	assert.NotNil(t, candidate)
+
+	// Only epoch blocks expire candidates
+	header = &types.Header{Number: big.NewInt(45)}
+	headerExtra = HeaderExtra{Epoch: 4, EpochBlock: 41}
+	assert.Nil(t, equality.expireCandidates(config, statedb, header, snap, &headerExtra))
+	assert.Equal(t, 0, len(headerExtra.CurrentBlockExpiredCandidates))
+}
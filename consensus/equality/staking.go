@@ -0,0 +1,57 @@
+package equality
+
+import (
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// processStakingLogs applies the registrations and cancellations the staking
+// contract logged in the transaction, returning how many it found.
+func processStakingLogs(state *state.StateDB, header *types.Header, snap *Snapshot, headerExtra *HeaderExtra,
+	tx *types.Transaction) int {
+
+	logs := stakingLogs(state, tx)
+	for _, log := range logs {
+		applyStakingLog(state, header, snap, headerExtra, log)
+	}
+	return len(logs)
+}
+
+// stakingLogs returns the registrations and cancellations the staking contract
+// logged in the transaction.
+func stakingLogs(state *state.StateDB, tx *types.Transaction) []*types.Log {
+	var logs []*types.Log
+	for _, log := range state.GetLogs(tx.Hash()) {
+		if log.Address != params.StakingContractAddress || len(log.Topics) != 2 {
+			continue
+		}
+		if log.Topics[0] == vm.CandidateRegisteredTopic || log.Topics[0] == vm.CandidateCancelledTopic {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+// applyStakingLog applies a registration or a cancellation logged by the staking
+// contract. The deposit escrowed by the contract is staked, or refunded to the
+// candidates already registered earlier in the block.
+func applyStakingLog(state *state.StateDB, header *types.Header, snap *Snapshot, headerExtra *HeaderExtra, log *types.Log) {
+	candidate := common.BytesToAddress(log.Topics[1].Bytes())
+	switch log.Topics[0] {
+	case vm.CandidateRegisteredTopic:
+		deposit := new(big.Int).SetBytes(log.Data)
+		state.SubBalance(params.StakingContractAddress, deposit)
+		if !registerCandidate(snap, headerExtra, candidate, header.Number.Uint64(), deposit) {
+			state.AddBalance(candidate, deposit)
+		}
+	case vm.CandidateCancelledTopic:
+		if security, ok := cancelCandidate(snap, headerExtra, candidate); ok {
+			state.AddBalance(candidate, security)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/accounts/abi"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStakingContract(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	chainConfig := *params.TestChainConfig
+	chainConfig.StakingBlock = big.NewInt(6)
+	chain.config = &chainConfig
+
+	candidate := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	legacyKey, _ := crypto.GenerateKey()
+	legacy := crypto.PubkeyToAddress(legacyKey.PublicKey)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(candidate, big.NewInt(10))
+	statedb.AddBalance(legacy, big.NewInt(10))
+
+	becomeCandidate := crypto.Keccak256([]byte("becomeCandidate()"))[:4]
+	cancelCandidate := crypto.Keccak256([]byte("cancelCandidate()"))[:4]
+
+	// mint executes the calls to the staking contract, each in a transaction of
+	// its own, and mints the block along with the other transactions.
+	type call struct {
+		from  common.Address
+		input []byte
+		value int64
+	}
+	mint := func(calls []call, others ...*types.Transaction) ([]string, *HeaderExtra) {
+		parent := chain.headers[len(chain.headers)-1]
+		header := &types.Header{
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			ParentHash: parent.Hash(),
+			Coinbase:   testUserAddress,
+		}
+		assert.Nil(t, sealer.Prepare(chain, header))
+
+		var (
+			txs     []*types.Transaction
+			reasons []string
+		)
+		for i, call := range calls {
+			tx := types.NewTransaction(uint64(i), params.StakingContractAddress, big.NewInt(call.value), 100000, new(big.Int), call.input)
+			statedb.Prepare(tx.Hash(), common.Hash{}, len(txs))
+			evm := vm.NewEVM(vm.Context{
+				CanTransfer: core.CanTransfer,
+				Transfer:    core.Transfer,
+				BlockNumber: header.Number,
+				Time:        new(big.Int),
+				Difficulty:  new(big.Int),
+				Consensus:   sealer.ConsensusReader(header, chain.GetHeader),
+			}, statedb, &chainConfig, vm.Config{})
+			output, gas, err := evm.Call(vm.AccountRef(call.from), params.StakingContractAddress, call.input, 100000, big.NewInt(call.value))
+			if err == vm.ErrExecutionReverted {
+				reason, _ := abi.UnpackRevert(output)
+				reasons = append(reasons, reason)
+				assert.Equal(t, 100000-params.StakingContractGas, gas)
+			} else {
+				assert.Nil(t, err)
+			}
+			txs = append(txs, tx)
+		}
+		for _, tx := range others {
+			statedb.Prepare(tx.Hash(), common.Hash{}, len(txs))
+			txs = append(txs, tx)
+		}
+		block, err := sealer.FinalizeAndAssemble(chain, header, statedb, txs, nil, nil)
+		assert.Nil(t, err)
+
+		header = block.Header()
+		signature, err := crypto.Sign(SealHash(header).Bytes(), testUserKey)
+		assert.Nil(t, err)
+		copy(header.Extra[len(header.Extra)-extraSeal:], signature)
+		chain.headers = append(chain.headers, header)
+
+		headerExtra, err := DecodeHeaderExtra(header)
+		assert.Nil(t, err)
+		return reasons, &headerExtra
+	}
+
+	// The custom transactions no longer register candidates after the fork
+	register, err := types.SignTx(types.NewTransaction(0, legacy, new(big.Int), 0, new(big.Int), EncodeTransaction(&EventBecomeCandidate{})),
+		types.NewEIP155Signer(big.NewInt(1)), legacyKey)
+	assert.Nil(t, err)
+
+	reasons, headerExtra := mint([]call{
+		{from: candidate, input: becomeCandidate, value: 2},
+		{from: candidate, input: cancelCandidate},
+		{from: candidate, input: []byte{0x01}},
+		{from: candidate, input: becomeCandidate, value: 1},
+		{from: candidate, input: becomeCandidate, value: 1},
+	}, register)
+	assert.Equal(t, []string{"deposit must equal the minimum candidate balance", "not a candidate", "unknown function"}, reasons)
+	assert.Equal(t, []common.Address{candidate}, headerExtra.CurrentBlockCandidates)
+	assert.Equal(t, big.NewInt(9), statedb.GetBalance(candidate))
+	assert.Equal(t, big.NewInt(10), statedb.GetBalance(legacy))
+	assert.Zero(t, statedb.GetBalance(params.StakingContractAddress).Sign())
+
+	snap, err := sealer.openSnapshot(headerExtra.Root)
+	assert.Nil(t, err)
+	staked, err := snap.GetCandidate(candidate)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(1), staked.Staked)
+
+	// Registered candidates cancel, getting their deposit back
+	reasons, headerExtra = mint([]call{
+		{from: candidate, input: becomeCandidate, value: 1},
+		{from: candidate, input: cancelCandidate, value: 1},
+		{from: candidate, input: cancelCandidate},
+	})
+	assert.Equal(t, []string{"already a candidate", "cancellation takes no value"}, reasons)
+	assert.Empty(t, headerExtra.CurrentBlockCandidates)
+	assert.Equal(t, []common.Address{candidate}, headerExtra.CurrentBlockCancelCandidates)
+	assert.Equal(t, big.NewInt(10), statedb.GetBalance(candidate))
+
+	// The contract logs the calls to the consensus engine
+	logs := statedb.GetLogs(types.NewTransaction(2, params.StakingContractAddress, new(big.Int), 100000, new(big.Int), cancelCandidate).Hash())
+	assert.Len(t, logs, 1)
+	assert.Equal(t, []common.Hash{vm.CandidateCancelledTopic, common.BytesToHash(candidate.Bytes())}, logs[0].Topics)
+}
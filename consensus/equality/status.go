@@ -0,0 +1,149 @@
+package equality
+
+import (
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// Roles of the local signer reported by NodeStatus.
+const (
+	RoleActive    = "active"    // Validator of the current epoch
+	RoleCandidate = "candidate" // Registered candidate, not elected in the current epoch
+	RoleNone      = "none"      // Neither a validator nor a candidate
+)
+
+// NodeStatus is the consensus status of the local node at a given block.
+type NodeStatus struct {
+	Epoch      uint64         // Epoch of the block
+	Signer     common.Address // Signer of the node, zero if it doesn't mint
+	Role       string         // Role of the signer in the epoch, see the Role constants
+	LastSealed uint64         // Last block sealed by the node since it started, 0 if none
+}
+
+// NodeStatus returns the consensus status of the local node at the given block.
+// Light clients don't keep the candidates, their signer is either active or none.
+func (e *Equality) NodeStatus(header *types.Header) (NodeStatus, error) {
+	e.lock.RLock()
+	status := NodeStatus{Signer: e.signer, Role: RoleNone, LastSealed: e.lastSealed}
+	e.lock.RUnlock()
+
+	if header.Number.Uint64() < e.start {
+		if status.Signer != (common.Address{}) && hasAddress(e.config.Validators, status.Signer) {
+			status.Role = RoleActive
+		}
+		return status, nil
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return status, err
+	}
+	status.Epoch = headerExtra.Epoch
+	if status.Signer == (common.Address{}) {
+		return status, nil
+	}
+	if e.lightMode() {
+		validators := signingKeys(headerExtra.CurrentEpochValidators, headerExtra.CurrentEpochSignerKeys)
+		if cached, ok := e.lightValidators.Get(header.Hash()); ok {
+			validators = cached.([]common.Address)
+		} else if header.Number.Uint64() != headerExtra.EpochBlock {
+			return status, errUnknownEpoch
+		}
+		if hasAddress(validators, status.Signer) {
+			status.Role = RoleActive
+		}
+		return status, nil
+	}
+	snap, err := e.openSnapshot(headerExtra.Root)
+	if err != nil {
+		return status, err
+	}
+	validators, err := snap.GetSigners()
+	if err != nil {
+		return status, err
+	}
+	if hasAddress(validators, status.Signer) {
+		status.Role = RoleActive
+		return status, nil
+	}
+	// The signer may be the key bound to a candidate, effective from next epoch
+	identity, err := snap.GetSignerOf(status.Signer)
+	if err != nil {
+		return status, err
+	}
+	candidate, err := snap.GetCandidate(identity)
+	if err != nil {
+		return status, err
+	}
+	if candidate != nil {
+		status.Role = RoleCandidate
+	}
+	return status, nil
+}
+
+// NextSlot returns the unix time of the next slot of the local signer following
+// the given time, scheduled among the validators after the given block. The
+// schedule being reshuffled at every epoch, the slot is only an estimation once
+// the next epoch starts. Light clients don't know the schedule and don't mint.
+func (e *Equality) NextSlot(header *types.Header, now uint64) (uint64, error) {
+	e.lock.RLock()
+	signer := e.signer
+	e.lock.RUnlock()
+
+	if signer == (common.Address{}) || e.lightMode() {
+		return 0, errUnauthorized
+	}
+	var (
+		config     params.EqualityConfig
+		validators []common.Address
+		err        error
+	)
+	if header.Number.Uint64() < e.start {
+		config, validators = *e.config, e.config.Validators
+	} else if validators, config, err = e.scheduledValidators(header); err != nil {
+		return 0, err
+	}
+	return nextSlot(config, validators, signer, now)
+}
+
+// nextSlot returns the unix time of the first slot of the signer from the given
+// time on, in a fixed schedule of validators.
+func nextSlot(config params.EqualityConfig, validators []common.Address, signer common.Address, now uint64) (uint64, error) {
+	if config.Period == 0 || len(validators) == 0 {
+		return 0, errUnauthorized
+	}
+	if now < config.GenesisTimestamp {
+		now = config.GenesisTimestamp
+	}
+	slot := (now - config.GenesisTimestamp + config.Period - 1) / config.Period
+	for i := uint64(0); i < uint64(len(validators)); i++ {
+		if validators[(slot+i)%uint64(len(validators))] == signer {
+			return config.GenesisTimestamp + (slot+i)*config.Period, nil
+		}
+	}
+	return 0, errUnauthorized
+}
+
+// SnapshotAvailable returns if the root nodes of all the snapshot tries of the
+// given block are in the database. It's a cheap check meant for health probes,
+// see VerifySnapshot for a full one.
+func (e *Equality) SnapshotAvailable(header *types.Header) bool {
+	if header.Number.Uint64() < e.start || e.lightMode() {
+		return true
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return false
+	}
+	return e.snapshotAvailable(headerExtra.Root)
+}
+
+// hasAddress returns if the address is in the list.
+func hasAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package equality
+
+import (
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeStatus(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	head := chain.headers[len(chain.headers)-1]
+	headExtra, err := DecodeHeaderExtra(head)
+	assert.Nil(t, err)
+
+	// Nodes without a signer have no role
+	status, err := sealer.NodeStatus(head)
+	assert.Nil(t, err)
+	assert.Equal(t, headExtra.Epoch, status.Epoch)
+	assert.Equal(t, RoleNone, status.Role)
+
+	sealer.Authorize(testUserAddress, func(accounts.Account, string, []byte) ([]byte, error) { return nil, nil })
+	status, err = sealer.NodeStatus(head)
+	assert.Nil(t, err)
+	assert.Equal(t, RoleActive, status.Role)
+
+	sealer.Authorize(common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c"), nil)
+	status, err = sealer.NodeStatus(head)
+	assert.Nil(t, err)
+	assert.Equal(t, RoleNone, status.Role)
+}
+
+func TestNextSlot(t *testing.T) {
+	first := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	second := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	validators := []common.Address{first, second}
+	config := params.EqualityConfig{Period: 5, GenesisTimestamp: 100}
+
+	// Slots start on the period boundaries and alternate between the validators
+	slot, err := nextSlot(config, validators, first, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), slot)
+	slot, err = nextSlot(config, validators, first, 101)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(110), slot)
+	slot, err = nextSlot(config, validators, second, 101)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(105), slot)
+
+	// Before the genesis the first slot is at the genesis
+	slot, err = nextSlot(config, validators, second, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(105), slot)
+
+	_, err = nextSlot(config, validators, common.Address{1}, 100)
+	assert.Equal(t, errUnauthorized, err)
+}
@@ -0,0 +1,156 @@
+package equality
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/math"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/event"
+	"github.com/SecretBlockChain/go-secret/rpc"
+)
+
+// chainEventChanSize is the size of channel listening to ChainEvent.
+const chainEventChanSize = 10
+
+// errSubscriptionUnsupported is returned if the chain the API is attached to
+// doesn't publish chain events.
+var errSubscriptionUnsupported = errors.New("chain events not supported")
+
+// chainEventSubscriber is the part of the blockchain publishing canonical blocks.
+type chainEventSubscriber interface {
+	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+}
+
+type rpcValidatorSet struct {
+	BlockNumber *math.HexOrDecimal256 `json:"blockNumber"`
+	BlockHash   common.Hash           `json:"blockHash"`
+	Epoch       uint64                `json:"epoch"`
+	Validators  []common.Address      `json:"validators"`
+}
+
+type rpcSealedBlock struct {
+	Signer      common.Address        `json:"signer"`
+	BlockNumber *math.HexOrDecimal256 `json:"blockNumber"`
+	BlockHash   common.Hash           `json:"blockHash"`
+}
+
+type rpcCandidateNotification struct {
+	Address     common.Address        `json:"address"`
+	Event       string                `json:"event"`
+	BlockNumber *math.HexOrDecimal256 `json:"blockNumber"`
+	BlockHash   common.Hash           `json:"blockHash"`
+	Epoch       uint64                `json:"epoch"`
+}
+
+// subscribeHeaderExtras feeds the HeaderExtra of every new canonical block to
+// the callback until the subscription is closed.
+func (api *API) subscribeHeaderExtras(ctx context.Context, fn func(notifier *rpc.Notifier, id rpc.ID, ev core.ChainEvent, headerExtra HeaderExtra)) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	chain, ok := api.chain.(chainEventSubscriber)
+	if !ok {
+		return &rpc.Subscription{}, errSubscriptionUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan core.ChainEvent, chainEventChanSize)
+		eventsSub := chain.SubscribeChainEvent(events)
+		defer eventsSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				headerExtra, err := DecodeHeaderExtra(ev.Block.Header())
+				if err != nil {
+					continue
+				}
+				fn(notifier, rpcSub.ID, ev, headerExtra)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// ValidatorSetChanged creates a subscription that fires at every epoch transition
+// with the validators elected for the new epoch.
+func (api *API) ValidatorSetChanged(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribeHeaderExtras(ctx, func(notifier *rpc.Notifier, id rpc.ID, ev core.ChainEvent, headerExtra HeaderExtra) {
+		number := ev.Block.NumberU64()
+		if number != headerExtra.EpochBlock {
+			return
+		}
+		notifier.Notify(id, rpcValidatorSet{
+			BlockNumber: math.NewHexOrDecimal256(int64(number)),
+			BlockHash:   ev.Hash,
+			Epoch:       headerExtra.Epoch,
+			Validators:  headerExtra.CurrentEpochValidators,
+		})
+	})
+}
+
+// CandidateEvents creates a subscription that fires for every candidate
+// registration, cancellation and kick-out included in the canonical chain.
+func (api *API) CandidateEvents(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribeHeaderExtras(ctx, func(notifier *rpc.Notifier, id rpc.ID, ev core.ChainEvent, headerExtra HeaderExtra) {
+		notify := func(addresses []common.Address, kind uint8) {
+			for _, address := range addresses {
+				notifier.Notify(id, rpcCandidateNotification{
+					Address:     address,
+					Event:       candidateEventNames[kind],
+					BlockNumber: math.NewHexOrDecimal256(ev.Block.Number().Int64()),
+					BlockHash:   ev.Hash,
+					Epoch:       headerExtra.Epoch,
+				})
+			}
+		}
+		notify(headerExtra.CurrentBlockCandidates, candidateEventRegister)
+		notify(headerExtra.CurrentBlockCancelCandidates, candidateEventCancel)
+		notify(headerExtra.CurrentBlockKickOutCandidates, candidateEventKickOut)
+	})
+}
+
+// BlockSealed creates a subscription that fires for every block sealed by the
+// local signer, before it's imported into the chain.
+func (api *API) BlockSealed(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		sealed := make(chan BlockSealedEvent, chainEventChanSize)
+		sealedSub := api.equality.SubscribeBlockSealed(sealed)
+		defer sealedSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-sealed:
+				notifier.Notify(rpcSub.ID, rpcSealedBlock{
+					Signer:      ev.Signer,
+					BlockNumber: math.NewHexOrDecimal256(int64(ev.Number)),
+					BlockHash:   ev.Hash,
+				})
+			case <-sealedSub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,74 @@
+package equality
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/event"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testChainEvents is a chain header reader publishing chain events.
+type testChainEvents struct {
+	consensus.ChainHeaderReader
+	feed event.Feed
+}
+
+func (chain *testChainEvents) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return chain.feed.Subscribe(ch)
+}
+
+func newTestBlock(t *testing.T, number uint64, headerExtra HeaderExtra) *types.Block {
+	data, err := headerExtra.Encode()
+	assert.Nil(t, err)
+
+	extra := append(bytes.Repeat([]byte{0x00}, extraVanity), data...)
+	extra = append(extra, bytes.Repeat([]byte{0x00}, extraSeal)...)
+	return types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(number), Extra: extra})
+}
+
+func TestValidatorSetChangedSubscription(t *testing.T) {
+	chain := new(testChainEvents)
+	equality := New(&params.EqualityConfig{MinCandidateBalance: big.NewInt(0)}, rawdb.NewMemoryDatabase())
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	assert.Nil(t, server.RegisterName("eq", &API{chain: chain, equality: equality}))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	validatorSets := make(chan rpcValidatorSet)
+	sub, err := client.Subscribe(context.Background(), "eq", validatorSets, "validatorSetChanged")
+	assert.Nil(t, err)
+	defer sub.Unsubscribe()
+
+	validators := []common.Address{common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")}
+	for chain.feed.Send(core.ChainEvent{Block: newTestBlock(t, 5, HeaderExtra{Epoch: 1, EpochBlock: 1})}) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	chain.feed.Send(core.ChainEvent{Block: newTestBlock(t, 6, HeaderExtra{
+		Epoch:                  2,
+		EpochBlock:             6,
+		CurrentEpochValidators: validators,
+	})})
+
+	select {
+	case set := <-validatorSets:
+		assert.Equal(t, uint64(2), set.Epoch)
+		assert.Equal(t, validators, set.Validators)
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(time.Second):
+		t.Fatal("validator set change not notified")
+	}
+}
@@ -0,0 +1,106 @@
+package equality
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// Storage slots of the system contracts, laid out like the state variables of
+// Solidity contracts declaring
+//
+//	staking:    uint256 epoch; address[] validators; mapping(address => uint256) deposits; address[] candidates;
+//	governance: uint256 quorum; uint256 haltQuorum; uint256 deposit;
+//
+// so that the contracts deployed at genesis read them as their own variables.
+var (
+	stakingEpochSlot      = common.BigToHash(big.NewInt(0))
+	stakingValidatorsSlot = common.BigToHash(big.NewInt(1))
+	stakingDepositsSlot   = common.BigToHash(big.NewInt(2))
+	stakingCandidatesSlot = common.BigToHash(big.NewInt(3))
+
+	governanceQuorumSlot     = common.BigToHash(big.NewInt(0))
+	governanceHaltQuorumSlot = common.BigToHash(big.NewInt(1))
+	governanceDepositSlot    = common.BigToHash(big.NewInt(2))
+)
+
+// updateSystemContracts writes the validators of the epoch starting with the
+// block, the candidates along with their deposits and the approvals proposals
+// require into the storage of the system contracts deployed at genesis.
+func (e *Equality) updateSystemContracts(config params.EqualityConfig, state *state.StateDB, epoch uint64,
+	validators []common.Address, candidates map[common.Address]Candidate) {
+
+	if address, ok := e.config.SystemContract("staking"); ok {
+		state.SetState(address, stakingEpochSlot, common.BigToHash(new(big.Int).SetUint64(epoch)))
+		writeAddressArray(state, address, stakingValidatorsSlot, validators)
+		writeDeposits(state, address, candidates)
+	}
+	if address, ok := e.config.SystemContract("governance"); ok {
+		state.SetState(address, governanceQuorumSlot, common.BigToHash(big.NewInt(int64(proposalQuorum(config, len(validators))))))
+		state.SetState(address, governanceHaltQuorumSlot, common.BigToHash(big.NewInt(int64(haltQuorum(config, len(validators))))))
+		state.SetState(address, governanceDepositSlot, common.BigToHash(config.RequiredDeposit()))
+	}
+}
+
+// writeDeposits stores the candidates of the staking contract, sorted, and their
+// deposits, clearing the deposits of the candidates stored before and gone since.
+func writeDeposits(state *state.StateDB, address common.Address, candidates map[common.Address]Candidate) {
+	for _, candidate := range readAddressArray(state, address, stakingCandidatesSlot) {
+		if _, ok := candidates[candidate]; !ok {
+			state.SetState(address, mappingSlot(stakingDepositsSlot, candidate), common.Hash{})
+		}
+	}
+	sorted := make([]common.Address, 0, len(candidates))
+	for candidate, info := range candidates {
+		deposit := new(big.Int)
+		if info.Staked != nil {
+			deposit = info.Staked
+		}
+		state.SetState(address, mappingSlot(stakingDepositsSlot, candidate), common.BigToHash(deposit))
+		sorted = append(sorted, candidate)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Bytes(), sorted[j].Bytes()) < 0
+	})
+	writeAddressArray(state, address, stakingCandidatesSlot, sorted)
+}
+
+// mappingSlot returns the slot of the value of the key in the mapping at the slot.
+func mappingSlot(slot common.Hash, key common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(key.Bytes(), 32), slot.Bytes())
+}
+
+// arrayElement returns the slot of the element of the dynamic array at the slot.
+func arrayElement(slot common.Hash, i int) common.Hash {
+	base := new(big.Int).SetBytes(crypto.Keccak256(slot.Bytes()))
+	return common.BigToHash(base.Add(base, big.NewInt(int64(i))))
+}
+
+// readAddressArray loads the addresses stored as a dynamic array at the slot.
+func readAddressArray(state *state.StateDB, address common.Address, slot common.Hash) []common.Address {
+	length := state.GetState(address, slot).Big().Uint64()
+	values := make([]common.Address, length)
+	for i := range values {
+		values[i] = common.BytesToAddress(state.GetState(address, arrayElement(slot, i)).Bytes())
+	}
+	return values
+}
+
+// writeAddressArray stores the addresses as a dynamic array at the slot, the
+// length in the slot and the elements from the hash of the slot on, clearing
+// the elements of a longer array stored before.
+func writeAddressArray(state *state.StateDB, address common.Address, slot common.Hash, values []common.Address) {
+	stored := state.GetState(address, slot).Big()
+	for i := len(values); stored.Cmp(big.NewInt(int64(i))) > 0; i++ {
+		state.SetState(address, arrayElement(slot, i), common.Hash{})
+	}
+	for i, value := range values {
+		state.SetState(address, arrayElement(slot, i), common.BytesToHash(value.Bytes()))
+	}
+	state.SetState(address, slot, common.BigToHash(big.NewInt(int64(len(values)))))
+}
@@ -0,0 +1,89 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemContracts(t *testing.T) {
+	governance := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	config := testSnapshotConfig()
+	config.ProposalDeposit = big.NewInt(10)
+	config.SystemContracts = []params.EqualitySystemContract{{Name: "staking"}, {Name: "governance", Address: governance}}
+	sealer, chain := makeSnapshotChain(t, &config)
+	staking := params.SystemContractAddresses["staking"]
+
+	// The validators of the epoch are written in the first block only
+	for i := 0; i < 2; i++ {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetCode(staking, []byte{0x00})
+		statedb.SetCode(governance, []byte{0x00})
+		header := mintTestBlock(t, sealer, chain, statedb, nil)
+		headerExtra, err := DecodeHeaderExtra(header)
+		assert.Nil(t, err)
+		if header.Number.Uint64() != headerExtra.EpochBlock {
+			assert.Equal(t, common.Hash{}, statedb.GetState(staking, stakingValidatorsSlot))
+			continue
+		}
+		assert.Equal(t, common.BigToHash(new(big.Int).SetUint64(headerExtra.Epoch)), statedb.GetState(staking, stakingEpochSlot))
+		assert.Equal(t, common.BigToHash(big.NewInt(1)), statedb.GetState(staking, stakingValidatorsSlot))
+		element := common.BytesToHash(crypto.Keccak256(stakingValidatorsSlot.Bytes()))
+		assert.Equal(t, common.BytesToHash(testUserAddress.Bytes()), statedb.GetState(staking, element))
+		assert.Equal(t, []common.Address{testUserAddress}, readAddressArray(statedb, staking, stakingCandidatesSlot))
+		assert.Equal(t, common.Hash{}, statedb.GetState(staking, mappingSlot(stakingDepositsSlot, testUserAddress)))
+
+		assert.Equal(t, common.BigToHash(big.NewInt(1)), statedb.GetState(governance, governanceQuorumSlot))
+		assert.Equal(t, common.BigToHash(big.NewInt(1)), statedb.GetState(governance, governanceHaltQuorumSlot))
+		assert.Equal(t, common.BigToHash(big.NewInt(10)), statedb.GetState(governance, governanceDepositSlot))
+		assert.Nil(t, sealer.verifyCascadingFields(chain, header, nil))
+	}
+}
+
+func TestWriteAddressArray(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	contract, slot := common.Address{0x01}, common.BigToHash(big.NewInt(1))
+	base := new(big.Int).SetBytes(crypto.Keccak256(slot.Bytes()))
+	element := func(i int64) common.Hash {
+		return statedb.GetState(contract, common.BigToHash(new(big.Int).Add(base, big.NewInt(i))))
+	}
+	writeAddressArray(statedb, contract, slot, []common.Address{{0x0a}, {0x0b}, {0x0c}})
+	assert.Equal(t, common.BigToHash(big.NewInt(3)), statedb.GetState(contract, slot))
+	assert.Equal(t, common.BytesToHash(common.Address{0x0c}.Bytes()), element(2))
+
+	// Shrinking the array clears the elements dropped
+	writeAddressArray(statedb, contract, slot, []common.Address{{0x0d}})
+	assert.Equal(t, common.BigToHash(big.NewInt(1)), statedb.GetState(contract, slot))
+	assert.Equal(t, common.BytesToHash(common.Address{0x0d}.Bytes()), element(0))
+	assert.Equal(t, common.Hash{}, element(1))
+	assert.Equal(t, common.Hash{}, element(2))
+}
+
+func TestWriteDeposits(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	contract := common.Address{0x01}
+	deposit := func(candidate common.Address) uint64 {
+		return statedb.GetState(contract, mappingSlot(stakingDepositsSlot, candidate)).Big().Uint64()
+	}
+	writeDeposits(statedb, contract, map[common.Address]Candidate{
+		{0x0c}: {Staked: big.NewInt(3)},
+		{0x0a}: {Staked: big.NewInt(1)},
+		{0x0b}: {},
+	})
+	assert.Equal(t, []common.Address{{0x0a}, {0x0b}, {0x0c}}, readAddressArray(statedb, contract, stakingCandidatesSlot))
+	assert.Equal(t, uint64(3), deposit(common.Address{0x0c}))
+	assert.Equal(t, uint64(0), deposit(common.Address{0x0b}))
+
+	// The deposits of the candidates gone are cleared
+	writeDeposits(statedb, contract, map[common.Address]Candidate{{0x0b}: {Staked: big.NewInt(2)}})
+	assert.Equal(t, []common.Address{{0x0b}}, readAddressArray(statedb, contract, stakingCandidatesSlot))
+	assert.Equal(t, uint64(0), deposit(common.Address{0x0a}))
+	assert.Equal(t, uint64(0), deposit(common.Address{0x0c}))
+	assert.Equal(t, uint64(2), deposit(common.Address{0x0b}))
+}
@@ -0,0 +1,348 @@
+package equality
+
+import (
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// Actions of the consensus effects reported to the tracers.
+const (
+	EffectLock         = "lock"         // Security deposit taken from the balance of a candidate, or from the escrow of the staking contract
+	EffectRefund       = "refund"       // Security deposit given back to a canceling candidate
+	EffectExpiryRefund = "expiryRefund" // Security deposit given back to an expired candidate
+	EffectReward       = "reward"       // Block reward credited to the coinbase
+	EffectPoolReward   = "poolReward"   // Block reward credited to the pool
+	EffectBaseFee      = "baseFee"      // Base fee of the block credited to the pool
+
+	EffectProposalLock   = "proposalLock"   // Proposal deposit taken from the balance of the proposer
+	EffectProposalRefund = "proposalRefund" // Proposal deposit given back once the quorum approves the proposal
+	EffectPoolSpend      = "poolSpend"      // Amount of an approved spending transferred from the pool to the recipient
+
+	EffectRegisterCandidate = "registerCandidate" // Candidate added to the snapshot
+	EffectCancelCandidate   = "cancelCandidate"   // Candidate removed from the snapshot
+	EffectExpireCandidate   = "expireCandidate"   // Dormant candidate removed from the snapshot
+	EffectKickOutCandidate  = "kickOutCandidate"  // Inactive validator removed from the snapshot
+	EffectElectValidator    = "electValidator"    // Validator elected for the new epoch
+	EffectBindSigner        = "bindSigner"        // Signing key bound to a candidate
+	EffectSetPayout         = "setPayout"         // Reward recipient set by a candidate
+	EffectPropose           = "propose"           // Config proposal submitted
+	EffectVote              = "vote"              // Vote cast on a proposal by a validator
+	EffectExecuteProposal   = "executeProposal"   // Approved config activated, halt or fork set, signal closed, reported for the proposer
+	EffectProposeHalt       = "proposeHalt"       // Halt proposal submitted
+	EffectProposeFork       = "proposeFork"       // Fork proposal submitted
+	EffectProposeSignal     = "proposeSignal"     // Signal proposal submitted
+	EffectProposeSpend      = "proposeSpend"      // Pool spending proposal submitted
+	EffectExpireProposal    = "expireProposal"    // Proposal dropped unapproved past its deadline, reported for the proposer
+)
+
+// ConsensusEffect is a side effect of a block applied by the consensus engine
+// outside of the EVM, either a balance change or a snapshot mutation.
+type ConsensusEffect struct {
+	Action  string         `json:"action"`
+	Address common.Address `json:"address"`
+	Amount  *hexutil.Big   `json:"amount,omitempty"` // Signed balance change, nil for snapshot mutations
+}
+
+// ConsensusEffects are the consensus side effects of a block, split between the
+// custom transactions causing them and the block itself, along with the credits
+// of the block rewards.
+type ConsensusEffects struct {
+	Transactions map[common.Hash][]ConsensusEffect `json:"transactions"`
+	Block        []ConsensusEffect                 `json:"block"`
+	Rewards      []RewardTransfer                  `json:"rewards"`
+}
+
+// Types of the reward credits.
+const (
+	RewardTypeBlock   = "blockReward" // Share of the block reward credited to the payout of the coinbase
+	RewardTypePool    = "poolReward"  // Share of the block reward credited to the pool
+	RewardTypeBaseFee = "baseFee"     // Base fee of the block credited to the pool
+)
+
+// RewardTransfer is a credit of the block rewards, shaped like the internal
+// transactions of the call tracers so that explorers reconcile the balances
+// with the value transfers of the contracts. The rewards being minted, they
+// are sent from the zero address.
+type RewardTransfer struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+}
+
+// newRewardTransfer returns the credit of the amount to the address.
+func newRewardTransfer(kind string, to common.Address, amount *big.Int) RewardTransfer {
+	return RewardTransfer{Type: kind, To: to, Value: (*hexutil.Big)(new(big.Int).Set(amount))}
+}
+
+// rewardEffects maps the types of the reward credits to the consensus effects.
+var rewardEffects = map[string]string{
+	RewardTypeBlock:   EffectReward,
+	RewardTypePool:    EffectPoolReward,
+	RewardTypeBaseFee: EffectBaseFee,
+}
+
+// TraceConsensusEffects replays the finalization of a block to report the side
+// effects invisible to the EVM tracers. The state is the one following the
+// transactions of the block, it isn't modified.
+func (e *Equality) TraceConsensusEffects(chain consensus.ChainHeaderReader, block *types.Block, statedb *state.StateDB) (*ConsensusEffects, error) {
+	header := block.Header()
+	number := header.Number.Uint64()
+
+	var (
+		snap *Snapshot
+		err  error
+	)
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	if number <= e.start {
+		snap, err = e.createSnapshot()
+	} else {
+		var parentExtra HeaderExtra
+		if parentExtra, err = DecodeHeaderExtra(parent); err != nil {
+			return nil, err
+		}
+		snap, err = e.openSnapshot(parentExtra.Root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	config, err := e.chainConfig(parent)
+	if err != nil {
+		return nil, err
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	effects := &ConsensusEffects{
+		Transactions: make(map[common.Hash][]ConsensusEffect),
+		Block:        make([]ConsensusEffect, 0),
+		Rewards:      make([]RewardTransfer, 0),
+	}
+	statedb = statedb.Copy()
+
+	// Replay the finalization in the same order as Finalize
+	balance := func(address common.Address, action string, apply func()) []ConsensusEffect {
+		before := statedb.GetBalance(address)
+		apply()
+		if diff := new(big.Int).Sub(statedb.GetBalance(address), before); diff.Sign() != 0 {
+			return []ConsensusEffect{{Action: action, Address: address, Amount: (*hexutil.Big)(diff)}}
+		}
+		return nil
+	}
+	temp := HeaderExtra{Root: headerExtra.Root, Epoch: headerExtra.Epoch, EpochBlock: headerExtra.EpochBlock}
+	recipient, err := rewardRecipient(chain.Config(), snap, header, &temp)
+	if err != nil {
+		return nil, err
+	}
+	effects.Rewards = e.accumulateRewards(config, statedb, header, recipient)
+	for _, transfer := range effects.Rewards {
+		effects.Block = append(effects.Block, ConsensusEffect{Action: rewardEffects[transfer.Type], Address: transfer.To, Amount: transfer.Value})
+	}
+
+	for _, tx := range block.Transactions() {
+		ctx, err := e.decodeTransaction(chain.Config(), header.Number, tx)
+		if err != nil {
+			if chain.Config().IsStaking(header.Number) {
+				if txEffects := e.traceStakingLogs(statedb, header, snap, &temp, tx, balance); len(txEffects) > 0 {
+					effects.Transactions[tx.Hash()] = append(effects.Transactions[tx.Hash()], txEffects...)
+				}
+			}
+			continue
+		}
+		var txEffects []ConsensusEffect
+		switch ctx := ctx.(type) {
+		case *EventBecomeCandidate:
+			registered := len(temp.CurrentBlockCandidates)
+			txEffects = balance(ctx.Candidate, EffectLock, func() {
+				e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			})
+			if len(temp.CurrentBlockCandidates) > registered {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectRegisterCandidate, Address: ctx.Candidate})
+			}
+		case *EventCancelCandidate:
+			canceled := len(temp.CurrentBlockCancelCandidates)
+			txEffects = balance(ctx.Delegator, EffectRefund, func() {
+				e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			})
+			if len(temp.CurrentBlockCancelCandidates) > canceled {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectCancelCandidate, Address: ctx.Delegator})
+			}
+		case *EventBindSigner:
+			bound := len(temp.CurrentBlockSignerKeys)
+			e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			if len(temp.CurrentBlockSignerKeys) > bound {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectBindSigner, Address: ctx.Candidate})
+			}
+		case *EventSetPayout:
+			set := len(temp.CurrentBlockPayouts)
+			e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			if len(temp.CurrentBlockPayouts) > set {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectSetPayout, Address: ctx.Candidate})
+			}
+		case *EventPropose:
+			proposed := len(temp.CurrentBlockProposals)
+			txEffects = balance(ctx.Proposer, EffectProposalLock, func() {
+				e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			})
+			if len(temp.CurrentBlockProposals) > proposed {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectPropose, Address: ctx.Proposer})
+			}
+		case *EventHalt:
+			proposed := len(temp.CurrentBlockProposals)
+			txEffects = balance(ctx.Proposer, EffectProposalLock, func() {
+				e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			})
+			if len(temp.CurrentBlockProposals) > proposed {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectProposeHalt, Address: ctx.Proposer})
+			}
+		case *EventFork:
+			proposed := len(temp.CurrentBlockProposals)
+			txEffects = balance(ctx.Proposer, EffectProposalLock, func() {
+				e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			})
+			if len(temp.CurrentBlockProposals) > proposed {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectProposeFork, Address: ctx.Proposer})
+			}
+		case *EventSignal:
+			proposed := len(temp.CurrentBlockProposals)
+			txEffects = balance(ctx.Proposer, EffectProposalLock, func() {
+				e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			})
+			if len(temp.CurrentBlockProposals) > proposed {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectProposeSignal, Address: ctx.Proposer})
+			}
+		case *EventSpend:
+			proposed := len(temp.CurrentBlockProposals)
+			txEffects = balance(ctx.Proposer, EffectProposalLock, func() {
+				e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			})
+			if len(temp.CurrentBlockProposals) > proposed {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectProposeSpend, Address: ctx.Proposer})
+			}
+		case *EventVote:
+			voted := len(temp.CurrentBlockVotes)
+			e.processTransactions(config, chain.Config(), statedb, header, snap, &temp, []*types.Transaction{tx})
+			if len(temp.CurrentBlockVotes) > voted {
+				txEffects = append(txEffects, ConsensusEffect{Action: EffectVote, Address: ctx.Validator})
+			}
+		}
+		if len(txEffects) > 0 {
+			effects.Transactions[tx.Hash()] = append(effects.Transactions[tx.Hash()], txEffects...)
+		}
+	}
+	e.traceEpochEffects(config, statedb, header, snap, &temp, effects)
+	return effects, nil
+}
+
+// traceStakingLogs replays the calls to the staking contract logged in the
+// transaction, reporting the deposits the contract escrowed as locked from its
+// balance and the deposits given back as refunds.
+func (e *Equality) traceStakingLogs(statedb *state.StateDB, header *types.Header, snap *Snapshot, headerExtra *HeaderExtra,
+	tx *types.Transaction, balance func(common.Address, string, func()) []ConsensusEffect) []ConsensusEffect {
+
+	var effects []ConsensusEffect
+	for _, log := range stakingLogs(statedb, tx) {
+		candidate := common.BytesToAddress(log.Topics[1].Bytes())
+		registered, canceled := len(headerExtra.CurrentBlockCandidates), len(headerExtra.CurrentBlockCancelCandidates)
+
+		var refund []ConsensusEffect
+		lock := balance(params.StakingContractAddress, EffectLock, func() {
+			refund = balance(candidate, EffectRefund, func() {
+				applyStakingLog(statedb, header, snap, headerExtra, log)
+			})
+		})
+		effects = append(append(effects, lock...), refund...)
+		if len(headerExtra.CurrentBlockCandidates) > registered {
+			effects = append(effects, ConsensusEffect{Action: EffectRegisterCandidate, Address: candidate})
+		}
+		if len(headerExtra.CurrentBlockCancelCandidates) > canceled {
+			effects = append(effects, ConsensusEffect{Action: EffectCancelCandidate, Address: candidate})
+		}
+	}
+	return effects
+}
+
+// traceEpochEffects replays the expiries, the proposals and the election of the
+// first block of an epoch, appending their effects to the ones of the block.
+func (e *Equality) traceEpochEffects(config params.EqualityConfig, statedb *state.StateDB, header *types.Header,
+	snap *Snapshot, headerExtra *HeaderExtra, effects *ConsensusEffects) {
+
+	// Remember the balances of the candidates which may expire to report refunds
+	balances := make(map[common.Address]*big.Int)
+	if number := header.Number.Uint64(); config.CandidateExpiry > 0 && number > e.start && number == headerExtra.EpochBlock {
+		dormant, _ := snap.DormantCandidates(number, config.CandidateExpiry*config.Epoch)
+		for _, candidate := range dormant {
+			balances[candidate] = statedb.GetBalance(candidate)
+		}
+	}
+	if err := e.expireCandidates(config, statedb, header, snap, headerExtra); err != nil {
+		return
+	}
+	for _, candidate := range headerExtra.CurrentBlockExpiredCandidates {
+		effects.Block = append(effects.Block, ConsensusEffect{Action: EffectExpireCandidate, Address: candidate})
+		if before, ok := balances[candidate]; ok {
+			if diff := new(big.Int).Sub(statedb.GetBalance(candidate), before); diff.Sign() != 0 {
+				effects.Block = append(effects.Block, ConsensusEffect{Action: EffectExpiryRefund, Address: candidate, Amount: (*hexutil.Big)(diff)})
+			}
+		}
+	}
+	// Remember the proposers of the proposals which may expire, their records
+	// being pruned along, and the deposits of the pending ones which may be
+	// refunded without executing them
+	var (
+		expired []common.Address
+		pending = make(map[common.Hash]*ProposalRecord)
+	)
+	if number := header.Number.Uint64(); config.ProposalWindow > 0 && number > e.start && number == headerExtra.EpochBlock {
+		ids, _ := snap.ExpiredProposals(number)
+		for _, id := range ids {
+			if proposal, err := snap.GetProposal(id); err == nil && proposal != nil {
+				expired = append(expired, proposal.Proposer)
+			}
+		}
+		ids, _ = snap.GetPendingProposals()
+		for _, id := range ids {
+			if proposal, err := snap.GetProposal(id); err == nil && proposal != nil {
+				pending[id] = proposal
+			}
+		}
+	}
+	if err := e.tryElect(config, statedb, header, snap, headerExtra); err != nil {
+		return
+	}
+	for _, proposer := range expired {
+		effects.Block = append(effects.Block, ConsensusEffect{Action: EffectExpireProposal, Address: proposer})
+	}
+	for _, id := range headerExtra.RefundedProposals {
+		if proposal := pending[id]; proposal != nil {
+			effects.Block = append(effects.Block, ConsensusEffect{Action: EffectProposalRefund, Address: proposal.Proposer, Amount: (*hexutil.Big)(proposal.Deposit)})
+		}
+	}
+	for _, id := range headerExtra.ExecutedProposals {
+		if proposal, err := snap.GetProposal(id); err == nil && proposal != nil {
+			effects.Block = append(effects.Block, ConsensusEffect{Action: EffectExecuteProposal, Address: proposal.Proposer})
+			if proposal.Deposit != nil && proposal.Deposit.Sign() > 0 {
+				effects.Block = append(effects.Block, ConsensusEffect{Action: EffectProposalRefund, Address: proposal.Proposer, Amount: (*hexutil.Big)(proposal.Deposit)})
+			}
+			if proposal.spending() {
+				effects.Block = append(effects.Block, ConsensusEffect{Action: EffectPoolSpend, Address: proposal.Recipient, Amount: (*hexutil.Big)(proposal.Amount)})
+			}
+		}
+	}
+	for _, candidate := range headerExtra.CurrentBlockKickOutCandidates {
+		effects.Block = append(effects.Block, ConsensusEffect{Action: EffectKickOutCandidate, Address: candidate})
+	}
+	for _, validator := range headerExtra.CurrentEpochValidators {
+		effects.Block = append(effects.Block, ConsensusEffect{Action: EffectElectValidator, Address: validator})
+	}
+}
@@ -0,0 +1,114 @@
+package equality
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceConsensusEffects(t *testing.T) {
+	config := testSnapshotConfig()
+	config.Rewards = params.EqualityRewards{{Number: math.MaxUint64, Reward: big.NewInt(10)}}
+	sealer, chain := makeSnapshotChain(t, &config)
+	parent := chain.headers[len(chain.headers)-1]
+
+	// Mint a block registering a new candidate
+	key, _ := crypto.GenerateKey()
+	candidate := crypto.PubkeyToAddress(key.PublicKey)
+	register := types.NewTransaction(0, candidate, new(big.Int), 0, new(big.Int), EncodeTransaction(new(EventBecomeCandidate)))
+	register, err := types.SignTx(register, types.NewEIP155Signer(big.NewInt(1)), key)
+	assert.Nil(t, err)
+
+	header := &types.Header{
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		ParentHash: parent.Hash(),
+		Coinbase:   testUserAddress,
+	}
+	assert.Nil(t, sealer.Prepare(chain, header))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(candidate, big.NewInt(100))
+
+	block, err := sealer.FinalizeAndAssemble(chain, header, statedb.Copy(), []*types.Transaction{register}, nil, nil)
+	assert.Nil(t, err)
+
+	effects, err := sealer.TraceConsensusEffects(chain, block, statedb)
+	assert.Nil(t, err)
+	assert.Equal(t, []ConsensusEffect{
+		{Action: EffectLock, Address: candidate, Amount: (*hexutil.Big)(big.NewInt(-1))},
+		{Action: EffectRegisterCandidate, Address: candidate},
+	}, effects.Transactions[register.Hash()])
+	assert.Equal(t, []ConsensusEffect{
+		{Action: EffectReward, Address: testUserAddress, Amount: (*hexutil.Big)(big.NewInt(1))},
+		{Action: EffectPoolReward, Address: config.Pool, Amount: (*hexutil.Big)(big.NewInt(9))},
+	}, effects.Block)
+	assert.Equal(t, []RewardTransfer{
+		{Type: RewardTypeBlock, To: testUserAddress, Value: (*hexutil.Big)(big.NewInt(1))},
+		{Type: RewardTypePool, To: config.Pool, Value: (*hexutil.Big)(big.NewInt(9))},
+	}, effects.Rewards)
+
+	// The state given is left untouched
+	assert.Equal(t, big.NewInt(100), statedb.GetBalance(candidate))
+}
+
+func TestTraceStakingEffects(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	chainConfig := *params.TestChainConfig
+	chainConfig.StakingBlock = big.NewInt(0)
+	chain.config = &chainConfig
+	parent := chain.headers[len(chain.headers)-1]
+
+	header := &types.Header{
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		ParentHash: parent.Hash(),
+		Coinbase:   testUserAddress,
+	}
+	assert.Nil(t, sealer.Prepare(chain, header))
+
+	// Register the same candidate twice through the staking contract
+	candidate := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(candidate, big.NewInt(10))
+	input := crypto.Keccak256([]byte("becomeCandidate()"))[:4]
+	var txs []*types.Transaction
+	for i := 0; i < 2; i++ {
+		tx := types.NewTransaction(uint64(i), params.StakingContractAddress, big.NewInt(1), 100000, new(big.Int), input)
+		statedb.Prepare(tx.Hash(), common.Hash{}, i)
+		evm := vm.NewEVM(vm.Context{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			BlockNumber: header.Number,
+			Time:        new(big.Int),
+			Difficulty:  new(big.Int),
+			Consensus:   sealer.ConsensusReader(header, chain.GetHeader),
+		}, statedb, &chainConfig, vm.Config{})
+		_, _, err := evm.Call(vm.AccountRef(candidate), params.StakingContractAddress, input, 100000, big.NewInt(1))
+		assert.Nil(t, err)
+		txs = append(txs, tx)
+	}
+	block, err := sealer.FinalizeAndAssemble(chain, header, statedb.Copy(), txs, nil, nil)
+	assert.Nil(t, err)
+
+	// The escrowed deposits are staked, or refunded for the second registration
+	effects, err := sealer.TraceConsensusEffects(chain, block, statedb)
+	assert.Nil(t, err)
+	assert.Equal(t, []ConsensusEffect{
+		{Action: EffectLock, Address: params.StakingContractAddress, Amount: (*hexutil.Big)(big.NewInt(-1))},
+		{Action: EffectRegisterCandidate, Address: candidate},
+	}, effects.Transactions[txs[0].Hash()])
+	assert.Equal(t, []ConsensusEffect{
+		{Action: EffectLock, Address: params.StakingContractAddress, Amount: (*hexutil.Big)(big.NewInt(-1))},
+		{Action: EffectRefund, Address: candidate, Amount: (*hexutil.Big)(big.NewInt(1))},
+	}, effects.Transactions[txs[1].Hash()])
+}
@@ -1,12 +1,23 @@
 package equality
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/consensus"
 	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/metrics"
+	"github.com/SecretBlockChain/go-secret/params"
 )
 
 // Transaction custom transaction interface.
@@ -27,6 +38,14 @@ var (
 	prototypes = []Transaction{
 		new(EventBecomeCandidate),
 		new(EventCancelCandidate),
+		new(EventBindSigner),
+		new(EventSetPayout),
+		new(EventPropose),
+		new(EventVote),
+		new(EventHalt),
+		new(EventFork),
+		new(EventSignal),
+		new(EventSpend),
 	}
 	prototypeMapper = map[TransactionType][]Transaction{}
 )
@@ -38,6 +57,57 @@ func init() {
 	}
 }
 
+var (
+	// errUnprotectedTransaction is returned if a custom transaction isn't replay
+	// protected while the chain requires it.
+	errUnprotectedTransaction = errors.New("unprotected custom transaction")
+
+	// errForeignTransaction is returned if a custom transaction is signed for
+	// another chain.
+	errForeignTransaction = errors.New("custom transaction of another chain")
+
+	// errAlreadyCandidate is returned if a candidate registration is submitted
+	// by a candidate.
+	errAlreadyCandidate = errors.New("already a candidate")
+
+	// errInsufficientCandidateFunds is returned if the sender of a candidate
+	// registration can't pay the minimum candidate balance on top of the
+	// transaction costs.
+	errInsufficientCandidateFunds = errors.New("insufficient funds for candidate deposit")
+
+	unprotectedTxMeter = metrics.NewRegisteredMeter("equality/txs/unprotected", nil)
+	foreignTxMeter     = metrics.NewRegisteredMeter("equality/txs/foreign", nil)
+)
+
+// decodeTransaction parses the custom transaction of the block with the given
+// number. From the replay protection fork on, the transactions signed for other
+// chains are rejected and, unless the chain allows them for its legacy signers,
+// the ones without replay protection. The legacy transactions accepted are
+// counted, telling when the switch can be turned off.
+func (e *Equality) decodeTransaction(config *params.ChainConfig, number *big.Int, tx *types.Transaction) (Transaction, error) {
+	ctx, err := NewTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	if !config.IsReplayProtection(number) {
+		return ctx, nil
+	}
+	if !tx.Protected() {
+		unprotectedTxMeter.Mark(1)
+		if !e.config.AllowUnprotectedTx {
+			log.Debug("[equality] Dropping unprotected custom transaction", "hash", tx.Hash())
+			return nil, errUnprotectedTransaction
+		}
+		return ctx, nil
+	}
+	if tx.ChainId().Cmp(config.ChainID) != 0 {
+		foreignTxMeter.Mark(1)
+		log.Debug("[equality] Dropping custom transaction of another chain", "hash", tx.Hash(), "chainid", tx.ChainId())
+		return nil, errForeignTransaction
+	}
+	return ctx, nil
+}
+
 // NewTransaction new custom transaction from transaction data.
 // data format: equality:version:type:action:data
 func NewTransaction(tx *types.Transaction) (Transaction, error) {
@@ -80,6 +150,82 @@ func NewTransaction(tx *types.Transaction) (Transaction, error) {
 	return nil, errors.New("undefined custom transaction action")
 }
 
+// IsConsensusTransaction returns whether the transaction registers or cancels a
+// candidate in the block, calling becomeCandidate or cancelCandidate on the
+// staking contract from the staking fork on and through the custom transactions
+// before. The miner includes them ahead of the others, up to a quota.
+func (e *Equality) IsConsensusTransaction(chain consensus.ChainHeaderReader, header *types.Header, tx *types.Transaction) bool {
+	if chain.Config().IsStaking(header.Number) {
+		if tx.To() == nil || *tx.To() != params.StakingContractAddress {
+			return false
+		}
+		return bytes.HasPrefix(tx.Data(), vm.BecomeCandidateSelector) || bytes.HasPrefix(tx.Data(), vm.CancelCandidateSelector)
+	}
+	ctx, err := NewTransaction(tx)
+	if err != nil {
+		return false
+	}
+	switch ctx.(type) {
+	case *EventBecomeCandidate, *EventCancelCandidate:
+		return true
+	}
+	return false
+}
+
+// ValidateConsensusTx rejects the candidate registrations the engine would drop in
+// the block following the head: the ones of the candidates already registered
+// and the ones whose sender can't pay the minimum candidate balance on top of the
+// transaction costs. The transactions are left to the engine if the consensus
+// state of the head is unavailable.
+func (e *Equality) ValidateConsensusTx(config *params.ChainConfig, head *types.Header, from common.Address, tx *types.Transaction,
+	balance *big.Int) error {
+
+	number := new(big.Int).Add(head.Number, common.Big1)
+	if number.Uint64() <= e.start {
+		return nil
+	}
+	// Only the registrations are checked, the gas paid being required on top of
+	// the deposit the staking contract is sent
+	var required *big.Int
+	if config.IsStaking(number) {
+		if tx.To() == nil || *tx.To() != params.StakingContractAddress || !bytes.HasPrefix(tx.Data(), vm.BecomeCandidateSelector) {
+			return nil
+		}
+		required = new(big.Int).Sub(tx.Cost(), tx.Value())
+	} else {
+		ctx, err := NewTransaction(tx)
+		if err != nil {
+			return nil
+		}
+		if _, ok := ctx.(*EventBecomeCandidate); !ok {
+			return nil
+		}
+		required = tx.Cost()
+	}
+	headerExtra, err := DecodeHeaderExtra(head)
+	if err != nil {
+		return nil
+	}
+	snap, err := e.openSnapshot(headerExtra.Root)
+	if err != nil {
+		return nil
+	}
+	equalityConfig, err := e.chainConfig(head)
+	if err != nil {
+		return nil
+	}
+	if equalityConfig.MinCandidateBalance != nil {
+		required.Add(required, equalityConfig.MinCandidateBalance)
+	}
+	if balance.Cmp(required) < 0 {
+		return errInsufficientCandidateFunds
+	}
+	if candidate, err := snap.GetCandidate(from); err == nil && candidate != nil {
+		return errAlreadyCandidate
+	}
+	return nil
+}
+
 // EventBecomeCandidate apply to become Candidate.
 // data like "equality:1:event:candidate"
 // Sender will become a Candidate
@@ -127,3 +273,300 @@ func (event *EventCancelCandidate) Decode(tx *types.Transaction, data []byte) er
 	event.Delegator = txSender
 	return nil
 }
+
+// EventBindSigner apply to seal with another key.
+// data like "equality:1:event:signer:0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c"
+// The key will seal the slots of the sender from the next epoch, keeping its deposit and seat
+type EventBindSigner struct {
+	Candidate common.Address
+	Key       common.Address
+}
+
+func (event *EventBindSigner) Type() TransactionType {
+	return EventTransactionType
+}
+
+func (event *EventBindSigner) Action() string {
+	return "signer"
+}
+
+func (event *EventBindSigner) Decode(tx *types.Transaction, data []byte) error {
+	if !common.IsHexAddress(string(data)) {
+		return errors.New("invalid signer key")
+	}
+	txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	event.Candidate = txSender
+	event.Key = common.HexToAddress(string(data))
+	return nil
+}
+
+// EventSetPayout apply to credit the rewards to another address.
+// data like "equality:1:event:payout:0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c"
+// The rewards of the blocks minted by the sender are credited to the recipient, e.g. a cold wallet
+type EventSetPayout struct {
+	Candidate common.Address
+	Recipient common.Address
+}
+
+func (event *EventSetPayout) Type() TransactionType {
+	return EventTransactionType
+}
+
+func (event *EventSetPayout) Action() string {
+	return "payout"
+}
+
+func (event *EventSetPayout) Decode(tx *types.Transaction, data []byte) error {
+	if !common.IsHexAddress(string(data)) {
+		return errors.New("invalid payout recipient")
+	}
+	txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	event.Candidate = txSender
+	event.Recipient = common.HexToAddress(string(data))
+	return nil
+}
+
+// EventPropose apply to change the chain config.
+// data like "equality:1:event:propose:{\"period\":3,\"epoch\":600,...}"
+// The proposal is identified by the hash of the transaction, the config is activated once approved by the validators
+type EventPropose struct {
+	ID       common.Hash
+	Proposer common.Address
+	Config   params.EqualityConfig
+}
+
+func (event *EventPropose) Type() TransactionType {
+	return EventTransactionType
+}
+
+func (event *EventPropose) Action() string {
+	return "propose"
+}
+
+func (event *EventPropose) Decode(tx *types.Transaction, data []byte) error {
+	if err := json.Unmarshal(data, &event.Config); err != nil {
+		return fmt.Errorf("invalid proposed config: %v", err)
+	}
+	txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	event.ID = tx.Hash()
+	event.Proposer = txSender
+	return nil
+}
+
+// EventVote apply to approve or reject a proposal.
+// data like "equality:1:event:vote:0x1d5c5f7a6b0b2b5e4f0c2c4a3c8c1b2e3d4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b:yes"
+// Only the validators of the current epoch vote, a later vote replacing the previous one
+type EventVote struct {
+	ID        common.Hash
+	Validator common.Address
+	Approve   bool
+}
+
+func (event *EventVote) Type() TransactionType {
+	return EventTransactionType
+}
+
+func (event *EventVote) Action() string {
+	return "vote"
+}
+
+func (event *EventVote) Decode(tx *types.Transaction, data []byte) error {
+	slice := strings.Split(string(data), ":")
+	if len(slice) != 2 || len(slice[0]) != 2+2*common.HashLength || !strings.HasPrefix(slice[0], "0x") {
+		return errors.New("invalid vote")
+	}
+	switch slice[1] {
+	case "yes":
+		event.Approve = true
+	case "no":
+		event.Approve = false
+	default:
+		return errors.New("invalid vote")
+	}
+	txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	event.ID = common.HexToHash(slice[0])
+	event.Validator = txSender
+	return nil
+}
+
+// EventHalt apply to halt the chain after the given height.
+// data like "equality:1:event:halt:1000000"
+// The proposal is identified by the hash of the transaction, the halt is set once approved by a supermajority of the validators
+type EventHalt struct {
+	ID       common.Hash
+	Proposer common.Address
+	Height   uint64
+}
+
+func (event *EventHalt) Type() TransactionType {
+	return EventTransactionType
+}
+
+func (event *EventHalt) Action() string {
+	return "halt"
+}
+
+func (event *EventHalt) Decode(tx *types.Transaction, data []byte) error {
+	height, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil || height == 0 {
+		return errors.New("invalid halt height")
+	}
+	txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	event.ID = tx.Hash()
+	event.Proposer = txSender
+	event.Height = height
+	return nil
+}
+
+// EventFork apply to schedule a protocol fork at the given block.
+// data like "equality:1:event:fork:london:1000000"
+// The proposal is identified by the hash of the transaction, the fork is scheduled once approved by the validators
+type EventFork struct {
+	ID       common.Hash
+	Proposer common.Address
+	Fork     string
+	Block    uint64
+}
+
+func (event *EventFork) Type() TransactionType {
+	return EventTransactionType
+}
+
+func (event *EventFork) Action() string {
+	return "fork"
+}
+
+func (event *EventFork) Decode(tx *types.Transaction, data []byte) error {
+	slice := strings.Split(string(data), ":")
+	if len(slice) != 2 || slice[0] == "" {
+		return errors.New("invalid fork")
+	}
+	block, err := strconv.ParseUint(slice[1], 10, 64)
+	if err != nil || block == 0 {
+		return errors.New("invalid fork block")
+	}
+	txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	event.ID = tx.Hash()
+	event.Proposer = txSender
+	event.Fork = slice[0]
+	event.Block = block
+	return nil
+}
+
+// EventSignal apply to poll the validators on a text, without effect on chain.
+// data like "equality:1:event:signal:0xfd0810fc2fec68a5ac3d6c4ff6fc4ce9bc2b5a7a3ea9a8cfe9ba6c6ef1a0a5a3"
+// The proposal is identified by the hash of the transaction, the text by its hash, published off chain
+type EventSignal struct {
+	ID       common.Hash
+	Proposer common.Address
+	Text     common.Hash
+}
+
+func (event *EventSignal) Type() TransactionType {
+	return EventTransactionType
+}
+
+func (event *EventSignal) Action() string {
+	return "signal"
+}
+
+func (event *EventSignal) Decode(tx *types.Transaction, data []byte) error {
+	text, err := hexutil.Decode(string(data))
+	if err != nil || len(text) != common.HashLength || common.BytesToHash(text) == (common.Hash{}) {
+		return errors.New("invalid signal text hash")
+	}
+	txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	event.ID = tx.Hash()
+	event.Proposer = txSender
+	event.Text = common.BytesToHash(text)
+	return nil
+}
+
+// EventSpend apply to transfer an amount from the pool to a recipient.
+// data like "equality:1:event:spend:0x47746e8acb5dafe9c00b7195d0c2d830fcc04910:1000000000000000000"
+// The proposal is identified by the hash of the transaction, the amount in wei is transferred once approved by the validators
+type EventSpend struct {
+	ID        common.Hash
+	Proposer  common.Address
+	Recipient common.Address
+	Amount    *big.Int
+}
+
+func (event *EventSpend) Type() TransactionType {
+	return EventTransactionType
+}
+
+func (event *EventSpend) Action() string {
+	return "spend"
+}
+
+func (event *EventSpend) Decode(tx *types.Transaction, data []byte) error {
+	slice := strings.Split(string(data), ":")
+	if len(slice) != 2 || !common.IsHexAddress(slice[0]) {
+		return errors.New("invalid spending recipient")
+	}
+	amount, ok := new(big.Int).SetString(slice[1], 10)
+	if !ok || amount.Sign() <= 0 {
+		return errors.New("invalid spending amount")
+	}
+	txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	event.ID = tx.Hash()
+	event.Proposer = txSender
+	event.Recipient = common.HexToAddress(slice[0])
+	event.Amount = amount
+	return nil
+}
+
+// EncodeTransaction returns the transaction data carrying a custom transaction,
+// the inverse of NewTransaction.
+func EncodeTransaction(ctx Transaction) []byte {
+	fields := []string{"equality", "1", string(ctx.Type()), ctx.Action()}
+	switch event := ctx.(type) {
+	case *EventBindSigner:
+		fields = append(fields, event.Key.Hex())
+	case *EventSetPayout:
+		fields = append(fields, event.Recipient.Hex())
+	case *EventPropose:
+		config, _ := json.Marshal(event.Config)
+		fields = append(fields, string(config))
+	case *EventVote:
+		vote := "no"
+		if event.Approve {
+			vote = "yes"
+		}
+		fields = append(fields, event.ID.Hex(), vote)
+	case *EventHalt:
+		fields = append(fields, strconv.FormatUint(event.Height, 10))
+	case *EventFork:
+		fields = append(fields, event.Fork, strconv.FormatUint(event.Block, 10))
+	case *EventSignal:
+		fields = append(fields, event.Text.Hex())
+	case *EventSpend:
+		fields = append(fields, event.Recipient.Hex(), event.Amount.String())
+	}
+	return []byte(strings.Join(fields, ":"))
+}
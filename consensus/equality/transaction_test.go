@@ -1,14 +1,19 @@
 package equality
 
 import (
+	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
 	"math/big"
 	"testing"
 
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
 	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
 	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,3 +40,120 @@ func TestCustomTransactionDecode(t *testing.T) {
 	assert.Nil(t, err)
 	assert.IsType(t, new(EventCancelCandidate), ctx)
 }
+
+func TestReplayProtection(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+
+	register := func(signer types.Signer) (common.Address, *types.Transaction) {
+		key, _ := crypto.GenerateKey()
+		tx := types.NewTransaction(0, testUserAddress, new(big.Int), 0, new(big.Int), EncodeTransaction(new(EventBecomeCandidate)))
+		tx, err := types.SignTx(tx, signer, key)
+		assert.Nil(t, err)
+		return crypto.PubkeyToAddress(key.PublicKey), tx
+	}
+	mint := func(candidates []common.Address, txs []*types.Transaction) []common.Address {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		for _, candidate := range candidates {
+			statedb.AddBalance(candidate, big.NewInt(100))
+		}
+		headerExtra, err := DecodeHeaderExtra(mintTestBlock(t, sealer, chain, statedb, txs))
+		assert.Nil(t, err)
+		return headerExtra.CurrentBlockCandidates
+	}
+
+	// All the transactions are applied before the fork
+	local, protected := register(types.NewEIP155Signer(big.NewInt(1)))
+	legacy, unprotected := register(types.HomesteadSigner{})
+	foreign, replayed := register(types.NewEIP155Signer(big.NewInt(2)))
+	candidates := []common.Address{local, legacy, foreign}
+	assert.Equal(t, candidates, mint(candidates, []*types.Transaction{protected, unprotected, replayed}))
+
+	// Only the transactions protected for the local chain are after it
+	chainConfig := *params.TestChainConfig
+	chainConfig.ReplayProtectionBlock = new(big.Int).Add(chain.headers[len(chain.headers)-1].Number, common.Big1)
+	chain.config = &chainConfig
+	local, protected = register(types.NewEIP155Signer(big.NewInt(1)))
+	legacy, unprotected = register(types.HomesteadSigner{})
+	foreign, replayed = register(types.NewEIP155Signer(big.NewInt(2)))
+	candidates = []common.Address{local, legacy, foreign}
+	assert.Equal(t, []common.Address{local}, mint(candidates, []*types.Transaction{protected, unprotected, replayed}))
+
+	// Chains of legacy signers accept the unprotected ones
+	config.AllowUnprotectedTx = true
+	legacy, unprotected = register(types.HomesteadSigner{})
+	foreign, replayed = register(types.NewEIP155Signer(big.NewInt(2)))
+	assert.Equal(t, []common.Address{legacy}, mint([]common.Address{legacy, foreign}, []*types.Transaction{unprotected, replayed}))
+}
+
+func TestIsConsensusTransaction(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	header := &types.Header{Number: big.NewInt(6)}
+
+	sign := func(to common.Address, data []byte) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(0, to, new(big.Int), 0, new(big.Int), data), types.NewEIP155Signer(big.NewInt(1)), testKey)
+		assert.Nil(t, err)
+		return tx
+	}
+	var (
+		register = sign(testUserAddress, EncodeTransaction(new(EventBecomeCandidate)))
+		cancel   = sign(testUserAddress, EncodeTransaction(new(EventCancelCandidate)))
+		vote     = sign(testUserAddress, EncodeTransaction(&EventVote{ID: common.HexToHash("0x01"), Approve: true}))
+		become   = sign(params.StakingContractAddress, vm.BecomeCandidateSelector)
+		leave    = sign(params.StakingContractAddress, vm.CancelCandidateSelector)
+		staking  = sign(params.StakingContractAddress, nil)
+		transfer = sign(testUserAddress, nil)
+	)
+
+	// The custom transactions register and cancel the candidates before the fork
+	assert.True(t, sealer.IsConsensusTransaction(chain, header, register))
+	assert.True(t, sealer.IsConsensusTransaction(chain, header, cancel))
+	assert.False(t, sealer.IsConsensusTransaction(chain, header, vote))
+	assert.False(t, sealer.IsConsensusTransaction(chain, header, become))
+	assert.False(t, sealer.IsConsensusTransaction(chain, header, transfer))
+
+	// The calls registering and canceling on the staking contract do after it
+	chainConfig := *params.TestChainConfig
+	chainConfig.StakingBlock = big.NewInt(6)
+	chain.config = &chainConfig
+	assert.False(t, sealer.IsConsensusTransaction(chain, header, register))
+	assert.True(t, sealer.IsConsensusTransaction(chain, header, become))
+	assert.True(t, sealer.IsConsensusTransaction(chain, header, leave))
+	assert.False(t, sealer.IsConsensusTransaction(chain, header, staking))
+	assert.False(t, sealer.IsConsensusTransaction(chain, header, sign(testUserAddress, vm.BecomeCandidateSelector)))
+	assert.False(t, sealer.IsConsensusTransaction(chain, header, transfer))
+}
+
+func TestValidateConsensusTx(t *testing.T) {
+	config := testSnapshotConfig()
+	sealer, chain := makeSnapshotChain(t, &config)
+	head := chain.headers[len(chain.headers)-1]
+
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(key *ecdsa.PrivateKey, to common.Address, value int64, data []byte) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(0, to, big.NewInt(value), 30000, big.NewInt(1), data), types.NewEIP155Signer(big.NewInt(1)), key)
+		assert.Nil(t, err)
+		return tx
+	}
+	validate := func(config *params.ChainConfig, from common.Address, tx *types.Transaction, balance int64) error {
+		return sealer.ValidateConsensusTx(config, head, from, tx, big.NewInt(balance))
+	}
+
+	// The custom registrations must afford the deposit on top of the gas
+	register := EncodeTransaction(new(EventBecomeCandidate))
+	assert.Equal(t, errInsufficientCandidateFunds, validate(params.TestChainConfig, sender, sign(key, sender, 0, register), 30000))
+	assert.Nil(t, validate(params.TestChainConfig, sender, sign(key, sender, 0, register), 30001))
+	assert.Equal(t, errAlreadyCandidate, validate(params.TestChainConfig, testUserAddress, sign(testUserKey, testUserAddress, 0, register), 30001))
+	assert.Nil(t, validate(params.TestChainConfig, sender, sign(key, sender, 0, EncodeTransaction(new(EventCancelCandidate))), 0))
+
+	// The staking contract is sent the deposit after the fork
+	chainConfig := *params.TestChainConfig
+	chainConfig.StakingBlock = big.NewInt(6)
+	assert.Equal(t, errInsufficientCandidateFunds, validate(&chainConfig, sender, sign(key, params.StakingContractAddress, 0, vm.BecomeCandidateSelector), 30000))
+	assert.Nil(t, validate(&chainConfig, sender, sign(key, params.StakingContractAddress, 1, vm.BecomeCandidateSelector), 30001))
+	assert.Equal(t, errAlreadyCandidate, validate(&chainConfig, testUserAddress, sign(testUserKey, params.StakingContractAddress, 1, vm.BecomeCandidateSelector), 30001))
+	assert.Nil(t, validate(&chainConfig, sender, sign(key, params.StakingContractAddress, 0, vm.CancelCandidateSelector), 30000))
+	assert.Nil(t, validate(&chainConfig, sender, sign(key, sender, 0, register), 0))
+}
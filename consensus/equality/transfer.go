@@ -0,0 +1,159 @@
+package equality
+
+import (
+	"errors"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/rlp"
+	"github.com/SecretBlockChain/go-secret/trie"
+)
+
+var (
+	// errNotEpochBlock is returned if a snapshot is requested for a block which
+	// doesn't open an epoch, only those are exchanged between the peers.
+	errNotEpochBlock = errors.New("block doesn't open an epoch")
+
+	// errSnapshotUnavailable is returned if the snapshot of a block to export
+	// isn't in the database.
+	errSnapshotUnavailable = errors.New("snapshot unavailable")
+
+	// errSnapshotMismatch is returned if the snapshot to import doesn't match
+	// the roots committed in the header.
+	errSnapshotMismatch = errors.New("snapshot doesn't match the header roots")
+)
+
+// SnapshotFetcher retrieves the snapshot of an epoch block from the network and
+// imports it, see ImportSnapshot.
+type SnapshotFetcher func(header *types.Header) error
+
+// snapshotLeaf is a key-value pair of a snapshot trie, the prefix included.
+type snapshotLeaf struct {
+	Key   []byte
+	Value []byte
+}
+
+// snapshotDump is the serialized form of a snapshot, the leaves of its tries.
+type snapshotDump struct {
+	Epoch     []snapshotLeaf
+	Candidate []snapshotLeaf
+	MintCnt   []snapshotLeaf
+	Config    []snapshotLeaf
+}
+
+// SetSnapshotFetcher sets the function retrieving the snapshots of the epoch
+// blocks from the peers, sparing the replay of the epochs when regenerating the
+// snapshots missing from the database.
+func (e *Equality) SetSnapshotFetcher(fetch SnapshotFetcher) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.fetchSnapshot = fetch
+}
+
+// snapshotFetcher returns the function retrieving the snapshots from the peers.
+func (e *Equality) snapshotFetcher() SnapshotFetcher {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	return e.fetchSnapshot
+}
+
+// isEpochBlock returns if the header opens an epoch of the engine.
+func (e *Equality) isEpochBlock(header *types.Header) (HeaderExtra, bool) {
+	if header.Number.Uint64() < e.start || header.Number.Uint64() == 0 {
+		return HeaderExtra{}, false
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return HeaderExtra{}, false
+	}
+	return headerExtra, headerExtra.EpochBlock == header.Number.Uint64()
+}
+
+// ExportSnapshot serializes the snapshot committed in the header of an epoch
+// block, to be sent to the peers missing it.
+func (e *Equality) ExportSnapshot(header *types.Header) ([]byte, error) {
+	headerExtra, ok := e.isEpochBlock(header)
+	if !ok {
+		return nil, errNotEpochBlock
+	}
+	if !e.snapshotAvailable(headerExtra.Root) {
+		return nil, errSnapshotUnavailable
+	}
+	snap, err := e.openSnapshot(headerExtra.Root)
+	if err != nil {
+		return nil, err
+	}
+	var dump snapshotDump
+	for prefix, leaves := range map[string]*[]snapshotLeaf{
+		string(epochPrefix):     &dump.Epoch,
+		string(candidatePrefix): &dump.Candidate,
+		string(mintCntPrefix):   &dump.MintCnt,
+		string(configPrefix):    &dump.Config,
+	} {
+		t, err := snap.ensureTrie([]byte(prefix))
+		if err != nil {
+			return nil, err
+		}
+		it := trie.NewIterator(t.trie.NodeIterator(nil))
+		for it.Next() {
+			*leaves = append(*leaves, snapshotLeaf{Key: common.CopyBytes(it.Key), Value: common.CopyBytes(it.Value)})
+		}
+		if it.Err != nil {
+			return nil, it.Err
+		}
+	}
+	return rlp.EncodeToBytes(&dump)
+}
+
+// ImportSnapshot rebuilds the snapshot of an epoch block from its serialized
+// form and commits it to the database, provided the roots of the rebuilt tries
+// match the ones committed in the header.
+func (e *Equality) ImportSnapshot(header *types.Header, data []byte) error {
+	headerExtra, ok := e.isEpochBlock(header)
+	if !ok {
+		return errNotEpochBlock
+	}
+	var dump snapshotDump
+	if err := rlp.DecodeBytes(data, &dump); err != nil {
+		return err
+	}
+	// Rebuild in a scratch database, only written to disk if the roots match
+	snap, err := newSnapshot(e.db)
+	if err != nil {
+		return err
+	}
+	for _, tr := range []struct {
+		prefix []byte
+		root   common.Hash
+		leaves []snapshotLeaf
+	}{
+		{epochPrefix, headerExtra.Root.EpochHash, dump.Epoch},
+		{candidatePrefix, headerExtra.Root.CandidateHash, dump.Candidate},
+		{mintCntPrefix, headerExtra.Root.MintCntHash, dump.MintCnt},
+		{configPrefix, headerExtra.Root.ConfigHash, dump.Config},
+	} {
+		// Tries never created in the snapshot are committed as the zero hash
+		if tr.root == (common.Hash{}) && len(tr.leaves) == 0 {
+			continue
+		}
+		t, err := snap.ensureTrie(tr.prefix)
+		if err != nil {
+			return err
+		}
+		for _, leaf := range tr.leaves {
+			if err := t.trie.TryUpdate(leaf.Key, leaf.Value); err != nil {
+				return err
+			}
+		}
+	}
+	root, err := snap.Root()
+	if err != nil {
+		return err
+	}
+	if root != headerExtra.Root {
+		return errSnapshotMismatch
+	}
+	return snap.Commit(root)
+}
@@ -0,0 +1,340 @@
+package equality
+
+import (
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/clique"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/event"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rpc"
+)
+
+// Transition is a consensus engine for chains switching to proof-of-equality at
+// a scheduled block. The blocks before the switch are handled by the legacy
+// engine (clique or ethash), the blocks from the switch on by equality.
+type Transition struct {
+	legacy   consensus.Engine // Engine minting the blocks before the switch
+	equality *Equality        // Engine minting the blocks from the switch on
+}
+
+// NewTransition creates a consensus engine dispatching to the legacy engine the
+// blocks before the first block of the equality engine.
+func NewTransition(legacy consensus.Engine, equality *Equality) *Transition {
+	return &Transition{legacy: legacy, equality: equality}
+}
+
+// Legacy returns the engine minting the blocks before the switch.
+func (t *Transition) Legacy() consensus.Engine {
+	return t.legacy
+}
+
+// Equality returns the engine minting the blocks from the switch on.
+func (t *Transition) Equality() *Equality {
+	return t.equality
+}
+
+// engine returns the consensus engine responsible of the given block number.
+func (t *Transition) engine(number *big.Int) consensus.Engine {
+	if number == nil || number.Uint64() < t.equality.start {
+		return t.legacy
+	}
+	return t.equality
+}
+
+// Authorize injects a private key into the consensus engines to mint new blocks
+// with. Only clique needs signing among the legacy engines.
+func (t *Transition) Authorize(signer common.Address, signFn SignerFn) {
+	if engine, ok := t.legacy.(*clique.Clique); ok {
+		engine.Authorize(signer, clique.SignerFn(signFn))
+	}
+	t.equality.Authorize(signer, signFn)
+}
+
+// ConsensusReader returns the reader of the equality engine once it seals the
+// header, nil before.
+func (t *Transition) ConsensusReader(header *types.Header, getHeader func(common.Hash, uint64) *types.Header) vm.ConsensusReader {
+	return t.equality.ConsensusReader(header, getHeader)
+}
+
+// SetLightMode switches the equality engine to verifying headers only.
+func (t *Transition) SetLightMode() {
+	t.equality.SetLightMode()
+}
+
+// SetHeaderOnly switches the equality engine to the relaxed verification of the
+// full nodes not minting blocks.
+func (t *Transition) SetHeaderOnly() {
+	t.equality.SetHeaderOnly()
+}
+
+// EnsureSnapshot regenerates the missing equality snapshots up to the header.
+func (t *Transition) EnsureSnapshot(chain consensus.ChainHeaderReader, header *types.Header) error {
+	return t.equality.EnsureSnapshot(chain, header)
+}
+
+// TrackSlots counts the slots missed by the equality validators before the header.
+func (t *Transition) TrackSlots(chain consensus.ChainHeaderReader, header *types.Header) []common.Address {
+	return t.equality.TrackSlots(chain, header)
+}
+
+// RepairSnapshots regenerates the damaged equality snapshots up to the header.
+func (t *Transition) RepairSnapshots(chain consensus.ChainHeaderReader, header *types.Header, depth uint64) (int, error) {
+	return t.equality.RepairSnapshots(chain, header, depth)
+}
+
+// SnapshotTries returns the roots of the equality snapshot tries of the header.
+func (t *Transition) SnapshotTries(header *types.Header) ([]common.Hash, error) {
+	return t.equality.SnapshotTries(header)
+}
+
+// SetSnapshotPruning switches the equality engine between the archive and the
+// pruned retention of the snapshots.
+func (t *Transition) SetSnapshotPruning(enabled bool) {
+	t.equality.SetSnapshotPruning(enabled)
+}
+
+// AddSealPolicy appends a policy consulted before sealing each equality block.
+func (t *Transition) AddSealPolicy(policy SealPolicy) {
+	t.equality.AddSealPolicy(policy)
+}
+
+// SetSignGuard sets the guard consulted before sealing each equality block.
+func (t *Transition) SetSignGuard(guard *SignGuard) {
+	t.equality.SetSignGuard(guard)
+}
+
+// SetSnapshotFetcher sets the function retrieving the equality snapshots of the
+// epoch blocks from the peers.
+func (t *Transition) SetSnapshotFetcher(fetch SnapshotFetcher) {
+	t.equality.SetSnapshotFetcher(fetch)
+}
+
+// ExportSnapshot serializes the equality snapshot of an epoch block.
+func (t *Transition) ExportSnapshot(header *types.Header) ([]byte, error) {
+	return t.equality.ExportSnapshot(header)
+}
+
+// ImportSnapshot rebuilds the equality snapshot of an epoch block.
+func (t *Transition) ImportSnapshot(header *types.Header, data []byte) error {
+	return t.equality.ImportSnapshot(header, data)
+}
+
+// EpochBlocks returns the numbers of the blocks opening the equality epochs
+// following the one opened at the given block.
+func (t *Transition) EpochBlocks(epochBlock, limit uint64, max int) []uint64 {
+	return t.equality.EpochBlocks(epochBlock, limit, max)
+}
+
+// VerifyEpochHeaders verifies a batch of consecutive equality epoch headers.
+func (t *Transition) VerifyEpochHeaders(epoch *types.Header, headers []*types.Header) error {
+	return t.equality.VerifyEpochHeaders(epoch, headers)
+}
+
+// NodeStatus returns the consensus status of the local node at the given block.
+func (t *Transition) NodeStatus(header *types.Header) (NodeStatus, error) {
+	return t.equality.NodeStatus(header)
+}
+
+// NextSlot returns the unix time of the next slot of the local signer.
+func (t *Transition) NextSlot(header *types.Header, now uint64) (uint64, error) {
+	return t.equality.NextSlot(header, now)
+}
+
+// SnapshotAvailable returns if the snapshot tries of the given block are in the
+// database, the legacy blocks have none.
+func (t *Transition) SnapshotAvailable(header *types.Header) bool {
+	return t.equality.SnapshotAvailable(header)
+}
+
+// SubscribeEpochStarted registers a subscription of EpochStartedEvent.
+func (t *Transition) SubscribeEpochStarted(ch chan<- EpochStartedEvent) event.Subscription {
+	return t.equality.SubscribeEpochStarted(ch)
+}
+
+// SubscribeValidatorElected registers a subscription of ValidatorElectedEvent.
+func (t *Transition) SubscribeValidatorElected(ch chan<- ValidatorElectedEvent) event.Subscription {
+	return t.equality.SubscribeValidatorElected(ch)
+}
+
+// SubscribeCandidateKicked registers a subscription of CandidateKickedEvent.
+func (t *Transition) SubscribeCandidateKicked(ch chan<- CandidateKickedEvent) event.Subscription {
+	return t.equality.SubscribeCandidateKicked(ch)
+}
+
+// SubscribeBlockSealed registers a subscription of BlockSealedEvent.
+func (t *Transition) SubscribeBlockSealed(ch chan<- BlockSealedEvent) event.Subscription {
+	return t.equality.SubscribeBlockSealed(ch)
+}
+
+// PublishChainEvent posts the consensus events of a canonical block, the legacy
+// blocks have none.
+func (t *Transition) PublishChainEvent(header *types.Header) {
+	t.equality.PublishChainEvent(header)
+}
+
+// ScheduleForks applies the forks approved up to a canonical block, the legacy
+// blocks approve none.
+func (t *Transition) ScheduleForks(chain consensus.ChainHeaderReader, header *types.Header) {
+	if header.Number.Uint64() < t.equality.start {
+		return
+	}
+	t.equality.ScheduleForks(chain, header)
+}
+
+// TraceConsensusEffects replays the finalization of a block to report its side
+// effects, the legacy blocks have none.
+func (t *Transition) TraceConsensusEffects(chain consensus.ChainHeaderReader, block *types.Block, statedb *state.StateDB) (*ConsensusEffects, error) {
+	if block.NumberU64() < t.equality.start {
+		return &ConsensusEffects{Transactions: make(map[common.Hash][]ConsensusEffect), Block: make([]ConsensusEffect, 0), Rewards: make([]RewardTransfer, 0)}, nil
+	}
+	return t.equality.TraceConsensusEffects(chain, block, statedb)
+}
+
+// InTurn returns if the local signer may mint the block following the given one.
+// The legacy engines decide on their own while sealing.
+func (t *Transition) InTurn(lastBlockHeader *types.Header, now uint64) bool {
+	if lastBlockHeader.Number.Uint64()+1 < t.equality.start {
+		return true
+	}
+	return t.equality.InTurn(lastBlockHeader, now)
+}
+
+// IsConsensusTransaction returns whether the transaction registers or cancels a
+// candidate, the legacy engines having no such transactions.
+func (t *Transition) IsConsensusTransaction(chain consensus.ChainHeaderReader, header *types.Header, tx *types.Transaction) bool {
+	if header.Number.Uint64() < t.equality.start {
+		return false
+	}
+	return t.equality.IsConsensusTransaction(chain, header, tx)
+}
+
+// ValidateConsensusTx rejects the candidate registrations the engine would drop,
+// the legacy engines having none.
+func (t *Transition) ValidateConsensusTx(config *params.ChainConfig, head *types.Header, from common.Address, tx *types.Transaction,
+	balance *big.Int) error {
+
+	if head.Number.Uint64()+1 < t.equality.start {
+		return nil
+	}
+	return t.equality.ValidateConsensusTx(config, head, from, tx, balance)
+}
+
+// Author implements consensus.Engine, returning the minter of the block.
+func (t *Transition) Author(header *types.Header) (common.Address, error) {
+	return t.engine(header.Number).Author(header)
+}
+
+// VerifyHeader implements consensus.Engine, dispatching on the block number.
+func (t *Transition) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return t.engine(header.Number).VerifyHeader(chain, header, seal)
+}
+
+// VerifyHeaders implements consensus.Engine, verifying the headers before the
+// switch with the legacy engine and the remaining ones with equality. The
+// results are delivered in the order of the input slice.
+func (t *Transition) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	split := len(headers)
+	for i, header := range headers {
+		if header.Number.Uint64() >= t.equality.start {
+			split = i
+			break
+		}
+	}
+	if split == 0 {
+		return t.equality.VerifyHeaders(chain, headers, seals)
+	}
+	if split == len(headers) {
+		return t.legacy.VerifyHeaders(chain, headers, seals)
+	}
+
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+	legacyAbort, legacyResults := t.legacy.VerifyHeaders(chain, headers[:split], seals[:split])
+
+	go func() {
+		defer close(legacyAbort)
+		for i := 0; i < len(headers); i++ {
+			var err error
+			if i < split {
+				select {
+				case <-abort:
+					return
+				case err = <-legacyResults:
+				}
+			} else {
+				// The parents of the first equality blocks are not yet in the chain
+				err = t.equality.verifyHeader(chain, headers[i], headers[:i])
+			}
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// VerifyUncles implements consensus.Engine, dispatching on the block number.
+func (t *Transition) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	return t.engine(block.Number()).VerifyUncles(chain, block)
+}
+
+// VerifySeal implements consensus.Engine, dispatching on the block number.
+func (t *Transition) VerifySeal(chain consensus.ChainHeaderReader, header *types.Header) error {
+	return t.engine(header.Number).VerifySeal(chain, header)
+}
+
+// Prepare implements consensus.Engine, dispatching on the block number.
+func (t *Transition) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	return t.engine(header.Number).Prepare(chain, header)
+}
+
+// Finalize implements consensus.Engine, dispatching on the block number.
+func (t *Transition) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction,
+	uncles []*types.Header) {
+
+	t.engine(header.Number).Finalize(chain, header, state, txs, uncles)
+}
+
+// FinalizeAndAssemble implements consensus.Engine, dispatching on the block number.
+func (t *Transition) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction,
+	uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+
+	return t.engine(header.Number).FinalizeAndAssemble(chain, header, state, txs, uncles, receipts)
+}
+
+// Seal implements consensus.Engine, dispatching on the block number.
+func (t *Transition) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	return t.engine(block.Number()).Seal(chain, block, results, stop)
+}
+
+// SealHash implements consensus.Engine, dispatching on the block number.
+func (t *Transition) SealHash(header *types.Header) common.Hash {
+	return t.engine(header.Number).SealHash(header)
+}
+
+// CalcDifficulty implements consensus.Engine, dispatching on the number of the
+// block following the parent.
+func (t *Transition) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	number := new(big.Int).Add(parent.Number, common.Big1)
+	return t.engine(number).CalcDifficulty(chain, time, parent)
+}
+
+// APIs implements consensus.Engine, returning the APIs of both engines.
+func (t *Transition) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return append(t.legacy.APIs(chain), t.equality.APIs(chain)...)
+}
+
+// Close implements consensus.Engine, terminating both engines.
+func (t *Transition) Close() error {
+	if err := t.legacy.Close(); err != nil {
+		return err
+	}
+	return t.equality.Close()
+}
@@ -0,0 +1,44 @@
+package equality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/ethash"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition(t *testing.T) {
+	config := params.EqualityConfig{
+		Period:              3,
+		Epoch:               100,
+		MaxValidatorsCount:  21,
+		MinCandidateBalance: big.NewInt(1000),
+	}
+	legacy := ethash.NewFullFaker()
+	transition := NewTransition(legacy, NewFromBlock(&config, rawdb.NewMemoryDatabase(), 3))
+
+	assert.Equal(t, consensus.Engine(legacy), transition.engine(big.NewInt(2)))
+	assert.Equal(t, consensus.Engine(transition.Equality()), transition.engine(big.NewInt(3)))
+	assert.Equal(t, big.NewInt(defaultDifficulty), transition.CalcDifficulty(nil, 0, &types.Header{Number: big.NewInt(2)}))
+
+	// Blocks before the switch are left to the legacy engine
+	assert.True(t, transition.InTurn(&types.Header{Number: big.NewInt(1)}, 0))
+	headerConfig, err := transition.Equality().chainConfig(&types.Header{Number: big.NewInt(2)})
+	assert.Nil(t, err)
+	assert.Equal(t, config, headerConfig)
+
+	// Batches spanning the switch are verified by both engines, in order
+	headers := []*types.Header{{Number: big.NewInt(0)}}
+	for number := int64(1); number < 5; number++ {
+		headers = append(headers, &types.Header{Number: big.NewInt(number), ParentHash: headers[number-1].Hash()})
+	}
+	_, results := transition.VerifyHeaders(&testChainReader{headers: headers[:1]}, headers[1:], make([]bool, 4))
+	for _, expected := range []error{nil, nil, errMissingVanity, errMissingVanity} {
+		assert.Equal(t, expected, <-results)
+	}
+}
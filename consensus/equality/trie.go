@@ -3,12 +3,21 @@ package equality
 import (
 	"bytes"
 	"fmt"
+	"time"
 
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/ethdb"
 	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/metrics"
 	"github.com/SecretBlockChain/go-secret/trie"
 )
 
+var (
+	trieReadTimer       = metrics.NewRegisteredTimer("equality/trie/read", nil)
+	trieReadBytesMeter  = metrics.NewRegisteredMeter("equality/trie/read/bytes", nil)
+	trieWriteBytesMeter = metrics.NewRegisteredMeter("equality/trie/write/bytes", nil)
+)
+
 // PrefixTrie is a Merkle Patricia Trie.
 type Trie struct {
 	prefix []byte
@@ -62,7 +71,14 @@ func (t *Trie) TryGet(key []byte) ([]byte, error) {
 	if t.prefix != nil {
 		key = append(t.prefix, key...)
 	}
-	return t.trie.TryGet(key)
+	if !metrics.EnabledExpensive {
+		return t.trie.TryGet(key)
+	}
+	start := time.Now()
+	value, err := t.trie.TryGet(key)
+	trieReadTimer.UpdateSince(start)
+	trieReadBytesMeter.Mark(int64(len(value)))
+	return value, err
 }
 
 // Update associates key with value in the trie. Subsequent calls to
@@ -89,6 +105,9 @@ func (t *Trie) TryUpdate(key, value []byte) error {
 	if t.prefix != nil {
 		key = append(t.prefix, key...)
 	}
+	if metrics.EnabledExpensive {
+		trieWriteBytesMeter.Mark(int64(len(value)))
+	}
 	return t.trie.TryUpdate(key, value)
 }
 
@@ -108,6 +127,20 @@ func (t *Trie) TryDelete(key []byte) error {
 	return t.trie.TryDelete(key)
 }
 
+// Prove constructs a merkle proof for key. The result contains all encoded nodes
+// on the path to the value at key. The value itself is also included in the last
+// node and can be retrieved by verifying the proof.
+//
+// If the trie does not contain a value for key, the returned proof contains all
+// nodes of the longest existing prefix of the key (at least the root node), ending
+// with the node that proves the absence of the key.
+func (t *Trie) Prove(key []byte, fromLevel uint, proofDb ethdb.KeyValueWriter) error {
+	if t.prefix != nil {
+		key = append(t.prefix, key...)
+	}
+	return t.trie.Prove(key, fromLevel, proofDb)
+}
+
 // Commit writes all nodes to the trie's database.
 // Nodes are stored with their sha3 hash as the key.
 ////
@@ -0,0 +1,61 @@
+package equality
+
+import (
+	"bytes"
+	"errors"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/SecretBlockChain/go-secret/core/types"
+)
+
+// maxVanityRange is the maximum number of blocks scanned by a single vanity
+// tag query.
+const maxVanityRange = 10000
+
+var (
+	// errVanityTooLong is returned if a validator tag doesn't fit in the vanity.
+	errVanityTooLong = errors.New("vanity tag exceeds 32 bytes")
+
+	// errVanityNotPrintable is returned if a validator tag isn't printable UTF-8.
+	errVanityNotPrintable = errors.New("vanity tag is not printable UTF-8")
+)
+
+// ValidateVanity checks that a validator tag is printable UTF-8 text fitting in
+// the vanity prefix of the block extra-data.
+func ValidateVanity(tag string) error {
+	if len(tag) > extraVanity {
+		return errVanityTooLong
+	}
+	if !printable([]byte(tag)) {
+		return errVanityNotPrintable
+	}
+	return nil
+}
+
+// Vanity decodes the validator tag placed in the vanity prefix of the header
+// extra-data. It returns false if the vanity doesn't hold printable UTF-8 text,
+// which is the case for the default RLP encoded client version.
+func Vanity(header *types.Header) (string, bool) {
+	if len(header.Extra) < extraVanity {
+		return "", false
+	}
+	vanity := bytes.TrimRight(header.Extra[:extraVanity], "\x00")
+	if len(vanity) == 0 || !printable(vanity) {
+		return "", false
+	}
+	return string(vanity), true
+}
+
+// printable reports whether the bytes are valid UTF-8 made of printable runes.
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package equality
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVanity(t *testing.T) {
+	assert.Nil(t, ValidateVanity("TeamRocket v1.10"))
+	assert.Equal(t, errVanityTooLong, ValidateVanity(strings.Repeat("x", extraVanity+1)))
+	assert.Equal(t, errVanityNotPrintable, ValidateVanity("tag\x01"))
+
+	extra := append([]byte("TeamRocket v1.10"), bytes.Repeat([]byte{0x00}, extraVanity-16+extraSeal)...)
+	tag, ok := Vanity(&types.Header{Extra: extra})
+	assert.True(t, ok)
+	assert.Equal(t, "TeamRocket v1.10", tag)
+
+	// Default extra-data is the RLP encoded client version, not a tag
+	extra = append([]byte{0xda, 0x83, 0x01, 0x09, 0x1a}, bytes.Repeat([]byte{0x00}, extraVanity-5+extraSeal)...)
+	_, ok = Vanity(&types.Header{Extra: extra})
+	assert.False(t, ok)
+
+	_, ok = Vanity(&types.Header{Extra: make([]byte, extraVanity+extraSeal)})
+	assert.False(t, ok)
+}
@@ -0,0 +1,122 @@
+package equality
+
+import (
+	"errors"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/types"
+)
+
+// errNotNextEpoch is returned if a header handed to warp sync doesn't open the
+// epoch following the one of the previous epoch header.
+var errNotNextEpoch = errors.New("header doesn't open the next epoch")
+
+// nextEpochBlock returns the number of the block opening the epoch following
+// the one opened at the given block. Epochs end when the distance to the epoch
+// block reaches the epoch length of the config governing the next block, so
+// the candidates are the lengths of the configs in force after the block.
+func (e *Equality) nextEpochBlock(epochBlock uint64) uint64 {
+	var next uint64
+	consider := func(length uint64) {
+		candidate := epochBlock + length
+		if candidate > epochBlock && (next == 0 || candidate < next) && e.lightConfig(candidate).Epoch == length {
+			next = candidate
+		}
+	}
+	consider(e.lightConfig(epochBlock + 1).Epoch)
+	for _, override := range e.config.Overrides {
+		if override.Block > epochBlock {
+			consider(e.lightConfig(override.Block + 1).Epoch)
+		}
+	}
+	if next == 0 {
+		next = epochBlock + e.lightConfig(epochBlock+1).Epoch
+	}
+	return next
+}
+
+// EpochBlocks returns the numbers of the blocks opening the epochs following the
+// one opened at the given block, up to the limit and at most max of them. They
+// follow from the genesis config and its overrides, which lets warp sync request
+// the epoch headers only.
+func (e *Equality) EpochBlocks(epochBlock, limit uint64, max int) []uint64 {
+	next := e.start
+	if epochBlock >= e.start {
+		next = e.nextEpochBlock(epochBlock)
+	}
+	var numbers []uint64
+	for next <= limit && len(numbers) < max {
+		numbers = append(numbers, next)
+		next = e.nextEpochBlock(next)
+	}
+	return numbers
+}
+
+// VerifyEpochHeaders verifies a batch of consecutive epoch headers following the
+// given epoch header, without the blocks in between. Validators only change at
+// epoch blocks, so each header is checked to open the next epoch at the expected
+// block and to be sealed in turn by the validators elected in the previous one.
+func (e *Equality) VerifyEpochHeaders(epoch *types.Header, headers []*types.Header) error {
+	for _, header := range headers {
+		if err := e.verifyEpochHeader(epoch, header); err != nil {
+			return err
+		}
+		epoch = header
+	}
+	return nil
+}
+
+// verifyEpochHeader checks that the header opens the epoch following the one of
+// the given epoch header.
+func (e *Equality) verifyEpochHeader(epoch, header *types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	if err := verifyStandaloneFields(header); err != nil {
+		return err
+	}
+	var (
+		validators []common.Address
+		expected   uint64
+		index      uint64
+	)
+	if epoch.Number.Uint64() < e.start {
+		validators, expected = e.config.Validators, e.start
+	} else {
+		epochExtra, err := DecodeHeaderExtra(epoch)
+		if err != nil {
+			return err
+		}
+		if epochExtra.EpochBlock != epoch.Number.Uint64() {
+			return errNotNextEpoch
+		}
+		validators = signingKeys(epochExtra.CurrentEpochValidators, epochExtra.CurrentEpochSignerKeys)
+		expected, index = e.nextEpochBlock(epochExtra.EpochBlock), epochExtra.Epoch
+	}
+	number := header.Number.Uint64()
+	if number != expected {
+		return errNotNextEpoch
+	}
+	if number == epoch.Number.Uint64()+1 && header.ParentHash != epoch.Hash() {
+		return consensus.ErrUnknownAncestor
+	}
+	headerExtra, err := DecodeHeaderExtra(header)
+	if err != nil {
+		return err
+	}
+	if headerExtra.Epoch != index+1 || headerExtra.EpochBlock != number || len(headerExtra.CurrentEpochValidators) == 0 {
+		return errNotNextEpoch
+	}
+	if header.Time < epoch.Time {
+		return ErrInvalidTimestamp
+	}
+	signer, err := ecrecover(header, e.signatures)
+	if err != nil {
+		return err
+	}
+	if !isInTurn(e.lightConfig(number), validators, header.Time, signer) {
+		return errUnauthorized
+	}
+	return nil
+}
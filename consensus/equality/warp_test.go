@@ -0,0 +1,77 @@
+package equality
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpochBlocks(t *testing.T) {
+	epoch := uint64(5)
+	config := params.EqualityConfig{
+		Period:              1,
+		Epoch:               10,
+		MaxValidatorsCount:  21,
+		MinCandidateBalance: big.NewInt(1),
+		Overrides:           []params.EqualityOverride{{Block: 24, Epoch: &epoch}},
+	}
+	engine := New(&config, rawdb.NewMemoryDatabase())
+
+	// Epochs are opened at the start block and every epoch length after
+	assert.Equal(t, []uint64{1, 11, 21}, engine.EpochBlocks(0, 21, 10))
+	assert.Equal(t, []uint64{11, 21}, engine.EpochBlocks(1, 100, 2))
+
+	// The override shortens the epoch in progress once in force
+	assert.Equal(t, []uint64{26, 31, 36}, engine.EpochBlocks(21, 40, 10))
+}
+
+func TestVerifyEpochHeaders(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 2)
+	validators := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		validators[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	config := params.EqualityConfig{
+		Period:              1,
+		Epoch:               10,
+		MaxValidatorsCount:  21,
+		MinCandidateBalance: big.NewInt(1),
+		Validators:          validators,
+	}
+	engine := New(&config, rawdb.NewMemoryDatabase())
+
+	// Epoch headers signed in turn, the second epoch elects the second validator only
+	genesis := &types.Header{Number: big.NewInt(0), UncleHash: uncleHash}
+	newEpochHeader := func(number, index uint64, elected []common.Address, key *ecdsa.PrivateKey) *types.Header {
+		header := newTestHeader(t, number, HeaderExtra{Epoch: index, EpochBlock: number, CurrentEpochValidators: elected})
+		header.UncleHash = uncleHash
+		header.Time = number
+		if number == 1 {
+			header.ParentHash = genesis.Hash()
+		}
+		signature, err := crypto.Sign(SealHash(header).Bytes(), key)
+		assert.Nil(t, err)
+		copy(header.Extra[len(header.Extra)-extraSeal:], signature)
+		return header
+	}
+	headers := []*types.Header{
+		newEpochHeader(1, 1, validators, keys[1]),
+		newEpochHeader(11, 2, validators[1:], keys[1]),
+		newEpochHeader(21, 3, validators, keys[1]),
+	}
+	assert.Nil(t, engine.VerifyEpochHeaders(genesis, headers))
+	assert.Nil(t, engine.VerifyEpochHeaders(headers[0], headers[1:]))
+
+	// Headers skipping an epoch or sealed out of turn are rejected
+	assert.Equal(t, errNotNextEpoch, engine.VerifyEpochHeaders(headers[0], headers[2:]))
+	forged := newEpochHeader(21, 3, validators, keys[0])
+	assert.Equal(t, errUnauthorized, engine.VerifyEpochHeaders(headers[1], []*types.Header{forged}))
+}
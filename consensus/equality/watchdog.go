@@ -0,0 +1,162 @@
+package equality
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/rlp"
+)
+
+const (
+	// watchdogReorgDepth is the number of canonical blocks a reorg may drop before
+	// the watchdog considers the head of the node diverging from the network.
+	watchdogReorgDepth = 3
+
+	// watchdogSealedBlocks is the number of recently sealed blocks remembered to
+	// detect the double signs.
+	watchdogSealedBlocks = 1024
+)
+
+var (
+	// errWatchdogTripped is returned when sealing with a signer whose watchdog
+	// detected an anomaly, until it is armed again.
+	errWatchdogTripped = errors.New("sealing stopped by the watchdog")
+
+	// errDoubleSignRisk is returned when asked to seal another block at a height
+	// already sealed.
+	errDoubleSignRisk = errors.New("block height already sealed")
+)
+
+// Watchdog seals blocks with a local account unlocked for each seal only, the
+// password being read from a file instead of the account being unlocked for the
+// lifetime of the node. It stops sealing as soon as sealing looks unsafe: the
+// head of the node diverging from the network or a second block being sealed
+// at the same height.
+type Watchdog struct {
+	wallet  accounts.Wallet
+	account accounts.Account
+	onTrip  func(err error) // Called once the watchdog tripped, e.g. to stop mining
+
+	password string                 // Password of the account, dropped once tripped
+	tripped  error                  // Anomaly which stopped sealing, nil while armed
+	sealed   map[uint64]common.Hash // Seal hashes of the recent blocks sealed
+	head     *types.Header          // Last head of the chain seen
+	lock     sync.Mutex
+}
+
+// NewWatchdog creates an armed watchdog sealing with the account of the wallet.
+func NewWatchdog(wallet accounts.Wallet, account accounts.Account, password string, onTrip func(err error)) *Watchdog {
+	return &Watchdog{
+		wallet:   wallet,
+		account:  account,
+		onTrip:   onTrip,
+		password: password,
+		sealed:   make(map[uint64]common.Hash),
+	}
+}
+
+// Address returns the address of the key sealing the blocks.
+func (w *Watchdog) Address() common.Address {
+	return w.account.Address
+}
+
+// Arm resumes sealing with the given password after the watchdog tripped. The
+// blocks sealed before are remembered.
+func (w *Watchdog) Arm(password string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.tripped != nil {
+		log.Info("[equality] Watchdog armed again", "signer", w.account.Address, "tripped", w.tripped)
+	}
+	w.password, w.tripped = password, nil
+}
+
+// Tripped returns the anomaly which stopped sealing, nil if the watchdog is armed.
+func (w *Watchdog) Tripped() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.tripped
+}
+
+// trip stops sealing, the lock being held.
+func (w *Watchdog) trip(err error) {
+	if w.tripped != nil {
+		return
+	}
+	log.Error("[equality] Watchdog stopped sealing", "signer", w.account.Address, "err", err)
+	w.password, w.tripped = "", err
+	if w.onTrip != nil {
+		go w.onTrip(err)
+	}
+}
+
+// SignData unlocks the account to seal the header, matching the SignerFn of the
+// engine. The watchdog trips instead when another header was sealed at the same
+// height.
+func (w *Watchdog) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.tripped != nil {
+		return nil, errWatchdogTripped
+	}
+	if account.Address != w.account.Address {
+		return nil, accounts.ErrUnknownAccount
+	}
+	// The data sealed is the RLP of the header without the seal
+	var header types.Header
+	if err := rlp.DecodeBytes(data, &header); err != nil {
+		return nil, fmt.Errorf("invalid header to seal: %v", err)
+	}
+	number, hash := header.Number.Uint64(), header.Hash() // Seal hash, the seal being stripped
+	if sealed, ok := w.sealed[number]; ok && sealed != hash {
+		w.trip(fmt.Errorf("%w: %d", errDoubleSignRisk, number))
+		return nil, errDoubleSignRisk
+	}
+	signature, err := w.wallet.SignDataWithPassphrase(w.account, w.password, mimeType, data)
+	if err != nil {
+		return nil, err
+	}
+	w.sealed[number] = hash
+	delete(w.sealed, number-watchdogSealedBlocks)
+	return signature, nil
+}
+
+// ChainHead feeds the watchdog with the new heads of the chain. The watchdog
+// trips when the head diverged from the previous one, more than watchdogReorgDepth
+// canonical blocks being dropped.
+func (w *Watchdog) ChainHead(chain consensus.ChainHeaderReader, head *types.Header) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	previous := w.head
+	w.head = head
+	if previous == nil || head.Number.Uint64() > previous.Number.Uint64()+watchdogSealedBlocks {
+		return
+	}
+	// Rewind the new head to the height of the previous one, and both of them
+	// down to their common ancestor
+	ancestor := head
+	for ancestor != nil && ancestor.Number.Uint64() > previous.Number.Uint64() {
+		ancestor = chain.GetHeader(ancestor.ParentHash, ancestor.Number.Uint64()-1)
+	}
+	dropped := 0
+	for ancestor != nil && previous != nil && ancestor.Hash() != previous.Hash() && dropped <= watchdogReorgDepth {
+		if ancestor.Number.Uint64() == previous.Number.Uint64() {
+			ancestor = chain.GetHeader(ancestor.ParentHash, ancestor.Number.Uint64()-1)
+		}
+		previous = chain.GetHeader(previous.ParentHash, previous.Number.Uint64()-1)
+		dropped++
+	}
+	if dropped > watchdogReorgDepth {
+		w.trip(fmt.Errorf("chain head diverged, more than %d blocks dropped at %d", watchdogReorgDepth, head.Number))
+	}
+}
@@ -0,0 +1,101 @@
+package equality
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// testLockedWallet holds the test key, which is only used with its password.
+type testLockedWallet struct {
+	accounts.Wallet
+}
+
+func (w *testLockedWallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	if passphrase != "secret" {
+		return nil, accounts.ErrNotSupported
+	}
+	return crypto.Sign(crypto.Keccak256(data), testUserKey)
+}
+
+// testForkReader retrieves the headers of both sides of a fork.
+type testForkReader struct {
+	testChainReader
+	headers map[common.Hash]*types.Header
+}
+
+func (chain *testForkReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return chain.headers[hash]
+}
+
+func TestWatchdogDoubleSign(t *testing.T) {
+	tripped := make(chan error, 1)
+	watchdog := NewWatchdog(new(testLockedWallet), accounts.Account{Address: testUserAddress}, "secret", func(err error) {
+		tripped <- err
+	})
+	account := accounts.Account{Address: testUserAddress}
+	header := &types.Header{Number: big.NewInt(1), Extra: make([]byte, extraSeal)}
+
+	// Sealing the same header twice is harmless
+	_, err := watchdog.SignData(account, accounts.MimetypeEquality, EqualityRLP(header))
+	assert.Nil(t, err)
+	_, err = watchdog.SignData(account, accounts.MimetypeEquality, EqualityRLP(header))
+	assert.Nil(t, err)
+
+	// Sealing another header at the same height stops sealing
+	header.Time = 1
+	_, err = watchdog.SignData(account, accounts.MimetypeEquality, EqualityRLP(header))
+	assert.Equal(t, errDoubleSignRisk, err)
+	assert.True(t, errors.Is(<-tripped, errDoubleSignRisk))
+	assert.NotNil(t, watchdog.Tripped())
+
+	header.Number = big.NewInt(2)
+	_, err = watchdog.SignData(account, accounts.MimetypeEquality, EqualityRLP(header))
+	assert.Equal(t, errWatchdogTripped, err)
+
+	// Once armed again the blocks sealed before are still remembered
+	watchdog.Arm("secret")
+	assert.Nil(t, watchdog.Tripped())
+	_, err = watchdog.SignData(account, accounts.MimetypeEquality, EqualityRLP(header))
+	assert.Nil(t, err)
+	header.Number = big.NewInt(1)
+	_, err = watchdog.SignData(account, accounts.MimetypeEquality, EqualityRLP(header))
+	assert.Equal(t, errDoubleSignRisk, err)
+}
+
+func TestWatchdogChainHead(t *testing.T) {
+	chain := &testForkReader{headers: make(map[common.Hash]*types.Header)}
+	makeChain := func(parent *types.Header, n int, time uint64) []*types.Header {
+		var headers []*types.Header
+		for i := 0; i < n; i++ {
+			header := &types.Header{Number: new(big.Int).Add(parent.Number, common.Big1), ParentHash: parent.Hash(), Time: time}
+			chain.headers[header.Hash()] = header
+			headers = append(headers, header)
+			parent = header
+		}
+		return headers
+	}
+	genesis := &types.Header{Number: big.NewInt(0)}
+	chain.headers[genesis.Hash()] = genesis
+	canonical := makeChain(genesis, 10, 1)
+
+	watchdog := NewWatchdog(new(testLockedWallet), accounts.Account{Address: testUserAddress}, "secret", nil)
+	for _, header := range canonical[:8] {
+		watchdog.ChainHead(chain, header)
+	}
+	// Shallow reorgs are part of the life of the chain
+	shallow := makeChain(canonical[4], 5, 2)
+	watchdog.ChainHead(chain, shallow[len(shallow)-1])
+	assert.Nil(t, watchdog.Tripped())
+
+	// Dropping more blocks means the node was sealing on another chain
+	deep := makeChain(canonical[1], 10, 3)
+	watchdog.ChainHead(chain, deep[len(deep)-1])
+	assert.NotNil(t, watchdog.Tripped())
+}
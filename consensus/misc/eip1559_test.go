@@ -0,0 +1,97 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// config returns a chain config with London active from block 5.
+func config() *params.ChainConfig {
+	config := *params.TestChainConfig
+	config.BerlinBlock = big.NewInt(0)
+	config.LondonBlock = big.NewInt(5)
+	return &config
+}
+
+// TestCalcBaseFee assumes all blocks are EIP-1559 blocks.
+func TestCalcBaseFee(t *testing.T) {
+	tests := []struct {
+		parentBaseFee   uint64
+		parentGasLimit  uint64
+		parentGasUsed   uint64
+		expectedBaseFee uint64
+	}{
+		{params.InitialBaseFee, 20000000, 10000000, params.InitialBaseFee}, // usage == target
+		{params.InitialBaseFee, 20000000, 9000000, 987500000},              // usage below target
+		{params.InitialBaseFee, 20000000, 11000000, 1012500000},            // usage above target
+		{params.InitialBaseFee, 0, 0, params.InitialBaseFee},               // no target
+	}
+	for i, test := range tests {
+		parent := &types.Header{
+			Number:   big.NewInt(32),
+			GasLimit: test.parentGasLimit,
+			GasUsed:  test.parentGasUsed,
+			BaseFee:  new(big.Int).SetUint64(test.parentBaseFee),
+		}
+		if have, want := CalcBaseFee(config(), parent), new(big.Int).SetUint64(test.expectedBaseFee); have.Cmp(want) != 0 {
+			t.Errorf("test %d: have %d, want %d", i, have, want)
+		}
+	}
+}
+
+// TestVerifyEip1559Header tests the base fee of the headers across the fork.
+func TestVerifyEip1559Header(t *testing.T) {
+	initial := new(big.Int).SetUint64(params.InitialBaseFee)
+	tests := []struct {
+		number  int64
+		parent  *big.Int
+		baseFee *big.Int
+		ok      bool
+	}{
+		{4, nil, nil, true},            // Before the fork
+		{4, nil, initial, false},       // Base fee before the fork
+		{5, nil, initial, true},        // Initial base fee at the fork
+		{5, nil, nil, false},           // Missing base fee
+		{5, nil, big.NewInt(1), false}, // Wrong initial base fee
+		{6, initial, initial, true},    // Parent used its target
+		{6, initial, big.NewInt(1), false},
+	}
+	for i, test := range tests {
+		parent := &types.Header{
+			Number:   big.NewInt(test.number - 1),
+			GasLimit: 20000000,
+			GasUsed:  10000000,
+			BaseFee:  test.parent,
+		}
+		header := &types.Header{
+			Number:  big.NewInt(test.number),
+			BaseFee: test.baseFee,
+		}
+		err := VerifyEip1559Header(config(), parent, header)
+		if test.ok && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("test %d: invalid header accepted", i)
+		}
+	}
+}
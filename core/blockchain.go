@@ -567,12 +567,25 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	if _, err := trie.NewSecure(block.Root(), bc.stateCache.TrieDB()); err != nil {
 		return err
 	}
+	// Engines keeping snapshots next to the state need them at the pivot too
+	type snapshotEngine interface {
+		EnsureSnapshot(chain consensus.ChainHeaderReader, header *types.Header) error
+	}
+	if engine, ok := bc.engine.(snapshotEngine); ok {
+		if err := engine.EnsureSnapshot(bc, block.Header()); err != nil {
+			return fmt.Errorf("consensus snapshot of block [%x…] unavailable: %v", hash[:4], err)
+		}
+	}
 	// If all checks out, manually set the head block
 	bc.chainmu.Lock()
 	bc.currentBlock.Store(block)
 	headBlockGauge.Update(int64(block.NumberU64()))
 	bc.chainmu.Unlock()
 
+	// The blocks past the pivot are executed with the forks approved before it
+	if scheduler, ok := bc.engine.(ForkScheduler); ok {
+		scheduler.ScheduleForks(bc, block.Header())
+	}
 	// Destroy any existing state snapshot and regenerate it in the background
 	if bc.snaps != nil {
 		bc.snaps.Rebuild(block.Root())
@@ -699,6 +712,13 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	return nil
 }
 
+// ForkScheduler is implemented by the consensus engines whose validators schedule
+// protocol forks on chain. The forks approved by a block are only applied to the
+// chain config once the block is canonical.
+type ForkScheduler interface {
+	ScheduleForks(chain consensus.ChainHeaderReader, header *types.Header)
+}
+
 // writeHeadBlock injects a new head block into the current block chain. This method
 // assumes that the block is indeed a true head. It will also reset the head
 // header and the head fast sync block to this very same block if they are older
@@ -732,6 +752,10 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	}
 	bc.currentBlock.Store(block)
 	headBlockGauge.Update(int64(block.NumberU64()))
+
+	if scheduler, ok := bc.engine.(ForkScheduler); ok {
+		scheduler.ScheduleForks(bc, block.Header())
+	}
 }
 
 // Genesis retrieves the chain's genesis block.
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -29,6 +30,7 @@ import (
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/consensus"
 	"github.com/SecretBlockChain/go-secret/consensus/ethash"
+	"github.com/SecretBlockChain/go-secret/consensus/misc"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/state"
 	"github.com/SecretBlockChain/go-secret/core/types"
@@ -3029,3 +3031,129 @@ func TestInitThenFailCreateContract(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the base fee is set and verified from the London fork on, the
+// coinbase earning the price above it only.
+func TestEIP1559Transition(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		coinbase = common.Address{0x01}
+		funds    = big.NewInt(1000000000000000000)
+		price    = new(big.Int).SetUint64(2 * params.InitialBaseFee)
+		config   = *params.AllEthashProtocolChanges
+		db       = rawdb.NewMemoryDatabase()
+	)
+	config.BerlinBlock = big.NewInt(0)
+	config.LondonBlock = big.NewInt(2)
+	gspec := &Genesis{Config: &config, Alloc: GenesisAlloc{address: {Balance: funds}}}
+	genesis := gspec.MustCommit(db)
+	signer := types.NewEIP155Signer(config.ChainID)
+
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), db, 3, func(i int, b *BlockGen) {
+		b.SetCoinbase(coinbase)
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{0x02}, big.NewInt(1), params.TxGas, price, nil), signer, key)
+		b.AddTx(tx)
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+
+	if fee := blocks[0].BaseFee(); fee != nil {
+		t.Errorf("base fee before the fork: %v", fee)
+	}
+	if fee := blocks[1].BaseFee(); fee == nil || fee.Uint64() != params.InitialBaseFee {
+		t.Errorf("base fee mismatch at the fork: have %v, want %d", fee, params.InitialBaseFee)
+	}
+	if fee, want := blocks[2].BaseFee(), misc.CalcBaseFee(&config, blocks[1].Header()); fee == nil || fee.Cmp(want) != 0 || fee.Cmp(blocks[1].BaseFee()) >= 0 {
+		t.Errorf("base fee mismatch past the fork: have %v, want %v", fee, want)
+	}
+
+	// The coinbase earns the price above the base fee, the base fee is burned
+	statedb, _ := chain.State()
+	want := new(big.Int).Mul(ethash.ConstantinopleBlockReward, big.NewInt(3))
+	for _, block := range blocks {
+		tip := new(big.Int).Set(price)
+		if block.BaseFee() != nil {
+			tip.Sub(tip, block.BaseFee())
+		}
+		want.Add(want, tip.Mul(tip, new(big.Int).SetUint64(params.TxGas)))
+	}
+	if balance := statedb.GetBalance(coinbase); balance.Cmp(want) != 0 {
+		t.Errorf("coinbase balance mismatch: have %v, want %v", balance, want)
+	}
+
+	// Blocks with a wrong base fee are rejected
+	header := blocks[2].Header()
+	header.BaseFee = new(big.Int).Add(header.BaseFee, common.Big1)
+	if err := ethash.NewFaker().VerifyHeader(chain, header, false); err == nil {
+		t.Error("invalid base fee accepted")
+	}
+
+	// Transactions priced below the base fee are invalid
+	tx, _ := types.SignTx(types.NewTransaction(3, common.Address{0x02}, big.NewInt(1), params.TxGas, big.NewInt(1), nil), signer, key)
+	head := chain.CurrentHeader()
+	head.BaseFee = big.NewInt(2)
+	if _, err := ApplyTransaction(&config, chain, &coinbase, new(GasPool).AddGas(params.TxGas), statedb, head, tx, new(uint64), vm.Config{}); !errors.Is(err, ErrFeeCapTooLow) {
+		t.Errorf("transaction below the base fee accepted: %v", err)
+	}
+}
+
+// forkSchedulerEngine records the blocks the chain asks to schedule the forks of.
+type forkSchedulerEngine struct {
+	consensus.Engine
+	scheduled map[common.Hash]bool
+}
+
+func (e *forkSchedulerEngine) ScheduleForks(chain consensus.ChainHeaderReader, header *types.Header) {
+	e.scheduled[header.Hash()] = true
+}
+
+// Tests that the forks are only scheduled from canonical blocks, the side chains
+// being left out until they are reorganised in.
+func TestScheduleForksCanonical(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+		engine  = &forkSchedulerEngine{Engine: ethash.NewFaker(), scheduled: make(map[common.Hash]bool)}
+	)
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	canonical, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {})
+	side, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0x01})
+	})
+	if _, err := blockchain.InsertChain(canonical); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if _, err := blockchain.InsertChain(side[:2]); err != nil {
+		t.Fatalf("failed to insert side chain: %v", err)
+	}
+	for _, block := range canonical {
+		if !engine.scheduled[block.Hash()] {
+			t.Errorf("canonical block %d not scheduled", block.NumberU64())
+		}
+	}
+	for _, block := range side[:2] {
+		if engine.scheduled[block.Hash()] {
+			t.Errorf("side block %d scheduled", block.NumberU64())
+		}
+	}
+	if _, err := blockchain.InsertChain(side[2:]); err != nil {
+		t.Fatalf("failed to reorganise: %v", err)
+	}
+	for _, block := range side {
+		if !engine.scheduled[block.Hash()] {
+			t.Errorf("reorganised block %d not scheduled", block.NumberU64())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/naoina/toml"
+)
+
+// ChainSpec is a network preset defined outside of the code: the genesis of the
+// chain, including its chain configuration, along with the parameters nodes need
+// to join the network.
+type ChainSpec struct {
+	Name      string   `json:"name,omitempty"`      // Human readable name of the network
+	NetworkId uint64   `json:"networkId,omitempty"` // Network identifier (0 = chain ID of the genesis)
+	Bootnodes []string `json:"bootnodes,omitempty"` // Enode URLs of the bootstrap nodes
+	Genesis   *Genesis `json:"genesis"`             // Genesis block of the network
+
+	Checkpoint       *params.TrustedCheckpoint      `json:"checkpoint,omitempty"`       // Trusted checkpoint to start light sync from
+	CheckpointOracle *params.CheckpointOracleConfig `json:"checkpointOracle,omitempty"` // Oracle contract publishing signed checkpoints
+}
+
+// LoadChainSpec reads a chain spec from a JSON or, if the file extension says so,
+// a TOML file. Both use the field names of the JSON genesis format.
+func LoadChainSpec(path string) (*ChainSpec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		// The genesis fields only know how to decode from JSON, convert the document
+		var doc map[string]interface{}
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid chain spec %s: %v", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("invalid chain spec %s: %v", path, err)
+		}
+	}
+	spec := new(ChainSpec)
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("invalid chain spec %s: %v", path, err)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("invalid chain spec %s: %v", path, err)
+	}
+	return spec, nil
+}
+
+// validate checks that the spec defines a usable network.
+func (spec *ChainSpec) validate() error {
+	if spec.Genesis == nil {
+		return errors.New("genesis missing")
+	}
+	if spec.Genesis.Config == nil {
+		return errGenesisNoConfig
+	}
+	if spec.Genesis.Config.ChainID == nil {
+		return errors.New("chain ID missing")
+	}
+	if err := spec.Genesis.Config.CheckConfigForkOrder(); err != nil {
+		return err
+	}
+	if oracle := spec.CheckpointOracle; oracle != nil {
+		if oracle.Threshold == 0 || uint64(len(oracle.Signers)) < oracle.Threshold {
+			return fmt.Errorf("checkpoint oracle needs at least %d signers, %d given", oracle.Threshold, len(oracle.Signers))
+		}
+	}
+	return spec.Genesis.validate()
+}
+
+// Network returns the network identifier of the spec, defaulting to the chain ID.
+func (spec *ChainSpec) Network() uint64 {
+	if spec.NetworkId != 0 {
+		return spec.NetworkId
+	}
+	return spec.Genesis.Config.ChainID.Uint64()
+}
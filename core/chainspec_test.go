@@ -0,0 +1,131 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+)
+
+const testChainSpecJSON = `{
+  "name": "private",
+  "bootnodes": ["enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"],
+  "genesis": {
+    "config": {
+      "chainId": 4242,
+      "homesteadBlock": 0,
+      "eip150Block": 0,
+      "eip155Block": 0,
+      "eip158Block": 0,
+      "byzantiumBlock": 0,
+      "equality": {
+        "period": 3,
+        "epoch": 100,
+        "maxValidatorsCount": 21,
+        "minCandidateBalance": "0x64",
+        "genesisTimestamp": 1609459200,
+        "validators": ["0xcc7c8317b21e1cea6139700c3c46c21af998d14c"],
+        "pool": "0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c",
+        "rewards": [{"number": 1000, "reward": "0x1"}]
+      }
+    },
+    "timestamp": "0x5fee6600",
+    "extraData": "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+    "gasLimit": "0x47b760",
+    "difficulty": "0x1",
+    "alloc": {"0xcc7c8317b21e1cea6139700c3c46c21af998d14c": {"balance": "0x64"}}
+  }
+}`
+
+const testChainSpecTOML = `
+name = "private"
+networkId = 7
+
+[genesis]
+gasLimit = "0x47b760"
+difficulty = "0x1"
+
+[genesis.config]
+chainId = 4242
+homesteadBlock = 0
+
+[genesis.alloc.0xcc7c8317b21e1cea6139700c3c46c21af998d14c]
+balance = "0x64"
+`
+
+func TestLoadChainSpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chainspec-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	account := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+
+	spec, err := LoadChainSpec(write("spec.json", testChainSpecJSON))
+	if err != nil {
+		t.Fatalf("failed to load JSON spec: %v", err)
+	}
+	if spec.Network() != 4242 || len(spec.Bootnodes) != 1 || spec.Genesis.Config.Equality == nil {
+		t.Errorf("JSON spec mismatch: network %d, bootnodes %v, engine %v", spec.Network(), spec.Bootnodes, spec.Genesis.Config)
+	}
+	if balance := spec.Genesis.Alloc[account].Balance; balance.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("JSON spec balance mismatch: have %v, want 100", balance)
+	}
+
+	spec, err = LoadChainSpec(write("spec.toml", testChainSpecTOML))
+	if err != nil {
+		t.Fatalf("failed to load TOML spec: %v", err)
+	}
+	if spec.Network() != 7 || spec.Genesis.Config.ChainID.Uint64() != 4242 {
+		t.Errorf("TOML spec mismatch: network %d, chain %v", spec.Network(), spec.Genesis.Config.ChainID)
+	}
+	if balance := spec.Genesis.Alloc[account].Balance; balance.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("TOML spec balance mismatch: have %v, want 100", balance)
+	}
+
+	// Broken engine configurations are rejected upfront
+	if _, err := LoadChainSpec(write("broken.json", `{"genesis": {"config": {"chainId": 1, "equality": {"period": 0}}, "difficulty": "0x1", "alloc": {}}}`)); err == nil {
+		t.Error("invalid equality spec accepted")
+	}
+
+	// Checkpoint oracles must have enough signers to reach their threshold
+	oracle := `{"genesis": {"config": {"chainId": 1}, "gasLimit": "0x47b760", "difficulty": "0x1", "alloc": {}}, "checkpointOracle": {"address": "0x9a9070028361f7aabeb3f2f2dc07f82c4a98a02a", "signers": ["0xcc7c8317b21e1cea6139700c3c46c21af998d14c"], "threshold": %d}}`
+	spec, err = LoadChainSpec(write("oracle.json", fmt.Sprintf(oracle, 1)))
+	if err != nil {
+		t.Fatalf("failed to load oracle spec: %v", err)
+	}
+	if spec.CheckpointOracle == nil || spec.CheckpointOracle.Signers[0] != account {
+		t.Errorf("oracle spec mismatch: have %v", spec.CheckpointOracle)
+	}
+	if _, err := LoadChainSpec(write("oracle.json", fmt.Sprintf(oracle, 2))); err == nil {
+		t.Error("unreachable oracle threshold accepted")
+	}
+}
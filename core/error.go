@@ -63,4 +63,8 @@ var (
 	// ErrIntrinsicGas is returned if the transaction is specified to use less gas
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
+
+	// ErrFeeCapTooLow is returned if the gas price of a transaction is lower than
+	// the base fee of the block.
+	ErrFeeCapTooLow = errors.New("gas price less than block base fee")
 )
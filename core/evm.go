@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"math/big"
 
 	"github.com/SecretBlockChain/go-secret/common"
@@ -25,6 +26,10 @@ import (
 	"github.com/SecretBlockChain/go-secret/core/vm"
 )
 
+// errNoConsensusState is returned by the consensus precompile and the staking
+// contract on chains whose engine exposes no consensus state.
+var errNoConsensusState = errors.New("no consensus state")
+
 // ChainContext supports retrieving headers and consensus parameters from the
 // current blockchain to be used during transaction processing.
 type ChainContext interface {
@@ -35,6 +40,14 @@ type ChainContext interface {
 	GetHeader(common.Hash, uint64) *types.Header
 }
 
+// ConsensusReaderEngine is implemented by the consensus engines exposing their
+// state at a block to the consensus precompile and the staking contract.
+type ConsensusReaderEngine interface {
+	// ConsensusReader returns the reader of the consensus state at the header,
+	// nil if the engine doesn't seal it.
+	ConsensusReader(header *types.Header, getHeader func(common.Hash, uint64) *types.Header) vm.ConsensusReader
+}
+
 // NewEVMContext creates a new context for use in the EVM.
 func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author *common.Address) vm.Context {
 	// If we don't have an explicit author (i.e. not mining), extract from the header
@@ -44,6 +57,10 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 	} else {
 		beneficiary = *author
 	}
+	var baseFee *big.Int
+	if header.BaseFee != nil {
+		baseFee = new(big.Int).Set(header.BaseFee)
+	}
 	return vm.Context{
 		CanTransfer: CanTransfer,
 		Transfer:    Transfer,
@@ -53,9 +70,77 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		BlockNumber: new(big.Int).Set(header.Number),
 		Time:        new(big.Int).SetUint64(header.Time),
 		Difficulty:  new(big.Int).Set(header.Difficulty),
+		BaseFee:     baseFee,
 		GasLimit:    header.GasLimit,
 		GasPrice:    new(big.Int).Set(msg.GasPrice()),
+		Consensus:   &chainConsensusReader{chain: chain, header: header},
+	}
+}
+
+// chainConsensusReader resolves the consensus reader of the engine of the chain
+// on first use, sparing the lookup to the transactions never calling the
+// consensus precompile.
+type chainConsensusReader struct {
+	chain  ChainContext
+	header *types.Header
+	reader vm.ConsensusReader
+}
+
+func (r *chainConsensusReader) resolve() (vm.ConsensusReader, error) {
+	if r.reader == nil {
+		if engine, ok := r.chain.Engine().(ConsensusReaderEngine); ok {
+			r.reader = engine.ConsensusReader(r.header, r.chain.GetHeader)
+		}
+		if r.reader == nil {
+			return nil, errNoConsensusState
+		}
+	}
+	return r.reader, nil
+}
+
+// Epoch implements vm.ConsensusReader.
+func (r *chainConsensusReader) Epoch() (uint64, uint64, error) {
+	reader, err := r.resolve()
+	if err != nil {
+		return 0, 0, err
+	}
+	return reader.Epoch()
+}
+
+// IsValidator implements vm.ConsensusReader.
+func (r *chainConsensusReader) IsValidator(address common.Address) (bool, error) {
+	reader, err := r.resolve()
+	if err != nil {
+		return false, err
+	}
+	return reader.IsValidator(address)
+}
+
+// Candidate implements vm.ConsensusReader.
+func (r *chainConsensusReader) Candidate(address common.Address) (*big.Int, error) {
+	reader, err := r.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return reader.Candidate(address)
+}
+
+// MinCandidateBalance implements vm.ConsensusReader.
+func (r *chainConsensusReader) MinCandidateBalance() (*big.Int, error) {
+	reader, err := r.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return reader.MinCandidateBalance()
+}
+
+// EpochStatus implements vm.ConsensusReader.
+func (r *chainConsensusReader) EpochStatus(address common.Address, epoch uint64) (uint64, *big.Int, bool, error) {
+	reader, err := r.resolve()
+	if err != nil {
+		return 0, nil, false, err
 	}
+	return reader.EpochStatus(address, epoch)
 }
 
 // GetHashFn returns a GetHashFunc which retrieves header hashes by number
@@ -203,26 +203,12 @@ func checksumToBytes(hash uint32) [4]byte {
 }
 
 // gatherForks gathers all the known forks and creates a sorted list out of them.
+// Besides the chain rules, the forks scheduled in the equality engine section
+// are gathered too, as peers disagreeing on them would fork off each other.
 func gatherForks(config *params.ChainConfig) []uint64 {
-	// Gather all the fork block numbers via reflection
-	kind := reflect.TypeOf(params.ChainConfig{})
-	conf := reflect.ValueOf(config).Elem()
-
-	var forks []uint64
-	for i := 0; i < kind.NumField(); i++ {
-		// Fetch the next field and skip non-fork rules
-		field := kind.Field(i)
-		if !strings.HasSuffix(field.Name, "Block") {
-			continue
-		}
-		if field.Type != reflect.TypeOf(new(big.Int)) {
-			continue
-		}
-		// Extract the fork rule block number and aggregate it
-		rule := conf.Field(i).Interface().(*big.Int)
-		if rule != nil {
-			forks = append(forks, rule.Uint64())
-		}
+	forks := gatherBlocks(reflect.ValueOf(config).Elem())
+	if config.Equality != nil {
+		forks = append(forks, gatherBlocks(reflect.ValueOf(config.Equality).Elem())...)
 	}
 	// Sort the fork block numbers to permit chronological XOR
 	for i := 0; i < len(forks); i++ {
@@ -245,3 +231,26 @@ func gatherForks(config *params.ChainConfig) []uint64 {
 	}
 	return forks
 }
+
+// gatherBlocks collects the fork block numbers of a config struct via reflection.
+func gatherBlocks(conf reflect.Value) []uint64 {
+	kind := conf.Type()
+
+	var forks []uint64
+	for i := 0; i < kind.NumField(); i++ {
+		// Fetch the next field and skip non-fork rules
+		field := kind.Field(i)
+		if !strings.HasSuffix(field.Name, "Block") {
+			continue
+		}
+		if field.Type != reflect.TypeOf(new(big.Int)) {
+			continue
+		}
+		// Extract the fork rule block number and aggregate it
+		rule := conf.Field(i).Interface().(*big.Int)
+		if rule != nil {
+			forks = append(forks, rule.Uint64())
+		}
+	}
+	return forks
+}
@@ -19,6 +19,7 @@ package forkid
 import (
 	"bytes"
 	"math"
+	"math/big"
 	"testing"
 
 	"github.com/SecretBlockChain/go-secret/common"
@@ -26,6 +27,37 @@ import (
 	"github.com/SecretBlockChain/go-secret/rlp"
 )
 
+var (
+	// ethereumGenesisHash and ethereumChainConfig are the genesis and fork schedule
+	// of the Ethereum main network. The Secret networks activate every rule in
+	// their genesis, the Ethereum history exercises the fork ID rules instead.
+	ethereumGenesisHash = common.HexToHash("0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3")
+	ethereumChainConfig = &params.ChainConfig{
+		ChainID:             big.NewInt(1),
+		HomesteadBlock:      big.NewInt(1150000),
+		DAOForkBlock:        big.NewInt(1920000),
+		DAOForkSupport:      true,
+		EIP150Block:         big.NewInt(2463000),
+		EIP155Block:         big.NewInt(2675000),
+		EIP158Block:         big.NewInt(2675000),
+		ByzantiumBlock:      big.NewInt(4370000),
+		ConstantinopleBlock: big.NewInt(7280000),
+		PetersburgBlock:     big.NewInt(7280000),
+		IstanbulBlock:       big.NewInt(9069000),
+		MuirGlacierBlock:    big.NewInt(9200000),
+		Ethash:              new(params.EthashConfig),
+	}
+
+	// transitionChainConfig is a chain sealed by clique switching to the equality
+	// engine at block 1000.
+	transitionChainConfig = func() *params.ChainConfig {
+		config := *params.TestnetChainConfig
+		config.Clique = &params.CliqueConfig{Period: 5, Epoch: 30000}
+		config.EqualityBlock = big.NewInt(1000)
+		return &config
+	}()
+)
+
 // TestCreation tests that different genesis and fork rule combinations result in
 // the correct fork ID.
 func TestCreation(t *testing.T) {
@@ -38,10 +70,10 @@ func TestCreation(t *testing.T) {
 		genesis common.Hash
 		cases   []testcase
 	}{
-		// Mainnet test cases
+		// Ethereum mainnet test cases
 		{
-			params.MainnetChainConfig,
-			params.MainnetGenesisHash,
+			ethereumChainConfig,
+			ethereumGenesisHash,
 			[]testcase{
 				{0, ID{Hash: checksumToBytes(0xfc64ec04), Next: 1150000}},       // Unsynced
 				{1149999, ID{Hash: checksumToBytes(0xfc64ec04), Next: 1150000}}, // Last Frontier block
@@ -63,56 +95,33 @@ func TestCreation(t *testing.T) {
 				{10000000, ID{Hash: checksumToBytes(0xe029e991), Next: 0}},      // Future Muir Glacier block
 			},
 		},
-		// Ropsten test cases
+		// Secret mainnet test cases, all rules active since genesis
 		{
-			params.RopstenChainConfig,
-			params.RopstenGenesisHash,
+			params.MainnetChainConfig,
+			params.MainnetGenesisHash,
 			[]testcase{
-				{0, ID{Hash: checksumToBytes(0x30c7ddbc), Next: 10}},            // Unsynced, last Frontier, Homestead and first Tangerine block
-				{9, ID{Hash: checksumToBytes(0x30c7ddbc), Next: 10}},            // Last Tangerine block
-				{10, ID{Hash: checksumToBytes(0x63760190), Next: 1700000}},      // First Spurious block
-				{1699999, ID{Hash: checksumToBytes(0x63760190), Next: 1700000}}, // Last Spurious block
-				{1700000, ID{Hash: checksumToBytes(0x3ea159c7), Next: 4230000}}, // First Byzantium block
-				{4229999, ID{Hash: checksumToBytes(0x3ea159c7), Next: 4230000}}, // Last Byzantium block
-				{4230000, ID{Hash: checksumToBytes(0x97b544f3), Next: 4939394}}, // First Constantinople block
-				{4939393, ID{Hash: checksumToBytes(0x97b544f3), Next: 4939394}}, // Last Constantinople block
-				{4939394, ID{Hash: checksumToBytes(0xd6e2149b), Next: 6485846}}, // First Petersburg block
-				{6485845, ID{Hash: checksumToBytes(0xd6e2149b), Next: 6485846}}, // Last Petersburg block
-				{6485846, ID{Hash: checksumToBytes(0x4bc66396), Next: 7117117}}, // First Istanbul block
-				{7117116, ID{Hash: checksumToBytes(0x4bc66396), Next: 7117117}}, // Last Istanbul block
-				{7117117, ID{Hash: checksumToBytes(0x6727ef90), Next: 0}},       // First Muir Glacier block
-				{7500000, ID{Hash: checksumToBytes(0x6727ef90), Next: 0}},       // Future
+				{0, ID{Hash: checksumToBytes(0x587df107), Next: 0}},        // Unsynced
+				{10000000, ID{Hash: checksumToBytes(0x587df107), Next: 0}}, // Future block
 			},
 		},
-		// Rinkeby test cases
+		// Secret testnet test cases, all rules active since genesis
 		{
-			params.RinkebyChainConfig,
-			params.RinkebyGenesisHash,
+			params.TestnetChainConfig,
+			params.TestnetGenesisHash,
 			[]testcase{
-				{0, ID{Hash: checksumToBytes(0x3b8e0691), Next: 1}},             // Unsynced, last Frontier block
-				{1, ID{Hash: checksumToBytes(0x60949295), Next: 2}},             // First and last Homestead block
-				{2, ID{Hash: checksumToBytes(0x8bde40dd), Next: 3}},             // First and last Tangerine block
-				{3, ID{Hash: checksumToBytes(0xcb3a64bb), Next: 1035301}},       // First Spurious block
-				{1035300, ID{Hash: checksumToBytes(0xcb3a64bb), Next: 1035301}}, // Last Spurious block
-				{1035301, ID{Hash: checksumToBytes(0x8d748b57), Next: 3660663}}, // First Byzantium block
-				{3660662, ID{Hash: checksumToBytes(0x8d748b57), Next: 3660663}}, // Last Byzantium block
-				{3660663, ID{Hash: checksumToBytes(0xe49cab14), Next: 4321234}}, // First Constantinople block
-				{4321233, ID{Hash: checksumToBytes(0xe49cab14), Next: 4321234}}, // Last Constantinople block
-				{4321234, ID{Hash: checksumToBytes(0xafec6b27), Next: 5435345}}, // First Petersburg block
-				{5435344, ID{Hash: checksumToBytes(0xafec6b27), Next: 5435345}}, // Last Petersburg block
-				{5435345, ID{Hash: checksumToBytes(0xcbdb8838), Next: 0}},       // First Istanbul block
-				{6000000, ID{Hash: checksumToBytes(0xcbdb8838), Next: 0}},       // Future Istanbul block
+				{0, ID{Hash: checksumToBytes(0x8fbc1a44), Next: 0}},        // Unsynced
+				{10000000, ID{Hash: checksumToBytes(0x8fbc1a44), Next: 0}}, // Future block
 			},
 		},
-		// Goerli test cases
+		// Chain switching to the equality engine later on
 		{
-			params.GoerliChainConfig,
-			params.GoerliGenesisHash,
+			transitionChainConfig,
+			params.TestnetGenesisHash,
 			[]testcase{
-				{0, ID{Hash: checksumToBytes(0xa3f5ab08), Next: 1561651}},       // Unsynced, last Frontier, Homestead, Tangerine, Spurious, Byzantium, Constantinople and first Petersburg block
-				{1561650, ID{Hash: checksumToBytes(0xa3f5ab08), Next: 1561651}}, // Last Petersburg block
-				{1561651, ID{Hash: checksumToBytes(0xc25efa5c), Next: 0}},       // First Istanbul block
-				{2000000, ID{Hash: checksumToBytes(0xc25efa5c), Next: 0}},       // Future Istanbul block
+				{0, ID{Hash: checksumToBytes(0x8fbc1a44), Next: 1000}},    // Unsynced, last clique block
+				{999, ID{Hash: checksumToBytes(0x8fbc1a44), Next: 1000}},  // Last clique block
+				{1000, ID{Hash: checksumToBytes(0xd0191d3a), Next: 0}},    // First equality block
+				{2000000, ID{Hash: checksumToBytes(0xd0191d3a), Next: 0}}, // Future equality block
 			},
 		},
 	}
@@ -196,7 +205,7 @@ func TestValidation(t *testing.T) {
 		{7279999, ID{Hash: checksumToBytes(0xa00bc324), Next: 7279999}, ErrLocalIncompatibleOrStale},
 	}
 	for i, tt := range tests {
-		filter := newFilter(params.MainnetChainConfig, params.MainnetGenesisHash, func() uint64 { return tt.head })
+		filter := newFilter(ethereumChainConfig, ethereumGenesisHash, func() uint64 { return tt.head })
 		if err := filter(tt.id); err != tt.err {
 			t.Errorf("test %d: validation error mismatch: have %v, want %v", i, err, tt.err)
 		}
@@ -23,7 +23,9 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/hexutil"
@@ -44,6 +46,24 @@ import (
 
 var errGenesisNoConfig = errors.New("genesis has no chain configuration")
 
+// Layout of the extra-data of the genesis of an equality chain, the one of the
+// blocks sealed by the engine without any header extra.
+const (
+	equalityExtraVanity = 32                     // Fixed number of extra-data prefix bytes reserved for signer vanity
+	equalityExtraSeal   = crypto.SignatureLength // Fixed number of extra-data suffix bytes reserved for signer seal
+)
+
+// EqualityExtraData returns the extra-data of the genesis of an equality chain,
+// the vanity padded with zeros followed by the room of an empty seal.
+func EqualityExtraData(vanity []byte) ([]byte, error) {
+	if len(vanity) > equalityExtraVanity {
+		return nil, fmt.Errorf("vanity of %d bytes exceeds %d", len(vanity), equalityExtraVanity)
+	}
+	extra := make([]byte, equalityExtraVanity+equalityExtraSeal)
+	copy(extra, vanity)
+	return extra, nil
+}
+
 // Genesis specifies the header fields, state of a genesis block. It also defines hard
 // fork switch-over blocks through the chain configuration.
 type Genesis struct {
@@ -85,6 +105,7 @@ type GenesisAccount struct {
 	Storage    map[common.Hash]common.Hash `json:"storage,omitempty"`
 	Balance    *big.Int                    `json:"balance" gencodec:"required"`
 	Nonce      uint64                      `json:"nonce,omitempty"`
+	Vesting    *GenesisVesting             `json:"vesting,omitempty"`   // Schedule the balance is released on, nil if spendable at once
 	PrivateKey []byte                      `json:"secretKey,omitempty"` // for tests
 }
 
@@ -153,9 +174,20 @@ func (e *GenesisMismatchError) Error() string {
 //
 // The returned chain configuration is never nil.
 func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig, common.Hash, error) {
+	return SetupGenesisBlockWithOverride(db, genesis, nil)
+}
+
+// SetupGenesisBlockWithOverride works like SetupGenesisBlock, additionally
+// scheduling the given change of the equality parameters into the chain config.
+func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, overrideEquality *params.EqualityOverride) (*params.ChainConfig, common.Hash, error) {
 	if genesis != nil && genesis.Config == nil {
 		return params.AllEthashProtocolChanges, common.Hash{}, errGenesisNoConfig
 	}
+	if genesis != nil {
+		if err := genesis.validate(); err != nil {
+			return genesis.Config, common.Hash{}, err
+		}
+	}
 	// Just commit the new block if there is no stored genesis block.
 	stored := rawdb.ReadCanonicalHash(db, 0)
 	if (stored == common.Hash{}) {
@@ -165,6 +197,11 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 		} else {
 			log.Info("Writing custom genesis block")
 		}
+		if overrideEquality != nil {
+			overridden := *genesis
+			overridden.Config = withEqualityOverrides(genesis.Config, nil, overrideEquality)
+			genesis = &overridden
+		}
 		block, err := genesis.Commit(db)
 		if err != nil {
 			return genesis.Config, common.Hash{}, err
@@ -207,6 +244,7 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	storedcfg := rawdb.ReadChainConfig(db, stored)
 	if storedcfg == nil {
 		log.Warn("Found genesis block without chain config")
+		newcfg = withEqualityOverrides(newcfg, nil, overrideEquality)
 		rawdb.WriteChainConfig(db, stored, newcfg)
 		return newcfg, stored, nil
 	}
@@ -214,8 +252,13 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	// config is supplied. These chains would get AllProtocolChanges (and a compat error)
 	// if we just continued here.
 	if genesis == nil && stored != params.MainnetGenesisHash {
-		return storedcfg, stored, nil
+		if overrideEquality == nil {
+			return storedcfg, stored, nil
+		}
+		newcfg = storedcfg
 	}
+	// Overrides scheduled on earlier runs are kept, even if the genesis lacks them
+	newcfg = withEqualityOverrides(newcfg, storedcfg, overrideEquality)
 
 	// Check config compatibility and write the config. Compatibility errors
 	// are returned to the caller unless we're already at block zero.
@@ -223,14 +266,135 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	if height == nil {
 		return newcfg, stored, fmt.Errorf("missing block number for head header hash")
 	}
+	// Rewinding to the genesis is only skipped for forks active since the genesis,
+	// changes to blocks minted since have to go through the rewind.
 	compatErr := storedcfg.CheckCompatible(newcfg, *height)
-	if compatErr != nil && *height != 0 && compatErr.RewindTo != 0 {
+	if compatErr != nil && *height != 0 && (compatErr.RewindTo != 0 || compatErr.StoredConfig != nil && compatErr.StoredConfig.Sign() > 0) {
 		return newcfg, stored, compatErr
 	}
 	rawdb.WriteChainConfig(db, stored, newcfg)
 	return newcfg, stored, nil
 }
 
+// withEqualityOverrides returns a copy of the chain config with the equality
+// overrides of the stored config and the one given at startup merged into its
+// own. The startup override replaces any other one at the same block. The forks
+// scheduled by the validators into the stored config are kept as well.
+func withEqualityOverrides(config, stored *params.ChainConfig, override *params.EqualityOverride) *params.ChainConfig {
+	if config.Equality == nil {
+		return config
+	}
+	var (
+		extra []params.EqualityOverride
+		forks []params.EqualityFork
+	)
+	if stored != nil && stored.Equality != nil {
+		extra = append(extra, stored.Equality.Overrides...)
+		forks = append(forks, stored.Equality.Forks...)
+	}
+	if len(extra) == 0 && len(forks) == 0 && override == nil {
+		return config
+	}
+	equality := *config.Equality
+	equality.Overrides = append([]params.EqualityOverride{}, equality.Overrides...)
+	for _, o := range extra {
+		if equality.OverrideAt(o.Block) == nil {
+			equality.Overrides = append(equality.Overrides, o)
+		}
+	}
+	if override != nil {
+		if existing := equality.OverrideAt(override.Block); existing != nil {
+			*existing = *override
+		} else {
+			equality.Overrides = append(equality.Overrides, *override)
+		}
+		log.Info("Overriding equality parameters", "block", override.Block, "period", override.Period, "epoch", override.Epoch)
+	}
+	sort.Slice(equality.Overrides, func(i, j int) bool {
+		return equality.Overrides[i].Block < equality.Overrides[j].Block
+	})
+	cpy := *config
+	equality.Forks = append([]params.EqualityFork{}, equality.Forks...)
+	for _, fork := range forks {
+		if err := cpy.ScheduleFork(fork.Name, fork.Block); err != nil {
+			// Left for the compatibility check to report
+			log.Warn("Failed to keep scheduled fork", "fork", fork.Name, "block", fork.Block, "err", err)
+			continue
+		}
+		if !equality.HasFork(fork) {
+			equality.Forks = append(equality.Forks, fork)
+		}
+	}
+	sort.SliceStable(equality.Forks, func(i, j int) bool {
+		return equality.Forks[i].Block < equality.Forks[j].Block
+	})
+	cpy.Equality = &equality
+	return &cpy
+}
+
+// validate checks the consensus engine parameters of a custom genesis, along
+// with the accounts and the time the validators start sealing from, so that a
+// broken genesis is rejected instead of stalling the chain at its first block.
+// The built-in networks predate the checks and are trusted as they are.
+func (g *Genesis) validate() error {
+	for addr, account := range g.Alloc {
+		if account.Vesting == nil {
+			continue
+		}
+		if addr == params.VestingContractAddress {
+			return fmt.Errorf("invalid genesis: vesting contract address %s allocated", addr.Hex())
+		}
+		if err := account.Vesting.validate(); err != nil {
+			return fmt.Errorf("invalid genesis: account %s: %v", addr.Hex(), err)
+		}
+	}
+	config := g.Config.Equality
+	if config == nil {
+		return nil
+	}
+	switch g.ToBlock(nil).Hash() {
+	case params.MainnetGenesisHash, params.TestnetGenesisHash:
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	// The genesis of chains sealed by the engine from the start is laid out like its blocks
+	if g.Config.EqualityBlock == nil || g.Config.EqualityBlock.Sign() == 0 {
+		if len(g.ExtraData) != equalityExtraVanity+equalityExtraSeal {
+			return fmt.Errorf("invalid equality genesis: extraData of %d bytes, want %d bytes of vanity and %d of seal (see core.EqualityExtraData)",
+				len(g.ExtraData), equalityExtraVanity, equalityExtraSeal)
+		}
+	}
+	// The slots of the validators are counted from the genesis timestamp
+	if config.GenesisTimestamp < config.Period {
+		return fmt.Errorf("invalid equality genesis: genesisTimestamp %d must be set to the time the first block is due", config.GenesisTimestamp)
+	}
+	if config.GenesisTimestamp < g.Timestamp {
+		return fmt.Errorf("invalid equality genesis: genesisTimestamp %d before the genesis block timestamp %d", config.GenesisTimestamp, g.Timestamp)
+	}
+	// The genesis validators are held to the balance of the other candidates
+	for _, validator := range config.Validators {
+		balance := new(big.Int)
+		if account, ok := g.Alloc[validator]; ok && account.Balance != nil {
+			balance = account.Balance
+		}
+		if balance.Cmp(config.MinCandidateBalance) < 0 {
+			return fmt.Errorf("invalid equality genesis: validator %s funded with %v, below the minCandidateBalance %v, fund it in the alloc",
+				validator.Hex(), balance, config.MinCandidateBalance)
+		}
+	}
+	// The engine only maintains the storage of the system contracts, their code
+	// is deployed by the alloc unless built in
+	for _, contract := range config.SystemContracts {
+		address, _ := config.SystemContract(contract.Name)
+		if _, ok := systemContractCode[contract.Name]; !ok && len(g.Alloc[address].Code) == 0 {
+			return fmt.Errorf("invalid equality genesis: %s system contract at %s has no code in the alloc", contract.Name, address.Hex())
+		}
+	}
+	return nil
+}
+
 func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 	switch {
 	case g != nil:
@@ -252,13 +416,20 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	}
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
 	for addr, account := range g.Alloc {
-		statedb.AddBalance(addr, account.Balance)
+		if account.Vesting != nil {
+			allocVesting(statedb, addr, account)
+		} else {
+			statedb.AddBalance(addr, account.Balance)
+		}
 		statedb.SetCode(addr, account.Code)
 		statedb.SetNonce(addr, account.Nonce)
 		for key, value := range account.Storage {
 			statedb.SetState(addr, key, value)
 		}
 	}
+	if g.Config != nil && g.Config.Equality != nil {
+		deploySystemContracts(statedb, g.Config.Equality)
+	}
 	root := statedb.IntermediateRoot(false)
 	head := &types.Header{
 		Number:     new(big.Int).SetUint64(g.Number),
@@ -279,6 +450,9 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	if g.Difficulty == nil {
 		head.Difficulty = params.GenesisDifficulty
 	}
+	if g.Config != nil && g.Config.IsLondon(common.Big0) {
+		head.BaseFee = new(big.Int).SetUint64(params.InitialBaseFee)
+	}
 	statedb.Commit(false)
 	statedb.Database().TrieDB().Commit(root, true, nil)
 
@@ -352,6 +526,30 @@ func DefaultTestnetGenesisBlock() *Genesis {
 	}
 }
 
+// DefaultSecretMainnetGenesisBlock returns the complete genesis of the Secret
+// main network, alloc and equality config included, like DefaultGenesisBlock.
+// The chain config is a copy, free to be modified by embedders and tests
+// without affecting the one of the network.
+func DefaultSecretMainnetGenesisBlock() *Genesis {
+	return detachGenesisConfig(DefaultGenesisBlock())
+}
+
+// DefaultSecretTestnetGenesisBlock returns the complete genesis of the Secret
+// test network, like DefaultSecretMainnetGenesisBlock.
+func DefaultSecretTestnetGenesisBlock() *Genesis {
+	return detachGenesisConfig(DefaultTestnetGenesisBlock())
+}
+
+// detachGenesisConfig replaces the chain config of the genesis with a copy.
+func detachGenesisConfig(g *Genesis) *Genesis {
+	config := *g.Config
+	if config.Equality != nil {
+		config.Equality = config.Equality.Copy()
+	}
+	g.Config = &config
+	return g
+}
+
 // DeveloperGenesisBlock returns the 'geth --dev' genesis block.
 func DeveloperGenesisBlock(period uint64, faucet common.Address) *Genesis {
 	// Override the default period to the user requested one
@@ -364,18 +562,56 @@ func DeveloperGenesisBlock(period uint64, faucet common.Address) *Genesis {
 		ExtraData:  append(append(make([]byte, 32), faucet[:]...), make([]byte, crypto.SignatureLength)...),
 		GasLimit:   11500000,
 		Difficulty: big.NewInt(1),
-		Alloc: map[common.Address]GenesisAccount{
-			common.BytesToAddress([]byte{1}): {Balance: big.NewInt(1)}, // ECRecover
-			common.BytesToAddress([]byte{2}): {Balance: big.NewInt(1)}, // SHA256
-			common.BytesToAddress([]byte{3}): {Balance: big.NewInt(1)}, // RIPEMD
-			common.BytesToAddress([]byte{4}): {Balance: big.NewInt(1)}, // Identity
-			common.BytesToAddress([]byte{5}): {Balance: big.NewInt(1)}, // ModExp
-			common.BytesToAddress([]byte{6}): {Balance: big.NewInt(1)}, // ECAdd
-			common.BytesToAddress([]byte{7}): {Balance: big.NewInt(1)}, // ECScalarMul
-			common.BytesToAddress([]byte{8}): {Balance: big.NewInt(1)}, // ECPairing
-			common.BytesToAddress([]byte{9}): {Balance: big.NewInt(1)}, // BLAKE2b
-			faucet:                           {Balance: new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(9))},
-		},
+		Alloc:      developerAlloc(faucet),
+	}
+}
+
+// developerAlloc returns the alloc of the developer chains, the precompiles and
+// the faucet pre-funded.
+func developerAlloc(faucet common.Address) GenesisAlloc {
+	return GenesisAlloc{
+		common.BytesToAddress([]byte{1}): {Balance: big.NewInt(1)}, // ECRecover
+		common.BytesToAddress([]byte{2}): {Balance: big.NewInt(1)}, // SHA256
+		common.BytesToAddress([]byte{3}): {Balance: big.NewInt(1)}, // RIPEMD
+		common.BytesToAddress([]byte{4}): {Balance: big.NewInt(1)}, // Identity
+		common.BytesToAddress([]byte{5}): {Balance: big.NewInt(1)}, // ModExp
+		common.BytesToAddress([]byte{6}): {Balance: big.NewInt(1)}, // ECAdd
+		common.BytesToAddress([]byte{7}): {Balance: big.NewInt(1)}, // ECScalarMul
+		common.BytesToAddress([]byte{8}): {Balance: big.NewInt(1)}, // ECPairing
+		common.BytesToAddress([]byte{9}): {Balance: big.NewInt(1)}, // BLAKE2b
+		faucet:                           {Balance: new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(9))},
+	}
+}
+
+// DeveloperEqualityGenesisBlock returns the 'secret --dev --dev.equality' genesis
+// block, a chain sealed by the equality engine with the developer as its single
+// validator. The engine has no seal-on-demand, blocks are sealed at least every
+// second.
+func DeveloperEqualityGenesisBlock(period uint64, developer common.Address) *Genesis {
+	if period == 0 {
+		period = 1
+	}
+	config := *params.AllCliqueProtocolChanges
+	config.Clique = nil
+	config.Equality = &params.EqualityConfig{
+		Period:              period,
+		Epoch:               100,
+		MaxValidatorsCount:  1,
+		MinCandidateBalance: big.NewInt(params.Ether),
+		Validators:          []common.Address{developer},
+	}
+	// The slots of the validator are counted from the first block due
+	timestamp := uint64(time.Now().Unix())
+	config.Equality.GenesisTimestamp = timestamp + period
+
+	extra, _ := EqualityExtraData(nil)
+	return &Genesis{
+		Config:     &config,
+		Timestamp:  timestamp,
+		ExtraData:  extra,
+		GasLimit:   11500000,
+		Difficulty: big.NewInt(1),
+		Alloc:      developerAlloc(developer),
 	}
 }
 
@@ -18,7 +18,10 @@ package core
 
 // Constants containing the genesis allocation of built-in genesis blocks.
 // Their content is an RLP-encoded list of (address, balance) tuples.
-// Use mkalloc.go to create/update them.
+// Use mkalloc.go to create/update them from the holder lists in allocs.
+
+//go:generate go run mkalloc.go -name mainnetAllocData -update genesis_alloc.go allocs/mainnet.csv
+//go:generate go run mkalloc.go -name testnetAllocData -update genesis_alloc.go allocs/testnet.csv
 
 // nolint: misspell
 const mainnetAllocData = "\xe2\xe1\x94S\xd7x'\xbe\x16\x8a\xb2\xa9\x11\xb5\xa1M\x0f\x16\xd1\xc5eq\x96\x8b\bE\x95\x16\x14\x01H\x80\x00\x00\x00"
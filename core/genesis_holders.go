@@ -0,0 +1,177 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/common/math"
+)
+
+// GenesisHolder is an account of a holder list funded at genesis, the entries
+// of the JSON holder lists.
+type GenesisHolder struct {
+	Address common.Address              `json:"address"`
+	Balance *math.HexOrDecimal256       `json:"balance"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+	Vesting *GenesisVesting             `json:"vesting,omitempty"`
+}
+
+// LoadGenesisAlloc reads the accounts to fund at genesis from a holder list, a
+// JSON or, if the file extension says so, a CSV file.
+func LoadGenesisAlloc(path string) (GenesisAlloc, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var alloc GenesisAlloc
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		alloc, err = ReadGenesisAllocCSV(file)
+	} else {
+		alloc, err = ReadGenesisAllocJSON(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid holder list %s: %v", path, err)
+	}
+	return alloc, nil
+}
+
+// ReadGenesisAllocJSON reads a JSON array of genesis holders.
+func ReadGenesisAllocJSON(r io.Reader) (GenesisAlloc, error) {
+	var holders []GenesisHolder
+	if err := json.NewDecoder(r).Decode(&holders); err != nil {
+		return nil, err
+	}
+	alloc := make(GenesisAlloc, len(holders))
+	for idx, holder := range holders {
+		if holder.Balance == nil {
+			return nil, fmt.Errorf("holder #%d %s: balance missing", idx, holder.Address.Hex())
+		}
+		if err := addGenesisHolder(alloc, holder.Address, GenesisAccount{
+			Balance: (*big.Int)(holder.Balance),
+			Code:    holder.Code,
+			Storage: holder.Storage,
+			Vesting: holder.Vesting,
+		}); err != nil {
+			return nil, fmt.Errorf("holder #%d: %v", idx, err)
+		}
+	}
+	return alloc, nil
+}
+
+// ReadGenesisAllocCSV reads a CSV list of genesis holders, one per line with
+// the columns address, balance and optionally code and storage, the storage
+// being a space separated list of slot=value pairs. Balances are decimal or
+// hex. A header line naming the columns and lines starting with # are skipped.
+func ReadGenesisAllocCSV(r io.Reader) (GenesisAlloc, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	alloc := make(GenesisAlloc)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return alloc, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		idx := len(alloc)
+		if idx == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+		if len(record) < 2 || len(record) > 4 {
+			return nil, fmt.Errorf("holder #%d: want address, balance, code and storage columns, have %d", idx, len(record))
+		}
+		address, account, err := parseGenesisHolder(record)
+		if err != nil {
+			return nil, fmt.Errorf("holder #%d: %v", idx, err)
+		}
+		if err := addGenesisHolder(alloc, address, account); err != nil {
+			return nil, fmt.Errorf("holder #%d: %v", idx, err)
+		}
+	}
+}
+
+// parseGenesisHolder parses the columns of a CSV holder line.
+func parseGenesisHolder(record []string) (common.Address, GenesisAccount, error) {
+	addr := strings.TrimSpace(record[0])
+	if !common.IsHexAddress(addr) {
+		return common.Address{}, GenesisAccount{}, fmt.Errorf("invalid address %q", addr)
+	}
+	balance, ok := math.ParseBig256(strings.TrimSpace(record[1]))
+	if !ok || strings.TrimSpace(record[1]) == "" {
+		return common.Address{}, GenesisAccount{}, fmt.Errorf("invalid balance %q", record[1])
+	}
+	account := GenesisAccount{Balance: balance}
+	if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+		code, err := hexutil.Decode(strings.TrimSpace(record[2]))
+		if err != nil {
+			return common.Address{}, GenesisAccount{}, fmt.Errorf("invalid code: %v", err)
+		}
+		account.Code = code
+	}
+	if len(record) > 3 {
+		for _, entry := range strings.Fields(record[3]) {
+			parts := strings.SplitN(entry, "=", 2)
+			if len(parts) != 2 {
+				return common.Address{}, GenesisAccount{}, fmt.Errorf("invalid storage entry %q, want slot=value", entry)
+			}
+			slot, err := hexutil.Decode(parts[0])
+			if err != nil || len(slot) > common.HashLength {
+				return common.Address{}, GenesisAccount{}, fmt.Errorf("invalid storage slot %q", parts[0])
+			}
+			value, err := hexutil.Decode(parts[1])
+			if err != nil || len(value) > common.HashLength {
+				return common.Address{}, GenesisAccount{}, fmt.Errorf("invalid storage value %q", parts[1])
+			}
+			if account.Storage == nil {
+				account.Storage = make(map[common.Hash]common.Hash)
+			}
+			account.Storage[common.BytesToHash(slot)] = common.BytesToHash(value)
+		}
+	}
+	return common.HexToAddress(addr), account, nil
+}
+
+// addGenesisHolder adds a holder to the allocation, rejecting the holders listed
+// twice rather than silently keeping one of their balances.
+func addGenesisHolder(alloc GenesisAlloc, address common.Address, account GenesisAccount) error {
+	if _, ok := alloc[address]; ok {
+		return fmt.Errorf("holder %s listed twice", address.Hex())
+	}
+	if account.Balance.Sign() < 0 {
+		return errors.New("negative balance")
+	}
+	alloc[address] = account
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+)
+
+func TestReadGenesisAlloc(t *testing.T) {
+	want := GenesisAlloc{
+		common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c"): {Balance: big.NewInt(100)},
+		common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c"): {
+			Balance: big.NewInt(255),
+			Code:    []byte{0x60, 0x00},
+			Storage: map[common.Hash]common.Hash{{31: 0x01}: {31: 0x02}},
+		},
+	}
+	csv := `# Holders
+address,balance,code,storage
+0xcc7c8317b21e1cea6139700c3c46c21af998d14c,100
+0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c,0xff,0x6000,0x01=0x02
+`
+	alloc, err := ReadGenesisAllocCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("failed to read CSV holders: %v", err)
+	}
+	if !reflect.DeepEqual(alloc, want) {
+		t.Errorf("CSV holders mismatch: have %v, want %v", alloc, want)
+	}
+	json := `[
+  {"address": "0xcc7c8317b21e1cea6139700c3c46c21af998d14c", "balance": "100"},
+  {"address": "0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c", "balance": "0xff", "code": "0x6000",
+   "storage": {"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002"}}
+]`
+	alloc, err = ReadGenesisAllocJSON(strings.NewReader(json))
+	if err != nil {
+		t.Fatalf("failed to read JSON holders: %v", err)
+	}
+	if !reflect.DeepEqual(alloc, want) {
+		t.Errorf("JSON holders mismatch: have %v, want %v", alloc, want)
+	}
+
+	// Broken and duplicate holders are rejected
+	for i, csv := range []string{
+		"0xcc7c8317b21e1cea6139700c3c46c21af998d14c\n",
+		"0xcc7c8317b21e1cea6139700c3c46c21af998d14c,\n",
+		"0xcc7c,100\n",
+		"0xcc7c8317b21e1cea6139700c3c46c21af998d14c,-1\n",
+		"0xcc7c8317b21e1cea6139700c3c46c21af998d14c,1,0x,0x01\n",
+		"0xcc7c8317b21e1cea6139700c3c46c21af998d14c,1\n0xcc7c8317b21e1cea6139700c3c46c21af998d14c,2\n",
+	} {
+		if _, err := ReadGenesisAllocCSV(strings.NewReader(csv)); err == nil {
+			t.Errorf("test %d: invalid CSV holders accepted", i)
+		}
+	}
+	if _, err := ReadGenesisAllocJSON(strings.NewReader(`[{"address": "0xcc7c8317b21e1cea6139700c3c46c21af998d14c"}]`)); err == nil {
+		t.Error("JSON holder without balance accepted")
+	}
+}
+
+func TestBuiltinGenesisAlloc(t *testing.T) {
+	for name, data := range map[string]string{"mainnet": mainnetAllocData, "testnet": testnetAllocData} {
+		alloc, err := LoadGenesisAlloc("allocs/" + name + ".csv")
+		if err != nil {
+			t.Fatalf("failed to load %s holders: %v", name, err)
+		}
+		if have := decodePrealloc(data); !reflect.DeepEqual(have, alloc) {
+			t.Errorf("%s alloc out of date with its holder list, run go generate", name)
+		}
+	}
+}
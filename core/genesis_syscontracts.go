@@ -0,0 +1,174 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/core/asm"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// stakingSource is the code of the built-in staking system contract, a read-only
+// view of the storage the equality engine writes at each epoch, laid out like the
+// state variables
+//
+//	uint256 epoch; address[] validators; mapping(address => uint256) deposits; address[] candidates;
+//
+// and read through the getters epoch(), validators(), deposits(address) and
+// candidates(). Calls with value or to any other function revert.
+const stakingSource = `
+	CALLVALUE
+	JUMPI @fail
+	PUSH 0
+	CALLDATALOAD
+	PUSH 224
+	SHR
+	;; epoch()
+	DUP1
+	PUSH 2416767183
+	EQ
+	JUMPI @epoch
+	;; validators()
+	DUP1
+	PUSH 3390994457
+	EQ
+	JUMPI @validators
+	;; deposits(address)
+	DUP1
+	PUSH 4236126317
+	EQ
+	JUMPI @deposits
+	;; candidates()
+	DUP1
+	PUSH 1862561962
+	EQ
+	JUMPI @candidates
+fail:
+	PUSH 0
+	PUSH 0
+	REVERT
+epoch:
+	PUSH 0
+	SLOAD
+	PUSH 0
+	MSTORE
+	PUSH 32
+	PUSH 0
+	RETURN
+deposits:
+	PUSH 4
+	CALLDATALOAD
+	PUSH 96
+	SHL
+	PUSH 96
+	SHR
+	PUSH 0
+	MSTORE
+	PUSH 2
+	PUSH 32
+	MSTORE
+	PUSH 64
+	PUSH 0
+	SHA3
+	SLOAD
+	PUSH 0
+	MSTORE
+	PUSH 32
+	PUSH 0
+	RETURN
+validators:
+	PUSH 1
+	JUMP @array
+candidates:
+	PUSH 3
+	JUMP @array
+array:
+	DUP1
+	PUSH 0
+	MSTORE
+	PUSH 32
+	PUSH 0
+	SHA3
+	SWAP1
+	SLOAD
+	DUP1
+	PUSH 32
+	MSTORE
+	PUSH 32
+	PUSH 0
+	MSTORE
+	PUSH 0
+loop:
+	DUP2
+	DUP2
+	LT
+	ISZERO
+	JUMPI @done
+	DUP1
+	DUP4
+	ADD
+	SLOAD
+	DUP2
+	PUSH 32
+	MUL
+	PUSH 64
+	ADD
+	MSTORE
+	PUSH 1
+	ADD
+	JUMP @loop
+done:
+	POP
+	PUSH 32
+	MUL
+	PUSH 64
+	ADD
+	PUSH 0
+	RETURN
+`
+
+// systemContractCode is the code of the system contracts the genesis deploys
+// unless the alloc provides its own, by kind.
+var systemContractCode = map[string][]byte{
+	"staking": compileContract("staking", stakingSource),
+}
+
+// compileContract compiles the assembly of a built-in contract.
+func compileContract(name string, source string) []byte {
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex([]byte(source), false))
+	code, errs := compiler.Compile()
+	if len(errs) > 0 {
+		panic(fmt.Sprintf("invalid %s contract: %v", name, errs))
+	}
+	return hexutil.MustDecode("0x" + strings.TrimSpace(code))
+}
+
+// deploySystemContracts deploys the built-in code of the system contracts the
+// alloc left without code.
+func deploySystemContracts(statedb *state.StateDB, config *params.EqualityConfig) {
+	for _, contract := range config.SystemContracts {
+		address, _ := config.SystemContract(contract.Name)
+		if code, ok := systemContractCode[contract.Name]; ok && statedb.GetCodeSize(address) == 0 {
+			statedb.SetCode(address, code)
+		}
+	}
+}
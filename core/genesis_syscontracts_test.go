@@ -0,0 +1,105 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+func TestStakingContract(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := testEqualityGenesis()
+	genesis.Config.Equality.SystemContracts = []params.EqualitySystemContract{{Name: "staking"}}
+	if err := genesis.validate(); err != nil {
+		t.Fatalf("genesis without staking code rejected: %v", err)
+	}
+	block := genesis.MustCommit(db)
+	statedb, _ := state.New(block.Root(), state.NewDatabase(db), nil)
+	staking := params.SystemContractAddresses["staking"]
+	if !bytes.Equal(statedb.GetCode(staking), systemContractCode["staking"]) {
+		t.Fatal("built-in staking contract not deployed")
+	}
+
+	// Lay out the storage like the engine does at an epoch block
+	word := func(v int64) common.Hash { return common.BigToHash(big.NewInt(v)) }
+	element := func(slot int64, i int64) common.Hash {
+		base := new(big.Int).SetBytes(crypto.Keccak256(word(slot).Bytes()))
+		return common.BigToHash(base.Add(base, big.NewInt(i)))
+	}
+	validator, candidate := common.Address{0x0a}, common.Address{0x0b}
+	statedb.SetState(staking, word(0), word(7))
+	statedb.SetState(staking, word(1), word(2))
+	statedb.SetState(staking, element(1, 0), common.BytesToHash(validator.Bytes()))
+	statedb.SetState(staking, element(1, 1), common.BytesToHash(candidate.Bytes()))
+	statedb.SetState(staking, crypto.Keccak256Hash(common.LeftPadBytes(candidate.Bytes(), 32), word(2).Bytes()), word(5))
+	statedb.SetState(staking, word(3), word(1))
+	statedb.SetState(staking, element(3, 0), common.BytesToHash(candidate.Bytes()))
+
+	call := func(input []byte, value int64) ([]byte, error) {
+		evm := vm.NewEVM(vm.Context{
+			CanTransfer: CanTransfer,
+			Transfer:    Transfer,
+			BlockNumber: big.NewInt(1),
+			Time:        big.NewInt(1),
+			Difficulty:  big.NewInt(1),
+		}, statedb, params.AllEthashProtocolChanges, vm.Config{})
+		output, _, err := evm.Call(vm.AccountRef(common.Address{0x01}), staking, input, 100000, big.NewInt(value))
+		return output, err
+	}
+	selector := func(signature string) []byte { return crypto.Keccak256([]byte(signature))[:4] }
+	encode := func(words ...common.Hash) []byte {
+		var out []byte
+		for _, w := range words {
+			out = append(out, w.Bytes()...)
+		}
+		return out
+	}
+	tests := []struct {
+		input  []byte
+		output []byte
+	}{
+		{selector("epoch()"), encode(word(7))},
+		{selector("validators()"), encode(word(32), word(2), common.BytesToHash(validator.Bytes()), common.BytesToHash(candidate.Bytes()))},
+		{selector("candidates()"), encode(word(32), word(1), common.BytesToHash(candidate.Bytes()))},
+		{append(selector("deposits(address)"), common.LeftPadBytes(candidate.Bytes(), 32)...), encode(word(5))},
+		{append(selector("deposits(address)"), common.LeftPadBytes(validator.Bytes(), 32)...), encode(word(0))},
+	}
+	for i, test := range tests {
+		output, err := call(test.input, 0)
+		if err != nil {
+			t.Errorf("test %d: call failed: %v", i, err)
+		} else if !bytes.Equal(output, test.output) {
+			t.Errorf("test %d: output mismatch: have %x, want %x", i, output, test.output)
+		}
+	}
+	// The contract is read-only
+	if _, err := call(selector("epoch()"), 1); err == nil {
+		t.Error("call with value accepted")
+	}
+	if _, err := call(selector("stake()"), 0); err == nil {
+		t.Error("unknown function accepted")
+	}
+}
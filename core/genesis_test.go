@@ -24,7 +24,9 @@ import (
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/consensus/ethash"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/crypto"
 	"github.com/SecretBlockChain/go-secret/ethdb"
 	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/davecgh/go-spew/spew"
@@ -41,6 +43,212 @@ func TestDefaultGenesisBlock(t *testing.T) {
 	}
 }
 
+// testEqualityGenesis returns a custom equality genesis passing the checks of
+// SetupGenesisBlock.
+func testEqualityGenesis() *Genesis {
+	config := *params.TestnetChainConfig
+	config.Equality = &params.EqualityConfig{
+		Period:              3,
+		Epoch:               100,
+		MaxValidatorsCount:  21,
+		MinCandidateBalance: big.NewInt(1),
+		GenesisTimestamp:    1609459200,
+		Validators:          []common.Address{{0x01}},
+		Pool:                common.Address{0x02},
+	}
+	return &Genesis{
+		Config:    &config,
+		Timestamp: 1609459200,
+		ExtraData: make([]byte, 32+crypto.SignatureLength),
+		Alloc:     GenesisAlloc{{0x01}: {Balance: big.NewInt(1)}},
+	}
+}
+
+func TestDefaultSecretGenesisBlock(t *testing.T) {
+	for _, test := range []struct {
+		genesis *Genesis
+		hash    common.Hash
+		config  *params.ChainConfig
+	}{
+		{DefaultSecretMainnetGenesisBlock(), params.MainnetGenesisHash, params.MainnetChainConfig},
+		{DefaultSecretTestnetGenesisBlock(), params.TestnetGenesisHash, params.TestnetChainConfig},
+	} {
+		if hash := test.genesis.ToBlock(nil).Hash(); hash != test.hash {
+			t.Errorf("genesis hash mismatch: have %x, want %x", hash, test.hash)
+		}
+		if len(test.genesis.ExtraData) != 32+crypto.SignatureLength || len(test.genesis.Alloc) == 0 {
+			t.Errorf("incomplete genesis: extra %d bytes, %d accounts", len(test.genesis.ExtraData), len(test.genesis.Alloc))
+		}
+		if !test.genesis.Config.Equality.Equal(*test.config.Equality) {
+			t.Errorf("equality config mismatch")
+		}
+		// Changing the copy leaves the network untouched
+		test.genesis.Config.ChainID = big.NewInt(1337)
+		test.genesis.Config.Equality.Validators[0] = common.Address{}
+		test.genesis.Config.Equality.MinCandidateBalance.SetUint64(0)
+		if test.config.ChainID.Uint64() == 1337 || test.config.Equality.Validators[0] == (common.Address{}) || test.config.Equality.MinCandidateBalance.Sign() == 0 {
+			t.Errorf("genesis config shared with the network")
+		}
+	}
+}
+
+func TestDeveloperEqualityGenesisBlock(t *testing.T) {
+	developer := common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+	genesis := DeveloperEqualityGenesisBlock(0, developer)
+	if err := genesis.validate(); err != nil {
+		t.Fatalf("developer genesis rejected: %v", err)
+	}
+	config := genesis.Config.Equality
+	if config.Period != 1 || len(config.Validators) != 1 || config.Validators[0] != developer {
+		t.Errorf("wrong developer config: period %d, validators %v", config.Period, config.Validators)
+	}
+	if genesis.Config.Clique != nil || params.AllCliqueProtocolChanges.Clique.Period != 0 {
+		t.Error("developer genesis sealed by clique")
+	}
+	block := genesis.MustCommit(rawdb.NewMemoryDatabase())
+	if block.Time() >= config.GenesisTimestamp {
+		t.Errorf("first slot %d not after the genesis block at %d", config.GenesisTimestamp, block.Time())
+	}
+}
+
+func TestEqualityExtraData(t *testing.T) {
+	extra, err := EqualityExtraData([]byte("secret"))
+	if err != nil {
+		t.Fatalf("failed to build extra-data: %v", err)
+	}
+	if len(extra) != 32+crypto.SignatureLength || string(extra[:6]) != "secret" {
+		t.Errorf("extra-data mismatch: %x", extra)
+	}
+	if _, err := EqualityExtraData(make([]byte, 33)); err == nil {
+		t.Error("oversized vanity accepted")
+	}
+}
+
+func TestSetupGenesisInvalidEquality(t *testing.T) {
+	if _, _, err := SetupGenesisBlock(rawdb.NewMemoryDatabase(), testEqualityGenesis()); err != nil {
+		t.Fatalf("valid equality genesis rejected: %v", err)
+	}
+	tests := []func(g *Genesis){
+		func(g *Genesis) { g.Config.Equality.MaxValidatorsCount = 0 },
+		func(g *Genesis) { g.Config.Equality.Validators = nil },
+		func(g *Genesis) { g.Config.Equality.Validators = []common.Address{{0x01}, {0x01}} },
+		func(g *Genesis) { g.Config.Equality.GenesisTimestamp = 0 },
+		func(g *Genesis) { g.Config.Equality.GenesisTimestamp = g.Timestamp - 1 },
+		func(g *Genesis) { g.Alloc = nil },
+		func(g *Genesis) { g.Config.Equality.MinCandidateBalance = big.NewInt(2) },
+		func(g *Genesis) {
+			g.Config.Equality.SystemContracts = []params.EqualitySystemContract{{Name: "governance"}}
+		},
+		func(g *Genesis) { g.ExtraData = nil },
+		func(g *Genesis) { g.ExtraData = make([]byte, 32) },
+	}
+	for i, mutate := range tests {
+		genesis := testEqualityGenesis()
+		equality := *genesis.Config.Equality
+		genesis.Config.Equality = &equality
+		mutate(genesis)
+		if _, _, err := SetupGenesisBlock(rawdb.NewMemoryDatabase(), genesis); err == nil {
+			t.Errorf("test %d: invalid equality genesis accepted", i)
+		}
+	}
+
+	// The built-in networks are accepted as they are
+	if _, _, err := SetupGenesisBlock(rawdb.NewMemoryDatabase(), DefaultTestnetGenesisBlock()); err != nil {
+		t.Fatalf("testnet genesis rejected: %v", err)
+	}
+}
+
+func TestSetupGenesisEqualityCompat(t *testing.T) {
+	genesis := testEqualityGenesis()
+	config := *genesis.Config
+	db := rawdb.NewMemoryDatabase()
+	block := genesis.MustCommit(db)
+
+	// Pretend the chain was minted up to block 5
+	head := &types.Header{Number: big.NewInt(5), ParentHash: block.Hash()}
+	rawdb.WriteHeader(db, head)
+	rawdb.WriteHeadHeaderHash(db, head.Hash())
+
+	changed := config
+	changed.Equality = &params.EqualityConfig{}
+	*changed.Equality = *config.Equality
+	changed.Equality.Period = 5
+	_, _, err := SetupGenesisBlock(db, &Genesis{Config: &changed, Timestamp: genesis.Timestamp, ExtraData: genesis.ExtraData, Alloc: genesis.Alloc})
+	if compatErr, ok := err.(*params.ConfigCompatError); !ok || compatErr.RewindTo != 0 {
+		t.Fatalf("changed equality period accepted: %v", err)
+	}
+}
+
+func TestSetupGenesisEqualityOverride(t *testing.T) {
+	genesis := testEqualityGenesis()
+	config := *genesis.Config
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+
+	period := uint64(1)
+	override := &params.EqualityOverride{Block: 10, Period: &period}
+	stored, _, err := SetupGenesisBlockWithOverride(db, genesis, override)
+	if err != nil {
+		t.Fatalf("override rejected: %v", err)
+	}
+	if o := stored.Equality.OverrideAt(10); o == nil || *o.Period != 1 {
+		t.Fatalf("override not applied: %v", stored.Equality.Overrides)
+	}
+	if config.Equality.Overrides != nil {
+		t.Fatal("override leaked into the genesis config")
+	}
+
+	// The override is kept on restarts without it
+	stored, _, err = SetupGenesisBlock(db, genesis)
+	if err != nil {
+		t.Fatalf("restart rejected: %v", err)
+	}
+	if stored.Equality.OverrideAt(10) == nil {
+		t.Fatal("override dropped on restart")
+	}
+}
+
+func TestSetupGenesisEqualityFork(t *testing.T) {
+	genesis := testEqualityGenesis()
+	config := *genesis.Config
+	db := rawdb.NewMemoryDatabase()
+	block := genesis.MustCommit(db)
+
+	// Pretend the validators scheduled the staking contract at block 10, reached since
+	scheduled := config
+	scheduled.Equality = &params.EqualityConfig{}
+	*scheduled.Equality = *config.Equality
+	if err := scheduled.ScheduleFork("staking", 10); err != nil {
+		t.Fatalf("failed to schedule fork: %v", err)
+	}
+	scheduled.Equality.Forks = []params.EqualityFork{{Name: "staking", Block: 10}}
+	rawdb.WriteChainConfig(db, block.Hash(), &scheduled)
+
+	head := &types.Header{Number: big.NewInt(20), ParentHash: block.Hash()}
+	rawdb.WriteHeader(db, head)
+	rawdb.WriteHeadHeaderHash(db, head.Hash())
+
+	// The fork is kept on restarts with a genesis lacking it
+	stored, _, err := SetupGenesisBlock(db, genesis)
+	if err != nil {
+		t.Fatalf("restart rejected: %v", err)
+	}
+	if stored.StakingBlock == nil || stored.StakingBlock.Uint64() != 10 {
+		t.Fatalf("fork dropped on restart: %v", stored.StakingBlock)
+	}
+	if config.StakingBlock != nil || config.Equality.Forks != nil {
+		t.Fatal("fork leaked into the genesis config")
+	}
+
+	// A genesis scheduling the fork elsewhere requires a rewind
+	changed := config
+	changed.StakingBlock = big.NewInt(15)
+	_, _, err = SetupGenesisBlock(db, &Genesis{Config: &changed, Timestamp: genesis.Timestamp, ExtraData: genesis.ExtraData, Alloc: genesis.Alloc})
+	if _, ok := err.(*params.ConfigCompatError); !ok {
+		t.Fatalf("moved fork accepted: %v", err)
+	}
+}
+
 func TestSetupGenesis(t *testing.T) {
 	var (
 		customghash = common.HexToHash("0x89c99d90b79719238d2645c7642f2c9295246e80775b38cfd162b696817fbd50")
@@ -0,0 +1,166 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// GenesisVesting is the schedule the balance of a genesis account is released
+// on. The balance is held by the vesting contract generated at genesis, which
+// releases it linearly between the start and the end, nothing before the cliff.
+type GenesisVesting struct {
+	Start uint64 `json:"start"`           // Timestamp the vesting starts at
+	Cliff uint64 `json:"cliff,omitempty"` // Timestamp nothing is released before, zero for none
+	End   uint64 `json:"end"`             // Timestamp the whole balance is released at
+}
+
+// vestingSource is the code of the vesting contract. Any transaction of the
+// beneficiary to the contract releases the balance vested so far, the schedule
+// being kept at keccak(beneficiary . 0) like a Solidity mapping of structs
+// {amount, released, start, cliff, end} at slot 0.
+const vestingSource = `
+	CALLVALUE
+	JUMPI @fail
+	CALLER
+	PUSH 0
+	MSTORE
+	PUSH 0
+	PUSH 32
+	MSTORE
+	PUSH 64
+	PUSH 0
+	SHA3
+	DUP1
+	PUSH 3
+	ADD
+	SLOAD
+	TIMESTAMP
+	LT
+	JUMPI @fail
+	DUP1
+	PUSH 4
+	ADD
+	SLOAD
+	DUP1
+	TIMESTAMP
+	LT
+	JUMPI @linear
+	POP
+	DUP1
+	SLOAD
+	JUMP @release
+linear:
+	DUP2
+	PUSH 2
+	ADD
+	SLOAD
+	SWAP1
+	DUP2
+	SWAP1
+	SUB
+	SWAP1
+	TIMESTAMP
+	SUB
+	DUP3
+	SLOAD
+	MUL
+	DIV
+release:
+	DUP2
+	PUSH 1
+	ADD
+	SLOAD
+	SWAP1
+	SUB
+	DUP1
+	ISZERO
+	JUMPI @fail
+	DUP1
+	DUP3
+	PUSH 1
+	ADD
+	SLOAD
+	ADD
+	DUP3
+	PUSH 1
+	ADD
+	SSTORE
+	PUSH 0
+	PUSH 0
+	PUSH 0
+	PUSH 0
+	DUP5
+	CALLER
+	GAS
+	CALL
+	ISZERO
+	JUMPI @fail
+	STOP
+fail:
+	PUSH 0
+	PUSH 0
+	REVERT
+`
+
+// vestingCode is the compiled code of the vesting contract.
+var vestingCode = compileContract("vesting", vestingSource)
+
+// vestingSlot returns the storage slot of the given field of the schedule of the
+// beneficiary in the vesting contract.
+func vestingSlot(beneficiary common.Address, field int64) common.Hash {
+	base := new(big.Int).SetBytes(crypto.Keccak256(common.LeftPadBytes(beneficiary.Bytes(), 32), make([]byte, 32)))
+	return common.BigToHash(base.Add(base, big.NewInt(field)))
+}
+
+// validate checks the schedule can release the balance.
+func (v *GenesisVesting) validate() error {
+	if v.End <= v.Start {
+		return fmt.Errorf("vesting ends at %d, not after its start at %d", v.End, v.Start)
+	}
+	if v.Cliff != 0 && (v.Cliff < v.Start || v.Cliff > v.End) {
+		return fmt.Errorf("vesting cliff at %d out of the schedule from %d to %d", v.Cliff, v.Start, v.End)
+	}
+	return nil
+}
+
+// allocVesting moves the balance of the account to the vesting contract along
+// with its schedule.
+func allocVesting(statedb *state.StateDB, beneficiary common.Address, account GenesisAccount) {
+	contract := params.VestingContractAddress
+	if statedb.GetCodeSize(contract) == 0 {
+		statedb.SetCode(contract, vestingCode)
+	}
+	statedb.AddBalance(contract, account.Balance)
+
+	schedule := []*big.Int{
+		account.Balance,
+		new(big.Int), // Released so far
+		new(big.Int).SetUint64(account.Vesting.Start),
+		new(big.Int).SetUint64(account.Vesting.Cliff),
+		new(big.Int).SetUint64(account.Vesting.End),
+	}
+	for field, value := range schedule {
+		statedb.SetState(contract, vestingSlot(beneficiary, int64(field)), common.BigToHash(value))
+	}
+}
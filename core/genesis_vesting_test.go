@@ -0,0 +1,101 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+func TestGenesisVesting(t *testing.T) {
+	var (
+		beneficiary = common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+		stranger    = common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+		db          = rawdb.NewMemoryDatabase()
+	)
+	genesis := &Genesis{
+		Config: params.AllEthashProtocolChanges,
+		Alloc: GenesisAlloc{
+			beneficiary: {Balance: big.NewInt(1000), Vesting: &GenesisVesting{Start: 100, Cliff: 150, End: 200}},
+			stranger:    {Balance: big.NewInt(0)},
+		},
+	}
+	if err := genesis.validate(); err != nil {
+		t.Fatalf("vesting genesis rejected: %v", err)
+	}
+	block := genesis.MustCommit(db)
+	statedb, _ := state.New(block.Root(), state.NewDatabase(db), nil)
+	if balance := statedb.GetBalance(beneficiary); balance.Sign() != 0 {
+		t.Fatalf("vested balance spendable at genesis: %v", balance)
+	}
+	if balance := statedb.GetBalance(params.VestingContractAddress); balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("vesting contract balance mismatch: have %v, want 1000", balance)
+	}
+
+	// release calls the vesting contract from the account at the given time
+	release := func(from common.Address, time int64) error {
+		evm := vm.NewEVM(vm.Context{
+			CanTransfer: CanTransfer,
+			Transfer:    Transfer,
+			BlockNumber: big.NewInt(1),
+			Time:        big.NewInt(time),
+			Difficulty:  big.NewInt(1),
+		}, statedb, params.AllEthashProtocolChanges, vm.Config{})
+		_, _, err := evm.Call(vm.AccountRef(from), params.VestingContractAddress, nil, 100000, new(big.Int))
+		return err
+	}
+	tests := []struct {
+		from    common.Address
+		time    int64
+		fail    bool
+		balance int64
+	}{
+		{beneficiary, 149, true, 0},    // Before the cliff
+		{beneficiary, 150, false, 500}, // Linear from the start on
+		{beneficiary, 150, true, 500},  // Nothing more to release
+		{stranger, 170, true, 0},       // No schedule
+		{beneficiary, 170, false, 700},
+		{beneficiary, 250, false, 1000}, // Past the end
+		{beneficiary, 300, true, 1000},
+	}
+	for i, test := range tests {
+		err := release(test.from, test.time)
+		if test.fail != (err != nil) {
+			t.Errorf("test %d: release error mismatch: have %v, want failure %v", i, err, test.fail)
+		}
+		if balance := statedb.GetBalance(test.from); balance.Cmp(big.NewInt(test.balance)) != 0 {
+			t.Errorf("test %d: balance mismatch: have %v, want %d", i, balance, test.balance)
+		}
+	}
+	if balance := statedb.GetBalance(params.VestingContractAddress); balance.Sign() != 0 {
+		t.Errorf("vesting contract not drained: %v", balance)
+	}
+
+	// Schedules never releasing the balance are rejected
+	for i, vesting := range []*GenesisVesting{{Start: 200, End: 200}, {Start: 100, Cliff: 50, End: 200}, {Start: 100, Cliff: 250, End: 200}} {
+		genesis.Alloc[beneficiary] = GenesisAccount{Balance: big.NewInt(1000), Vesting: vesting}
+		if err := genesis.validate(); err == nil {
+			t.Errorf("test %d: invalid vesting accepted", i)
+		}
+	}
+}
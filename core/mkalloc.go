@@ -19,20 +19,30 @@
 /*
 
    The mkalloc tool creates the genesis allocation constants in genesis_alloc.go
-   It outputs a const declaration that contains an RLP-encoded list of (address, balance) tuples.
+   It outputs a const declaration that contains an RLP-encoded list of (address, balance) tuples,
+   taken from the alloc of a genesis spec or from a CSV or JSON holder list.
 
        go run mkalloc.go genesis.json
+       go run mkalloc.go -name mainnetAllocData -update genesis_alloc.go allocs/mainnet.csv
+
+   With -update, the declaration replaces the constant of the same name in the given file.
 
 */
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/rlp"
@@ -46,10 +56,10 @@ func (a allocList) Len() int           { return len(a) }
 func (a allocList) Less(i, j int) bool { return a[i].Addr.Cmp(a[j].Addr) < 0 }
 func (a allocList) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 
-func makelist(g *core.Genesis) allocList {
-	a := make(allocList, 0, len(g.Alloc))
-	for addr, account := range g.Alloc {
-		if len(account.Storage) > 0 || len(account.Code) > 0 || account.Nonce != 0 {
+func makelist(alloc core.GenesisAlloc) allocList {
+	a := make(allocList, 0, len(alloc))
+	for addr, account := range alloc {
+		if len(account.Storage) > 0 || len(account.Code) > 0 || account.Nonce != 0 || account.Vesting != nil {
 			panic(fmt.Sprintf("can't encode account %x", addr))
 		}
 		bigAddr := new(big.Int).SetBytes(addr.Bytes())
@@ -59,8 +69,8 @@ func makelist(g *core.Genesis) allocList {
 	return a
 }
 
-func makealloc(g *core.Genesis) string {
-	a := makelist(g)
+func makealloc(alloc core.GenesisAlloc) string {
+	a := makelist(alloc)
 	data, err := rlp.EncodeToBytes(a)
 	if err != nil {
 		panic(err)
@@ -68,19 +78,53 @@ func makealloc(g *core.Genesis) string {
 	return strconv.QuoteToASCII(string(data))
 }
 
+// loadalloc reads the allocation of a genesis spec, or of a holder list if the
+// file is a CSV file or a JSON array.
+func loadalloc(path string) core.GenesisAlloc {
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			panic(err)
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+			g := new(core.Genesis)
+			if err := json.Unmarshal(data, g); err != nil {
+				panic(err)
+			}
+			return g.Alloc
+		}
+	}
+	alloc, err := core.LoadGenesisAlloc(path)
+	if err != nil {
+		panic(err)
+	}
+	return alloc
+}
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintln(os.Stderr, "Usage: mkalloc genesis.json")
+	name := flag.String("name", "allocData", "name of the constant")
+	update := flag.String("update", "", "file to replace the constant in")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: mkalloc [-name allocData] [-update genesis_alloc.go] genesis.json|holders.csv|holders.json")
 		os.Exit(1)
 	}
-
-	g := new(core.Genesis)
-	file, err := os.Open(os.Args[1])
+	decl := fmt.Sprintf("const %s = %s", *name, makealloc(loadalloc(flag.Arg(0))))
+	if *update == "" {
+		fmt.Println(decl)
+		return
+	}
+	src, err := ioutil.ReadFile(*update)
 	if err != nil {
 		panic(err)
 	}
-	if err := json.NewDecoder(file).Decode(g); err != nil {
+	pattern := regexp.MustCompile(`(?m)^const ` + regexp.QuoteMeta(*name) + ` = .*$`)
+	if !pattern.Match(src) {
+		panic(fmt.Sprintf("constant %s not found in %s", *name, *update))
+	}
+	src = pattern.ReplaceAllLiteral(src, []byte(decl))
+	if err := ioutil.WriteFile(*update, src, 0644); err != nil {
 		panic(err)
 	}
-	fmt.Println("const allocData =", makealloc(g))
 }
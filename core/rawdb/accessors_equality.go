@@ -0,0 +1,308 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/ethdb"
+	"github.com/SecretBlockChain/go-secret/log"
+	"github.com/SecretBlockChain/go-secret/rlp"
+)
+
+// CandidateEvent is an entry of the candidate history index maintained by the
+// equality consensus engine.
+type CandidateEvent struct {
+	Kind   uint8    // Kind of the lifecycle event, defined by the consensus engine
+	Number uint64   // Block number the event was included in
+	Epoch  uint64   // Epoch the block belongs to
+	Staked *big.Int // Security deposit involved in the event
+}
+
+// ReadCandidateEvents retrieves all the indexed lifecycle events of a candidate,
+// ordered by block number.
+func ReadCandidateEvents(db ethdb.Iteratee, address common.Address) []CandidateEvent {
+	prefix := append(append([]byte{}, candidateHistoryPrefix...), address.Bytes()...)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var events []CandidateEvent
+	for it.Next() {
+		var event CandidateEvent
+		if err := rlp.DecodeBytes(it.Value(), &event); err != nil {
+			log.Error("Invalid candidate event RLP", "address", address, "err", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// WriteCandidateEvent stores a lifecycle event of a candidate.
+func WriteCandidateEvent(db ethdb.KeyValueWriter, address common.Address, event CandidateEvent) {
+	if event.Staked == nil {
+		event.Staked = new(big.Int)
+	}
+	data, err := rlp.EncodeToBytes(event)
+	if err != nil {
+		log.Crit("Failed to RLP encode candidate event", "err", err)
+	}
+	if err := db.Put(candidateHistoryKey(address, event.Number, event.Kind), data); err != nil {
+		log.Crit("Failed to store candidate event", "err", err)
+	}
+}
+
+// DeleteCandidateEvent removes a lifecycle event of a candidate.
+func DeleteCandidateEvent(db ethdb.KeyValueWriter, address common.Address, number uint64, kind uint8) {
+	if err := db.Delete(candidateHistoryKey(address, number, kind)); err != nil {
+		log.Crit("Failed to delete candidate event", "err", err)
+	}
+}
+
+// KickOutEvent is an entry of the kick-out index maintained by the equality
+// consensus engine, recording why a validator was removed from the candidates.
+type KickOutEvent struct {
+	Address   common.Address // Validator kicked out
+	Number    uint64         // Block number the kick-out was included in
+	MintCount uint64         // Blocks minted by the validator in the previous epoch
+	Threshold uint64         // Minimum blocks to mint to stay a candidate
+}
+
+// ReadKickOutEvents retrieves the validators kicked out when entering an epoch.
+func ReadKickOutEvents(db ethdb.Iteratee, epoch uint64) []KickOutEvent {
+	prefix := append(append([]byte{}, kickOutPrefix...), encodeBlockNumber(epoch)...)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var events []KickOutEvent
+	for it.Next() {
+		var event KickOutEvent
+		if err := rlp.DecodeBytes(it.Value(), &event); err != nil {
+			log.Error("Invalid kick-out event RLP", "epoch", epoch, "err", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// WriteKickOutEvent stores the kick-out of a validator when entering an epoch.
+func WriteKickOutEvent(db ethdb.KeyValueWriter, epoch uint64, event KickOutEvent) {
+	data, err := rlp.EncodeToBytes(event)
+	if err != nil {
+		log.Crit("Failed to RLP encode kick-out event", "err", err)
+	}
+	if err := db.Put(kickOutKey(epoch, event.Address), data); err != nil {
+		log.Crit("Failed to store kick-out event", "err", err)
+	}
+}
+
+// SlotRecord is an entry of the slot index maintained by the equality consensus
+// engine, recording whether a validator filled one of its time slots.
+type SlotRecord struct {
+	Epoch  uint64      // Epoch the slot belongs to
+	Slot   uint64      // Index of the time slot since the genesis timestamp
+	Number uint64      // Number of the block filling the slot, or following it if missed
+	Hash   common.Hash // Hash of the block, to filter out the ones reorganised away
+	Minted bool        // Whether the validator minted the block of the slot
+	Delay  uint64      // Milliseconds between the slot and the import of the block, 0 if unknown
+}
+
+// ReadSlotRecords retrieves the indexed slots of a validator within the given
+// range of epochs, ordered by slot.
+func ReadSlotRecords(db ethdb.Iteratee, address common.Address, fromEpoch, toEpoch uint64) []SlotRecord {
+	prefix := append(append([]byte{}, slotHistoryPrefix...), address.Bytes()...)
+	it := db.NewIterator(prefix, encodeBlockNumber(fromEpoch))
+	defer it.Release()
+
+	var records []SlotRecord
+	for it.Next() {
+		var record SlotRecord
+		if err := rlp.DecodeBytes(it.Value(), &record); err != nil {
+			log.Error("Invalid slot record RLP", "address", address, "err", err)
+			continue
+		}
+		if record.Epoch > toEpoch {
+			break
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// WriteSlotRecord stores a time slot of a validator.
+func WriteSlotRecord(db ethdb.KeyValueWriter, address common.Address, record SlotRecord) {
+	data, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		log.Crit("Failed to RLP encode slot record", "err", err)
+	}
+	if err := db.Put(slotHistoryKey(address, record.Epoch, record.Slot), data); err != nil {
+		log.Crit("Failed to store slot record", "err", err)
+	}
+}
+
+// EpochStats are the statistics of a finished epoch, aggregated by the equality
+// consensus engine when the next one starts.
+type EpochStats struct {
+	Epoch      uint64      // Epoch the statistics are about
+	FirstBlock uint64      // Number of the first block of the epoch
+	LastBlock  uint64      // Number of the last block of the epoch
+	LastHash   common.Hash // Hash of the last block, to filter out reorganised epochs
+	Proposers  uint64      // Number of distinct validators which minted blocks
+	Interval   uint64      // Average time between two blocks, in milliseconds
+	Rewards    *big.Int    // Block rewards minted, pool share included
+	Registered uint64      // Candidates registered
+	Canceled   uint64      // Candidacies canceled
+	KickedOut  uint64      // Candidates kicked out
+	Expired    uint64      // Candidates expired
+}
+
+// ReadEpochStats retrieves the statistics of a finished epoch.
+func ReadEpochStats(db ethdb.KeyValueReader, epoch uint64) *EpochStats {
+	data, _ := db.Get(epochStatsKey(epoch))
+	if len(data) == 0 {
+		return nil
+	}
+	stats := new(EpochStats)
+	if err := rlp.DecodeBytes(data, stats); err != nil {
+		log.Error("Invalid epoch stats RLP", "epoch", epoch, "err", err)
+		return nil
+	}
+	return stats
+}
+
+// WriteEpochStats stores the statistics of a finished epoch.
+func WriteEpochStats(db ethdb.KeyValueWriter, stats *EpochStats) {
+	if stats.Rewards == nil {
+		stats.Rewards = new(big.Int)
+	}
+	data, err := rlp.EncodeToBytes(stats)
+	if err != nil {
+		log.Crit("Failed to RLP encode epoch stats", "err", err)
+	}
+	if err := db.Put(epochStatsKey(stats.Epoch), data); err != nil {
+		log.Crit("Failed to store epoch stats", "err", err)
+	}
+}
+
+// ProposalOutcome is an entry of the proposal history index maintained by the
+// equality consensus engine, recording how a governance proposal was closed.
+type ProposalOutcome struct {
+	ID         common.Hash // Hash of the transaction submitting the proposal
+	Number     uint64      // Block number the proposal was executed or expired in
+	Executed   bool        // Whether the proposal was approved, expired otherwise
+	Approvals  uint64      // Validators approving the proposal when closed
+	Rejections uint64      // Validators rejecting the proposal when closed
+	Validators uint64      // Validators allowed to vote when closed
+	Quorum     uint64      // Approvals required by the proposal
+	Record     []byte      // Proposal as stored in the consensus snapshot, RLP encoded
+}
+
+// ReadProposalOutcomes retrieves the indexed outcomes of the proposals closed in
+// the given range of blocks, ordered by block number.
+func ReadProposalOutcomes(db ethdb.Iteratee, from, to uint64) []ProposalOutcome {
+	it := db.NewIterator(proposalHistoryPrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var outcomes []ProposalOutcome
+	for it.Next() {
+		var outcome ProposalOutcome
+		if err := rlp.DecodeBytes(it.Value(), &outcome); err != nil {
+			log.Error("Invalid proposal outcome RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		if outcome.Number > to {
+			break
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+// WriteProposalOutcome stores the outcome of a closed proposal.
+func WriteProposalOutcome(db ethdb.KeyValueWriter, outcome ProposalOutcome) {
+	data, err := rlp.EncodeToBytes(outcome)
+	if err != nil {
+		log.Crit("Failed to RLP encode proposal outcome", "err", err)
+	}
+	if err := db.Put(proposalHistoryKey(outcome.Number, outcome.ID), data); err != nil {
+		log.Crit("Failed to store proposal outcome", "err", err)
+	}
+}
+
+// ConsensusIntent is a staking operation queued on the local node, submitted as
+// a transaction once its trigger condition is met.
+type ConsensusIntent struct {
+	ID      uint64         // Sequence number of the intent, assigned on enqueue
+	Action  string         // Consensus operation to perform
+	Account common.Address // Account sending the transaction
+	Epoch   uint64         // Epoch from which the intent is due, 0 if unconditional
+	Time    uint64         // Unix timestamp from which the intent is due, 0 if unconditional
+	Status  uint8          // Processing status, defined by the scheduler
+	TxHash  common.Hash    // Hash of the submitted transaction
+	Error   string         // Reason of the failure, if any
+}
+
+// ReadConsensusIntent retrieves a single queued consensus intent.
+func ReadConsensusIntent(db ethdb.KeyValueReader, id uint64) *ConsensusIntent {
+	data, _ := db.Get(consensusIntentKey(id))
+	if len(data) == 0 {
+		return nil
+	}
+	intent := new(ConsensusIntent)
+	if err := rlp.DecodeBytes(data, intent); err != nil {
+		log.Error("Invalid consensus intent RLP", "id", id, "err", err)
+		return nil
+	}
+	return intent
+}
+
+// ReadConsensusIntents retrieves all the queued consensus intents, ordered by id.
+func ReadConsensusIntents(db ethdb.Iteratee) []*ConsensusIntent {
+	it := db.NewIterator(consensusIntentPrefix, nil)
+	defer it.Release()
+
+	var intents []*ConsensusIntent
+	for it.Next() {
+		intent := new(ConsensusIntent)
+		if err := rlp.DecodeBytes(it.Value(), intent); err != nil {
+			log.Error("Invalid consensus intent RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		intents = append(intents, intent)
+	}
+	return intents
+}
+
+// WriteConsensusIntent stores a queued consensus intent.
+func WriteConsensusIntent(db ethdb.KeyValueWriter, intent *ConsensusIntent) {
+	data, err := rlp.EncodeToBytes(intent)
+	if err != nil {
+		log.Crit("Failed to RLP encode consensus intent", "err", err)
+	}
+	if err := db.Put(consensusIntentKey(intent.ID), data); err != nil {
+		log.Crit("Failed to store consensus intent", "err", err)
+	}
+}
+
+// DeleteConsensusIntent removes a queued consensus intent.
+func DeleteConsensusIntent(db ethdb.KeyValueWriter, id uint64) {
+	if err := db.Delete(consensusIntentKey(id)); err != nil {
+		log.Crit("Failed to delete consensus intent", "err", err)
+	}
+}
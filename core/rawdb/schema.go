@@ -75,6 +75,13 @@ var (
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
+	candidateHistoryPrefix = []byte("eq-candidate-") // candidateHistoryPrefix + address + num (uint64 big endian) + kind -> candidate event
+	consensusIntentPrefix  = []byte("eq-intent-")    // consensusIntentPrefix + id (uint64 big endian) -> consensus intent
+	kickOutPrefix          = []byte("eq-kickout-")   // kickOutPrefix + epoch (uint64 big endian) + address -> kick-out event
+	slotHistoryPrefix      = []byte("eq-slot-")      // slotHistoryPrefix + address + epoch (uint64 big endian) + slot (uint64 big endian) -> slot record
+	epochStatsPrefix       = []byte("eq-epoch-")     // epochStatsPrefix + epoch (uint64 big endian) -> epoch statistics
+	proposalHistoryPrefix  = []byte("eq-proposal-")  // proposalHistoryPrefix + num (uint64 big endian) + id -> proposal outcome
+
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 
@@ -189,6 +196,38 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// candidateHistoryKey = candidateHistoryPrefix + address + num (uint64 big endian) + kind
+func candidateHistoryKey(address common.Address, number uint64, kind uint8) []byte {
+	key := append(append(candidateHistoryPrefix, address.Bytes()...), encodeBlockNumber(number)...)
+	return append(key, kind)
+}
+
+// kickOutKey = kickOutPrefix + epoch (uint64 big endian) + address
+func kickOutKey(epoch uint64, address common.Address) []byte {
+	return append(append(kickOutPrefix, encodeBlockNumber(epoch)...), address.Bytes()...)
+}
+
+// slotHistoryKey = slotHistoryPrefix + address + epoch (uint64 big endian) + slot (uint64 big endian)
+func slotHistoryKey(address common.Address, epoch uint64, slot uint64) []byte {
+	key := append(append(slotHistoryPrefix, address.Bytes()...), encodeBlockNumber(epoch)...)
+	return append(key, encodeBlockNumber(slot)...)
+}
+
+// epochStatsKey = epochStatsPrefix + epoch (uint64 big endian)
+func epochStatsKey(epoch uint64) []byte {
+	return append(epochStatsPrefix, encodeBlockNumber(epoch)...)
+}
+
+// proposalHistoryKey = proposalHistoryPrefix + num (uint64 big endian) + id
+func proposalHistoryKey(number uint64, id common.Hash) []byte {
+	return append(append(proposalHistoryPrefix, encodeBlockNumber(number)...), id.Bytes()...)
+}
+
+// consensusIntentKey = consensusIntentPrefix + id (uint64 big endian)
+func consensusIntentKey(id uint64) []byte {
+	return append(consensusIntentPrefix, encodeBlockNumber(id)...)
+}
+
 // preimageKey = preimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(preimagePrefix, hash.Bytes()...)
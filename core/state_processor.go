@@ -78,6 +78,18 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
 	p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles())
 
+	// Attach the logs the engine emitted while finalizing to their receipts
+	if logs := statedb.Logs(); len(logs) > len(allLogs) {
+		allLogs = allLogs[:0]
+		for _, receipt := range receipts {
+			receipt.Logs = statedb.GetLogs(receipt.TxHash)
+			for _, log := range receipt.Logs {
+				log.Index = uint(len(allLogs))
+				allLogs = append(allLogs, log)
+			}
+			receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		}
+	}
 	return receipts, allLogs, *usedGas, nil
 }
 
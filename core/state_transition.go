@@ -17,6 +17,7 @@
 package core
 
 import (
+	"fmt"
 	"math"
 	"math/big"
 
@@ -197,6 +198,13 @@ func (st *StateTransition) preCheck() error {
 			return ErrNonceTooLow
 		}
 	}
+	// Make sure the gas price covers the base fee, calls pricing no gas aside
+	if baseFee := st.evm.BaseFee; baseFee != nil && !(st.evm.Config().NoBaseFee && st.gasPrice.Sign() == 0) {
+		if st.gasPrice.Cmp(baseFee) < 0 {
+			return fmt.Errorf("%w: address %v, gasPrice: %s baseFee: %s", ErrFeeCapTooLow,
+				st.msg.From().Hex(), st.gasPrice, baseFee)
+		}
+	}
 	return st.buyGas()
 }
 
@@ -260,7 +268,16 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		ret, st.gas, vmerr = st.evm.Call(sender, st.to(), st.data, st.gas, st.value)
 	}
 	st.refundGas()
-	st.state.AddBalance(st.evm.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice))
+
+	// Past the London fork the coinbase only earns the price above the base fee,
+	// the base fee being left to the consensus engine to burn or redistribute
+	tip := st.gasPrice
+	if baseFee := st.evm.BaseFee; baseFee != nil {
+		tip = new(big.Int).Sub(st.gasPrice, baseFee)
+	}
+	if tip.Sign() > 0 {
+		st.state.AddBalance(st.evm.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), tip))
+	}
 
 	return &ExecutionResult{
 		UsedGas:    st.gasUsed(),
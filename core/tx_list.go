@@ -335,6 +335,25 @@ func (l *txList) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions
 	if len(removed) == 0 {
 		return nil, nil
 	}
+	return removed, l.invalidate(removed)
+}
+
+// FilterBaseFee removes all transactions from the list priced below the given
+// base fee, which no block could include. Like Filter, it returns the removed
+// transactions along with the strict-mode invalidated ones.
+func (l *txList) FilterBaseFee(baseFee *big.Int) (types.Transactions, types.Transactions) {
+	removed := l.txs.Filter(func(tx *types.Transaction) bool {
+		return tx.GasPriceIntCmp(baseFee) < 0
+	})
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed, l.invalidate(removed)
+}
+
+// invalidate removes the transactions left behind the removed ones if the list
+// is strict, returning them, and regenerates the heap.
+func (l *txList) invalidate(removed types.Transactions) types.Transactions {
 	var invalids types.Transactions
 	// If the list was strict, filter anything above the lowest nonce
 	if l.strict {
@@ -347,7 +366,7 @@ func (l *txList) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions
 		invalids = l.txs.filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
 	}
 	l.txs.reheap()
-	return removed, invalids
+	return invalids
 }
 
 // Cap places a hard limit on the number of items, returning all transactions
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"math"
 	"math/big"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/prque"
+	"github.com/SecretBlockChain/go-secret/consensus/misc"
 	"github.com/SecretBlockChain/go-secret/core/state"
 	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/event"
@@ -49,6 +51,10 @@ const (
 	// more expensive to propagate; larger transactions also take more resources
 	// to validate whether they fit into the pool or not.
 	txMaxSize = 4 * txSlotSize // 128KB
+
+	// consensusTxPrefix is the data prefix of the custom transactions of the
+	// equality engine.
+	consensusTxPrefix = "equality:"
 )
 
 var (
@@ -79,6 +85,10 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrUnprotectedConsensusTx is returned if a custom transaction of the equality
+	// engine isn't replay protected while the chain requires it.
+	ErrUnprotectedConsensusTx = errors.New("unprotected consensus transaction")
 )
 
 var (
@@ -132,6 +142,13 @@ type blockChain interface {
 	SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Subscription
 }
 
+// ConsensusTxValidator is implemented by the consensus engines checking the
+// transactions managing the consensus roles against the consensus state at the
+// head of the chain, rejecting the ones the engine would drop from the blocks.
+type ConsensusTxValidator interface {
+	ValidateConsensusTx(config *params.ChainConfig, head *types.Header, from common.Address, tx *types.Transaction, balance *big.Int) error
+}
+
 // TxPoolConfig are the configuration parameters of the transaction pool.
 type TxPoolConfig struct {
 	Locals    []common.Address // Addresses that should be treated by default as local
@@ -225,9 +242,13 @@ type TxPool struct {
 
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 
+	currentHead   *types.Header  // Current head of the blockchain
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
+	baseFee       *big.Int       // Base fee of the next block, nil before the london fork
+
+	consensus ConsensusTxValidator // Validator of the consensus transactions, nil if the engine has none
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
@@ -427,6 +448,15 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// SetConsensusValidator sets the validator checking the consensus transactions
+// against the consensus state at admission.
+func (pool *TxPool) SetConsensusValidator(validator ConsensusTxValidator) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.consensus = validator
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (pool *TxPool) Nonce(addr common.Address) uint64 {
@@ -535,20 +565,38 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Custom consensus transactions must be replay protected, the pool signer
+	// already rejecting the ones of other chains
+	if equality := pool.chainconfig.Equality; equality != nil && !equality.AllowUnprotectedTx &&
+		!tx.Protected() && bytes.HasPrefix(tx.Data(), []byte(consensusTxPrefix)) {
+		return ErrUnprotectedConsensusTx
+	}
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
 	if !local && tx.GasPriceIntCmp(pool.gasPrice) < 0 {
 		return ErrUnderpriced
 	}
+	// Drop transactions unable to pay the base fee of the next block, local ones
+	// included as the miner couldn't include them either
+	if pool.baseFee != nil && tx.GasPriceIntCmp(pool.baseFee) < 0 {
+		return ErrFeeCapTooLow
+	}
 	// Ensure the transaction adheres to nonce ordering
 	if pool.currentState.GetNonce(from) > tx.Nonce() {
 		return ErrNonceTooLow
 	}
 	// Transactor should have enough funds to cover the costs
 	// cost == V + GP * GL
-	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
+	balance := pool.currentState.GetBalance(from)
+	if balance.Cmp(tx.Cost()) < 0 {
 		return ErrInsufficientFunds
 	}
+	// Drop the consensus transactions the engine would ignore at the head
+	if pool.consensus != nil {
+		if err := pool.consensus.ValidateConsensusTx(pool.chainconfig, pool.currentHead, from, tx, balance); err != nil {
+			return err
+		}
+	}
 	// Ensure the transaction has more gas than the basic tx fee.
 	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
@@ -1168,9 +1216,14 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to reset txpool state", "err", err)
 		return
 	}
+	pool.currentHead = newHead
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.baseFee = nil
+	if pool.chainconfig.IsLondon(new(big.Int).Add(newHead.Number, big.NewInt(1))) {
+		pool.baseFee = misc.CalcBaseFee(pool.chainconfig, newHead)
+	}
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -1204,6 +1257,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		if pool.baseFee != nil {
+			underpriced, _ := list.FilterBaseFee(pool.baseFee)
+			drops = append(drops, underpriced...)
+		}
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
@@ -1397,6 +1454,10 @@ func (pool *TxPool) demoteUnexecutables() {
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		if pool.baseFee != nil {
+			underpriced, more := list.FilterBaseFee(pool.baseFee)
+			drops, invalids = append(drops, underpriced...), append(invalids, more...)
+		}
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
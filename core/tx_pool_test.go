@@ -269,6 +269,84 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+func TestUnprotectedConsensusTransactions(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 10000000, new(event.Feed)}
+	config := *params.TestChainConfig
+	config.Equality = &params.EqualityConfig{MinCandidateBalance: big.NewInt(1)}
+
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	sign := func(nonce uint64, data string, signer types.Signer) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 100000, big.NewInt(1), []byte(data)), signer, key)
+		return tx
+	}
+	if err := pool.AddRemote(sign(0, "equality:1:event:candidate", types.HomesteadSigner{})); err != ErrUnprotectedConsensusTx {
+		t.Error("expected", ErrUnprotectedConsensusTx, "got", err)
+	}
+	if err := pool.AddRemote(sign(0, "equality:1:event:candidate", types.NewEIP155Signer(big.NewInt(2)))); err != ErrInvalidSender {
+		t.Error("expected", ErrInvalidSender, "got", err)
+	}
+	if err := pool.AddRemote(sign(0, "equality:1:event:candidate", pool.signer)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+	// Other transactions may still go unprotected
+	if err := pool.AddRemote(sign(1, "payload", types.HomesteadSigner{})); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+	// Unless the chain allows them for its legacy signers
+	config.Equality.AllowUnprotectedTx = true
+	if err := pool.AddRemote(sign(2, "equality:1:event:delegator", types.HomesteadSigner{})); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
+// testConsensusValidator rejects the transactions carrying the given data.
+type testConsensusValidator struct {
+	data     string
+	balances []*big.Int
+}
+
+func (v *testConsensusValidator) ValidateConsensusTx(config *params.ChainConfig, head *types.Header, from common.Address, tx *types.Transaction, balance *big.Int) error {
+	v.balances = append(v.balances, balance)
+	if string(tx.Data()) == v.data {
+		return errors.New("rejected by the engine")
+	}
+	return nil
+}
+
+func TestConsensusTxValidator(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	validator := &testConsensusValidator{data: "equality:1:event:candidate"}
+	pool.SetConsensusValidator(validator)
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	sign := func(nonce uint64, data string) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 100000, big.NewInt(1), []byte(data)), pool.signer, key)
+		return tx
+	}
+	if err := pool.AddRemote(sign(0, "equality:1:event:candidate")); err == nil {
+		t.Error("expected the engine to reject the transaction")
+	}
+	if err := pool.AddRemote(sign(0, "payload")); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+	// The engine is given the balance of the sender at the head
+	if len(validator.balances) != 2 || validator.balances[0].Cmp(big.NewInt(1000000)) != 0 {
+		t.Errorf("balances mismatch: %v", validator.balances)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -369,6 +447,69 @@ func TestTransactionChainFork(t *testing.T) {
 	}
 }
 
+// baseFeeTestChain is a test chain whose head carries a base fee, its gas used
+// on target so that the next block keeps it.
+type baseFeeTestChain struct {
+	*testBlockChain
+	baseFee *big.Int
+}
+
+func (bc *baseFeeTestChain) CurrentBlock() *types.Block {
+	return types.NewBlock(&types.Header{
+		GasLimit: bc.gasLimit,
+		GasUsed:  bc.gasLimit / params.ElasticityMultiplier,
+		BaseFee:  bc.baseFee,
+	}, nil, nil, nil, new(trie.Trie))
+}
+
+func (bc *baseFeeTestChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return bc.CurrentBlock()
+}
+
+// Tests that transactions unable to pay the base fee of the next block are
+// rejected, and dropped once the base fee rises above them.
+func TestTransactionBaseFee(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(account, big.NewInt(1000000000))
+	chain := &baseFeeTestChain{&testBlockChain{statedb, 1000000, new(event.Feed)}, big.NewInt(10)}
+
+	config := *params.TestChainConfig
+	config.LondonBlock = big.NewInt(0)
+	pool := NewTxPool(testTxPoolConfig, &config, chain)
+	defer pool.Stop()
+
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(5), key)); err != ErrFeeCapTooLow {
+		t.Errorf("transaction below the base fee error mismatch: have %v, want %v", err, ErrFeeCapTooLow)
+	}
+	for i, price := range []int64{20, 15, 20} {
+		if err := pool.AddRemote(pricedTransaction(uint64(i), 100000, big.NewInt(price), key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, account))
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d pending, %d queued, want 3, 0", pending, queued)
+	}
+
+	// Raise the base fee, the transaction below it is dropped and the ones after
+	// it are queued back
+	chain.baseFee = big.NewInt(18)
+	<-pool.requestReset(nil, nil)
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Errorf("pool stats mismatch: have %d pending, %d queued, want 1, 1", pending, queued)
+	}
+	if pool.Get(pricedTransaction(1, 100000, big.NewInt(15), key).Hash()) != nil {
+		t.Error("transaction below the base fee kept")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestTransactionDoubleNonce(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,126 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+var (
+	errConsensusUnavailable = errors.New("consensus state unavailable")
+	errUnknownEpoch         = errors.New("unknown epoch")
+)
+
+// ConsensusReader gives the consensus precompile and the staking contract access
+// to the state of the consensus engine at the block being executed.
+type ConsensusReader interface {
+	// Epoch returns the epoch of the block and the number of its first block.
+	Epoch() (epoch uint64, start uint64, err error)
+
+	// IsValidator returns whether the address is one of the validators sealing
+	// the block.
+	IsValidator(address common.Address) (bool, error)
+
+	// Candidate returns the deposit staked by the candidate, nil if the address
+	// is not a candidate.
+	Candidate(address common.Address) (*big.Int, error)
+
+	// MinCandidateBalance returns the deposit a candidate registers with.
+	MinCandidateBalance() (*big.Int, error)
+
+	// EpochStatus returns the first block of the epoch, the deposit staked by the
+	// candidate as the epoch started, nil if the address was not a candidate, and
+	// whether the address was elected a validator of the epoch. Only the epochs
+	// started before the block can be looked up.
+	EpochStatus(address common.Address, epoch uint64) (start uint64, staked *big.Int, validator bool, err error)
+}
+
+// consensusView implements the consensus precompile. The input is an address
+// padded to 32 bytes, the output the epoch, its first block and 1 if the
+// address is an active validator, 0 otherwise, as three 32 byte words.
+type consensusView struct {
+	reader ConsensusReader
+}
+
+func (c *consensusView) RequiredGas(input []byte) uint64 {
+	return params.ConsensusPrecompileGas
+}
+
+func (c *consensusView) Run(input []byte) ([]byte, error) {
+	if c.reader == nil {
+		return nil, errConsensusUnavailable
+	}
+	epoch, start, err := c.reader.Epoch()
+	if err != nil {
+		return nil, err
+	}
+	address := common.BytesToAddress(getData(input, 0, 32))
+	active, err := c.reader.IsValidator(address)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]byte, 96)
+	binary.BigEndian.PutUint64(output[24:32], epoch)
+	binary.BigEndian.PutUint64(output[56:64], start)
+	if active {
+		output[95] = 1
+	}
+	return output, nil
+}
+
+// candidateStatus implements the candidate status precompile. The input is an
+// address and an epoch, each padded to 32 bytes, the output the first block of
+// the epoch, 1 if the address was a candidate as the epoch started, 0 otherwise,
+// 1 if it was a validator of the epoch, 0 otherwise, and its deposit, as four
+// 32 byte words.
+type candidateStatus struct {
+	reader ConsensusReader
+}
+
+func (c *candidateStatus) RequiredGas(input []byte) uint64 {
+	return params.CandidateStatusGas
+}
+
+func (c *candidateStatus) Run(input []byte) ([]byte, error) {
+	if c.reader == nil {
+		return nil, errConsensusUnavailable
+	}
+	address := common.BytesToAddress(getData(input, 0, 32))
+	epoch := new(big.Int).SetBytes(getData(input, 32, 32))
+	if !epoch.IsUint64() {
+		return nil, errUnknownEpoch
+	}
+	start, staked, validator, err := c.reader.EpochStatus(address, epoch.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	output := make([]byte, 128)
+	binary.BigEndian.PutUint64(output[24:32], start)
+	if staked != nil {
+		output[63] = 1
+		copy(output[96:], common.LeftPadBytes(staked.Bytes(), 32))
+	}
+	if validator {
+		output[95] = 1
+	}
+	return output, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// Selectors of the functions of the staking contract and topics of its logs, as
+// for a Solidity contract declaring
+//
+//	function becomeCandidate() payable;
+//	function cancelCandidate();
+//	event CandidateRegistered(address indexed candidate, uint256 deposit);
+//	event CandidateCancelled(address indexed candidate);
+var (
+	BecomeCandidateSelector = crypto.Keccak256([]byte("becomeCandidate()"))[:4]
+	CancelCandidateSelector = crypto.Keccak256([]byte("cancelCandidate()"))[:4]
+
+	CandidateRegisteredTopic = crypto.Keccak256Hash([]byte("CandidateRegistered(address,uint256)"))
+	CandidateCancelledTopic  = crypto.Keccak256Hash([]byte("CandidateCancelled(address)"))
+)
+
+// revertSelector is the selector of Error(string), under which Solidity encodes
+// its revert reasons.
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// runStakingContract executes a call to the native staking contract, the value
+// being transferred to the contract already. A registration pays exactly the
+// minimum candidate balance, escrowed by the contract until the consensus
+// engine stakes it at the end of the block. The checks are made against the
+// consensus state of the parent block, the engine refunding the deposits of
+// the candidates registered twice in a block. Invalid calls revert with their
+// reason, leaving the remaining gas to the caller.
+func runStakingContract(evm *EVM, caller common.Address, input []byte, gas uint64, value *big.Int) ([]byte, uint64, error) {
+	if gas < params.StakingContractGas {
+		return nil, 0, ErrOutOfGas
+	}
+	gas -= params.StakingContractGas
+
+	reader := evm.Context.Consensus
+	if reader == nil {
+		return nil, 0, errConsensusUnavailable
+	}
+	var topic common.Hash
+	switch {
+	case len(input) >= 4 && bytes.Equal(input[:4], BecomeCandidateSelector):
+		deposit, err := reader.MinCandidateBalance()
+		if err != nil {
+			return nil, 0, err
+		}
+		if value.Cmp(deposit) != 0 {
+			return revertReason("deposit must equal the minimum candidate balance"), gas, ErrExecutionReverted
+		}
+		staked, err := reader.Candidate(caller)
+		if err != nil {
+			return nil, 0, err
+		}
+		if staked != nil {
+			return revertReason("already a candidate"), gas, ErrExecutionReverted
+		}
+		topic = CandidateRegisteredTopic
+
+	case len(input) >= 4 && bytes.Equal(input[:4], CancelCandidateSelector):
+		if value.Sign() != 0 {
+			return revertReason("cancellation takes no value"), gas, ErrExecutionReverted
+		}
+		staked, err := reader.Candidate(caller)
+		if err != nil {
+			return nil, 0, err
+		}
+		if staked == nil {
+			return revertReason("not a candidate"), gas, ErrExecutionReverted
+		}
+		topic = CandidateCancelledTopic
+
+	default:
+		return revertReason("unknown function"), gas, ErrExecutionReverted
+	}
+	var data []byte
+	if topic == CandidateRegisteredTopic {
+		data = common.LeftPadBytes(value.Bytes(), 32)
+	}
+	evm.StateDB.AddLog(&types.Log{
+		Address:     params.StakingContractAddress,
+		Topics:      []common.Hash{topic, common.BytesToHash(caller.Bytes())},
+		Data:        data,
+		BlockNumber: evm.BlockNumber.Uint64(),
+	})
+	return nil, gas, nil
+}
+
+// revertReason encodes the reason of a revert as Error(string).
+func revertReason(reason string) []byte {
+	data := append([]byte{}, revertSelector...)
+	data = append(data, common.LeftPadBytes(big.NewInt(32).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(reason))).Bytes(), 32)...)
+	return append(data, common.RightPadBytes([]byte(reason), (len(reason)+31)/32*32)...)
+}
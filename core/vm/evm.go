@@ -54,6 +54,12 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 	default:
 		precompiles = PrecompiledContractsHomestead
 	}
+	if evm.chainRules.IsConsensusPrecompile && addr == params.ConsensusPrecompileAddress {
+		return &consensusView{reader: evm.Context.Consensus}, true
+	}
+	if evm.chainRules.IsCandidateStatus && addr == params.CandidateStatusPrecompileAddress {
+		return &candidateStatus{reader: evm.Context.Consensus}, true
+	}
 	p, ok := precompiles[addr]
 	return p, ok
 }
@@ -97,6 +103,10 @@ type Context struct {
 	BlockNumber *big.Int       // Provides information for NUMBER
 	Time        *big.Int       // Provides information for TIME
 	Difficulty  *big.Int       // Provides information for DIFFICULTY
+	BaseFee     *big.Int       // Base fee of the block, nil before the London fork
+
+	// Consensus provides the consensus state to the consensus precompile
+	Consensus ConsensusReader
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -206,9 +216,10 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	}
 	snapshot := evm.StateDB.Snapshot()
 	p, isPrecompile := evm.precompile(addr)
+	isStaking := evm.chainRules.IsStaking && addr == params.StakingContractAddress
 
 	if !evm.StateDB.Exist(addr) {
-		if !isPrecompile && evm.chainRules.IsEIP158 && value.Sign() == 0 {
+		if !isPrecompile && !isStaking && evm.chainRules.IsEIP158 && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
 				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
@@ -230,6 +241,8 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 
 	if isPrecompile {
 		ret, gas, err = RunPrecompiledContract(p, input, gas)
+	} else if isStaking {
+		ret, gas, err = runStakingContract(evm, caller.Address(), input, gas, value)
 	} else {
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
@@ -499,3 +512,6 @@ func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *
 
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
+
+// Config returns the configuration of the interpreter
+func (evm *EVM) Config() Config { return evm.vmConfig }
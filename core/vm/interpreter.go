@@ -31,6 +31,7 @@ type Config struct {
 	Tracer                  Tracer // Opcode logger
 	NoRecursion             bool   // Disables call, callcode, delegate call and create
 	EnablePreimageRecording bool   // Enables recording of SHA3/keccak preimages
+	NoBaseFee               bool   // Lets messages priced below the base fee through, e.g. for calls
 
 	JumpTable [256]*operation // EVM instruction table, automatically populated if unset
 
@@ -154,6 +154,114 @@ func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return api.e.miner.HashRate()
 }
 
+// PublicHealthAPI provides an API to probe the health of the node.
+type PublicHealthAPI struct {
+	e *Ethereum
+}
+
+// NewPublicHealthAPI creates a new RPC service reporting the health of the node.
+func NewPublicHealthAPI(e *Ethereum) *PublicHealthAPI {
+	return &PublicHealthAPI{e: e}
+}
+
+// Health returns the sync status of the node, the integrity of its consensus
+// snapshot and the status of its validator, see the /health HTTP endpoint.
+func (api *PublicHealthAPI) Health() *Health {
+	return api.e.health()
+}
+
+// PrivateIntentAPI provides private RPC methods to queue staking operations
+// which the node submits once they are due.
+type PrivateIntentAPI struct {
+	e *Ethereum
+}
+
+// NewPrivateIntentAPI create a new RPC service which manages the consensus
+// intent queue of this node.
+func NewPrivateIntentAPI(e *Ethereum) *PrivateIntentAPI {
+	return &PrivateIntentAPI{e: e}
+}
+
+// IntentArgs are the arguments to queue a consensus intent.
+type IntentArgs struct {
+	Action  string          `json:"action"`
+	Account common.Address  `json:"account"`
+	Epoch   *hexutil.Uint64 `json:"epoch"`
+	Time    *hexutil.Uint64 `json:"time"`
+}
+
+// RPCIntent is a queued consensus intent along with its processing status.
+type RPCIntent struct {
+	ID      hexutil.Uint64 `json:"id"`
+	Action  string         `json:"action"`
+	Account common.Address `json:"account"`
+	Epoch   hexutil.Uint64 `json:"epoch"`
+	Time    hexutil.Uint64 `json:"time"`
+	Status  string         `json:"status"`
+	TxHash  *common.Hash   `json:"txHash,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+func newRPCIntent(intent *rawdb.ConsensusIntent) *RPCIntent {
+	result := &RPCIntent{
+		ID:      hexutil.Uint64(intent.ID),
+		Action:  intent.Action,
+		Account: intent.Account,
+		Epoch:   hexutil.Uint64(intent.Epoch),
+		Time:    hexutil.Uint64(intent.Time),
+		Status:  intentStatusNames[intent.Status],
+		Error:   intent.Error,
+	}
+	if intent.TxHash != (common.Hash{}) {
+		result.TxHash = &intent.TxHash
+	}
+	return result
+}
+
+// EnqueueIntent queues a staking operation ("register" or "cancel") of a local
+// account. The transaction is signed and submitted with the first chain head
+// reaching both the given epoch and unix time, either of which can be omitted.
+func (api *PrivateIntentAPI) EnqueueIntent(args IntentArgs) (hexutil.Uint64, error) {
+	intent := &rawdb.ConsensusIntent{Action: args.Action, Account: args.Account}
+	if args.Epoch != nil {
+		intent.Epoch = uint64(*args.Epoch)
+	}
+	if args.Time != nil {
+		intent.Time = uint64(*args.Time)
+	}
+	if err := api.e.enqueueIntent(intent); err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(intent.ID), nil
+}
+
+// GetIntents returns all the queued consensus intents, including the processed
+// ones.
+func (api *PrivateIntentAPI) GetIntents() []*RPCIntent {
+	intents := make([]*RPCIntent, 0)
+	for _, intent := range rawdb.ReadConsensusIntents(api.e.chainDb) {
+		intents = append(intents, newRPCIntent(intent))
+	}
+	return intents
+}
+
+// GetIntent returns a single queued consensus intent.
+func (api *PrivateIntentAPI) GetIntent(id hexutil.Uint64) (*RPCIntent, error) {
+	intent := rawdb.ReadConsensusIntent(api.e.chainDb, uint64(id))
+	if intent == nil {
+		return nil, errUnknownIntent
+	}
+	return newRPCIntent(intent), nil
+}
+
+// RemoveIntent drops a consensus intent which wasn't submitted yet.
+func (api *PrivateIntentAPI) RemoveIntent(id hexutil.Uint64) (bool, error) {
+	if err := api.e.removeIntent(uint64(id)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // PrivateAdminAPI is the collection of Ethereum full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/state"
@@ -73,6 +75,8 @@ type StdTraceConfig struct {
 type txTraceResult struct {
 	Result interface{} `json:"result,omitempty"` // Trace results produced by the tracer
 	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
+
+	ConsensusEffects []equality.ConsensusEffect `json:"consensusEffects,omitempty"` // Side effects applied by the consensus engine
 }
 
 // blockTraceTask represents a single block trace task when an entire chain is
@@ -440,7 +444,9 @@ func (api *PrivateDebugAPI) StandardTraceBadBlockToFile(ctx context.Context, has
 
 // traceBlock configures a new tracer according to the provided configuration, and
 // executes all the transactions contained within. The return value will be one item
-// per transaction, dependent on the requestd tracer.
+// per transaction, dependent on the requestd tracer. The consensus engines applying
+// side effects outside of the EVM report the ones of the transactions along their
+// traces, and the ones of the block itself in a trailing item.
 func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]*txTraceResult, error) {
 	// Create the parent state database
 	if err := api.eth.engine.VerifyHeader(api.eth.blockchain, block.Header(), true); err != nil {
@@ -495,6 +501,7 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	var failed error
 	for i, tx := range txs {
 		// Send the trace task over for execution
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
 		jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
 
 		// Generate the next state snapshot fast without tracing
@@ -517,6 +524,16 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	if failed != nil {
 		return nil, failed
 	}
+	// Attach the side effects of the consensus engine, if any
+	if effects := api.consensusEffects(block, statedb); effects != nil {
+		for i, tx := range txs {
+			results[i].ConsensusEffects = effects.Transactions[tx.Hash()]
+			if isCallTracer(config) {
+				results[i].Result = withConsensusTransfers(results[i].Result, results[i].ConsensusEffects)
+			}
+		}
+		results = append(results, &txTraceResult{Result: &blockTraceEffects{ConsensusEffects: effects.Block, Rewards: effects.Rewards}})
+	}
 	return results, nil
 }
 
@@ -720,8 +737,27 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	if err != nil {
 		return nil, err
 	}
-	// Trace the transaction and return
-	return api.traceTx(ctx, msg, vmctx, statedb, config)
+	// Trace the transaction, attaching the side effects of the consensus engine
+	// to the structured logs
+	result, err := api.traceTx(ctx, msg, vmctx, statedb, config)
+	if err != nil {
+		return nil, err
+	}
+	switch result := result.(type) {
+	case *ethapi.ExecutionResult:
+		if result.ConsensusEffects, err = api.transactionEffects(block, tx, reexec); err != nil {
+			return nil, err
+		}
+	case json.RawMessage:
+		if isCallTracer(config) {
+			effects, err := api.transactionEffects(block, tx, reexec)
+			if err != nil {
+				return nil, err
+			}
+			return withConsensusTransfers(result, effects), nil
+		}
+	}
+	return result, nil
 }
 
 // TraceCall lets you trace a given eth_call. It collects the structured logs created during the execution of EVM
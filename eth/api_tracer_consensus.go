@@ -0,0 +1,185 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+// consensusTracer is implemented by the consensus engines applying side effects
+// outside of the EVM, e.g. the security deposits of the candidates and the block
+// rewards, which the EVM tracers can't see.
+type consensusTracer interface {
+	TraceConsensusEffects(chain consensus.ChainHeaderReader, block *types.Block, statedb *state.StateDB) (*equality.ConsensusEffects, error)
+}
+
+// blockTraceEffects is the trailing entry of a block trace listing the consensus
+// effects of the block itself rather than of its transactions, and the credits
+// of its rewards as internal transactions.
+type blockTraceEffects struct {
+	ConsensusEffects []equality.ConsensusEffect `json:"consensusEffects"`
+	Rewards          []equality.RewardTransfer  `json:"rewards"`
+}
+
+// consensusEffects returns the consensus side effects of a block, given the state
+// following its transactions. Nil is returned if the engine has none.
+func (api *PrivateDebugAPI) consensusEffects(block *types.Block, statedb *state.StateDB) *equality.ConsensusEffects {
+	tracer, ok := api.eth.engine.(consensusTracer)
+	if !ok {
+		return nil
+	}
+	effects, err := tracer.TraceConsensusEffects(api.eth.blockchain, block, statedb)
+	if err != nil {
+		return nil
+	}
+	return effects
+}
+
+// transactionEffects returns the consensus side effects of a transaction, which
+// are only applied once all the transactions of its block are executed.
+func (api *PrivateDebugAPI) transactionEffects(block *types.Block, tx *types.Transaction, reexec uint64) ([]equality.ConsensusEffect, error) {
+	if _, ok := api.eth.engine.(consensusTracer); !ok {
+		return nil, nil
+	}
+	if _, err := equality.NewTransaction(tx); err != nil && !api.callsStakingContract(block, tx) {
+		return nil, nil
+	}
+	parent := api.eth.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x not found", block.ParentHash())
+	}
+	statedb, err := api.computeStateDB(parent, reexec)
+	if err != nil {
+		return nil, err
+	}
+	signer := types.MakeSigner(api.eth.blockchain.Config(), block.Number())
+	for i, tx := range block.Transactions() {
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		msg, _ := tx.AsMessage(signer)
+		vmenv := vm.NewEVM(core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil), statedb, api.eth.blockchain.Config(), vm.Config{})
+		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+		}
+		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
+	}
+	if effects := api.consensusEffects(block, statedb); effects != nil {
+		return effects.Transactions[tx.Hash()], nil
+	}
+	return nil, nil
+}
+
+// callsStakingContract returns whether the transaction called the staking
+// contract, its calls being settled by the consensus engine with the block.
+func (api *PrivateDebugAPI) callsStakingContract(block *types.Block, tx *types.Transaction) bool {
+	if !api.eth.blockchain.Config().IsStaking(block.Number()) {
+		return false
+	}
+	for _, receipt := range api.eth.blockchain.GetReceiptsByHash(block.Hash()) {
+		if receipt.TxHash != tx.Hash() {
+			continue
+		}
+		for _, log := range receipt.Logs {
+			if log.Address == params.StakingContractAddress {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCallTracer returns whether the trace is made by the built-in call tracer,
+// whose results list the balance changes of the consensus engine among the
+// calls.
+func isCallTracer(config *TraceConfig) bool {
+	return config != nil && config.Tracer != nil && *config.Tracer == "callTracer"
+}
+
+// consensusCall is a balance change of the consensus engine reported among the
+// calls of the call tracer, a value transfer between the account and the zero
+// address standing for the engine. Deposits locked are sent to the engine, the
+// refunds sent back by it.
+type consensusCall struct {
+	Type    string         `json:"type"`
+	Action  string         `json:"action"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Value   *hexutil.Big   `json:"value"`
+	Gas     hexutil.Uint64 `json:"gas"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Input   hexutil.Bytes  `json:"input"`
+}
+
+// withConsensusTransfers appends the balance changes among the consensus effects
+// to the calls of a call tracer result, returning the result unchanged if there
+// are none.
+func withConsensusTransfers(result interface{}, effects []equality.ConsensusEffect) interface{} {
+	raw, ok := result.(json.RawMessage)
+	if !ok {
+		return result
+	}
+	var transfers []json.RawMessage
+	for _, effect := range effects {
+		if effect.Amount == nil || effect.Amount.ToInt().Sign() == 0 {
+			continue
+		}
+		call := consensusCall{Type: "CONSENSUS", Action: effect.Action, Value: (*hexutil.Big)(new(big.Int).Abs(effect.Amount.ToInt())), Input: []byte{}}
+		if effect.Amount.ToInt().Sign() < 0 {
+			call.From = effect.Address
+		} else {
+			call.To = effect.Address
+		}
+		transfer, err := json.Marshal(call)
+		if err != nil {
+			return result
+		}
+		transfers = append(transfers, transfer)
+	}
+	if len(transfers) == 0 {
+		return result
+	}
+	var frame map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &frame); err != nil {
+		return result
+	}
+	var calls []json.RawMessage
+	if frame["calls"] != nil {
+		if err := json.Unmarshal(frame["calls"], &calls); err != nil {
+			return result
+		}
+	}
+	merged, err := json.Marshal(append(calls, transfers...))
+	if err != nil {
+		return result
+	}
+	frame["calls"] = merged
+	if raw, err = json.Marshal(frame); err != nil {
+		return result
+	}
+	return raw
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+)
+
+func TestWithConsensusTransfers(t *testing.T) {
+	candidate := common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+	result := json.RawMessage(`{"type":"CALL","from":"0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c","to":"0x0000000000000000000000000000000000000001","value":"0x0"}`)
+	effects := []equality.ConsensusEffect{
+		{Action: equality.EffectLock, Address: candidate, Amount: (*hexutil.Big)(big.NewInt(-1000))},
+		{Action: equality.EffectRegisterCandidate, Address: candidate},
+	}
+
+	// Balance changes are appended to the calls as transfers to the engine
+	var frame struct {
+		Type  string          `json:"type"`
+		Calls []consensusCall `json:"calls"`
+	}
+	if err := json.Unmarshal(withConsensusTransfers(result, effects).(json.RawMessage), &frame); err != nil {
+		t.Fatalf("failed to decode the trace: %v", err)
+	}
+	if frame.Type != "CALL" {
+		t.Errorf("trace type changed to %q", frame.Type)
+	}
+	if len(frame.Calls) != 1 {
+		t.Fatalf("calls mismatch: have %d, want 1", len(frame.Calls))
+	}
+	call := frame.Calls[0]
+	if call.Action != equality.EffectLock || call.From != candidate || call.To != (common.Address{}) || call.Value.ToInt().Int64() != 1000 {
+		t.Errorf("lock mismatch: have %+v", call)
+	}
+
+	// Refunds come from the engine, results without balance changes are kept
+	effects = []equality.ConsensusEffect{{Action: equality.EffectRefund, Address: candidate, Amount: (*hexutil.Big)(big.NewInt(1000))}}
+	if err := json.Unmarshal(withConsensusTransfers(result, effects).(json.RawMessage), &frame); err != nil {
+		t.Fatalf("failed to decode the trace: %v", err)
+	}
+	if call := frame.Calls[0]; call.From != (common.Address{}) || call.To != candidate {
+		t.Errorf("refund mismatch: have %+v", call)
+	}
+	if have := withConsensusTransfers(result, effects[:0]).(json.RawMessage); string(have) != string(result) {
+		t.Errorf("trace without effects changed: %s", have)
+	}
+}
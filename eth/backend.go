@@ -74,12 +74,19 @@ type Ethereum struct {
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
+	closeClockCheck   chan struct{}
+	closeIntents      chan struct{}
+	closeSlotTrack    chan struct{}
+	closeEvents       chan struct{}
+	closeWatchdog     chan struct{}
+	intentLock        sync.Mutex // Serializes the updates of the consensus intent queue
 
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
 	gasPrice  *big.Int
 	etherbase common.Address
+	watchdog  *equality.Watchdog // Supervises the unlocked sealing key, nil unless enabled
 
 	networkID     uint64
 	netRPCService *ethapi.PublicNetAPI
@@ -93,7 +100,7 @@ type Ethereum struct {
 // initialisation of the common Ethereum object)
 func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	// Ensure configuration values are compatible and sane
-	if config.SyncMode == downloader.LightSync {
+	if config.SyncMode.IsLight() {
 		return nil, errors.New("can't run eth.Ethereum in light sync mode, use les.LightEthereum")
 	}
 	if !config.SyncMode.IsValid() {
@@ -119,7 +126,7 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis)
+	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideEquality)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
 	}
@@ -132,6 +139,11 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		accountManager:    stack.AccountManager(),
 		engine:            CreateConsensusEngine(stack, chainConfig, &config.Ethash, config.Miner.Notify, config.Miner.Noverify, chainDb),
 		closeBloomHandler: make(chan struct{}),
+		closeClockCheck:   make(chan struct{}),
+		closeIntents:      make(chan struct{}),
+		closeSlotTrack:    make(chan struct{}),
+		closeEvents:       make(chan struct{}),
+		closeWatchdog:     make(chan struct{}),
 		networkID:         config.NetworkId,
 		gasPrice:          config.Miner.GasPrice,
 		etherbase:         config.Miner.Etherbase,
@@ -172,6 +184,46 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 			SnapshotLimit:       config.SnapshotCache,
 		}
 	)
+	type pruned interface {
+		SetSnapshotPruning(enabled bool)
+	}
+	if engine, ok := eth.engine.(pruned); ok {
+		engine.SetSnapshotPruning(!config.EqualityNoPruning)
+	}
+	type guarded interface {
+		SetSignGuard(guard *equality.SignGuard)
+	}
+	if engine, ok := eth.engine.(guarded); ok && config.Miner.SignGuard != "" {
+		// Ephemeral nodes have no datadir to keep the guard in
+		if path := stack.ResolvePath(config.Miner.SignGuard); path != "" {
+			guard, err := equality.OpenSignGuard(path)
+			if err != nil {
+				return nil, err
+			}
+			engine.SetSignGuard(guard)
+		}
+	}
+	type policed interface {
+		AddSealPolicy(policy equality.SealPolicy)
+	}
+	if engine, ok := eth.engine.(policed); ok {
+		if config.Miner.PolicyHeight {
+			engine.AddSealPolicy(new(equality.HeightPolicy))
+		}
+		if config.Miner.PolicyDrift > 0 {
+			engine.AddSealPolicy(equality.ClockPolicy{MaxDrift: config.Miner.PolicyDrift})
+		}
+		if len(config.Miner.PolicyValidators) > 0 {
+			engine.AddSealPolicy(equality.NewValidatorSetPolicy(config.Miner.PolicyValidators))
+		}
+	}
+	type headerOnly interface {
+		SetHeaderOnly()
+	}
+	if engine, ok := eth.engine.(headerOnly); ok && config.EqualityHeaderOnly {
+		log.Warn("Verifying equality headers only, the snapshot roots are trusted")
+		engine.SetHeaderOnly()
+	}
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
 	if err != nil {
 		return nil, err
@@ -188,6 +240,9 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
+	if validator, ok := eth.engine.(core.ConsensusTxValidator); ok {
+		eth.txPool.SetConsensusValidator(validator)
+	}
 
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
@@ -218,6 +273,7 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 
 	// Register the backend on the node
 	stack.RegisterAPIs(eth.APIs())
+	stack.RegisterHandler("Health", "/health", healthHandler{eth})
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
 	return eth, nil
@@ -242,6 +298,13 @@ func makeExtraData(extra []byte) []byte {
 
 // CreateConsensusEngine creates the required type of consensus engine instance for an Ethereum service
 func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, config *ethash.Config, notify []string, noverify bool, db ethdb.Database) consensus.Engine {
+	// If the chain switches to proof-of-equality later on, wrap the legacy engine
+	if chainConfig.Equality != nil && !chainConfig.IsEquality(common.Big0) {
+		legacyConfig := *chainConfig
+		legacyConfig.Equality, legacyConfig.EqualityBlock = nil, nil
+		legacy := CreateConsensusEngine(stack, &legacyConfig, config, notify, noverify, db)
+		return equality.NewTransition(legacy, equality.NewFromBlock(chainConfig.Equality, db, chainConfig.EqualityBlock.Uint64()))
+	}
 	// If proof-of-authority is requested, set it up
 	if chainConfig.Clique != nil {
 		return clique.New(chainConfig.Clique, db)
@@ -320,6 +383,16 @@ func (s *Ethereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateMinerAPI(s),
 			Public:    false,
+		}, {
+			Namespace: "eq",
+			Version:   "1.0",
+			Service:   NewPrivateIntentAPI(s),
+			Public:    false,
+		}, {
+			Namespace: "eq",
+			Version:   "1.0",
+			Service:   NewPublicHealthAPI(s),
+			Public:    true,
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
@@ -480,12 +553,18 @@ func (s *Ethereum) StartMining(threads int) error {
 			clique.Authorize(eb, wallet.SignData)
 		}
 		if equality, ok := s.engine.(*equality.Equality); ok {
-			wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
-			if wallet == nil || err != nil {
-				log.Error("Etherbase account unavailable locally", "err", err)
-				return fmt.Errorf("signer missing: %v", err)
+			key, signFn, err := s.equalitySigner(eb)
+			if err != nil {
+				return err
+			}
+			equality.Authorize(key, signFn)
+		}
+		if transition, ok := s.engine.(*equality.Transition); ok {
+			key, signFn, err := s.equalitySigner(eb)
+			if err != nil {
+				return err
 			}
-			equality.Authorize(eb, wallet.SignData)
+			transition.Authorize(key, signFn)
 		}
 		// If mining is started, we can disable the transaction rejection mechanism
 		// introduced to speed sync times.
@@ -496,6 +575,91 @@ func (s *Ethereum) StartMining(threads int) error {
 	return nil
 }
 
+// equalitySigner returns the key and the function sealing the equality blocks of
+// the etherbase, after the approval of the co-signers if any. The key is the one
+// bound to the etherbase if configured, the etherbase itself otherwise.
+func (s *Ethereum) equalitySigner(eb common.Address) (common.Address, equality.SignerFn, error) {
+	key := eb
+	if s.config.Miner.SealKey != (common.Address{}) {
+		key = s.config.Miner.SealKey
+	}
+	signFn, err := s.equalityKeySigner(key)
+	if err != nil || len(s.config.Miner.CoSigners) == 0 {
+		return key, signFn, err
+	}
+	tlsConfig := equality.RemoteSignerConfig{
+		Cert: s.config.Miner.SignerCert,
+		Key:  s.config.Miner.SignerKey,
+		CA:   s.config.Miner.SignerCA,
+	}
+	cosigners := make([]*equality.CoSigner, 0, len(s.config.Miner.CoSigners))
+	for _, spec := range s.config.Miner.CoSigners {
+		cosigner, err := equality.ParseCoSigner(spec, tlsConfig)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		cosigners = append(cosigners, cosigner)
+	}
+	gate, err := equality.NewApprovalGate(s.config.Miner.CoSignerThreshold, cosigners, signFn)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	log.Info("Sealing after co-signer approvals", "threshold", s.config.Miner.CoSignerThreshold, "cosigners", len(cosigners))
+	return key, gate.SignData, nil
+}
+
+// equalityKeySigner returns the function signing with the sealing key, either a
+// local account, the remote signer or the hardware wallet configured. Local
+// accounts are unlocked for each seal by the watchdog if a password file is set.
+func (s *Ethereum) equalityKeySigner(eb common.Address) (equality.SignerFn, error) {
+	if s.config.Miner.SignerURL != "" {
+		signer, err := equality.NewRemoteSigner(equality.RemoteSignerConfig{
+			URL:  s.config.Miner.SignerURL,
+			Cert: s.config.Miner.SignerCert,
+			Key:  s.config.Miner.SignerKey,
+			CA:   s.config.Miner.SignerCA,
+		})
+		if err != nil {
+			log.Error("Remote signer unavailable", "err", err)
+			return nil, fmt.Errorf("remote signer: %v", err)
+		}
+		log.Info("Sealing with remote signer", "url", s.config.Miner.SignerURL, "signer", eb)
+		return signer.SignData, nil
+	}
+	if s.config.Miner.SignerWallet != "" {
+		path := accounts.DefaultBaseDerivationPath
+		if s.config.Miner.SignerPath != "" {
+			var err error
+			if path, err = accounts.ParseDerivationPath(s.config.Miner.SignerPath); err != nil {
+				return nil, fmt.Errorf("invalid signer derivation path: %v", err)
+			}
+		}
+		signer, err := equality.NewDeviceSigner(s.accountManager, s.config.Miner.SignerWallet, path)
+		if err != nil {
+			log.Error("Hardware wallet unavailable", "url", s.config.Miner.SignerWallet, "err", err)
+			return nil, fmt.Errorf("hardware wallet: %v", err)
+		}
+		if signer.Address() != eb {
+			return nil, fmt.Errorf("hardware wallet key %s isn't the sealing key %s", signer.Address().Hex(), eb.Hex())
+		}
+		log.Info("Sealing with hardware wallet", "url", s.config.Miner.SignerWallet, "signer", eb)
+		return signer.SignData, nil
+	}
+	wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
+	if wallet == nil || err != nil {
+		log.Error("Etherbase account unavailable locally", "err", err)
+		return nil, fmt.Errorf("signer missing: %v", err)
+	}
+	if s.config.Miner.SignerUnlock != "" {
+		watchdog, err := s.equalityWatchdog(wallet, accounts.Account{Address: eb})
+		if err != nil {
+			return nil, err
+		}
+		return watchdog.SignData, nil
+	}
+	return wallet.SignData, nil
+}
+
 // StopMining terminates the miner, both at the consensus engine level as well as
 // at the block creation level.
 func (s *Ethereum) StopMining() {
@@ -547,6 +711,18 @@ func (s *Ethereum) Start() error {
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers(params.BloomBitsBlocks)
 
+	// Watch the local clock if validators take turns in fixed time slots
+	if config := s.blockchain.Config().Equality; config != nil {
+		go s.clockDriftLoop(config.Period)
+		go s.intentLoop()
+	}
+	if tracker, ok := s.engine.(slotTracker); ok {
+		go s.slotTrackLoop(tracker)
+	}
+	if publisher, ok := s.engine.(chainEventPublisher); ok {
+		go s.consensusEventLoop(publisher)
+	}
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
 	if s.config.LightServ > 0 {
@@ -569,6 +745,11 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	close(s.closeClockCheck)
+	close(s.closeIntents)
+	close(s.closeSlotTrack)
+	close(s.closeEvents)
+	close(s.closeWatchdog)
 	s.txPool.Stop()
 	s.miner.Stop()
 	s.blockchain.Stop()
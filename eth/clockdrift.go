@@ -0,0 +1,68 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// clockDriftCheckInterval is the time between two comparisons of the local
+// clock with the network time estimated from the connected peers.
+const clockDriftCheckInterval = time.Minute
+
+// clockDriftWarnInterval is the minimum time between two warnings about the
+// local clock drifting, the checks in between being logged at debug level.
+const clockDriftWarnInterval = 10 * time.Minute
+
+// clockDriftLoop periodically compares the local clock to the network time and
+// warns a mining node ahead of time if the drift is large enough to make it miss
+// its time slots. Slots are period seconds long, a drift of half a slot already
+// makes the node seal outside of its turn.
+func (s *Ethereum) clockDriftLoop(period uint64) {
+	limit := time.Duration(period) * time.Second / 2
+
+	ticker := time.NewTicker(clockDriftCheckInterval)
+	defer ticker.Stop()
+
+	var warned time.Time
+	for {
+		select {
+		case <-ticker.C:
+			if !s.IsMining() {
+				continue
+			}
+			offset, peers := s.p2pServer.ClockOffset()
+			if peers == 0 {
+				continue
+			}
+			if offset <= limit && offset >= -limit {
+				continue
+			}
+			logFn := log.Debug
+			if time.Since(warned) >= clockDriftWarnInterval {
+				logFn, warned = log.Warn, time.Now()
+			}
+			logFn("Local clock drifts from the network, time slots will be missed",
+				"offset", common.PrettyDuration(offset), "peers", peers, "limit", common.PrettyDuration(limit))
+		case <-s.closeClockCheck:
+			return
+		}
+	}
+}
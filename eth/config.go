@@ -70,10 +70,13 @@ var DefaultConfig = Config{
 	TrieTimeout:             60 * time.Minute,
 	SnapshotCache:           102,
 	Miner: miner.Config{
-		GasFloor: 8000000,
-		GasCeil:  8000000,
-		GasPrice: big.NewInt(params.GWei),
-		Recommit: 3 * time.Second,
+		GasFloor:  8000000,
+		GasCeil:   8000000,
+		GasPrice:  big.NewInt(params.GWei),
+		Recommit:  3 * time.Second,
+		SignGuard: "signguard.json",
+
+		ConsensusTxQuota: 4,
 	},
 	TxPool:      core.DefaultTxPoolConfig,
 	RPCGasCap:   25000000,
@@ -120,6 +123,9 @@ type Config struct {
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
+	EqualityNoPruning  bool // Whether to write the equality snapshot of every block to disk
+	EqualityHeaderOnly bool // Whether to trust the equality headers instead of replaying the snapshots
+
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
 	// Whitelist of required block number -> hash values to accept
@@ -186,4 +192,7 @@ type Config struct {
 
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"`
+
+	// OverrideEquality schedules a change of the equality parameters at startup.
+	OverrideEquality *params.EqualityOverride `toml:",omitempty"`
 }
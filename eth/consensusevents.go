@@ -0,0 +1,47 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/types"
+)
+
+// chainEventPublisher is implemented by the consensus engines posting events of
+// their own, e.g. epoch transitions, for the blocks included in the chain.
+type chainEventPublisher interface {
+	PublishChainEvent(header *types.Header)
+}
+
+// consensusEventLoop feeds the canonical blocks to the consensus engine, which
+// posts the consensus events they carry to its subscribers.
+func (s *Ethereum) consensusEventLoop(publisher chainEventPublisher) {
+	chainCh := make(chan core.ChainEvent, 10)
+	sub := s.blockchain.SubscribeChainEvent(chainCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-chainCh:
+			publisher.PublishChainEvent(ev.Block.Header())
+		case <-sub.Err():
+			return
+		case <-s.closeEvents:
+			return
+		}
+	}
+}
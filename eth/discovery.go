@@ -70,5 +70,23 @@ func (eth *Ethereum) setupDiscovery(cfg *p2p.Config) (enode.Iterator, error) {
 		return nil, nil
 	}
 	client := dnsdisc.NewClient(dnsdisc.Config{})
-	return client.NewIterator(eth.config.DiscoveryURLs...)
+	it, err := client.NewIterator(eth.config.DiscoveryURLs...)
+	if err != nil {
+		return nil, err
+	}
+	return enode.Filter(it, newNodeFilter(eth.blockchain)), nil
+}
+
+// newNodeFilter returns a filter dropping the discovered nodes which announce in
+// their ENR a fork ID incompatible with the local chain, so no bandwidth is lost
+// dialing peers on another fork schedule.
+func newNodeFilter(chain forkid.Blockchain) func(*enode.Node) bool {
+	filter := forkid.NewFilter(chain)
+	return func(n *enode.Node) bool {
+		var entry ethEntry
+		if err := n.Load(&entry); err != nil {
+			return false
+		}
+		return filter(entry.ForkID) == nil
+	}
 }
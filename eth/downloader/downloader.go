@@ -467,6 +467,14 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 	}
 	height := latest.Number.Uint64()
 
+	// Jump over the epochs the remote chain is ahead, light syncing the last one
+	if mode == WarpSync {
+		if err := d.warpHeaders(p, latest); err != nil {
+			return err
+		}
+		mode = LightSync
+		atomic.StoreUint32(&d.mode, uint32(mode))
+	}
 	origin, err := d.findAncestor(p, latest)
 	if err != nil {
 		return err
@@ -665,7 +673,7 @@ func (d *Downloader) fetchHead(p *peerConnection) (head *types.Header, pivot *ty
 			// and request. If only 1 header was returned, make sure there's no pivot
 			// or there was not one requested.
 			head := headers[0]
-			if (mode == FastSync || mode == LightSync) && head.Number.Uint64() < d.checkpoint {
+			if (mode == FastSync || mode.IsLight()) && head.Number.Uint64() < d.checkpoint {
 				return nil, nil, fmt.Errorf("%w: remote head %d below checkpoint %d", errUnsyncedPeer, head.Number, d.checkpoint)
 			}
 			if len(headers) == 1 {
@@ -1710,7 +1718,7 @@ func (d *Downloader) processFastSyncContent() error {
 	// Start syncing state of the reported head block. This should get us most of
 	// the state of the pivot block.
 	d.pivotLock.RLock()
-	sync := d.syncState(d.pivotHeader.Root)
+	sync := d.syncState(d.pivotHeader.Root, d.consensusTries(d.pivotHeader)...)
 	d.pivotLock.RUnlock()
 
 	defer func() {
@@ -1762,7 +1770,7 @@ func (d *Downloader) processFastSyncContent() error {
 		if oldPivot == nil {
 			if pivot.Root != sync.root {
 				sync.Cancel()
-				sync = d.syncState(pivot.Root)
+				sync = d.syncState(pivot.Root, d.consensusTries(pivot)...)
 
 				go closeOnErr(sync)
 			}
@@ -1800,7 +1808,7 @@ func (d *Downloader) processFastSyncContent() error {
 			// If new pivot block found, cancel old state retrieval and restart
 			if oldPivot != P {
 				sync.Cancel()
-				sync = d.syncState(P.Header.Root)
+				sync = d.syncState(P.Header.Root, d.consensusTries(P.Header)...)
 
 				go closeOnErr(sync)
 				oldPivot = P
@@ -26,10 +26,16 @@ const (
 	FullSync  SyncMode = iota // Synchronise the entire blockchain history from full blocks
 	FastSync                  // Quickly download the headers, full sync only at the chain head
 	LightSync                 // Download only the headers and terminate afterwards
+	WarpSync                  // Download only the epoch headers, then light sync the last epoch
 )
 
 func (mode SyncMode) IsValid() bool {
-	return mode >= FullSync && mode <= LightSync
+	return mode >= FullSync && mode <= WarpSync
+}
+
+// IsLight returns whether the mode is served by a light client.
+func (mode SyncMode) IsLight() bool {
+	return mode == LightSync || mode == WarpSync
 }
 
 // String implements the stringer interface.
@@ -41,6 +47,8 @@ func (mode SyncMode) String() string {
 		return "fast"
 	case LightSync:
 		return "light"
+	case WarpSync:
+		return "warp"
 	default:
 		return "unknown"
 	}
@@ -54,6 +62,8 @@ func (mode SyncMode) MarshalText() ([]byte, error) {
 		return []byte("fast"), nil
 	case LightSync:
 		return []byte("light"), nil
+	case WarpSync:
+		return []byte("warp"), nil
 	default:
 		return nil, fmt.Errorf("unknown sync mode %d", mode)
 	}
@@ -67,8 +77,10 @@ func (mode *SyncMode) UnmarshalText(text []byte) error {
 		*mode = FastSync
 	case "light":
 		*mode = LightSync
+	case "warp":
+		*mode = WarpSync
 	default:
-		return fmt.Errorf(`unknown sync mode %q, want "full", "fast" or "light"`, text)
+		return fmt.Errorf(`unknown sync mode %q, want "full", "fast", "light" or "warp"`, text)
 	}
 	return nil
 }
@@ -23,8 +23,10 @@ import (
 	"time"
 
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/ethdb"
 	"github.com/SecretBlockChain/go-secret/log"
 	"github.com/SecretBlockChain/go-secret/trie"
@@ -52,6 +54,31 @@ func (req *stateReq) timedOut() bool {
 
 // stateSyncStats is a collection of progress stats to report during a state trie
 // sync to RPC requests as well as to display in user logs.
+// consensusTries returns the roots of the tries the consensus engine keeps next
+// to the state of the given block, e.g. the equality snapshots, if any.
+func (d *Downloader) consensusTries(header *types.Header) []common.Hash {
+	type engineChain interface {
+		Engine() consensus.Engine
+	}
+	type trieEngine interface {
+		SnapshotTries(header *types.Header) ([]common.Hash, error)
+	}
+	chain, ok := d.blockchain.(engineChain)
+	if !ok {
+		return nil
+	}
+	engine, ok := chain.Engine().(trieEngine)
+	if !ok {
+		return nil
+	}
+	tries, err := engine.SnapshotTries(header)
+	if err != nil {
+		log.Warn("Failed to retrieve consensus tries", "number", header.Number, "hash", header.Hash(), "err", err)
+		return nil
+	}
+	return tries
+}
+
 type stateSyncStats struct {
 	processed  uint64 // Number of state entries processed
 	duplicate  uint64 // Number of state entries downloaded twice
@@ -59,10 +86,11 @@ type stateSyncStats struct {
 	pending    uint64 // Number of still pending state entries
 }
 
-// syncState starts downloading state with the given root hash.
-func (d *Downloader) syncState(root common.Hash) *stateSync {
+// syncState starts downloading state with the given root hash, along with the
+// additional standalone tries given.
+func (d *Downloader) syncState(root common.Hash, tries ...common.Hash) *stateSync {
 	// Create the state sync
-	s := newStateSync(d, root)
+	s := newStateSync(d, root, tries...)
 	select {
 	case d.stateSyncStart <- s:
 		// If we tell the statesync to restart with a new root, we also need
@@ -287,10 +315,17 @@ type codeTask struct {
 
 // newStateSync creates a new state trie download scheduler. This method does not
 // yet start the sync. The user needs to call run to initiate.
-func newStateSync(d *Downloader, root common.Hash) *stateSync {
+//
+// The additional tries are scheduled next to the state trie and fetched from the
+// same node data requests, their leaves aren't interpreted.
+func newStateSync(d *Downloader, root common.Hash, tries ...common.Hash) *stateSync {
+	sched := state.NewStateSync(root, d.stateDB, d.stateBloom)
+	for _, extra := range tries {
+		sched.AddSubTrie(extra, nil, common.Hash{}, nil)
+	}
 	return &stateSync{
 		d:         d,
-		sched:     state.NewStateSync(root, d.stateDB, d.stateBloom),
+		sched:     sched,
 		keccak:    sha3.NewLegacyKeccak256(),
 		trieTasks: make(map[common.Hash]*trieTask),
 		codeTasks: make(map[common.Hash]*codeTask),
@@ -0,0 +1,132 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// errWarpUnsupported is returned if warp sync is requested for a chain which
+// can't skip the headers within the epochs.
+var errWarpUnsupported = errors.New("warp sync not supported by the chain")
+
+// WarpChain is implemented by the header chains able to skip over the epochs of
+// their consensus engine, storing the headers opening the epochs only.
+type WarpChain interface {
+	// EpochBlocks returns the numbers of the headers opening the epochs which
+	// follow the one of the local head, up to the limit and at most max of them.
+	EpochBlocks(limit uint64, max int) ([]uint64, error)
+
+	// InsertEpochHeaders verifies a batch of consecutive epoch headers following
+	// the epoch of the local head and makes the last one the new head.
+	InsertEpochHeaders(headers []*types.Header) error
+}
+
+// warpHeaders advances the local header chain to the last epoch header of the
+// remote chain below the given head, fetching and verifying the epoch headers
+// only. The headers of the last epoch are left to the light sync following.
+func (d *Downloader) warpHeaders(p *peerConnection, latest *types.Header) error {
+	chain, ok := d.lightchain.(WarpChain)
+	if !ok {
+		return errWarpUnsupported
+	}
+	var (
+		start  = time.Now()
+		warped int
+	)
+	for {
+		numbers, err := chain.EpochBlocks(latest.Number.Uint64(), MaxHeaderFetch)
+		if err != nil {
+			return err
+		}
+		if len(numbers) == 0 {
+			break
+		}
+		// Request the longest run of evenly spaced epoch headers at once
+		count, skip := 1, 0
+		if len(numbers) > 1 {
+			skip = int(numbers[1] - numbers[0] - 1)
+			for count < len(numbers) && numbers[count]-numbers[count-1] == numbers[1]-numbers[0] {
+				count++
+			}
+		}
+		headers, err := d.fetchEpochHeaders(p, numbers[0], count, skip)
+		if err != nil {
+			return err
+		}
+		for i, header := range headers {
+			if header.Number.Uint64() != numbers[i] {
+				return fmt.Errorf("%w: epoch header %d, requested %d", errBadPeer, header.Number, numbers[i])
+			}
+		}
+		if err := chain.InsertEpochHeaders(headers); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidChain, err)
+		}
+		warped += len(headers)
+
+		last := headers[len(headers)-1]
+		log.Info("Warped over epochs", "count", len(headers), "number", last.Number, "hash", last.Hash(), "age", common.PrettyAge(time.Unix(int64(last.Time), 0)))
+	}
+	if warped > 0 {
+		log.Info("Warp sync finished", "epochs", warped, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// fetchEpochHeaders retrieves a run of evenly spaced headers from the peer.
+func (d *Downloader) fetchEpochHeaders(p *peerConnection, from uint64, count, skip int) ([]*types.Header, error) {
+	p.log.Debug("Retrieving epoch headers", "from", from, "count", count, "skip", skip)
+	go p.peer.RequestHeadersByNumber(from, count, skip, false)
+
+	ttl := d.requestTTL()
+	timeout := time.After(ttl)
+	for {
+		select {
+		case <-d.cancelCh:
+			return nil, errCanceled
+
+		case packet := <-d.headerCh:
+			// Discard anything not from the origin peer
+			if packet.PeerId() != p.id {
+				log.Debug("Received headers from incorrect peer", "peer", packet.PeerId())
+				break
+			}
+			headers := packet.(*headerPack).headers
+			if len(headers) == 0 {
+				return nil, fmt.Errorf("%w: no epoch headers from %d", errStallingPeer, from)
+			}
+			if len(headers) > count {
+				return nil, fmt.Errorf("%w: returned headers %d > requested %d", errBadPeer, len(headers), count)
+			}
+			return headers, nil
+
+		case <-timeout:
+			p.log.Debug("Waiting for epoch headers timed out", "elapsed", ttl)
+			return nil, errTimeout
+
+		case <-d.bodyCh:
+		case <-d.receiptCh:
+			// Out of bounds delivery, ignore
+		}
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/SecretBlockChain/go-secret/eth/fetcher"
 	"github.com/SecretBlockChain/go-secret/ethdb"
 	"github.com/SecretBlockChain/go-secret/event"
+	"github.com/SecretBlockChain/go-secret/light"
 	"github.com/SecretBlockChain/go-secret/log"
 	"github.com/SecretBlockChain/go-secret/p2p"
 	"github.com/SecretBlockChain/go-secret/p2p/enode"
@@ -78,6 +79,7 @@ type ProtocolManager struct {
 	downloader   *downloader.Downloader
 	blockFetcher *fetcher.BlockFetcher
 	txFetcher    *fetcher.TxFetcher
+	snapshots    *snapshotFetcher // Consensus snapshot fetcher, nil if the engine has no snapshots
 	peers        *peerSet
 
 	eventMux      *event.TypeMux
@@ -142,7 +144,7 @@ func NewProtocolManager(config *params.ChainConfig, checkpoint *params.TrustedCh
 
 	// If we have trusted checkpoints, enforce them on the chain
 	if checkpoint != nil {
-		manager.checkpointNumber = (checkpoint.SectionIndex+1)*params.CHTFrequency - 1
+		manager.checkpointNumber = (checkpoint.SectionIndex+1)*light.ServerIndexerConfig(config).ChtSize - 1
 		manager.checkpointHash = checkpoint.SectionHead
 	}
 
@@ -155,6 +157,10 @@ func NewProtocolManager(config *params.ChainConfig, checkpoint *params.TrustedCh
 	}
 	manager.downloader = downloader.New(manager.checkpointNumber, chaindb, stateBloom, manager.eventMux, blockchain, nil, manager.removePeer)
 
+	// Let the consensus engine fetch the snapshots it misses from the peers
+	if engine, ok := engine.(snapshotEngine); ok {
+		manager.snapshots = newSnapshotFetcher(engine, manager.peers, manager.quitSync)
+	}
 	// Construct the fetcher (short sync)
 	validator := func(header *types.Header) error {
 		return engine.VerifyHeader(blockchain, header, true)
@@ -685,6 +691,43 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			log.Debug("Failed to deliver receipts", "err", err)
 		}
 
+	case msg.Code == GetConsensusSnapshotMsg:
+		// Decode the retrieval message
+		var hashes []common.Hash
+		if err := msg.Decode(&hashes); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		// Gather the snapshots until the fetch or network limits is reached,
+		// leaving the unavailable ones empty to keep the order of the request
+		var (
+			bytes int
+			data  [][]byte
+		)
+		for _, hash := range hashes {
+			if bytes >= softResponseLimit || len(data) >= maxConsensusSnapshotFetch {
+				break
+			}
+			var entry []byte
+			if pm.snapshots != nil {
+				if header := pm.blockchain.GetHeaderByHash(hash); header != nil {
+					entry, _ = pm.snapshots.engine.ExportSnapshot(header)
+				}
+			}
+			data = append(data, entry)
+			bytes += len(entry)
+		}
+		return p.SendConsensusSnapshots(data)
+
+	case msg.Code == ConsensusSnapshotMsg:
+		// A batch of consensus snapshots arrived to one of our previous requests
+		var data [][]byte
+		if err := msg.Decode(&data); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		if pm.snapshots != nil {
+			pm.snapshots.deliver(p.id, data)
+		}
+
 	case msg.Code == NewBlockHashesMsg:
 		var announces newBlockHashesData
 		if err := msg.Decode(&announces); err != nil {
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/consensus/ethash"
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
@@ -426,6 +428,58 @@ func testGetReceipt(t *testing.T, protocol int) {
 	}
 }
 
+// testSnapshotEngine serves a fixed consensus snapshot for the genesis block.
+type testSnapshotEngine struct {
+	imported []byte
+}
+
+func (e *testSnapshotEngine) ExportSnapshot(header *types.Header) ([]byte, error) {
+	if header.Number.Sign() != 0 {
+		return nil, errors.New("snapshot unavailable")
+	}
+	return []byte("genesis"), nil
+}
+
+func (e *testSnapshotEngine) ImportSnapshot(header *types.Header, data []byte) error {
+	if header.Number.Sign() != 0 || string(data) != "genesis" {
+		return errors.New("snapshot mismatch")
+	}
+	e.imported = data
+	return nil
+}
+
+func (e *testSnapshotEngine) SetSnapshotFetcher(fetch equality.SnapshotFetcher) {}
+
+// Tests that consensus snapshots can be retrieved from a remote chain.
+func TestConsensusSnapshots(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 4, nil, nil)
+	engine := new(testSnapshotEngine)
+	pm.snapshots = newSnapshotFetcher(engine, pm.peers, pm.quitSync)
+
+	peer, _ := newTestPeer("peer", 64, pm, true)
+	defer peer.close()
+
+	// Unavailable snapshots are left empty in the response
+	genesis := pm.blockchain.Genesis().Header()
+	p2p.Send(peer.app, GetConsensusSnapshotMsg, []common.Hash{genesis.Hash(), pm.blockchain.CurrentHeader().Hash()})
+	if err := p2p.ExpectMsg(peer.app, ConsensusSnapshotMsg, [][]byte{[]byte("genesis"), nil}); err != nil {
+		t.Fatalf("snapshots mismatch: %v", err)
+	}
+	// Fetch the snapshot from the remote peer and import it
+	go func() {
+		if err := p2p.ExpectMsg(peer.app, GetConsensusSnapshotMsg, []common.Hash{genesis.Hash()}); err != nil {
+			t.Errorf("request mismatch: %v", err)
+		}
+		p2p.Send(peer.app, ConsensusSnapshotMsg, [][]byte{[]byte("genesis")})
+	}()
+	if err := pm.snapshots.fetch(genesis); err != nil {
+		t.Fatalf("failed to fetch snapshot: %v", err)
+	}
+	if string(engine.imported) != "genesis" {
+		t.Fatalf("snapshot not imported: %q", engine.imported)
+	}
+}
+
 // Tests that post eth protocol handshake, clients perform a mutual checkpoint
 // challenge to validate each other's chains. Hash mismatches, or missing ones
 // during a fast sync should lead to the peer getting dropped.
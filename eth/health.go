@@ -0,0 +1,98 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core/types"
+)
+
+// healthEngine is implemented by the consensus engines whose validators take
+// turns in fixed time slots, reporting the status of the local validator.
+type healthEngine interface {
+	NodeStatus(header *types.Header) (equality.NodeStatus, error)
+	NextSlot(header *types.Header, now uint64) (uint64, error)
+	SnapshotAvailable(header *types.Header) bool
+}
+
+// Health is the status of the node reported to load balancers and liveness
+// probes. A node is healthy once synced with an intact consensus snapshot, the
+// validator fields being informational.
+type Health struct {
+	Healthy          bool           `json:"healthy"`
+	Synced           bool           `json:"synced"`
+	Head             uint64         `json:"head"`
+	SnapshotIntact   bool           `json:"snapshotIntact"`
+	SignerConfigured bool           `json:"signerConfigured"`
+	Signer           common.Address `json:"signer"`
+	Validator        bool           `json:"validator"`          // Whether the signer is in the validator set
+	NextSlot         *uint64        `json:"nextSlot,omitempty"` // Seconds to the next slot of the signer
+}
+
+// health assembles the health status of the node at the current head.
+func (s *Ethereum) health() *Health {
+	head := s.blockchain.CurrentHeader()
+	health := &Health{
+		Synced:         s.Synced(),
+		Head:           head.Number.Uint64(),
+		SnapshotIntact: true,
+	}
+	if engine, ok := s.engine.(healthEngine); ok {
+		health.SnapshotIntact = engine.SnapshotAvailable(head)
+		if status, err := engine.NodeStatus(head); err == nil {
+			health.SignerConfigured = status.Signer != (common.Address{})
+			health.Signer = status.Signer
+			health.Validator = status.Role == equality.RoleActive
+		}
+		if health.Validator {
+			now := uint64(time.Now().Unix())
+			if slot, err := engine.NextSlot(head, now); err == nil {
+				wait := slot - now
+				health.NextSlot = &wait
+			}
+		}
+	}
+	health.Healthy = health.Synced && health.SnapshotIntact
+	return health
+}
+
+// healthHandler serves the health status of the node over HTTP, answering with
+// 503 Service Unavailable while unhealthy. Validator deployments can also require
+// the signer to be in the validator set with the "validator" query parameter.
+type healthHandler struct {
+	eth *Ethereum
+}
+
+func (h healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	health := h.eth.health()
+	status := http.StatusOK
+	if !health.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	if _, ok := r.URL.Query()["validator"]; ok && !health.Validator {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(health)
+}
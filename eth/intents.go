@@ -0,0 +1,190 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// Processing status of a queued consensus intent.
+const (
+	intentPending   uint8 = iota // Waiting for its trigger condition
+	intentSubmitted              // Transaction sent to the pool, waiting for inclusion
+	intentIncluded               // Transaction included in the canonical chain
+	intentFailed                 // Transaction could not be created or submitted
+)
+
+var intentStatusNames = map[uint8]string{
+	intentPending:   "pending",
+	intentSubmitted: "submitted",
+	intentIncluded:  "included",
+	intentFailed:    "failed",
+}
+
+// intentActions maps the supported intent actions to the custom transaction
+// performing them.
+var intentActions = map[string]equality.Transaction{
+	"register": new(equality.EventBecomeCandidate),
+	"cancel":   new(equality.EventCancelCandidate),
+}
+
+var (
+	errUnknownIntent       = errors.New("unknown intent")
+	errUnknownIntentAction = errors.New("unknown intent action")
+	errIntentNotPending    = errors.New("intent already processed")
+)
+
+// intentDue reports whether the trigger condition of an intent is met by a
+// chain head of the given epoch and timestamp.
+func intentDue(intent *rawdb.ConsensusIntent, epoch, time uint64) bool {
+	return epoch >= intent.Epoch && time >= intent.Time
+}
+
+// enqueueIntent validates and persists a new consensus intent.
+func (s *Ethereum) enqueueIntent(intent *rawdb.ConsensusIntent) error {
+	if _, ok := intentActions[intent.Action]; !ok {
+		return errUnknownIntentAction
+	}
+	if _, err := s.accountManager.Find(accounts.Account{Address: intent.Account}); err != nil {
+		return err
+	}
+	s.intentLock.Lock()
+	defer s.intentLock.Unlock()
+
+	intent.ID = 0
+	if intents := rawdb.ReadConsensusIntents(s.chainDb); len(intents) > 0 {
+		intent.ID = intents[len(intents)-1].ID + 1
+	}
+	intent.Status, intent.TxHash, intent.Error = intentPending, common.Hash{}, ""
+	rawdb.WriteConsensusIntent(s.chainDb, intent)
+
+	log.Info("Queued consensus intent", "id", intent.ID, "action", intent.Action, "account", intent.Account,
+		"epoch", intent.Epoch, "time", intent.Time)
+	return nil
+}
+
+// removeIntent drops a consensus intent which wasn't submitted yet.
+func (s *Ethereum) removeIntent(id uint64) error {
+	s.intentLock.Lock()
+	defer s.intentLock.Unlock()
+
+	intent := rawdb.ReadConsensusIntent(s.chainDb, id)
+	if intent == nil {
+		return errUnknownIntent
+	}
+	if intent.Status != intentPending {
+		return errIntentNotPending
+	}
+	rawdb.DeleteConsensusIntent(s.chainDb, id)
+	return nil
+}
+
+// intentLoop submits the queued consensus intents as their trigger conditions
+// are met by the chain head, and tracks the inclusion of the transactions.
+func (s *Ethereum) intentLoop() {
+	headCh := make(chan core.ChainHeadEvent, 10)
+	sub := s.blockchain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case head := <-headCh:
+			if !s.Synced() {
+				continue
+			}
+			s.processIntents(head.Block.Header())
+		case <-sub.Err():
+			return
+		case <-s.closeIntents:
+			return
+		}
+	}
+}
+
+// processIntents advances the queued consensus intents against a new head.
+func (s *Ethereum) processIntents(head *types.Header) {
+	headerExtra, err := equality.DecodeHeaderExtra(head)
+	if err != nil {
+		log.Warn("Failed to decode head extra-data", "number", head.Number, "err", err)
+		return
+	}
+	s.intentLock.Lock()
+	defer s.intentLock.Unlock()
+
+	for _, intent := range rawdb.ReadConsensusIntents(s.chainDb) {
+		switch intent.Status {
+		case intentPending:
+			if !intentDue(intent, headerExtra.Epoch, head.Time) {
+				continue
+			}
+			if err := s.submitIntent(intent); err != nil {
+				intent.Status, intent.Error = intentFailed, err.Error()
+				log.Warn("Failed to submit consensus intent", "id", intent.ID, "action", intent.Action, "err", err)
+			} else {
+				intent.Status = intentSubmitted
+				log.Info("Submitted consensus intent", "id", intent.ID, "action", intent.Action, "tx", intent.TxHash)
+			}
+		case intentSubmitted:
+			if rawdb.ReadTxLookupEntry(s.chainDb, intent.TxHash) == nil {
+				continue
+			}
+			intent.Status = intentIncluded
+		default:
+			continue
+		}
+		rawdb.WriteConsensusIntent(s.chainDb, intent)
+	}
+}
+
+// submitIntent signs the transaction performing an intent with the account
+// manager and adds it to the local transaction pool.
+func (s *Ethereum) submitIntent(intent *rawdb.ConsensusIntent) error {
+	data := equality.EncodeTransaction(intentActions[intent.Action])
+	gas, err := core.IntrinsicGas(data, false, true, true)
+	if err != nil {
+		return err
+	}
+	price, err := s.APIBackend.SuggestPrice(context.Background())
+	if err != nil {
+		return err
+	}
+	account := accounts.Account{Address: intent.Account}
+	wallet, err := s.accountManager.Find(account)
+	if err != nil {
+		return err
+	}
+	tx := types.NewTransaction(s.txPool.Nonce(intent.Account), intent.Account, new(big.Int), gas, price, data)
+	signed, err := wallet.SignTx(account, tx, s.blockchain.Config().ChainID)
+	if err != nil {
+		return err
+	}
+	if err := s.txPool.AddLocal(signed); err != nil {
+		return err
+	}
+	intent.TxHash = signed.Hash()
+	return nil
+}
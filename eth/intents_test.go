@@ -0,0 +1,87 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/crypto"
+)
+
+func TestIntentDue(t *testing.T) {
+	tests := []struct {
+		epoch, time uint64
+		due         bool
+	}{
+		{0, 0, false},
+		{5, 0, false},
+		{0, 1000, false},
+		{5, 999, false},
+		{5, 1000, true},
+		{6, 2000, true},
+	}
+	intent := &rawdb.ConsensusIntent{Epoch: 5, Time: 1000}
+	for i, tt := range tests {
+		if due := intentDue(intent, tt.epoch, tt.time); due != tt.due {
+			t.Errorf("test %d: due mismatch: have %v, want %v", i, due, tt.due)
+		}
+	}
+}
+
+func TestIntentTransactions(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(1))
+
+	for action, prototype := range intentActions {
+		data := equality.EncodeTransaction(prototype)
+		tx, err := types.SignTx(types.NewTransaction(0, common.Address{}, new(big.Int), 0, new(big.Int), data), signer, key)
+		if err != nil {
+			t.Fatalf("%s: failed to sign transaction: %v", action, err)
+		}
+		ctx, err := equality.NewTransaction(tx)
+		if err != nil {
+			t.Fatalf("%s: failed to decode transaction: %v", action, err)
+		}
+		if ctx.Action() != prototype.Action() {
+			t.Errorf("%s: action mismatch: have %s, want %s", action, ctx.Action(), prototype.Action())
+		}
+	}
+}
+
+func TestIntentPersistence(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	for i := uint64(0); i < 3; i++ {
+		rawdb.WriteConsensusIntent(db, &rawdb.ConsensusIntent{ID: i, Action: "register", Epoch: i})
+	}
+	rawdb.DeleteConsensusIntent(db, 1)
+
+	intents := rawdb.ReadConsensusIntents(db)
+	if len(intents) != 2 || intents[0].ID != 0 || intents[1].ID != 2 {
+		t.Fatalf("unexpected intents: %v", intents)
+	}
+	if intent := rawdb.ReadConsensusIntent(db, 2); intent == nil || intent.Epoch != 2 {
+		t.Fatalf("unexpected intent: %v", intent)
+	}
+	if intent := rawdb.ReadConsensusIntent(db, 1); intent != nil {
+		t.Fatalf("deleted intent retrieved: %v", intent)
+	}
+}
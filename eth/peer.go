@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
@@ -515,6 +516,12 @@ func (p *peer) SendReceiptsRLP(receipts []rlp.RawValue) error {
 	return p2p.Send(p.rw, ReceiptsMsg, receipts)
 }
 
+// SendConsensusSnapshots sends a batch of serialized consensus snapshots,
+// corresponding to the blocks requested.
+func (p *peer) SendConsensusSnapshots(data [][]byte) error {
+	return p2p.Send(p.rw, ConsensusSnapshotMsg, data)
+}
+
 // RequestOneHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *peer) RequestOneHeader(hash common.Hash) error {
@@ -562,6 +569,13 @@ func (p *peer) RequestTxs(hashes []common.Hash) error {
 	return p2p.Send(p.rw, GetPooledTransactionsMsg, hashes)
 }
 
+// RequestConsensusSnapshots fetches the consensus snapshots committed in the
+// headers of the given blocks from a remote node.
+func (p *peer) RequestConsensusSnapshots(hashes []common.Hash) error {
+	p.Log().Debug("Fetching consensus snapshots", "count", len(hashes))
+	return p2p.Send(p.rw, GetConsensusSnapshotMsg, hashes)
+}
+
 // Handshake executes the eth protocol handshake, negotiating version number,
 // network IDs, difficulties, head and genesis blocks.
 func (p *peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
@@ -809,6 +823,24 @@ func (ps *peerSet) BestPeer() *peer {
 	return bestPeer
 }
 
+// PeersByTd retrieves the list of peers sorted by their total difficulty, the
+// highest first.
+func (ps *peerSet) PeersByTd() []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		_, tdi := list[i].Head()
+		_, tdj := list[j].Head()
+		return tdi.Cmp(tdj) > 0
+	})
+	return list
+}
+
 // Close disconnects all peers.
 // No new peers can be registered after Close has returned.
 func (ps *peerSet) Close() {
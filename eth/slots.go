@@ -0,0 +1,53 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/types"
+)
+
+// slotTracker is implemented by the consensus engines whose validators take turns
+// in fixed time slots, counting the slots left empty.
+type slotTracker interface {
+	TrackSlots(chain consensus.ChainHeaderReader, header *types.Header) []common.Address
+}
+
+// slotTrackLoop feeds the new chain heads to the consensus engine, which reports
+// the validators missing their slots. Heads imported while syncing are skipped,
+// the slots missed long ago being of no interest.
+func (s *Ethereum) slotTrackLoop(tracker slotTracker) {
+	headCh := make(chan core.ChainHeadEvent, 10)
+	sub := s.blockchain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case head := <-headCh:
+			if !s.Synced() {
+				continue
+			}
+			tracker.TrackSlots(s.blockchain, head.Block.Header())
+		case <-sub.Err():
+			return
+		case <-s.closeSlotTrack:
+			return
+		}
+	}
+}
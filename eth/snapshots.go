@@ -0,0 +1,148 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+const (
+	maxConsensusSnapshotFetch = 4                // Amount of consensus snapshots served in a single response
+	snapshotFetchPeers        = 4                // Amount of peers asked for a consensus snapshot before giving up
+	snapshotFetchTimeout      = 30 * time.Second // Time allowance for a peer to serve a consensus snapshot
+)
+
+var (
+	errNoSnapshotPeer  = errors.New("no peer served the consensus snapshot")
+	errSnapshotBusy    = errors.New("consensus snapshot already requested from peer")
+	errSnapshotTimeout = errors.New("consensus snapshot request timed out")
+)
+
+// snapshotEngine is implemented by the consensus engines whose snapshots can be
+// exchanged between the peers, sparing the nodes missing them the replay of the
+// chain.
+type snapshotEngine interface {
+	ExportSnapshot(header *types.Header) ([]byte, error)
+	ImportSnapshot(header *types.Header, data []byte) error
+	SetSnapshotFetcher(fetch equality.SnapshotFetcher)
+}
+
+// snapshotFetcher retrieves the consensus snapshots of the epoch blocks from the
+// peers on behalf of the consensus engine.
+type snapshotFetcher struct {
+	engine snapshotEngine
+	peers  *peerSet
+	quit   chan struct{}
+
+	pending map[string]chan [][]byte // Delivery channels of the requests in flight, by peer
+	lock    sync.Mutex
+}
+
+// newSnapshotFetcher creates a consensus snapshot fetcher, hooking it into the
+// consensus engine.
+func newSnapshotFetcher(engine snapshotEngine, peers *peerSet, quit chan struct{}) *snapshotFetcher {
+	f := &snapshotFetcher{
+		engine:  engine,
+		peers:   peers,
+		quit:    quit,
+		pending: make(map[string]chan [][]byte),
+	}
+	engine.SetSnapshotFetcher(f.fetch)
+	return f
+}
+
+// fetch retrieves the consensus snapshot of the header from the best peers and
+// imports it, trying the next peer if one fails to serve a valid snapshot.
+func (f *snapshotFetcher) fetch(header *types.Header) error {
+	peers := f.peers.PeersByTd()
+	if len(peers) > snapshotFetchPeers {
+		peers = peers[:snapshotFetchPeers]
+	}
+	for _, p := range peers {
+		data, err := f.request(p, header.Hash())
+		if err != nil {
+			p.Log().Debug("Failed to retrieve consensus snapshot", "number", header.Number, "err", err)
+			continue
+		}
+		if err := f.engine.ImportSnapshot(header, data); err != nil {
+			p.Log().Warn("Invalid consensus snapshot", "number", header.Number, "hash", header.Hash(), "err", err)
+			continue
+		}
+		return nil
+	}
+	return errNoSnapshotPeer
+}
+
+// request asks the peer for the consensus snapshot of a block and waits for the
+// reply.
+func (f *snapshotFetcher) request(p *peer, hash common.Hash) ([]byte, error) {
+	ch := make(chan [][]byte, 1)
+
+	f.lock.Lock()
+	if _, ok := f.pending[p.id]; ok {
+		f.lock.Unlock()
+		return nil, errSnapshotBusy
+	}
+	f.pending[p.id] = ch
+	f.lock.Unlock()
+
+	defer func() {
+		f.lock.Lock()
+		if f.pending[p.id] == ch {
+			delete(f.pending, p.id)
+		}
+		f.lock.Unlock()
+	}()
+	if err := p.RequestConsensusSnapshots([]common.Hash{hash}); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(snapshotFetchTimeout)
+	defer timeout.Stop()
+
+	select {
+	case data := <-ch:
+		if len(data) != 1 || len(data[0]) == 0 {
+			return nil, errNoSnapshotPeer
+		}
+		return data[0], nil
+	case <-timeout.C:
+		return nil, errSnapshotTimeout
+	case <-f.quit:
+		return nil, errNoSnapshotPeer
+	}
+}
+
+// deliver hands the consensus snapshots received from a peer to the request in
+// flight, if any.
+func (f *snapshotFetcher) deliver(peer string, data [][]byte) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if ch, ok := f.pending[peer]; ok {
+		delete(f.pending, peer)
+		ch <- data
+	} else {
+		log.Debug("Unrequested consensus snapshots", "peer", peer, "count", len(data))
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/SecretBlockChain/go-secret/accounts"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/log"
+)
+
+// equalityWatchdog returns the watchdog sealing with the local account, armed
+// with the password of the unlock file. The watchdog is kept across the restarts
+// of the miner, remembering the blocks it sealed.
+func (s *Ethereum) equalityWatchdog(wallet accounts.Wallet, account accounts.Account) (*equality.Watchdog, error) {
+	text, err := ioutil.ReadFile(s.config.Miner.SignerUnlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signer password: %v", err)
+	}
+	password := strings.TrimRight(strings.Split(string(text), "\n")[0], "\r")
+
+	// Reject a wrong password right away rather than at the first seal
+	if _, err := wallet.SignTextWithPassphrase(account, password, []byte("equality watchdog")); err != nil {
+		return nil, fmt.Errorf("failed to unlock signer: %v", err)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.watchdog != nil && s.watchdog.Address() == account.Address {
+		s.watchdog.Arm(password)
+		return s.watchdog, nil
+	}
+	first := s.watchdog == nil
+	s.watchdog = equality.NewWatchdog(wallet, account, password, func(err error) {
+		log.Error("Sealing stopped, restart mining once the node is safe to seal", "err", err)
+		s.StopMining()
+	})
+	if first {
+		go s.watchdogLoop()
+	}
+	log.Info("Sealing with supervised unlock", "signer", account.Address)
+	return s.watchdog, nil
+}
+
+// watchdogLoop feeds the new chain heads to the sealing watchdog, tripping it
+// when the head diverges.
+func (s *Ethereum) watchdogLoop() {
+	headCh := make(chan core.ChainHeadEvent, 10)
+	sub := s.blockchain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case head := <-headCh:
+			s.lock.RLock()
+			watchdog := s.watchdog
+			s.lock.RUnlock()
+			watchdog.ChainHead(s.blockchain, head.Block.Header())
+		case <-sub.Err():
+			return
+		case <-s.closeWatchdog:
+			return
+		}
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/mclock"
 	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/eth"
@@ -81,6 +82,12 @@ type fullNodeBackend interface {
 	SuggestPrice(ctx context.Context) (*big.Int, error)
 }
 
+// consensusBackend is implemented by the consensus engines of Secret nodes to
+// report the status of the local validator.
+type consensusBackend interface {
+	NodeStatus(header *types.Header) (equality.NodeStatus, error)
+}
+
 // Service implements an Ethereum netstats reporting daemon that pushes local
 // chain statistics up to a monitoring server.
 type Service struct {
@@ -736,6 +743,37 @@ type nodeStats struct {
 	Peers    int  `json:"peers"`
 	GasPrice int  `json:"gasPrice"`
 	Uptime   int  `json:"uptime"`
+
+	Consensus *consensusStats `json:"consensus,omitempty"`
+}
+
+// consensusStats is the information to report about the local validator of a
+// Secret node.
+type consensusStats struct {
+	Epoch      uint64         `json:"epoch"`
+	Validator  common.Address `json:"validator"`
+	Status     string         `json:"status"` // active, candidate or none
+	LastSealed uint64         `json:"lastSealed"`
+}
+
+// assembleConsensusStats retrieves the status of the local validator at the given
+// block, nil if the node doesn't run a Secret consensus engine.
+func (s *Service) assembleConsensusStats(header *types.Header) *consensusStats {
+	engine, ok := s.engine.(consensusBackend)
+	if !ok || header == nil {
+		return nil
+	}
+	status, err := engine.NodeStatus(header)
+	if err != nil {
+		log.Debug("Failed to retrieve consensus status", "number", header.Number, "err", err)
+		return nil
+	}
+	return &consensusStats{
+		Epoch:      status.Epoch,
+		Validator:  status.Signer,
+		Status:     status.Role,
+		LastSealed: status.LastSealed,
+	}
 }
 
 // reportStats retrieves various stats about the node at the networking and
@@ -769,13 +807,14 @@ func (s *Service) reportStats(conn *connWrapper) error {
 	stats := map[string]interface{}{
 		"id": s.node,
 		"stats": &nodeStats{
-			Active:   true,
-			Mining:   mining,
-			Hashrate: hashrate,
-			Peers:    s.server.PeerCount(),
-			GasPrice: gasprice,
-			Syncing:  syncing,
-			Uptime:   100,
+			Active:    true,
+			Mining:    mining,
+			Hashrate:  hashrate,
+			Peers:     s.server.PeerCount(),
+			GasPrice:  gasprice,
+			Syncing:   syncing,
+			Uptime:    100,
+			Consensus: s.assembleConsensusStats(s.backend.CurrentHeader()),
 		},
 	}
 	report := map[string][]interface{}{
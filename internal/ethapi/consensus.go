@@ -0,0 +1,74 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"math/big"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+)
+
+// consensusOverride is a consensus reader answering for the accounts whose
+// consensus view is overridden in a message call, the others being read from
+// the consensus state of the block.
+type consensusOverride struct {
+	vm.ConsensusReader
+	overrides map[common.Address]account
+}
+
+// overrideConsensus wraps the consensus reader of a message call if any of the
+// overrides stubs the consensus view of its account. Chains without consensus
+// state have nothing to stub.
+func overrideConsensus(reader vm.ConsensusReader, overrides map[common.Address]account) vm.ConsensusReader {
+	if reader == nil {
+		return nil
+	}
+	for _, account := range overrides {
+		if account.Validator != nil || account.Candidate != nil {
+			return &consensusOverride{ConsensusReader: reader, overrides: overrides}
+		}
+	}
+	return reader
+}
+
+// IsValidator implements vm.ConsensusReader, returning the stubbed status of the
+// overridden validators.
+func (c *consensusOverride) IsValidator(address common.Address) (bool, error) {
+	if validator := c.overrides[address].Validator; validator != nil {
+		return *validator, nil
+	}
+	return c.ConsensusReader.IsValidator(address)
+}
+
+// Candidate implements vm.ConsensusReader. The accounts stubbed as candidates
+// keep their deposit if they are candidates already, staking the minimum
+// candidate balance otherwise.
+func (c *consensusOverride) Candidate(address common.Address) (*big.Int, error) {
+	candidate := c.overrides[address].Candidate
+	if candidate == nil {
+		return c.ConsensusReader.Candidate(address)
+	}
+	if !*candidate {
+		return nil, nil
+	}
+	staked, err := c.ConsensusReader.Candidate(address)
+	if err != nil || staked != nil {
+		return staked, err
+	}
+	return c.ConsensusReader.MinCandidateBalance()
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/common/hexutil"
+	"github.com/SecretBlockChain/go-secret/core"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/core/state"
+	"github.com/SecretBlockChain/go-secret/core/types"
+	"github.com/SecretBlockChain/go-secret/core/vm"
+	"github.com/SecretBlockChain/go-secret/params"
+	"github.com/SecretBlockChain/go-secret/rpc"
+)
+
+// testConsensusReader is a consensus state of fixed validators and candidates.
+type testConsensusReader struct {
+	validators map[common.Address]bool
+	candidates map[common.Address]*big.Int
+}
+
+func (r *testConsensusReader) Epoch() (uint64, uint64, error) { return 3, 100, nil }
+
+func (r *testConsensusReader) IsValidator(address common.Address) (bool, error) {
+	return r.validators[address], nil
+}
+
+func (r *testConsensusReader) Candidate(address common.Address) (*big.Int, error) {
+	return r.candidates[address], nil
+}
+
+func (r *testConsensusReader) MinCandidateBalance() (*big.Int, error) { return big.NewInt(1000), nil }
+
+func (r *testConsensusReader) EpochStatus(address common.Address, epoch uint64) (uint64, *big.Int, bool, error) {
+	return 100, r.candidates[address], r.validators[address], nil
+}
+
+// consensusBackend runs the message calls over an empty state, the consensus
+// precompile and the staking contract reading the test consensus state.
+type consensusBackend struct {
+	Backend
+	config *params.ChainConfig
+	header *types.Header
+	reader vm.ConsensusReader
+}
+
+func newConsensusBackend(reader vm.ConsensusReader) *consensusBackend {
+	config := *params.TestChainConfig
+	config.ConsensusPrecompileBlock = big.NewInt(0)
+	config.StakingBlock = big.NewInt(0)
+	return &consensusBackend{
+		config: &config,
+		header: &types.Header{Number: big.NewInt(110), GasLimit: 8000000, Difficulty: new(big.Int)},
+		reader: reader,
+	}
+}
+
+func (b *consensusBackend) RPCGasCap() uint64 { return 25000000 }
+
+func (b *consensusBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return statedb, b.header, err
+}
+
+func (b *consensusBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
+	return types.NewBlockWithHeader(b.header), nil
+}
+
+func (b *consensusBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmConfig *vm.Config) (*vm.EVM, func() error, error) {
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Origin:      msg.From(),
+		BlockNumber: new(big.Int).Set(header.Number),
+		Time:        new(big.Int),
+		Difficulty:  new(big.Int),
+		GasLimit:    header.GasLimit,
+		GasPrice:    new(big.Int).Set(msg.GasPrice()),
+		Consensus:   b.reader,
+	}
+	return vm.NewEVM(context, state, b.config, *vmConfig), func() error { return nil }, nil
+}
+
+func TestOverrideConsensus(t *testing.T) {
+	var (
+		validator = common.HexToAddress("0x44d1ce0b7cb3588bca96151fe1bc05af38f91b6c")
+		candidate = common.HexToAddress("0xcc7c8317b21e1cea6139700c3c46c21af998d14c")
+		sender    = common.HexToAddress("0x47746e8acb5dafe9c00b7195d0c2d830fcc04910")
+		yes, no   = true, false
+		balance   = (*hexutil.Big)(big.NewInt(params.Ether))
+	)
+	reader := &testConsensusReader{
+		validators: map[common.Address]bool{validator: true},
+		candidates: map[common.Address]*big.Int{candidate: big.NewInt(2000)},
+	}
+	api := NewPublicBlockChainAPI(newConsensusBackend(reader))
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	// Overrides of the balance, code or storage only leave the reader untouched
+	funded := map[common.Address]account{sender: {Balance: &balance}}
+	if overrideConsensus(reader, funded) != vm.ConsensusReader(reader) {
+		t.Error("reader wrapped without consensus overrides")
+	}
+	if overrideConsensus(reader, nil) != vm.ConsensusReader(reader) {
+		t.Error("reader wrapped without overrides")
+	}
+	if overrideConsensus(nil, map[common.Address]account{sender: {Validator: &yes}}) != nil {
+		t.Error("missing consensus state stubbed")
+	}
+
+	// The consensus precompile reports the stubbed validators
+	active := func(address common.Address, overrides map[common.Address]account) bool {
+		data := hexutil.Bytes(common.LeftPadBytes(address.Bytes(), 32))
+		args := CallArgs{To: &params.ConsensusPrecompileAddress, Data: &data}
+		output, err := api.Call(context.Background(), args, latest, &overrides)
+		if err != nil {
+			t.Fatalf("failed to call the consensus precompile: %v", err)
+		}
+		if len(output) != 96 {
+			t.Fatalf("output length mismatch: have %d, want 96", len(output))
+		}
+		return output[95] == 1
+	}
+	if !active(validator, funded) || active(sender, funded) {
+		t.Error("validators misreported without consensus overrides")
+	}
+	if !active(sender, map[common.Address]account{sender: {Validator: &yes}}) {
+		t.Error("validator stub ignored")
+	}
+	if active(validator, map[common.Address]account{validator: {Validator: &no}}) {
+		t.Error("validator removal stub ignored")
+	}
+	if !active(validator, map[common.Address]account{sender: {Validator: &yes}}) {
+		t.Error("validator stub leaked to another account")
+	}
+
+	// Gas estimates of the staking contract follow the stubbed candidates
+	estimate := func(from common.Address, selector []byte, value int64, overrides map[common.Address]account) error {
+		data := hexutil.Bytes(selector)
+		args := CallArgs{From: &from, To: &params.StakingContractAddress, Data: &data, Value: (*hexutil.Big)(big.NewInt(value))}
+		_, err := api.EstimateGas(context.Background(), args, &latest, &overrides)
+		return err
+	}
+	if err := estimate(sender, vm.BecomeCandidateSelector, 1000, funded); err != nil {
+		t.Errorf("registration rejected without consensus overrides: %v", err)
+	}
+	if err := estimate(sender, vm.BecomeCandidateSelector, 1000, map[common.Address]account{sender: {Balance: &balance, Candidate: &yes}}); err == nil {
+		t.Error("registration of a stubbed candidate accepted")
+	}
+	if err := estimate(sender, vm.CancelCandidateSelector, 0, map[common.Address]account{sender: {Candidate: &yes}}); err != nil {
+		t.Errorf("cancellation of a stubbed candidate rejected: %v", err)
+	}
+	if err := estimate(candidate, vm.CancelCandidateSelector, 0, nil); err != nil {
+		t.Errorf("cancellation rejected without overrides: %v", err)
+	}
+	if err := estimate(candidate, vm.CancelCandidateSelector, 0, map[common.Address]account{candidate: {Candidate: &no}}); err == nil {
+		t.Error("cancellation of a stubbed non-candidate accepted")
+	}
+}
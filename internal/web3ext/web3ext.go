@@ -170,6 +170,16 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'addBootnode',
+			call: 'admin_addBootnode',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeBootnode',
+			call: 'admin_removeBootnode',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -208,6 +218,10 @@ web3._extend({
 		}),
 	],
 	properties: [
+		new web3._extend.Property({
+			name: 'bootnodes',
+			getter: 'admin_bootnodes'
+		}),
 		new web3._extend.Property({
 			name: 'nodeInfo',
 			getter: 'admin_nodeInfo'
@@ -17,14 +17,20 @@
 package les
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/hexutil"
 	"github.com/SecretBlockChain/go-secret/common/mclock"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core/types"
 	lps "github.com/SecretBlockChain/go-secret/les/lespay/server"
+	"github.com/SecretBlockChain/go-secret/light"
 	"github.com/SecretBlockChain/go-secret/p2p/enode"
+	"github.com/SecretBlockChain/go-secret/rpc"
 )
 
 var (
@@ -32,6 +38,7 @@ var (
 	errNotActivated         = errors.New("checkpoint registrar is not activated")
 	errUnknownBenchmarkType = errors.New("unknown benchmark type")
 	errNoPriority           = errors.New("priority too low to raise capacity")
+	errUnknownHeader        = errors.New("unknown header")
 )
 
 // PrivateLightServerAPI provides an API to access the LES light server.
@@ -360,3 +367,66 @@ func (api *PrivateLightAPI) GetCheckpointContractAddress() (string, error) {
 	}
 	return api.backend.oracle.Contract().ContractAddr().Hex(), nil
 }
+
+// PublicLightValidatorAPI provides an API to retrieve the validators of the
+// equality epochs on light clients, verified against the epoch headers.
+type PublicLightValidatorAPI struct {
+	odr   *LesOdr
+	chain *light.LightChain
+}
+
+// NewPublicLightValidatorAPI creates a new validator API of the light client.
+func NewPublicLightValidatorAPI(odr *LesOdr, chain *light.LightChain) *PublicLightValidatorAPI {
+	return &PublicLightValidatorAPI{odr: odr, chain: chain}
+}
+
+// EpochValidators is the validator set of an epoch along with the merkle proof
+// against the epoch trie root committed in the header of the block.
+type EpochValidators struct {
+	Number     hexutil.Uint64   `json:"number"`
+	Hash       common.Hash      `json:"hash"`
+	Epoch      hexutil.Uint64   `json:"epoch"`
+	EpochBlock hexutil.Uint64   `json:"epochBlock"`
+	EpochHash  common.Hash      `json:"epochHash"`
+	Validators []common.Address `json:"validators"`
+	Proof      []hexutil.Bytes  `json:"proof"`
+}
+
+// GetEpochValidators retrieves the validators of the epoch the given block
+// belongs to from the light servers. They are the signers allowed to seal the
+// children of the block, proven against the epoch trie root of its header.
+func (api *PublicLightValidatorAPI) GetEpochValidators(ctx context.Context, number rpc.BlockNumber) (*EpochValidators, error) {
+	var header *types.Header
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		var err error
+		if header, err = light.GetHeaderByNumber(ctx, api.odr, uint64(number.Int64())); err != nil {
+			return nil, err
+		}
+	}
+	if header == nil {
+		return nil, errUnknownHeader
+	}
+	headerExtra, err := equality.DecodeHeaderExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	req := &light.ValidatorsRequest{Header: header}
+	if err := api.odr.Retrieve(ctx, req); err != nil {
+		return nil, err
+	}
+	proof := make([]hexutil.Bytes, 0, len(req.Proof.NodeList()))
+	for _, node := range req.Proof.NodeList() {
+		proof = append(proof, hexutil.Bytes(node))
+	}
+	return &EpochValidators{
+		Number:     hexutil.Uint64(header.Number.Uint64()),
+		Hash:       header.Hash(),
+		Epoch:      hexutil.Uint64(headerExtra.Epoch),
+		EpochBlock: hexutil.Uint64(headerExtra.EpochBlock),
+		EpochHash:  headerExtra.Root.EpochHash,
+		Validators: req.Validators,
+		Proof:      proof,
+	}, nil
+}
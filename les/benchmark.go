@@ -33,7 +33,6 @@ import (
 	"github.com/SecretBlockChain/go-secret/log"
 	"github.com/SecretBlockChain/go-secret/p2p"
 	"github.com/SecretBlockChain/go-secret/p2p/enode"
-	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/SecretBlockChain/go-secret/rlp"
 )
 
@@ -136,7 +135,7 @@ func (b *benchmarkHelperTrie) init(h *serverHandler, count int) error {
 		b.sectionCount, b.headNum, _ = h.server.bloomTrieIndexer.Sections()
 	} else {
 		b.sectionCount, _, _ = h.server.chtIndexer.Sections()
-		b.headNum = b.sectionCount*params.CHTFrequency - 1
+		b.headNum = b.sectionCount*h.server.iConfig.ChtSize - 1
 	}
 	if b.sectionCount == 0 {
 		return fmt.Errorf("no processed sections available")
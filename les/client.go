@@ -84,7 +84,7 @@ func New(stack *node.Node, config *eth.Config) (*LightEthereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis)
+	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideEquality)
 	if _, isCompat := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !isCompat {
 		return nil, genesisErr
 	}
@@ -96,7 +96,7 @@ func New(stack *node.Node, config *eth.Config) (*LightEthereum, error) {
 			genesis:     genesisHash,
 			config:      config,
 			chainConfig: chainConfig,
-			iConfig:     light.DefaultClientIndexerConfig,
+			iConfig:     light.ClientIndexerConfig(chainConfig),
 			chainDb:     chainDb,
 			closeCh:     make(chan struct{}),
 		},
@@ -106,12 +106,17 @@ func New(stack *node.Node, config *eth.Config) (*LightEthereum, error) {
 		accountManager: stack.AccountManager(),
 		engine:         eth.CreateConsensusEngine(stack, chainConfig, &config.Ethash, nil, false, chainDb),
 		bloomRequests:  make(chan chan *bloombits.Retrieval),
-		bloomIndexer:   eth.NewBloomIndexer(chainDb, params.BloomBitsBlocksClient, params.HelperTrieConfirmations),
+		bloomIndexer:   eth.NewBloomIndexer(chainDb, light.ClientIndexerConfig(chainConfig).BloomSize, light.ClientIndexerConfig(chainConfig).BloomConfirms),
 		valueTracker:   lpc.NewValueTracker(lespayDb, &mclock.System{}, requestList, time.Minute, 1/float64(time.Hour), 1/float64(time.Hour*100), 1/float64(time.Hour*1000)),
 		p2pServer:      stack.Server(),
 	}
 	peers.subscribe((*vtSubscription)(leth.valueTracker))
 
+	// Engines relying on local snapshots need to verify headers differently
+	if engine, ok := leth.engine.(lightVerifier); ok {
+		engine.SetLightMode()
+	}
+
 	dnsdisc, err := leth.setupDiscovery(&stack.Config().P2P)
 	if err != nil {
 		return nil, err
@@ -123,9 +128,9 @@ func New(stack *node.Node, config *eth.Config) (*LightEthereum, error) {
 	leth.retriever = newRetrieveManager(peers, leth.reqDist, leth.serverPool.getTimeout)
 	leth.relay = newLesTxRelay(peers, leth.retriever)
 
-	leth.odr = NewLesOdr(chainDb, light.DefaultClientIndexerConfig, leth.retriever)
-	leth.chtIndexer = light.NewChtIndexer(chainDb, leth.odr, params.CHTFrequency, params.HelperTrieConfirmations, config.LightNoPrune)
-	leth.bloomTrieIndexer = light.NewBloomTrieIndexer(chainDb, leth.odr, params.BloomBitsBlocksClient, params.BloomTrieFrequency, config.LightNoPrune)
+	leth.odr = NewLesOdr(chainDb, leth.iConfig, leth.retriever)
+	leth.chtIndexer = light.NewChtIndexer(chainDb, leth.odr, leth.iConfig.ChtSize, leth.iConfig.ChtConfirms, config.LightNoPrune)
+	leth.bloomTrieIndexer = light.NewBloomTrieIndexer(chainDb, leth.odr, leth.iConfig.BloomSize, leth.iConfig.BloomTrieSize, config.LightNoPrune)
 	leth.odr.SetIndexers(leth.chtIndexer, leth.bloomTrieIndexer, leth.bloomIndexer)
 
 	checkpoint := config.Checkpoint
@@ -181,6 +186,12 @@ func New(stack *node.Node, config *eth.Config) (*LightEthereum, error) {
 	return leth, nil
 }
 
+// lightVerifier is implemented by the consensus engines needing to be told
+// that they verify the headers of a light client.
+type lightVerifier interface {
+	SetLightMode()
+}
+
 // vtSubscription implements serverPeerSubscriber
 type vtSubscription lpc.ValueTracker
 
@@ -251,6 +262,11 @@ func (s *LightEthereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateLightAPI(&s.lesCommons),
 			Public:    false,
+		}, {
+			Namespace: "les",
+			Version:   "1.0",
+			Service:   NewPublicLightValidatorAPI(s.odr, s.blockchain),
+			Public:    true,
 		}, {
 			Namespace: "lespay",
 			Version:   "1.0",
@@ -289,7 +305,7 @@ func (s *LightEthereum) Start() error {
 	s.serverPool.start()
 	// Start bloom request workers.
 	s.wg.Add(bloomServiceThreads)
-	s.startBloomHandlers(params.BloomBitsBlocksClient)
+	s.startBloomHandlers(s.iConfig.BloomSize)
 	s.handler.start()
 
 	return nil
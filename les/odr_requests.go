@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/crypto"
@@ -43,6 +44,7 @@ var (
 	errCHTHashMismatch     = errors.New("cht hash mismatch")
 	errCHTNumberMismatch   = errors.New("cht number mismatch")
 	errUselessNodes        = errors.New("useless nodes in merkle proof nodeset")
+	errNoValidators        = errors.New("validators not proven")
 )
 
 type LesOdrRequest interface {
@@ -66,6 +68,8 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 		return (*ChtRequest)(r)
 	case *light.BloomRequest:
 		return (*BloomRequest)(r)
+	case *light.ValidatorsRequest:
+		return (*ValidatorsRequest)(r)
 	case *light.TxStatusRequest:
 		return (*TxStatusRequest)(r)
 	default:
@@ -294,6 +298,7 @@ const (
 	// helper trie type constants
 	htCanonical = iota // Canonical hash trie
 	htBloomBits        // BloomBits trie
+	htEpoch            // Epoch trie of the equality snapshot
 
 	// applicable for all helper trie requests
 	auxRoot = 1
@@ -406,6 +411,66 @@ func (r *ChtRequest) Validate(db ethdb.Database, msg *Msg) error {
 	return nil
 }
 
+// ODR request type for requesting the validators of an epoch, see LesOdrRequest interface
+type ValidatorsRequest light.ValidatorsRequest
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *ValidatorsRequest) GetCost(peer *serverPeer) uint64 {
+	return peer.getRequestCost(GetHelperTrieProofsMsg, 1)
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *ValidatorsRequest) CanSend(peer *serverPeer) bool {
+	return peer.HasBlock(r.Header.Hash(), r.Header.Number.Uint64(), false)
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *ValidatorsRequest) Request(reqID uint64, peer *serverPeer) error {
+	peer.Log().Debug("Requesting epoch validators", "number", r.Header.Number, "hash", r.Header.Hash())
+	req := HelperTrieReq{
+		Type:    htEpoch,
+		TrieIdx: r.Header.Number.Uint64(),
+		Key:     equality.ValidatorsKey,
+	}
+	return peer.requestHelperTrieProofs(reqID, []HelperTrieReq{req})
+}
+
+// Valid processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *ValidatorsRequest) Validate(db ethdb.Database, msg *Msg) error {
+	log.Debug("Validating epoch validators", "number", r.Header.Number, "hash", r.Header.Hash())
+
+	if msg.MsgType != MsgHelperTrieProofs {
+		return errInvalidMessageType
+	}
+	root, err := equality.EpochTrieRoot(r.Header)
+	if err != nil {
+		return err
+	}
+	// Verify the proof against the root committed in the header
+	nodeSet := msg.Obj.(HelperTrieResps).Proofs.NodeSet()
+	reads := &readTraceDB{db: nodeSet}
+	value, err := trie.VerifyProof(root, equality.ValidatorsKey, reads)
+	if err != nil {
+		return fmt.Errorf("merkle proof verification failed: %v", err)
+	}
+	if len(reads.reads) != nodeSet.KeyCount() {
+		return errUselessNodes
+	}
+	if value == nil {
+		return errNoValidators
+	}
+	validators, err := equality.DecodeValidators(value)
+	if err != nil {
+		return err
+	}
+	r.Validators = validators
+	r.Proof = nodeSet
+	return nil
+}
+
 type BloomReq struct {
 	BloomTrieNum, BitIdx, SectionIndex, FromLevel uint64
 }
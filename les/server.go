@@ -31,7 +31,6 @@ import (
 	"github.com/SecretBlockChain/go-secret/p2p/discv5"
 	"github.com/SecretBlockChain/go-secret/p2p/enode"
 	"github.com/SecretBlockChain/go-secret/p2p/enr"
-	"github.com/SecretBlockChain/go-secret/params"
 	"github.com/SecretBlockChain/go-secret/rpc"
 )
 
@@ -71,16 +70,17 @@ func NewLesServer(node *node.Node, e *eth.Ethereum, config *eth.Config) (*LesSer
 	if threads < 4 {
 		threads = 4
 	}
+	iConfig := light.ServerIndexerConfig(e.BlockChain().Config())
 	srv := &LesServer{
 		lesCommons: lesCommons{
 			genesis:          e.BlockChain().Genesis().Hash(),
 			config:           config,
 			chainConfig:      e.BlockChain().Config(),
-			iConfig:          light.DefaultServerIndexerConfig,
+			iConfig:          iConfig,
 			chainDb:          e.ChainDb(),
 			chainReader:      e.BlockChain(),
-			chtIndexer:       light.NewChtIndexer(e.ChainDb(), nil, params.CHTFrequency, params.HelperTrieProcessConfirmations, true),
-			bloomTrieIndexer: light.NewBloomTrieIndexer(e.ChainDb(), nil, params.BloomBitsBlocks, params.BloomTrieFrequency, true),
+			chtIndexer:       light.NewChtIndexer(e.ChainDb(), nil, iConfig.ChtSize, iConfig.ChtConfirms, true),
+			bloomTrieIndexer: light.NewBloomTrieIndexer(e.ChainDb(), nil, iConfig.BloomSize, iConfig.BloomTrieSize, true),
 			closeCh:          make(chan struct{}),
 		},
 		archiveMode:  e.ArchiveMode(),
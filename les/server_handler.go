@@ -26,6 +26,7 @@ import (
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/mclock"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/state"
@@ -880,6 +881,17 @@ func (h *serverHandler) getHelperTrie(typ uint, index uint64) (common.Hash, stri
 	case htBloomBits:
 		sectionHead := rawdb.ReadCanonicalHash(h.chainDb, (index+1)*h.server.iConfig.BloomTrieSize-1)
 		return light.GetBloomTrieRoot(h.chainDb, index, sectionHead), light.BloomTrieTablePrefix
+	case htEpoch:
+		// The epoch trie of the block is stored unprefixed next to the state
+		header := h.blockchain.GetHeaderByNumber(index)
+		if header == nil {
+			return common.Hash{}, ""
+		}
+		root, err := equality.EpochTrieRoot(header)
+		if err != nil {
+			return common.Hash{}, ""
+		}
+		return root, ""
 	}
 	return common.Hash{}, ""
 }
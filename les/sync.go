@@ -177,8 +177,13 @@ func (h *clientHandler) synchronise(peer *serverPeer) {
 			return
 		}
 	}
-	// Fetch the remaining block headers based on the current chain header.
-	if err := h.downloader.Synchronise(peer.id, peer.Head(), peer.Td(), downloader.LightSync); err != nil {
+	// Fetch the remaining block headers based on the current chain header,
+	// skipping whole epochs first if so requested.
+	syncMode := downloader.LightSync
+	if h.backend.config.SyncMode == downloader.WarpSync {
+		syncMode = downloader.WarpSync
+	}
+	if err := h.downloader.Synchronise(peer.id, peer.Head(), peer.Td(), syncMode); err != nil {
 		log.Debug("Synchronise failed", "reason", err)
 		return
 	}
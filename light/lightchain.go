@@ -28,6 +28,7 @@ import (
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/consensus"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/core/rawdb"
 	"github.com/SecretBlockChain/go-secret/core/state"
@@ -505,7 +506,8 @@ func (lc *LightChain) Config() *params.ChainConfig { return lc.hc.Config() }
 // the checkpoint provided by the remote peer.
 //
 // Note if we are running the clique, fetches the last epoch snapshot header
-// which covered by checkpoint.
+// which covered by checkpoint. The same goes for equality, whose epoch header
+// commits the validators needed to verify the following headers.
 func (lc *LightChain) SyncCheckpoint(ctx context.Context, checkpoint *params.TrustedCheckpoint) bool {
 	// Ensure the remote checkpoint head is ahead of us
 	head := lc.CurrentHeader().Number.Uint64()
@@ -518,7 +520,11 @@ func (lc *LightChain) SyncCheckpoint(ctx context.Context, checkpoint *params.Tru
 		return true
 	}
 	// Retrieve the latest useful header and update to it
-	if header, err := GetHeaderByNumber(ctx, lc.odr, latest); header != nil && err == nil {
+	header, err := GetHeaderByNumber(ctx, lc.odr, latest)
+	if header != nil && err == nil && lc.hc.Config().IsEquality(header.Number) {
+		header, err = lc.equalityEpochHeader(ctx, header)
+	}
+	if header != nil && err == nil {
 		lc.chainmu.Lock()
 		defer lc.chainmu.Unlock()
 
@@ -533,6 +539,105 @@ func (lc *LightChain) SyncCheckpoint(ctx context.Context, checkpoint *params.Tru
 	return false
 }
 
+// equalityEpochHeader retrieves the header of the equality epoch the given
+// header belongs to.
+func (lc *LightChain) equalityEpochHeader(ctx context.Context, header *types.Header) (*types.Header, error) {
+	headerExtra, err := equality.DecodeHeaderExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	if headerExtra.EpochBlock == header.Number.Uint64() {
+		return header, nil
+	}
+	return GetHeaderByNumber(ctx, lc.odr, headerExtra.EpochBlock)
+}
+
+// warpEngine is implemented by the consensus engines able to verify the headers
+// opening their epochs without the headers in between.
+type warpEngine interface {
+	EpochBlocks(epochBlock, limit uint64, max int) []uint64
+	VerifyEpochHeaders(epoch *types.Header, headers []*types.Header) error
+}
+
+// headEpochHeader returns the header opening the equality epoch of the head, or
+// the head itself before equality.
+func (lc *LightChain) headEpochHeader() (*types.Header, error) {
+	head := lc.hc.CurrentHeader()
+	if head.Number.Uint64() == 0 || !lc.hc.Config().IsEquality(head.Number) {
+		return head, nil
+	}
+	headerExtra, err := equality.DecodeHeaderExtra(head)
+	if err != nil {
+		return nil, err
+	}
+	if headerExtra.EpochBlock == head.Number.Uint64() {
+		return head, nil
+	}
+	if header := lc.hc.GetHeaderByNumber(headerExtra.EpochBlock); header != nil {
+		return header, nil
+	}
+	return nil, errors.New("epoch header of the head unavailable")
+}
+
+// EpochBlocks returns the numbers of the headers opening the epochs which follow
+// the one of the head, up to the limit and at most max of them.
+func (lc *LightChain) EpochBlocks(limit uint64, max int) ([]uint64, error) {
+	engine, ok := lc.engine.(warpEngine)
+	if !ok {
+		return nil, errors.New("consensus engine can't skip epochs")
+	}
+	epoch, err := lc.headEpochHeader()
+	if err != nil {
+		return nil, err
+	}
+	return engine.EpochBlocks(epoch.Number.Uint64(), limit, max), nil
+}
+
+// InsertEpochHeaders verifies a batch of consecutive epoch headers following the
+// epoch of the head and makes the last one the new head, leaving out the headers
+// in between. The total difficulty of the skipped headers is derived from the
+// difficulty of the epoch headers, equality blocks all share the same one.
+func (lc *LightChain) InsertEpochHeaders(headers []*types.Header) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	engine, ok := lc.engine.(warpEngine)
+	if !ok {
+		return errors.New("consensus engine can't skip epochs")
+	}
+	lc.chainmu.Lock()
+	defer lc.chainmu.Unlock()
+
+	epoch, err := lc.headEpochHeader()
+	if err != nil {
+		return err
+	}
+	if err := engine.VerifyEpochHeaders(epoch, headers); err != nil {
+		return err
+	}
+	td := lc.hc.GetTd(epoch.Hash(), epoch.Number.Uint64())
+	if td == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	batch := lc.chainDb.NewBatch()
+	for _, header := range headers {
+		distance := new(big.Int).Sub(header.Number, epoch.Number)
+		td = new(big.Int).Add(td, distance.Mul(distance, header.Difficulty))
+
+		hash, number := header.Hash(), header.Number.Uint64()
+		rawdb.WriteTd(batch, hash, number, td)
+		rawdb.WriteHeader(batch, header)
+		rawdb.WriteCanonicalHash(batch, hash, number)
+		epoch = header
+	}
+	rawdb.WriteHeadHeaderHash(batch, epoch.Hash())
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	lc.hc.SetCurrentHeader(epoch)
+	return nil
+}
+
 // LockChain locks the chain mutex for reading so that multiple canonical hashes can be
 // retrieved while it is guaranteed that they belong to the same version of the chain
 func (lc *LightChain) LockChain() {
@@ -180,6 +180,17 @@ func (req *BloomRequest) StoreResult(db ethdb.Database) {
 	}
 }
 
+// ValidatorsRequest is the ODR request type for retrieving the validators of the
+// epoch of a block, proven against the epoch trie root committed in its header
+type ValidatorsRequest struct {
+	Header     *types.Header
+	Validators []common.Address
+	Proof      *NodeSet
+}
+
+// StoreResult stores the retrieved data in local database
+func (req *ValidatorsRequest) StoreResult(db ethdb.Database) {}
+
 // TxStatus describes the status of a transaction
 type TxStatus struct {
 	Status core.TxStatus
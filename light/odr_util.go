@@ -229,6 +229,17 @@ func GetUntrustedBlockLogs(ctx context.Context, odr OdrBackend, header *types.He
 	return logs, nil
 }
 
+// GetEpochValidators retrieves the validators of the epoch the block of the given
+// header belongs to, which are the ones allowed to seal its children. They are
+// verified against the epoch trie root committed in the header.
+func GetEpochValidators(ctx context.Context, odr OdrBackend, header *types.Header) ([]common.Address, error) {
+	r := &ValidatorsRequest{Header: header}
+	if err := odr.Retrieve(ctx, r); err != nil {
+		return nil, err
+	}
+	return r.Validators, nil
+}
+
 // GetBloomBits retrieves a batch of compressed bloomBits vectors belonging to
 // the given bit index and section indexes.
 func GetBloomBits(ctx context.Context, odr OdrBackend, bit uint, sections []uint64) ([][]byte, error) {
@@ -79,6 +79,27 @@ var (
 		BloomTrieSize:     params.BloomTrieFrequency,
 		BloomTrieConfirms: params.HelperTrieConfirmations,
 	}
+	// EqualityServerIndexerConfig wraps a set of configs as the indexer config for
+	// server side on chains sealed by equality. The bloom bits are shared with the
+	// full node and keep their section size.
+	EqualityServerIndexerConfig = &IndexerConfig{
+		ChtSize:           params.EqualityCHTFrequency,
+		ChtConfirms:       params.EqualityHelperTrieProcessConfirmations,
+		BloomSize:         params.BloomBitsBlocks,
+		BloomConfirms:     params.BloomConfirms,
+		BloomTrieSize:     params.EqualityBloomTrieFrequency,
+		BloomTrieConfirms: params.EqualityHelperTrieProcessConfirmations,
+	}
+	// EqualityClientIndexerConfig wraps a set of configs as the indexer config for
+	// client side on chains sealed by equality.
+	EqualityClientIndexerConfig = &IndexerConfig{
+		ChtSize:           params.EqualityCHTFrequency,
+		ChtConfirms:       params.EqualityHelperTrieConfirmations,
+		BloomSize:         params.EqualityBloomTrieFrequency,
+		BloomConfirms:     params.EqualityHelperTrieConfirmations,
+		BloomTrieSize:     params.EqualityBloomTrieFrequency,
+		BloomTrieConfirms: params.EqualityHelperTrieConfirmations,
+	}
 	// TestServerIndexerConfig wraps a set of configs as a test indexer config for server side.
 	TestServerIndexerConfig = &IndexerConfig{
 		ChtSize:           128,
@@ -107,6 +128,22 @@ var (
 	ChtTablePrefix        = "cht-"
 )
 
+// ServerIndexerConfig returns the server side indexer config of the given chain.
+func ServerIndexerConfig(config *params.ChainConfig) *IndexerConfig {
+	if config != nil && config.Equality != nil {
+		return EqualityServerIndexerConfig
+	}
+	return DefaultServerIndexerConfig
+}
+
+// ClientIndexerConfig returns the client side indexer config of the given chain.
+func ClientIndexerConfig(config *params.ChainConfig) *IndexerConfig {
+	if config != nil && config.Equality != nil {
+		return EqualityClientIndexerConfig
+	}
+	return DefaultClientIndexerConfig
+}
+
 // ChtNode structures are stored in the Canonical Hash Trie in an RLP encoded format
 type ChtNode struct {
 	Hash common.Hash
@@ -506,3 +543,29 @@ func (b *BloomTrieIndexerBackend) Prune(threshold uint64) error {
 	log.Debug("Prune history bloombits", "threshold", threshold, "elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
 }
+
+// ReadTrustedCheckpoint assembles the checkpoint of the given section from the
+// roots stored by the local CHT and BloomTrie indexers. The checkpoint is empty
+// if the section has not been indexed.
+func ReadTrustedCheckpoint(db ethdb.Database, config *IndexerConfig, section uint64) params.TrustedCheckpoint {
+	sectionHead := rawdb.ReadCanonicalHash(db, (section+1)*config.ChtSize-1)
+	return params.TrustedCheckpoint{
+		SectionIndex: section,
+		SectionHead:  sectionHead,
+		CHTRoot:      GetChtRoot(db, section, sectionHead),
+		BloomRoot:    GetBloomTrieRoot(db, section, sectionHead),
+	}
+}
+
+// ReadLatestTrustedCheckpoint returns the checkpoint of the last section indexed
+// locally, or an empty checkpoint if there is none.
+func ReadLatestTrustedCheckpoint(db ethdb.Database, config *IndexerConfig) params.TrustedCheckpoint {
+	var latest params.TrustedCheckpoint
+	for section := uint64(0); ; section++ {
+		checkpoint := ReadTrustedCheckpoint(db, config, section)
+		if checkpoint.Empty() {
+			return latest
+		}
+		latest = checkpoint
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
+	"github.com/SecretBlockChain/go-secret/core/rawdb"
+	"github.com/SecretBlockChain/go-secret/params"
+)
+
+func TestReadTrustedCheckpoint(t *testing.T) {
+	testReadTrustedCheckpoint(t, DefaultServerIndexerConfig)
+	testReadTrustedCheckpoint(t, EqualityServerIndexerConfig)
+}
+
+func testReadTrustedCheckpoint(t *testing.T, config *IndexerConfig) {
+	db := rawdb.NewMemoryDatabase()
+	if cp := ReadLatestTrustedCheckpoint(db, config); !cp.Empty() {
+		t.Fatalf("checkpoint found in empty database: %v", cp)
+	}
+	for section := uint64(0); section < 2; section++ {
+		head := common.Hash{byte(section + 1)}
+		rawdb.WriteCanonicalHash(db, head, (section+1)*config.ChtSize-1)
+		StoreChtRoot(db, section, head, common.Hash{0xc0, byte(section)})
+		StoreBloomTrieRoot(db, section, head, common.Hash{0xb0, byte(section)})
+	}
+	want := params.TrustedCheckpoint{
+		SectionIndex: 1,
+		SectionHead:  common.Hash{0x02},
+		CHTRoot:      common.Hash{0xc0, 0x01},
+		BloomRoot:    common.Hash{0xb0, 0x01},
+	}
+	if cp := ReadLatestTrustedCheckpoint(db, config); cp != want {
+		t.Errorf("latest checkpoint mismatch: have %+v, want %+v", cp, want)
+	}
+	if cp := ReadTrustedCheckpoint(db, config, 2); !cp.Empty() {
+		t.Errorf("checkpoint found for unindexed section: %+v", cp)
+	}
+}
+
+func TestIndexerConfigSelection(t *testing.T) {
+	if config := ServerIndexerConfig(params.TestChainConfig); config != DefaultServerIndexerConfig {
+		t.Errorf("server config mismatch: have %+v, want %+v", config, DefaultServerIndexerConfig)
+	}
+	equality := &params.ChainConfig{ChainID: big.NewInt(1), Equality: &params.EqualityConfig{Period: 3, Epoch: 100}}
+	if config := ClientIndexerConfig(equality); config != EqualityClientIndexerConfig {
+		t.Errorf("client config mismatch: have %+v, want %+v", config, EqualityClientIndexerConfig)
+	}
+	// The bloom trie sections must be made of whole bloom bits sections
+	for _, config := range []*IndexerConfig{EqualityServerIndexerConfig, EqualityClientIndexerConfig} {
+		if config.BloomTrieSize%config.BloomSize != 0 {
+			t.Errorf("bloom trie size %d not a multiple of bloom size %d", config.BloomTrieSize, config.BloomSize)
+		}
+	}
+}
@@ -50,6 +50,25 @@ type Config struct {
 	GasPrice  *big.Int       // Minimum gas price for mining a transaction
 	Recommit  time.Duration  // The time interval for miner to re-create mining work.
 	Noverify  bool           // Disable remote mining solution verification(only useful in ethash).
+
+	SealKey      common.Address `toml:",omitempty"` // Key bound to the etherbase sealing the blocks (default = etherbase, only useful in equality).
+	SignerURL    string         `toml:",omitempty"` // Remote signer sealing the blocks instead of a local account (only useful in equality).
+	SignerCert   string         `toml:",omitempty"` // TLS client certificate authenticating to the remote signer
+	SignerKey    string         `toml:",omitempty"` // TLS client key authenticating to the remote signer
+	SignerCA     string         `toml:",omitempty"` // TLS certificate authority of the remote signer
+	SignerWallet string         `toml:",omitempty"` // URL of the hardware wallet sealing the blocks (only useful in equality).
+	SignerPath   string         `toml:",omitempty"` // Derivation path of the sealing key in the hardware wallet
+	SignerUnlock string         `toml:",omitempty"` // Password file unlocking the sealing key for each seal, supervised by a watchdog (only useful in equality).
+	SignGuard    string         `toml:",omitempty"` // File recording the last slot sealed to refuse double signs, relative to the datadir (only useful in equality).
+
+	CoSigners         []string `toml:",omitempty"` // Co-signers whose approval the seals wait for, as address@url (only useful in equality, advisory).
+	CoSignerThreshold int      `toml:",omitempty"` // Number of co-signer approvals needed to seal a block
+
+	PolicyHeight     bool             `toml:",omitempty"` // Refuse to seal below the highest block sealed (only useful in equality).
+	PolicyDrift      time.Duration    `toml:",omitempty"` // Refuse to seal blocks drifting from the local clock by more than this (only useful in equality).
+	PolicyValidators []common.Address `toml:",omitempty"` // Refuse to seal in epochs with other validators (only useful in equality).
+
+	ConsensusTxQuota int `toml:",omitempty"` // Transactions registering or cancelling candidates included ahead of the others in each block (only useful in equality).
 }
 
 // Miner creates blocks and searches for proof-of-work values.
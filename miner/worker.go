@@ -26,7 +26,6 @@ import (
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/consensus"
-	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/consensus/misc"
 	"github.com/SecretBlockChain/go-secret/core"
 	"github.com/SecretBlockChain/go-secret/core/state"
@@ -802,6 +801,14 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			txs.Pop()
 			continue
 		}
+		// Check whether the tx pays the base fee of the block, skipping the sender
+		// if not as its following transactions can't be executed without it.
+		if baseFee := w.current.header.BaseFee; baseFee != nil && tx.GasPriceIntCmp(baseFee) < 0 {
+			log.Trace("Ignoring transaction below the base fee", "hash", tx.Hash(), "gasPrice", tx.GasPrice(), "baseFee", baseFee)
+
+			txs.Pop()
+			continue
+		}
 		// Start executing the transaction
 		w.current.state.Prepare(tx.Hash(), common.Hash{}, w.current.tcount)
 
@@ -859,6 +866,51 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 	return false
 }
 
+// consensusLane is implemented by the consensus engines telling apart the
+// transactions managing the consensus roles, which are given a lane of their own.
+type consensusLane interface {
+	IsConsensusTransaction(chain consensus.ChainHeaderReader, header *types.Header, tx *types.Transaction) bool
+}
+
+// splitConsensusTransactions moves up to quota consensus transactions out of the
+// pending ones, the best paying first. Only the transactions leading the queue of
+// their account are taken, keeping the nonces in order.
+func splitConsensusTransactions(signer types.Signer, pending map[common.Address]types.Transactions, quota int,
+	isConsensus func(*types.Transaction) bool) map[common.Address]types.Transactions {
+
+	leading := make(map[common.Address]types.Transactions)
+	for account, txs := range pending {
+		n := 0
+		for n < len(txs) && isConsensus(txs[n]) {
+			n++
+		}
+		if n > 0 {
+			leading[account] = txs[:n]
+		}
+	}
+	if len(leading) == 0 {
+		return nil
+	}
+	selected := make(map[common.Address]types.Transactions)
+	for txs := types.NewTransactionsByPriceAndNonce(signer, leading); quota > 0; quota-- {
+		tx := txs.Peek()
+		if tx == nil {
+			break
+		}
+		from, _ := types.Sender(signer, tx)
+		selected[from] = append(selected[from], tx)
+		txs.Shift()
+	}
+	for account, txs := range selected {
+		if rest := pending[account][len(txs):]; len(rest) > 0 {
+			pending[account] = rest
+		} else {
+			delete(pending, account)
+		}
+	}
+	return selected
+}
+
 // commitNewWork generates several new sealing tasks based on the parent block.
 func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64) {
 	w.mu.RLock()
@@ -885,6 +937,10 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		Extra:      w.extra,
 		Time:       uint64(timestamp),
 	}
+	// Set baseFee if we are on an EIP-1559 chain
+	if w.chainConfig.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(w.chainConfig, parent.Header())
+	}
 	// Only set the coinbase if our consensus engine is running (avoid spurious block rewards)
 	if w.isRunning() {
 		if w.coinbase == (common.Address{}) {
@@ -917,7 +973,10 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		return
 	}
 
-	engine, ok := w.engine.(*equality.Equality)
+	type turnBased interface {
+		InTurn(lastBlockHeader *types.Header, now uint64) bool
+	}
+	engine, ok := w.engine.(turnBased)
 	if ok && !engine.InTurn(parent.Header(), uint64(tstart.Unix())) {
 		w.updateSnapshot()
 		return
@@ -972,6 +1031,19 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		w.updateSnapshot()
 		return
 	}
+	// Include the transactions managing the consensus roles ahead of the others
+	// up to the quota, so that fee competition doesn't crowd them out
+	if lane, ok := w.engine.(consensusLane); ok && w.config.ConsensusTxQuota > 0 {
+		isConsensus := func(tx *types.Transaction) bool {
+			return lane.IsConsensusTransaction(w.chain, header, tx)
+		}
+		if consensusTxs := splitConsensusTransactions(w.current.signer, pending, w.config.ConsensusTxQuota, isConsensus); len(consensusTxs) > 0 {
+			txs := types.NewTransactionsByPriceAndNonce(w.current.signer, consensusTxs)
+			if w.commitTransactions(txs, w.coinbase, interrupt) {
+				return
+			}
+		}
+	}
 	// Split the pending transactions into locals and remotes
 	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
 	for _, account := range w.eth.TxPool().Locals() {
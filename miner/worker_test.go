@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"math/rand"
 	"sync/atomic"
@@ -501,3 +502,45 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+func TestSplitConsensusTransactions(t *testing.T) {
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	lane := params.StakingContractAddress
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	sign := func(key *ecdsa.PrivateKey, nonce uint64, to common.Address, price int64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, to, new(big.Int), params.TxGas, big.NewInt(price), nil), signer, key)
+		return tx
+	}
+	var (
+		first  = crypto.PubkeyToAddress(keys[0].PublicKey)
+		second = crypto.PubkeyToAddress(keys[1].PublicKey)
+		third  = crypto.PubkeyToAddress(keys[2].PublicKey)
+	)
+	pending := map[common.Address]types.Transactions{
+		first:  {sign(keys[0], 0, lane, 1), sign(keys[0], 1, lane, 1), sign(keys[0], 2, testUserAddress, 1)},
+		second: {sign(keys[1], 0, testUserAddress, 9), sign(keys[1], 1, lane, 9)},
+		third:  {sign(keys[2], 0, lane, 5)},
+	}
+	isConsensus := func(tx *types.Transaction) bool { return *tx.To() == lane }
+
+	// The best paying transactions leading their account are taken up to the quota
+	firstTxs, thirdTxs := pending[first], pending[third]
+	selected := splitConsensusTransactions(signer, pending, 2, isConsensus)
+	if len(selected) != 2 || len(selected[first]) != 1 || selected[first][0] != firstTxs[0] || len(selected[third]) != 1 || selected[third][0] != thirdTxs[0] {
+		t.Fatalf("selected transactions mismatch: %v", selected)
+	}
+	// The others stay pending in order
+	if len(pending) != 2 || len(pending[first]) != 2 || pending[first][0] != firstTxs[1] || len(pending[second]) != 2 {
+		t.Fatalf("pending transactions mismatch: %v", pending)
+	}
+	if _, ok := pending[third]; ok {
+		t.Errorf("account with all its transactions selected still pending")
+	}
+	// Nothing is taken without consensus transactions leading the queues
+	if selected := splitConsensusTransactions(signer, map[common.Address]types.Transactions{second: pending[second]}, 2, isConsensus); selected != nil {
+		t.Errorf("selected transactions behind others: %v", selected)
+	}
+}
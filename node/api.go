@@ -93,6 +93,52 @@ func (api *privateAdminAPI) RemovePeer(url string) (bool, error) {
 	return true, nil
 }
 
+// AddBootnode adds a remote node to the bootstrap nodes used by the discovery.
+func (api *privateAdminAPI) AddBootnode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	if err := server.AddBootnode(node); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveBootnode removes a remote node from the bootstrap nodes used by the
+// discovery, returning whether it was one of them.
+func (api *privateAdminAPI) RemoveBootnode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	return server.RemoveBootnode(node), nil
+}
+
+// Bootnodes returns the enode URLs of the bootstrap nodes used by the discovery.
+func (api *privateAdminAPI) Bootnodes() ([]string, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	urls := []string{}
+	for _, node := range server.Bootnodes() {
+		urls = append(urls, node.URLv4())
+	}
+	return urls, nil
+}
+
 // AddTrustedPeer allows a remote node to always connect, even if slots are full
 func (api *privateAdminAPI) AddTrustedPeer(url string) (bool, error) {
 	// Make sure the server is running, fail otherwise
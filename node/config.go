@@ -18,6 +18,7 @@ package node
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/SecretBlockChain/go-secret/accounts"
 	"github.com/SecretBlockChain/go-secret/accounts/external"
+	"github.com/SecretBlockChain/go-secret/accounts/hsm"
 	"github.com/SecretBlockChain/go-secret/accounts/keystore"
 	"github.com/SecretBlockChain/go-secret/accounts/scwallet"
 	"github.com/SecretBlockChain/go-secret/accounts/usbwallet"
@@ -45,6 +47,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirGoodPeers       = "peers.json"         // Path within the datadir to the peers of the last run
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -98,6 +101,19 @@ type Config struct {
 	// SmartCardDaemonPath is the path to the smartcard daemon's socket
 	SmartCardDaemonPath string `toml:",omitempty"`
 
+	// PKCS11Module is the path of the PKCS#11 library of the HSM holding a key to
+	// sign with, e.g. the sealing key of a validator. An empty path disables it.
+	PKCS11Module string `toml:",omitempty"`
+
+	// PKCS11Slot is the slot of the HSM token holding the key.
+	PKCS11Slot uint `toml:",omitempty"`
+
+	// PKCS11PIN is the PIN logging into the HSM token.
+	PKCS11PIN string `toml:",omitempty"`
+
+	// PKCS11Label is the label of the key pair in the HSM token.
+	PKCS11Label string `toml:",omitempty"`
+
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
 	// a simple file name, it is placed inside the data directory (or on the root
 	// pipe path on Windows), whereas if it's a resolvable path name (absolute or
@@ -383,8 +399,37 @@ func (c *Config) TrustedNodes() []*enode.Node {
 	return c.parsePersistentNodes(&c.trustedNodesWarning, c.ResolvePath(datadirTrustedNodes))
 }
 
+// GoodPeers returns the dialed peers the node was connected to when it was last
+// shut down. They are complete discovery nodes, usable to seed the discovery.
+func (c *Config) GoodPeers() []*enode.Node {
+	var nodes []*enode.Node
+	for _, node := range c.parsePersistentNodes(nil, c.ResolvePath(datadirGoodPeers)) {
+		if node.ValidateComplete() == nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// saveGoodPeers stores the given peers for seeding the discovery on the next run.
+func (c *Config) saveGoodPeers(nodes []*enode.Node) error {
+	if c.DataDir == "" {
+		return nil
+	}
+	urls := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		urls = append(urls, node.URLv4())
+	}
+	blob, err := json.MarshalIndent(urls, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.ResolvePath(datadirGoodPeers), blob, 0600)
+}
+
 // parsePersistentNodes parses a list of discovery node URLs loaded from a .json
-// file from within the data directory.
+// file from within the data directory. The deprecation warning is only printed
+// for the lists which have an equivalent in the TOML config file (w != nil).
 func (c *Config) parsePersistentNodes(w *bool, path string) []*enode.Node {
 	// Short circuit if no node config is present
 	if c.DataDir == "" {
@@ -393,7 +438,9 @@ func (c *Config) parsePersistentNodes(w *bool, path string) []*enode.Node {
 	if _, err := os.Stat(path); err != nil {
 		return nil
 	}
-	c.warnOnce(w, "Found deprecated node list file %s, please use the TOML config file instead.", path)
+	if w != nil {
+		c.warnOnce(w, "Found deprecated node list file %s, please use the TOML config file instead.", path)
+	}
 
 	// Load the nodes from the config file.
 	var nodelist []string
@@ -504,6 +551,19 @@ func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
 				backends = append(backends, schub)
 			}
 		}
+		if len(conf.PKCS11Module) > 0 {
+			// Start a PKCS#11 session on the HSM holding the key
+			hsmbackend, err := hsm.NewBackend(hsm.Config{
+				Module: conf.PKCS11Module,
+				Slot:   conf.PKCS11Slot,
+				PIN:    conf.PKCS11PIN,
+				Label:  conf.PKCS11Label,
+			})
+			if err != nil {
+				return nil, "", fmt.Errorf("error opening HSM: %v", err)
+			}
+			backends = append(backends, hsmbackend)
+		}
 	}
 
 	return accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: conf.InsecureUnlockAllowed}, backends...), ephemeral, nil
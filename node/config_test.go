@@ -19,6 +19,7 @@ package node
 import (
 	"bytes"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/SecretBlockChain/go-secret/crypto"
 	"github.com/SecretBlockChain/go-secret/p2p"
+	"github.com/SecretBlockChain/go-secret/p2p/enode"
 )
 
 // Tests that datadirs can be successfully created, be them manually configured
@@ -160,3 +162,32 @@ func TestNodeKeyPersistency(t *testing.T) {
 		t.Fatalf("ephemeral node key persisted to disk")
 	}
 }
+
+// Tests that the dialed peers of a run are persisted to seed the next one.
+func TestGoodPeersPersistency(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := &Config{Name: "unit-test", DataDir: dir}
+	if peers := config.GoodPeers(); len(peers) != 0 {
+		t.Fatalf("good peers found in fresh data directory: %v", peers)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "unit-test"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	peers := []*enode.Node{
+		enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303),
+		enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 30303, 0), // not usable for discovery
+	}
+	if err := config.saveGoodPeers(peers); err != nil {
+		t.Fatalf("failed to save good peers: %v", err)
+	}
+	loaded := config.GoodPeers()
+	if len(loaded) != 1 || loaded[0].URLv4() != peers[0].URLv4() {
+		t.Fatalf("good peers mismatch: have %v, want %v", loaded, peers[:1])
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/SecretBlockChain/go-secret/event"
 	"github.com/SecretBlockChain/go-secret/log"
 	"github.com/SecretBlockChain/go-secret/p2p"
+	"github.com/SecretBlockChain/go-secret/p2p/enode"
 	"github.com/SecretBlockChain/go-secret/rpc"
 	"github.com/prometheus/tsdb/fileutil"
 )
@@ -131,6 +132,7 @@ func New(conf *Config) (*Node, error) {
 	if node.server.Config.TrustedNodes == nil {
 		node.server.Config.TrustedNodes = node.config.TrustedNodes()
 	}
+	node.server.Config.ExtraBootstrapNodes = append(node.server.Config.ExtraBootstrapNodes, node.config.GoodPeers()...)
 	if node.server.Config.NodeDatabase == "" {
 		node.server.Config.NodeDatabase = node.config.NodeDB()
 	}
@@ -276,6 +278,9 @@ func containsLifecycle(lfs []Lifecycle, l Lifecycle) bool {
 func (n *Node) stopServices(running []Lifecycle) error {
 	n.stopRPC()
 
+	// Remember the peers before the protocols drop them.
+	n.saveGoodPeers()
+
 	// Stop running lifecycles in reverse order.
 	failure := &StopError{Services: make(map[reflect.Type]error)}
 	for i := len(running) - 1; i >= 0; i-- {
@@ -293,6 +298,23 @@ func (n *Node) stopServices(running []Lifecycle) error {
 	return nil
 }
 
+// saveGoodPeers persists the dialed peers to seed the discovery with on the next
+// run. Inbound peers are skipped as their advertised ports are unknown.
+func (n *Node) saveGoodPeers() {
+	var nodes []*enode.Node
+	for _, peer := range n.server.Peers() {
+		if !peer.Inbound() && peer.Node().ValidateComplete() == nil {
+			nodes = append(nodes, peer.Node())
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+	if err := n.config.saveGoodPeers(nodes); err != nil {
+		n.log.Warn("Failed to save good peers", "err", err)
+	}
+}
+
 func (n *Node) openDataDir() error {
 	if n.config.DataDir == "" {
 		return nil // ephemeral
@@ -0,0 +1,145 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/rlp"
+)
+
+// defaultMaxClockDrift is the clock difference tolerated from a remote peer if
+// none is configured.
+const defaultMaxClockDrift = 10 * time.Second
+
+// clockDriftWarnInterval is the minimum time between two warnings about peers
+// drifting beyond the tolerated clock difference.
+const clockDriftWarnInterval = 10 * time.Minute
+
+// clockDriftReport aggregates the peers drifting beyond the tolerated clock
+// difference, so a flood of drifting peers doesn't flood the logs too.
+type clockDriftReport struct {
+	lock   sync.Mutex
+	warned time.Time // time of the last warning
+	peers  int       // drifting peers seen since the last warning
+}
+
+// encodeHandshakeTime packs the local time into the tail fields of the protocol
+// handshake. Peers not aware of the extension simply ignore the extra field.
+func encodeHandshakeTime(now time.Time) []rlp.RawValue {
+	enc, err := rlp.EncodeToBytes(uint64(now.UnixNano() / int64(time.Millisecond)))
+	if err != nil {
+		return nil
+	}
+	return []rlp.RawValue{enc}
+}
+
+// decodeHandshakeTime extracts the remote time from the tail fields of the
+// protocol handshake, if the remote peer advertised it.
+func decodeHandshakeTime(rest []rlp.RawValue) (time.Time, bool) {
+	if len(rest) == 0 {
+		return time.Time{}, false
+	}
+	var millis uint64
+	if err := rlp.DecodeBytes(rest[0], &millis); err != nil || millis == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(millis)*int64(time.Millisecond)), true
+}
+
+// handshakeClockOffset estimates how far the remote clock is ahead of the local
+// one, assuming the remote time was taken halfway through the handshake.
+func handshakeClockOffset(sent, received, remote time.Time) time.Duration {
+	local := sent.Add(received.Sub(sent) / 2)
+	return remote.Sub(local)
+}
+
+// maxClockDrift returns the clock difference tolerated from a remote peer.
+func (srv *Server) maxClockDrift() time.Duration {
+	if srv.MaxClockDrift == 0 {
+		return defaultMaxClockDrift
+	}
+	return srv.MaxClockDrift
+}
+
+// reportClockDrift records a peer drifting beyond the tolerated clock difference
+// and returns the number of drifting peers seen since the last warning, if a new
+// one is due. Drifting peers are only reported, they are not penalised.
+func (srv *Server) reportClockDrift(now time.Time) (int, bool) {
+	r := &srv.clockDrift
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.peers++
+	if !r.warned.IsZero() && now.Sub(r.warned) < clockDriftWarnInterval {
+		return 0, false
+	}
+	peers := r.peers
+	r.warned, r.peers = now, 0
+	return peers, true
+}
+
+// ClockOffset estimates the offset of the local clock from the network time,
+// as the median of the clock offsets advertised by the connected peers. The
+// number of peers the estimate is based on is returned too, zero meaning unknown.
+func (srv *Server) ClockOffset() (time.Duration, int) {
+	offsets := make([]time.Duration, 0)
+	for _, p := range srv.Peers() {
+		if offset, ok := p.ClockOffset(); ok {
+			offsets = append(offsets, offset)
+		}
+	}
+	return networkClockOffset(offsets, srv.maxClockDrift())
+}
+
+// networkClockOffset estimates the offset of the local clock from the network
+// time out of the peer clock offsets. The outliers are classified against the
+// median of all the offsets rather than against the local clock: peers drifting
+// from the median beyond the limit are left out, while a local clock drifting
+// from all the peers is still reported.
+func networkClockOffset(offsets []time.Duration, limit time.Duration) (time.Duration, int) {
+	if len(offsets) == 0 {
+		return 0, 0
+	}
+	sorted := make([]time.Duration, len(offsets))
+	copy(sorted, offsets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	center := medianOffset(sorted)
+	inliers := make([]time.Duration, 0, len(sorted))
+	for _, offset := range sorted {
+		if diff := offset - center; diff <= limit && diff >= -limit {
+			inliers = append(inliers, offset)
+		}
+	}
+	if len(inliers) == 0 {
+		return 0, 0
+	}
+	// The network is ahead of us by the median of the peer offsets
+	return -medianOffset(inliers), len(inliers)
+}
+
+// medianOffset returns the median of the sorted, non-empty clock offsets.
+func medianOffset(sorted []time.Duration) time.Duration {
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+	return median
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SecretBlockChain/go-secret/rlp"
+)
+
+func TestHandshakeTime(t *testing.T) {
+	now := time.Unix(1625976000, 123*int64(time.Millisecond))
+	remote, ok := decodeHandshakeTime(encodeHandshakeTime(now))
+	if !ok {
+		t.Fatal("handshake time not decoded")
+	}
+	if !remote.Equal(now) {
+		t.Errorf("handshake time mismatch: have %v, want %v", remote, now)
+	}
+	if _, ok := decodeHandshakeTime(nil); ok {
+		t.Error("handshake time decoded from legacy handshake")
+	}
+
+	// Make sure the time survives a round trip through the handshake message
+	enc, err := rlp.EncodeToBytes(&protoHandshake{Version: baseProtocolVersion, Rest: encodeHandshakeTime(now)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hs protoHandshake
+	if err := rlp.DecodeBytes(enc, &hs); err != nil {
+		t.Fatal(err)
+	}
+	if remote, ok = decodeHandshakeTime(hs.Rest); !ok || !remote.Equal(now) {
+		t.Errorf("handshake time mismatch after encoding: have %v, want %v", remote, now)
+	}
+}
+
+func TestHandshakeClockOffset(t *testing.T) {
+	sent := time.Unix(1000, 0)
+	received := sent.Add(200 * time.Millisecond)
+	remote := sent.Add(3 * time.Second)
+
+	if offset := handshakeClockOffset(sent, received, remote); offset != 2900*time.Millisecond {
+		t.Errorf("clock offset mismatch: have %v, want %v", offset, 2900*time.Millisecond)
+	}
+}
+
+func TestReportClockDrift(t *testing.T) {
+	srv := new(Server)
+	start := time.Unix(1000, 0)
+
+	// The first drifting peer is warned about right away
+	if peers, ok := srv.reportClockDrift(start); !ok || peers != 1 {
+		t.Fatalf("first drift report mismatch: have (%d, %v), want (1, true)", peers, ok)
+	}
+	// Further ones are aggregated until the warning interval passes
+	for i := 1; i <= 3; i++ {
+		if _, ok := srv.reportClockDrift(start.Add(time.Duration(i) * time.Minute)); ok {
+			t.Fatalf("drift report %d warned within the interval", i)
+		}
+	}
+	if peers, ok := srv.reportClockDrift(start.Add(clockDriftWarnInterval)); !ok || peers != 4 {
+		t.Errorf("aggregated drift report mismatch: have (%d, %v), want (4, true)", peers, ok)
+	}
+}
+
+func TestNetworkClockOffset(t *testing.T) {
+	limit := 10 * time.Second
+	tests := []struct {
+		offsets []time.Duration
+		offset  time.Duration
+		peers   int
+	}{
+		// No peer advertising its time leaves the offset unknown
+		{nil, 0, 0},
+		// Peers agreeing with the local clock
+		{[]time.Duration{-time.Second, 0, 2 * time.Second}, 0, 3},
+		{[]time.Duration{time.Second, 3 * time.Second}, -2 * time.Second, 2},
+		// A peer drifting from the others is left out
+		{[]time.Duration{0, time.Second, 2 * time.Second, time.Minute}, -time.Second, 3},
+		{[]time.Duration{-time.Hour, 0, time.Second}, -500 * time.Millisecond, 2},
+		// The local clock drifting from all the peers is reported
+		{[]time.Duration{30 * time.Second, 31 * time.Second, 32 * time.Second}, -31 * time.Second, 3},
+		{[]time.Duration{-time.Minute, -time.Minute}, time.Minute, 2},
+	}
+	for i, tt := range tests {
+		offset, peers := networkClockOffset(tt.offsets, limit)
+		if offset != tt.offset || peers != tt.peers {
+			t.Errorf("test %d: offset mismatch: have (%v, %d), want (%v, %d)", i, offset, peers, tt.offset, tt.peers)
+		}
+	}
+}
@@ -194,6 +194,50 @@ func (tab *Table) setFallbackNodes(nodes []*enode.Node) error {
 	return nil
 }
 
+// addFallbackNode adds a node to the initial points of contact and seeds it into
+// the table right away.
+func (tab *Table) addFallbackNode(n *enode.Node) error {
+	if err := n.ValidateComplete(); err != nil {
+		return fmt.Errorf("bad bootstrap node %q: %v", n, err)
+	}
+	tab.mutex.Lock()
+	for _, fallback := range tab.nursery {
+		if fallback.ID() == n.ID() {
+			tab.mutex.Unlock()
+			return nil
+		}
+	}
+	wn := wrapNode(n)
+	tab.nursery = append(tab.nursery, wn)
+	tab.mutex.Unlock()
+
+	tab.addSeenNode(wn)
+	return nil
+}
+
+// removeFallbackNode removes a node from the initial points of contact. Nodes
+// already in the table are left to revalidation.
+func (tab *Table) removeFallbackNode(id enode.ID) bool {
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+
+	for i, fallback := range tab.nursery {
+		if fallback.ID() == id {
+			tab.nursery = append(tab.nursery[:i:i], tab.nursery[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fallbackNodes returns the current initial points of contact.
+func (tab *Table) fallbackNodes() []*enode.Node {
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+
+	return unwrapNodes(tab.nursery)
+}
+
 // isInitDone returns whether the table's initial seeding procedure has completed.
 func (tab *Table) isInitDone() bool {
 	select {
@@ -302,7 +346,9 @@ func (tab *Table) doRefresh(done chan struct{}) {
 
 func (tab *Table) loadSeedNodes() {
 	seeds := wrapNodes(tab.db.QuerySeeds(seedCount, seedMaxAge))
+	tab.mutex.Lock()
 	seeds = append(seeds, tab.nursery...)
+	tab.mutex.Unlock()
 	for i := range seeds {
 		seed := seeds[i]
 		age := log.Lazy{Fn: func() interface{} { return time.Since(tab.db.LastPongReceived(seed.ID(), seed.IP())) }}
@@ -426,3 +426,38 @@ func newkey() *ecdsa.PrivateKey {
 	}
 	return key
 }
+
+func TestTable_fallbackNodes(t *testing.T) {
+	tab, db := newTestTable(newPingRecorder())
+	<-tab.initDone
+	defer db.Close()
+	defer tab.close()
+
+	key := newkey()
+	n := enode.NewV4(&key.PublicKey, net.IP{88, 77, 66, 1}, 30303, 30303)
+	if err := tab.addFallbackNode(n); err != nil {
+		t.Fatalf("failed to add bootnode: %v", err)
+	}
+	if err := tab.addFallbackNode(n); err != nil {
+		t.Fatalf("failed to re-add bootnode: %v", err)
+	}
+	if nodes := tab.fallbackNodes(); len(nodes) != 1 || nodes[0].ID() != n.ID() {
+		t.Fatalf("wrong bootnodes: %v", nodes)
+	}
+	if tab.getNode(n.ID()) == nil {
+		t.Fatal("bootnode not seeded into the table")
+	}
+	if err := tab.addFallbackNode(enode.NewV4(&key.PublicKey, net.IP{88, 77, 66, 1}, 30303, 0)); err == nil {
+		t.Fatal("bootnode without UDP port accepted")
+	}
+
+	if !tab.removeFallbackNode(n.ID()) {
+		t.Fatal("bootnode not removed")
+	}
+	if tab.removeFallbackNode(n.ID()) {
+		t.Fatal("bootnode removed twice")
+	}
+	if nodes := tab.fallbackNodes(); len(nodes) != 0 {
+		t.Fatalf("bootnodes left after removal: %v", nodes)
+	}
+}
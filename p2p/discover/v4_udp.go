@@ -156,6 +156,22 @@ func ListenV4(c UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv4, error) {
 	return t, nil
 }
 
+// AddBootnode adds a node to the bootstrap nodes of the table at runtime.
+func (t *UDPv4) AddBootnode(n *enode.Node) error {
+	return t.tab.addFallbackNode(n)
+}
+
+// RemoveBootnode removes a node from the bootstrap nodes of the table, reporting
+// whether it was one of them.
+func (t *UDPv4) RemoveBootnode(id enode.ID) bool {
+	return t.tab.removeFallbackNode(id)
+}
+
+// Bootnodes returns the bootstrap nodes of the table.
+func (t *UDPv4) Bootnodes() []*enode.Node {
+	return t.tab.fallbackNodes()
+}
+
 // Self returns the local node.
 func (t *UDPv4) Self() *enode.Node {
 	return t.localNode.Node()
@@ -457,10 +457,18 @@ type PeerInfo struct {
 		Inbound       bool   `json:"inbound"`
 		Trusted       bool   `json:"trusted"`
 		Static        bool   `json:"static"`
+		ClockOffset   string `json:"clockOffset,omitempty"`
 	} `json:"network"`
 	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
 }
 
+// ClockOffset returns how far the clock of the remote peer is ahead of the local
+// one, as estimated during the protocol handshake. The boolean is false if the
+// peer did not advertise its clock.
+func (p *Peer) ClockOffset() (time.Duration, bool) {
+	return p.rw.clockOffset, p.rw.clockKnown
+}
+
 // Info gathers and returns a collection of metadata known about a peer.
 func (p *Peer) Info() *PeerInfo {
 	// Gather the protocol capabilities
@@ -484,6 +492,9 @@ func (p *Peer) Info() *PeerInfo {
 	info.Network.Inbound = p.rw.is(inboundConn)
 	info.Network.Trusted = p.rw.is(trustedConn)
 	info.Network.Static = p.rw.is(staticDialedConn)
+	if offset, ok := p.ClockOffset(); ok {
+		info.Network.ClockOffset = offset.String()
+	}
 
 	// Gather all the running protocol infos
 	for _, proto := range p.running {
@@ -107,6 +107,10 @@ type Config struct {
 	// protocol.
 	BootstrapNodesV5 []*discv5.Node `toml:",omitempty"`
 
+	// ExtraBootstrapNodes are used on top of the BootstrapNodes, e.g. to
+	// add seed nodes from the config file without replacing the defaults.
+	ExtraBootstrapNodes []*enode.Node `toml:",omitempty"`
+
 	// Static nodes are used as pre-configured connections which are always
 	// maintained and re-connected on disconnects.
 	StaticNodes []*enode.Node
@@ -156,6 +160,12 @@ type Config struct {
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
+	// MaxClockDrift is the maximum clock difference tolerated from a peer, as
+	// advertised in the protocol handshake. Peers beyond it are warned about, and
+	// peers drifting beyond it from the median of all peers are left out of the
+	// network time estimate. Zero defaults to 10 seconds.
+	MaxClockDrift time.Duration `toml:",omitempty"`
+
 	clock mclock.Clock
 }
 
@@ -198,6 +208,8 @@ type Server struct {
 
 	// State of run loop and listenLoop.
 	inboundHistory expHeap
+
+	clockDrift clockDriftReport // peers drifting beyond MaxClockDrift
 }
 
 type peerOpFunc func(map[enode.ID]*Peer)
@@ -227,6 +239,9 @@ type conn struct {
 	cont  chan error // The run loop uses cont to signal errors to SetupConn.
 	caps  []Cap      // valid after the protocol handshake
 	name  string     // valid after the protocol handshake
+
+	clockOffset time.Duration // remote clock offset, valid after the protocol handshake
+	clockKnown  bool          // whether the remote peer advertised its clock
 }
 
 type transport interface {
@@ -354,6 +369,49 @@ func (srv *Server) RemovePeer(node *enode.Node) {
 	}
 }
 
+// AddBootnode adds the given node to the bootstrap nodes of the discovery table.
+// Unlike static peers, bootnodes are only contacted for discovery.
+func (srv *Server) AddBootnode(node *enode.Node) error {
+	if srv.ntab == nil {
+		return errors.New("discovery is not running")
+	}
+	return srv.ntab.AddBootnode(node)
+}
+
+// RemoveBootnode removes the given node from the bootstrap nodes of the discovery
+// table, reporting whether it was one of them.
+func (srv *Server) RemoveBootnode(node *enode.Node) bool {
+	if srv.ntab == nil {
+		return false
+	}
+	return srv.ntab.RemoveBootnode(node.ID())
+}
+
+// Bootnodes returns the current bootstrap nodes of the discovery table.
+func (srv *Server) Bootnodes() []*enode.Node {
+	if srv.ntab == nil {
+		return nil
+	}
+	return srv.ntab.Bootnodes()
+}
+
+// bootstrapNodes returns the configured bootstrap nodes followed by the extra
+// ones which are not already part of them.
+func (srv *Server) bootstrapNodes() []*enode.Node {
+	nodes := append([]*enode.Node{}, srv.BootstrapNodes...)
+	known := make(map[enode.ID]bool)
+	for _, n := range nodes {
+		known[n.ID()] = true
+	}
+	for _, n := range srv.ExtraBootstrapNodes {
+		if !known[n.ID()] {
+			nodes = append(nodes, n)
+			known[n.ID()] = true
+		}
+	}
+	return nodes
+}
+
 // AddTrustedPeer adds the given node to a reserved whitelist which allows the
 // node to always connect, even if the slot are full.
 func (srv *Server) AddTrustedPeer(node *enode.Node) {
@@ -582,7 +640,7 @@ func (srv *Server) setupDiscovery() error {
 		cfg := discover.Config{
 			PrivateKey:  srv.PrivateKey,
 			NetRestrict: srv.NetRestrict,
-			Bootnodes:   srv.BootstrapNodes,
+			Bootnodes:   srv.bootstrapNodes(),
 			Unhandled:   unhandled,
 			Log:         srv.log,
 		}
@@ -966,8 +1024,11 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 		return err
 	}
 
-	// Run the capability negotiation handshake.
-	phs, err := c.doProtoHandshake(srv.ourHandshake)
+	// Run the capability negotiation handshake, advertising our local time so
+	// the clock drift between the two sides can be estimated.
+	our, sent := *srv.ourHandshake, time.Now()
+	our.Rest = encodeHandshakeTime(sent)
+	phs, err := c.doProtoHandshake(&our)
 	if err != nil {
 		clog.Trace("Failed p2p handshake", "err", err)
 		return err
@@ -977,6 +1038,15 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 		return DiscUnexpectedIdentity
 	}
 	c.caps, c.name = phs.Caps, phs.Name
+	if remote, ok := decodeHandshakeTime(phs.Rest); ok {
+		c.clockOffset, c.clockKnown = handshakeClockOffset(sent, time.Now(), remote), true
+		if limit := srv.maxClockDrift(); c.clockOffset > limit || c.clockOffset < -limit {
+			clog.Debug("Peer clock drift exceeds limit", "offset", common.PrettyDuration(c.clockOffset), "limit", limit)
+			if peers, ok := srv.reportClockDrift(time.Now()); ok {
+				srv.log.Warn("Peers with drifting clocks connected", "peers", peers, "limit", limit)
+			}
+		}
+	}
 	err = srv.checkpoint(c, srv.checkpointAddPeer)
 	if err != nil {
 		clog.Trace("Rejected peer", "err", err)
@@ -1062,8 +1132,9 @@ type NodeInfo struct {
 		Discovery int `json:"discovery"` // UDP listening port for discovery protocol
 		Listener  int `json:"listener"`  // TCP listening port for RLPx
 	} `json:"ports"`
-	ListenAddr string                 `json:"listenAddr"`
-	Protocols  map[string]interface{} `json:"protocols"`
+	ListenAddr  string                 `json:"listenAddr"`
+	ClockOffset string                 `json:"clockOffset,omitempty"` // Estimated offset of the local clock from the network time
+	Protocols   map[string]interface{} `json:"protocols"`
 }
 
 // NodeInfo gathers and returns a collection of metadata known about the host.
@@ -1081,6 +1152,9 @@ func (srv *Server) NodeInfo() *NodeInfo {
 	info.Ports.Discovery = node.UDP()
 	info.Ports.Listener = node.TCP()
 	info.ENR = node.String()
+	if offset, peers := srv.ClockOffset(); peers > 0 {
+		info.ClockOffset = offset.String()
+	}
 
 	// Gather all the running protocol infos (only once per protocol type)
 	for _, proto := range srv.Protocols {
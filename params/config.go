@@ -19,11 +19,15 @@ package params
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math/big"
+	"reflect"
+	"sync"
 
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/math"
 	"github.com/SecretBlockChain/go-secret/crypto"
+	"github.com/SecretBlockChain/go-secret/rlp"
 )
 
 //go:generate gencodec -type EqualityReward -field-override equalityRewardMarshaling -out gen_equality_reward.go
@@ -39,10 +43,28 @@ var (
 // the chain it belongs to.
 var TrustedCheckpoints = map[common.Hash]*TrustedCheckpoint{}
 
+// MainnetTrustedCheckpoint contains the light client trusted checkpoint for the
+// main network, as computed by `secret checkpoint` on a node serving light clients.
+// It is only registered once set, an empty checkpoint would reject every peer.
+var MainnetTrustedCheckpoint = &TrustedCheckpoint{}
+
+func init() {
+	if !MainnetTrustedCheckpoint.Empty() {
+		TrustedCheckpoints[MainnetGenesisHash] = MainnetTrustedCheckpoint
+	}
+	if MainnetCheckpointOracle.Address != (common.Address{}) {
+		CheckpointOracles[MainnetGenesisHash] = MainnetCheckpointOracle
+	}
+}
+
 // CheckpointOracles associates each known checkpoint oracles with the genesis hash of
 // the chain it belongs to.
 var CheckpointOracles = map[common.Hash]*CheckpointOracleConfig{}
 
+// MainnetCheckpointOracle contains the checkpoint oracle deployed on the main
+// network with `checkpoint-admin deploy`. It is only registered once deployed.
+var MainnetCheckpointOracle = &CheckpointOracleConfig{}
+
 var (
 	// MainnetChainConfig is the chain parameters to run a node on the main network.
 	MainnetChainConfig = &ChainConfig{
@@ -83,16 +105,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -163,10 +185,21 @@ type ChainConfig struct {
 	PetersburgBlock     *big.Int `json:"petersburgBlock,omitempty"`     // Petersburg switch block (nil = same as Constantinople)
 	IstanbulBlock       *big.Int `json:"istanbulBlock,omitempty"`       // Istanbul switch block (nil = no fork, 0 = already on istanbul)
 	MuirGlacierBlock    *big.Int `json:"muirGlacierBlock,omitempty"`    // Eip-2384 (bomb delay) switch block (nil = no fork, 0 = already activated)
+	BerlinBlock         *big.Int `json:"berlinBlock,omitempty"`         // Berlin switch block (nil = no fork, 0 = already on berlin)
+	LondonBlock         *big.Int `json:"londonBlock,omitempty"`         // London switch block (nil = no fork, 0 = already on london)
 
 	YoloV1Block *big.Int `json:"yoloV1Block,omitempty"` // YOLO v1: https://github.com/ethereum/EIPs/pull/2657 (Ephemeral testnet)
 	EWASMBlock  *big.Int `json:"ewasmBlock,omitempty"`  // EWASM switch block (nil = no fork, 0 = already activated)
 
+	EqualityBlock            *big.Int `json:"equalityBlock,omitempty"`            // Switch block to the equality engine (nil = equality from genesis, if configured)
+	ConsensusPrecompileBlock *big.Int `json:"consensusPrecompileBlock,omitempty"` // Consensus precompile switch block (nil = no fork, 0 = already activated)
+	StakingBlock             *big.Int `json:"stakingBlock,omitempty"`             // Native staking contract switch block (nil = no fork, 0 = already activated)
+	RewardLogBlock           *big.Int `json:"rewardLogBlock,omitempty"`           // Reward log switch block (nil = no fork, 0 = already activated)
+	CandidateStatusBlock     *big.Int `json:"candidateStatusBlock,omitempty"`     // Candidate status precompile switch block (nil = no fork, 0 = already activated)
+	ReplayProtectionBlock    *big.Int `json:"replayProtectionBlock,omitempty"`    // Custom transaction replay protection switch block (nil = no fork, 0 = already activated)
+	SignerKeyBlock           *big.Int `json:"signerKeyBlock,omitempty"`           // Signer key binding switch block (nil = no fork, 0 = already activated)
+	PayoutBlock              *big.Int `json:"payoutBlock,omitempty"`              // Reward payout switch block (nil = no fork, 0 = already activated)
+
 	// Various consensus engines
 	Ethash   *EthashConfig   `json:"ethash,omitempty"`
 	Clique   *CliqueConfig   `json:"clique,omitempty"`
@@ -202,14 +235,311 @@ type EqualityRewards []EqualityReward
 
 // EqualityConfig is the consensus engine configs for proof-of-equality based sealing.
 type EqualityConfig struct {
-	Period              uint64           `json:"period"`                                  // Number of seconds between blocks to enforce
-	Epoch               uint64           `json:"epoch"`                                   // Epoch length to reset votes and checkpoint
-	MaxValidatorsCount  uint64           `json:"maxValidatorsCount"`                      // Max count of validators
-	MinCandidateBalance *big.Int         `json:"minCandidateBalance" gencodec:"required"` // Min candidate balance to valid this candidate
-	GenesisTimestamp    uint64           `json:"genesisTimestamp"`                        // The timestamp of first Block
-	Validators          []common.Address `json:"validators"`                              // Genesis validator list
-	Pool                common.Address   `json:"pool"`                                    // Deposit pool address
-	Rewards             EqualityRewards  `json:"rewards"`                                 // Reward rule of mint block
+	Period              uint64             `json:"period"`                                  // Number of seconds between blocks to enforce
+	Epoch               uint64             `json:"epoch"`                                   // Epoch length to reset votes and checkpoint
+	MaxValidatorsCount  uint64             `json:"maxValidatorsCount"`                      // Max count of validators
+	MinCandidateBalance *big.Int           `json:"minCandidateBalance" gencodec:"required"` // Min candidate balance to valid this candidate
+	GenesisTimestamp    uint64             `json:"genesisTimestamp"`                        // The timestamp of first Block
+	Validators          []common.Address   `json:"validators"`                              // Genesis validator list
+	Pool                common.Address     `json:"pool"`                                    // Deposit pool address
+	Rewards             EqualityRewards    `json:"rewards"`                                 // Reward rule of mint block
+	CandidateExpiry     uint64             `json:"candidateExpiry,omitempty"`               // Epochs of inactivity after which a candidate is canceled (0 = never)
+	ProposalWindow      uint64             `json:"proposalWindow,omitempty"`                // Epochs the validators have to approve a config proposal (0 = no governance)
+	ProposalQuorum      uint64             `json:"proposalQuorum,omitempty"`                // Percentage of the validators approving a proposal (0 = two thirds)
+	ProposalDeposit     *big.Int           `json:"proposalDeposit,omitempty"`               // Deposit locked by a proposal, refunded once approved and burned otherwise
+	Overrides           []EqualityOverride `json:"overrides,omitempty"`                     // Parameter changes agreed on off-chain, ordered by block
+	Forks               []EqualityFork     `json:"forks,omitempty"`                         // Protocol forks scheduled by the validators, ordered by block
+
+	SystemContracts    []EqualitySystemContract `json:"systemContracts,omitempty"`    // Contracts deployed at genesis the engine keeps consensus data in
+	AllowUnprotectedTx bool                     `json:"allowUnprotectedTx,omitempty"` // Accept custom transactions without replay protection, for the legacy signers
+	BurnBaseFee        bool                     `json:"burnBaseFee,omitempty"`        // Burn the EIP-1559 base fee instead of crediting it to the pool
+}
+
+// EqualityFork is the activation block of a protocol fork scheduled by the
+// validators through governance, kept in the stored chain config so that the
+// fork survives restarts with a genesis lacking it.
+type EqualityFork struct {
+	Name  string `json:"name"`
+	Block uint64 `json:"block"`
+}
+
+// SchedulableForks are the names of the protocol forks the validators of an
+// equality chain may schedule through governance. They only change how blocks
+// are executed, which happens block by block once the approving block is
+// canonical. Forks changing the validity of the headers, like london and its
+// base fee, are left to the genesis: headers are verified ahead of the blocks
+// in batches, and never executed at all by fast sync and light clients.
+var SchedulableForks = []string{"consensusPrecompile", "staking", "rewardLog", "candidateStatus", "replayProtection", "signerKey", "payout"}
+
+// EqualitySystemContract is a contract deployed through the genesis alloc, or
+// with the built-in code of its kind if the alloc has none, the engine writing
+// the consensus data of its kind into its storage at each epoch so that other
+// contracts can read it. Like the overrides, system contracts are part of the
+// genesis only and never carried in the headers.
+type EqualitySystemContract struct {
+	Name    string         `json:"name"`              // Kind of the contract, one of SystemContractAddresses
+	Address common.Address `json:"address,omitempty"` // Zero for the reserved address of its kind
+}
+
+// SystemContractAddresses are the kinds of system contracts the engine knows,
+// along with the reserved addresses they are deployed at unless told otherwise.
+var SystemContractAddresses = map[string]common.Address{
+	"governance": common.HexToAddress("0x000000000000000000000000000000000000e001"),
+	"staking":    common.HexToAddress("0x000000000000000000000000000000000000e002"),
+}
+
+// VestingContractAddress is the reserved address of the contract generated at
+// genesis to hold the balances of the genesis accounts released over time.
+var VestingContractAddress = common.HexToAddress("0x000000000000000000000000000000000000e003")
+
+// ConsensusPrecompileAddress is the address of the precompiled contract reading
+// the epoch and the validators of the block being executed, active from the
+// consensus precompile fork on.
+var ConsensusPrecompileAddress = common.HexToAddress("0x000000000000000000000000000000000000e004")
+
+// StakingContractAddress is the address of the native contract candidates call
+// to register and cancel, active from the staking fork on.
+var StakingContractAddress = common.HexToAddress("0x000000000000000000000000000000000000e005")
+
+// RewardLogAddress is the reserved address the logs of the block rewards are
+// emitted from, from the reward log fork on, no key or code standing behind it.
+var RewardLogAddress = common.HexToAddress("0x000000000000000000000000000000000000e006")
+
+// CandidateStatusPrecompileAddress is the address of the precompiled contract
+// reading the candidacy and the validator status of an address in an epoch,
+// active from the candidate status fork on.
+var CandidateStatusPrecompileAddress = common.HexToAddress("0x000000000000000000000000000000000000e007")
+
+// SystemContract returns the address of the system contract of the given kind,
+// false if the genesis doesn't deploy one.
+func (c *EqualityConfig) SystemContract(name string) (common.Address, bool) {
+	for _, contract := range c.SystemContracts {
+		if contract.Name != name {
+			continue
+		}
+		if contract.Address == (common.Address{}) {
+			return SystemContractAddresses[name], true
+		}
+		return contract.Address, true
+	}
+	return common.Address{}, false
+}
+
+// forkBlock returns the field of the chain config holding the activation block
+// of the schedulable fork, nil if there is no such fork.
+func (c *ChainConfig) forkBlock(name string) **big.Int {
+	switch name {
+	case "consensusPrecompile":
+		return &c.ConsensusPrecompileBlock
+	case "staking":
+		return &c.StakingBlock
+	case "rewardLog":
+		return &c.RewardLogBlock
+	case "candidateStatus":
+		return &c.CandidateStatusBlock
+	case "replayProtection":
+		return &c.ReplayProtectionBlock
+	case "signerKey":
+		return &c.SignerKeyBlock
+	case "payout":
+		return &c.PayoutBlock
+	}
+	return nil
+}
+
+// forkLock protects the activation blocks of the schedulable forks, which the
+// validators of an equality chain set while the chain config is in use.
+var forkLock sync.RWMutex
+
+// isScheduled returns whether num is either equal to the activation block of the
+// schedulable fork or greater.
+func (c *ChainConfig) isScheduled(fork **big.Int, num *big.Int) bool {
+	forkLock.RLock()
+	defer forkLock.RUnlock()
+
+	return isForked(*fork, num)
+}
+
+// ScheduleFork sets the activation block of the schedulable fork, which must not
+// be scheduled yet at another block, keeping the forks ordered.
+func (c *ChainConfig) ScheduleFork(name string, block uint64) error {
+	forkLock.Lock()
+	defer forkLock.Unlock()
+
+	return c.scheduleFork(name, block)
+}
+
+// ScheduleEqualityFork schedules the fork approved by the validators of an
+// equality chain, recording it in the engine parameters. It returns false if
+// the fork was scheduled already.
+func (c *ChainConfig) ScheduleEqualityFork(fork EqualityFork) (bool, error) {
+	forkLock.Lock()
+	defer forkLock.Unlock()
+
+	if c.Equality == nil || c.Equality.HasFork(fork) {
+		return false, nil
+	}
+	if err := c.scheduleFork(fork.Name, fork.Block); err != nil {
+		return false, err
+	}
+	c.Equality.Forks = append(c.Equality.Forks, fork)
+	return true, nil
+}
+
+func (c *ChainConfig) scheduleFork(name string, block uint64) error {
+	fork := c.forkBlock(name)
+	if fork == nil {
+		return fmt.Errorf("unknown fork %q", name)
+	}
+	if *fork != nil {
+		if (*fork).Uint64() == block {
+			return nil
+		}
+		return fmt.Errorf("fork %s already scheduled at block %v", name, *fork)
+	}
+	*fork = new(big.Int).SetUint64(block)
+	if err := c.CheckConfigForkOrder(); err != nil {
+		*fork = nil
+		return err
+	}
+	return nil
+}
+
+// EqualityOverride is a change of the equality parameters agreed on off-chain by
+// the operators of a network, e.g. to react to an emergency. The new parameters
+// are recorded in the given block and apply to its descendants.
+type EqualityOverride struct {
+	Block  uint64          `json:"block"`
+	Period *uint64         `json:"period,omitempty"`
+	Epoch  *uint64         `json:"epoch,omitempty"`
+	Pool   *common.Address `json:"pool,omitempty"`   // Moves the pool, e.g. once its key is lost or to hand it over to a contract
+	Resume bool            `json:"resume,omitempty"` // Lifts the halt voted by the validators before the block
+}
+
+// Apply returns a copy of the config with the overridden parameters changed.
+// The schedule of overrides is not part of the result.
+func (o *EqualityOverride) Apply(config EqualityConfig) EqualityConfig {
+	if o.Period != nil {
+		config.Period = *o.Period
+	}
+	if o.Epoch != nil {
+		config.Epoch = *o.Epoch
+	}
+	if o.Pool != nil {
+		config.Pool = *o.Pool
+	}
+	config.Overrides = nil
+	return config
+}
+
+// Resumed returns whether an override lifts a halt at the given height for the
+// block of the given number.
+func (c *EqualityConfig) Resumed(halt, number uint64) bool {
+	for _, override := range c.Overrides {
+		if override.Resume && override.Block > halt && override.Block <= number {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredDeposit returns the deposit locked by a proposal, zero if proposals
+// are free.
+func (c *EqualityConfig) RequiredDeposit() *big.Int {
+	if c.ProposalDeposit == nil {
+		return new(big.Int)
+	}
+	return c.ProposalDeposit
+}
+
+// HasFork returns whether the validators scheduled the fork.
+func (c *EqualityConfig) HasFork(fork EqualityFork) bool {
+	for _, scheduled := range c.Forks {
+		if scheduled == fork {
+			return true
+		}
+	}
+	return false
+}
+
+// OverrideAt returns the override recorded in the given block, if any.
+func (c *EqualityConfig) OverrideAt(number uint64) *EqualityOverride {
+	for i := range c.Overrides {
+		if c.Overrides[i].Block == number {
+			return &c.Overrides[i]
+		}
+	}
+	return nil
+}
+
+// equalityConfigRLP is the RLP layout of EqualityConfig. Fields added after the
+// launch of the chain are carried in the tail, so that the configs recorded in
+// existing headers keep decoding.
+type equalityConfigRLP struct {
+	Period              uint64
+	Epoch               uint64
+	MaxValidatorsCount  uint64
+	MinCandidateBalance *big.Int
+	GenesisTimestamp    uint64
+	Validators          []common.Address
+	Pool                common.Address
+	Rewards             EqualityRewards
+	Tail                []*big.Int `rlp:"tail"` // CandidateExpiry, ProposalWindow, ProposalQuorum and ProposalDeposit, trailing zeros omitted
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (c EqualityConfig) EncodeRLP(w io.Writer) error {
+	enc := equalityConfigRLP{
+		Period:              c.Period,
+		Epoch:               c.Epoch,
+		MaxValidatorsCount:  c.MaxValidatorsCount,
+		MinCandidateBalance: c.MinCandidateBalance,
+		GenesisTimestamp:    c.GenesisTimestamp,
+		Validators:          c.Validators,
+		Pool:                c.Pool,
+		Rewards:             c.Rewards,
+	}
+	enc.Tail = []*big.Int{
+		new(big.Int).SetUint64(c.CandidateExpiry),
+		new(big.Int).SetUint64(c.ProposalWindow),
+		new(big.Int).SetUint64(c.ProposalQuorum),
+		c.RequiredDeposit(),
+	}
+	for len(enc.Tail) > 0 && enc.Tail[len(enc.Tail)-1].Sign() == 0 {
+		enc.Tail = enc.Tail[:len(enc.Tail)-1]
+	}
+	return rlp.Encode(w, &enc)
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (c *EqualityConfig) DecodeRLP(s *rlp.Stream) error {
+	var dec equalityConfigRLP
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	*c = EqualityConfig{
+		Period:              dec.Period,
+		Epoch:               dec.Epoch,
+		MaxValidatorsCount:  dec.MaxValidatorsCount,
+		MinCandidateBalance: dec.MinCandidateBalance,
+		GenesisTimestamp:    dec.GenesisTimestamp,
+		Validators:          dec.Validators,
+		Pool:                dec.Pool,
+		Rewards:             dec.Rewards,
+	}
+	for idx, field := range []*uint64{&c.CandidateExpiry, &c.ProposalWindow, &c.ProposalQuorum} {
+		if idx >= len(dec.Tail) {
+			break
+		}
+		if !dec.Tail[idx].IsUint64() {
+			return fmt.Errorf("equality config field %d overflows uint64", 8+idx)
+		}
+		*field = dec.Tail[idx].Uint64()
+	}
+	if len(dec.Tail) > 3 {
+		c.ProposalDeposit = dec.Tail[3]
+	}
+	return nil
 }
 
 type equalityRewardMarshaling struct {
@@ -226,6 +556,12 @@ type equalityConfigMarshaling struct {
 	Validators          []common.Address
 	Pool                common.Address
 	Rewards             EqualityRewards
+	CandidateExpiry     uint64
+	ProposalWindow      uint64
+	ProposalQuorum      uint64
+	ProposalDeposit     *math.HexOrDecimal256
+	Overrides           []EqualityOverride
+	Forks               []EqualityFork
 }
 
 // MainNetEqualityConfig returns mainnet config of equality consensus engine.
@@ -314,6 +650,46 @@ func TestnetEqualityConfig() *EqualityConfig {
 	}
 }
 
+// Copy returns a deep copy of the config, sharing neither its lists nor its
+// balances.
+func (c *EqualityConfig) Copy() *EqualityConfig {
+	cpy := *c
+	cpy.Validators = append([]common.Address(nil), c.Validators...)
+	cpy.Rewards = nil
+	for _, reward := range c.Rewards {
+		cpy.Rewards = append(cpy.Rewards, EqualityReward{Number: reward.Number, Reward: copyBig(reward.Reward)})
+	}
+	cpy.MinCandidateBalance = copyBig(c.MinCandidateBalance)
+	cpy.ProposalDeposit = copyBig(c.ProposalDeposit)
+	cpy.Overrides = nil
+	for _, override := range c.Overrides {
+		if override.Period != nil {
+			period := *override.Period
+			override.Period = &period
+		}
+		if override.Epoch != nil {
+			epoch := *override.Epoch
+			override.Epoch = &epoch
+		}
+		if override.Pool != nil {
+			pool := *override.Pool
+			override.Pool = &pool
+		}
+		cpy.Overrides = append(cpy.Overrides, override)
+	}
+	cpy.Forks = append([]EqualityFork(nil), c.Forks...)
+	cpy.SystemContracts = append([]EqualitySystemContract(nil), c.SystemContracts...)
+	return &cpy
+}
+
+// copyBig returns a copy of the number, nil if nil.
+func copyBig(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+	return new(big.Int).Set(x)
+}
+
 // String implements the stringer interface, returning the consensus engine details.
 func (c *EqualityConfig) String() string {
 	return "equality"
@@ -339,6 +715,18 @@ func (c *EqualityConfig) Equal(other EqualityConfig) bool {
 	if c.GenesisTimestamp != other.GenesisTimestamp {
 		return false
 	}
+	if c.CandidateExpiry != other.CandidateExpiry {
+		return false
+	}
+	if c.ProposalWindow != other.ProposalWindow {
+		return false
+	}
+	if c.ProposalQuorum != other.ProposalQuorum {
+		return false
+	}
+	if c.RequiredDeposit().Cmp(other.RequiredDeposit()) != 0 {
+		return false
+	}
 
 	if len(c.Validators) != len(other.Validators) {
 		return false
@@ -363,6 +751,100 @@ func (c *EqualityConfig) Equal(other EqualityConfig) bool {
 	return true
 }
 
+// Validate checks the sanity of the equality engine configuration, so that a
+// broken genesis is rejected before the engine trips over it while minting.
+func (c *EqualityConfig) Validate() error {
+	if c.Period == 0 {
+		return fmt.Errorf("invalid equality config: period must be positive")
+	}
+	if c.Epoch == 0 {
+		return fmt.Errorf("invalid equality config: epoch must be positive")
+	}
+	if c.MaxValidatorsCount == 0 {
+		return fmt.Errorf("invalid equality config: maxValidatorsCount must be positive")
+	}
+	if c.MinCandidateBalance == nil || c.MinCandidateBalance.Sign() < 0 {
+		return fmt.Errorf("invalid equality config: minCandidateBalance must be set")
+	}
+	if len(c.Validators) == 0 {
+		return fmt.Errorf("invalid equality config: no genesis validators")
+	}
+	listed := make(map[common.Address]bool, len(c.Validators))
+	for idx, validator := range c.Validators {
+		if listed[validator] {
+			return fmt.Errorf("invalid equality config: validator #%d %s listed twice", idx, validator.Hex())
+		}
+		listed[validator] = true
+	}
+	rewarded := false
+	for idx, reward := range c.Rewards {
+		if reward.Reward == nil || reward.Reward.Sign() < 0 {
+			return fmt.Errorf("invalid equality config: reward #%d must be set", idx)
+		}
+		if idx > 0 && reward.Number <= c.Rewards[idx-1].Number {
+			return fmt.Errorf("invalid equality config: reward #%d at block %d not sorted after block %d",
+				idx, reward.Number, c.Rewards[idx-1].Number)
+		}
+		rewarded = rewarded || reward.Reward.Sign() > 0
+	}
+	if rewarded && c.Pool == (common.Address{}) {
+		return fmt.Errorf("invalid equality config: pool address missing while blocks are rewarded")
+	}
+	if c.ProposalQuorum != 0 && (c.ProposalQuorum <= 50 || c.ProposalQuorum > 100) {
+		return fmt.Errorf("invalid equality config: proposalQuorum must be above 50 and at most 100 percent")
+	}
+	if c.ProposalDeposit != nil && c.ProposalDeposit.Sign() < 0 {
+		return fmt.Errorf("invalid equality config: proposalDeposit must not be negative")
+	}
+	for idx, override := range c.Overrides {
+		if override.Block == 0 {
+			return fmt.Errorf("invalid equality config: override #%d at genesis", idx)
+		}
+		if idx > 0 && override.Block <= c.Overrides[idx-1].Block {
+			return fmt.Errorf("invalid equality config: override #%d at block %d not sorted after block %d",
+				idx, override.Block, c.Overrides[idx-1].Block)
+		}
+		if override.Period == nil && override.Epoch == nil && override.Pool == nil && !override.Resume {
+			return fmt.Errorf("invalid equality config: override #%d changes nothing", idx)
+		}
+		if override.Period != nil && *override.Period == 0 || override.Epoch != nil && *override.Epoch == 0 {
+			return fmt.Errorf("invalid equality config: override #%d must keep period and epoch positive", idx)
+		}
+		if override.Pool != nil && *override.Pool == (common.Address{}) {
+			return fmt.Errorf("invalid equality config: override #%d moves the pool to the zero address", idx)
+		}
+	}
+	for idx, fork := range c.Forks {
+		if (&ChainConfig{}).forkBlock(fork.Name) == nil {
+			return fmt.Errorf("invalid equality config: fork #%d %q can't be scheduled", idx, fork.Name)
+		}
+		if idx > 0 && fork.Block < c.Forks[idx-1].Block {
+			return fmt.Errorf("invalid equality config: fork #%d at block %d not sorted after block %d",
+				idx, fork.Block, c.Forks[idx-1].Block)
+		}
+	}
+	var (
+		declared = make(map[string]bool, len(c.SystemContracts))
+		deployed = make(map[common.Address]bool, len(c.SystemContracts))
+	)
+	for idx, contract := range c.SystemContracts {
+		if _, ok := SystemContractAddresses[contract.Name]; !ok {
+			return fmt.Errorf("invalid equality config: system contract #%d of unknown kind %q", idx, contract.Name)
+		}
+		if declared[contract.Name] {
+			return fmt.Errorf("invalid equality config: system contract #%d %s declared twice", idx, contract.Name)
+		}
+		declared[contract.Name] = true
+
+		address, _ := c.SystemContract(contract.Name)
+		if deployed[address] {
+			return fmt.Errorf("invalid equality config: system contract #%d %s shares address %s", idx, contract.Name, address.Hex())
+		}
+		deployed[address] = true
+	}
+	return nil
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -376,7 +858,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, YOLO v1: %v, Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, YOLO v1: %v, Equality: %v, Consensus precompile: %v, Staking: %v, Reward log: %v, Candidate status: %v, Replay protection: %v, Signer key: %v, Payout: %v, Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -389,7 +871,17 @@ func (c *ChainConfig) String() string {
 		c.PetersburgBlock,
 		c.IstanbulBlock,
 		c.MuirGlacierBlock,
+		c.BerlinBlock,
+		c.LondonBlock,
 		c.YoloV1Block,
+		c.EqualityBlock,
+		c.ConsensusPrecompileBlock,
+		c.StakingBlock,
+		c.RewardLogBlock,
+		c.CandidateStatusBlock,
+		c.ReplayProtectionBlock,
+		c.SignerKeyBlock,
+		c.PayoutBlock,
 		engine,
 	)
 }
@@ -446,6 +938,63 @@ func (c *ChainConfig) IsIstanbul(num *big.Int) bool {
 	return isForked(c.IstanbulBlock, num)
 }
 
+// IsBerlin returns whether num is either equal to the Berlin fork block or greater.
+func (c *ChainConfig) IsBerlin(num *big.Int) bool {
+	return c.isScheduled(&c.BerlinBlock, num)
+}
+
+// IsLondon returns whether num is either equal to the London fork block or greater.
+func (c *ChainConfig) IsLondon(num *big.Int) bool {
+	return c.isScheduled(&c.LondonBlock, num)
+}
+
+// IsEquality returns whether num is sealed by the equality engine, either because
+// the chain runs it from genesis or because num is past the engine switch block.
+func (c *ChainConfig) IsEquality(num *big.Int) bool {
+	return c.Equality != nil && (c.EqualityBlock == nil || isForked(c.EqualityBlock, num))
+}
+
+// IsConsensusPrecompile returns whether num is either equal to the consensus
+// precompile fork block or greater.
+func (c *ChainConfig) IsConsensusPrecompile(num *big.Int) bool {
+	return c.isScheduled(&c.ConsensusPrecompileBlock, num)
+}
+
+// IsStaking returns whether num is either equal to the staking fork block or
+// greater.
+func (c *ChainConfig) IsStaking(num *big.Int) bool {
+	return c.isScheduled(&c.StakingBlock, num)
+}
+
+// IsRewardLog returns whether num is either equal to the reward log fork block
+// or greater.
+func (c *ChainConfig) IsRewardLog(num *big.Int) bool {
+	return c.isScheduled(&c.RewardLogBlock, num)
+}
+
+// IsCandidateStatus returns whether num is either equal to the candidate status
+// fork block or greater.
+func (c *ChainConfig) IsCandidateStatus(num *big.Int) bool {
+	return c.isScheduled(&c.CandidateStatusBlock, num)
+}
+
+// IsReplayProtection returns whether num is either equal to the replay protection
+// fork block or greater.
+func (c *ChainConfig) IsReplayProtection(num *big.Int) bool {
+	return c.isScheduled(&c.ReplayProtectionBlock, num)
+}
+
+// IsSignerKey returns whether num is either equal to the signer key fork block or
+// greater.
+func (c *ChainConfig) IsSignerKey(num *big.Int) bool {
+	return c.isScheduled(&c.SignerKeyBlock, num)
+}
+
+// IsPayout returns whether num is either equal to the payout fork block or greater.
+func (c *ChainConfig) IsPayout(num *big.Int) bool {
+	return c.isScheduled(&c.PayoutBlock, num)
+}
+
 // IsYoloV1 returns whether num is either equal to the YoloV1 fork block or greater.
 func (c *ChainConfig) IsYoloV1(num *big.Int) bool {
 	return isForked(c.YoloV1Block, num)
@@ -494,7 +1043,9 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		{name: "petersburgBlock", block: c.PetersburgBlock},
 		{name: "istanbulBlock", block: c.IstanbulBlock},
 		{name: "muirGlacierBlock", block: c.MuirGlacierBlock, optional: true},
-		{name: "yoloV1Block", block: c.YoloV1Block},
+		{name: "yoloV1Block", block: c.YoloV1Block, optional: true},
+		{name: "berlinBlock", block: c.BerlinBlock},
+		{name: "londonBlock", block: c.LondonBlock},
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -558,15 +1109,113 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.MuirGlacierBlock, newcfg.MuirGlacierBlock, head) {
 		return newCompatError("Muir Glacier fork block", c.MuirGlacierBlock, newcfg.MuirGlacierBlock)
 	}
+	if isForkIncompatible(c.BerlinBlock, newcfg.BerlinBlock, head) {
+		return newCompatError("Berlin fork block", c.BerlinBlock, newcfg.BerlinBlock)
+	}
+	if isForkIncompatible(c.LondonBlock, newcfg.LondonBlock, head) {
+		return newCompatError("London fork block", c.LondonBlock, newcfg.LondonBlock)
+	}
 	if isForkIncompatible(c.YoloV1Block, newcfg.YoloV1Block, head) {
 		return newCompatError("YOLOv1 fork block", c.YoloV1Block, newcfg.YoloV1Block)
 	}
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.EqualityBlock, newcfg.EqualityBlock, head) {
+		return newCompatError("equality fork block", c.EqualityBlock, newcfg.EqualityBlock)
+	}
+	if isForkIncompatible(c.ConsensusPrecompileBlock, newcfg.ConsensusPrecompileBlock, head) {
+		return newCompatError("consensus precompile fork block", c.ConsensusPrecompileBlock, newcfg.ConsensusPrecompileBlock)
+	}
+	if isForkIncompatible(c.StakingBlock, newcfg.StakingBlock, head) {
+		return newCompatError("staking fork block", c.StakingBlock, newcfg.StakingBlock)
+	}
+	if isForkIncompatible(c.RewardLogBlock, newcfg.RewardLogBlock, head) {
+		return newCompatError("reward log fork block", c.RewardLogBlock, newcfg.RewardLogBlock)
+	}
+	if isForkIncompatible(c.CandidateStatusBlock, newcfg.CandidateStatusBlock, head) {
+		return newCompatError("candidate status fork block", c.CandidateStatusBlock, newcfg.CandidateStatusBlock)
+	}
+	if isForkIncompatible(c.ReplayProtectionBlock, newcfg.ReplayProtectionBlock, head) {
+		return newCompatError("replay protection fork block", c.ReplayProtectionBlock, newcfg.ReplayProtectionBlock)
+	}
+	if isForkIncompatible(c.SignerKeyBlock, newcfg.SignerKeyBlock, head) {
+		return newCompatError("signer key fork block", c.SignerKeyBlock, newcfg.SignerKeyBlock)
+	}
+	if isForkIncompatible(c.PayoutBlock, newcfg.PayoutBlock, head) {
+		return newCompatError("payout fork block", c.PayoutBlock, newcfg.PayoutBlock)
+	}
+	return c.checkEqualityCompatible(newcfg, head)
+}
+
+// checkEqualityCompatible checks the equality parameters the chain was minted
+// with from its first equality block on. Once the head is past that block, they
+// can only change by rewinding the chain to before it.
+func (c *ChainConfig) checkEqualityCompatible(newcfg *ChainConfig, head *big.Int) *ConfigCompatError {
+	start := c.equalityStart()
+	if start == nil || !isForked(start, head) {
+		return nil
+	}
+	var (
+		stored = c.Equality
+		config = newcfg.Equality
+	)
+	switch {
+	case config == nil:
+		return newCompatError("equality engine", start, start)
+	case stored.Period != config.Period:
+		return newCompatError("equality period", start, start)
+	case stored.Epoch != config.Epoch:
+		return newCompatError("equality epoch", start, start)
+	case stored.GenesisTimestamp != config.GenesisTimestamp:
+		return newCompatError("equality genesis timestamp", start, start)
+	case stored.Pool != config.Pool:
+		// The pool of a running chain moves through an override only
+		return newCompatError("equality pool", start, start)
+	case len(stored.Validators) != len(config.Validators):
+		return newCompatError("equality validators", start, start)
+	}
+	for i, validator := range stored.Validators {
+		if config.Validators[i] != validator {
+			return newCompatError("equality validators", start, start)
+		}
+	}
+	// Overrides already recorded in the chain cannot change any more
+	for _, override := range append(append([]EqualityOverride{}, stored.Overrides...), config.Overrides...) {
+		if override.Block > head.Uint64() {
+			continue
+		}
+		have, want := stored.OverrideAt(override.Block), config.OverrideAt(override.Block)
+		if have == nil || want == nil || !reflect.DeepEqual(have, want) {
+			block := new(big.Int).SetUint64(override.Block)
+			return newCompatError("equality override", block, block)
+		}
+	}
+	// Forks scheduled by the validators and already reached cannot change either
+	for _, fork := range append(append([]EqualityFork{}, stored.Forks...), config.Forks...) {
+		if fork.Block > head.Uint64() {
+			continue
+		}
+		if !stored.HasFork(fork) || !config.HasFork(fork) {
+			block := new(big.Int).SetUint64(fork.Block)
+			return newCompatError("equality fork "+fork.Name, block, block)
+		}
+	}
 	return nil
 }
 
+// equalityStart returns the number of the first block minted by the equality
+// engine, or nil if the chain does not use it.
+func (c *ChainConfig) equalityStart() *big.Int {
+	if c.Equality == nil {
+		return nil
+	}
+	if c.EqualityBlock == nil || c.EqualityBlock.Sign() == 0 {
+		return big.NewInt(1)
+	}
+	return c.EqualityBlock
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
@@ -631,7 +1280,9 @@ type Rules struct {
 	ChainID                                                 *big.Int
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158               bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
+	IsBerlin, IsLondon                                      bool
 	IsYoloV1                                                bool
+	IsConsensusPrecompile, IsStaking, IsCandidateStatus     bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -650,6 +1301,12 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsConstantinople: c.IsConstantinople(num),
 		IsPetersburg:     c.IsPetersburg(num),
 		IsIstanbul:       c.IsIstanbul(num),
+		IsBerlin:         c.IsBerlin(num),
+		IsLondon:         c.IsLondon(num),
 		IsYoloV1:         c.IsYoloV1(num),
+
+		IsConsensusPrecompile: c.IsConsensusPrecompile(num),
+		IsStaking:             c.IsStaking(num),
+		IsCandidateStatus:     c.IsCandidateStatus(num),
 	}
 }
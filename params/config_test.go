@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/SecretBlockChain/go-secret/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -87,6 +89,151 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     30,
 			},
 		},
+		{
+			stored:  &ChainConfig{BerlinBlock: big.NewInt(30)},
+			new:     &ChainConfig{BerlinBlock: big.NewInt(40)},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{BerlinBlock: big.NewInt(30), LondonBlock: big.NewInt(50)},
+			new:    &ChainConfig{BerlinBlock: big.NewInt(30), LondonBlock: big.NewInt(60)},
+			head:   55,
+			wantErr: &ConfigCompatError{
+				What:         "London fork block",
+				StoredConfig: big.NewInt(50),
+				NewConfig:    big.NewInt(60),
+				RewindTo:     49,
+			},
+		},
+		{
+			stored: &ChainConfig{EqualityBlock: big.NewInt(100)},
+			new:    &ChainConfig{EqualityBlock: big.NewInt(200)},
+			head:   150,
+			wantErr: &ConfigCompatError{
+				What:         "equality fork block",
+				StoredConfig: big.NewInt(100),
+				NewConfig:    big.NewInt(200),
+				RewindTo:     99,
+			},
+		},
+		{
+			stored: &ChainConfig{ConsensusPrecompileBlock: big.NewInt(100)},
+			new:    &ChainConfig{},
+			head:   150,
+			wantErr: &ConfigCompatError{
+				What:         "consensus precompile fork block",
+				StoredConfig: big.NewInt(100),
+				NewConfig:    nil,
+				RewindTo:     99,
+			},
+		},
+		{
+			stored: &ChainConfig{StakingBlock: big.NewInt(100)},
+			new:    &ChainConfig{StakingBlock: big.NewInt(200)},
+			head:   150,
+			wantErr: &ConfigCompatError{
+				What:         "staking fork block",
+				StoredConfig: big.NewInt(100),
+				NewConfig:    big.NewInt(200),
+				RewindTo:     99,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{RewardLogBlock: big.NewInt(100)},
+			head:   150,
+			wantErr: &ConfigCompatError{
+				What:         "reward log fork block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(100),
+				RewindTo:     99,
+			},
+		},
+		{
+			stored: &ChainConfig{CandidateStatusBlock: big.NewInt(100)},
+			new:    &ChainConfig{CandidateStatusBlock: big.NewInt(120)},
+			head:   110,
+			wantErr: &ConfigCompatError{
+				What:         "candidate status fork block",
+				StoredConfig: big.NewInt(100),
+				NewConfig:    big.NewInt(120),
+				RewindTo:     99,
+			},
+		},
+		{
+			stored: &ChainConfig{ReplayProtectionBlock: big.NewInt(100)},
+			new:    &ChainConfig{},
+			head:   150,
+			wantErr: &ConfigCompatError{
+				What:         "replay protection fork block",
+				StoredConfig: big.NewInt(100),
+				NewConfig:    nil,
+				RewindTo:     99,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{SignerKeyBlock: big.NewInt(120)},
+			head:   150,
+			wantErr: &ConfigCompatError{
+				What:         "signer key fork block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(120),
+				RewindTo:     119,
+			},
+		},
+		{
+			stored: &ChainConfig{PayoutBlock: big.NewInt(100)},
+			new:    &ChainConfig{PayoutBlock: big.NewInt(200)},
+			head:   150,
+			wantErr: &ConfigCompatError{
+				What:         "payout fork block",
+				StoredConfig: big.NewInt(100),
+				NewConfig:    big.NewInt(200),
+				RewindTo:     99,
+			},
+		},
+		{
+			stored:  &ChainConfig{Equality: &EqualityConfig{Period: 3}},
+			new:     &ChainConfig{Equality: &EqualityConfig{Period: 3}},
+			head:    100,
+			wantErr: nil,
+		},
+		{
+			stored:  &ChainConfig{Equality: &EqualityConfig{Period: 3}},
+			new:     &ChainConfig{Equality: &EqualityConfig{Period: 5}},
+			head:    0,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Equality: &EqualityConfig{Period: 3}},
+			new:    &ChainConfig{Equality: &EqualityConfig{Period: 5}},
+			head:   100,
+			wantErr: &ConfigCompatError{
+				What:         "equality period",
+				StoredConfig: big.NewInt(1),
+				NewConfig:    big.NewInt(1),
+				RewindTo:     0,
+			},
+		},
+		{
+			stored: &ChainConfig{EqualityBlock: big.NewInt(50), Equality: &EqualityConfig{Validators: []common.Address{{0x01}}}},
+			new:    &ChainConfig{EqualityBlock: big.NewInt(50), Equality: &EqualityConfig{Validators: []common.Address{{0x02}}}},
+			head:   100,
+			wantErr: &ConfigCompatError{
+				What:         "equality validators",
+				StoredConfig: big.NewInt(50),
+				NewConfig:    big.NewInt(50),
+				RewindTo:     49,
+			},
+		},
+		{
+			stored:  &ChainConfig{EqualityBlock: big.NewInt(50), Equality: &EqualityConfig{Epoch: 100}},
+			new:     &ChainConfig{EqualityBlock: big.NewInt(50), Equality: &EqualityConfig{Epoch: 200}},
+			head:    40,
+			wantErr: nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -96,3 +243,237 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckConfigForkOrder(t *testing.T) {
+	config := &ChainConfig{
+		HomesteadBlock:      big.NewInt(0),
+		EIP150Block:         big.NewInt(0),
+		EIP155Block:         big.NewInt(0),
+		EIP158Block:         big.NewInt(0),
+		ByzantiumBlock:      big.NewInt(0),
+		ConstantinopleBlock: big.NewInt(0),
+		PetersburgBlock:     big.NewInt(0),
+		IstanbulBlock:       big.NewInt(0),
+		BerlinBlock:         big.NewInt(10),
+		LondonBlock:         big.NewInt(20),
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("valid fork order rejected: %v", err)
+	}
+	if rules := config.Rules(big.NewInt(15)); !rules.IsBerlin || rules.IsLondon {
+		t.Errorf("rules mismatch at block 15: berlin %v, london %v", rules.IsBerlin, rules.IsLondon)
+	}
+
+	config.BerlinBlock = nil
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Error("london enabled without berlin accepted")
+	}
+	config.BerlinBlock = big.NewInt(30)
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Error("london enabled before berlin accepted")
+	}
+}
+
+func TestIsEquality(t *testing.T) {
+	config := &ChainConfig{}
+	if config.IsEquality(big.NewInt(0)) {
+		t.Error("equality enabled without engine config")
+	}
+	config.Equality = &EqualityConfig{}
+	if !config.IsEquality(big.NewInt(0)) {
+		t.Error("equality disabled at genesis without switch block")
+	}
+	config.EqualityBlock = big.NewInt(10)
+	if config.IsEquality(big.NewInt(9)) || !config.IsEquality(big.NewInt(10)) {
+		t.Error("equality switch block not honoured")
+	}
+}
+
+func TestEqualityConfigValidate(t *testing.T) {
+	valid := func() *EqualityConfig {
+		return &EqualityConfig{
+			Period:              3,
+			Epoch:               100,
+			MaxValidatorsCount:  21,
+			MinCandidateBalance: big.NewInt(1000),
+			Validators:          []common.Address{{0x01}},
+			Pool:                common.Address{0x02},
+			Rewards:             EqualityRewards{{Number: 10, Reward: big.NewInt(1)}, {Number: 20, Reward: big.NewInt(0)}},
+		}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+	if err := MainNetEqualityConfig().Validate(); err != nil {
+		t.Errorf("mainnet config rejected: %v", err)
+	}
+	deployed := valid()
+	deployed.SystemContracts = []EqualitySystemContract{{Name: "staking"}, {Name: "governance", Address: common.Address{0x03}}}
+	if err := deployed.Validate(); err != nil {
+		t.Errorf("system contracts rejected: %v", err)
+	}
+	if address, ok := deployed.SystemContract("staking"); !ok || address != SystemContractAddresses["staking"] {
+		t.Errorf("staking contract mismatch: have %v, want reserved address", address)
+	}
+	if address, ok := deployed.SystemContract("governance"); !ok || address != (common.Address{0x03}) {
+		t.Errorf("governance contract mismatch: have %v, want 0x03", address)
+	}
+	resumed := valid()
+	resumed.Overrides = []EqualityOverride{{Block: 10, Resume: true}}
+	if err := resumed.Validate(); err != nil {
+		t.Errorf("resuming override rejected: %v", err)
+	}
+
+	tests := []func(c *EqualityConfig){
+		func(c *EqualityConfig) { c.Period = 0 },
+		func(c *EqualityConfig) { c.Epoch = 0 },
+		func(c *EqualityConfig) { c.MaxValidatorsCount = 0 },
+		func(c *EqualityConfig) { c.MinCandidateBalance = nil },
+		func(c *EqualityConfig) { c.Validators = nil },
+		func(c *EqualityConfig) { c.Validators = []common.Address{{0x01}, {0x01}} },
+		func(c *EqualityConfig) { c.SystemContracts = []EqualitySystemContract{{Name: "oracle"}} },
+		func(c *EqualityConfig) {
+			c.SystemContracts = []EqualitySystemContract{{Name: "staking"}, {Name: "staking"}}
+		},
+		func(c *EqualityConfig) {
+			c.SystemContracts = []EqualitySystemContract{{Name: "staking"}, {Name: "governance", Address: SystemContractAddresses["staking"]}}
+		},
+		func(c *EqualityConfig) { c.Rewards[1].Number = 10 },
+		func(c *EqualityConfig) { c.Rewards[0].Reward = nil },
+		func(c *EqualityConfig) { c.Pool = common.Address{} },
+		func(c *EqualityConfig) { c.ProposalQuorum = 50 },
+		func(c *EqualityConfig) { c.ProposalQuorum = 101 },
+		func(c *EqualityConfig) { c.Overrides = []EqualityOverride{{Block: 0, Period: &c.Period}} },
+		func(c *EqualityConfig) { c.Overrides = []EqualityOverride{{Block: 10}} },
+		func(c *EqualityConfig) { c.Overrides = []EqualityOverride{{Block: 10, Epoch: new(uint64)}} },
+		func(c *EqualityConfig) { c.Overrides = []EqualityOverride{{Block: 10, Pool: new(common.Address)}} },
+		func(c *EqualityConfig) {
+			c.Overrides = []EqualityOverride{{Block: 10, Period: &c.Period}, {Block: 10, Epoch: &c.Epoch}}
+		},
+	}
+	for i, mutate := range tests {
+		config := valid()
+		mutate(config)
+		if err := config.Validate(); err == nil {
+			t.Errorf("test %d: invalid config accepted", i)
+		}
+	}
+}
+
+func TestEqualityOverride(t *testing.T) {
+	period, epoch := uint64(5), uint64(200)
+	config := &EqualityConfig{
+		Period:    3,
+		Epoch:     100,
+		Overrides: []EqualityOverride{{Block: 10, Period: &period}, {Block: 20, Epoch: &epoch}},
+	}
+	if config.OverrideAt(15) != nil {
+		t.Fatal("override found at unscheduled block")
+	}
+	overridden := config.OverrideAt(10).Apply(*config)
+	if overridden.Period != 5 || overridden.Epoch != 100 || overridden.Overrides != nil {
+		t.Errorf("wrong override at block 10: period %d, epoch %d, overrides %v", overridden.Period, overridden.Epoch, overridden.Overrides)
+	}
+	overridden = config.OverrideAt(20).Apply(overridden)
+	if overridden.Period != 5 || overridden.Epoch != 200 {
+		t.Errorf("wrong override at block 20: period %d, epoch %d", overridden.Period, overridden.Epoch)
+	}
+
+	// The pool moves through an override, not by changing the genesis
+	pool := common.Address{0x02}
+	moved := (&EqualityOverride{Block: 40, Pool: &pool}).Apply(overridden)
+	if moved.Pool != pool || moved.Period != 5 || moved.Epoch != 200 {
+		t.Errorf("wrong pool override: pool %x, period %d, epoch %d", moved.Pool, moved.Period, moved.Epoch)
+	}
+	relocated := *config
+	relocated.Pool = pool
+	if err := (&ChainConfig{Equality: config}).CheckCompatible(&ChainConfig{Equality: &relocated}, 15); err == nil || err.What != "equality pool" {
+		t.Errorf("genesis pool change accepted: %v", err)
+	}
+
+	// Halts are lifted from the resuming override on, later halts are not
+	config.Overrides = append(config.Overrides, EqualityOverride{Block: 31, Resume: true})
+	if config.Resumed(30, 30) || !config.Resumed(30, 31) || config.Resumed(31, 40) {
+		t.Error("wrong halt resumption")
+	}
+	config.Overrides = config.Overrides[:2]
+
+	// Recorded overrides cannot change any more, the pending ones can
+	changed := *config
+	changed.Overrides = []EqualityOverride{{Block: 10, Period: &period}, {Block: 20, Epoch: &period}}
+	stored := &ChainConfig{Equality: config}
+	if err := stored.CheckCompatible(&ChainConfig{Equality: &changed}, 15); err != nil {
+		t.Errorf("pending override change rejected: %v", err)
+	}
+	err := stored.CheckCompatible(&ChainConfig{Equality: &changed}, 25)
+	if err == nil || err.What != "equality override" || err.RewindTo != 19 {
+		t.Errorf("recorded override change accepted: %v", err)
+	}
+}
+
+func TestScheduleFork(t *testing.T) {
+	config := *TestChainConfig
+	if err := config.ScheduleFork("frontier", 10); err == nil {
+		t.Error("unknown fork scheduled")
+	}
+	// Forks changing the validity of the headers are left to the genesis
+	if err := config.ScheduleFork("london", 10); err == nil || config.LondonBlock != nil {
+		t.Error("london scheduled")
+	}
+	if err := config.ScheduleFork("berlin", 10); err == nil || config.BerlinBlock != nil {
+		t.Error("berlin scheduled")
+	}
+	if err := config.ScheduleFork("staking", 10); err != nil {
+		t.Fatalf("failed to schedule the staking contract: %v", err)
+	}
+	if err := config.ScheduleFork("staking", 10); err != nil {
+		t.Errorf("failed to reschedule the staking contract at the same block: %v", err)
+	}
+	if err := config.ScheduleFork("staking", 20); err == nil {
+		t.Error("staking contract moved")
+	}
+	if err := config.ScheduleFork("consensusPrecompile", 5); err != nil || config.ConsensusPrecompileBlock.Uint64() != 5 {
+		t.Errorf("failed to schedule the consensus precompile: %v", err)
+	}
+	if TestChainConfig.StakingBlock != nil {
+		t.Error("fork leaked into the copied config")
+	}
+
+	// Forks the validators scheduled and the chain reached cannot change
+	stored := &ChainConfig{Equality: &EqualityConfig{Forks: []EqualityFork{{Name: "staking", Block: 10}}}}
+	if err := stored.CheckCompatible(&ChainConfig{Equality: &EqualityConfig{}}, 5); err != nil {
+		t.Errorf("pending fork change rejected: %v", err)
+	}
+	err := stored.CheckCompatible(&ChainConfig{Equality: &EqualityConfig{}}, 15)
+	if err == nil || err.RewindTo != 9 {
+		t.Errorf("reached fork change accepted: %v", err)
+	}
+}
+
+func TestScheduleEqualityFork(t *testing.T) {
+	config := *TestChainConfig
+	config.Equality = &EqualityConfig{}
+	fork := EqualityFork{Name: "staking", Block: 30}
+
+	// Readers may run while the validators schedule forks
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			config.Rules(big.NewInt(30))
+		}
+	}()
+	if scheduled, err := config.ScheduleEqualityFork(fork); err != nil || !scheduled {
+		t.Fatalf("failed to schedule the fork: %v", err)
+	}
+	<-done
+	if !config.IsStaking(big.NewInt(30)) || !config.Equality.HasFork(fork) {
+		t.Error("fork not recorded")
+	}
+	if scheduled, err := config.ScheduleEqualityFork(fork); err != nil || scheduled {
+		t.Errorf("fork scheduled twice: %v", err)
+	}
+	if _, err := config.ScheduleEqualityFork(EqualityFork{Name: "london", Block: 10}); err == nil || config.Equality.HasFork(EqualityFork{Name: "london", Block: 10}) {
+		t.Error("london scheduled")
+	}
+}
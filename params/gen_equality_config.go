@@ -16,14 +16,23 @@ var _ = (*equalityConfigMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (e EqualityConfig) MarshalJSON() ([]byte, error) {
 	type EqualityConfig struct {
-		Period              uint64                `json:"period"`
-		Epoch               uint64                `json:"epoch"`
-		MaxValidatorsCount  uint64                `json:"maxValidatorsCount"`
-		MinCandidateBalance *math.HexOrDecimal256 `json:"minCandidateBalance" gencodec:"required"`
-		GenesisTimestamp    uint64                `json:"genesisTimestamp"`
-		Validators          []common.Address      `json:"validators"`
-		Pool                common.Address        `json:"pool"`
-		Rewards             EqualityRewards       `json:"rewards"`
+		Period              uint64                   `json:"period"`
+		Epoch               uint64                   `json:"epoch"`
+		MaxValidatorsCount  uint64                   `json:"maxValidatorsCount"`
+		MinCandidateBalance *math.HexOrDecimal256    `json:"minCandidateBalance" gencodec:"required"`
+		GenesisTimestamp    uint64                   `json:"genesisTimestamp"`
+		Validators          []common.Address         `json:"validators"`
+		Pool                common.Address           `json:"pool"`
+		Rewards             EqualityRewards          `json:"rewards"`
+		CandidateExpiry     uint64                   `json:"candidateExpiry,omitempty"`
+		ProposalWindow      uint64                   `json:"proposalWindow,omitempty"`
+		ProposalQuorum      uint64                   `json:"proposalQuorum,omitempty"`
+		ProposalDeposit     *math.HexOrDecimal256    `json:"proposalDeposit,omitempty"`
+		Overrides           []EqualityOverride       `json:"overrides,omitempty"`
+		Forks               []EqualityFork           `json:"forks,omitempty"`
+		SystemContracts     []EqualitySystemContract `json:"systemContracts,omitempty"`
+		AllowUnprotectedTx  bool                     `json:"allowUnprotectedTx,omitempty"`
+		BurnBaseFee         bool                     `json:"burnBaseFee,omitempty"`
 	}
 	var enc EqualityConfig
 	enc.Period = e.Period
@@ -34,20 +43,38 @@ func (e EqualityConfig) MarshalJSON() ([]byte, error) {
 	enc.Validators = e.Validators
 	enc.Pool = e.Pool
 	enc.Rewards = e.Rewards
+	enc.CandidateExpiry = e.CandidateExpiry
+	enc.ProposalWindow = e.ProposalWindow
+	enc.ProposalQuorum = e.ProposalQuorum
+	enc.ProposalDeposit = (*math.HexOrDecimal256)(e.ProposalDeposit)
+	enc.Overrides = e.Overrides
+	enc.Forks = e.Forks
+	enc.SystemContracts = e.SystemContracts
+	enc.AllowUnprotectedTx = e.AllowUnprotectedTx
+	enc.BurnBaseFee = e.BurnBaseFee
 	return json.Marshal(&enc)
 }
 
 // UnmarshalJSON unmarshals from JSON.
 func (e *EqualityConfig) UnmarshalJSON(input []byte) error {
 	type EqualityConfig struct {
-		Period              *uint64               `json:"period"`
-		Epoch               *uint64               `json:"epoch"`
-		MaxValidatorsCount  *uint64               `json:"maxValidatorsCount"`
-		MinCandidateBalance *math.HexOrDecimal256 `json:"minCandidateBalance" gencodec:"required"`
-		GenesisTimestamp    *uint64               `json:"genesisTimestamp"`
-		Validators          []common.Address      `json:"validators"`
-		Pool                *common.Address       `json:"pool"`
-		Rewards             *EqualityRewards      `json:"rewards"`
+		Period              *uint64                  `json:"period"`
+		Epoch               *uint64                  `json:"epoch"`
+		MaxValidatorsCount  *uint64                  `json:"maxValidatorsCount"`
+		MinCandidateBalance *math.HexOrDecimal256    `json:"minCandidateBalance" gencodec:"required"`
+		GenesisTimestamp    *uint64                  `json:"genesisTimestamp"`
+		Validators          []common.Address         `json:"validators"`
+		Pool                *common.Address          `json:"pool"`
+		Rewards             *EqualityRewards         `json:"rewards"`
+		CandidateExpiry     *uint64                  `json:"candidateExpiry,omitempty"`
+		ProposalWindow      *uint64                  `json:"proposalWindow,omitempty"`
+		ProposalQuorum      *uint64                  `json:"proposalQuorum,omitempty"`
+		ProposalDeposit     *math.HexOrDecimal256    `json:"proposalDeposit,omitempty"`
+		Overrides           []EqualityOverride       `json:"overrides,omitempty"`
+		Forks               []EqualityFork           `json:"forks,omitempty"`
+		SystemContracts     []EqualitySystemContract `json:"systemContracts,omitempty"`
+		AllowUnprotectedTx  *bool                    `json:"allowUnprotectedTx,omitempty"`
+		BurnBaseFee         *bool                    `json:"burnBaseFee,omitempty"`
 	}
 	var dec EqualityConfig
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -78,5 +105,32 @@ func (e *EqualityConfig) UnmarshalJSON(input []byte) error {
 	if dec.Rewards != nil {
 		e.Rewards = *dec.Rewards
 	}
+	if dec.CandidateExpiry != nil {
+		e.CandidateExpiry = *dec.CandidateExpiry
+	}
+	if dec.ProposalWindow != nil {
+		e.ProposalWindow = *dec.ProposalWindow
+	}
+	if dec.ProposalQuorum != nil {
+		e.ProposalQuorum = *dec.ProposalQuorum
+	}
+	if dec.ProposalDeposit != nil {
+		e.ProposalDeposit = (*big.Int)(dec.ProposalDeposit)
+	}
+	if dec.Overrides != nil {
+		e.Overrides = dec.Overrides
+	}
+	if dec.Forks != nil {
+		e.Forks = dec.Forks
+	}
+	if dec.SystemContracts != nil {
+		e.SystemContracts = dec.SystemContracts
+	}
+	if dec.AllowUnprotectedTx != nil {
+		e.AllowUnprotectedTx = *dec.AllowUnprotectedTx
+	}
+	if dec.BurnBaseFee != nil {
+		e.BurnBaseFee = *dec.BurnBaseFee
+	}
 	return nil
 }
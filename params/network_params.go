@@ -65,3 +65,30 @@ const (
 	// reorgs, by the light pruner as the pruning validity guarantee.
 	LightImmutabilityThreshold = 30000
 )
+
+// The light client sections of chains sealed by equality. Their 3 second blocks
+// are about four times as frequent as Ethereum's, so the sections hold four times
+// as many blocks to span a similar time, and so do the confirmations.
+const (
+	// EqualityCHTFrequency is the block frequency for creating CHTs
+	EqualityCHTFrequency = 131072
+
+	// EqualityBloomTrieFrequency is the block frequency for creating BloomTrie on
+	// both server/client sides.
+	EqualityBloomTrieFrequency = 131072
+
+	// EqualityHelperTrieConfirmations is the number of confirmations before a client
+	// is expected to have the given HelperTrie available.
+	EqualityHelperTrieConfirmations = 8192
+
+	// EqualityHelperTrieProcessConfirmations is the number of confirmations before a
+	// HelperTrie is generated
+	EqualityHelperTrieProcessConfirmations = 1024
+
+	// EqualityCheckpointFrequency is the block frequency for creating checkpoint
+	EqualityCheckpointFrequency = 131072
+
+	// EqualityCheckpointProcessConfirmations is the number before a checkpoint is
+	// generated
+	EqualityCheckpointProcessConfirmations = 1024
+)
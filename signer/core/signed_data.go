@@ -35,6 +35,7 @@ import (
 	"github.com/SecretBlockChain/go-secret/common/hexutil"
 	"github.com/SecretBlockChain/go-secret/common/math"
 	"github.com/SecretBlockChain/go-secret/consensus/clique"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
 	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/crypto"
 	"github.com/SecretBlockChain/go-secret/rlp"
@@ -58,6 +59,10 @@ var (
 		accounts.MimetypeClique,
 		0x02,
 	}
+	ApplicationEquality = SigFormat{
+		accounts.MimetypeEquality,
+		0x03,
+	}
 	TextPlain = SigFormat{
 		accounts.MimetypeTextPlain,
 		0x45,
@@ -258,6 +263,47 @@ func (api *SignerAPI) determineSignatureFormat(ctx context.Context, contentType
 		// Clique uses V on the form 0 or 1
 		useEthereumV = false
 		req = &SignDataRequest{ContentType: mediaType, Rawdata: cliqueRlp, Messages: messages, Hash: sighash}
+	case ApplicationEquality.Mime:
+		// Equality headers are sealed like clique ones, the seal being the last 65 bytes of the extradata
+		stringData, ok := data.(string)
+		if !ok {
+			return nil, useEthereumV, fmt.Errorf("input for %v must be an hex-encoded string", ApplicationEquality.Mime)
+		}
+		equalityData, err := hexutil.Decode(stringData)
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		header := &types.Header{}
+		if err := rlp.DecodeBytes(equalityData, header); err != nil {
+			return nil, useEthereumV, err
+		}
+		// The incoming header is truncated like the clique ones, add the seal back
+		newExtra := make([]byte, len(header.Extra)+65)
+		copy(newExtra, header.Extra)
+		header.Extra = newExtra
+
+		// The block number is exposed on its own, letting rules refuse to sign the
+		// same height twice
+		messages := []*NameValueType{
+			{
+				Name:  "Equality header",
+				Typ:   "equality",
+				Value: fmt.Sprintf("equality header %d [0x%x]", header.Number, header.Hash()),
+			},
+			{
+				Name:  "Block number",
+				Typ:   "uint64",
+				Value: header.Number.Uint64(),
+			},
+			{
+				Name:  "Coinbase",
+				Typ:   "address",
+				Value: header.Coinbase.String(),
+			},
+		}
+		// Equality uses V on the form 0 or 1 too
+		useEthereumV = false
+		req = &SignDataRequest{ContentType: mediaType, Rawdata: equality.EqualityRLP(header), Messages: messages, Hash: equality.SealHash(header).Bytes()}
 	default: // also case TextPlain.Mime:
 		// Calculates an Ethereum ECDSA signature for:
 		// hash = keccak256("\x19${byteVersion}Ethereum Signed Message:\n${message length}${message}")
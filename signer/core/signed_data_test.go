@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"path"
 	"strings"
 	"testing"
@@ -30,6 +31,8 @@ import (
 	"github.com/SecretBlockChain/go-secret/common"
 	"github.com/SecretBlockChain/go-secret/common/hexutil"
 	"github.com/SecretBlockChain/go-secret/common/math"
+	"github.com/SecretBlockChain/go-secret/consensus/equality"
+	"github.com/SecretBlockChain/go-secret/core/types"
 	"github.com/SecretBlockChain/go-secret/crypto"
 	"github.com/SecretBlockChain/go-secret/signer/core"
 )
@@ -229,6 +232,24 @@ func TestSignData(t *testing.T) {
 	if signature == nil || len(signature) != 65 {
 		t.Errorf("Expected 65 byte signature (got %d bytes)", len(signature))
 	}
+	// application/x-equality-header
+	header := &types.Header{Number: big.NewInt(1337), Difficulty: big.NewInt(1), Extra: make([]byte, 32+65)}
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	signature, err = api.SignData(context.Background(), core.ApplicationEquality.Mime, a, hexutil.Encode(equality.EqualityRLP(header)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signature == nil || len(signature) != 65 {
+		t.Fatalf("Expected 65 byte signature (got %d bytes)", len(signature))
+	}
+	pubkey, err := crypto.SigToPub(equality.SealHash(header).Bytes(), signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != list[0] {
+		t.Errorf("Expected equality header sealed by %x, got %x", list[0], signer)
+	}
 }
 
 func TestDomainChainId(t *testing.T) {
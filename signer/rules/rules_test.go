@@ -625,3 +625,57 @@ function ApproveSignData(r){
 		t.Fatalf("Expected approved")
 	}
 }
+
+func TestSignEqualityIncreasing(t *testing.T) {
+	js := `function ApproveSignData(r){
+    if( r.content_type != "application/x-equality-header"){
+        return
+    }
+    var number = -1
+    r.messages.forEach(function(m){
+        if( m.name == "Block number"){
+            number = m.value
+        }
+    })
+    var last = storage.get("lastSealed")
+    if( number < 0 || (last != "" && number <= parseInt(last))){
+        return "Reject"
+    }
+    storage.put("lastSealed", number.toString())
+    return "Approve"
+}`
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	addr, _ := mixAddr("0x694267f14675d7e1b9494fd8d72fefe1755710fa")
+	request := func(number uint64) *core.SignDataRequest {
+		return &core.SignDataRequest{
+			ContentType: accounts.MimetypeEquality,
+			Address:     *addr,
+			Messages: []*core.NameValueType{
+				{Name: "Equality header", Typ: "equality", Value: fmt.Sprintf("equality header %d", number)},
+				{Name: "Block number", Typ: "uint64", Value: number},
+			},
+			Meta: core.Metadata{Remote: "remoteip", Local: "localip", Scheme: "inproc"},
+		}
+	}
+	for i, test := range []struct {
+		number   uint64
+		approved bool
+	}{
+		{10, true},
+		{11, true},
+		{11, false}, // Double sign of the same height
+		{9, false},  // Sign of an older height
+		{12, true},
+	} {
+		resp, err := r.ApproveSignData(request(test.number))
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i, err)
+		}
+		if resp.Approved != test.approved {
+			t.Errorf("test %d: block %d approved %v, want %v", i, test.number, resp.Approved, test.approved)
+		}
+	}
+}
@@ -166,6 +166,7 @@ var Forks = map[string]*params.ChainConfig{
 		PetersburgBlock:     big.NewInt(0),
 		IstanbulBlock:       big.NewInt(0),
 		YoloV1Block:         big.NewInt(0),
+		BerlinBlock:         big.NewInt(0),
 	},
 }
 